@@ -106,18 +106,23 @@ func (m *Manager) SendDeploySuccess(ctx context.Context, commit, target string)
 
 // SendDeployFailure sends a deployment failure notification.
 func (m *Manager) SendDeployFailure(ctx context.Context, commit, target, reason string) error {
+	return m.Send(ctx, DeployFailureAlert(commit, target, reason))
+}
+
+// DeployFailureAlert builds the notification sent when a deployment fails.
+func DeployFailureAlert(commit, target, reason string) *Alert {
 	shortCommit := commit
 	if len(commit) > 8 {
 		shortCommit = commit[:8]
 	}
 
-	return m.Send(ctx, &Alert{
+	return &Alert{
 		Title:    "Deployment Failed",
 		Message:  fmt.Sprintf("Failed to deploy commit %s to %s: %s", shortCommit, target, reason),
 		Severity: SeverityError,
 		Source:   "reconcile",
 		Metadata: map[string]string{"commit": commit, "target": target, "error": reason},
-	})
+	}
 }
 
 // SendRollbackSuccess sends a rollback success notification.
@@ -142,6 +147,23 @@ func (m *Manager) SendRollbackFailure(ctx context.Context, target, reason string
 	})
 }
 
+// SendDrift sends a config drift notification (see DriftAlert).
+func (m *Manager) SendDrift(ctx context.Context, target string, drifted []string) error {
+	return m.Send(ctx, DriftAlert(target, drifted))
+}
+
+// SendPermissionRegression sends a notification about sensitive files whose
+// permissions or ownership regressed during a deploy.
+func (m *Manager) SendPermissionRegression(ctx context.Context, target string, issues []string) error {
+	return m.Send(ctx, &Alert{
+		Title:    "Permission Regression After Deploy",
+		Message:  fmt.Sprintf("Sensitive files on %s regressed during the sync:\n%s", target, strings.Join(issues, "\n")),
+		Severity: SeverityWarning,
+		Source:   "reconcile",
+		Metadata: map[string]string{"target": target, "issue_count": fmt.Sprintf("%d", len(issues))},
+	})
+}
+
 // SendDoctorAlert sends a health check alert.
 func (m *Manager) SendDoctorAlert(ctx context.Context, severity Severity, issues []string) error {
 	var title string
@@ -227,6 +227,25 @@ func TestManager_SendRollbackFailure(t *testing.T) {
 	assert.Contains(t, alert.Message, "Manual intervention required")
 }
 
+func TestManager_SendPermissionRegression(t *testing.T) {
+	m := NewManager()
+	p := newMockProvider("test", true)
+	m.AddProvider(p)
+
+	issues := []string{"traefik/acme.json: mode 0644 is looser than 0600 (repaired)"}
+	err := m.SendPermissionRegression(context.Background(), "unraid", issues)
+	require.NoError(t, err)
+
+	alerts := p.getAlerts()
+	require.Len(t, alerts, 1)
+
+	alert := alerts[0]
+	assert.Equal(t, "Permission Regression After Deploy", alert.Title)
+	assert.Equal(t, SeverityWarning, alert.Severity)
+	assert.Contains(t, alert.Message, "traefik/acme.json")
+	assert.Equal(t, "1", alert.Metadata["issue_count"])
+}
+
 func TestManager_SendDoctorAlert(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -264,3 +283,18 @@ func TestSeverityConstants(t *testing.T) {
 	assert.Equal(t, Severity("error"), SeverityError)
 	assert.Equal(t, Severity("critical"), SeverityCritical)
 }
+
+func TestManager_SendDrift(t *testing.T) {
+	m := NewManager()
+	p := newMockProvider("test", true)
+	m.AddProvider(p)
+
+	err := m.SendDrift(context.Background(), "unraid", []string{"traefik: image drift"})
+	require.NoError(t, err)
+
+	alerts := p.getAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "Config Drift Detected", alerts[0].Title)
+	assert.Equal(t, SeverityWarning, alerts[0].Severity)
+	assert.Equal(t, "unraid", alerts[0].Metadata["target"])
+}
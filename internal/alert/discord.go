@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -83,32 +84,8 @@ func (d *DiscordProvider) Send(ctx context.Context, alert *Alert) error {
 		return nil
 	}
 
-	embed := discordEmbed{
-		Title:       alert.Title,
-		Description: alert.Message,
-		Color:       severityToColor(alert.Severity),
-		Footer:      &discordFooter{Text: fmt.Sprintf("bosun/%s", alert.Source)},
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-	}
-
-	// Add metadata as fields.
-	if len(alert.Metadata) > 0 {
-		embed.Fields = make([]discordEmbedField, 0, len(alert.Metadata))
-		for key, value := range alert.Metadata {
-			// Skip empty values.
-			if value == "" {
-				continue
-			}
-			embed.Fields = append(embed.Fields, discordEmbedField{
-				Name:   key,
-				Value:  truncateString(value, 1024), // Discord field limit.
-				Inline: true,
-			})
-		}
-	}
-
 	payload := discordPayload{
-		Embeds: []discordEmbed{embed},
+		Embeds: []discordEmbed{d.buildEmbed(alert)},
 	}
 
 	body, err := json.Marshal(payload)
@@ -136,6 +113,53 @@ func (d *DiscordProvider) Send(ctx context.Context, alert *Alert) error {
 	return nil
 }
 
+// Preview renders the embed as plain text without posting it.
+func (d *DiscordProvider) Preview(alert *Alert) Preview {
+	embed := d.buildEmbed(alert)
+
+	lines := []string{"**" + embed.Title + "**", embed.Description}
+	for _, field := range sortedMetadata(alert.Metadata) {
+		lines = append(lines, truncateString(field, 1024))
+	}
+	lines = append(lines, embed.Footer.Text)
+
+	return Preview{
+		Provider:   d.Name(),
+		WouldSend:  true,
+		Recipients: []string{"webhook " + MaskWebhookURL(d.webhookURL)},
+		Rendered:   strings.Join(lines, "\n"),
+	}
+}
+
+// buildEmbed converts an alert into a Discord embed.
+func (d *DiscordProvider) buildEmbed(alert *Alert) discordEmbed {
+	embed := discordEmbed{
+		Title:       alert.Title,
+		Description: alert.Message,
+		Color:       severityToColor(alert.Severity),
+		Footer:      &discordFooter{Text: fmt.Sprintf("bosun/%s", alert.Source)},
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Add metadata as fields.
+	if len(alert.Metadata) > 0 {
+		embed.Fields = make([]discordEmbedField, 0, len(alert.Metadata))
+		for key, value := range alert.Metadata {
+			// Skip empty values.
+			if value == "" {
+				continue
+			}
+			embed.Fields = append(embed.Fields, discordEmbedField{
+				Name:   key,
+				Value:  truncateString(value, 1024), // Discord field limit.
+				Inline: true,
+			})
+		}
+	}
+
+	return embed
+}
+
 // severityToColor maps alert severity to Discord embed color.
 func severityToColor(severity Severity) int {
 	switch severity {
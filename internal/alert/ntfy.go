@@ -0,0 +1,142 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultNtfyServer is the public ntfy server used when no server is set.
+const DefaultNtfyServer = "https://ntfy.sh"
+
+// NtfyConfig holds configuration for the ntfy push notification provider.
+type NtfyConfig struct {
+	// Server is the ntfy server URL (default: https://ntfy.sh).
+	Server string
+
+	// Topic is the topic to publish to.
+	Topic string
+
+	// Token is an optional access token for protected topics.
+	Token string
+}
+
+// Ntfy implements the Provider interface for ntfy push notifications.
+type Ntfy struct {
+	config NtfyConfig
+	client *http.Client
+}
+
+// NewNtfy creates a new ntfy provider with the given configuration.
+func NewNtfy(config NtfyConfig) *Ntfy {
+	if config.Server == "" {
+		config.Server = DefaultNtfyServer
+	}
+	return &Ntfy{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the provider name.
+func (n *Ntfy) Name() string {
+	return "ntfy"
+}
+
+// IsConfigured returns true if a topic is set.
+func (n *Ntfy) IsConfigured() bool {
+	return n.config.Topic != ""
+}
+
+// Send publishes an alert to the ntfy topic.
+func (n *Ntfy) Send(ctx context.Context, alert *Alert) error {
+	if !n.IsConfigured() {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.topicURL(), strings.NewReader(n.buildMessage(alert)))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Title", alert.Title)
+	req.Header.Set("Priority", ntfyPriority(alert.Severity))
+	req.Header.Set("Tags", strings.Join(ntfyTags(alert), ","))
+	if n.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.config.Token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Preview renders the notification without publishing it.
+func (n *Ntfy) Preview(alert *Alert) Preview {
+	lines := []string{
+		fmt.Sprintf("%s (priority %s, tags %s)", alert.Title, ntfyPriority(alert.Severity), strings.Join(ntfyTags(alert), ",")),
+		n.buildMessage(alert),
+	}
+
+	return Preview{
+		Provider:   n.Name(),
+		WouldSend:  true,
+		Recipients: []string{"topic " + n.topicURL()},
+		Rendered:   strings.Join(lines, "\n"),
+	}
+}
+
+// topicURL returns the URL to publish to.
+func (n *Ntfy) topicURL() string {
+	return strings.TrimRight(n.config.Server, "/") + "/" + n.config.Topic
+}
+
+// buildMessage formats the notification body: the message followed by
+// metadata lines.
+func (n *Ntfy) buildMessage(alert *Alert) string {
+	lines := append([]string{alert.Message}, sortedMetadata(alert.Metadata)...)
+	return strings.Join(lines, "\n")
+}
+
+// ntfyPriority maps alert severity to an ntfy priority (1-5).
+func ntfyPriority(severity Severity) string {
+	switch severity {
+	case SeverityCritical:
+		return "5"
+	case SeverityError, SeverityWarning:
+		return "4"
+	default:
+		return "3"
+	}
+}
+
+// ntfyTags returns the tags for an alert: an emoji for the severity and the
+// alert source.
+func ntfyTags(alert *Alert) []string {
+	var emoji string
+	switch alert.Severity {
+	case SeverityCritical:
+		emoji = "rotating_light"
+	case SeverityError:
+		emoji = "x"
+	case SeverityWarning:
+		emoji = "warning"
+	default:
+		emoji = "information_source"
+	}
+
+	tags := []string{emoji}
+	if alert.Source != "" {
+		tags = append(tags, alert.Source)
+	}
+	return tags
+}
@@ -0,0 +1,98 @@
+package alert
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNtfy_IsConfigured(t *testing.T) {
+	assert.True(t, NewNtfy(NtfyConfig{Topic: "bosun"}).IsConfigured())
+	assert.False(t, NewNtfy(NtfyConfig{}).IsConfigured())
+}
+
+func TestNtfy_DefaultServer(t *testing.T) {
+	p := NewNtfy(NtfyConfig{Topic: "bosun"})
+	assert.Equal(t, "https://ntfy.sh/bosun", p.topicURL())
+}
+
+func TestNtfy_Send(t *testing.T) {
+	t.Run("publishes with headers", func(t *testing.T) {
+		var path, body string
+		var header http.Header
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			path = r.URL.Path
+			header = r.Header.Clone()
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(data)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		p := NewNtfy(NtfyConfig{Server: server.URL + "/", Topic: "homelab", Token: "tk_secret"})
+		err := p.Send(context.Background(), &Alert{
+			Title:    "CRITICAL: Rollback Failed",
+			Message:  "manual intervention required",
+			Severity: SeverityCritical,
+			Source:   "reconcile",
+			Metadata: map[string]string{"target": "local"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "/homelab", path)
+		assert.Equal(t, "CRITICAL: Rollback Failed", header.Get("Title"))
+		assert.Equal(t, "5", header.Get("Priority"))
+		assert.Equal(t, "rotating_light,reconcile", header.Get("Tags"))
+		assert.Equal(t, "Bearer tk_secret", header.Get("Authorization"))
+		assert.Equal(t, "manual intervention required\ntarget: local", body)
+	})
+
+	t.Run("omits authorization without token", func(t *testing.T) {
+		var auth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		require.NoError(t, NewNtfy(NtfyConfig{Server: server.URL, Topic: "t"}).Send(context.Background(), &Alert{Title: "Test"}))
+		assert.Empty(t, auth)
+	})
+
+	t.Run("returns error on non-200 status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := NewNtfy(NtfyConfig{Server: server.URL, Topic: "t"}).Send(context.Background(), &Alert{Title: "Test"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status: 401")
+	})
+}
+
+func TestNtfyPriority(t *testing.T) {
+	assert.Equal(t, "3", ntfyPriority(SeverityInfo))
+	assert.Equal(t, "4", ntfyPriority(SeverityWarning))
+	assert.Equal(t, "4", ntfyPriority(SeverityError))
+	assert.Equal(t, "5", ntfyPriority(SeverityCritical))
+}
+
+func TestNtfy_Preview(t *testing.T) {
+	p := NewNtfy(NtfyConfig{Topic: "homelab", Token: "tk_secret"})
+	preview := p.Preview(DriftAlert("local", []string{"traefik: image drift"}))
+
+	assert.Equal(t, "ntfy", preview.Provider)
+	assert.True(t, preview.WouldSend)
+	assert.Equal(t, []string{"topic https://ntfy.sh/homelab"}, preview.Recipients)
+	assert.Contains(t, preview.Rendered, "Config Drift Detected (priority 4, tags warning,drift)")
+	assert.NotContains(t, preview.Rendered, "tk_secret")
+}
@@ -0,0 +1,102 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Preview describes what a provider would deliver for an alert, without
+// sending anything.
+type Preview struct {
+	Provider   string   // Provider name
+	WouldSend  bool     // Whether the provider would deliver this alert
+	Reason     string   // Why the provider would not deliver, if WouldSend is false
+	Recipients []string // Where the alert would go (masked where sensitive)
+	Rendered   string   // Message as the provider would render it
+}
+
+// MaskWebhookURL hides the last path segment of a webhook URL, which
+// holds the token for most providers, keeping the host and path before it.
+func MaskWebhookURL(webhookURL string) string {
+	i := strings.LastIndex(webhookURL, "/")
+	if i < 0 || i == len(webhookURL)-1 {
+		return "****"
+	}
+	return webhookURL[:i+1] + "****"
+}
+
+// Previewer is implemented by providers that can render an alert without
+// sending it.
+type Previewer interface {
+	Preview(alert *Alert) Preview
+}
+
+// Preview returns what each configured provider would deliver for an alert.
+// Providers that don't implement Previewer are reported as sending the
+// alert with no rendered message.
+func (m *Manager) Preview(alert *Alert) []Preview {
+	previews := make([]Preview, 0, len(m.providers))
+	for _, p := range m.providers {
+		if pv, ok := p.(Previewer); ok {
+			previews = append(previews, pv.Preview(alert))
+			continue
+		}
+		previews = append(previews, Preview{Provider: p.Name(), WouldSend: true})
+	}
+	return previews
+}
+
+// DriftAlert builds a notification for services whose running state no
+// longer matches git.
+func DriftAlert(target string, drifted []string) *Alert {
+	return &Alert{
+		Title:    "Config Drift Detected",
+		Message:  fmt.Sprintf("%d service(s) on %s differ from git:\n%s", len(drifted), target, strings.Join(drifted, "\n")),
+		Severity: SeverityWarning,
+		Source:   "drift",
+		Metadata: map[string]string{"target": target, "drift_count": fmt.Sprintf("%d", len(drifted))},
+	}
+}
+
+// UpdatesAlert builds a notification for newer images available for the
+// deployed services, one "stack/service: old -> new" line each.
+func UpdatesAlert(target string, updates []string) *Alert {
+	return &Alert{
+		Title:    "Image Updates Available",
+		Message:  fmt.Sprintf("%d image update(s) available on %s:\n%s", len(updates), target, strings.Join(updates, "\n")),
+		Severity: SeverityInfo,
+		Source:   "updates",
+		Metadata: map[string]string{"target": target, "update_count": fmt.Sprintf("%d", len(updates))},
+	}
+}
+
+// DigestAlert builds the weekly "state of the yacht" report from digest
+// lines (see state.Digest).
+func DigestAlert(target, period string, lines []string) *Alert {
+	return &Alert{
+		Title:    "Weekly Digest",
+		Message:  fmt.Sprintf("State of %s over %s:\n%s", target, period, strings.Join(lines, "\n")),
+		Severity: SeverityInfo,
+		Source:   "digest",
+		Metadata: map[string]string{"target": target, "period": period},
+	}
+}
+
+// sortedMetadata returns alert metadata as "key: value" lines in key order,
+// skipping empty values.
+func sortedMetadata(metadata map[string]string) []string {
+	keys := make([]string, 0, len(metadata))
+	for k, v := range metadata {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("%s: %s", k, metadata[k])
+	}
+	return lines
+}
@@ -0,0 +1,60 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Preview(t *testing.T) {
+	m := NewManager()
+	m.AddProvider(NewDiscordProvider("https://discord.com/api/webhooks/123/secret-token"))
+	m.AddProvider(NewSendGrid(SendGridConfig{APIKey: "SG.x", FromEmail: "bosun@example.com", ToEmails: []string{"ops@example.com"}}))
+	m.AddProvider(NewTwilio(TwilioConfig{AccountSID: "AC1", AuthToken: "t", FromNumber: "+15550000000", ToNumbers: []string{"+15551234567"}}))
+	m.AddProvider(newMockProvider("mock", true))
+
+	t.Run("warning skips twilio", func(t *testing.T) {
+		previews := m.Preview(DriftAlert("nas", []string{"traefik: image drift"}))
+		require.Len(t, previews, 4)
+
+		discord := previews[0]
+		assert.True(t, discord.WouldSend)
+		assert.Equal(t, []string{"webhook https://discord.com/api/webhooks/123/****"}, discord.Recipients)
+		assert.Contains(t, discord.Rendered, "**Config Drift Detected**")
+		assert.Contains(t, discord.Rendered, "drift_count: 1")
+		assert.Contains(t, discord.Rendered, "bosun/drift")
+		assert.NotContains(t, discord.Rendered, "secret-token")
+
+		sendgrid := previews[1]
+		assert.True(t, sendgrid.WouldSend)
+		assert.Equal(t, []string{"ops@example.com"}, sendgrid.Recipients)
+		assert.Contains(t, sendgrid.Rendered, "Subject: [WARNING] Config Drift Detected")
+
+		twilio := previews[2]
+		assert.False(t, twilio.WouldSend)
+		assert.Contains(t, twilio.Reason, "not warning")
+		assert.Equal(t, []string{"****4567"}, twilio.Recipients)
+
+		mock := previews[3]
+		assert.Equal(t, Preview{Provider: "mock", WouldSend: true}, mock)
+	})
+
+	t.Run("error fires twilio", func(t *testing.T) {
+		previews := m.Preview(DeployFailureAlert("abcdef1234567890", "nas", "boom"))
+		twilio := previews[2]
+		assert.True(t, twilio.WouldSend)
+		assert.Equal(t, "[ERROR] Deployment Failed: Failed to deploy commit abcdef12 to nas: boom", twilio.Rendered)
+	})
+}
+
+func TestSortedMetadata(t *testing.T) {
+	got := sortedMetadata(map[string]string{"b": "2", "a": "1", "empty": ""})
+	assert.Equal(t, []string{"a: 1", "b: 2"}, got)
+}
+
+func TestMaskWebhookURL(t *testing.T) {
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/****", MaskWebhookURL("https://hooks.slack.com/services/T/B/secret"))
+	assert.Equal(t, "****", MaskWebhookURL("https://example.com/"))
+	assert.Equal(t, "****", MaskWebhookURL("token"))
+}
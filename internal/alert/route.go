@@ -0,0 +1,67 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+)
+
+// severityRank orders severities from least to most severe.
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityError:    2,
+	SeverityCritical: 3,
+}
+
+// AtLeast reports whether s is as severe as min or more. Unknown
+// severities rank as info.
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRank[s] >= severityRank[min]
+}
+
+// ValidSeverity reports whether s is a known severity.
+func ValidSeverity(s Severity) bool {
+	_, ok := severityRank[s]
+	return ok
+}
+
+// routedProvider wraps a provider so it only receives alerts at or above a
+// minimum severity.
+type routedProvider struct {
+	Provider
+	min Severity
+}
+
+// MinSeverity returns p limited to alerts at or above min, so noisy
+// channels and paging channels can share one manager. An info minimum
+// returns p unchanged.
+func MinSeverity(p Provider, min Severity) Provider {
+	if min == "" || min == SeverityInfo {
+		return p
+	}
+	return &routedProvider{Provider: p, min: min}
+}
+
+// Send forwards the alert if it meets the minimum severity.
+func (r *routedProvider) Send(ctx context.Context, alert *Alert) error {
+	if !alert.Severity.AtLeast(r.min) {
+		return nil
+	}
+	return r.Provider.Send(ctx, alert)
+}
+
+// Preview reports alerts below the minimum severity as skipped.
+func (r *routedProvider) Preview(alert *Alert) Preview {
+	var p Preview
+	if pv, ok := r.Provider.(Previewer); ok {
+		p = pv.Preview(alert)
+	} else {
+		p = Preview{Provider: r.Name(), WouldSend: true}
+	}
+
+	if p.WouldSend && !alert.Severity.AtLeast(r.min) {
+		p.WouldSend = false
+		p.Reason = fmt.Sprintf("routed for %s alerts and above, not %s", r.min, alert.Severity)
+	}
+	return p
+}
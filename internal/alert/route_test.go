@@ -0,0 +1,67 @@
+package alert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverity_AtLeast(t *testing.T) {
+	assert.True(t, SeverityCritical.AtLeast(SeverityError))
+	assert.True(t, SeverityError.AtLeast(SeverityError))
+	assert.False(t, SeverityWarning.AtLeast(SeverityError))
+	assert.True(t, SeverityInfo.AtLeast(SeverityInfo))
+	assert.False(t, Severity("bogus").AtLeast(SeverityWarning))
+}
+
+func TestValidSeverity(t *testing.T) {
+	assert.True(t, ValidSeverity(SeverityWarning))
+	assert.False(t, ValidSeverity("urgent"))
+}
+
+func TestMinSeverity(t *testing.T) {
+	t.Run("info minimum returns provider unchanged", func(t *testing.T) {
+		p := newMockProvider("test", true)
+		assert.Same(t, p, MinSeverity(p, SeverityInfo).(*mockProvider))
+		assert.Same(t, p, MinSeverity(p, "").(*mockProvider))
+	})
+
+	t.Run("drops alerts below the minimum", func(t *testing.T) {
+		p := newMockProvider("pager", true)
+		m := NewManager()
+		m.AddProvider(MinSeverity(p, SeverityError))
+
+		require.NoError(t, m.Send(context.Background(), &Alert{Title: "drift", Severity: SeverityWarning}))
+		require.NoError(t, m.Send(context.Background(), &Alert{Title: "failed", Severity: SeverityError}))
+		require.NoError(t, m.Send(context.Background(), &Alert{Title: "rollback", Severity: SeverityCritical}))
+
+		alerts := p.getAlerts()
+		require.Len(t, alerts, 2)
+		assert.Equal(t, "failed", alerts[0].Title)
+		assert.Equal(t, "rollback", alerts[1].Title)
+		assert.Equal(t, []string{"pager"}, m.ProviderNames())
+	})
+
+	t.Run("preview reports routing", func(t *testing.T) {
+		p := MinSeverity(NewSlackProvider("https://hooks.slack.com/services/T/B/x"), SeverityError)
+
+		skipped := p.(Previewer).Preview(DriftAlert("local", nil))
+		assert.Equal(t, "slack", skipped.Provider)
+		assert.False(t, skipped.WouldSend)
+		assert.Equal(t, "routed for error alerts and above, not warning", skipped.Reason)
+
+		firing := p.(Previewer).Preview(DeployFailureAlert("abc", "local", "boom"))
+		assert.True(t, firing.WouldSend)
+		assert.NotEmpty(t, firing.Rendered)
+	})
+
+	t.Run("preview keeps the provider's own reason", func(t *testing.T) {
+		p := MinSeverity(NewTwilio(TwilioConfig{AccountSID: "AC1", AuthToken: "t", FromNumber: "+1", ToNumbers: []string{"+2"}}), SeverityWarning)
+
+		preview := p.(Previewer).Preview(DriftAlert("local", nil))
+		assert.False(t, preview.WouldSend)
+		assert.Contains(t, preview.Reason, "SMS is only sent")
+	})
+}
@@ -85,6 +85,16 @@ func (s *SendGrid) Send(ctx context.Context, alert *Alert) error {
 	return nil
 }
 
+// Preview renders the email subject and plain text body without sending.
+func (s *SendGrid) Preview(alert *Alert) Preview {
+	return Preview{
+		Provider:   s.Name(),
+		WouldSend:  true,
+		Recipients: s.config.ToEmails,
+		Rendered:   "Subject: " + s.formatSubject(alert) + "\n\n" + s.formatPlainBody(alert),
+	}
+}
+
 // sendGridRequest represents the SendGrid v3 API request structure.
 type sendGridRequest struct {
 	Personalizations []sendGridPersonalization `json:"personalizations"`
@@ -0,0 +1,130 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackAttachment represents a Slack message attachment, used for the
+// severity color bar and metadata fields.
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Text   string       `json:"text"`
+	Fields []slackField `json:"fields,omitempty"`
+	Footer string       `json:"footer,omitempty"`
+	Ts     int64        `json:"ts,omitempty"`
+}
+
+// slackField represents a Slack attachment field.
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// slackPayload represents the Slack incoming webhook payload.
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// SlackProvider sends alerts via Slack incoming webhooks.
+type SlackProvider struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackProvider creates a new Slack provider.
+func NewSlackProvider(webhookURL string) *SlackProvider {
+	return &SlackProvider{
+		webhookURL: webhookURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name.
+func (s *SlackProvider) Name() string {
+	return "slack"
+}
+
+// IsConfigured returns true if the webhook URL is set.
+func (s *SlackProvider) IsConfigured() bool {
+	return s.webhookURL != ""
+}
+
+// Send sends an alert to Slack.
+func (s *SlackProvider) Send(ctx context.Context, alert *Alert) error {
+	if !s.IsConfigured() {
+		return nil
+	}
+
+	body, err := json.Marshal(s.buildPayload(alert))
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Preview renders the message as plain text without posting it.
+func (s *SlackProvider) Preview(alert *Alert) Preview {
+	payload := s.buildPayload(alert)
+
+	lines := []string{payload.Text, payload.Attachments[0].Text}
+	lines = append(lines, sortedMetadata(alert.Metadata)...)
+	lines = append(lines, payload.Attachments[0].Footer)
+
+	return Preview{
+		Provider:   s.Name(),
+		WouldSend:  true,
+		Recipients: []string{"webhook " + MaskWebhookURL(s.webhookURL)},
+		Rendered:   strings.Join(lines, "\n"),
+	}
+}
+
+// buildPayload converts an alert into a Slack webhook payload.
+func (s *SlackProvider) buildPayload(alert *Alert) slackPayload {
+	attachment := slackAttachment{
+		Color:  fmt.Sprintf("#%06x", severityToColor(alert.Severity)),
+		Text:   alert.Message,
+		Footer: fmt.Sprintf("bosun/%s", alert.Source),
+		Ts:     time.Now().Unix(),
+	}
+
+	for _, field := range sortedMetadata(alert.Metadata) {
+		key, value, _ := strings.Cut(field, ": ")
+		attachment.Fields = append(attachment.Fields, slackField{
+			Title: key,
+			Value: truncateString(value, 2000),
+			Short: true,
+		})
+	}
+
+	return slackPayload{
+		Text:        "*" + alert.Title + "*",
+		Attachments: []slackAttachment{attachment},
+	}
+}
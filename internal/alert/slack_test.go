@@ -0,0 +1,79 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackProvider_IsConfigured(t *testing.T) {
+	assert.True(t, NewSlackProvider("https://hooks.slack.com/services/T/B/x").IsConfigured())
+	assert.False(t, NewSlackProvider("").IsConfigured())
+}
+
+func TestSlackProvider_Send(t *testing.T) {
+	t.Run("sends alert successfully", func(t *testing.T) {
+		var received slackPayload
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		p := NewSlackProvider(server.URL)
+		err := p.Send(context.Background(), &Alert{
+			Title:    "Deployment Failed",
+			Message:  "render failed",
+			Severity: SeverityError,
+			Source:   "reconcile",
+			Metadata: map[string]string{"target": "local", "commit": "abc123", "empty": ""},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "*Deployment Failed*", received.Text)
+		require.Len(t, received.Attachments, 1)
+		attachment := received.Attachments[0]
+		assert.Equal(t, "#e74c3c", attachment.Color)
+		assert.Equal(t, "render failed", attachment.Text)
+		assert.Equal(t, "bosun/reconcile", attachment.Footer)
+		assert.Equal(t, []slackField{
+			{Title: "commit", Value: "abc123", Short: true},
+			{Title: "target", Value: "local", Short: true},
+		}, attachment.Fields)
+	})
+
+	t.Run("returns error on non-200 status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := NewSlackProvider(server.URL).Send(context.Background(), &Alert{Title: "Test"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status: 403")
+	})
+
+	t.Run("skips when not configured", func(t *testing.T) {
+		assert.NoError(t, NewSlackProvider("").Send(context.Background(), &Alert{Title: "Test"}))
+	})
+}
+
+func TestSlackProvider_Preview(t *testing.T) {
+	p := NewSlackProvider("https://hooks.slack.com/services/T000/B000/secret")
+	preview := p.Preview(DriftAlert("local", []string{"traefik: image drift"}))
+
+	assert.Equal(t, "slack", preview.Provider)
+	assert.True(t, preview.WouldSend)
+	assert.Equal(t, []string{"webhook https://hooks.slack.com/services/T000/B000/****"}, preview.Recipients)
+	assert.Contains(t, preview.Rendered, "*Config Drift Detected*")
+	assert.Contains(t, preview.Rendered, "traefik: image drift")
+	assert.NotContains(t, preview.Rendered, "secret")
+}
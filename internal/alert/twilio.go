@@ -84,6 +84,27 @@ func (t *Twilio) Send(ctx context.Context, alert *Alert) error {
 	return lastErr
 }
 
+// Preview renders the SMS body without sending. Alerts below error
+// severity are reported as skipped, matching Send.
+func (t *Twilio) Preview(alert *Alert) Preview {
+	recipients := make([]string, len(t.config.ToNumbers))
+	for i, n := range t.config.ToNumbers {
+		recipients[i] = maskPhoneNumber(n)
+	}
+
+	p := Preview{
+		Provider:   t.Name(),
+		WouldSend:  true,
+		Recipients: recipients,
+		Rendered:   t.formatMessage(alert),
+	}
+	if alert.Severity != SeverityError && alert.Severity != SeverityCritical {
+		p.WouldSend = false
+		p.Reason = fmt.Sprintf("SMS is only sent for error and critical alerts, not %s", alert.Severity)
+	}
+	return p
+}
+
 // sendSMS sends a single SMS message to one recipient.
 func (t *Twilio) sendSMS(ctx context.Context, toNumber, message string) error {
 	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", twilioAPIURL, t.config.AccountSID)
@@ -0,0 +1,111 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookPayload is the JSON body posted by the generic webhook provider.
+type webhookPayload struct {
+	Title     string            `json:"title"`
+	Message   string            `json:"message"`
+	Severity  Severity          `json:"severity"`
+	Source    string            `json:"source"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// WebhookConfig holds configuration for the generic webhook provider.
+type WebhookConfig struct {
+	// URL is the endpoint the alert is POSTed to as JSON.
+	URL string
+
+	// Token is an optional bearer token sent in the Authorization header.
+	Token string
+}
+
+// Webhook implements the Provider interface by POSTing alerts as JSON to
+// an arbitrary endpoint, for receivers bosun has no dedicated provider for.
+type Webhook struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+// NewWebhook creates a new generic webhook provider.
+func NewWebhook(config WebhookConfig) *Webhook {
+	return &Webhook{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the provider name.
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// IsConfigured returns true if the endpoint URL is set.
+func (w *Webhook) IsConfigured() bool {
+	return w.config.URL != ""
+}
+
+// Send posts an alert to the webhook endpoint. Any 2xx status is success.
+func (w *Webhook) Send(ctx context.Context, alert *Alert) error {
+	if !w.IsConfigured() {
+		return nil
+	}
+
+	body, err := json.Marshal(w.buildPayload(alert))
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.config.Token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Preview renders the JSON body without posting it.
+func (w *Webhook) Preview(alert *Alert) Preview {
+	body, _ := json.MarshalIndent(w.buildPayload(alert), "", "  ")
+
+	return Preview{
+		Provider:   w.Name(),
+		WouldSend:  true,
+		Recipients: []string{"webhook " + MaskWebhookURL(w.config.URL)},
+		Rendered:   string(body),
+	}
+}
+
+// buildPayload converts an alert into the webhook JSON body.
+func (w *Webhook) buildPayload(alert *Alert) webhookPayload {
+	return webhookPayload{
+		Title:     alert.Title,
+		Message:   alert.Message,
+		Severity:  alert.Severity,
+		Source:    alert.Source,
+		Metadata:  alert.Metadata,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
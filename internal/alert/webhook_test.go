@@ -0,0 +1,66 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_IsConfigured(t *testing.T) {
+	assert.True(t, NewWebhook(WebhookConfig{URL: "https://example.com/hook"}).IsConfigured())
+	assert.False(t, NewWebhook(WebhookConfig{}).IsConfigured())
+}
+
+func TestWebhook_Send(t *testing.T) {
+	t.Run("posts alert as JSON", func(t *testing.T) {
+		var received webhookPayload
+		var auth string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			auth = r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		p := NewWebhook(WebhookConfig{URL: server.URL, Token: "s3cret"})
+		err := p.Send(context.Background(), DriftAlert("local", []string{"traefik: image drift"}))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Bearer s3cret", auth)
+		assert.Equal(t, "Config Drift Detected", received.Title)
+		assert.Equal(t, SeverityWarning, received.Severity)
+		assert.Equal(t, "drift", received.Source)
+		assert.Equal(t, "local", received.Metadata["target"])
+		assert.NotEmpty(t, received.Timestamp)
+	})
+
+	t.Run("returns error on non-2xx status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := NewWebhook(WebhookConfig{URL: server.URL}).Send(context.Background(), &Alert{Title: "Test"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status: 500")
+	})
+}
+
+func TestWebhook_Preview(t *testing.T) {
+	p := NewWebhook(WebhookConfig{URL: "https://hooks.example.com/bosun/abc123", Token: "s3cret"})
+	preview := p.Preview(&Alert{Title: "Test", Severity: SeverityInfo, Source: "alert-test"})
+
+	assert.Equal(t, "webhook", preview.Provider)
+	assert.True(t, preview.WouldSend)
+	assert.Equal(t, []string{"webhook https://hooks.example.com/bosun/****"}, preview.Recipients)
+	assert.Contains(t, preview.Rendered, `"title": "Test"`)
+	assert.NotContains(t, preview.Rendered, "s3cret")
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,6 +13,8 @@ import (
 
 	"github.com/cameronsjo/bosun/internal/alert"
 	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/timezone"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -23,7 +27,7 @@ var alertCmd = &cobra.Command{
 
 Commands:
   status    Show which alert providers are configured
-  test      Send test alert to all or specific providers`,
+  test      Preview or send a test alert through the alert manager`,
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
 	},
@@ -37,25 +41,51 @@ var alertStatusCmd = &cobra.Command{
 	Run:   runAlertStatus,
 }
 
-// alertTestCmd sends a test alert.
+// alertTestCmd previews or sends a synthetic alert.
 var alertTestCmd = &cobra.Command{
 	Use:   "test",
-	Short: "Send test alert to configured providers",
-	Long:  "Send a test alert message to verify provider configuration.",
-	Run:   runAlertTest,
+	Short: "Preview or send a test alert through the alert manager",
+	Long: `Route a synthetic event through the alert manager to verify alert
+configuration before a real incident.
+
+By default this is a dry run: it shows which providers would fire and the
+message each would render. Use --send to actually deliver it.
+
+Events:
+  test                Generic test alert (info)
+  drift               Config drift detected (warning)
+  reconcile_failure   Deployment failed (error)
+  digest              Weekly digest from the state store (info)
+  updates             Image updates available (info)
+
+Examples:
+  bosun alert test                              # Dry-run a generic test alert
+  bosun alert test --event reconcile_failure    # See who gets paged on failure
+  bosun alert test --event drift --send         # Deliver a drift alert
+  bosun alert test --event digest               # Preview this week's digest
+  bosun alert test -p discord -m "hello" --send # Send to Discord only
+
+Providers limited by alerts.min_severity in bosun.yml show as not firing
+for events below their minimum.`,
+	Args: cobra.NoArgs,
+	Run:  runAlertTest,
 }
 
 var (
 	alertTestProvider string
 	alertTestMessage  string
 	alertTestSeverity string
+	alertTestEvent    string
+	alertTestSend     bool
 )
 
 func init() {
 	// Add test command flags
-	alertTestCmd.Flags().StringVarP(&alertTestProvider, "provider", "p", "", "Test specific provider (discord, sendgrid, twilio)")
+	alertTestCmd.Flags().StringVarP(&alertTestProvider, "provider", "p", "", "Test specific provider (discord, sendgrid, twilio, slack, ntfy, webhook)")
 	alertTestCmd.Flags().StringVarP(&alertTestMessage, "message", "m", "", "Custom test message")
-	alertTestCmd.Flags().StringVarP(&alertTestSeverity, "severity", "s", "info", "Test severity level (info, warning, error)")
+	alertTestCmd.Flags().StringVarP(&alertTestSeverity, "severity", "s", "", "Override the event severity (info, warning, error, critical)")
+	alertTestCmd.Flags().StringVarP(&alertTestEvent, "event", "e", "test", "Synthetic event (test, drift, reconcile_failure, digest, updates)")
+	alertTestCmd.Flags().BoolVar(&alertTestSend, "send", false, "Deliver the alert instead of a dry run")
 
 	// Add subcommands to alert
 	alertCmd.AddCommand(alertStatusCmd)
@@ -78,23 +108,16 @@ func runAlertStatus(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if err := cfg.AlertSealError(); err != nil {
+		ui.Warning("%v", err)
+		fmt.Println()
+	}
 	alertCfg := cfg.GetAlertConfig()
 	displayAlertStatus(alertCfg)
 }
 
 func displayAlertStatusFromEnv() {
-	// Check environment variables directly
-	alertCfg := config.AlertConfig{}
-	if v := os.Getenv("DISCORD_WEBHOOK_URL"); v != "" {
-		alertCfg.DiscordWebhookURL = v
-	}
-	if v := os.Getenv("SENDGRID_API_KEY"); v != "" {
-		alertCfg.SendGridAPIKey = v
-	}
-	if v := os.Getenv("TWILIO_ACCOUNT_SID"); v != "" {
-		alertCfg.TwilioAccountSID = v
-	}
-	displayAlertStatus(alertCfg)
+	displayAlertStatus(config.EnvAlertConfig())
 }
 
 func displayAlertStatus(alertCfg config.AlertConfig) {
@@ -152,6 +175,49 @@ func displayAlertStatus(alertCfg config.AlertConfig) {
 	}
 	fmt.Println()
 
+	// Slack
+	if alertCfg.SlackWebhookURL != "" {
+		ui.Success("Slack: configured")
+		fmt.Printf("  Webhook URL: %s\n", alert.MaskWebhookURL(alertCfg.SlackWebhookURL))
+		hasProvider = true
+	} else {
+		ui.Warning("Slack: not configured")
+		fmt.Println("  Set SLACK_WEBHOOK_URL or add slack_webhook_url to bosun.yaml")
+	}
+	fmt.Println()
+
+	// ntfy
+	if alertCfg.NtfyTopic != "" {
+		ui.Success("ntfy: configured")
+		server := alertCfg.NtfyServer
+		if server == "" {
+			server = alert.DefaultNtfyServer
+		}
+		fmt.Printf("  Topic: %s/%s\n", strings.TrimRight(server, "/"), alertCfg.NtfyTopic)
+		if alertCfg.NtfyToken != "" {
+			fmt.Println("  Token: set")
+		}
+		hasProvider = true
+	} else {
+		ui.Warning("ntfy: not configured")
+		fmt.Println("  Set NTFY_TOPIC or add ntfy_topic to bosun.yaml")
+	}
+	fmt.Println()
+
+	// Generic webhook
+	if alertCfg.WebhookURL != "" {
+		ui.Success("Webhook: configured")
+		fmt.Printf("  URL: %s\n", alert.MaskWebhookURL(alertCfg.WebhookURL))
+		if alertCfg.WebhookToken != "" {
+			fmt.Println("  Token: set")
+		}
+		hasProvider = true
+	} else {
+		ui.Warning("Webhook: not configured")
+		fmt.Println("  Set ALERT_WEBHOOK_URL or add webhook_url to bosun.yaml")
+	}
+	fmt.Println()
+
 	// Settings
 	ui.Blue.Println("--- Settings ---")
 	fmt.Println()
@@ -165,6 +231,12 @@ func displayAlertStatus(alertCfg config.AlertConfig) {
 	} else {
 		fmt.Println("  Alert on failure: no")
 	}
+	if len(alertCfg.MinSeverity) > 0 {
+		fmt.Println("  Routing:")
+		for _, name := range slices.Sorted(maps.Keys(alertCfg.MinSeverity)) {
+			fmt.Printf("    %s: %s and above\n", name, alertCfg.MinSeverity[name])
+		}
+	}
 	fmt.Println()
 
 	if !hasProvider {
@@ -173,9 +245,6 @@ func displayAlertStatus(alertCfg config.AlertConfig) {
 }
 
 func runAlertTest(cmd *cobra.Command, args []string) {
-	ui.Info("Testing alert providers...")
-	fmt.Println()
-
 	cfg, err := config.Load()
 	if err != nil {
 		ui.Warning("Could not load project config: %v", err)
@@ -185,200 +254,253 @@ func runAlertTest(cmd *cobra.Command, args []string) {
 
 	var alertCfg config.AlertConfig
 	if cfg != nil {
+		if err := cfg.AlertSealError(); err != nil {
+			ui.Warning("%v", err)
+		}
 		alertCfg = cfg.GetAlertConfig()
 	} else {
-		// Load from environment variables only
-		if v := os.Getenv("DISCORD_WEBHOOK_URL"); v != "" {
-			alertCfg.DiscordWebhookURL = v
-		}
-		if v := os.Getenv("SENDGRID_API_KEY"); v != "" {
-			alertCfg.SendGridAPIKey = v
-		}
-		if v := os.Getenv("TWILIO_ACCOUNT_SID"); v != "" {
-			alertCfg.TwilioAccountSID = v
-		}
-		if v := os.Getenv("TWILIO_AUTH_TOKEN"); v != "" {
-			alertCfg.TwilioAuthToken = v
-		}
+		alertCfg = config.EnvAlertConfig()
 	}
 
-	// Determine message
-	message := alertTestMessage
-	if message == "" {
-		message = "This is a test alert from bosun"
-	}
-
-	// Track results
-	tested := 0
-	succeeded := 0
-	failed := 0
-
-	// Test specific provider or all
-	if alertTestProvider == "" || alertTestProvider == "discord" {
-		if alertCfg.DiscordWebhookURL != "" {
-			tested++
-			ui.Info("Testing Discord...")
-			if err := testDiscordAlert(alertCfg.DiscordWebhookURL, message, alertTestSeverity); err != nil {
-				ui.Error("Discord test failed: %v", err)
-				failed++
-			} else {
-				ui.Success("Discord test passed")
-				succeeded++
-			}
-			fmt.Println()
-		} else if alertTestProvider == "discord" {
-			ui.Error("Discord not configured")
-			os.Exit(1)
-		}
+	testAlert, err := syntheticAlert(alertTestEvent, alertTestMessage, alertTestSeverity)
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
 	}
 
-	if alertTestProvider == "" || alertTestProvider == "sendgrid" {
-		if alertCfg.SendGridAPIKey != "" {
-			tested++
-			ui.Info("Testing SendGrid...")
-			if err := testSendGridAlert(alertCfg, message, alertTestSeverity); err != nil {
-				ui.Error("SendGrid test failed: %v", err)
-				failed++
-			} else {
-				ui.Success("SendGrid test passed")
-				succeeded++
-			}
-			fmt.Println()
-		} else if alertTestProvider == "sendgrid" {
-			ui.Error("SendGrid not configured")
-			os.Exit(1)
-		}
+	providers, problems := alertProviders(alertCfg, alertTestProvider)
+	mgr := alert.NewManager()
+	for _, p := range providers {
+		mgr.AddProvider(p)
 	}
 
-	if alertTestProvider == "" || alertTestProvider == "twilio" {
-		if alertCfg.TwilioAccountSID != "" && alertCfg.TwilioAuthToken != "" {
-			tested++
-			ui.Info("Testing Twilio...")
-			if err := testTwilioAlert(alertCfg, message); err != nil {
-				ui.Error("Twilio test failed: %v", err)
-				failed++
-			} else {
-				ui.Success("Twilio test passed")
-				succeeded++
-			}
-			fmt.Println()
-		} else if alertTestProvider == "twilio" {
-			ui.Error("Twilio not configured")
-			os.Exit(1)
+	ui.Blue.Println("--- Event ---")
+	fmt.Printf("  Event:    %s\n", alertTestEvent)
+	fmt.Printf("  Title:    %s\n", testAlert.Title)
+	fmt.Printf("  Severity: %s\n", testAlert.Severity)
+	fmt.Printf("  Source:   %s\n", testAlert.Source)
+	fmt.Println()
+
+	ui.Blue.Println("--- Routing ---")
+	for _, problem := range problems {
+		ui.Yellow.Printf("  ~ %s\n", problem)
+	}
+
+	previews := mgr.Preview(testAlert)
+	firing := 0
+	for _, p := range previews {
+		if !p.WouldSend {
+			ui.Yellow.Printf("  ~ %s would not fire: %s\n", p.Provider, p.Reason)
+			continue
+		}
+		firing++
+		ui.Green.Printf("  * %s would fire", p.Provider)
+		if len(p.Recipients) > 0 {
+			fmt.Printf(" -> %s", strings.Join(p.Recipients, ", "))
 		}
+		fmt.Println()
+		printIndented(p.Rendered, "      ")
 	}
+	fmt.Println()
 
-	// Summary
-	if tested == 0 {
-		ui.Warning("No alert providers configured to test")
+	if firing == 0 {
+		ui.Warning("No alert providers would fire for this event")
 		os.Exit(1)
 	}
 
-	ui.Blue.Println("--- Summary ---")
-	fmt.Printf("  Tested: %d, Passed: %d, Failed: %d\n", tested, succeeded, failed)
+	if !alertTestSend {
+		ui.Info("Dry run: nothing sent. Use --send to deliver.")
+		return
+	}
 
-	if failed > 0 {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ui.Info("Sending through the alert manager...")
+	if err := mgr.Send(ctx, testAlert); err != nil {
+		ui.Error("Delivery failed: %v", err)
 		os.Exit(1)
 	}
+	ui.Success("Delivered to %d provider(s)", firing)
 }
 
-// testDiscordAlert sends a test message to Discord.
-func testDiscordAlert(webhookURL, message, severity string) error {
-	provider := alert.NewDiscordProvider(webhookURL)
-	if !provider.IsConfigured() {
-		return fmt.Errorf("discord webhook URL not configured")
+// syntheticAlert builds the alert for a test event, rendered the same way
+// as the real notification. message and severity override the defaults.
+func syntheticAlert(event, message, severity string) (*alert.Alert, error) {
+	var a *alert.Alert
+	switch event {
+	case "", "test":
+		a = &alert.Alert{
+			Title:    "Test Alert from Bosun",
+			Message:  "This is a test alert from bosun",
+			Severity: alert.SeverityInfo,
+			Source:   "alert-test",
+			Metadata: map[string]string{"time": timezone.Format(time.Now(), time.RFC3339)},
+		}
+	case "drift":
+		a = alert.DriftAlert("local", []string{"traefik: image drift", "authelia: not running"})
+	case "reconcile_failure":
+		a = alert.DeployFailureAlert("0123456789abcdef", "local", "failed to render templates")
+	case "digest":
+		st, err := stateStore("").Load()
+		if err != nil {
+			return nil, err
+		}
+		a = alert.DigestAlert("local", "the last 7 days", st.Digest(time.Now(), daemon.DigestPeriod).Lines())
+	case "updates":
+		a = alert.UpdatesAlert("local", []string{"media/sonarr: lscr.io/linuxserver/sonarr:4.0.2 -> lscr.io/linuxserver/sonarr:4.0.3"})
+	default:
+		return nil, fmt.Errorf("unknown event %q (use test, drift, reconcile_failure, digest, or updates)", event)
 	}
 
-	testAlert := &alert.Alert{
-		Title:    "Test Alert from Bosun",
-		Message:  message,
-		Severity: parseSeverity(severity),
-		Source:   "alert-test",
-		Metadata: map[string]string{
-			"type": "test",
-			"time": time.Now().Format(time.RFC3339),
-		},
+	if message != "" {
+		a.Message = message
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	return provider.Send(ctx, testAlert)
+	if severity != "" {
+		a.Severity = parseSeverity(severity)
+	}
+	a.Metadata["type"] = "test"
+	return a, nil
 }
 
-// testSendGridAlert sends a test email via SendGrid.
-func testSendGridAlert(cfg config.AlertConfig, message, severity string) error {
-	if cfg.SendGridFromEmail == "" {
-		return fmt.Errorf("sendgrid_from_email not configured")
+// alertProviders builds providers from alert config, optionally limited to
+// one provider by name. Providers that are partly configured are left out
+// and described in problems. Providers listed in min_severity only receive
+// alerts at or above that severity.
+func alertProviders(cfg config.AlertConfig, only string) ([]alert.Provider, []string) {
+	var providers []alert.Provider
+	var problems []string
+
+	want := func(name string) bool { return only == "" || only == name }
+
+	if want("discord") {
+		if cfg.DiscordWebhookURL != "" {
+			providers = append(providers, alert.NewDiscordProvider(cfg.DiscordWebhookURL))
+		} else if only == "discord" {
+			problems = append(problems, "discord not configured")
+		}
+	}
+
+	if want("sendgrid") {
+		switch {
+		case cfg.SendGridAPIKey == "":
+			if only == "sendgrid" {
+				problems = append(problems, "sendgrid not configured")
+			}
+		case cfg.SendGridFromEmail == "":
+			problems = append(problems, "sendgrid skipped: sendgrid_from_email not configured")
+		case len(cfg.SendGridToEmails) == 0:
+			problems = append(problems, "sendgrid skipped: sendgrid_to_emails not configured")
+		default:
+			providers = append(providers, alert.NewSendGrid(alert.SendGridConfig{
+				APIKey:    cfg.SendGridAPIKey,
+				FromEmail: cfg.SendGridFromEmail,
+				FromName:  cfg.SendGridFromName,
+				ToEmails:  cfg.SendGridToEmails,
+			}))
+		}
 	}
-	if len(cfg.SendGridToEmails) == 0 {
-		return fmt.Errorf("sendgrid_to_emails not configured")
+
+	if want("twilio") {
+		switch {
+		case cfg.TwilioAccountSID == "" || cfg.TwilioAuthToken == "":
+			if only == "twilio" {
+				problems = append(problems, "twilio not configured")
+			}
+		case cfg.TwilioFromNumber == "":
+			problems = append(problems, "twilio skipped: twilio_from_number not configured")
+		case len(cfg.TwilioToNumbers) == 0:
+			problems = append(problems, "twilio skipped: twilio_to_numbers not configured")
+		default:
+			providers = append(providers, alert.NewTwilio(alert.TwilioConfig{
+				AccountSID: cfg.TwilioAccountSID,
+				AuthToken:  cfg.TwilioAuthToken,
+				FromNumber: cfg.TwilioFromNumber,
+				ToNumbers:  cfg.TwilioToNumbers,
+			}))
+		}
 	}
 
-	provider := alert.NewSendGrid(alert.SendGridConfig{
-		APIKey:    cfg.SendGridAPIKey,
-		FromEmail: cfg.SendGridFromEmail,
-		FromName:  cfg.SendGridFromName,
-		ToEmails:  cfg.SendGridToEmails,
-	})
+	if want("slack") {
+		if cfg.SlackWebhookURL != "" {
+			providers = append(providers, alert.NewSlackProvider(cfg.SlackWebhookURL))
+		} else if only == "slack" {
+			problems = append(problems, "slack not configured")
+		}
+	}
 
-	if !provider.IsConfigured() {
-		return fmt.Errorf("sendgrid not fully configured")
+	if want("ntfy") {
+		if cfg.NtfyTopic != "" {
+			providers = append(providers, alert.NewNtfy(alert.NtfyConfig{
+				Server: cfg.NtfyServer,
+				Topic:  cfg.NtfyTopic,
+				Token:  cfg.NtfyToken,
+			}))
+		} else if only == "ntfy" {
+			problems = append(problems, "ntfy not configured")
+		}
 	}
 
-	testAlert := &alert.Alert{
-		Title:    "Test Alert from Bosun",
-		Message:  message,
-		Severity: parseSeverity(severity),
-		Source:   "alert-test",
-		Metadata: map[string]string{
-			"type": "test",
-			"time": time.Now().Format(time.RFC3339),
-		},
+	if want("webhook") {
+		if cfg.WebhookURL != "" {
+			providers = append(providers, alert.NewWebhook(alert.WebhookConfig{
+				URL:   cfg.WebhookURL,
+				Token: cfg.WebhookToken,
+			}))
+		} else if only == "webhook" {
+			problems = append(problems, "webhook not configured")
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	for i, p := range providers {
+		min, ok := cfg.MinSeverity[p.Name()]
+		if !ok {
+			continue
+		}
+		severity := alert.Severity(strings.ToLower(min))
+		if !alert.ValidSeverity(severity) {
+			problems = append(problems, fmt.Sprintf("%s: unknown min_severity %q, sending all alerts", p.Name(), min))
+			continue
+		}
+		providers[i] = alert.MinSeverity(p, severity)
+	}
 
-	return provider.Send(ctx, testAlert)
+	return providers, problems
 }
 
-// testTwilioAlert sends a test SMS via Twilio.
-func testTwilioAlert(cfg config.AlertConfig, message string) error {
-	if cfg.TwilioFromNumber == "" {
-		return fmt.Errorf("twilio_from_number not configured")
+// projectAlertConfig returns the alert configuration from bosun.yml with
+// environment overrides, or from the environment alone outside a project.
+func projectAlertConfig() config.AlertConfig {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.EnvAlertConfig()
 	}
-	if len(cfg.TwilioToNumbers) == 0 {
-		return fmt.Errorf("twilio_to_numbers not configured")
+	if err := cfg.AlertSealError(); err != nil {
+		ui.Warning("%v", err)
 	}
+	return cfg.GetAlertConfig()
+}
 
-	provider := alert.NewTwilio(alert.TwilioConfig{
-		AccountSID: cfg.TwilioAccountSID,
-		AuthToken:  cfg.TwilioAuthToken,
-		FromNumber: cfg.TwilioFromNumber,
-		ToNumbers:  cfg.TwilioToNumbers,
-	})
-
-	if !provider.IsConfigured() {
-		return fmt.Errorf("twilio not fully configured")
+// newAlertManager creates an alert manager from alert config, warning about
+// providers that are left out.
+func newAlertManager(cfg config.AlertConfig) *alert.Manager {
+	providers, problems := alertProviders(cfg, "")
+	for _, problem := range problems {
+		ui.Warning("Alerts: %s", problem)
 	}
 
-	// Note: Twilio only sends for error/critical severity to minimize costs
-	testAlert := &alert.Alert{
-		Title:    "Test Alert from Bosun",
-		Message:  message,
-		Severity: alert.SeverityError, // Force error severity to ensure SMS is sent
-		Source:   "alert-test",
-		Metadata: map[string]string{
-			"type": "test",
-			"time": time.Now().Format(time.RFC3339),
-		},
+	mgr := alert.NewManager()
+	for _, p := range providers {
+		mgr.AddProvider(p)
 	}
+	return mgr
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	return provider.Send(ctx, testAlert)
+// printIndented prints each line of s with the given prefix.
+func printIndented(s, prefix string) {
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		fmt.Println(prefix + line)
+	}
 }
 
 // parseSeverity converts a string severity to alert.Severity.
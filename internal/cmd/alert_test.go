@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/cameronsjo/bosun/internal/alert"
 	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/state"
 )
 
 func TestAlertCmd_Help(t *testing.T) {
@@ -62,6 +66,14 @@ func TestAlertTestCmd_Flags(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Contains(t, output, "-s, --severity")
 	})
+
+	t.Run("event and send flags", func(t *testing.T) {
+		output, err := executeCmd(t, "alert", "test", "--help")
+		assert.NoError(t, err)
+		assert.Contains(t, output, "-e, --event")
+		assert.Contains(t, output, "reconcile_failure")
+		assert.Contains(t, output, "--send")
+	})
 }
 
 func TestDisplayAlertStatus(t *testing.T) {
@@ -122,85 +134,190 @@ func TestDisplayAlertStatus(t *testing.T) {
 
 		displayAlertStatus(cfg)
 	})
-}
 
-func TestTestDiscordAlert(t *testing.T) {
-	t.Run("returns error with invalid webhook", func(t *testing.T) {
-		// Real API call with invalid URL returns error
-		err := testDiscordAlert("https://discord.com/api/webhooks/test", "test message", "info")
-		assert.Error(t, err)
-	})
+	t.Run("display with notifiers and routing", func(t *testing.T) {
+		cfg := config.AlertConfig{
+			SlackWebhookURL: "https://hooks.slack.com/services/T000/B000/secret",
+			NtfyTopic:       "homelab",
+			NtfyToken:       "tk_secret",
+			WebhookURL:      "https://hooks.example.com/bosun",
+			MinSeverity:     map[string]string{"ntfy": "error", "slack": "warning"},
+			OnFailure:       true,
+		}
 
-	t.Run("returns error if webhook not configured", func(t *testing.T) {
-		err := testDiscordAlert("", "test message", "info")
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "not configured")
+		displayAlertStatus(cfg)
 	})
 }
 
-func TestTestSendGridAlert(t *testing.T) {
-	t.Run("returns error if from_email not set", func(t *testing.T) {
+func TestAlertProviders(t *testing.T) {
+	t.Run("builds fully configured providers", func(t *testing.T) {
 		cfg := config.AlertConfig{
-			SendGridAPIKey: "SG.test",
+			DiscordWebhookURL: "https://discord.com/api/webhooks/1/abc",
+			SendGridAPIKey:    "SG.test",
+			SendGridFromEmail: "from@example.com",
+			SendGridToEmails:  []string{"to@example.com"},
+			TwilioAccountSID:  "AC.test",
+			TwilioAuthToken:   "token",
+			TwilioFromNumber:  "+15551234567",
+			TwilioToNumbers:   []string{"+15559876543"},
 		}
-		err := testSendGridAlert(cfg, "test message", "info")
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "sendgrid_from_email")
+		providers, problems := alertProviders(cfg, "")
+		assert.Len(t, providers, 3)
+		assert.Empty(t, problems)
 	})
 
-	t.Run("returns error if to_emails not set", func(t *testing.T) {
+	t.Run("limits to one provider", func(t *testing.T) {
 		cfg := config.AlertConfig{
+			DiscordWebhookURL: "https://discord.com/api/webhooks/1/abc",
 			SendGridAPIKey:    "SG.test",
-			SendGridFromEmail: "from@example.com",
 		}
-		err := testSendGridAlert(cfg, "test message", "info")
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "sendgrid_to_emails")
+		providers, problems := alertProviders(cfg, "discord")
+		require.Len(t, providers, 1)
+		assert.Equal(t, "discord", providers[0].Name())
+		assert.Empty(t, problems)
 	})
 
-	t.Run("returns error with invalid credentials", func(t *testing.T) {
-		// Real API call with invalid credentials returns error
+	t.Run("reports missing provider when requested", func(t *testing.T) {
+		providers, problems := alertProviders(config.AlertConfig{}, "discord")
+		assert.Empty(t, providers)
+		assert.Equal(t, []string{"discord not configured"}, problems)
+	})
+
+	t.Run("reports sendgrid without from_email", func(t *testing.T) {
+		cfg := config.AlertConfig{
+			SendGridAPIKey: "SG.test",
+		}
+		providers, problems := alertProviders(cfg, "")
+		assert.Empty(t, providers)
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "sendgrid_from_email")
+	})
+
+	t.Run("reports sendgrid without to_emails", func(t *testing.T) {
 		cfg := config.AlertConfig{
 			SendGridAPIKey:    "SG.test",
 			SendGridFromEmail: "from@example.com",
-			SendGridToEmails:  []string{"to@example.com"},
 		}
-		err := testSendGridAlert(cfg, "test message", "info")
-		assert.Error(t, err)
+		_, problems := alertProviders(cfg, "")
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "sendgrid_to_emails")
 	})
-}
 
-func TestTestTwilioAlert(t *testing.T) {
-	t.Run("returns error if from_number not set", func(t *testing.T) {
+	t.Run("reports twilio without from_number", func(t *testing.T) {
 		cfg := config.AlertConfig{
 			TwilioAccountSID: "AC.test",
 			TwilioAuthToken:  "token",
 		}
-		err := testTwilioAlert(cfg, "test message")
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "twilio_from_number")
+		_, problems := alertProviders(cfg, "")
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "twilio_from_number")
 	})
 
-	t.Run("returns error if to_numbers not set", func(t *testing.T) {
+	t.Run("reports twilio without to_numbers", func(t *testing.T) {
 		cfg := config.AlertConfig{
 			TwilioAccountSID: "AC.test",
 			TwilioAuthToken:  "token",
 			TwilioFromNumber: "+15551234567",
 		}
-		err := testTwilioAlert(cfg, "test message")
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "twilio_to_numbers")
+		_, problems := alertProviders(cfg, "")
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "twilio_to_numbers")
 	})
 
-	t.Run("returns error with invalid credentials", func(t *testing.T) {
-		// Real API call with invalid credentials returns error
+	t.Run("builds notifier providers", func(t *testing.T) {
 		cfg := config.AlertConfig{
-			TwilioAccountSID: "AC.test",
-			TwilioAuthToken:  "token",
-			TwilioFromNumber: "+15551234567",
-			TwilioToNumbers:  []string{"+15559876543"},
+			SlackWebhookURL: "https://hooks.slack.com/services/T/B/x",
+			NtfyTopic:       "homelab",
+			WebhookURL:      "https://hooks.example.com/bosun",
+		}
+		providers, problems := alertProviders(cfg, "")
+		assert.Empty(t, problems)
+		names := make([]string, len(providers))
+		for i, p := range providers {
+			names[i] = p.Name()
+		}
+		assert.Equal(t, []string{"slack", "ntfy", "webhook"}, names)
+	})
+
+	t.Run("reports missing notifier when requested", func(t *testing.T) {
+		providers, problems := alertProviders(config.AlertConfig{}, "ntfy")
+		assert.Empty(t, providers)
+		assert.Equal(t, []string{"ntfy not configured"}, problems)
+	})
+
+	t.Run("routes by minimum severity", func(t *testing.T) {
+		cfg := config.AlertConfig{
+			DiscordWebhookURL: "https://discord.com/api/webhooks/1/abc",
+			NtfyTopic:         "homelab",
+			MinSeverity:       map[string]string{"ntfy": "Error"},
+		}
+		providers, problems := alertProviders(cfg, "")
+		assert.Empty(t, problems)
+
+		mgr := alert.NewManager()
+		for _, p := range providers {
+			mgr.AddProvider(p)
+		}
+		previews := mgr.Preview(alert.DriftAlert("local", []string{"traefik: image drift"}))
+		require.Len(t, previews, 2)
+		assert.True(t, previews[0].WouldSend)
+		assert.Equal(t, "ntfy", previews[1].Provider)
+		assert.False(t, previews[1].WouldSend)
+		assert.Equal(t, "routed for error alerts and above, not warning", previews[1].Reason)
+	})
+
+	t.Run("reports unknown minimum severity", func(t *testing.T) {
+		cfg := config.AlertConfig{
+			NtfyTopic:   "homelab",
+			MinSeverity: map[string]string{"ntfy": "urgent"},
 		}
-		err := testTwilioAlert(cfg, "test message")
+		providers, problems := alertProviders(cfg, "")
+		assert.Len(t, providers, 1)
+		assert.Equal(t, []string{`ntfy: unknown min_severity "urgent", sending all alerts`}, problems)
+	})
+}
+
+func TestSyntheticAlert(t *testing.T) {
+	t.Run("reconcile failure matches the real alert", func(t *testing.T) {
+		a, err := syntheticAlert("reconcile_failure", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "Deployment Failed", a.Title)
+		assert.Equal(t, alert.SeverityError, a.Severity)
+		assert.Equal(t, "reconcile", a.Source)
+		assert.Equal(t, "test", a.Metadata["type"])
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		a, err := syntheticAlert("drift", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "drift", a.Source)
+		assert.Equal(t, alert.SeverityWarning, a.Severity)
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("BOSUN_STATE_DIR", dir)
+		require.NoError(t, state.NewStore(dir).Update(func(st *state.State) error {
+			st.Day(time.Now()).Reconciles = 4
+			return nil
+		}))
+
+		a, err := syntheticAlert("digest", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "digest", a.Source)
+		assert.Equal(t, alert.SeverityInfo, a.Severity)
+		assert.Contains(t, a.Message, "Reconciles: 4 (0 failed)")
+	})
+
+	t.Run("overrides message and severity", func(t *testing.T) {
+		a, err := syntheticAlert("test", "hello", "critical")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", a.Message)
+		assert.Equal(t, alert.SeverityCritical, a.Severity)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		_, err := syntheticAlert("meteor", "", "")
 		assert.Error(t, err)
 	})
 }
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// userAliasArgs returns the command line arguments with any user-defined
+// alias from the project config expanded. Outside a project, or without
+// aliases, the arguments are returned unchanged.
+func userAliasArgs(args []string) ([]string, error) {
+	pos, project := commandPosition(rootCmd, args)
+	if pos < 0 {
+		return args, nil
+	}
+	if project != "" {
+		config.SelectProject(project)
+	}
+
+	root, err := config.FindRoot()
+	if err != nil {
+		return args, nil
+	}
+	aliases := config.LoadAliases(root)
+	if _, ok := aliases[args[pos]]; !ok {
+		return args, nil
+	}
+	if isBuiltinCommand(rootCmd, args[pos]) {
+		ui.Warning("Alias %q shadows a built-in command and is ignored", args[pos])
+		return args, nil
+	}
+	return expandAlias(args, pos, aliases)
+}
+
+// expandAlias replaces the alias at args[pos] with the words of its command
+// line. Aliases expand once, so one alias can't refer to another.
+func expandAlias(args []string, pos int, aliases map[string]string) ([]string, error) {
+	name := args[pos]
+	words, err := splitCommandLine(aliases[name])
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", name, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("alias %q is empty", name)
+	}
+
+	expanded := make([]string, 0, len(args)+len(words)-1)
+	expanded = append(expanded, args[:pos]...)
+	expanded = append(expanded, words...)
+	return append(expanded, args[pos+1:]...), nil
+}
+
+// commandPosition returns the index of the command name in args, skipping
+// root flags and their values, or -1 if there is none. It also returns the
+// value of --project, if given, so aliases come from that project.
+func commandPosition(root *cobra.Command, args []string) (int, string) {
+	project := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1, project
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i, project
+		}
+		if strings.Contains(arg, "=") {
+			if name, value, _ := strings.Cut(strings.TrimLeft(arg, "-"), "="); name == "project" {
+				project = value
+			}
+			continue
+		}
+
+		flag := root.PersistentFlags().Lookup(strings.TrimLeft(arg, "-"))
+		if !strings.HasPrefix(arg, "--") && len(arg) == 2 {
+			flag = root.PersistentFlags().ShorthandLookup(arg[1:])
+		}
+		if flag != nil && flag.Value.Type() != "bool" && i+1 < len(args) {
+			i++
+			if flag.Name == "project" {
+				project = args[i]
+			}
+		}
+	}
+	return -1, project
+}
+
+// isBuiltinCommand reports whether name is a bosun command or one of its
+// nautical aliases.
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommandLine splits an alias command line into words. Single and
+// double quotes group words with spaces; there are no escapes or variables.
+func splitCommandLine(line string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+	)
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// executeArgs returns the arguments the root command runs with: os.Args
+// with user aliases expanded. An invalid alias is fatal.
+func executeArgs() []string {
+	args, err := userAliasArgs(os.Args[1:])
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	return args
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"yacht up traefik authelia", []string{"yacht", "up", "traefik", "authelia"}},
+		{"  logs   traefik\t-f ", []string{"logs", "traefik", "-f"}},
+		{`search "media server"`, []string{"search", "media server"}},
+		{`alert test -m 'it''s down'`, []string{"alert", "test", "-m", "its down"}},
+		{`bump app ""`, []string{"bump", "app", ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := splitCommandLine(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := splitCommandLine(`search "media`)
+	assert.ErrorContains(t, err, "unterminated")
+}
+
+func TestCommandPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		pos     int
+		project string
+	}{
+		{"command first", []string{"up", "-f"}, 0, ""},
+		{"after a value flag", []string{"--color", "never", "up"}, 2, ""},
+		{"after flag=value", []string{"--color=never", "up"}, 1, ""},
+		{"project flag", []string{"--project", "vps", "up"}, 2, "vps"},
+		{"project flag=value", []string{"--project=vps", "up"}, 1, "vps"},
+		{"after a bool flag", []string{"--help", "up"}, 1, ""},
+		{"no command", []string{"--version"}, -1, ""},
+		{"no args", nil, -1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, project := commandPosition(rootCmd, tt.args)
+			assert.Equal(t, tt.pos, pos)
+			assert.Equal(t, tt.project, project)
+		})
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"up":    "yacht up traefik authelia",
+		"blank": "  ",
+	}
+
+	t.Run("keeps surrounding args", func(t *testing.T) {
+		got, err := expandAlias([]string{"--color", "never", "up", "-d"}, 2, aliases)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"--color", "never", "yacht", "up", "traefik", "authelia", "-d"}, got)
+	})
+
+	t.Run("empty alias", func(t *testing.T) {
+		_, err := expandAlias([]string{"blank"}, 0, aliases)
+		assert.ErrorContains(t, err, `alias "blank" is empty`)
+	})
+}
+
+func TestIsBuiltinCommand(t *testing.T) {
+	assert.True(t, isBuiltinCommand(rootCmd, "yacht"))
+	assert.True(t, isBuiltinCommand(rootCmd, "hoist"), "nautical aliases are built in")
+	assert.True(t, isBuiltinCommand(rootCmd, "help"))
+	assert.False(t, isBuiltinCommand(rootCmd, "up"))
+}
+
+func TestUserAliasArgs(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "manifest"), 0755))
+	config := "aliases:\n  up: yacht up traefik authelia\n  status: yacht status\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bosun.yml"), []byte(config), 0644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(root))
+	defer func() { _ = os.Chdir(wd) }()
+
+	t.Run("expands an alias", func(t *testing.T) {
+		got, err := userAliasArgs([]string{"up"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"yacht", "up", "traefik", "authelia"}, got)
+	})
+
+	t.Run("built-in commands win", func(t *testing.T) {
+		got, err := userAliasArgs([]string{"status"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"status"}, got)
+	})
+
+	t.Run("other commands are untouched", func(t *testing.T) {
+		got, err := userAliasArgs([]string{"lint", "--strict"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"lint", "--strict"}, got)
+	})
+}
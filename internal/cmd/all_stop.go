@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DefaultAllStopWindow is how long an all-stop lasts without --for.
+const DefaultAllStopWindow = time.Hour
+
+var (
+	maydayAllStop  bool
+	maydayFor      time.Duration
+	maydayReason   string
+	maydayResume   bool
+	maydayStateDir string
+)
+
+// allStopActor names who is running the command for the audit log: the
+// invoking user (the sudo caller when run under sudo) at this host.
+func allStopActor() string {
+	name := os.Getenv("SUDO_USER")
+	if name == "" {
+		if u, err := user.Current(); err == nil {
+			name = u.Username
+		} else {
+			name = os.Getenv("USER")
+		}
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return name
+}
+
+// runAllStop pauses all automation for window, or extends the all-stop in
+// force.
+func runAllStop(window time.Duration, reason string) error {
+	if window <= 0 {
+		return fmt.Errorf("--for must be positive, got %s", window)
+	}
+
+	now := time.Now()
+	var extended bool
+	err := stateStore(maydayStateDir).Update(func(st *state.State) error {
+		extended = st.StartAllStop(now, window, allStopActor(), reason)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	until := timezone.Display(now.Add(window))
+	if extended {
+		ui.Mayday("ALL STOP extended until %s", until)
+	} else {
+		ui.Mayday("ALL STOP until %s", until)
+	}
+	fmt.Println("  Polling, webhooks, and scheduled tasks are paused; manual triggers still run.")
+	fmt.Println("  Automation resumes on its own when the window ends.")
+	fmt.Println("  Extend: bosun mayday --all-stop --for <duration>")
+	fmt.Println("  Resume: bosun mayday --resume")
+	return nil
+}
+
+// runAllStopResume lifts the all-stop in force.
+func runAllStopResume(reason string) error {
+	var ended bool
+	err := stateStore(maydayStateDir).Update(func(st *state.State) error {
+		ended = st.EndAllStop(time.Now(), allStopActor(), reason)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !ended {
+		return fmt.Errorf("no all-stop is in force")
+	}
+	ui.Success("All-stop lifted; automation resumed")
+	return nil
+}
+
+// showAllStop prints the all-stop section of the status dashboard. Prints
+// nothing when no all-stop is in force.
+func showAllStop() {
+	st, err := stateStore("").Load()
+	if err != nil {
+		return
+	}
+	stop := st.ActiveAllStop(time.Now())
+	if stop == nil {
+		return
+	}
+
+	fmt.Println()
+	ui.Blue.Println("--- All Stop ---")
+	ui.Red.Printf("  x Automation paused until %s by %s\n", timezone.Display(stop.Until), stop.By)
+	if stop.Reason != "" {
+		fmt.Printf("      Reason: %s\n", stop.Reason)
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestRunAllStop(t *testing.T) {
+	dir := t.TempDir()
+	prev := maydayStateDir
+	maydayStateDir = dir
+	t.Cleanup(func() { maydayStateDir = prev })
+
+	assert.Error(t, runAllStop(0, ""))
+	assert.Error(t, runAllStopResume(""), "nothing to resume")
+
+	require.NoError(t, runAllStop(time.Hour, "db corruption"))
+	require.NoError(t, runAllStop(2*time.Hour, ""))
+
+	st, err := state.NewStore(dir).Load()
+	require.NoError(t, err)
+	stop := st.ActiveAllStop(time.Now())
+	require.NotNil(t, stop)
+	assert.Equal(t, "db corruption", stop.Reason)
+	assert.Equal(t, allStopActor(), stop.By)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), stop.Until, time.Minute)
+
+	require.NoError(t, runAllStopResume("restored"))
+	st, err = state.NewStore(dir).Load()
+	require.NoError(t, err)
+	assert.Nil(t, st.AllStop)
+	require.Len(t, st.Audit, 3)
+	assert.Equal(t, state.AuditResume, st.Audit[2].Action)
+	assert.Equal(t, "restored", st.Audit[2].Reason)
+}
+
+func TestAllStopActor(t *testing.T) {
+	t.Setenv("SUDO_USER", "ops")
+	assert.Regexp(t, `^ops(@.+)?$`, allStopActor())
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	buildBuildKit bool
+	buildCacheDir string
+	buildForce    bool
+	buildDryRun   bool
+	buildRemote   string
+)
+
+// buildCmd builds images for services with a build context.
+var buildCmd = &cobra.Command{
+	Use:     "build [service]",
+	Aliases: []string{"shipwright"},
+	Short:   "Build images for services built from source",
+	Long: `Build images for rendered services that have a build: section.
+
+Each image is tagged with its compose image name and with the git tree hash
+of its build context (e.g. bosun.local/myapp:3f9c2e1a7b4d), so an unchanged
+context is never rebuilt. Uncommitted changes are built but do not change
+the tag; use --force to rebuild them. Reconciles build the same way before
+deploying, recreating only services whose context changed.
+
+Run 'bosun provision' first; builds are read from the rendered compose files.
+
+Examples:
+  bosun build                         # Build every service with a build context
+  bosun build myapp                   # Build one service
+  bosun build --cache .buildcache     # BuildKit with a local layer cache
+  bosun build --remote root@tower     # Build for the deploy host (see BOSUN_IMAGE_DISTRIBUTION)
+  bosun build -n                      # Show what would be built`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBuild,
+}
+
+func init() {
+	buildCmd.Flags().BoolVar(&buildBuildKit, "buildkit", false, "Build with docker buildx (BuildKit)")
+	buildCmd.Flags().StringVar(&buildCacheDir, "cache", "", "Local BuildKit layer cache directory (implies --buildkit; default: $BOSUN_BUILD_CACHE)")
+	buildCmd.Flags().BoolVarP(&buildForce, "force", "f", false, "Rebuild even if an image for the context exists")
+	buildCmd.Flags().BoolVarP(&buildDryRun, "dry-run", "n", false, "Show what would be built without building")
+	buildCmd.Flags().StringVar(&buildRemote, "remote", "", "Build for a remote Docker host over SSH (e.g., root@192.168.1.8)")
+
+	rootCmd.AddCommand(buildCmd)
+}
+
+func runBuild(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	specs, err := findProjectBuilds(composeDir)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		var matched []reconcile.BuildSpec
+		for _, spec := range specs {
+			if spec.Service == args[0] {
+				matched = append(matched, spec)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("no build context for service %s in %s (run 'bosun provision' first?)", args[0], composeDir)
+		}
+		specs = matched
+	}
+	if len(specs) == 0 {
+		ui.Info("No services with a build context in %s", composeDir)
+		return nil
+	}
+
+	opts := reconcile.BuildOptions{BuildKit: buildBuildKit, CacheDir: buildCacheDir, Force: buildForce}
+	if opts.CacheDir == "" {
+		opts.CacheDir = os.Getenv("BOSUN_BUILD_CACHE")
+	}
+	deploy := reconcile.NewDeployOps(buildDryRun)
+
+	// With --remote, ship images the same way reconciles do.
+	distribution, registry := os.Getenv("BOSUN_IMAGE_DISTRIBUTION"), os.Getenv("BOSUN_REGISTRY")
+	if err := reconcile.ValidateImageDistribution(distribution, registry); err != nil {
+		return err
+	}
+
+	ui.Blue.Println("--- Building Images ---")
+	var failed int
+	for _, spec := range specs {
+		ref, built, err := deploy.DistributeImage(cmd.Context(), buildRemote, spec, opts, distribution, registry)
+		switch {
+		case err != nil:
+			ui.Red.Printf("  x %s: %v\n", spec.Service, err)
+			failed++
+		case buildDryRun:
+			ui.Yellow.Printf("  ~ %s: would build %s from %s\n", spec.Service, ref, spec.Context)
+		case built:
+			ui.Green.Printf("  * %s: built %s\n", spec.Service, ref)
+		default:
+			ui.Green.Printf("  * %s: %s is up to date\n", spec.Service, ref)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d builds failed", failed, len(specs))
+	}
+	return nil
+}
+
+// findProjectBuilds returns the services with a build section across the
+// rendered compose files in composeDir.
+func findProjectBuilds(composeDir string) ([]reconcile.BuildSpec, error) {
+	files, err := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []reconcile.BuildSpec
+	for _, file := range files {
+		found, err := reconcile.FindBuilds(file, composeDir)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, found...)
+	}
+	return specs, nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "build", "--help")
+	require.NoError(t, err)
+	assert.Contains(t, output, "git tree hash")
+	assert.Contains(t, output, "--cache")
+}
+
+func TestRunBuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	composeDir := filepath.Join(tmpDir, "manifest", "output", "compose")
+	require.NoError(t, os.MkdirAll(composeDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "apps.yml"), []byte("services:\n  redis:\n    image: redis:7\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bosun.yaml"), []byte("root: .\nmanifest_dir: manifest\n"), 0644))
+
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	t.Run("nothing to build", func(t *testing.T) {
+		require.NoError(t, runBuild(buildCmd, nil))
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		err := runBuild(buildCmd, []string{"redis"})
+		assert.ErrorContains(t, err, "no build context for service redis")
+	})
+
+	t.Run("finds builds across stacks", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(composeDir, "web.yml"), []byte("services:\n  web:\n    image: bosun.local/web\n    build: ../../../images/web\n"), 0644))
+
+		specs, err := findProjectBuilds(composeDir)
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+		assert.Equal(t, "web", specs[0].Service)
+		assert.Equal(t, filepath.Join(tmpDir, "images", "web"), specs[0].Context)
+	})
+}
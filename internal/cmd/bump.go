@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/lint"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	bumpSidecar string
+	bumpDryRun  bool
+	bumpPR      bool
+
+	// prBase and prForgeAPI set up the pull requests bump and updates open.
+	prBase     string
+	prForgeAPI string
+)
+
+// bumpCmd updates a service's image tag in its manifest.
+var bumpCmd = &cobra.Command{
+	Use:     "bump <service> <tag>",
+	Aliases: []string{"refit"},
+	Short:   "Update a service's image tag in its manifest",
+	Long: `Bump sets the image tag of a service manifest, lints the rendered result,
+and shows the render diff. Only the image value in the manifest changes;
+comments and formatting are kept.
+
+The image must be a literal reference at config.image (services.<name>.image
+for sidecars, compose.<service>.image for raw manifests). The tag
+may include a digest: 1.2.3@sha256:..., or @sha256:... to pin the current tag.
+
+With --pr, the change is committed on a new branch, pushed to origin, and a
+pull request is opened through the GitHub or Gitea/Forgejo API using
+$BOSUN_FORGE_TOKEN, $GITHUB_TOKEN, or $GITEA_TOKEN.
+
+Examples:
+  bosun bump sonarr 4.0.2
+  bosun bump sonarr 4.0.2 --dry-run
+  bosun bump immich 16-alpine --sidecar postgres
+  bosun bump sonarr 4.0.2 --pr`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBump,
+}
+
+func init() {
+	bumpCmd.Flags().StringVar(&bumpSidecar, "sidecar", "", "Bump a sidecar's image (services.<name>.image) instead of the service's")
+	bumpCmd.Flags().BoolVarP(&bumpDryRun, "dry-run", "n", false, "Show the diff without changing the manifest")
+	bumpCmd.Flags().BoolVar(&bumpPR, "pr", false, "Commit on a new branch, push, and open a pull request")
+	bumpCmd.Flags().StringVar(&prBase, "base", "", "Base branch for the pull request (default: current branch)")
+	bumpCmd.Flags().StringVar(&prForgeAPI, "forge-api", "", "Forge API base URL (default: derived from the origin remote)")
+
+	rootCmd.AddCommand(bumpCmd)
+}
+
+func runBump(cmd *cobra.Command, args []string) error {
+	service, tag := args[0], args[1]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	path := filepath.Join(cfg.ServicesDir(), service+".yml")
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("service not found: %s", service)
+	}
+
+	after, oldImage, err := manifest.SetServiceImage(before, bumpSidecar, tag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	newImage, _ := manifest.WithTag(oldImage, tag)
+	if bytes.Equal(before, after) {
+		ui.Info("%s is already at %s", service, newImage)
+		return nil
+	}
+
+	ui.Blue.Printf("Bumping %s: %s -> %s\n", service, oldImage, newImage)
+
+	loadHostFacts(cmd.Context())
+	loadServicePorts(cfg.ServicesDir())
+	diff, lintErrors, err := bumpRenderDiff(before, after, cfg.ProvisionsDir())
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if diff == "" {
+		fmt.Println("  Rendered output unchanged")
+	} else {
+		printUnifiedDiff(diff)
+	}
+
+	if len(lintErrors) > 0 {
+		fmt.Println()
+		for _, e := range lintErrors {
+			ui.Red.Printf("  x %s\n", e)
+		}
+		return fmt.Errorf("lint failed for %s at %s; manifest not changed", service, newImage)
+	}
+
+	if bumpDryRun {
+		fmt.Println()
+		ui.Info("Dry run: manifest not changed")
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, after, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	fmt.Println()
+	ui.Success("Updated %s", path)
+
+	if !bumpPR {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), DefaultOperationTimeout*2)
+	defer cancel()
+	url, err := openBumpPR(ctx, cfg.Root, path, service, newImage)
+	if err != nil {
+		return fmt.Errorf("open pull request: %w", err)
+	}
+	ui.Success("Opened pull request: %s", url)
+	return nil
+}
+
+// bumpRenderDiff renders the service before and after the bump and returns
+// a unified diff of the output plus lint errors in the new rendered compose.
+func bumpRenderDiff(before, after []byte, provisionsDir string) (string, []string, error) {
+	render := func(source []byte) (*manifest.ServiceManifest, string, *manifest.RenderOutput, error) {
+		var m manifest.ServiceManifest
+		if err := yaml.Unmarshal(source, &m); err != nil {
+			return nil, "", nil, fmt.Errorf("parse manifest: %w", err)
+		}
+		output, err := manifest.RenderService(&m, provisionsDir)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("render service: %w", err)
+		}
+		text, err := manifest.RenderToYAML(output)
+		return &m, text, output, err
+	}
+
+	_, oldText, _, err := render(before)
+	if err != nil {
+		return "", nil, err
+	}
+	m, newText, output, err := render(after)
+	if err != nil {
+		return "", nil, err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(oldText),
+		B:        difflib.SplitLines(newText),
+		FromFile: "before",
+		ToFile:   "after",
+		Context:  2,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var lintErrors []string
+	issues, err := manifest.LintServiceVariables(m, provisionsDir)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, issue := range issues {
+		lintErrors = append(lintErrors, issue.String())
+	}
+
+	// Lint the rendered compose the same way reconcile would.
+	dir, err := os.MkdirTemp("", "bosun-bump-*")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(dir)
+	composeData, err := yaml.Marshal(output.Compose)
+	if err != nil {
+		return "", nil, err
+	}
+	composeFile := filepath.Join(dir, m.Name+".yml")
+	if err := os.WriteFile(composeFile, composeData, 0644); err != nil {
+		return "", nil, err
+	}
+	result, err := lint.ComposeFiles([]string{composeFile})
+	if err != nil {
+		return "", nil, err
+	}
+	for _, finding := range result.Errors() {
+		lintErrors = append(lintErrors, finding.String())
+	}
+
+	return diff, lintErrors, nil
+}
+
+// printUnifiedDiff prints a unified diff with added and removed lines colored.
+func printUnifiedDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			ui.Bold.Println(line)
+		case strings.HasPrefix(line, "+"):
+			ui.Green.Println(line)
+		case strings.HasPrefix(line, "-"):
+			ui.Red.Println(line)
+		case strings.HasPrefix(line, "@@"):
+			ui.Cyan.Println(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// openBumpPR commits the manifest on a new branch, pushes it, opens a pull
+// request, and switches back to the original branch.
+func openBumpPR(ctx context.Context, root, path, service, image string) (string, error) {
+	_, tag := splitRef(image)
+	return openManifestPR(ctx, root, manifestPR{
+		Paths:  []string{path},
+		Branch: "bosun/bump-" + service + "-" + branchSafe(tag),
+		Title:  fmt.Sprintf("Bump %s to %s", service, tag),
+		Body:   fmt.Sprintf("Updates `%s` to `%s`.\n\nOpened by `bosun bump`; lint and render diff passed.", service, image),
+	})
+}
+
+// manifestPR is a manifest change to propose as a pull request.
+type manifestPR struct {
+	Paths  []string // Changed files to commit
+	Branch string
+	Title  string // Also the commit message
+	Body   string
+}
+
+// openManifestPR commits the changed manifests on a new branch, pushes it,
+// opens a pull request against --base (default: the current branch), and
+// switches back to the original branch.
+func openManifestPR(ctx context.Context, root string, change manifestPR) (string, error) {
+	git := func(args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	token := firstEnv("BOSUN_FORGE_TOKEN", "GITHUB_TOKEN", "GITEA_TOKEN")
+	if token == "" {
+		return "", fmt.Errorf("no forge token: set BOSUN_FORGE_TOKEN, GITHUB_TOKEN, or GITEA_TOKEN")
+	}
+	remote, err := git("remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+	api, owner, repo, err := forgeRepo(remote)
+	if err != nil {
+		return "", err
+	}
+	if prForgeAPI != "" {
+		api = strings.TrimSuffix(prForgeAPI, "/")
+	}
+
+	current, err := git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	base := prBase
+	if base == "" {
+		base = current
+	}
+
+	if _, err := git("checkout", "-b", change.Branch); err != nil {
+		return "", err
+	}
+	defer func() { _, _ = git("checkout", current) }()
+
+	if _, err := git(append([]string{"add"}, change.Paths...)...); err != nil {
+		return "", err
+	}
+	if _, err := git("commit", "-m", change.Title); err != nil {
+		return "", err
+	}
+	if _, err := git("push", "-u", "origin", change.Branch); err != nil {
+		return "", err
+	}
+
+	return createPullRequest(ctx, api, owner, repo, token, pullRequest{Title: change.Title, Head: change.Branch, Base: base, Body: change.Body})
+}
+
+// pullRequest is the request body for creating a pull request. GitHub and
+// Gitea/Forgejo accept the same fields.
+type pullRequest struct {
+	Title string `json:"title"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Body  string `json:"body"`
+}
+
+// createPullRequest opens a pull request and returns its web URL.
+func createPullRequest(ctx context.Context, api, owner, repo, token string, pr pullRequest) (string, error) {
+	payload, err := json.Marshal(pr)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/repos/%s/%s/pulls", api, owner, repo), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "token "+token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		HTMLURL string `json:"html_url"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("forge returned %d: %s", resp.StatusCode, result.Message)
+	}
+	return result.HTMLURL, nil
+}
+
+// remotePattern matches scp-style (git@host:owner/repo.git) and URL-style
+// (https://host/owner/repo, ssh://git@host:22/owner/repo.git) remotes.
+var remotePattern = regexp.MustCompile(`^(?:[a-z+]+://)?(?:[^@/]+@)?([^/:]+)(?::\d+)?[:/](.+?)/([^/]+?)(?:\.git)?/?$`)
+
+// forgeRepo derives the forge API base URL, owner, and repo from a remote
+// URL: api.github.com for github.com, otherwise the Gitea/Forgejo API.
+func forgeRepo(remote string) (api, owner, repo string, err error) {
+	m := remotePattern.FindStringSubmatch(remote)
+	if m == nil {
+		return "", "", "", fmt.Errorf("cannot parse remote %q", remote)
+	}
+	host, owner, repo := m[1], m[2], m[3]
+	if host == "github.com" {
+		return "https://api.github.com", owner, repo, nil
+	}
+	return "https://" + host + "/api/v1", owner, repo, nil
+}
+
+// splitRef splits an image reference into name and tag (with any digest).
+func splitRef(image string) (name, tag string) {
+	ref := image
+	if i := strings.Index(ref, "@"); i != -1 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, image[len(ref):]
+}
+
+// branchSafe makes s usable in a branch name.
+func branchSafe(s string) string {
+	return strings.NewReplacer("@", "-", ":", "-").Replace(s)
+}
+
+// firstEnv returns the first non-empty environment variable among names.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBumpProject creates a project with one service using a container provision.
+func setupBumpProject(t *testing.T, service string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	servicesDir := filepath.Join(tmpDir, "manifest", "services")
+	provisionsDir := filepath.Join(tmpDir, "manifest", "provisions")
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	require.NoError(t, os.MkdirAll(provisionsDir, 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(provisionsDir, "container.yml"), []byte(`compose:
+  services:
+    ${name}:
+      image: ${image}
+      container_name: ${name}
+      restart: unless-stopped
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "sonarr.yml"), []byte(service), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bosun.yaml"), []byte("root: .\nmanifest_dir: manifest\n"), 0644))
+
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+	return filepath.Join(servicesDir, "sonarr.yml")
+}
+
+func TestRunBump(t *testing.T) {
+	service := "name: sonarr\nprovisions: [container]\nconfig:\n  image: lscr.io/linuxserver/sonarr:4.0.1 # keep\n"
+
+	t.Run("dry run leaves manifest unchanged", func(t *testing.T) {
+		path := setupBumpProject(t, service)
+		bumpDryRun = true
+		defer func() { bumpDryRun = false }()
+
+		require.NoError(t, runBump(bumpCmd, []string{"sonarr", "4.0.2"}))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, service, string(data))
+	})
+
+	t.Run("updates the image tag", func(t *testing.T) {
+		path := setupBumpProject(t, service)
+
+		require.NoError(t, runBump(bumpCmd, []string{"sonarr", "4.0.2"}))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "image: lscr.io/linuxserver/sonarr:4.0.2 # keep")
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		setupBumpProject(t, service)
+		err := runBump(bumpCmd, []string{"radarr", "5"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "service not found")
+	})
+}
+
+func TestForgeRepo(t *testing.T) {
+	tests := []struct {
+		remote, api, owner, repo string
+	}{
+		{"git@github.com:cameronsjo/infra.git", "https://api.github.com", "cameronsjo", "infra"},
+		{"https://github.com/cameronsjo/infra", "https://api.github.com", "cameronsjo", "infra"},
+		{"ssh://git@git.home.lan:2222/ops/homelab.git", "https://git.home.lan/api/v1", "ops", "homelab"},
+		{"https://git.home.lan/ops/homelab.git", "https://git.home.lan/api/v1", "ops", "homelab"},
+	}
+	for _, tt := range tests {
+		api, owner, repo, err := forgeRepo(tt.remote)
+		require.NoError(t, err, tt.remote)
+		assert.Equal(t, []string{tt.api, tt.owner, tt.repo}, []string{api, owner, repo}, tt.remote)
+	}
+
+	_, _, _, err := forgeRepo("not a remote")
+	assert.Error(t, err)
+}
+
+func TestSplitRef(t *testing.T) {
+	name, tag := splitRef("ghcr.io/org/app:1.2@sha256:abc")
+	assert.Equal(t, "ghcr.io/org/app", name)
+	assert.Equal(t, "1.2@sha256:abc", tag)
+
+	name, tag = splitRef("registry:5000/app")
+	assert.Equal(t, "registry:5000/app", name)
+	assert.Equal(t, "", tag)
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/ops/homelab/pulls", r.URL.Path)
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+
+		var pr pullRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pr))
+		assert.Equal(t, "bosun/bump-sonarr-4.0.2", pr.Head)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"html_url": "https://git.home.lan/ops/homelab/pulls/7"})
+	}))
+	defer server.Close()
+
+	url, err := createPullRequest(context.Background(), server.URL, "ops", "homelab", "secret",
+		pullRequest{Title: "Bump sonarr to 4.0.2", Head: "bosun/bump-sonarr-4.0.2", Base: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://git.home.lan/ops/homelab/pulls/7", url)
+}
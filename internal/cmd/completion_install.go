@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var completionInstallPath string
+
+// completionInstallCmd writes the completion script where the shell loads it.
+var completionInstallCmd = &cobra.Command{
+	Use:   "install [bash|zsh|fish]",
+	Short: "Install shell completion for the current user",
+	Long: `Install writes the completion script to the per-user location the shell
+loads completions from, so they work in every new session. The shell is
+detected from $SHELL unless given.
+
+  bash  ~/.local/share/bash-completion/completions/bosun (needs bash-completion)
+  zsh   ~/.local/share/zsh/site-functions/_bosun (must be on $fpath)
+  fish  ~/.config/fish/completions/bosun.fish
+
+Run it again after upgrading bosun to pick up new commands.
+
+Examples:
+  bosun completion install
+  bosun completion install zsh
+  bosun completion install bash --path /etc/bash_completion.d/bosun`,
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	RunE:      runCompletionInstall,
+}
+
+func init() {
+	completionInstallCmd.Flags().StringVar(&completionInstallPath, "path", "", "Write the script here instead of the default location")
+	completionCmd.AddCommand(completionInstallCmd)
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) error {
+	shell := detectShell()
+	if len(args) > 0 {
+		shell = args[0]
+	}
+	if shell == "" {
+		return fmt.Errorf("cannot detect shell from $SHELL; pass one of: bash, zsh, fish")
+	}
+
+	dest := completionInstallPath
+	if dest == "" {
+		paths := completionPaths(shell)
+		if len(paths) == 0 {
+			return fmt.Errorf("install is not supported for %s; see 'bosun completion --help'", shell)
+		}
+		dest = paths[0]
+	}
+
+	var script bytes.Buffer
+	if err := generateCompletion(cmd.Root(), shell, &script); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(dest), err)
+	}
+	if err := os.WriteFile(dest, script.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write completion script: %w", err)
+	}
+
+	ui.Success("Installed %s completion to %s", shell, dest)
+	switch shell {
+	case "zsh":
+		ui.Info("Make sure %s is on $fpath before compinit, e.g. in ~/.zshrc:", filepath.Dir(dest))
+		fmt.Printf("    fpath=(%s $fpath)\n", filepath.Dir(dest))
+		fmt.Println("    autoload -U compinit; compinit")
+	case "bash":
+		ui.Info("Requires the bash-completion package; start a new shell to use it")
+	default:
+		ui.Info("Start a new shell to use it")
+	}
+	return nil
+}
+
+// generateCompletion writes the completion script for shell to w.
+func generateCompletion(root *cobra.Command, shell string, w *bytes.Buffer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	default:
+		return fmt.Errorf("unsupported shell %q (use bash, zsh, or fish)", shell)
+	}
+}
+
+// detectShell returns the user's shell name from $SHELL, or "" if unset.
+func detectShell() string {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		return ""
+	}
+	return filepath.Base(shell)
+}
+
+// completionPaths lists where a shell loads bosun completion from. The
+// first entry is the per-user location install writes to; the rest are
+// system locations used by package managers.
+func completionPaths(shell string) []string {
+	home, _ := os.UserHomeDir()
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		return []string{
+			filepath.Join(dataHome, "bash-completion", "completions", "bosun"),
+			"/etc/bash_completion.d/bosun",
+			"/usr/share/bash-completion/completions/bosun",
+			"/usr/local/etc/bash_completion.d/bosun",
+			"/opt/homebrew/etc/bash_completion.d/bosun",
+		}
+	case "zsh":
+		return []string{
+			filepath.Join(dataHome, "zsh", "site-functions", "_bosun"),
+			"/usr/local/share/zsh/site-functions/_bosun",
+			"/usr/share/zsh/site-functions/_bosun",
+			"/usr/share/zsh/vendor-completions/_bosun",
+			"/opt/homebrew/share/zsh/site-functions/_bosun",
+		}
+	case "fish":
+		return []string{
+			filepath.Join(configHome, "fish", "completions", "bosun.fish"),
+			"/usr/share/fish/vendor_completions.d/bosun.fish",
+			"/usr/local/share/fish/vendor_completions.d/bosun.fish",
+			"/opt/homebrew/share/fish/vendor_completions.d/bosun.fish",
+		}
+	}
+	return nil
+}
+
+// shellRCFiles lists startup files that may load completion inline,
+// e.g. with "source <(bosun completion bash)".
+func shellRCFiles(shell string) []string {
+	home, _ := os.UserHomeDir()
+	switch shell {
+	case "bash":
+		return []string{filepath.Join(home, ".bashrc"), filepath.Join(home, ".bash_profile")}
+	case "zsh":
+		zdotdir := os.Getenv("ZDOTDIR")
+		if zdotdir == "" {
+			zdotdir = home
+		}
+		return []string{filepath.Join(zdotdir, ".zshrc")}
+	case "fish":
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return []string{filepath.Join(configHome, "fish", "config.fish")}
+	}
+	return nil
+}
+
+// findCompletion returns where bosun completion is installed for shell,
+// or "" if it is not.
+func findCompletion(shell string) string {
+	for _, path := range completionPaths(shell) {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	for _, rc := range shellRCFiles(shell) {
+		data, err := os.ReadFile(rc)
+		if err == nil && strings.Contains(string(data), "bosun completion") {
+			return rc
+		}
+	}
+	return ""
+}
+
+// checkCompletion reports whether shell completion is installed for the
+// user's shell. Skipped when the shell is unknown or unsupported.
+func checkCompletion() CheckResult {
+	shell := detectShell()
+	if completionPaths(shell) == nil {
+		return CheckResult{}
+	}
+
+	if path := findCompletion(shell); path != "" {
+		ui.Green.Printf("  * Shell completion installed (%s)\n", path)
+		return CheckResult{Passed: 1}
+	}
+	ui.Yellow.Printf("  ! Shell completion not installed for %s\n", shell)
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Println("      - Run: bosun completion install")
+	return CheckResult{Warned: 1}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionPaths(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/xdg/data")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+
+	assert.Equal(t, "/xdg/data/bash-completion/completions/bosun", completionPaths("bash")[0])
+	assert.Equal(t, "/xdg/data/zsh/site-functions/_bosun", completionPaths("zsh")[0])
+	assert.Equal(t, "/xdg/config/fish/completions/bosun.fish", completionPaths("fish")[0])
+	assert.Nil(t, completionPaths("tcsh"))
+}
+
+func TestRunCompletionInstall(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	t.Run("installs for detected shell", func(t *testing.T) {
+		t.Setenv("SHELL", "/bin/bash")
+		require.NoError(t, runCompletionInstall(completionInstallCmd, nil))
+
+		data, err := os.ReadFile(completionPaths("bash")[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "bash completion V2 for bosun")
+	})
+
+	t.Run("custom path", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "nested", "_bosun")
+		completionInstallPath = dest
+		defer func() { completionInstallPath = "" }()
+
+		require.NoError(t, runCompletionInstall(completionInstallCmd, []string{"zsh"}))
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "#compdef bosun")
+	})
+
+	t.Run("unknown shell", func(t *testing.T) {
+		t.Setenv("SHELL", "")
+		err := runCompletionInstall(completionInstallCmd, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot detect shell")
+	})
+}
+
+func TestCheckCompletion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("ZDOTDIR", "")
+
+	t.Run("skipped for unknown shell", func(t *testing.T) {
+		t.Setenv("SHELL", "")
+		assert.Equal(t, CheckResult{}, checkCompletion())
+	})
+
+	t.Run("warns when missing", func(t *testing.T) {
+		t.Setenv("SHELL", "/usr/bin/fish")
+		assert.Equal(t, CheckResult{Warned: 1}, checkCompletion())
+	})
+
+	t.Run("passes when installed", func(t *testing.T) {
+		t.Setenv("SHELL", "/usr/bin/fish")
+		path := completionPaths("fish")[0]
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("complete -c bosun"), 0644))
+		assert.Equal(t, CheckResult{Passed: 1}, checkCompletion())
+	})
+
+	t.Run("passes when loaded from rc file", func(t *testing.T) {
+		t.Setenv("SHELL", "/bin/zsh")
+		require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), []byte("source <(bosun completion zsh)\n"), 0644))
+		assert.Equal(t, CheckResult{Passed: 1}, checkCompletion())
+	})
+}
+
+func TestCompletionInstallCmd_Help(t *testing.T) {
+	resetRootCmd(t)
+	output, err := executeCmd(t, "completion", "install", "--help")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Install writes the completion script")
+	assert.Contains(t, output, "bosun completion install zsh")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -119,6 +120,23 @@ func completeComposeServices(cmd *cobra.Command, args []string, toComplete strin
 	return names, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completeRenderedStacks completes the names of rendered stacks.
+func completeRenderedStacks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for name := range renderedStacks(filepath.Join(cfg.OutputDir(), "compose")) {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 // completeSnapshotNames returns a completion function that completes snapshot names.
 func completeSnapshotNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	cfg, err := config.Load()
@@ -194,6 +212,7 @@ func registerCompletions() {
 	crewLogsCmd.ValidArgsFunction = completeContainerNames(true)
 	crewInspectCmd.ValidArgsFunction = completeContainerNames(false)
 	crewRestartCmd.ValidArgsFunction = completeContainerNames(true)
+	execCmd.ValidArgsFunction = completeContainerNames(true)
 
 	// Provision command - complete stack/service names
 	provisionCmd.ValidArgsFunction = completeStackNames
@@ -214,6 +233,9 @@ func registerCompletions() {
 		// Silently ignore - completions are optional
 		_ = err
 	}
+	if err := yachtUpCmd.RegisterFlagCompletionFunc("only", completeRenderedStacks); err != nil {
+		_ = err
+	}
 }
 
 // init registers completions after all commands are set up.
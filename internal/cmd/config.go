@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/seal"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	configOutput  string
+	configEnvFile string
+	configForce   bool
+)
+
+// configCmd groups controller configuration commands.
+var configCmd = &cobra.Command{
+	Use:     "config",
+	Aliases: []string{"papers"},
+	Short:   "Export or import controller configuration",
+	Long: `Config commands move bosun's own configuration between hosts, for
+migrating the controller or rebuilding it after a loss.
+
+Commands:
+  export    Write a portable config bundle
+  import    Apply a config bundle on this host
+  seal      Encrypt alert credentials in config files`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write a portable config bundle",
+	Long: `Export bosun.yml, .bosun/config.yml, the daemon environment file, and
+the docker context definitions into a single YAML bundle.
+
+Daemon variables holding secrets (webhook secrets, bearer tokens, API keys,
+the SOPS age key) are left out and listed in the bundle so they can be set
+again on the new host. Credentials embedded in URLs, including a token used
+as the username, are replaced with REDACTED. Config files are copied as-is;
+if they contain alert credentials, keep the bundle safe.
+
+Examples:
+  bosun config export                        # Print bundle to stdout
+  bosun config export -o bosun-bundle.yml    # Write bundle to a file
+  bosun config export --env-file ./bosund.env`,
+	Args: cobra.NoArgs,
+	RunE: runConfigExport,
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Apply a config bundle on this host",
+	Long: `Import a bundle written by 'bosun config export'.
+
+Config files are written to the project root and docker contexts to the
+docker config directory, skipping ones that already exist unless --force is
+given. Daemon variables are merged into the env file: bundled values replace
+existing ones, and anything else in the file (such as secrets set by hand)
+is kept.
+
+Examples:
+  bosun config import bosun-bundle.yml
+  bosun config import bosun-bundle.yml --force
+  bosun config import bosun-bundle.yml --env-file ./bosund.env`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigImport,
+}
+
+var configSealCmd = &cobra.Command{
+	Use:   "seal",
+	Short: "Encrypt alert credentials in config files",
+	Long: `Encrypt the alert credentials in bosun.yml and .bosun/config.yml
+(discord_webhook_url, sendgrid_api_key, twilio_account_sid,
+twilio_auth_token) in place with the SOPS age key, so a copied project
+directory doesn't leak them. Comments and other settings are kept.
+
+Sealed values look like "age:..." and are opened with the same age key
+whenever the config is loaded. Values that are already sealed are left alone.
+
+Examples:
+  bosun config seal`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSeal,
+}
+
+func init() {
+	configExportCmd.Flags().StringVarP(&configOutput, "output", "o", "", "Output file (stdout if not set)")
+	configExportCmd.Flags().StringVar(&configEnvFile, "env-file", config.DefaultDaemonEnvFile, "Daemon environment file")
+
+	configImportCmd.Flags().StringVar(&configEnvFile, "env-file", config.DefaultDaemonEnvFile, "Daemon environment file")
+	configImportCmd.Flags().BoolVar(&configForce, "force", false, "Overwrite existing config files")
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+	configCmd.AddCommand(configSealCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigExport(cmd *cobra.Command, args []string) error {
+	root, err := config.FindRoot()
+	if err != nil {
+		return err
+	}
+
+	bundle, err := config.ExportBundle(root, configEnvFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("marshal bundle: %w", err)
+	}
+
+	if configOutput == "" {
+		fmt.Print(string(data))
+	} else {
+		if err := os.WriteFile(configOutput, data, 0600); err != nil {
+			return fmt.Errorf("write %s: %w", configOutput, err)
+		}
+		ui.Success("Exported config bundle to %s", configOutput)
+	}
+
+	// Notes go to stderr so stdout stays a clean bundle
+	if files := bundle.SecretFiles(); len(files) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s contain alert credentials; store the bundle securely\n", strings.Join(files, ", "))
+	}
+	if len(bundle.OmittedSecrets) > 0 {
+		fmt.Fprintf(os.Stderr, "Omitted secrets (set these on the new host): %s\n", strings.Join(bundle.OmittedSecrets, ", "))
+	}
+	if len(bundle.RedactedEnv) > 0 {
+		fmt.Fprintf(os.Stderr, "Redacted URL credentials (set these on the new host): %s\n", strings.Join(bundle.RedactedEnv, ", "))
+	}
+	return nil
+}
+
+func runConfigImport(cmd *cobra.Command, args []string) error {
+	bundle, err := config.LoadBundle(args[0])
+	if err != nil {
+		return err
+	}
+
+	root, err := config.FindRoot()
+	if err != nil {
+		root, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		ui.Warning("No project root found; importing into %s", root)
+	}
+
+	if bundle.SourceHost != "" {
+		ui.Info("Importing bundle from %s (%s)", bundle.SourceHost, timezone.Display(bundle.CreatedAt))
+	}
+
+	result, err := config.ImportBundle(bundle, root, configEnvFile, configForce)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range result.Written {
+		ui.Green.Printf("  * Wrote %s\n", f)
+	}
+	for _, f := range result.Skipped {
+		ui.Yellow.Printf("  ~ Kept existing %s (use --force to overwrite)\n", f)
+	}
+	if len(result.EnvKeys) > 0 {
+		ui.Green.Printf("  * Set %d daemon variables in %s\n", len(result.EnvKeys), configEnvFile)
+	}
+	for _, name := range result.Contexts {
+		ui.Green.Printf("  * Created docker context %s\n", name)
+	}
+	for _, name := range result.SkippedContexts {
+		ui.Yellow.Printf("  ~ Kept existing docker context %s (use --force to overwrite)\n", name)
+	}
+
+	if len(bundle.OmittedSecrets) > 0 {
+		fmt.Println()
+		ui.Warning("Secrets were not exported; set them in %s:", configEnvFile)
+		for _, name := range bundle.OmittedSecrets {
+			fmt.Printf("    %s\n", name)
+		}
+	}
+	if len(bundle.RedactedEnv) > 0 {
+		fmt.Println()
+		ui.Warning("URL credentials were redacted; set them again in %s:", configEnvFile)
+		for _, name := range bundle.RedactedEnv {
+			fmt.Printf("    %s\n", name)
+		}
+	}
+
+	return nil
+}
+
+func runConfigSeal(cmd *cobra.Command, args []string) error {
+	root, err := config.FindRoot()
+	if err != nil {
+		return err
+	}
+
+	key, err := seal.LoadKey()
+	if err != nil {
+		return fmt.Errorf("load age key: %w", err)
+	}
+
+	var total int
+	for _, rel := range []string{"bosun.yml", filepath.Join(".bosun", "config.yml")} {
+		path := filepath.Join(root, rel)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		sealed, err := config.SealConfigFile(path, key)
+		if err != nil {
+			return fmt.Errorf("seal %s: %w", rel, err)
+		}
+		for _, name := range sealed {
+			ui.Green.Printf("  * %s: sealed %s\n", rel, name)
+		}
+		total += len(sealed)
+	}
+
+	if total == 0 {
+		ui.Info("No plaintext alert credentials to seal")
+		return nil
+	}
+	ui.Success("Sealed %d credential(s)", total)
+	return nil
+}
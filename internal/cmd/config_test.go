@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "config", "export", "--help")
+	assert.NoError(t, err)
+	if len(output) > 0 {
+		assert.Contains(t, output, "--env-file")
+	}
+
+	_, err = executeCmd(t, "config", "import", "--help")
+	assert.NoError(t, err)
+
+	_, err = executeCmd(t, "config", "seal", "--help")
+	assert.NoError(t, err)
+}
+
+func TestConfigCmd_Aliases(t *testing.T) {
+	_, err := executeCmd(t, "papers", "--help")
+	assert.NoError(t, err)
+}
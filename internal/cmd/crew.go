@@ -8,12 +8,15 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/selector"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -31,6 +34,15 @@ var (
 	crewAll    bool
 	crewTail   int
 	crewFollow bool
+
+	crewLogsStack       string
+	crewLogsSelector    string
+	crewRestartStack    string
+	crewRestartSelector string
+	crewRestartDryRun   bool
+	crewRestartCascade  bool
+
+	crewRestartHealthTimeout time.Duration
 )
 
 var crewCmd = &cobra.Command{
@@ -41,6 +53,7 @@ var crewCmd = &cobra.Command{
 
 Commands:
   list      Show all hands on deck (docker ps)
+  ls        Long-format listing with sorting and filters
   logs      Tail crew member logs
   inspect   Detailed crew info
   restart   Send crew member for coffee break`,
@@ -84,12 +97,35 @@ var crewListCmd = &cobra.Command{
 }
 
 var crewLogsCmd = &cobra.Command{
-	Use:   "logs <name>",
+	Use:   "logs [name]",
 	Short: "Tail crew member logs",
-	Long:  `Shows logs from a container. Use -f to follow.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Shows logs from a container, or from every running container in a stack
+or matching a label selector. Use -f to follow.
+
+With more than one container, each line is prefixed with its container name
+and followed streams are interleaved as they arrive.
+
+Selectors match container labels plus the bosun.stack and bosun.service
+pseudo-labels: key=value, key!=value, or key, comma-separated, all of which
+must match.
+
+Examples:
+  bosun crew logs sonarr -f
+  bosun crew logs --stack media -n 20
+  bosun crew logs -l app=arr -f`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := args[0]
+		sel, err := crewSelector(crewLogsStack, crewLogsSelector)
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 && !sel.Empty() {
+			return fmt.Errorf("give a container name or --stack/--selector, not both")
+		}
+		if len(args) == 0 && sel.Empty() {
+			return fmt.Errorf("give a container name, --stack, or --selector")
+		}
+
 		// NOTE: This command uses explicit Docker client handling because it needs
 		// custom context management for signal-based cancellation during log streaming.
 		ctx, cancel := context.WithCancel(context.Background())
@@ -110,35 +146,106 @@ var crewLogsCmd = &cobra.Command{
 			cancel()
 		}()
 
+		opts := docker.LogOptions{Tail: crewTail, Follow: crewFollow}
 		return withDockerClientContext(ctx, func(client *docker.Client) error {
-			reader, err := client.Logs(ctx, name, crewTail, crewFollow)
+			if len(args) == 1 {
+				return streamLogs(ctx, client, args[0], opts, os.Stdout, os.Stderr)
+			}
+
+			containers, err := selectContainers(ctx, client, sel, false)
 			if err != nil {
-				return fmt.Errorf("get logs: %w", err)
+				return err
+			}
+			if len(containers) == 0 {
+				ui.Warning("No running containers match %s", sel)
+				return nil
 			}
 
-			// Stream logs to stdout, stripping Docker multiplex headers
-			_, copyErr := stdCopy(os.Stdout, os.Stderr, reader)
+			names := make([]string, len(containers))
+			for i, c := range containers {
+				names[i] = c.Name
+			}
+			return streamContainerLogs(ctx, client, names, opts)
+		})
+	},
+}
 
-			// Always close reader and capture error
-			closeErr := reader.Close()
+// streamContainerLogs prints the logs of several containers, each line
+// prefixed with its container name. Without opts.Follow each container's
+// logs are printed in turn; with it the streams are followed together and
+// interleaved as lines arrive.
+func streamContainerLogs(ctx context.Context, client *docker.Client, names []string, opts docker.LogOptions) error {
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
 
-			// Handle copy errors first
-			if copyErr != nil {
-				if ctx.Err() != nil {
-					// Context cancelled, normal exit
-					return nil
-				}
-				return fmt.Errorf("read logs: %w", copyErr)
-			}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, name := range names {
+		prefix := fmt.Sprintf("%-*s | ", width, name)
+		stdout := &prefixWriter{mu: &mu, w: os.Stdout, prefix: prefix}
+		stderr := &prefixWriter{mu: &mu, w: os.Stderr, prefix: prefix}
+		run := func() {
+			errs[i] = streamLogs(ctx, client, name, opts, stdout, stderr)
+			_ = stdout.Flush()
+			_ = stderr.Flush()
+		}
+		if !opts.Follow {
+			run()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run()
+		}()
+	}
+	wg.Wait()
 
-			// Report close errors (usually less critical but shouldn't be silent)
-			if closeErr != nil {
-				ui.Warning("Failed to close log reader: %v", closeErr)
-			}
+	var failed int
+	for i, err := range errs {
+		if err != nil {
+			ui.Red.Printf("  x %s: %v\n", names[i], err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d log streams failed", failed, len(names))
+	}
+	return nil
+}
+
+// streamLogs copies a container's logs to stdout and stderr until they end
+// or ctx is cancelled.
+func streamLogs(ctx context.Context, client *docker.Client, name string, opts docker.LogOptions, stdout, stderr io.Writer) error {
+	reader, err := client.LogsWith(ctx, name, opts)
+	if err != nil {
+		return fmt.Errorf("get logs: %w", err)
+	}
 
+	// Stream logs, stripping Docker multiplex headers
+	_, copyErr := stdCopy(stdout, stderr, reader)
+
+	// Always close reader and capture error
+	closeErr := reader.Close()
+
+	// Handle copy errors first
+	if copyErr != nil {
+		if ctx.Err() != nil {
+			// Context cancelled, normal exit
 			return nil
-		})
-	},
+		}
+		return fmt.Errorf("read logs: %w", copyErr)
+	}
+
+	// Report close errors (usually less critical but shouldn't be silent)
+	if closeErr != nil {
+		ui.Warning("Failed to close log reader: %v", closeErr)
+	}
+
+	return nil
 }
 
 var crewInspectCmd = &cobra.Command{
@@ -168,26 +275,95 @@ var crewInspectCmd = &cobra.Command{
 }
 
 var crewRestartCmd = &cobra.Command{
-	Use:   "restart <name>",
+	Use:   "restart [name...]",
 	Short: "Send crew member for coffee break",
-	Long:  `Restarts a specific container.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Restarts containers by name, or every running container in a stack or
+matching a label selector.
+
+Selectors match container labels plus the bosun.stack and bosun.service
+pseudo-labels: key=value, key!=value, or key, comma-separated, all of which
+must match.
+
+With --cascade, containers that depend on the restarted ones (depends_on in
+the rendered compose files, followed transitively) are restarted too, one at
+a time with each after its dependencies. Each container must be running, and
+healthy if it has a healthcheck, before the next is restarted; the cascade
+stops at the first one that isn't.
+
+Examples:
+  bosun crew restart sonarr radarr
+  bosun crew restart --stack media
+  bosun crew restart -l app=arr -n      # Show what would be restarted
+  bosun crew restart postgres --cascade # Restart postgres, then its dependents
+  bosun crew restart postgres --cascade -n`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := args[0]
+		sel, err := crewSelector(crewRestartStack, crewRestartSelector)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && !sel.Empty() {
+			return fmt.Errorf("give container names or --stack/--selector, not both")
+		}
+		if len(args) == 0 && sel.Empty() {
+			return fmt.Errorf("give a container name, --stack, or --selector")
+		}
+		if crewRestartCascade {
+			return runCascadeRestart(args, sel)
+		}
 
 		return withDockerClient(func(ctx context.Context, client *docker.Client) error {
-			ui.Blue.Printf("Sending %s for a coffee break...\n", name)
+			names, err := restartTargets(ctx, client, args, sel)
+			if err != nil || len(names) == 0 {
+				return err
+			}
+
+			var failed int
+			for _, name := range names {
+				if crewRestartDryRun {
+					ui.Yellow.Printf("  ~ %s: would restart\n", name)
+					continue
+				}
 
-			if err := client.RestartContainer(ctx, name); err != nil {
-				return fmt.Errorf("restart container: %w", err)
+				ui.Blue.Printf("Sending %s for a coffee break...\n", name)
+				if err := client.RestartContainer(ctx, name); err != nil {
+					ui.Red.Printf("  x %s: %v\n", name, err)
+					failed++
+					continue
+				}
+				ui.Success("%s is back on duty!", name)
 			}
 
-			ui.Success("%s is back on duty!", name)
+			if failed > 0 {
+				if len(names) == 1 {
+					return fmt.Errorf("restart container %s failed", names[0])
+				}
+				return fmt.Errorf("%d of %d restarts failed", failed, len(names))
+			}
 			return nil
 		})
 	},
 }
 
+// restartTargets returns the named containers, or the running containers
+// sel matches. It warns and returns none when sel matches nothing.
+func restartTargets(ctx context.Context, client *docker.Client, names []string, sel selector.Selector) ([]string, error) {
+	if len(names) > 0 {
+		return names, nil
+	}
+	containers, err := selectContainers(ctx, client, sel, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		ui.Warning("No running containers match %s", sel)
+		return nil, nil
+	}
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return names, nil
+}
+
 // stdCopy copies docker multiplexed stream to stdout/stderr.
 // Docker log streams have an 8-byte header per frame:
 // [STREAM_TYPE, 0, 0, 0, SIZE1, SIZE2, SIZE3, SIZE4]
@@ -234,6 +410,14 @@ func init() {
 
 	crewLogsCmd.Flags().IntVarP(&crewTail, "tail", "n", DefaultLogTailLines, "Number of lines to show")
 	crewLogsCmd.Flags().BoolVarP(&crewFollow, "follow", "f", false, "Follow log output")
+	crewLogsCmd.Flags().StringVar(&crewLogsStack, "stack", "", "Show logs for every running container in this stack")
+	crewLogsCmd.Flags().StringVarP(&crewLogsSelector, "selector", "l", "", "Show logs for running containers matching a label selector (e.g., app=arr)")
+
+	crewRestartCmd.Flags().StringVar(&crewRestartStack, "stack", "", "Restart every running container in this stack")
+	crewRestartCmd.Flags().StringVarP(&crewRestartSelector, "selector", "l", "", "Restart running containers matching a label selector (e.g., app=arr)")
+	crewRestartCmd.Flags().BoolVarP(&crewRestartDryRun, "dry-run", "n", false, "Show which containers would be restarted")
+	crewRestartCmd.Flags().BoolVar(&crewRestartCascade, "cascade", false, "Also restart containers that depend on these, in dependency order, waiting for health between steps")
+	crewRestartCmd.Flags().DurationVar(&crewRestartHealthTimeout, "health-timeout", DefaultRestartHealthTimeout, "With --cascade, how long to wait for each container to become healthy")
 
 	crewCmd.AddCommand(crewListCmd)
 	crewCmd.AddCommand(crewLogsCmd)
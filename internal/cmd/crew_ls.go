@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/selector"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// crewStatsConcurrency bounds parallel stats requests; each takes about a
+// second because Docker samples CPU twice.
+const crewStatsConcurrency = 8
+
+// crewSortKeys lists the columns crew ls can sort by.
+var crewSortKeys = []string{"name", "stack", "image", "state", "health", "uptime", "restarts", "cpu", "memory", "ports"}
+
+var (
+	crewLsAll       bool
+	crewLsSort      string
+	crewLsReverse   bool
+	crewLsStack     string
+	crewLsSelector  string
+	crewLsUnhealthy bool
+	crewLsDrifted   bool
+	crewLsWide      bool
+	crewLsJSON      bool
+)
+
+// crewRow is one container in the long-format listing.
+type crewRow struct {
+	Name     string        `json:"name"`
+	Stack    string        `json:"stack,omitempty"`
+	Image    string        `json:"image"`
+	State    string        `json:"state"`
+	Status   string        `json:"status"`
+	Health   string        `json:"health,omitempty"`
+	Uptime   time.Duration `json:"uptime"`
+	Restarts int           `json:"restarts"`
+	CPU      float64       `json:"cpu_percent"`
+	Memory   uint64        `json:"memory_bytes"`
+	Ports    []string      `json:"ports"`
+	// Drift is "image" when the running image differs from the manifest,
+	// or "orphan" when no manifest defines the container.
+	Drift string `json:"drift,omitempty"`
+}
+
+var crewLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Long-format container listing with sorting and filters",
+	Long: `Lists containers with their stack, image, state, health, uptime, restarts,
+and published ports. Stacks come from the rendered compose files, falling back
+to the compose project label outside a bosun project.
+
+Sort keys: name, stack, image, state, health, uptime, restarts, cpu, memory,
+ports. Uptime sorts shortest first; restarts, cpu, and memory sort highest
+first. --wide adds CPU and memory columns, which take a moment to sample.
+
+Examples:
+  bosun crew ls
+  bosun crew ls --stack media --sort restarts
+  bosun crew ls -l app=arr
+  bosun crew ls --unhealthy -a
+  bosun crew ls --drifted
+  bosun crew ls --wide --sort memory
+  bosun crew ls --json`,
+	RunE: runCrewLs,
+}
+
+func init() {
+	crewLsCmd.Flags().BoolVarP(&crewLsAll, "all", "a", false, "Show all containers (including stopped)")
+	crewLsCmd.Flags().StringVarP(&crewLsSort, "sort", "s", "stack", "Sort by column: "+strings.Join(crewSortKeys, ", "))
+	crewLsCmd.Flags().BoolVarP(&crewLsReverse, "reverse", "r", false, "Reverse the sort order")
+	crewLsCmd.Flags().StringVar(&crewLsStack, "stack", "", "Only show containers in this stack")
+	crewLsCmd.Flags().StringVarP(&crewLsSelector, "selector", "l", "", "Only show containers matching a label selector (e.g., app=arr)")
+	crewLsCmd.Flags().BoolVar(&crewLsUnhealthy, "unhealthy", false, "Only show unhealthy, restarting, or crashed containers")
+	crewLsCmd.Flags().BoolVar(&crewLsDrifted, "drifted", false, "Only show containers that drift from the manifests")
+	crewLsCmd.Flags().BoolVarP(&crewLsWide, "wide", "w", false, "Add CPU and memory columns")
+	crewLsCmd.Flags().BoolVar(&crewLsJSON, "json", false, "Output as JSON (includes CPU and memory)")
+
+	crewCmd.AddCommand(crewLsCmd)
+}
+
+func runCrewLs(cmd *cobra.Command, args []string) error {
+	if !isCrewSortKey(crewLsSort) {
+		return fmt.Errorf("unknown sort key %q (use one of: %s)", crewLsSort, strings.Join(crewSortKeys, ", "))
+	}
+
+	sel, err := selector.Parse(crewLsSelector)
+	if err != nil {
+		return err
+	}
+
+	// Without a project there are no manifests, so stacks come from labels
+	// and drift cannot be determined.
+	cfg, cfgErr := config.Load()
+	if crewLsDrifted && cfgErr != nil {
+		return fmt.Errorf("--drifted needs a bosun project: %w", cfgErr)
+	}
+
+	needStats := crewLsWide || crewLsJSON || crewLsSort == "cpu" || crewLsSort == "memory"
+
+	return withDockerClient(func(ctx context.Context, client *docker.Client) error {
+		containers, err := client.ListContainers(ctx, !crewLsAll)
+		if err != nil {
+			return fmt.Errorf("list containers: %w", err)
+		}
+
+		var stacks map[string]string
+		var images map[string]string
+		var infra []string
+		if cfgErr == nil {
+			stacks, images = manifestServices(filepath.Join(cfg.OutputDir(), "compose"))
+			infra = append(cfg.InfraContainers(), "bosun")
+		}
+		if !sel.Empty() {
+			containers = matchContainers(containers, stacks, sel)
+		}
+
+		rows := buildCrewRows(containers, stacks, images, infra, time.Now())
+		rows = filterCrewRows(rows, crewLsStack, crewLsUnhealthy, crewLsDrifted)
+
+		if needStats {
+			collectCrewStats(ctx, client, rows)
+		}
+		sortCrewRows(rows, crewLsSort, crewLsReverse)
+
+		if crewLsJSON {
+			data, err := json.MarshalIndent(rows, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal containers: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(rows) == 0 {
+			ui.Warning("No containers found")
+			return nil
+		}
+		printCrewRows(os.Stdout, rows, crewLsWide)
+		return nil
+	})
+}
+
+func isCrewSortKey(key string) bool {
+	for _, k := range crewSortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestServices maps each service in the rendered compose files to its
+// stack and expected image.
+func manifestServices(composeDir string) (stacks, images map[string]string) {
+	stacks = make(map[string]string)
+	images = make(map[string]string)
+	stackFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	for _, stackFile := range stackFiles {
+		stack := strings.TrimSuffix(filepath.Base(stackFile), ".yml")
+		for svc, image := range extractServicesFromCompose(stackFile) {
+			stacks[svc] = stack
+			images[svc] = image
+		}
+	}
+	return stacks, images
+}
+
+// buildCrewRows converts containers to rows. stacks and images come from the
+// manifests and are nil outside a project; infra containers are never orphans.
+func buildCrewRows(containers []docker.ContainerInfo, stacks, images map[string]string, infra []string, now time.Time) []crewRow {
+	rows := make([]crewRow, 0, len(containers))
+	for _, c := range containers {
+		row := crewRow{
+			Name:     c.Name,
+			Stack:    stacks[c.Name],
+			Image:    c.Image,
+			State:    c.State,
+			Status:   c.Status,
+			Health:   c.Health,
+			Restarts: c.Restarts,
+			Ports:    c.Ports,
+		}
+		if row.Stack == "" {
+			row.Stack = c.Labels["com.docker.compose.project"]
+		}
+		if c.State == "running" && !c.Started.IsZero() {
+			row.Uptime = now.Sub(c.Started)
+		}
+
+		if stacks != nil && c.State == "running" {
+			expected, managed := images[c.Name]
+			switch {
+			case managed && expected != "" && normalizeImage(expected) != normalizeImage(c.Image):
+				row.Drift = "image"
+			case !managed && !containsString(infra, c.Name):
+				row.Drift = "orphan"
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCrewRows keeps rows matching every given filter.
+func filterCrewRows(rows []crewRow, stack string, unhealthy, drifted bool) []crewRow {
+	kept := rows[:0]
+	for _, r := range rows {
+		if stack != "" && r.Stack != stack {
+			continue
+		}
+		if unhealthy && !r.unhealthy() {
+			continue
+		}
+		if drifted && r.Drift == "" {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// unhealthy reports whether the container is failing its health check,
+// restart-looping, dead, or exited with a non-zero code.
+func (r crewRow) unhealthy() bool {
+	switch {
+	case r.Health == "unhealthy", r.State == "restarting", r.State == "dead":
+		return true
+	case r.State == "exited":
+		code := parseExitCode(r.Status)
+		return code != "" && code != "0"
+	}
+	return false
+}
+
+// collectCrewStats fills CPU and memory for running containers. Containers
+// whose stats cannot be read are left at zero.
+func collectCrewStats(ctx context.Context, client *docker.Client, rows []crewRow) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, crewStatsConcurrency)
+	for i := range rows {
+		if rows[i].State != "running" {
+			continue
+		}
+		wg.Add(1)
+		go func(row *crewRow) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if s, err := client.GetContainerStats(ctx, row.Name); err == nil {
+				row.CPU = s.CPUPercent
+				row.Memory = s.MemUsage
+			}
+		}(&rows[i])
+	}
+	wg.Wait()
+}
+
+// sortCrewRows sorts rows by key, breaking ties by name. Numeric columns
+// other than uptime sort highest first.
+func sortCrewRows(rows []crewRow, key string, reverse bool) {
+	less := func(a, b crewRow) (bool, bool) {
+		switch key {
+		case "stack":
+			return a.Stack < b.Stack, a.Stack == b.Stack
+		case "image":
+			return a.Image < b.Image, a.Image == b.Image
+		case "state":
+			return a.State < b.State, a.State == b.State
+		case "health":
+			return a.Health < b.Health, a.Health == b.Health
+		case "uptime":
+			return a.Uptime < b.Uptime, a.Uptime == b.Uptime
+		case "restarts":
+			return a.Restarts > b.Restarts, a.Restarts == b.Restarts
+		case "cpu":
+			return a.CPU > b.CPU, a.CPU == b.CPU
+		case "memory":
+			return a.Memory > b.Memory, a.Memory == b.Memory
+		case "ports":
+			pa, pb := strings.Join(a.Ports, ","), strings.Join(b.Ports, ",")
+			return pa < pb, pa == pb
+		}
+		return false, true
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if reverse {
+			a, b = b, a
+		}
+		if lt, eq := less(a, b); !eq {
+			return lt
+		}
+		return a.Name < b.Name
+	})
+}
+
+// printCrewRows writes rows as an aligned table.
+func printCrewRows(out io.Writer, rows []crewRow, wide bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := "NAME\tSTACK\tIMAGE\tSTATE\tHEALTH\tUPTIME\tRESTARTS"
+	if wide {
+		header += "\tCPU\tMEMORY"
+	}
+	fmt.Fprintln(w, header+"\tPORTS")
+
+	for _, r := range rows {
+		state := r.State
+		if r.Drift != "" {
+			state += " (" + r.Drift + " drift)"
+		}
+		uptime := "-"
+		if r.Uptime > 0 {
+			uptime = formatAge(r.Uptime)
+		}
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%d",
+			r.Name, dashIfEmpty(r.Stack), r.Image, state, dashIfEmpty(r.Health), uptime, r.Restarts)
+		if wide {
+			line += fmt.Sprintf("\t%.1f%%\t%s", r.CPU, formatBytes(int64(r.Memory)))
+		}
+
+		ports := strings.Join(r.Ports, ", ")
+		if !wide && len(ports) > MaxPortDisplayLength {
+			ports = ports[:TruncatedPortLength] + "..."
+		}
+		fmt.Fprintln(w, line+"\t"+ports)
+	}
+	w.Flush()
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
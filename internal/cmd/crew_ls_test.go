@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+func TestCrewLsCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "crew", "ls", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Sort keys:")
+	assert.Contains(t, output, "--drifted")
+}
+
+func testCrewRows(now time.Time) []crewRow {
+	containers := []docker.ContainerInfo{
+		{Name: "plex", Image: "plexinc/pms:latest", State: "running", Status: "Up 2 hours", Started: now.Add(-2 * time.Hour), Restarts: 0},
+		{Name: "sonarr", Image: "linuxserver/sonarr:4", State: "running", Status: "Up 5 minutes", Health: "unhealthy", Started: now.Add(-5 * time.Minute), Restarts: 12},
+		{Name: "stray", Image: "busybox", State: "running", Status: "Up 1 hour", Started: now.Add(-time.Hour), Labels: map[string]string{"com.docker.compose.project": "scratch"}},
+		{Name: "traefik", Image: "traefik:v3", State: "running", Status: "Up 3 days", Started: now.Add(-72 * time.Hour)},
+		{Name: "backup", Image: "restic", State: "exited", Status: "Exited (1) 2 hours ago"},
+	}
+	stacks := map[string]string{"plex": "media", "sonarr": "media", "backup": "ops"}
+	images := map[string]string{"plex": "plexinc/pms:1.40", "sonarr": "ghcr.io/linuxserver/sonarr:4", "backup": "restic"}
+	return buildCrewRows(containers, stacks, images, []string{"traefik", "bosun"}, now)
+}
+
+func TestBuildCrewRows(t *testing.T) {
+	now := time.Now()
+	rows := testCrewRows(now)
+	require.Len(t, rows, 5)
+
+	byName := make(map[string]crewRow)
+	for _, r := range rows {
+		byName[r.Name] = r
+	}
+
+	assert.Equal(t, "media", byName["plex"].Stack)
+	assert.Equal(t, 2*time.Hour, byName["plex"].Uptime)
+	assert.Empty(t, byName["plex"].Drift, "tag changes are not drift")
+	assert.Equal(t, "image", byName["sonarr"].Drift)
+	assert.Equal(t, "scratch", byName["stray"].Stack, "falls back to compose project label")
+	assert.Equal(t, "orphan", byName["stray"].Drift)
+	assert.Empty(t, byName["traefik"].Drift, "infra containers are not orphans")
+	assert.Zero(t, byName["backup"].Uptime)
+
+	t.Run("no drift outside a project", func(t *testing.T) {
+		rows := buildCrewRows([]docker.ContainerInfo{{Name: "stray", State: "running"}}, nil, nil, nil, now)
+		assert.Empty(t, rows[0].Drift)
+	})
+}
+
+func TestFilterCrewRows(t *testing.T) {
+	names := func(rows []crewRow) []string {
+		var out []string
+		for _, r := range rows {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+	now := time.Now()
+
+	assert.Equal(t, []string{"plex", "sonarr"}, names(filterCrewRows(testCrewRows(now), "media", false, false)))
+	assert.Equal(t, []string{"sonarr", "backup"}, names(filterCrewRows(testCrewRows(now), "", true, false)))
+	assert.Equal(t, []string{"sonarr", "stray"}, names(filterCrewRows(testCrewRows(now), "", false, true)))
+	assert.Equal(t, []string{"sonarr"}, names(filterCrewRows(testCrewRows(now), "media", true, true)))
+}
+
+func TestSortCrewRows(t *testing.T) {
+	tests := []struct {
+		key     string
+		reverse bool
+		want    []string
+	}{
+		{"name", false, []string{"backup", "plex", "sonarr", "stray", "traefik"}},
+		{"stack", false, []string{"traefik", "plex", "sonarr", "backup", "stray"}},
+		{"restarts", false, []string{"sonarr", "backup", "plex", "stray", "traefik"}},
+		{"uptime", false, []string{"backup", "sonarr", "stray", "plex", "traefik"}},
+		{"uptime", true, []string{"traefik", "plex", "stray", "sonarr", "backup"}},
+	}
+	for _, tt := range tests {
+		rows := testCrewRows(time.Now())
+		sortCrewRows(rows, tt.key, tt.reverse)
+		var got []string
+		for _, r := range rows {
+			got = append(got, r.Name)
+		}
+		assert.Equal(t, tt.want, got, "sort by %s (reverse=%v)", tt.key, tt.reverse)
+	}
+}
+
+func TestPrintCrewRows(t *testing.T) {
+	rows := testCrewRows(time.Now())
+
+	var buf bytes.Buffer
+	printCrewRows(&buf, rows, false)
+	out := buf.String()
+	assert.Contains(t, out, "NAME")
+	assert.Contains(t, out, "RESTARTS")
+	assert.NotContains(t, out, "MEMORY")
+	assert.Contains(t, out, "running (image drift)")
+
+	buf.Reset()
+	printCrewRows(&buf, rows, true)
+	assert.Contains(t, buf.String(), "MEMORY")
+}
+
+func TestRunCrewLs_InvalidSort(t *testing.T) {
+	crewLsSort = "color"
+	defer func() { crewLsSort = "stack" }()
+
+	err := runCrewLs(crewLsCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown sort key")
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/selector"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DefaultRestartHealthTimeout is how long a cascading restart waits for
+// each container to become healthy before giving up.
+const DefaultRestartHealthTimeout = 2 * time.Minute
+
+// restartHealthPoll is how often a cascading restart checks container
+// health (tests shorten it).
+var restartHealthPoll = 2 * time.Second
+
+// restartCompose is the part of a rendered compose file a cascading
+// restart reads.
+type restartCompose struct {
+	Services map[string]struct {
+		ContainerName string `yaml:"container_name"`
+		DependsOn     any    `yaml:"depends_on"`
+	} `yaml:"services"`
+}
+
+// runCascadeRestart restarts the named (or selected) containers and every
+// container that depends on them, dependencies first, waiting for each to
+// become healthy before restarting the next.
+func runCascadeRestart(names []string, sel selector.Selector) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	files, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	if len(files) == 0 {
+		return fmt.Errorf("--cascade reads depends_on from the rendered compose files, but there are none in %s (run 'bosun provision')", composeDir)
+	}
+	deps, err := loadContainerDeps(files)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return withDockerClientContext(ctx, func(client *docker.Client) error {
+		roots, err := restartTargets(ctx, client, names, sel)
+		if err != nil || len(roots) == 0 {
+			return err
+		}
+
+		plan, err := cascadeOrder(roots, deps)
+		if err != nil {
+			return err
+		}
+
+		if crewRestartDryRun {
+			ui.Info("Would restart %d containers in this order:", len(plan))
+			for i, name := range plan {
+				ui.Yellow.Printf("  %d. %s%s\n", i+1, name, dependsNote(name, deps, plan))
+			}
+			return nil
+		}
+
+		return restartInOrder(ctx, client, plan, crewRestartHealthTimeout)
+	})
+}
+
+// restartInOrder restarts containers one at a time, waiting for each to
+// come back healthy. It stops at the first failure so no container is
+// restarted while a dependency is down.
+func restartInOrder(ctx context.Context, client *docker.Client, plan []string, healthTimeout time.Duration) error {
+	for i, name := range plan {
+		ui.Blue.Printf("[%d/%d] Sending %s for a coffee break...\n", i+1, len(plan), name)
+		if err := client.RestartContainer(ctx, name); err != nil {
+			return cascadeStopped(plan[i+1:], fmt.Errorf("restart %s: %w", name, err))
+		}
+		health, err := waitRestartHealthy(ctx, client, name, healthTimeout)
+		if err != nil {
+			return cascadeStopped(plan[i+1:], err)
+		}
+		ui.Success("%s is back on duty (%s)", name, health)
+	}
+	return nil
+}
+
+// cascadeStopped reports the containers a failed cascade left alone.
+func cascadeStopped(skipped []string, err error) error {
+	if len(skipped) > 0 {
+		ui.Warning("Not restarted: %s", strings.Join(skipped, ", "))
+	}
+	return err
+}
+
+// waitRestartHealthy waits until a restarted container is running and, if
+// it has a healthcheck, healthy. It returns the state it settled in.
+func waitRestartHealthy(ctx context.Context, client *docker.Client, name string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(restartHealthPoll)
+	defer ticker.Stop()
+
+	last := "unknown"
+	for {
+		details, err := client.Inspect(ctx, name)
+		if err == nil {
+			switch {
+			case details.State != "running" && details.State != "restarting":
+				return "", fmt.Errorf("%s is %s after restart", name, details.State)
+			case details.State == "running" && details.Health == "":
+				return "running", nil
+			case details.Health == "healthy":
+				return "healthy", nil
+			case details.Health == "unhealthy":
+				return "", fmt.Errorf("%s is unhealthy after restart", name)
+			}
+			last = details.State
+			if details.Health != "" {
+				last = details.Health
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("%s not healthy after %s (last seen %s)", name, timeout, last)
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadContainerDeps reads rendered compose files and returns, for each
+// container, the containers it depends on. Containers are named by
+// container_name, or by service name when it isn't set.
+func loadContainerDeps(files []string) (map[string][]string, error) {
+	deps := make(map[string][]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		var compose restartCompose
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+
+		containerName := func(service string) string {
+			if svc, ok := compose.Services[service]; ok && svc.ContainerName != "" {
+				return svc.ContainerName
+			}
+			return service
+		}
+		for service, svc := range compose.Services {
+			name := containerName(service)
+			for _, dep := range composeNames(svc.DependsOn) {
+				deps[name] = append(deps[name], containerName(dep))
+			}
+			sort.Strings(deps[name])
+		}
+	}
+	return deps, nil
+}
+
+// cascadeOrder returns roots and everything that depends on them, directly
+// or not, in an order where each container comes after its dependencies.
+// Ties are broken by name, so the order is stable.
+func cascadeOrder(roots []string, deps map[string][]string) ([]string, error) {
+	dependents := make(map[string][]string)
+	for name, ds := range deps {
+		for _, dep := range ds {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	include := make(map[string]bool)
+	queue := slices.Clone(roots)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if include[name] {
+			continue
+		}
+		include[name] = true
+		queue = append(queue, dependents[name]...)
+	}
+
+	// Kahn's algorithm over the included containers
+	waiting := make(map[string]int)
+	var ready []string
+	for name := range include {
+		for _, dep := range deps[name] {
+			if include[dep] && dep != name {
+				waiting[name]++
+			}
+		}
+		if waiting[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(include))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			if !include[dependent] || dependent == name {
+				continue
+			}
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(include) {
+		var cyclic []string
+		for name := range include {
+			if !slices.Contains(order, name) {
+				cyclic = append(cyclic, name)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("depends_on cycle among %s; restart them without --cascade", strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
+
+// dependsNote describes which planned containers name depends on.
+func dependsNote(name string, deps map[string][]string, plan []string) string {
+	var after []string
+	for _, dep := range deps[name] {
+		if slices.Contains(plan, dep) {
+			after = append(after, dep)
+		}
+	}
+	if len(after) == 0 {
+		return ""
+	}
+	return " (after " + strings.Join(after, ", ") + ")"
+}
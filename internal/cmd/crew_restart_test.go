@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker/dockertest"
+)
+
+func TestCascadeOrder(t *testing.T) {
+	deps := map[string][]string{
+		"app":    {"db"},
+		"worker": {"db", "redis"},
+		"web":    {"app"},
+		"other":  {"redis"},
+	}
+
+	order, err := cascadeOrder([]string{"db"}, deps)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "app", "web", "worker"}, order)
+
+	order, err = cascadeOrder([]string{"web"}, deps)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, order, "dependencies are not restarted")
+
+	order, err = cascadeOrder([]string{"app", "db"}, deps)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "app", "web", "worker"}, order, "a root waits for another root it depends on")
+
+	deps["db"] = []string{"web"}
+	_, err = cascadeOrder([]string{"db"}, deps)
+	assert.ErrorContains(t, err, "depends_on cycle among app, db, web")
+}
+
+func TestLoadContainerDeps(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "apps.yml")
+	require.NoError(t, os.WriteFile(file, []byte(`services:
+  wiki:
+    container_name: wiki-app
+    depends_on:
+      db:
+        condition: service_healthy
+  db:
+    container_name: wiki-db
+  backup:
+    depends_on: [db, wiki]
+`), 0644))
+
+	deps, err := loadContainerDeps([]string{file})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wiki-db"}, deps["wiki-app"])
+	assert.Equal(t, []string{"wiki-app", "wiki-db"}, deps["backup"])
+	assert.Empty(t, deps["wiki-db"])
+}
+
+func TestRestartInOrder(t *testing.T) {
+	restartHealthPoll = 10 * time.Millisecond
+	t.Cleanup(func() { restartHealthPoll = 2 * time.Second })
+
+	t.Run("restarts in order", func(t *testing.T) {
+		scenario := dockertest.NewScenario().
+			WithHealthyContainer("db").
+			WithRunning("app")
+		err := restartInOrder(context.Background(), scenario.Client(), []string{"db", "app"}, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 2, scenario.Calls(dockertest.OpRestart))
+	})
+
+	t.Run("stops at an unhealthy container", func(t *testing.T) {
+		scenario := dockertest.NewScenario().
+			WithUnhealthy("db").
+			WithRunning("app")
+		err := restartInOrder(context.Background(), scenario.Client(), []string{"db", "app"}, time.Second)
+		assert.ErrorContains(t, err, "db is unhealthy after restart")
+		app, _ := scenario.Container("app")
+		assert.Zero(t, app.Restarts, "dependents of an unhealthy container are not restarted")
+	})
+
+	t.Run("times out while starting", func(t *testing.T) {
+		scenario := dockertest.NewScenario().
+			WithContainer(dockertest.Container{Name: "db", State: "running", Health: "starting"})
+		err := restartInOrder(context.Background(), scenario.Client(), []string{"db"}, 50*time.Millisecond)
+		assert.ErrorContains(t, err, "db not healthy after 50ms (last seen starting)")
+	})
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/selector"
+)
+
+// crewSelector combines a --stack flag and a --selector expression into one
+// selector.
+func crewSelector(stack, expr string) (selector.Selector, error) {
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return selector.Selector{}, err
+	}
+	if stack != "" {
+		if !stackNameRegex.MatchString(stack) {
+			return selector.Selector{}, fmt.Errorf("invalid stack name: %s", stack)
+		}
+		sel = selector.Stack(stack).And(sel)
+	}
+	return sel, nil
+}
+
+// containerLabels returns a container's labels plus the bosun.stack and
+// bosun.service pseudo-labels. The stack comes from the manifests, falling
+// back to the compose project label; labels set on the container win.
+func containerLabels(c docker.ContainerInfo, stacks map[string]string) map[string]string {
+	labels := make(map[string]string, len(c.Labels)+2)
+	for k, v := range c.Labels {
+		labels[k] = v
+	}
+	if _, ok := labels[selector.StackLabel]; !ok {
+		stack := stacks[c.Name]
+		if stack == "" {
+			stack = c.Labels["com.docker.compose.project"]
+		}
+		if stack != "" {
+			labels[selector.StackLabel] = stack
+		}
+	}
+	if _, ok := labels[selector.ServiceLabel]; !ok {
+		service := c.Labels["com.docker.compose.service"]
+		if service == "" {
+			service = c.Name
+		}
+		labels[selector.ServiceLabel] = service
+	}
+	return labels
+}
+
+// matchContainers returns the containers matching sel, sorted by name.
+func matchContainers(containers []docker.ContainerInfo, stacks map[string]string, sel selector.Selector) []docker.ContainerInfo {
+	var matched []docker.ContainerInfo
+	for _, c := range containers {
+		if sel.Matches(containerLabels(c, stacks)) {
+			matched = append(matched, c)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+	return matched
+}
+
+// selectContainers lists containers (running only unless all) and returns
+// those matching sel. Stacks come from the rendered compose files when run
+// inside a bosun project.
+func selectContainers(ctx context.Context, client *docker.Client, sel selector.Selector, all bool) ([]docker.ContainerInfo, error) {
+	containers, err := client.ListContainers(ctx, !all)
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+
+	var stacks map[string]string
+	if cfg, err := config.Load(); err == nil {
+		stacks, _ = manifestServices(filepath.Join(cfg.OutputDir(), "compose"))
+	}
+	return matchContainers(containers, stacks, sel), nil
+}
+
+// prefixWriter writes complete lines to w, each prefixed with a container
+// name, so interleaved log streams stay readable. Writers sharing mu never
+// interleave within a line.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i == -1 {
+			return len(data), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes any trailing partial line.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	err := p.writeLine(append(p.buf, '\n'))
+	p.buf = nil
+	return err
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := io.WriteString(p.w, p.prefix); err != nil {
+		return err
+	}
+	_, err := p.w.Write(line)
+	return err
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+func TestCrewSelector(t *testing.T) {
+	sel, err := crewSelector("media", "app=arr")
+	require.NoError(t, err)
+	assert.Equal(t, "bosun.stack=media,app=arr", sel.String())
+
+	_, err = crewSelector("../media", "")
+	assert.Error(t, err)
+
+	_, err = crewSelector("", "=arr")
+	assert.Error(t, err)
+}
+
+func TestMatchContainers(t *testing.T) {
+	containers := []docker.ContainerInfo{
+		{Name: "sonarr", Labels: map[string]string{"app": "arr"}},
+		{Name: "radarr", Labels: map[string]string{"app": "arr"}},
+		{Name: "plex", Labels: map[string]string{}},
+		{Name: "adguard", Labels: map[string]string{"com.docker.compose.project": "dns", "com.docker.compose.service": "dns"}},
+	}
+	stacks := map[string]string{"sonarr": "media", "radarr": "media", "plex": "media"}
+
+	names := func(sel string) []string {
+		s, err := crewSelector("", sel)
+		require.NoError(t, err)
+		var out []string
+		for _, c := range matchContainers(containers, stacks, s) {
+			out = append(out, c.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"plex", "radarr", "sonarr"}, names("bosun.stack=media"))
+	assert.Equal(t, []string{"radarr", "sonarr"}, names("app=arr"))
+	assert.Equal(t, []string{"plex"}, names("bosun.stack=media,app!=arr"))
+	assert.Equal(t, []string{"adguard"}, names("bosun.stack=dns,bosun.service=dns"))
+	assert.Equal(t, []string{"sonarr"}, names("bosun.service=sonarr"))
+	assert.Empty(t, names("bosun.stack=infra"))
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	a := &prefixWriter{mu: &mu, w: &out, prefix: "a | "}
+	b := &prefixWriter{mu: &mu, w: &out, prefix: "b | "}
+
+	_, _ = a.Write([]byte("one\ntw"))
+	_, _ = b.Write([]byte("three\n"))
+	_, _ = a.Write([]byte("o\nfour"))
+	require.NoError(t, a.Flush())
+
+	assert.Equal(t, "a | one\nb | three\na | two\na | four\n", out.String())
+}
@@ -111,10 +111,17 @@ func TestCrewLogsCmd_UsageInfo(t *testing.T) {
 		assert.Contains(t, output, "-f")
 	})
 
-	t.Run("help shows usage with name placeholder", func(t *testing.T) {
+	t.Run("help shows usage with optional name", func(t *testing.T) {
 		output, err := executeCmd(t, "crew", "logs", "--help")
 		assert.NoError(t, err)
-		assert.Contains(t, output, "<name>")
+		assert.Contains(t, output, "logs [name]")
+	})
+
+	t.Run("help shows selector flags", func(t *testing.T) {
+		output, err := executeCmd(t, "crew", "logs", "--help")
+		assert.NoError(t, err)
+		assert.Contains(t, output, "--stack")
+		assert.Contains(t, output, "-l, --selector")
 	})
 }
 
@@ -129,10 +136,18 @@ func TestCrewInspectCmd_UsageInfo(t *testing.T) {
 
 // TestCrewRestartCmd_UsageInfo tests crew restart command usage.
 func TestCrewRestartCmd_UsageInfo(t *testing.T) {
-	t.Run("help shows usage with name placeholder", func(t *testing.T) {
+	t.Run("help shows usage with optional names", func(t *testing.T) {
 		output, err := executeCmd(t, "crew", "restart", "--help")
 		assert.NoError(t, err)
-		assert.Contains(t, output, "<name>")
+		assert.Contains(t, output, "restart [name...]")
+	})
+
+	t.Run("help shows selector flags", func(t *testing.T) {
+		output, err := executeCmd(t, "crew", "restart", "--help")
+		assert.NoError(t, err)
+		assert.Contains(t, output, "--stack")
+		assert.Contains(t, output, "-l, --selector")
+		assert.Contains(t, output, "-n, --dry-run")
 	})
 }
 
@@ -167,7 +182,7 @@ func TestCrewCmd_SubcommandUsage(t *testing.T) {
 		{
 			name:           "logs usage",
 			args:           []string{"crew", "logs", "--help"},
-			expectInOutput: []string{"<name>", "logs"},
+			expectInOutput: []string{"[name]", "logs"},
 		},
 		{
 			name:           "inspect usage",
@@ -177,7 +192,7 @@ func TestCrewCmd_SubcommandUsage(t *testing.T) {
 		{
 			name:           "restart usage",
 			args:           []string{"crew", "restart", "--help"},
-			expectInOutput: []string{"<name>", "restart"},
+			expectInOutput: []string{"[name...]", "restart"},
 		},
 		{
 			name:           "list usage",
@@ -3,13 +3,14 @@ package cmd
 import (
 	"context"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/alert"
+	"github.com/cameronsjo/bosun/internal/config"
 	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/reconcile"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -17,6 +18,7 @@ var (
 	daemonPort         int
 	daemonPollInterval int
 	daemonDryRun       bool
+	daemonChaos        string
 )
 
 // daemonCmd represents the daemon command.
@@ -39,6 +41,12 @@ Configuration via environment variables:
   DISCORD_WEBHOOK_URL              Discord notifications
   SENDGRID_API_KEY                 SendGrid email notifications
   TWILIO_ACCOUNT_SID               Twilio SMS notifications
+  SLACK_WEBHOOK_URL                Slack notifications
+  NTFY_TOPIC                       ntfy push notifications
+  ALERT_WEBHOOK_URL                Generic JSON webhook notifications
+
+Alert providers and per-severity routing can also be set under alerts in
+bosun.yml; environment variables override it.
 
 Endpoints:
   /health        Health check (JSON status)
@@ -54,6 +62,8 @@ func init() {
 	daemonCmd.Flags().IntVarP(&daemonPort, "port", "p", 8080, "HTTP server port")
 	daemonCmd.Flags().IntVarP(&daemonPollInterval, "poll-interval", "i", 3600, "Poll interval in seconds (0 disables)")
 	daemonCmd.Flags().BoolVarP(&daemonDryRun, "dry-run", "n", false, "Dry run mode (no actual changes)")
+	daemonCmd.Flags().StringVar(&daemonChaos, "chaos", "", "Inject deploy failures for testing rollback (staging only), e.g. 0.2 or health-gate=0.5")
+	_ = daemonCmd.Flags().MarkHidden("chaos")
 
 	rootCmd.AddCommand(daemonCmd)
 }
@@ -72,12 +82,21 @@ func runDaemon(cmd *cobra.Command, args []string) {
 	if cmd.Flags().Changed("dry-run") || daemonDryRun {
 		cfg.ReconcileConfig.DryRun = true
 	}
+	if daemonChaos != "" {
+		chaos, err := reconcile.ParseChaos(daemonChaos)
+		if err != nil {
+			ui.Fatal("Invalid chaos spec: %v", err)
+		}
+		cfg.ReconcileConfig.Chaos = chaos
+	}
 
 	// Validate configuration
 	if err := daemon.ValidateConfig(cfg); err != nil {
 		ui.Fatal("Invalid configuration: %v", err)
 	}
 
+	applyProjectDaemonConfig(cfg)
+
 	// Set up alert manager
 	cfg.AlertManager = createDaemonAlertManager()
 
@@ -93,6 +112,29 @@ func runDaemon(cmd *cobra.Command, args []string) {
 	}
 }
 
+// applyProjectDaemonConfig applies the daemon: section of the project
+// config to settings the environment leaves unset.
+func applyProjectDaemonConfig(cfg *daemon.Config) {
+	project, err := config.Load()
+	if err != nil {
+		return
+	}
+	dcfg := project.GetDaemonConfig()
+	if _, ok := os.LookupEnv("BOSUN_DRIFT_REMEDIATE"); !ok && dcfg.DriftRemediate {
+		cfg.DriftRemediate = true
+	}
+	if _, ok := os.LookupEnv("BOSUN_DRIFT_DEBOUNCE"); !ok && dcfg.DriftDebounce > 0 {
+		cfg.DriftDebounce = dcfg.DriftDebounce
+	}
+	if _, ok := os.LookupEnv("BOSUN_MAINTENANCE_WINDOWS"); !ok && dcfg.MaintenanceWindows != "" {
+		if windows, err := daemon.ParseFreezeWindows(dcfg.MaintenanceWindows); err != nil {
+			ui.Warning("Ignoring invalid maintenance windows: %v", err)
+		} else {
+			cfg.MaintenanceWindows = windows
+		}
+	}
+}
+
 // secondsToDuration converts seconds to time.Duration.
 func secondsToDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
@@ -100,32 +142,7 @@ func secondsToDuration(seconds int) time.Duration {
 
 // createDaemonAlertManager creates an alert manager for the daemon.
 func createDaemonAlertManager() *alert.Manager {
-	mgr := alert.NewManager()
-
-	// Add Discord provider
-	discord := alert.NewDiscordProvider(os.Getenv("DISCORD_WEBHOOK_URL"))
-	mgr.AddProvider(discord)
-
-	// Add SendGrid provider
-	toEmails := filterEmptyStrings(strings.Split(os.Getenv("SENDGRID_TO_EMAILS"), ","))
-	sendgrid := alert.NewSendGrid(alert.SendGridConfig{
-		APIKey:    os.Getenv("SENDGRID_API_KEY"),
-		FromEmail: os.Getenv("SENDGRID_FROM_EMAIL"),
-		FromName:  os.Getenv("SENDGRID_FROM_NAME"),
-		ToEmails:  toEmails,
-	})
-	mgr.AddProvider(sendgrid)
-
-	// Add Twilio provider
-	toNumbers := filterEmptyStrings(strings.Split(os.Getenv("TWILIO_TO_NUMBERS"), ","))
-	twilio := alert.NewTwilio(alert.TwilioConfig{
-		AccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
-		AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
-		FromNumber: os.Getenv("TWILIO_FROM_NUMBER"),
-		ToNumbers:  toNumbers,
-	})
-	mgr.AddProvider(twilio)
-
+	mgr := newAlertManager(projectAlertConfig())
 	if mgr.HasProviders() {
 		ui.Info("Alert providers: %v", mgr.ProviderNames())
 	}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	historySocket  string
+	historyTCP     string
+	historyToken   string
+	historyTimeout int
+	historyLimit   int
+	historyJSON    bool
+)
+
+// daemonHistoryCmd shows the daemon's recent reconcile runs.
+var daemonHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recent reconcile runs from the running daemon",
+	Long: `Show the reconcile runs of the running daemon, newest first: when each
+started, what triggered it, how long it took, the commits it went from and
+to, and whether it succeeded, failed, or was cancelled.
+
+The daemon keeps the last 100 runs in memory, so history starts over when
+it restarts. Remote tooling can query GET /history?limit=N on the TCP API
+directly.
+
+Examples:
+  bosun daemon history
+  bosun daemon history -n 5
+  bosun daemon history --json
+  bosun daemon history --tcp 10.0.0.5:9090 --token $BOSUN_BEARER_TOKEN`,
+	Args: cobra.NoArgs,
+	Run:  runDaemonHistory,
+}
+
+func init() {
+	daemonHistoryCmd.Flags().StringVar(&historySocket, "socket", daemon.DefaultSocketPath(), "Path to daemon socket")
+	daemonHistoryCmd.Flags().StringVar(&historyTCP, "tcp", "", "Query the daemon's TCP API at this address instead of the socket")
+	daemonHistoryCmd.Flags().StringVar(&historyToken, "token", os.Getenv("BOSUN_BEARER_TOKEN"), "Bearer token for the TCP API (default: $BOSUN_BEARER_TOKEN)")
+	daemonHistoryCmd.Flags().IntVarP(&historyTimeout, "timeout", "t", 10, "Timeout in seconds")
+	daemonHistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Number of runs to show")
+	daemonHistoryCmd.Flags().BoolVar(&historyJSON, "json", false, "Output as JSON")
+
+	daemonCmd.AddCommand(daemonHistoryCmd)
+}
+
+func runDaemonHistory(cmd *cobra.Command, args []string) {
+	if historyLimit < 1 {
+		ui.Fatal("--limit must be at least 1")
+	}
+
+	client := daemon.NewClient(historySocket)
+	if historyTCP != "" {
+		client = daemon.NewTCPClient(historyTCP, historyToken)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(historyTimeout)*time.Second)
+	defer cancel()
+
+	history, err := client.History(ctx, historyLimit)
+	if err != nil {
+		ui.Fatal("Failed to get reconcile history: %v", err)
+	}
+
+	if historyJSON {
+		data, _ := json.MarshalIndent(history, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	printReconcileHistory(history.Runs)
+}
+
+func printReconcileHistory(runs []daemon.ReconcileRun) {
+	if len(runs) == 0 {
+		ui.Info("No reconcile runs since the daemon started")
+		return
+	}
+
+	ui.Blue.Println("--- Reconcile History ---")
+	for _, run := range runs {
+		line := fmt.Sprintf("%s %s %s (%s) %s", timezone.Display(run.StartedAt), run.Outcome, formatCommitRange(run.FromCommit, run.ToCommit), run.Source, run.Duration().Round(100*time.Millisecond))
+		switch run.Outcome {
+		case daemon.StatusSucceeded:
+			ui.Green.Printf("  * %s\n", line)
+		case daemon.StatusCancelled:
+			ui.Yellow.Printf("  - %s\n", line)
+		default:
+			ui.Red.Printf("  x %s\n", line)
+		}
+		if run.Error != "" {
+			fmt.Printf("      %s\n", run.Error)
+		}
+	}
+}
+
+// formatCommitRange shows the commits a run went from and to.
+func formatCommitRange(from, to string) string {
+	switch {
+	case to == "":
+		return "(not synced)"
+	case from == "" || from == to:
+		return shortCommit(to)
+	default:
+		return shortCommit(from) + ".." + shortCommit(to)
+	}
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonHistoryCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "daemon", "history", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "reconcile runs")
+	assert.Contains(t, output, "/history")
+	assert.Contains(t, output, "--limit")
+}
+
+func TestFormatCommitRange(t *testing.T) {
+	assert.Equal(t, "abc1234..def5678", formatCommitRange("abc1234aaaa", "def5678bbbb"))
+	assert.Equal(t, "def5678", formatCommitRange("def5678bbbb", "def5678bbbb"))
+	assert.Equal(t, "def5678", formatCommitRange("", "def5678"))
+	assert.Equal(t, "(not synced)", formatCommitRange("", ""))
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	windowSocket  string
+	windowTCP     string
+	windowToken   string
+	windowTimeout int
+	windowJSON    bool
+)
+
+// deployWindowCmd asks the daemon whether it is safe to deploy.
+var deployWindowCmd = &cobra.Command{
+	Use:     "deploy-window",
+	Aliases: []string{"tide"},
+	Short:   "Check whether it is safe to deploy right now",
+	Long: `Ask the daemon whether it is currently safe to deploy. It is safe when:
+  - The Unraid mover is not running
+  - No freeze window is active (BOSUN_FREEZE_WINDOWS)
+  - No reconcile is in progress
+  - Recent failed reconciles are within the error budget
+
+Exits 0 when safe and 1 when not, so CI jobs and automerge tooling can gate
+on it. Remote tooling can query GET /deploy-window on the TCP API directly;
+it returns 200 when safe and 503 when not.
+
+Examples:
+  bosun deploy-window
+  bosun deploy-window --json
+  bosun deploy-window --tcp 10.0.0.5:9090 --token $BOSUN_BEARER_TOKEN`,
+	Run: runDeployWindow,
+}
+
+func init() {
+	deployWindowCmd.Flags().StringVar(&windowSocket, "socket", daemon.DefaultSocketPath(), "Path to daemon socket")
+	deployWindowCmd.Flags().StringVar(&windowTCP, "tcp", "", "Query the daemon's TCP API at this address instead of the socket")
+	deployWindowCmd.Flags().StringVar(&windowToken, "token", os.Getenv("BOSUN_BEARER_TOKEN"), "Bearer token for the TCP API (default: $BOSUN_BEARER_TOKEN)")
+	deployWindowCmd.Flags().IntVarP(&windowTimeout, "timeout", "t", 10, "Timeout in seconds")
+	deployWindowCmd.Flags().BoolVar(&windowJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(deployWindowCmd)
+}
+
+func runDeployWindow(cmd *cobra.Command, args []string) {
+	client := daemon.NewClient(windowSocket)
+	if windowTCP != "" {
+		client = daemon.NewTCPClient(windowTCP, windowToken)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(windowTimeout)*time.Second)
+	defer cancel()
+
+	window, err := client.DeployWindow(ctx)
+	if err != nil {
+		ui.Fatal("Failed to get deploy window: %v", err)
+	}
+
+	if windowJSON {
+		data, _ := json.MarshalIndent(window, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printDeployWindow(window)
+	}
+
+	if !window.Safe {
+		os.Exit(1)
+	}
+}
+
+func printDeployWindow(window *daemon.DeployWindowResponse) {
+	for _, c := range window.Checks {
+		detail := ""
+		if c.Detail != "" {
+			detail = " (" + c.Detail + ")"
+		}
+		if c.OK {
+			ui.Green.Printf("  * %s%s\n", c.Name, detail)
+		} else {
+			ui.Red.Printf("  x %s%s\n", c.Name, detail)
+		}
+	}
+
+	fmt.Println()
+	if window.Safe {
+		ui.Success("Safe to deploy")
+	} else {
+		ui.Error("Not safe to deploy")
+	}
+}
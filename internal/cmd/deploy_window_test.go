@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployWindowCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "deploy-window", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "safe to deploy")
+	assert.Contains(t, output, "/deploy-window")
+}
@@ -3,21 +3,32 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types/system"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
 	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/daemon"
 	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/preflight"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/timezone"
 	"github.com/cameronsjo/bosun/internal/tunnel"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
@@ -155,6 +166,10 @@ func runStatus(cmd *cobra.Command, args []string) {
 			}
 		}
 
+		// All-stop and pinned stacks (held back from the tracked branch)
+		showAllStop()
+		showPinnedStacks()
+
 		// Resources
 		fmt.Println()
 		ui.Blue.Println("--- Resources ---")
@@ -182,6 +197,22 @@ func runStatus(cmd *cobra.Command, args []string) {
 			fmt.Printf("  Volumes: %s\n", formatBytes(volumeSize))
 		}
 
+		// Host resources (containers alone don't show host pressure)
+		fmt.Println()
+		ui.Blue.Println("--- Host ---")
+		dockerRoot := "/var/lib/docker"
+		if info, err := client.Info(ctx); err == nil && info.DockerRootDir != "" {
+			dockerRoot = info.DockerRootDir
+		}
+		appdata := reconcile.DefaultConfig().LocalAppdataPath
+		if localAppdata := os.Getenv("LOCAL_APPDATA"); localAppdata != "" {
+			appdata = localAppdata
+		}
+		printHostMetrics(hostmetrics.Collect([]hostmetrics.Path{
+			{Label: "docker", Path: dockerRoot},
+			{Label: "appdata", Path: appdata},
+		}))
+
 		// Recent Activity
 		fmt.Println()
 		ui.Blue.Println("--- Recent Activity ---")
@@ -203,12 +234,68 @@ func runStatus(cmd *cobra.Command, args []string) {
 	}
 }
 
+// Host usage thresholds for status coloring.
+const (
+	hostUsageWarnPercent = 80.0
+	hostUsageCritPercent = 90.0
+)
+
+// printHostMetrics prints host load, memory, and disk usage.
+func printHostMetrics(m *hostmetrics.Metrics) {
+	if m == nil || (m.Load == nil && m.Memory == nil && len(m.Disks) == 0) {
+		ui.Yellow.Println("  No host metrics available")
+		return
+	}
+
+	if m.Load != nil {
+		cpus := runtime.NumCPU()
+		line := fmt.Sprintf("  Load: %.2f %.2f %.2f (%d CPUs)\n", m.Load.Load1, m.Load.Load5, m.Load.Load15, cpus)
+		if m.Load.Load5 > float64(cpus) {
+			ui.Yellow.Print(line)
+		} else {
+			fmt.Print(line)
+		}
+	}
+
+	if m.Memory != nil {
+		printHostUsage(fmt.Sprintf("  Memory: %s / %s (%.1f%%)\n",
+			formatBytes(int64(m.Memory.Used())), formatBytes(int64(m.Memory.Total)), m.Memory.UsedPercent()),
+			m.Memory.UsedPercent())
+	}
+
+	for _, d := range m.Disks {
+		printHostUsage(fmt.Sprintf("  Disk (%s): %s / %s (%.1f%%) %s\n",
+			d.Label, formatBytes(int64(d.Used)), formatBytes(int64(d.Used+d.Available)), d.UsedPercent(), d.Path),
+			d.UsedPercent())
+	}
+}
+
+// printHostUsage prints a usage line colored by how close it is to full.
+func printHostUsage(line string, percent float64) {
+	switch {
+	case percent >= hostUsageCritPercent:
+		ui.Red.Print(line)
+	case percent >= hostUsageWarnPercent:
+		ui.Yellow.Print(line)
+	default:
+		fmt.Print(line)
+	}
+}
+
 // logCmd shows release history.
 var logCmd = &cobra.Command{
 	Use:     "log [n]",
 	Aliases: []string{"ledger"},
 	Short:   "Show release history",
-	Long:    "Display recent manifest changes, provisions, and deploy tags.",
+	Long: `Display a summary of the last deploy, then recent manifest changes,
+provisions, and deploy tags.
+
+The summary line comes from the deploy history the reconciler records in the
+state directory, plus a drift check against the running containers:
+
+  last deploy 3 hours ago by webhook at abc1234, drift: none
+
+Use --summary to print only that line, e.g. from a MOTD script.`,
 	Args:    cobra.MaximumNArgs(1),
 	Run:     runLog,
 }
@@ -227,7 +314,19 @@ func runLog(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	st, err := stateStore(logStateDir).Load()
+	if err != nil {
+		ui.Warning("Could not read deploy history: %v", err)
+		st = &state.State{}
+	}
+	summary := deploySummary(st, driftStatus(cfg), time.Now())
+	if logSummary {
+		fmt.Println(summary)
+		return
+	}
+
 	ui.Blue.Println("Release History")
+	fmt.Printf("  %s\n", summary)
 	fmt.Println()
 
 	// Create context with timeout for git commands
@@ -236,10 +335,14 @@ func runLog(cmd *cobra.Command, args []string) {
 
 	// Recent Manifest Changes
 	ui.Blue.Println("--- Recent Manifest Changes ---")
+	manifestPath, err := filepath.Rel(cfg.Root, cfg.ManifestDir)
+	if err != nil {
+		manifestPath = cfg.ManifestDir
+	}
 	gitLog := exec.CommandContext(ctx, "git", "-C", cfg.Root, "log", "--oneline",
 		fmt.Sprintf("-n%d", count),
 		"--format=  %C(yellow)%h%C(reset) %s %C(dim)(%cr)%C(reset)",
-		"--", "manifest/")
+		"--", manifestPath+"/")
 	gitLog.Stdout = os.Stdout
 	gitLog.Stderr = os.Stderr
 	if err := gitLog.Run(); err != nil {
@@ -280,6 +383,28 @@ func runLog(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Println()
+
+	// Drift Remediations (only when the daemon has made any)
+	if len(st.Remediations) > 0 {
+		ui.Blue.Println("--- Drift Remediations ---")
+		showRemediations(st.Remediations, MaxDeployTagsDisplay)
+		fmt.Println()
+	}
+}
+
+// showRemediations prints up to limit of the most recent drift
+// remediations, newest first.
+func showRemediations(remediations []state.Remediation, limit int) {
+	for i := len(remediations) - 1; i >= 0 && i >= len(remediations)-limit; i-- {
+		r := remediations[i]
+		line := fmt.Sprintf("%s %s: %s", timezone.Display(r.At), r.Stack, strings.Join(r.Findings, ", "))
+		if r.OK() {
+			ui.Green.Printf("  * %s\n", line)
+		} else {
+			ui.Red.Printf("  x %s\n", line)
+			fmt.Printf("      %s\n", r.Error)
+		}
+	}
 }
 
 // driftCmd detects config drift between manifests and running state.
@@ -287,13 +412,36 @@ var driftCmd = &cobra.Command{
 	Use:     "drift",
 	Aliases: []string{"compass"},
 	Short:   "Detect config drift - git vs running state",
-	Long:    "Compare manifest services vs running containers, detect image mismatches and orphans.",
-	Run:     runDrift,
+	Long: `Compare manifest services vs running containers, detect image mismatches and orphans.
+
+Use --json or --format json|yaml for a machine-readable report that can feed
+dashboards and automation. The exit code is 1 when drift is found in every format.
+
+Use --alert to also send found drift through the alert providers configured
+in bosun.yml, e.g. from a cron job.`,
+	Run: runDrift,
 }
 
+var (
+	driftJSON   bool
+	driftFormat string
+	driftAlert  bool
+)
+
 func runDrift(cmd *cobra.Command, args []string) {
-	ui.Blue.Println("Checking for drift...")
-	fmt.Println()
+	format := driftFormat
+	if driftJSON {
+		format = "json"
+	}
+	if format != "table" && format != "json" && format != "yaml" {
+		ui.Error("Invalid format %q (want table, json, or yaml)", format)
+		os.Exit(1)
+	}
+
+	if format == "table" {
+		ui.Blue.Println("Checking for drift...")
+		fmt.Println()
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -301,102 +449,208 @@ func runDrift(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	hasDrift := false
-
+	var report *driftReport
 	err = withDockerClient(func(ctx context.Context, client *docker.Client) error {
-		// Get running containers
-		containers, err := client.ListContainers(ctx, true)
+		// Get all containers; stopped ones help explain why a service is down
+		containers, err := client.ListContainers(ctx, false)
 		if err != nil {
 			return fmt.Errorf("list containers: %w", err)
 		}
 
-		runningNames := make(map[string]string) // name -> image
-		for _, ctr := range containers {
-			runningNames[ctr.Name] = ctr.Image
+		// Events are best-effort context; without them causes fall back to container state
+		events, _ := client.RecentEvents(ctx, time.Now().Add(-driftEventWindow))
+		report = buildDriftReport(cfg, containers, events)
+		applyConfigDrift(report, containers, stackConfigHashes(ctx, cfg))
+		if st := loadDeployState(reconcile.ResolveStateDir("")); st != nil {
+			applyDeployState(report, st)
 		}
+		return nil
+	})
 
-		if len(runningNames) == 0 {
-			ui.Yellow.Println("No containers running")
-			return nil
+	if err != nil {
+		ui.Error("Docker not available: %v", err)
+		os.Exit(1)
+	}
+
+	if err := printDriftReport(report, format); err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	if report.Drift {
+		recordDriftEvent(reconcile.ResolveStateDir(""), time.Now())
+		if driftAlert {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			providers, err := sendDriftAlert(ctx, cfg.GetAlertConfig(), report)
+			cancel()
+			if err != nil {
+				ui.Error("Could not send drift alert: %v", err)
+			} else if format == "table" {
+				ui.Info("Drift alert sent: %s", strings.Join(providers, ", "))
+			}
 		}
+		os.Exit(1)
+	}
+}
 
-		ui.Blue.Println("--- Container Drift ---")
+// driftEventWindow is how far back drift explanations look for container events.
+const driftEventWindow = 24 * time.Hour
 
-		// Check each stack's compose file
-		composeDir := filepath.Join(cfg.OutputDir(), "compose")
-		stackFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+// driftCause is the probable cause of a drift finding and the command that fixes it.
+type driftCause struct {
+	Cause string `json:"cause" yaml:"cause"`
+	Fix   string `json:"fix" yaml:"fix"`
+}
 
-		allExpected := make(map[string]bool)
+// printDriftCause prints a cause beneath its drift finding.
+func printDriftCause(c driftCause) {
+	fmt.Printf("      Cause:    %s\n", c.Cause)
+	fmt.Printf("      Fix:      %s\n", c.Fix)
+}
 
-		for _, stackFile := range stackFiles {
-			stackName := strings.TrimSuffix(filepath.Base(stackFile), ".yml")
-			expected := extractServicesFromCompose(stackFile)
-
-			for svc, expectedImage := range expected {
-				allExpected[svc] = true
-
-				runningImage, isRunning := runningNames[svc]
-				if isRunning {
-					// Use normalized comparison to avoid false positives from tag vs digest
-					if expectedImage != "" && normalizeImage(runningImage) != normalizeImage(expectedImage) {
-						ui.Yellow.Printf("  ~ %s: image drift\n", svc)
-						fmt.Printf("      Expected: %s\n", expectedImage)
-						fmt.Printf("      Running:  %s\n", runningImage)
-						hasDrift = true
-					} else {
-						ui.Green.Printf("  * %s\n", svc)
-					}
-				} else {
-					ui.Red.Printf("  x %s: not running (expected by %s)\n", svc, stackName)
-					hasDrift = true
-				}
-			}
+// driftHistory is the deploy history and recent container events used to
+// explain drift findings.
+type driftHistory struct {
+	// deployedAt maps stack name to when its compose file was last rendered.
+	deployedAt map[string]time.Time
+	// events are recent container events, oldest first.
+	events []docker.ContainerEvent
+}
+
+// newDriftHistory builds drift history from rendered stack files and events.
+func newDriftHistory(stackFiles []string, events []docker.ContainerEvent) *driftHistory {
+	h := &driftHistory{deployedAt: make(map[string]time.Time), events: events}
+	for _, f := range stackFiles {
+		if info, err := os.Stat(f); err == nil {
+			h.deployedAt[strings.TrimSuffix(filepath.Base(f), ".yml")] = info.ModTime()
+		}
+	}
+	return h
+}
+
+// explainImageDrift classifies a running container whose image differs from its manifest.
+func (h *driftHistory) explainImageDrift(stack string, ctr docker.ContainerInfo) driftCause {
+	deployedAt, ok := h.deployedAt[stack]
+	if ok && ctr.Created.After(deployedAt) {
+		return driftCause{
+			Cause: "image updated out-of-band (container recreated after last deploy)",
+			Fix:   "bosun yacht up",
 		}
+	}
+	return driftCause{
+		Cause: "stack not yet deployed (manifest changed after container was created)",
+		Fix:   "bosun yacht up",
+	}
+}
 
-		// Check for orphaned containers
-		fmt.Println()
-		ui.Blue.Println("--- Orphaned Containers ---")
-		orphansFound := false
-		infraContainerList := cfg.InfraContainers()
-		for name := range runningNames {
-			// Skip known infrastructure
-			isInfra := false
-			for _, infra := range append(infraContainerList, "bosun") {
-				if name == infra {
-					isInfra = true
-					break
-				}
-			}
-			if isInfra {
-				continue
-			}
+// explainNotRunning classifies an expected service that is not running.
+// ctr is nil when no container exists for the service.
+func (h *driftHistory) explainNotRunning(svc string, ctr *docker.ContainerInfo) driftCause {
+	if ctr == nil {
+		return driftCause{
+			Cause: "stack not yet deployed (no container exists)",
+			Fix:   "bosun yacht up",
+		}
+	}
+	if ctr.State == "created" {
+		return driftCause{
+			Cause: "container created but never started",
+			Fix:   fmt.Sprintf("bosun crew restart %s", svc),
+		}
+	}
 
-			if !allExpected[name] {
-				ui.Yellow.Printf("  ? %s: not in any manifest\n", name)
-				orphansFound = true
-				hasDrift = true
-			}
+	// Replay recent events: docker stop/kill emit "kill" before "die",
+	// a crash emits only "die", and a restart clears what came before.
+	stopped, oom := false, false
+	exitCode := ""
+	for _, ev := range h.events {
+		if ev.Name != svc {
+			continue
 		}
+		switch ev.Action {
+		case "kill", "stop":
+			stopped = true
+		case "oom":
+			oom = true
+		case "die":
+			exitCode = ev.ExitCode
+		case "start":
+			stopped, oom, exitCode = false, false, ""
+		}
+	}
+	if exitCode == "" {
+		exitCode = parseExitCode(ctr.Status)
+	}
 
-		if !orphansFound {
-			ui.Green.Println("  * No orphaned containers")
+	switch {
+	case oom:
+		return driftCause{
+			Cause: "crashed (out of memory)",
+			Fix:   fmt.Sprintf("bosun crew logs %s", svc),
+		}
+	case stopped:
+		return driftCause{
+			Cause: "container manually stopped",
+			Fix:   fmt.Sprintf("bosun crew restart %s", svc),
+		}
+	case exitCode == "137" || exitCode == "143":
+		// SIGKILL/SIGTERM with no event history is almost always a manual stop
+		return driftCause{
+			Cause: fmt.Sprintf("container manually stopped (exit %s)", exitCode),
+			Fix:   fmt.Sprintf("bosun crew restart %s", svc),
 		}
+	case exitCode == "0":
+		return driftCause{
+			Cause: "container exited cleanly (exit 0)",
+			Fix:   fmt.Sprintf("bosun crew restart %s", svc),
+		}
+	case exitCode != "":
+		return driftCause{
+			Cause: fmt.Sprintf("crashed (exit %s)", exitCode),
+			Fix:   fmt.Sprintf("bosun crew logs %s", svc),
+		}
+	}
+	return driftCause{
+		Cause: fmt.Sprintf("container %s", ctr.State),
+		Fix:   fmt.Sprintf("bosun crew restart %s", svc),
+	}
+}
 
-		return nil
-	})
+// explainOrphan classifies a running container that no manifest expects.
+// Compose labels reveal which stack file started it.
+func (h *driftHistory) explainOrphan(ctr docker.ContainerInfo) driftCause {
+	fix := fmt.Sprintf("bosun overboard %s", ctr.Name)
 
-	if err != nil {
-		ui.Error("Docker not available: %v", err)
-		os.Exit(1)
+	configFiles := ctr.Labels["com.docker.compose.project.config_files"]
+	if configFiles == "" {
+		if project := ctr.Labels["com.docker.compose.project"]; project != "" {
+			return driftCause{Cause: fmt.Sprintf("orphan from compose project %s", project), Fix: fix}
+		}
+		return driftCause{Cause: "started manually (not managed by compose)", Fix: fix}
 	}
 
-	fmt.Println()
-	if hasDrift {
-		ui.Yellow.Println("Drift detected. Run 'bosun yacht up' to reconcile.")
-		os.Exit(1)
-	} else {
-		ui.Green.Println("* No drift - running state matches manifests")
+	first, _, _ := strings.Cut(configFiles, ",")
+	stack := strings.TrimSuffix(filepath.Base(first), filepath.Ext(first))
+	if _, ok := h.deployedAt[stack]; ok {
+		return driftCause{
+			Cause: fmt.Sprintf("orphan from removed manifest (service no longer in stack %s)", stack),
+			Fix:   fix,
+		}
 	}
+	return driftCause{
+		Cause: fmt.Sprintf("orphan from removed manifest (stack %s no longer rendered)", stack),
+		Fix:   fix,
+	}
+}
+
+// exitCodePattern extracts the exit code from a container status like "Exited (137) 2 hours ago".
+var exitCodePattern = regexp.MustCompile(`^Exited \((-?\d+)\)`)
+
+// parseExitCode returns the exit code from a container status, or "" if none.
+func parseExitCode(status string) string {
+	if m := exitCodePattern.FindStringSubmatch(status); m != nil {
+		return m[1]
+	}
+	return ""
 }
 
 // doctorCmd runs pre-flight checks.
@@ -404,8 +658,41 @@ var doctorCmd = &cobra.Command{
 	Use:     "doctor",
 	Aliases: []string{"checkup"},
 	Short:   "Pre-flight checks - is the ship seaworthy?",
-	Long:    "Run diagnostic checks for Docker, Git, SOPS, and other dependencies.",
-	Run:     runDoctor,
+	Long: `Run diagnostic checks for Docker, Git, SOPS, and other dependencies.
+
+Every check has a stable ID and a severity:
+
+  docker          critical   Docker daemon is reachable
+  docker-userns   warning    User namespace remapping
+  compose         critical   Docker Compose is installed
+  git             critical   Git is installed
+  project-root    warning    A bosun project is found
+  project-config  info       .bosun/config.yml or bosun.yml exists
+  age-key         warning    An age key is available
+  sops            warning    SOPS is installed
+  manifest-dir    warning    The manifest directory exists
+  known-hosts     warning    The DEPLOY_TARGET host key is in known_hosts
+  bind-mounts     critical   Bind mount sources exist
+  webhook         info       The webhook endpoint answers
+  completion      info       Shell completion is installed
+  tunnel          info       The tunnel provider is connected
+
+By default doctor exits nonzero when any check fails. With --fail-on, it
+exits nonzero when any check at or above that severity fails or warns.
+
+With --fix, doctor offers to fix what it can, asking before each change:
+it generates a missing age key, creates missing manifest directories, adds
+the deploy target's host key to known_hosts (showing its fingerprint), and
+scaffolds .bosun/config.yml. Fixed checks are run again.
+
+Examples:
+  bosun doctor                                   # Run every check
+  bosun doctor --skip webhook,tunnel             # Skip checks that can't pass here
+  bosun doctor --fix                             # Fix what it can, asking first
+  bosun doctor --only docker,compose,sops \
+    --fail-on warning --format json              # Gate CI on what it needs`,
+	Args: cobra.NoArgs,
+	Run:  runDoctor,
 }
 
 // checkDocker verifies Docker is running and accessible.
@@ -439,14 +726,75 @@ func checkDocker(ctx context.Context) CheckResult {
 	return result
 }
 
-// checkDockerCompose verifies Docker Compose v2 is installed.
-func checkDockerCompose() CheckResult {
-	composeCmd := exec.Command("docker", "compose", "version", "--short")
-	if output, err := composeCmd.Output(); err == nil {
-		version := strings.TrimSpace(string(output))
-		ui.Green.Printf("  * Docker Compose v2 (%s)\n", version)
+// checkDockerUserns reports whether Docker runs rootless or with
+// userns-remap, and the defaults bosun adjusts for it.
+func checkDockerUserns(ctx context.Context) CheckResult {
+	var info system.Info
+	err := withDockerClientContext(ctx, func(client *docker.Client) error {
+		var err error
+		info, err = client.Info(ctx)
+		return err
+	})
+	if err != nil {
+		// checkDocker already reported an unreachable daemon.
+		return CheckResult{}
+	}
+	return reportDockerUserns(info, unprivilegedPortStart())
+}
+
+// reportDockerUserns prints the user namespace mode in info. portStart is the
+// lowest port unprivileged users can bind (0 when unknown).
+func reportDockerUserns(info system.Info, portStart int) CheckResult {
+	switch docker.ParseUsernsMode(info.SecurityOptions) {
+	case docker.UsernsRootless:
+		ui.Green.Printf("  * Docker is rootless (socket %s)\n", docker.SocketPath())
+		ui.Blue.Printf("      Daemon socket: %s\n", daemon.DefaultSocketPath())
+		ui.Blue.Printf("      Docker root: %s\n", info.DockerRootDir)
+		ui.Blue.Println("      Ownership rules: applied from a helper container")
+		result := CheckResult{Passed: 1}
+		if portStart > 80 {
+			ui.Yellow.Printf("  ~ Rootless Docker cannot publish ports below %d (e.g. 80 and 443)\n", portStart)
+			ui.Blue.Println("      To fix this:")
+			ui.Blue.Println("      - sysctl -w net.ipv4.ip_unprivileged_port_start=80")
+			result.Warned++
+		}
+		if root := os.Getenv("BOSUN_DOCKER_ROOT_DIR"); root != "" && root != info.DockerRootDir {
+			ui.Yellow.Printf("  ~ BOSUN_DOCKER_ROOT_DIR is %s, but Docker's root is %s\n", root, info.DockerRootDir)
+			result.Warned++
+		}
+		return result
+	case docker.UsernsRemap:
+		uid, gid, err := docker.ParseRemapRoot(info.DockerRootDir)
+		if err != nil {
+			ui.Yellow.Printf("  ~ Docker uses userns-remap, but %v\n", err)
+			ui.Blue.Println("      Set BOSUN_DOCKER_USERNS=none to apply ownership rules unmapped")
+			return CheckResult{Warned: 1}
+		}
+		ui.Green.Printf("  * Docker uses userns-remap (container root is host %d:%d)\n", uid, gid)
+		ui.Blue.Printf("      Ownership rules: IDs offset by %d:%d\n", uid, gid)
 		return CheckResult{Passed: 1}
 	}
+	ui.Green.Println("  * Docker runs as root without user namespace remapping")
+	return CheckResult{Passed: 1}
+}
+
+// unprivilegedPortStart returns the lowest port unprivileged processes may
+// bind on this machine, or 0 when it can't be read.
+func unprivilegedPortStart() int {
+	data, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start")
+	if err != nil {
+		return 0
+	}
+	port, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return port
+}
+
+// checkDockerCompose verifies Docker Compose v2 is installed and recent
+// enough for rendered stacks.
+func checkDockerCompose() CheckResult {
+	if output, err := toolVersionOutput("docker", "compose", "version", "--short"); err == nil {
+		return reportComposeVersion(output)
+	}
 	ui.Red.Println("  x Docker Compose v2 not found")
 	ui.Blue.Println("      To fix this:")
 	ui.Blue.Println("      - Install Docker Desktop (includes Compose v2)")
@@ -454,11 +802,11 @@ func checkDockerCompose() CheckResult {
 	return CheckResult{Failed: 1}
 }
 
-// checkGit verifies Git is installed.
+// checkGit verifies Git is installed and recent enough.
 func checkGit() CheckResult {
-	if _, err := exec.LookPath("git"); err == nil {
-		ui.Green.Println("  * Git is installed")
-		return CheckResult{Passed: 1}
+	if gitPath, err := exec.LookPath("git"); err == nil {
+		output, _ := toolVersionOutput(gitPath, "--version")
+		return reportGitVersion(output)
 	}
 	ui.Red.Println("  x Git not found")
 	ui.Blue.Println("      To fix this:")
@@ -485,34 +833,25 @@ func checkProjectRoot(cfg *config.Config) CheckResult {
 
 // checkAgeKey verifies the Age key exists for SOPS decryption.
 func checkAgeKey() CheckResult {
-	ageKeyFile := os.Getenv("SOPS_AGE_KEY_FILE")
-	if ageKeyFile == "" {
-		home, _ := os.UserHomeDir()
-		ageKeyFile = filepath.Join(home, ".config", "sops", "age", "keys.txt")
-	}
-	if _, err := os.Stat(ageKeyFile); err == nil {
-		ui.Green.Printf("  * Age key found: %s\n", ageKeyFile)
+	keyFile := ageKeyFile()
+	if _, err := os.Stat(keyFile); err == nil {
+		ui.Green.Printf("  * Age key found: %s\n", keyFile)
 		return CheckResult{Passed: 1}
 	}
-	ui.Yellow.Printf("  ! Age key not found at %s\n", ageKeyFile)
+	ui.Yellow.Printf("  ! Age key not found at %s\n", keyFile)
 	ui.Blue.Println("      To fix this:")
-	ui.Blue.Printf("      - Run: age-keygen -o %s\n", ageKeyFile)
+	ui.Blue.Printf("      - Run: age-keygen -o %s\n", keyFile)
+	ui.Blue.Println("      - Or run: bosun doctor --fix")
 	ui.Blue.Println("      - Or set SOPS_AGE_KEY_FILE env var to existing key")
 	ui.Blue.Println("      - Install age: https://github.com/FiloSottile/age#installation")
 	return CheckResult{Warned: 1}
 }
 
-// checkSOPS verifies SOPS is installed.
+// checkSOPS verifies SOPS is installed and recent enough.
 func checkSOPS() CheckResult {
 	if sopsPath, err := exec.LookPath("sops"); err == nil {
-		versionCmd := exec.Command(sopsPath, "--version")
-		if output, err := versionCmd.Output(); err == nil {
-			version := strings.TrimSpace(string(output))
-			ui.Green.Printf("  * SOPS is installed (%s)\n", version)
-		} else {
-			ui.Green.Println("  * SOPS is installed")
-		}
-		return CheckResult{Passed: 1}
+		output, _ := toolVersionOutput(sopsPath, "--version")
+		return reportSOPSVersion(output)
 	}
 	ui.Yellow.Println("  ! SOPS not found (needed for secrets)")
 	ui.Blue.Println("      To fix this:")
@@ -540,6 +879,49 @@ func checkManifestDirectory(cfg *config.Config) CheckResult {
 	return CheckResult{Warned: 1}
 }
 
+// checkBindMounts probes bind-mount source paths from rendered compose files
+// for stale NFS/FUSE handles and hung mounts.
+func checkBindMounts(cfg *config.Config) CheckResult {
+	if cfg == nil {
+		return CheckResult{} // Skip if no config
+	}
+
+	composeFiles, _ := filepath.Glob(filepath.Join(cfg.OutputDir(), "compose", "*.yml"))
+	seen := make(map[string]bool)
+	var paths []string
+	for _, composeFile := range composeFiles {
+		sources, err := preflight.BindMountSources(composeFile)
+		if err != nil {
+			continue
+		}
+		for _, s := range sources {
+			if !seen[s] {
+				seen[s] = true
+				paths = append(paths, s)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return CheckResult{} // Nothing to probe
+	}
+
+	failed := preflight.CheckMountPaths(paths, preflight.DefaultMountProbeTimeout)
+	if len(failed) == 0 {
+		ui.Green.Printf("  * Bind mounts responsive (%d paths)\n", len(paths))
+		return CheckResult{Passed: 1}
+	}
+
+	for _, f := range failed {
+		ui.Red.Printf("  x Bind mount unhealthy: %v\n", f.Error)
+	}
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Println("      - Remount the share: umount -l <path> && mount <path>")
+	ui.Blue.Println("      - On Unraid, wait for the mover to finish, then restart affected containers")
+	ui.Blue.Println("      - Check NFS server / FUSE daemon health")
+	return CheckResult{Failed: 1}
+}
+
 // checkWebhook verifies the webhook endpoint is responding.
 func checkWebhook() CheckResult {
 	httpClient := &http.Client{Timeout: httpClientTimeout}
@@ -594,9 +976,9 @@ func checkTunnel(ctx context.Context, cfg *config.Config) CheckResult {
 	if status.Connected {
 		ui.Green.Printf("  * %s is connected", capitalizeProviderName(providerName))
 		if status.Hostname != "" {
-			fmt.Printf(" (%s)", status.Hostname)
+			ui.Green.Printf(" (%s)", status.Hostname)
 		}
-		fmt.Println()
+		ui.Green.Println()
 		return CheckResult{Passed: 1}
 	}
 
@@ -628,31 +1010,54 @@ func capitalizeProviderName(name string) string {
 }
 
 func runDoctor(cmd *cobra.Command, args []string) {
-	ui.Blue.Println("Running pre-flight checks...")
-	fmt.Println()
-
-	var result CheckResult
+	if doctorFormat != "text" && doctorFormat != "json" {
+		ui.Fatal("Invalid format %q (want text or json)", doctorFormat)
+	}
+	if err := validateFailOn(doctorFailOn); err != nil {
+		ui.Fatal("%v", err)
+	}
+	checks, err := selectDoctorChecks(doctorOnly, doctorSkip)
+	if err != nil {
+		ui.Fatal("%v", err)
+	}
+	if doctorFixMode && doctorFormat == "json" {
+		ui.Fatal("--fix asks before each change, so it only works with --format text")
+	}
+	if doctorFixMode && !isTerminal() {
+		ui.Fatal("--fix asks before each change; run it in a terminal")
+	}
 
 	// Load config once for checks that need it
 	cfg, _ := config.Load()
 
-	// Run all checks with timeout context for Docker
-	ctx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
-	result.Add(checkDocker(ctx))
-	cancel()
+	if doctorFormat == "json" {
+		results := make([]doctorResult, 0, len(checks))
+		for _, c := range checks {
+			_, res := runDoctorCheck(c, cfg, true)
+			results = append(results, res)
+		}
+		report := newDoctorReport(results, doctorFailOn)
+		if err := writeDoctorJSON(os.Stdout, report); err != nil {
+			ui.Fatal("Failed to encode report: %v", err)
+		}
+		exitDoctor(report)
+		return
+	}
 
-	result.Add(checkDockerCompose())
-	result.Add(checkGit())
-	result.Add(checkProjectRoot(cfg))
-	result.Add(checkAgeKey())
-	result.Add(checkSOPS())
-	result.Add(checkManifestDirectory(cfg))
-	result.Add(checkWebhook())
+	ui.Blue.Println("Running pre-flight checks...")
+	fmt.Println()
 
-	// Check tunnel provider with timeout
-	tunnelCtx, tunnelCancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
-	result.Add(checkTunnel(tunnelCtx, cfg))
-	tunnelCancel()
+	var result CheckResult
+	results := make([]doctorResult, 0, len(checks))
+	for _, c := range checks {
+		counts, res := runDoctorCheck(c, cfg, false)
+		if doctorFixMode && (res.Status == checkStatusFail || res.Status == checkStatusWarn) && offerDoctorFix(c, cfg) {
+			counts, res = runDoctorCheck(c, cfg, false)
+		}
+		result.Add(counts)
+		results = append(results, res)
+	}
+	report := newDoctorReport(results, doctorFailOn)
 
 	// Summary
 	fmt.Println()
@@ -663,11 +1068,11 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	fmt.Printf(", ")
 	ui.Red.Printf("%d failed\n", result.Failed)
 
-	if result.Failed > 0 {
+	if !report.OK {
 		fmt.Println()
 		ui.Red.Println("Ship not seaworthy! Fix errors above.")
-		os.Exit(1)
-	} else if result.Warned > 0 {
+		exitDoctor(report)
+	} else if result.Failed > 0 || result.Warned > 0 {
 		fmt.Println()
 		ui.Yellow.Println("Ship can sail, but check warnings.")
 	} else {
@@ -676,14 +1081,28 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	}
 }
 
+// lintStrict promotes lint warnings to errors.
+var lintStrict bool
+
 // lintCmd validates manifests before deploy.
 var lintCmd = &cobra.Command{
 	Use:     "lint [target]",
 	Aliases: []string{"inspect"},
 	Short:   "Validate all manifests before deploy",
-	Long:    "Validate provisions, services, dependencies, and port conflicts.",
-	Args:    cobra.MaximumNArgs(1),
-	Run:     runLint,
+	Long: `Validate provisions, services, dependencies, and port conflicts.
+
+Each check reports errors, warnings, or info findings. Severities can be
+changed per check in the lint: section of .bosun/config.yml or bosun.yml:
+
+  lint:
+    severity:
+      coupling: error
+      ports: warn
+
+Exit codes: 0 when no errors were found, 1 when errors were found (or
+warnings, with --strict), 2 when lint could not run.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runLint,
 }
 
 func runLint(cmd *cobra.Command, args []string) {
@@ -693,21 +1112,25 @@ func runLint(cmd *cobra.Command, args []string) {
 	cfg, err := config.Load()
 	if err != nil {
 		ui.Error("Failed to load config: %v", err)
-		os.Exit(1)
+		os.Exit(lintExitFailed)
 	}
 
 	if _, err := os.Stat(cfg.ManifestDir); os.IsNotExist(err) {
 		ui.Error("Manifest directory not found")
-		os.Exit(1)
+		os.Exit(lintExitFailed)
 	}
 
-	errors := 0
+	report, err := newLintReport(cfg.GetLintConfig().Severity, lintStrict)
+	if err != nil {
+		ui.Error("Invalid lint config: %v", err)
+		os.Exit(lintExitFailed)
+	}
 
 	// Check provisions exist
 	provisionsDir := cfg.ProvisionsDir()
 	if _, err := os.Stat(provisionsDir); os.IsNotExist(err) {
 		ui.Error("Provisions directory not found")
-		errors++
+		report.add("provisions", 1)
 	} else {
 		files, _ := filepath.Glob(filepath.Join(provisionsDir, "*.yml"))
 		ui.Green.Printf("* Found %d provisions\n", len(files))
@@ -716,80 +1139,182 @@ func runLint(cmd *cobra.Command, args []string) {
 	// Validate services
 	servicesDir := cfg.ServicesDir()
 	if _, err := os.Stat(servicesDir); err == nil {
-		fmt.Println()
-		fmt.Println("Validating services:")
+		report.heading("schema", "Validating services")
 		serviceFiles, _ := filepath.Glob(filepath.Join(servicesDir, "*.yml"))
 
 		for _, serviceFile := range serviceFiles {
 			name := filepath.Base(serviceFile)
-			if validateServiceFile(serviceFile, cfg.ManifestDir) {
-				ui.Green.Printf("  * %s\n", name)
-			} else {
+			if err := validateServiceFile(serviceFile, cfg.ManifestDir); err != nil {
 				ui.Red.Printf("  x %s\n", name)
-				errors++
+				printSchemaErrors(err)
+				report.add("schema", 1)
+			} else {
+				ui.Green.Printf("  * %s\n", name)
 			}
 		}
 	}
 
+	// Check template variables
+	if _, err := os.Stat(servicesDir); err == nil {
+		report.heading("variables", "Checking template variables")
+		loadHostFacts(cmd.Context())
+		loadServicePorts(servicesDir)
+		undefined := checkTemplateVariables(servicesDir, provisionsDir)
+		if undefined == 0 {
+			ui.Green.Println("  * All variables defined")
+		} else {
+			report.add("variables", undefined)
+		}
+	}
+
+	// Check pinned provision versions
+	if _, err := os.Stat(servicesDir); err == nil {
+		report.heading("provision-versions", "Checking provision versions")
+		outdated := checkProvisionVersions(servicesDir, provisionsDir)
+		if outdated == 0 {
+			ui.Green.Println("  * No outdated provision pins")
+		}
+		report.add("provision-versions", outdated)
+	}
+
 	// Validate stacks
 	stacksDir := cfg.StacksDir()
 	if _, err := os.Stat(stacksDir); err == nil {
-		fmt.Println()
-		fmt.Println("Validating stacks:")
+		report.heading("schema", "Validating stacks")
 		stackFiles, _ := filepath.Glob(filepath.Join(stacksDir, "*.yml"))
 
 		for _, stackFile := range stackFiles {
 			name := filepath.Base(stackFile)
-			if validateStackFile(stackFile, cfg.ManifestDir) {
-				ui.Green.Printf("  * %s\n", name)
-			} else {
+			if err := validateStackFile(stackFile, cfg.ManifestDir); err != nil {
 				ui.Red.Printf("  x %s\n", name)
-				errors++
+				printSchemaErrors(err)
+				report.add("schema", 1)
+			} else {
+				ui.Green.Printf("  * %s\n", name)
 			}
 		}
 	}
 
+	// Render stacks with the Go renderer; the dependency and cycle checks
+	// use the result instead of the last provisioned output.
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	cleanup := func() {}
+	if _, err := os.Stat(stacksDir); err == nil {
+		renderDir, err := os.MkdirTemp("", "bosun-lint-*")
+		if err != nil {
+			ui.Error("Failed to create render directory: %v", err)
+			os.Exit(lintExitFailed)
+		}
+		cleanup = func() { os.RemoveAll(renderDir) }
+		defer cleanup()
+
+		report.heading("render", "Rendering stacks")
+		report.add("render", renderStacksForLint(cfg, renderDir))
+		composeDir = renderDir
+	}
+
 	// Check dependencies
-	fmt.Println()
-	fmt.Println("Validating dependencies:")
-	depWarnings := checkDependencies(cfg)
+	report.heading("dependencies", "Validating dependencies")
+	depWarnings := checkDependencies(composeDir)
 	if depWarnings == 0 {
 		ui.Green.Println("  * All dependencies look correct")
 	}
+	report.add("dependencies", depWarnings)
 
 	// Check port conflicts
-	fmt.Println()
-	fmt.Println("Checking for port conflicts:")
+	report.heading("ports", "Checking for port conflicts")
 	portConflicts := checkPortConflicts(cfg)
 	if portConflicts == 0 {
 		ui.Green.Println("  * No port conflicts detected")
-	} else {
-		errors += portConflicts
 	}
+	report.add("ports", portConflicts)
 
 	// Check for dependency cycles
-	fmt.Println()
-	fmt.Println("Checking for dependency cycles:")
-	cycles := checkDependencyCycles(cfg)
+	report.heading("cycles", "Checking for dependency cycles")
+	cycles := checkDependencyCycles(composeDir)
 	if len(cycles) == 0 {
 		ui.Green.Println("  * No dependency cycles detected")
 	} else {
 		for _, cycle := range cycles {
 			ui.Red.Printf("  x Cycle detected: %s\n", cycle)
 		}
-		errors += len(cycles)
 	}
+	report.add("cycles", len(cycles))
+
+	// Check for sharing between stacks that breaks scoped deploys
+	report.heading("coupling", "Checking cross-stack coupling")
+	coupling := checkStackCoupling(composeDir)
+	if coupling == 0 {
+		ui.Green.Println("  * No unintended sharing between stacks")
+	}
+	report.add("coupling", coupling)
 
 	// Summary
-	fmt.Println()
-	if errors > 0 {
-		ui.Red.Printf("Found %d error(s). Fix before deploying.\n", errors)
-		os.Exit(1)
-	} else {
-		ui.Green.Println("* All manifests valid!")
+	report.printSummary()
+	if code := report.exitCode(); code != lintExitOK {
+		cleanup()
+		os.Exit(code)
 	}
 }
 
+// checkTemplateVariables reports undefined ${var} references per service,
+// located at the provisions entry that needs each, with did-you-mean
+// suggestions. Returns the number of undefined references.
+func checkTemplateVariables(servicesDir, provisionsDir string) int {
+	undefined := 0
+	serviceFiles, _ := filepath.Glob(filepath.Join(servicesDir, "*.yml"))
+
+	for _, serviceFile := range serviceFiles {
+		m, err := manifest.LoadServiceManifest(serviceFile)
+		if err != nil {
+			continue // Reported by service validation
+		}
+		content, err := os.ReadFile(serviceFile)
+		if err != nil {
+			continue
+		}
+
+		errs, err := manifest.ValidateServiceProvisions(serviceFile, content, m, provisionsDir)
+		if err != nil {
+			ui.Yellow.Printf("  ! %s: %v\n", m.Name, err)
+		}
+		if len(errs) > 0 {
+			ui.Red.Printf("  x %s\n", m.Name)
+			printSchemaErrors(errs)
+			undefined += len(errs)
+		}
+	}
+
+	return undefined
+}
+
+// checkProvisionVersions warns about services pinned to provision versions
+// that differ from the current provision library. Returns the number of
+// warnings.
+func checkProvisionVersions(servicesDir, provisionsDir string) int {
+	warnings := 0
+	serviceFiles, _ := filepath.Glob(filepath.Join(servicesDir, "*.yml"))
+
+	for _, serviceFile := range serviceFiles {
+		m, err := manifest.LoadServiceManifest(serviceFile)
+		if err != nil {
+			continue // Reported by service validation
+		}
+
+		outdated, err := manifest.OutdatedProvisions(m, provisionsDir)
+		if err != nil {
+			ui.Yellow.Printf("  ! %s: %v\n", m.Name, err)
+			warnings++
+		}
+		for _, o := range outdated {
+			ui.Yellow.Printf("  ~ %s: %s\n", m.Name, o)
+			warnings++
+		}
+	}
+
+	return warnings
+}
+
 // Helper functions
 
 func formatBytes(bytes int64) string {
@@ -811,7 +1336,8 @@ func formatBytes(bytes int64) string {
 
 func showProvisionTimestamps(outputDir, manifestDir string) {
 	count := 0
-	_ = filepath.WalkDir(outputDir, func(path string, d os.DirEntry, err error) error {
+	root := fileutil.ResolveDir(outputDir)
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".yml") {
 			return nil
 		}
@@ -819,8 +1345,9 @@ func showProvisionTimestamps(outputDir, manifestDir string) {
 			return nil
 		}
 		info, _ := d.Info()
-		relPath, _ := filepath.Rel(manifestDir, path)
-		fmt.Printf("  %s  (%s)\n", relPath, info.ModTime().Format("2006-01-02 15:04"))
+		relPath, _ := filepath.Rel(root, path)
+		relPath, _ = filepath.Rel(manifestDir, filepath.Join(outputDir, relPath))
+		fmt.Printf("  %s  (%s)\n", relPath, timezone.Display(info.ModTime()))
 		count++
 		return nil
 	})
@@ -874,35 +1401,50 @@ func normalizeImage(image string) string {
 	return image
 }
 
-func validateServiceFile(filename, _ string) bool {
+// validateServiceFile checks a service manifest against the schema. The
+// error is a manifest.SchemaErrors when the file was read.
+func validateServiceFile(filename, _ string) error {
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return false
+		return err
 	}
-
-	hasName := strings.Contains(string(content), "name:")
-	hasProvisions := strings.Contains(string(content), "provisions:")
-
-	return hasName && hasProvisions
+	if errs := manifest.ValidateServiceSchema(filename, content); len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
-func validateStackFile(filename, _ string) bool {
+// validateStackFile checks a stack manifest against the schema. Stacks
+// without include are warnings, not errors.
+func validateStackFile(filename, _ string) error {
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return false
+		return err
 	}
+	if errs := manifest.ValidateStackSchema(filename, content); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
 
-	// Stacks without include are warnings, not errors
-	// Basic validation: file is readable
-	_ = content
-	return true
+// printSchemaErrors prints each located schema error under a lint result,
+// or the error itself when it isn't a schema error.
+func printSchemaErrors(err error) {
+	var schemaErrs manifest.SchemaErrors
+	if !errors.As(err, &schemaErrs) {
+		ui.Red.Printf("      %v\n", err)
+		return
+	}
+	for _, e := range schemaErrs {
+		ui.Red.Printf("      %s\n", e)
+	}
 }
 
-func checkDependencies(cfg *config.Config) int {
+// checkDependencies warns about likely missing depends_on entries in the
+// compose files in composeDir.
+func checkDependencies(composeDir string) int {
 	warnings := 0
 
-	// Check rendered compose files in output directory
-	composeDir := filepath.Join(cfg.OutputDir(), "compose")
 	composeFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
 
 	for _, composeFile := range composeFiles {
@@ -966,37 +1508,13 @@ type ServiceWithPorts struct {
 // Returns a map of host port -> service name.
 func extractPorts(filename string) map[int]string {
 	portMap := make(map[int]string)
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return portMap
-	}
-
-	var compose ComposeFileWithPorts
-	if err := yaml.Unmarshal(data, &compose); err != nil {
-		return portMap
-	}
-
-	for serviceName, service := range compose.Services {
-		// Extract ports from ports: section
-		for _, portEntry := range service.Ports {
-			ports := parsePortEntry(portEntry)
-			for _, port := range ports {
-				portMap[port] = serviceName
-			}
-		}
-
-		// Extract ports from traefik labels
-		for labelKey, labelValue := range service.Labels {
-			if strings.Contains(labelKey, "loadbalancer.server.port") {
-				port, err := strconv.Atoi(labelValue)
-				if err == nil && port > 0 {
-					portMap[port] = serviceName + " (traefik)"
-				}
-			}
+	for _, claim := range extractPortClaims(filename) {
+		if claim.Source == portSourceTraefik {
+			portMap[claim.Port] = claim.Service + " (traefik)"
+		} else {
+			portMap[claim.Port] = claim.Service
 		}
 	}
-
 	return portMap
 }
 
@@ -1083,44 +1601,14 @@ func parsePortString(portStr string) []int {
 	return ports
 }
 
+// checkPortConflicts reports ports claimed by more than one service in the
+// port registry (rendered compose files and unprovisioned service manifests).
 func checkPortConflicts(cfg *config.Config) int {
-	conflicts := 0
-	portMap := make(map[int]string) // port -> service@stack
-
-	// Check rendered compose files in output directory (most accurate)
-	composeDir := filepath.Join(cfg.OutputDir(), "compose")
-	composeFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
-
-	for _, composeFile := range composeFiles {
-		stackName := strings.TrimSuffix(filepath.Base(composeFile), ".yml")
-		servicePorts := extractPorts(composeFile)
-
-		for port, serviceName := range servicePorts {
-			identifier := serviceName + "@" + stackName
-			if existing, ok := portMap[port]; ok && existing != identifier {
-				ui.Yellow.Printf("  ! Port %d claimed by multiple services (%s and %s)\n", port, existing, identifier)
-				conflicts++
-			} else {
-				portMap[port] = identifier
-			}
-		}
+	conflicts := loadPortRegistry(cfg).Conflicts()
+	for _, conflict := range conflicts {
+		ui.Yellow.Printf("  ! %s\n", conflict)
 	}
-
-	// If no rendered files, fall back to dry-run rendering of stacks
-	if len(composeFiles) == 0 {
-		conflicts += checkPortConflictsFromStacks(cfg, portMap)
-	}
-
-	return conflicts
-}
-
-// checkPortConflictsFromStacks checks port conflicts from raw stack files.
-// This is a fallback when no rendered compose files exist.
-// Without rendered files, we cannot reliably detect port conflicts.
-func checkPortConflictsFromStacks(_ *config.Config, _ map[int]string) int {
-	// Port conflict detection requires rendered compose files.
-	// Run 'bosun provision' first to generate them.
-	return 0
+	return len(conflicts)
 }
 
 func extractSection(content, serviceName string) string {
@@ -1146,12 +1634,10 @@ func extractSection(content, serviceName string) string {
 	return section.String()
 }
 
-// checkDependencyCycles checks rendered compose files for dependency cycles.
-func checkDependencyCycles(cfg *config.Config) []string {
+// checkDependencyCycles checks the compose files in composeDir for dependency cycles.
+func checkDependencyCycles(composeDir string) []string {
 	var allCycles []string
 
-	// Check rendered compose files in output directory
-	composeDir := filepath.Join(cfg.OutputDir(), "compose")
 	composeFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
 
 	for _, composeFile := range composeFiles {
@@ -1189,28 +1675,29 @@ func extractDependencyGraph(filename string) map[string][]string {
 	}
 
 	for svc, svcCfg := range compose.Services {
-		graph[svc] = []string{}
+		graph[svc] = append([]string{}, composeNames(svcCfg.DependsOn)...)
+	}
 
-		if svcCfg.DependsOn == nil {
-			continue
-		}
+	return graph
+}
 
-		// depends_on can be either a list or a map
-		switch deps := svcCfg.DependsOn.(type) {
-		case []any:
-			for _, d := range deps {
-				if depName, ok := d.(string); ok {
-					graph[svc] = append(graph[svc], depName)
-				}
-			}
-		case map[string]any:
-			for depName := range deps {
-				graph[svc] = append(graph[svc], depName)
+// composeNames returns the names in a compose field that can be either a
+// list or a map keyed by name, such as depends_on and networks.
+func composeNames(v any) []string {
+	var names []string
+	switch items := v.(type) {
+	case []any:
+		for _, item := range items {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
 			}
 		}
+	case map[string]any:
+		for name := range items {
+			names = append(names, name)
+		}
 	}
-
-	return graph
+	return names
 }
 
 // detectCycles uses depth-first search with coloring to detect cycles in a dependency graph.
@@ -1282,8 +1769,19 @@ func buildCyclePathFromSlice(path []string, cycleStart string) string {
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	logCmd.Flags().BoolVar(&logSummary, "summary", false, "Print only the one-line deploy summary")
+	logCmd.Flags().StringVar(&logStateDir, "state-dir", "", "State directory holding the deploy history (default: $BOSUN_STATE_DIR, $STATE_DIR, or /app/state)")
 	rootCmd.AddCommand(logCmd)
+	driftCmd.Flags().BoolVar(&driftJSON, "json", false, "Output as JSON (same as --format json)")
+	driftCmd.Flags().StringVar(&driftFormat, "format", "table", "Output format: table, json, or yaml")
+	driftCmd.Flags().BoolVar(&driftAlert, "alert", false, "Send an alert when drift is found")
 	rootCmd.AddCommand(driftCmd)
+	doctorCmd.Flags().StringVar(&doctorFormat, "format", "text", "Output format: text or json")
+	doctorCmd.Flags().StringSliceVar(&doctorOnly, "only", nil, "Run only these checks (comma-separated IDs)")
+	doctorCmd.Flags().StringSliceVar(&doctorSkip, "skip", nil, "Skip these checks (comma-separated IDs)")
+	doctorCmd.Flags().BoolVar(&doctorFixMode, "fix", false, "Offer to fix failed checks, asking before each change")
+	doctorCmd.Flags().StringVar(&doctorFailOn, "fail-on", "", "Exit nonzero when a check at or above this severity fails or warns: info, warning, or critical")
 	rootCmd.AddCommand(doctorCmd)
+	lintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Treat warnings as errors")
 	rootCmd.AddCommand(lintCmd)
 }
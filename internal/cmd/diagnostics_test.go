@@ -4,11 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/system"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
 )
 
 func TestStatusCmd_Help(t *testing.T) {
@@ -31,6 +34,7 @@ func TestLogCmd_Help(t *testing.T) {
 		output, err := executeCmd(t, "log", "--help")
 		assert.NoError(t, err)
 		assert.Contains(t, output, "manifest")
+		assert.Contains(t, output, "--summary")
 	})
 }
 
@@ -172,9 +176,7 @@ config:
 `
 		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
 
-		// The validateServiceFile checks for name: and provisions: keywords
-		result := validateServiceFile(serviceFile, tmpDir)
-		assert.True(t, result)
+		assert.NoError(t, validateServiceFile(serviceFile, tmpDir))
 	})
 
 	t.Run("missing name", func(t *testing.T) {
@@ -186,8 +188,7 @@ config:
 `
 		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
 
-		result := validateServiceFile(serviceFile, tmpDir)
-		assert.False(t, result)
+		assert.Error(t, validateServiceFile(serviceFile, tmpDir))
 	})
 
 	t.Run("missing provisions", func(t *testing.T) {
@@ -200,13 +201,11 @@ config:
 `
 		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
 
-		result := validateServiceFile(serviceFile, tmpDir)
-		assert.False(t, result)
+		assert.Error(t, validateServiceFile(serviceFile, tmpDir))
 	})
 
 	t.Run("non-existent file", func(t *testing.T) {
-		result := validateServiceFile("/non/existent/file.yml", "/tmp")
-		assert.False(t, result)
+		assert.Error(t, validateServiceFile("/non/existent/file.yml", "/tmp"))
 	})
 }
 
@@ -221,25 +220,23 @@ func TestValidateStackFile(t *testing.T) {
 `
 		require.NoError(t, os.WriteFile(stackFile, []byte(content), 0644))
 
-		result := validateStackFile(stackFile, tmpDir)
-		assert.True(t, result)
+		assert.NoError(t, validateStackFile(stackFile, tmpDir))
 	})
 
 	t.Run("stack without include", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		stackFile := filepath.Join(tmpDir, "stack.yml")
 
-		content := `name: mystack
+		content := `apiVersion: bosun.io/v1
+kind: Stack
 `
 		require.NoError(t, os.WriteFile(stackFile, []byte(content), 0644))
 
-		result := validateStackFile(stackFile, tmpDir)
-		assert.True(t, result) // Warning, not error
+		assert.NoError(t, validateStackFile(stackFile, tmpDir)) // Warning, not error
 	})
 
 	t.Run("non-existent file", func(t *testing.T) {
-		result := validateStackFile("/non/existent/file.yml", "/tmp")
-		assert.False(t, result)
+		assert.Error(t, validateStackFile("/non/existent/file.yml", "/tmp"))
 	})
 }
 
@@ -734,6 +731,133 @@ func TestNormalizeImage(t *testing.T) {
 	}
 }
 
+func TestDriftHistory_ExplainImageDrift(t *testing.T) {
+	deployedAt := time.Now().Add(-time.Hour)
+	h := &driftHistory{deployedAt: map[string]time.Time{"apps": deployedAt}}
+
+	t.Run("container recreated after deploy", func(t *testing.T) {
+		c := h.explainImageDrift("apps", docker.ContainerInfo{Created: deployedAt.Add(time.Minute)})
+		assert.Contains(t, c.Cause, "image updated out-of-band")
+		assert.Equal(t, "bosun yacht up", c.Fix)
+	})
+
+	t.Run("container older than deploy", func(t *testing.T) {
+		c := h.explainImageDrift("apps", docker.ContainerInfo{Created: deployedAt.Add(-time.Minute)})
+		assert.Contains(t, c.Cause, "stack not yet deployed")
+		assert.Equal(t, "bosun yacht up", c.Fix)
+	})
+}
+
+func TestDriftHistory_ExplainNotRunning(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ctr       *docker.ContainerInfo
+		events    []docker.ContainerEvent
+		wantCause string
+		wantFix   string
+	}{
+		{
+			name:      "no container",
+			wantCause: "stack not yet deployed",
+			wantFix:   "bosun yacht up",
+		},
+		{
+			name:      "stopped via docker stop",
+			ctr:       &docker.ContainerInfo{State: "exited", Status: "Exited (0) 5 minutes ago"},
+			events:    []docker.ContainerEvent{{Name: "web", Action: "kill"}, {Name: "web", Action: "die", ExitCode: "0"}, {Name: "web", Action: "stop"}},
+			wantCause: "manually stopped",
+			wantFix:   "bosun crew restart web",
+		},
+		{
+			name:      "crash after earlier manual restart",
+			ctr:       &docker.ContainerInfo{State: "exited", Status: "Exited (1) 1 minute ago"},
+			events:    []docker.ContainerEvent{{Name: "web", Action: "stop"}, {Name: "web", Action: "start"}, {Name: "web", Action: "die", ExitCode: "1"}},
+			wantCause: "crashed (exit 1)",
+			wantFix:   "bosun crew logs web",
+		},
+		{
+			name:      "out of memory",
+			ctr:       &docker.ContainerInfo{State: "exited", Status: "Exited (137) 1 minute ago"},
+			events:    []docker.ContainerEvent{{Name: "web", Action: "oom"}, {Name: "web", Action: "die", ExitCode: "137"}},
+			wantCause: "out of memory",
+			wantFix:   "bosun crew logs web",
+		},
+		{
+			name:      "no events, SIGTERM exit code",
+			ctr:       &docker.ContainerInfo{State: "exited", Status: "Exited (143) 3 days ago"},
+			wantCause: "manually stopped (exit 143)",
+			wantFix:   "bosun crew restart web",
+		},
+		{
+			name:      "no events, crash exit code",
+			ctr:       &docker.ContainerInfo{State: "exited", Status: "Exited (2) 3 days ago"},
+			events:    []docker.ContainerEvent{{Name: "other", Action: "stop"}},
+			wantCause: "crashed (exit 2)",
+			wantFix:   "bosun crew logs web",
+		},
+		{
+			name:      "never started",
+			ctr:       &docker.ContainerInfo{State: "created", Status: "Created"},
+			wantCause: "never started",
+			wantFix:   "bosun crew restart web",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &driftHistory{events: tc.events}
+			c := h.explainNotRunning("web", tc.ctr)
+			assert.Contains(t, c.Cause, tc.wantCause)
+			assert.Equal(t, tc.wantFix, c.Fix)
+		})
+	}
+}
+
+func TestDriftHistory_ExplainOrphan(t *testing.T) {
+	h := &driftHistory{deployedAt: map[string]time.Time{"apps": time.Now()}}
+
+	testCases := []struct {
+		name      string
+		labels    map[string]string
+		wantCause string
+	}{
+		{
+			name:      "service removed from existing stack",
+			labels:    map[string]string{"com.docker.compose.project.config_files": "/app/output/compose/apps.yml"},
+			wantCause: "service no longer in stack apps",
+		},
+		{
+			name:      "whole stack removed",
+			labels:    map[string]string{"com.docker.compose.project.config_files": "/app/output/compose/media.yml,/tmp/override.yml"},
+			wantCause: "stack media no longer rendered",
+		},
+		{
+			name:      "compose project without config files",
+			labels:    map[string]string{"com.docker.compose.project": "legacy"},
+			wantCause: "compose project legacy",
+		},
+		{
+			name:      "started manually",
+			wantCause: "started manually",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := h.explainOrphan(docker.ContainerInfo{Name: "stray", Labels: tc.labels})
+			assert.Contains(t, c.Cause, tc.wantCause)
+			assert.Equal(t, "bosun overboard stray", c.Fix)
+		})
+	}
+}
+
+func TestParseExitCode(t *testing.T) {
+	assert.Equal(t, "137", parseExitCode("Exited (137) 2 hours ago"))
+	assert.Equal(t, "0", parseExitCode("Exited (0) About a minute ago"))
+	assert.Equal(t, "", parseExitCode("Up 3 hours"))
+	assert.Equal(t, "", parseExitCode("Created"))
+}
+
 func TestCheckResult_Add(t *testing.T) {
 	t.Run("add two results", func(t *testing.T) {
 		r1 := CheckResult{Passed: 2, Failed: 1, Warned: 3}
@@ -878,6 +1002,35 @@ func TestCheckDockerCompose(t *testing.T) {
 	})
 }
 
+func TestReportDockerUserns(t *testing.T) {
+	t.Setenv("BOSUN_DOCKER_ROOT_DIR", "")
+
+	t.Run("rootful", func(t *testing.T) {
+		result := reportDockerUserns(system.Info{DockerRootDir: "/var/lib/docker"}, 1024)
+		assert.Equal(t, CheckResult{Passed: 1}, result)
+	})
+
+	t.Run("rootless with privileged ports", func(t *testing.T) {
+		info := system.Info{SecurityOptions: []string{"name=seccomp,profile=builtin", "name=rootless"}, DockerRootDir: "/home/deploy/.local/share/docker"}
+		assert.Equal(t, CheckResult{Passed: 1, Warned: 1}, reportDockerUserns(info, 1024))
+		assert.Equal(t, CheckResult{Passed: 1}, reportDockerUserns(info, 80))
+	})
+
+	t.Run("rootless with mismatched docker root", func(t *testing.T) {
+		t.Setenv("BOSUN_DOCKER_ROOT_DIR", "/var/lib/docker")
+		info := system.Info{SecurityOptions: []string{"name=rootless"}, DockerRootDir: "/home/deploy/.local/share/docker"}
+		assert.Equal(t, CheckResult{Passed: 1, Warned: 1}, reportDockerUserns(info, 0))
+	})
+
+	t.Run("userns-remap", func(t *testing.T) {
+		info := system.Info{SecurityOptions: []string{"name=userns"}, DockerRootDir: "/var/lib/docker/100000.100000"}
+		assert.Equal(t, CheckResult{Passed: 1}, reportDockerUserns(info, 1024))
+
+		info.DockerRootDir = "/var/lib/docker"
+		assert.Equal(t, CheckResult{Warned: 1}, reportDockerUserns(info, 1024))
+	})
+}
+
 func TestCheckSOPS(t *testing.T) {
 	t.Run("sops check", func(t *testing.T) {
 		result := checkSOPS()
@@ -1096,23 +1249,31 @@ func TestValidateServiceFile_EdgeCases(t *testing.T) {
 		tmpDir := t.TempDir()
 		serviceFile := filepath.Join(tmpDir, "service.yml")
 		require.NoError(t, os.WriteFile(serviceFile, []byte(""), 0644))
-		result := validateServiceFile(serviceFile, tmpDir)
-		assert.False(t, result)
+		assert.Error(t, validateServiceFile(serviceFile, tmpDir))
 	})
 
-	t.Run("name in comments - basic string check passes", func(t *testing.T) {
+	t.Run("name in comments fails", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		serviceFile := filepath.Join(tmpDir, "service.yml")
-		// Note: Basic string validation cannot distinguish comments from actual YAML keys.
-		// This test documents current behavior - more nuanced validation would require YAML parsing.
 		content := `# name: not a real name
 provisions:
   - webapp
 `
 		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
-		result := validateServiceFile(serviceFile, tmpDir)
-		// Basic validation passes since "name:" and "provisions:" appear in file
-		assert.True(t, result)
+		err := validateServiceFile(serviceFile, tmpDir)
+		assert.ErrorContains(t, err, "name: is required")
+	})
+
+	t.Run("misspelled provisions fails with its location", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		serviceFile := filepath.Join(tmpDir, "service.yml")
+		content := `name: myservice
+provsions:
+  - webapp
+`
+		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
+		err := validateServiceFile(serviceFile, tmpDir)
+		assert.ErrorContains(t, err, serviceFile+":2:1: provsions: unknown field (did you mean provisions?)")
 	})
 
 	t.Run("missing provisions fails", func(t *testing.T) {
@@ -1123,8 +1284,7 @@ config:
   port: 8080
 `
 		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
-		result := validateServiceFile(serviceFile, tmpDir)
-		assert.False(t, result)
+		assert.Error(t, validateServiceFile(serviceFile, tmpDir))
 	})
 
 	t.Run("missing name fails", func(t *testing.T) {
@@ -1134,36 +1294,33 @@ config:
   - webapp
 `
 		require.NoError(t, os.WriteFile(serviceFile, []byte(content), 0644))
-		result := validateServiceFile(serviceFile, tmpDir)
-		assert.False(t, result)
+		assert.Error(t, validateServiceFile(serviceFile, tmpDir))
 	})
 }
 
 // TestValidateStackFile_EdgeCases tests edge cases in stack file validation.
 func TestValidateStackFile_EdgeCases(t *testing.T) {
-	t.Run("without include returns true (warning only)", func(t *testing.T) {
+	t.Run("without include passes (warning only)", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		stackFile := filepath.Join(tmpDir, "stack.yml")
-		content := `name: mystack
+		content := `apiVersion: bosun.io/v1
+kind: Stack
 `
 		require.NoError(t, os.WriteFile(stackFile, []byte(content), 0644))
-		result := validateStackFile(stackFile, tmpDir)
-		// validateStackFile returns true for "no include" as it's just a warning
-		assert.True(t, result)
+		// No include is just a warning
+		assert.NoError(t, validateStackFile(stackFile, tmpDir))
 	})
 
-	t.Run("empty file returns true (no include is just a warning)", func(t *testing.T) {
+	t.Run("empty file passes (no include is just a warning)", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		stackFile := filepath.Join(tmpDir, "stack.yml")
 		require.NoError(t, os.WriteFile(stackFile, []byte(""), 0644))
-		result := validateStackFile(stackFile, tmpDir)
-		// validateStackFile returns true for empty (no include) as it's just a warning
-		assert.True(t, result)
+		// An empty stack has no include, which is just a warning
+		assert.NoError(t, validateStackFile(stackFile, tmpDir))
 	})
 
-	t.Run("non-existent file returns false", func(t *testing.T) {
-		result := validateStackFile("/non/existent/file.yml", "/tmp")
-		assert.False(t, result)
+	t.Run("non-existent file fails", func(t *testing.T) {
+		assert.Error(t, validateStackFile("/non/existent/file.yml", "/tmp"))
 	})
 }
 
@@ -1398,3 +1555,26 @@ func TestExtractDependencyGraph_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckTemplateVariables(t *testing.T) {
+	tmpDir := t.TempDir()
+	servicesDir := filepath.Join(tmpDir, "services")
+	provisionsDir := filepath.Join(tmpDir, "provisions")
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	require.NoError(t, os.MkdirAll(provisionsDir, 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(provisionsDir, "container.yml"),
+		[]byte("compose:\n  services:\n    ${name}:\n      image: ${image}\n"), 0644))
+
+	t.Run("all variables defined", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "app.yml"),
+			[]byte("name: app\nprovisions: [container]\nconfig:\n  image: nginx\n"), 0644))
+		assert.Equal(t, 0, checkTemplateVariables(servicesDir, provisionsDir))
+	})
+
+	t.Run("undefined variable counted", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "app.yml"),
+			[]byte("name: app\nprovisions: [container]\nconfig:\n  imgae: nginx\n"), 0644))
+		assert.Equal(t, 1, checkTemplateVariables(servicesDir, provisionsDir))
+	})
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	diffRemote   string
+	diffAppdata  string
+	diffExitCode bool
+)
+
+// diffCmd previews what a deploy would change in appdata.
+var diffCmd = &cobra.Command{
+	Use:   "diff [stack...]",
+	Short: "Diff rendered manifests against the deployed config",
+	Long: `Render stack manifests and diff them against the files deployed in appdata.
+
+Every stack (or the named ones) is rendered in memory, as 'bosun provision'
+would write it, and each output file is compared with its deployed copy under
+the appdata root: compose/<stack>.yml, traefik/dynamic.yml, and so on. Files
+that differ are shown as a colored unified diff; files missing on the target
+are shown as new. Content hash headers are ignored.
+
+Nothing is written, locally or on the target.
+
+Examples:
+  bosun diff                          # Every stack against local appdata
+  bosun diff media                    # Just the media stack
+  bosun diff --remote root@tower      # Against appdata on a remote host
+  bosun diff --exit-code && echo same # Exit 1 if anything would change`,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffRemote, "remote", "", "Read deployed files from a remote host over SSH (e.g., root@192.168.1.8)")
+	diffCmd.Flags().StringVar(&diffAppdata, "appdata", "", "Appdata root holding the deployed files (default: $LOCAL_APPDATA or $REMOTE_APPDATA)")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit 1 if any file would change")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+// Diff statuses for an output file.
+const (
+	diffUnchanged = "unchanged"
+	diffChanged   = "changed"
+	diffNew       = "new"
+)
+
+// fileDiff is the comparison of one rendered file with its current copy.
+type fileDiff struct {
+	Path   string // Slash-separated, relative to the output root
+	Status string // diffUnchanged, diffChanged, or diffNew
+	Diff   string // Unified diff, empty when unchanged
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	loadHostFacts(cmd.Context())
+	loadServicePorts(cfg.ServicesDir())
+
+	files, err := renderStackFiles(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	root := appdataRoot(diffAppdata, diffRemote)
+	deploy := reconcile.NewDeployOps(false)
+	ctx, cancel := context.WithTimeout(context.Background(), reconcile.RemoteDeployTimeout)
+	defer cancel()
+
+	read := func(rel string) ([]byte, error) {
+		return deploy.ReadFile(ctx, diffRemote, filepath.Join(root, filepath.FromSlash(rel)))
+	}
+	diffs, err := diffFiles(files, read)
+	if err != nil {
+		return err
+	}
+
+	target := root
+	if diffRemote != "" {
+		target = diffRemote + ":" + root
+	}
+	changed := printFileDiffs(diffs, target)
+	if changed > 0 && diffExitCode {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// renderStackFiles renders the named stacks, or every stack when none are
+// named, and returns their output files keyed by path relative to the
+// output root. As with provisioning them in turn, a shared file such as
+// traefik/dynamic.yml holds the last stack's version.
+func renderStackFiles(cfg *config.Config, stacks []string) (map[string][]byte, error) {
+	var stackFiles []string
+	if len(stacks) == 0 {
+		stackFiles, _ = filepath.Glob(filepath.Join(cfg.StacksDir(), "*.yml"))
+		if len(stackFiles) == 0 {
+			return nil, fmt.Errorf("no stacks found in %s", cfg.StacksDir())
+		}
+	}
+	for _, stack := range stacks {
+		stackFile := filepath.Join(cfg.StacksDir(), stack+".yml")
+		if _, err := os.Stat(stackFile); err != nil {
+			return nil, fmt.Errorf("stack not found: %s", stack)
+		}
+		stackFiles = append(stackFiles, stackFile)
+	}
+
+	files := make(map[string][]byte)
+	for _, stackFile := range stackFiles {
+		name := strings.TrimSuffix(filepath.Base(stackFile), ".yml")
+		output, err := manifest.RenderStack(stackFile, cfg.ProvisionsDir(), cfg.ServicesDir(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("render stack %s: %w", name, err)
+		}
+		rendered, err := manifest.RenderFiles(output, name)
+		if err != nil {
+			return nil, fmt.Errorf("render stack %s: %w", name, err)
+		}
+		for p, data := range rendered {
+			files[p] = data
+		}
+	}
+	return files, nil
+}
+
+// diffFiles compares each rendered file with the current copy returned by
+// read, sorted by path. A copy read reports as fs.ErrNotExist is new; any
+// other read error is returned.
+func diffFiles(files map[string][]byte, read func(rel string) ([]byte, error)) ([]fileDiff, error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	diffs := make([]fileDiff, 0, len(paths))
+	for _, p := range paths {
+		rel := path.Clean(p)
+		current, err := read(rel)
+		status := diffChanged
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			status, current = diffNew, nil
+		case err != nil:
+			return nil, fmt.Errorf("read deployed %s: %w", rel, err)
+		case manifest.ContentHash(current) == manifest.ContentHash(files[p]):
+			diffs = append(diffs, fileDiff{Path: rel, Status: diffUnchanged})
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(manifest.StripHashHeader(current))),
+			B:        difflib.SplitLines(string(manifest.StripHashHeader(files[p]))),
+			FromFile: "deployed/" + rel,
+			ToFile:   "rendered/" + rel,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("diff %s: %w", rel, err)
+		}
+		diffs = append(diffs, fileDiff{Path: rel, Status: status, Diff: diff})
+	}
+	return diffs, nil
+}
+
+// printFileDiffs prints the diff of every changed or new file and a
+// summary, and returns how many files would change.
+func printFileDiffs(diffs []fileDiff, target string) int {
+	var changed, added, unchanged int
+	for _, d := range diffs {
+		switch d.Status {
+		case diffUnchanged:
+			unchanged++
+			continue
+		case diffNew:
+			added++
+			ui.Yellow.Printf("%s (new file)\n", d.Path)
+		default:
+			changed++
+			ui.Yellow.Printf("%s\n", d.Path)
+		}
+		printUnifiedDiff(d.Diff)
+		fmt.Println()
+	}
+
+	summary := fmt.Sprintf("%d changed, %d new, %d unchanged against %s", changed, added, unchanged, target)
+	if changed+added == 0 {
+		ui.Success("No changes: %s", summary)
+	} else {
+		ui.Info("%s", summary)
+	}
+	return changed + added
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+func TestRenderStackFiles(t *testing.T) {
+	manifestDir := t.TempDir()
+	for _, dir := range []string{"provisions", "services", "stacks"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(manifestDir, dir), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "provisions", "container.yml"), []byte(`compose:
+  services:
+    ${name}:
+      image: ${image}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "services", "sonarr.yml"),
+		[]byte("name: sonarr\nprovisions: [container]\nconfig:\n  image: lscr.io/linuxserver/sonarr:4.0.1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "stacks", "media.yml"), []byte("include:\n  - sonarr.yml\n"), 0644))
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	t.Run("renders every stack", func(t *testing.T) {
+		files, err := renderStackFiles(cfg, nil)
+		require.NoError(t, err)
+		require.Contains(t, files, "compose/media.yml")
+		assert.Contains(t, string(files["compose/media.yml"]), "image: lscr.io/linuxserver/sonarr:4.0.1")
+		_, hashed := manifest.HashHeader(files["compose/media.yml"])
+		assert.True(t, hashed, "rendered files carry a hash header, as provisioned ones do")
+	})
+
+	t.Run("unknown stack", func(t *testing.T) {
+		_, err := renderStackFiles(cfg, []string{"nope"})
+		assert.ErrorContains(t, err, "stack not found: nope")
+	})
+}
+
+func TestDiffFiles(t *testing.T) {
+	deployed := map[string][]byte{
+		"compose/media.yml":   manifest.WithHashHeader([]byte("services:\n  sonarr:\n    image: sonarr:4.0.0\n")),
+		"traefik/dynamic.yml": []byte("http: {}\n"),
+	}
+	read := func(rel string) ([]byte, error) {
+		data, ok := deployed[rel]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return data, nil
+	}
+	rendered := map[string][]byte{
+		"compose/media.yml":   manifest.WithHashHeader([]byte("services:\n  sonarr:\n    image: sonarr:4.0.1\n")),
+		"traefik/dynamic.yml": manifest.WithHashHeader([]byte("http: {}\n")),
+		"gatus/endpoints.yml": manifest.WithHashHeader([]byte("endpoints: []\n")),
+	}
+
+	diffs, err := diffFiles(rendered, read)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+
+	assert.Equal(t, "compose/media.yml", diffs[0].Path)
+	assert.Equal(t, diffChanged, diffs[0].Status)
+	assert.Contains(t, diffs[0].Diff, "--- deployed/compose/media.yml")
+	assert.Contains(t, diffs[0].Diff, "+++ rendered/compose/media.yml")
+	assert.Contains(t, diffs[0].Diff, "-    image: sonarr:4.0.0")
+	assert.Contains(t, diffs[0].Diff, "+    image: sonarr:4.0.1")
+	assert.NotContains(t, diffs[0].Diff, "bosun:hash", "hash headers are not part of the diff")
+
+	assert.Equal(t, "gatus/endpoints.yml", diffs[1].Path)
+	assert.Equal(t, diffNew, diffs[1].Status)
+	assert.Contains(t, diffs[1].Diff, "+endpoints: []")
+
+	assert.Equal(t, "traefik/dynamic.yml", diffs[2].Path)
+	assert.Equal(t, diffUnchanged, diffs[2].Status, "a missing hash header on the deployed copy is not a change")
+	assert.Empty(t, diffs[2].Diff)
+
+	t.Run("read errors are returned", func(t *testing.T) {
+		_, err := diffFiles(rendered, func(string) ([]byte, error) { return nil, errors.New("ssh down") })
+		assert.ErrorContains(t, err, "ssh down")
+	})
+}
+
+func TestPrintFileDiffs(t *testing.T) {
+	diffs := []fileDiff{
+		{Path: "compose/media.yml", Status: diffChanged, Diff: "--- a\n+++ b\n"},
+		{Path: "gatus/endpoints.yml", Status: diffNew, Diff: "--- a\n+++ b\n"},
+		{Path: "traefik/dynamic.yml", Status: diffUnchanged},
+	}
+	assert.Equal(t, 2, printFileDiffs(diffs, "/mnt/appdata"))
+	assert.Equal(t, 0, printFileDiffs(diffs[2:], "/mnt/appdata"))
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var docsOutput string
+
+// docsCmd generates markdown documentation from service manifests.
+var docsCmd = &cobra.Command{
+	Use:     "docs [stack]",
+	Aliases: []string{"logbook"},
+	Short:   "Generate markdown docs for services",
+	Long: `Generate a markdown page per service from manifest metadata, plus a
+README.md index, so the homelab wiki stays in sync with what's deployed.
+
+Each page lists the description, URL, image, ports, volumes, backup policy,
+and dependencies. Set 'description', 'url' (or 'subdomain' + 'domain'), and
+'backup' in a service's config to fill them in.
+
+Pages are written to <manifest>/output/docs unless --output is set.
+
+Examples:
+  bosun docs                     # Document every service
+  bosun docs core                # Document services in the 'core' stack
+  bosun docs -o ../wiki/services # Write straight into a wiki checkout`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDocs,
+}
+
+func init() {
+	docsCmd.Flags().StringVarP(&docsOutput, "output", "o", "", "Output directory (default: <manifest>/output/docs)")
+
+	rootCmd.AddCommand(docsCmd)
+}
+
+func runDocs(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	loadHostFacts(cmd.Context())
+	loadServicePorts(cfg.ServicesDir())
+
+	var serviceFiles []string
+	if len(args) == 1 {
+		serviceFiles, err = stackServiceFiles(filepath.Join(cfg.StacksDir(), args[0]+".yml"))
+		if err != nil {
+			return err
+		}
+	} else {
+		serviceFiles, err = filepath.Glob(filepath.Join(cfg.ServicesDir(), "*.yml"))
+		if err != nil {
+			return fmt.Errorf("list services: %w", err)
+		}
+		for i, f := range serviceFiles {
+			serviceFiles[i] = filepath.Base(f)
+		}
+		sort.Strings(serviceFiles)
+	}
+
+	if len(serviceFiles) == 0 {
+		fmt.Println("No services found")
+		return nil
+	}
+
+	docs := make([]manifest.ServiceDoc, 0, len(serviceFiles))
+	for _, file := range serviceFiles {
+		if !filepath.IsLocal(file) {
+			return fmt.Errorf("service path %s escapes services directory", file)
+		}
+
+		svc, err := manifest.LoadServiceManifest(filepath.Join(cfg.ServicesDir(), file))
+		if err != nil {
+			return fmt.Errorf("load service %s: %w", file, err)
+		}
+
+		output, err := manifest.RenderService(svc, cfg.ProvisionsDir())
+		if err != nil {
+			return fmt.Errorf("render service %s: %w", svc.Name, err)
+		}
+
+		docs = append(docs, manifest.BuildServiceDoc(svc, output))
+	}
+
+	outputDir := docsOutput
+	if outputDir == "" {
+		outputDir = filepath.Join(cfg.OutputDir(), "docs")
+	}
+
+	if err := manifest.WriteServiceDocs(docs, outputDir); err != nil {
+		return fmt.Errorf("write docs: %w", err)
+	}
+
+	ui.Green.Printf("Documented %d service(s)\n", len(docs))
+	return nil
+}
+
+// stackServiceFiles returns the service files included by a stack.
+func stackServiceFiles(stackPath string) ([]string, error) {
+	content, err := os.ReadFile(stackPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("stack not found: %s", strings.TrimSuffix(filepath.Base(stackPath), ".yml"))
+		}
+		return nil, fmt.Errorf("read stack file: %w", err)
+	}
+
+	var stack manifest.Stack
+	if err := yaml.Unmarshal(content, &stack); err != nil {
+		return nil, fmt.Errorf("parse stack file: %w", err)
+	}
+	return stack.Include, nil
+}
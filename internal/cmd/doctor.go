@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/cameronsjo/bosun/internal/config"
+)
+
+var (
+	doctorFormat string
+	doctorOnly   []string
+	doctorSkip   []string
+	doctorFailOn string
+)
+
+// Doctor check severities, from least to most important.
+const (
+	severityInfo     = "info"
+	severityWarning  = "warning"
+	severityCritical = "critical"
+)
+
+// severityRank orders severities for --fail-on.
+var severityRank = map[string]int{severityInfo: 0, severityWarning: 1, severityCritical: 2}
+
+// Doctor check statuses.
+const (
+	checkStatusPass = "pass"
+	checkStatusWarn = "warn"
+	checkStatusFail = "fail"
+	checkStatusSkip = "skip"
+)
+
+// doctorCheck is one pre-flight check. IDs are stable, for --only, --skip,
+// and CI scripts reading the JSON output.
+type doctorCheck struct {
+	ID       string
+	Name     string
+	Severity string
+	Run      func(cfg *config.Config) CheckResult
+	Fix      func(cfg *config.Config) (*doctorFix, error) // For --fix; nil fix when there's nothing it can do
+}
+
+// doctorChecks are the checks doctor runs, in order.
+var doctorChecks = []doctorCheck{
+	{ID: "docker", Name: "Docker daemon", Severity: severityCritical, Run: func(*config.Config) CheckResult {
+		ctx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+		defer cancel()
+		return checkDocker(ctx)
+	}},
+	{ID: "docker-userns", Name: "Docker user namespaces", Severity: severityWarning, Run: func(*config.Config) CheckResult {
+		ctx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+		defer cancel()
+		return checkDockerUserns(ctx)
+	}},
+	{ID: "compose", Name: "Docker Compose", Severity: severityCritical, Run: func(*config.Config) CheckResult { return checkDockerCompose() }},
+	{ID: "git", Name: "Git", Severity: severityCritical, Run: func(*config.Config) CheckResult { return checkGit() }},
+	{ID: "project-root", Name: "Project root", Severity: severityWarning, Run: checkProjectRoot},
+	{ID: "project-config", Name: "Project config", Severity: severityInfo, Run: checkProjectConfig, Fix: fixProjectConfig},
+	{ID: "age-key", Name: "Age key", Severity: severityWarning, Run: func(*config.Config) CheckResult { return checkAgeKey() }, Fix: fixAgeKey},
+	{ID: "sops", Name: "SOPS", Severity: severityWarning, Run: func(*config.Config) CheckResult { return checkSOPS() }},
+	{ID: "manifest-dir", Name: "Manifest directory", Severity: severityWarning, Run: checkManifestDirectory, Fix: fixManifestDirectory},
+	{ID: "known-hosts", Name: "Deploy target host key", Severity: severityWarning, Run: func(*config.Config) CheckResult { return checkKnownHosts() }, Fix: fixKnownHosts},
+	{ID: "bind-mounts", Name: "Bind mounts", Severity: severityCritical, Run: checkBindMounts},
+	{ID: "webhook", Name: "Webhook endpoint", Severity: severityInfo, Run: func(*config.Config) CheckResult { return checkWebhook() }},
+	{ID: "completion", Name: "Shell completion", Severity: severityInfo, Run: func(*config.Config) CheckResult { return checkCompletion() }},
+	{ID: "tunnel", Name: "Tunnel", Severity: severityInfo, Run: func(cfg *config.Config) CheckResult {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
+		defer cancel()
+		return checkTunnel(ctx, cfg)
+	}},
+}
+
+// doctorCheckIDs returns the IDs of every doctor check.
+func doctorCheckIDs() []string {
+	ids := make([]string, len(doctorChecks))
+	for i, c := range doctorChecks {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+// selectDoctorChecks returns the checks to run: those in only (all if
+// empty), minus those in skip. Unknown IDs are an error.
+func selectDoctorChecks(only, skip []string) ([]doctorCheck, error) {
+	ids := doctorCheckIDs()
+	for _, id := range append(slices.Clone(only), skip...) {
+		if !slices.Contains(ids, id) {
+			return nil, fmt.Errorf("unknown check %q (available: %s)", id, strings.Join(ids, ", "))
+		}
+	}
+
+	var selected []doctorCheck
+	for _, c := range doctorChecks {
+		if len(only) > 0 && !slices.Contains(only, c.ID) {
+			continue
+		}
+		if slices.Contains(skip, c.ID) {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// doctorResult is the structured result of one check.
+type doctorResult struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Severity string   `json:"severity"`
+	Status   string   `json:"status"`
+	Message  string   `json:"message,omitempty"`
+	Details  []string `json:"details,omitempty"`
+	Hints    []string `json:"hints,omitempty"`
+}
+
+// doctorReport is the JSON output of doctor.
+type doctorReport struct {
+	OK      bool           `json:"ok"`
+	FailOn  string         `json:"fail_on,omitempty"`
+	Summary doctorSummary  `json:"summary"`
+	Checks  []doctorResult `json:"checks"`
+}
+
+// doctorSummary counts check statuses.
+type doctorSummary struct {
+	Passed  int `json:"passed"`
+	Warned  int `json:"warned"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// checkStatus reduces a check's counts to one status: any failure fails
+// it, then any warning; a check that found nothing to check is skipped.
+func checkStatus(r CheckResult) string {
+	switch {
+	case r.Failed > 0:
+		return checkStatusFail
+	case r.Warned > 0:
+		return checkStatusWarn
+	case r.Passed > 0:
+		return checkStatusPass
+	}
+	return checkStatusSkip
+}
+
+// runDoctorCheck runs a check. With capture set, its console output is
+// collected instead of printed and parsed into the result's message,
+// details, and hints.
+func runDoctorCheck(c doctorCheck, cfg *config.Config, capture bool) (CheckResult, doctorResult) {
+	res := doctorResult{ID: c.ID, Name: c.Name, Severity: c.Severity}
+	if !capture {
+		counts := c.Run(cfg)
+		res.Status = checkStatus(counts)
+		return counts, res
+	}
+
+	var buf bytes.Buffer
+	out, noColor := color.Output, color.NoColor
+	color.Output, color.NoColor = &buf, true
+	counts := c.Run(cfg)
+	color.Output, color.NoColor = out, noColor
+
+	res.Status = checkStatus(counts)
+	res.Message, res.Details, res.Hints = parseCheckOutput(buf.String())
+	return counts, res
+}
+
+// checkMarkers prefix the status lines checks print.
+var checkMarkers = []string{"* ", "x ", "! ", "~ "}
+
+// parseCheckOutput splits a check's console output into its first status
+// line, the remaining lines, and the "To fix this:" hints.
+func parseCheckOutput(output string) (message string, details, hints []string) {
+	inHints := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line == "To fix this:" {
+			inHints = true
+			continue
+		}
+
+		marked := false
+		for _, m := range checkMarkers {
+			if strings.HasPrefix(line, m) {
+				line = strings.TrimPrefix(line, m)
+				marked = true
+				break
+			}
+		}
+		switch {
+		case marked && message == "":
+			message = line
+			inHints = false
+		case marked:
+			details = append(details, line)
+			inHints = false
+		case inHints:
+			hints = append(hints, strings.TrimPrefix(line, "- "))
+		default:
+			details = append(details, line)
+		}
+	}
+	return message, details, hints
+}
+
+// doctorFails reports whether a result fails the run. Without a threshold
+// only failed checks do; with one, any check at or above it that did not
+// pass does, warnings included.
+func doctorFails(r doctorResult, failOn string) bool {
+	if failOn == "" {
+		return r.Status == checkStatusFail
+	}
+	if r.Status != checkStatusFail && r.Status != checkStatusWarn {
+		return false
+	}
+	return severityRank[r.Severity] >= severityRank[failOn]
+}
+
+// validateFailOn checks a --fail-on severity.
+func validateFailOn(failOn string) error {
+	if _, ok := severityRank[failOn]; failOn != "" && !ok {
+		return fmt.Errorf("invalid severity %q (want info, warning, or critical)", failOn)
+	}
+	return nil
+}
+
+// writeDoctorJSON writes a doctor report as indented JSON.
+func writeDoctorJSON(w io.Writer, report doctorReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// newDoctorReport summarizes results into a report.
+func newDoctorReport(results []doctorResult, failOn string) doctorReport {
+	report := doctorReport{OK: true, FailOn: failOn, Checks: results}
+	for _, r := range results {
+		switch r.Status {
+		case checkStatusPass:
+			report.Summary.Passed++
+		case checkStatusWarn:
+			report.Summary.Warned++
+		case checkStatusFail:
+			report.Summary.Failed++
+		case checkStatusSkip:
+			report.Summary.Skipped++
+		}
+		if doctorFails(r, failOn) {
+			report.OK = false
+		}
+	}
+	if report.Checks == nil {
+		report.Checks = []doctorResult{}
+	}
+	return report
+}
+
+// exitDoctor exits 1 when the report failed.
+func exitDoctor(report doctorReport) {
+	if !report.OK {
+		os.Exit(1)
+	}
+}
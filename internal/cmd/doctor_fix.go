@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	xssh "golang.org/x/crypto/ssh"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var doctorFixMode bool
+
+// doctorConfirm asks before doctor --fix changes anything (tests substitute
+// an answer).
+var doctorConfirm = promptYesNo
+
+// keyscanTimeout is how long doctor --fix waits for ssh-keyscan, in seconds.
+const keyscanTimeout = 10
+
+// doctorFix is a change doctor --fix can make for a check that didn't pass.
+type doctorFix struct {
+	Prompt string // Question asked before applying, e.g. "Generate an age key at ...?"
+	Apply  func() error
+}
+
+// offerDoctorFix asks to apply a check's fix and applies it. It reports
+// whether anything changed, so the check is worth running again.
+func offerDoctorFix(c doctorCheck, cfg *config.Config) bool {
+	if c.Fix == nil {
+		return false
+	}
+	fix, err := c.Fix(cfg)
+	if err != nil {
+		ui.Yellow.Printf("      Can't fix %s: %v\n", c.ID, err)
+		return false
+	}
+	if fix == nil {
+		return false
+	}
+
+	ok, err := doctorConfirm("      " + fix.Prompt)
+	if err != nil {
+		ui.Yellow.Printf("      Can't fix %s: %v\n", c.ID, err)
+		return false
+	}
+	if !ok {
+		ui.Blue.Println("      Skipped")
+		return false
+	}
+	if err := fix.Apply(); err != nil {
+		ui.Red.Printf("      x Fix failed: %v\n", err)
+		return false
+	}
+	ui.Blue.Println("      Fixed; checking again...")
+	return true
+}
+
+// ageKeyFile returns where SOPS looks for the age key.
+func ageKeyFile() string {
+	if path := os.Getenv("SOPS_AGE_KEY_FILE"); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "sops", "age", "keys.txt")
+}
+
+// fixAgeKey offers to generate the missing age key.
+func fixAgeKey(*config.Config) (*doctorFix, error) {
+	path := ageKeyFile()
+	if _, err := os.Stat(path); err == nil {
+		return nil, nil
+	}
+	return &doctorFix{
+		Prompt: fmt.Sprintf("Generate an age key at %s?", path),
+		Apply: func() error {
+			recipient, err := writeAgeKey(path, time.Now())
+			if err != nil {
+				return err
+			}
+			ui.Blue.Printf("      Public key: %s (add it to .sops.yaml)\n", recipient)
+			return nil
+		},
+	}, nil
+}
+
+// writeAgeKey generates an age identity and writes it to path in the
+// age-keygen format, never overwriting an existing file. It returns the
+// public key.
+func writeAgeKey(path string, now time.Time) (string, error) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", fmt.Errorf("generate age key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("create key directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("create key file: %w", err)
+	}
+	_, err = fmt.Fprintf(f, "# created: %s\n# public key: %s\n%s\n", now.Format(time.RFC3339), id.Recipient(), id)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("write key file: %w", err)
+	}
+	return id.Recipient().String(), nil
+}
+
+// manifestDirs are the directories a manifest needs.
+func manifestDirs(cfg *config.Config) []string {
+	return []string{cfg.ManifestDir, cfg.ProvisionsDir(), cfg.ServicesDir(), cfg.StacksDir()}
+}
+
+// fixManifestDirectory offers to create the missing manifest directories.
+func fixManifestDirectory(cfg *config.Config) (*doctorFix, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	var missing []string
+	for _, dir := range manifestDirs(cfg) {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			missing = append(missing, dir)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return &doctorFix{
+		Prompt: fmt.Sprintf("Create %s?", strings.Join(missing, ", ")),
+		Apply: func() error {
+			for _, dir := range missing {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return fmt.Errorf("create directory %s: %w", dir, err)
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// sshHost returns the host of an SSH target like root@192.168.1.8.
+func sshHost(target string) string {
+	target = strings.TrimPrefix(target, "ssh://")
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		target = target[i+1:]
+	}
+	return target
+}
+
+// knownHostsFile returns the user's SSH known_hosts file.
+func knownHostsFile() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// checkKnownHosts verifies the DEPLOY_TARGET host key is in known_hosts,
+// since remote deploys run ssh in batch mode and can't accept a new one.
+func checkKnownHosts() CheckResult {
+	host := sshHost(os.Getenv("DEPLOY_TARGET"))
+	if host == "" {
+		return CheckResult{} // Local deploys don't use SSH
+	}
+	keygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		return CheckResult{} // Can't look it up without OpenSSH
+	}
+
+	file := knownHostsFile()
+	if exec.Command(keygen, "-F", host, "-f", file).Run() == nil {
+		ui.Green.Printf("  * Host key for %s is known\n", host)
+		return CheckResult{Passed: 1}
+	}
+	ui.Yellow.Printf("  ! Host key for %s not in %s\n", host, file)
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Printf("      - Run: ssh-keyscan %s >> %s\n", host, file)
+	ui.Blue.Println("      - Or run: bosun doctor --fix")
+	return CheckResult{Warned: 1}
+}
+
+// fixKnownHosts offers to add the DEPLOY_TARGET host keys to known_hosts.
+// The keys are scanned first so their fingerprints can be checked before
+// they are trusted.
+func fixKnownHosts(*config.Config) (*doctorFix, error) {
+	host := sshHost(os.Getenv("DEPLOY_TARGET"))
+	if host == "" {
+		return nil, nil
+	}
+	keyscan, err := exec.LookPath("ssh-keyscan")
+	if err != nil {
+		return nil, errors.New("ssh-keyscan not found")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(keyscan, "-T", fmt.Sprint(keyscanTimeout), host)
+	cmd.Stderr = &stderr
+	scanned, err := cmd.Output()
+	if err != nil || len(bytes.TrimSpace(scanned)) == 0 {
+		return nil, fmt.Errorf("ssh-keyscan %s found no host keys: %s", host, strings.TrimSpace(stderr.String()))
+	}
+	fingerprints, err := hostKeyFingerprints(scanned)
+	if err != nil {
+		return nil, err
+	}
+
+	file := knownHostsFile()
+	return &doctorFix{
+		Prompt: fmt.Sprintf("Trust %s (%s) and add it to %s?", host, strings.Join(fingerprints, ", "), file),
+		Apply:  func() error { return appendKnownHosts(file, scanned) },
+	}, nil
+}
+
+// hostKeyFingerprints returns "type SHA256:..." for each key in
+// ssh-keyscan output.
+func hostKeyFingerprints(scanned []byte) ([]string, error) {
+	var fingerprints []string
+	rest := scanned
+	for len(bytes.TrimSpace(rest)) > 0 {
+		_, _, key, _, next, err := xssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break // Only comments left
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse ssh-keyscan output: %w", err)
+		}
+		fingerprints = append(fingerprints, key.Type()+" "+xssh.FingerprintSHA256(key))
+		rest = next
+	}
+	return fingerprints, nil
+}
+
+// appendKnownHosts appends host key lines to a known_hosts file.
+func appendKnownHosts(file string, lines []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(file), err)
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", file, err)
+	}
+	defer f.Close()
+	if !bytes.HasSuffix(lines, []byte("\n")) {
+		lines = append(lines, '\n')
+	}
+	if _, err := f.Write(lines); err != nil {
+		return fmt.Errorf("write %s: %w", file, err)
+	}
+	return nil
+}
+
+// projectConfigFiles are the project config files, in the order they are
+// read.
+func projectConfigFiles(root string) []string {
+	return []string{filepath.Join(root, ".bosun", "config.yml"), filepath.Join(root, "bosun.yml")}
+}
+
+// checkProjectConfig reports whether the project has a config file.
+func checkProjectConfig(cfg *config.Config) CheckResult {
+	if cfg == nil {
+		return CheckResult{} // Skip if no config
+	}
+	for _, path := range projectConfigFiles(cfg.Root) {
+		if _, err := os.Stat(path); err == nil {
+			rel, _ := filepath.Rel(cfg.Root, path)
+			ui.Green.Printf("  * Project config found: %s\n", rel)
+			return CheckResult{Passed: 1}
+		}
+	}
+	ui.Yellow.Println("  ! No project config (.bosun/config.yml or bosun.yml); using defaults")
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Println("      - Run: bosun doctor --fix to scaffold .bosun/config.yml")
+	return CheckResult{Warned: 1}
+}
+
+// fixProjectConfig offers to scaffold .bosun/config.yml.
+func fixProjectConfig(cfg *config.Config) (*doctorFix, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	for _, path := range projectConfigFiles(cfg.Root) {
+		if _, err := os.Stat(path); err == nil {
+			return nil, nil
+		}
+	}
+	path := projectConfigFiles(cfg.Root)[0]
+	return &doctorFix{
+		Prompt: fmt.Sprintf("Create %s with the default settings?", path),
+		Apply: func() error {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+			if err != nil {
+				return fmt.Errorf("create %s: %w", path, err)
+			}
+			if _, err := f.WriteString(starterProjectConfig); err != nil {
+				f.Close()
+				return fmt.Errorf("write %s: %w", path, err)
+			}
+			return f.Close()
+		},
+	}, nil
+}
+
+// starterProjectConfig is the .bosun/config.yml doctor --fix scaffolds. It
+// spells out the defaults so there is something to edit.
+const starterProjectConfig = `# Bosun project configuration
+
+# Infrastructure containers: shown on 'bosun status' and skipped by 'bosun drift'
+infrastructure:
+  containers:
+    - traefik
+    - authelia
+    - gatus
+
+# Remote access tunnel: tailscale or cloudflare
+tunnel:
+  provider: tailscale
+
+# Alert providers (credentials can be sealed with 'bosun config seal')
+# alerts:
+#   discord_webhook_url: https://discord.com/api/webhooks/...
+#   on_failure: true
+
+# Snapshot retention for 'bosun provision'
+# snapshots:
+#   keep: 20
+#   keep_daily: 7
+
+# Daemon settings
+# daemon:
+#   drift_remediate: false
+#   drift_debounce: 5m
+#   maintenance_windows: "03:30-05:00"
+
+# Lint check severities: error, warn, or info
+# lint:
+#   severity:
+#     coupling: warn
+`
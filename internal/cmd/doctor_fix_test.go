@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xssh "golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+)
+
+func TestWriteAgeKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sops", "age", "keys.txt")
+
+	recipient, err := writeAgeKey(path, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# created: 2026-01-02T03:04:05Z\n# public key: "+recipient+"\n")
+	ids, err := age.ParseIdentities(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Equal(t, recipient, ids[0].(*age.X25519Identity).Recipient().String())
+
+	_, err = writeAgeKey(path, time.Now())
+	assert.Error(t, err, "an existing key must not be overwritten")
+	after, _ := os.ReadFile(path)
+	assert.Equal(t, data, after)
+}
+
+func TestFixAgeKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.txt")
+	t.Setenv("SOPS_AGE_KEY_FILE", path)
+
+	fix, err := fixAgeKey(nil)
+	require.NoError(t, err)
+	require.NotNil(t, fix)
+	assert.Contains(t, fix.Prompt, path)
+	require.NoError(t, fix.Apply())
+	assert.FileExists(t, path)
+
+	fix, err = fixAgeKey(nil)
+	require.NoError(t, err)
+	assert.Nil(t, fix, "nothing to fix once the key exists")
+}
+
+func TestFixManifestDirectory(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{Root: root, ManifestDir: filepath.Join(root, "manifest")}
+	require.NoError(t, os.MkdirAll(cfg.ServicesDir(), 0755))
+
+	fix, err := fixManifestDirectory(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, fix)
+	assert.Contains(t, fix.Prompt, cfg.ProvisionsDir())
+	assert.Contains(t, fix.Prompt, cfg.StacksDir())
+	assert.NotContains(t, fix.Prompt, cfg.ServicesDir())
+
+	require.NoError(t, fix.Apply())
+	for _, dir := range manifestDirs(cfg) {
+		assert.DirExists(t, dir)
+	}
+
+	fix, err = fixManifestDirectory(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, fix)
+}
+
+func TestProjectConfigFix(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{Root: root}
+	assert.Equal(t, CheckResult{Warned: 1}, checkProjectConfig(cfg))
+
+	fix, err := fixProjectConfig(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, fix)
+	require.NoError(t, fix.Apply())
+
+	data, err := os.ReadFile(filepath.Join(root, ".bosun", "config.yml"))
+	require.NoError(t, err)
+	var parsed map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &parsed))
+	assert.Contains(t, parsed, "infrastructure")
+	assert.Equal(t, CheckResult{Passed: 1}, checkProjectConfig(cfg))
+
+	fix, err = fixProjectConfig(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, fix)
+
+	t.Run("bosun.yml counts", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "bosun.yml"), []byte("aliases: {}\n"), 0644))
+		fix, err := fixProjectConfig(&config.Config{Root: root})
+		require.NoError(t, err)
+		assert.Nil(t, fix)
+	})
+}
+
+func TestSSHHost(t *testing.T) {
+	for target, want := range map[string]string{
+		"":                          "",
+		"tower":                     "tower",
+		"root@192.168.1.8":          "192.168.1.8",
+		"ssh://deploy@tower.local":  "tower.local",
+		"user@corp@bastion.example": "bastion.example",
+	} {
+		assert.Equal(t, want, sshHost(target), target)
+	}
+}
+
+func TestHostKeyFingerprints(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := xssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	scanned := []byte("tower " + string(xssh.MarshalAuthorizedKey(key)))
+	got, err := hostKeyFingerprints(scanned)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssh-ed25519 " + xssh.FingerprintSHA256(key)}, got)
+
+	_, err = hostKeyFingerprints([]byte("tower not-a-key\n"))
+	assert.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+	require.NoError(t, appendKnownHosts(file, []byte("old ssh-ed25519 AAAA")))
+	require.NoError(t, appendKnownHosts(file, scanned))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "old ssh-ed25519 AAAA\n"+string(scanned), string(data))
+}
+
+func TestOfferDoctorFix(t *testing.T) {
+	applied := 0
+	check := doctorCheck{
+		ID: "sample",
+		Fix: func(*config.Config) (*doctorFix, error) {
+			return &doctorFix{Prompt: "Fix it?", Apply: func() error { applied++; return nil }}, nil
+		},
+	}
+	orig := doctorConfirm
+	t.Cleanup(func() { doctorConfirm = orig })
+	answer := func(ok bool, err error) {
+		doctorConfirm = func(string) (bool, error) { return ok, err }
+	}
+
+	answer(false, nil)
+	assert.False(t, offerDoctorFix(check, nil))
+	assert.Equal(t, 0, applied)
+
+	answer(false, errors.New("stdin is not a TTY"))
+	assert.False(t, offerDoctorFix(check, nil))
+	assert.Equal(t, 0, applied)
+
+	answer(true, nil)
+	assert.True(t, offerDoctorFix(check, nil))
+	assert.Equal(t, 1, applied)
+
+	check.Fix = func(*config.Config) (*doctorFix, error) {
+		return &doctorFix{Prompt: "Fix it?", Apply: func() error { return errors.New("disk full") }}, nil
+	}
+	assert.False(t, offerDoctorFix(check, nil))
+
+	check.Fix = func(*config.Config) (*doctorFix, error) { return nil, nil }
+	assert.False(t, offerDoctorFix(check, nil))
+	check.Fix = nil
+	assert.False(t, offerDoctorFix(check, nil))
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+func checkIDs(checks []doctorCheck) []string {
+	ids := make([]string, len(checks))
+	for i, c := range checks {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestDoctorChecks_UniqueIDs(t *testing.T) {
+	seen := map[string]bool{}
+	for _, c := range doctorChecks {
+		assert.False(t, seen[c.ID], "duplicate check ID %q", c.ID)
+		seen[c.ID] = true
+		assert.Contains(t, severityRank, c.Severity, "check %q", c.ID)
+	}
+}
+
+func TestSelectDoctorChecks(t *testing.T) {
+	t.Run("all by default", func(t *testing.T) {
+		checks, err := selectDoctorChecks(nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, doctorCheckIDs(), checkIDs(checks))
+	})
+
+	t.Run("only keeps registry order", func(t *testing.T) {
+		checks, err := selectDoctorChecks([]string{"sops", "docker", "compose"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docker", "compose", "sops"}, checkIDs(checks))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		checks, err := selectDoctorChecks(nil, []string{"webhook", "tunnel"})
+		require.NoError(t, err)
+		assert.NotContains(t, checkIDs(checks), "webhook")
+		assert.NotContains(t, checkIDs(checks), "tunnel")
+		assert.Len(t, checks, len(doctorChecks)-2)
+	})
+
+	t.Run("only and skip", func(t *testing.T) {
+		checks, err := selectDoctorChecks([]string{"docker", "webhook"}, []string{"webhook"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docker"}, checkIDs(checks))
+	})
+
+	t.Run("unknown ID", func(t *testing.T) {
+		_, err := selectDoctorChecks(nil, []string{"dokcer"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown check "dokcer"`)
+		assert.Contains(t, err.Error(), "docker")
+	})
+}
+
+func TestCheckStatus(t *testing.T) {
+	assert.Equal(t, checkStatusFail, checkStatus(CheckResult{Passed: 2, Warned: 1, Failed: 1}))
+	assert.Equal(t, checkStatusWarn, checkStatus(CheckResult{Passed: 2, Warned: 1}))
+	assert.Equal(t, checkStatusPass, checkStatus(CheckResult{Passed: 1}))
+	assert.Equal(t, checkStatusSkip, checkStatus(CheckResult{}))
+}
+
+func TestDoctorFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		severity string
+		status   string
+		failOn   string
+		want     bool
+	}{
+		{"default fails on failure", severityInfo, checkStatusFail, "", true},
+		{"default ignores warnings", severityCritical, checkStatusWarn, "", false},
+		{"below threshold", severityInfo, checkStatusFail, severityWarning, false},
+		{"at threshold warns", severityWarning, checkStatusWarn, severityWarning, true},
+		{"above threshold", severityCritical, checkStatusFail, severityWarning, true},
+		{"passing never fails", severityCritical, checkStatusPass, severityInfo, false},
+		{"skipped never fails", severityCritical, checkStatusSkip, severityInfo, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := doctorResult{Severity: tt.severity, Status: tt.status}
+			assert.Equal(t, tt.want, doctorFails(r, tt.failOn))
+		})
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	assert.NoError(t, validateFailOn(""))
+	assert.NoError(t, validateFailOn(severityCritical))
+	assert.Error(t, validateFailOn("fatal"))
+}
+
+func TestParseCheckOutput(t *testing.T) {
+	output := `  x Docker is not running
+      To fix this:
+      - Start Docker: systemctl start docker
+      - Or use Docker Desktop on macOS/Windows
+`
+	message, details, hints := parseCheckOutput(output)
+	assert.Equal(t, "Docker is not running", message)
+	assert.Empty(t, details)
+	assert.Equal(t, []string{"Start Docker: systemctl start docker", "Or use Docker Desktop on macOS/Windows"}, hints)
+
+	output = `  * Bind mounts checked
+      /mnt/user/appdata
+  ! Missing /mnt/user/media
+`
+	message, details, hints = parseCheckOutput(output)
+	assert.Equal(t, "Bind mounts checked", message)
+	assert.Equal(t, []string{"/mnt/user/appdata", "Missing /mnt/user/media"}, details)
+	assert.Empty(t, hints)
+}
+
+func TestRunDoctorCheck_Capture(t *testing.T) {
+	check := doctorCheck{
+		ID:       "sample",
+		Name:     "Sample",
+		Severity: severityWarning,
+		Run: func(*config.Config) CheckResult {
+			ui.Yellow.Println("  ! Something is off")
+			ui.Blue.Println("      To fix this:")
+			ui.Blue.Println("      - Turn it off and on again")
+			return CheckResult{Warned: 1}
+		},
+	}
+
+	counts, res := runDoctorCheck(check, nil, true)
+	assert.Equal(t, CheckResult{Warned: 1}, counts)
+	assert.Equal(t, doctorResult{
+		ID:       "sample",
+		Name:     "Sample",
+		Severity: severityWarning,
+		Status:   checkStatusWarn,
+		Message:  "Something is off",
+		Hints:    []string{"Turn it off and on again"},
+	}, res)
+}
+
+func TestNewDoctorReport(t *testing.T) {
+	results := []doctorResult{
+		{ID: "docker", Severity: severityCritical, Status: checkStatusPass},
+		{ID: "sops", Severity: severityWarning, Status: checkStatusWarn},
+		{ID: "webhook", Severity: severityInfo, Status: checkStatusFail},
+		{ID: "tunnel", Severity: severityInfo, Status: checkStatusSkip},
+	}
+
+	report := newDoctorReport(results, "")
+	assert.False(t, report.OK)
+	assert.Equal(t, doctorSummary{Passed: 1, Warned: 1, Failed: 1, Skipped: 1}, report.Summary)
+
+	assert.True(t, newDoctorReport(results, severityCritical).OK)
+	assert.False(t, newDoctorReport(results, severityWarning).OK)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeDoctorJSON(&buf, newDoctorReport(nil, "")))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, true, decoded["ok"])
+	assert.Equal(t, []any{}, decoded["checks"])
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// toolVersion is a major.minor.patch version of an external tool.
+type toolVersion struct {
+	Major, Minor, Patch int
+}
+
+// Minimum versions of the tools bosun shells out to.
+var (
+	// composeMinVersion is the first Compose release with include: support,
+	// which rendered stacks can use.
+	composeMinVersion = toolVersion{2, 20, 0}
+	// sopsMinVersion is the oldest SOPS bosun's age-encrypted secrets are
+	// tested with.
+	sopsMinVersion = toolVersion{3, 8, 0}
+	// gitMinVersion is the oldest Git the reconciler's clone and fetch
+	// flags work with.
+	gitMinVersion = toolVersion{2, 30, 0}
+)
+
+// toolVersionPattern matches the first version number in a tool's output.
+var toolVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseToolVersion returns the first version number in output, such as
+// "2.29.7" in "Docker Compose version v2.29.7-desktop.1".
+func parseToolVersion(output string) (toolVersion, bool) {
+	m := toolVersionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return toolVersion{}, false
+	}
+	var v toolVersion
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3]) // Empty when the patch is omitted
+	return v, true
+}
+
+// atLeast reports whether v is min or newer.
+func (v toolVersion) atLeast(min toolVersion) bool {
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	if v.Minor != min.Minor {
+		return v.Minor > min.Minor
+	}
+	return v.Patch >= min.Patch
+}
+
+func (v toolVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// toolVersionOutput runs a tool's version command in the C locale, so the
+// output is not translated.
+func toolVersionOutput(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C", "LANG=C")
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// releaseArch returns the architecture name used in Linux-style release
+// asset names for a Go GOARCH, e.g. "x86_64" for amd64.
+func releaseArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "arm":
+		return "armv7"
+	}
+	return goarch
+}
+
+// composeReleaseAsset returns the Compose plugin binary to download for a
+// platform, e.g. "docker-compose-linux-x86_64".
+func composeReleaseAsset(goos, goarch string) string {
+	return fmt.Sprintf("docker-compose-%s-%s", goos, releaseArch(goarch))
+}
+
+// sopsReleaseAsset returns the SOPS binary to download for a platform, e.g.
+// "sops-v3.8.1.linux.amd64".
+func sopsReleaseAsset(goos, goarch string, v toolVersion) string {
+	return fmt.Sprintf("sops-v%s.%s.%s", v, goos, goarch)
+}
+
+// reportComposeVersion checks the output of 'docker compose version --short'
+// against composeMinVersion.
+func reportComposeVersion(output string) CheckResult {
+	v, ok := parseToolVersion(output)
+	if !ok || v.atLeast(composeMinVersion) {
+		ui.Green.Printf("  * Docker Compose v2 (%s)\n", output)
+		return CheckResult{Passed: 1}
+	}
+	ui.Red.Printf("  x Docker Compose %s is older than %s (rendered stacks may use include:)\n", v, composeMinVersion)
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Println("      - Upgrade Docker Desktop, or the docker-compose-plugin package")
+	ui.Blue.Printf("      - Or: download %s from https://github.com/docker/compose/releases\n", composeReleaseAsset(runtime.GOOS, runtime.GOARCH))
+	ui.Blue.Println("        to ~/.docker/cli-plugins/docker-compose")
+	return CheckResult{Failed: 1}
+}
+
+// reportGitVersion checks the output of 'git --version' against
+// gitMinVersion.
+func reportGitVersion(output string) CheckResult {
+	v, ok := parseToolVersion(output)
+	if !ok {
+		ui.Green.Println("  * Git is installed")
+		return CheckResult{Passed: 1}
+	}
+	if v.atLeast(gitMinVersion) {
+		ui.Green.Printf("  * Git is installed (%s)\n", v)
+		return CheckResult{Passed: 1}
+	}
+	ui.Red.Printf("  x Git %s is older than %s\n", v, gitMinVersion)
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Println("      - macOS: brew upgrade git")
+	ui.Blue.Println("      - Ubuntu/Debian: apt-get install git (or the git-core PPA on older releases)")
+	ui.Blue.Println("      - Fedora/RHEL: dnf upgrade git")
+	return CheckResult{Failed: 1}
+}
+
+// reportSOPSVersion checks the output of 'sops --version' against
+// sopsMinVersion.
+func reportSOPSVersion(output string) CheckResult {
+	v, ok := parseToolVersion(output)
+	if !ok {
+		ui.Green.Println("  * SOPS is installed")
+		return CheckResult{Passed: 1}
+	}
+	if v.atLeast(sopsMinVersion) {
+		ui.Green.Printf("  * SOPS is installed (%s)\n", v)
+		return CheckResult{Passed: 1}
+	}
+	ui.Yellow.Printf("  ! SOPS %s is older than %s (age support may be incomplete)\n", v, sopsMinVersion)
+	ui.Blue.Println("      To fix this:")
+	ui.Blue.Println("      - macOS: brew upgrade sops")
+	ui.Blue.Printf("      - Or: download %s from https://github.com/getsops/sops/releases\n", sopsReleaseAsset(runtime.GOOS, runtime.GOARCH, sopsMinVersion))
+	return CheckResult{Warned: 1}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToolVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   toolVersion
+		ok     bool
+	}{
+		{"2.29.7", toolVersion{2, 29, 7}, true},
+		{"v2.20.0-desktop.1", toolVersion{2, 20, 0}, true},
+		{"git version 2.39.5 (Apple Git-154)", toolVersion{2, 39, 5}, true},
+		{"sops 3.8.1 (latest)", toolVersion{3, 8, 1}, true},
+		{"Docker Compose version 2.17", toolVersion{2, 17, 0}, true},
+		{"unknown", toolVersion{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseToolVersion(tt.output)
+		assert.Equal(t, tt.ok, ok, tt.output)
+		assert.Equal(t, tt.want, got, tt.output)
+	}
+}
+
+func TestToolVersion_AtLeast(t *testing.T) {
+	min := toolVersion{2, 20, 0}
+	assert.True(t, toolVersion{2, 20, 0}.atLeast(min))
+	assert.True(t, toolVersion{2, 29, 1}.atLeast(min))
+	assert.True(t, toolVersion{3, 0, 0}.atLeast(min))
+	assert.False(t, toolVersion{2, 19, 9}.atLeast(min))
+	assert.False(t, toolVersion{1, 99, 0}.atLeast(min))
+}
+
+func TestReleaseAssets(t *testing.T) {
+	assert.Equal(t, "docker-compose-linux-x86_64", composeReleaseAsset("linux", "amd64"))
+	assert.Equal(t, "docker-compose-darwin-aarch64", composeReleaseAsset("darwin", "arm64"))
+	assert.Equal(t, "docker-compose-linux-armv7", composeReleaseAsset("linux", "arm"))
+	assert.Equal(t, "sops-v3.8.0.linux.arm64", sopsReleaseAsset("linux", "arm64", toolVersion{3, 8, 0}))
+}
+
+func TestReportToolVersions(t *testing.T) {
+	assert.Equal(t, CheckResult{Passed: 1}, reportComposeVersion("2.29.7"))
+	assert.Equal(t, CheckResult{Failed: 1}, reportComposeVersion("2.17.3"))
+	assert.Equal(t, CheckResult{Passed: 1}, reportComposeVersion("dev"), "unparseable versions are not blocked")
+
+	assert.Equal(t, CheckResult{Passed: 1}, reportGitVersion("git version 2.39.5"))
+	assert.Equal(t, CheckResult{Failed: 1}, reportGitVersion("git version 2.25.1"))
+
+	assert.Equal(t, CheckResult{Passed: 1}, reportSOPSVersion("sops 3.9.0 (latest)"))
+	assert.Equal(t, CheckResult{Warned: 1}, reportSOPSVersion("sops 3.7.3"))
+}
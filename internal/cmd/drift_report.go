@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/alert"
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// Drift statuses of a service.
+const (
+	driftStatusOK            = "ok"
+	driftStatusImageMismatch = "image_mismatch"
+	driftStatusConfigChanged = "config_changed"
+	driftStatusNotRunning    = "not_running"
+	driftStatusNotDeployed   = "not_deployed"
+	driftStatusOrphan        = "orphan"
+)
+
+// driftReport compares running containers with the rendered stacks.
+type driftReport struct {
+	Drift   bool         `json:"drift" yaml:"drift"`
+	Running int          `json:"running" yaml:"running"` // Running containers
+	Summary driftSummary `json:"summary" yaml:"summary"`
+	// Services are the services the stacks expect, by stack then name.
+	Services []driftService `json:"services" yaml:"services"`
+	// Orphans are running containers no stack expects.
+	Orphans   []driftService `json:"orphans" yaml:"orphans"`
+	PortDrift []string       `json:"port_drift" yaml:"port_drift"`
+}
+
+// driftSummary counts the findings of a drift report.
+type driftSummary struct {
+	InSync          int `json:"in_sync" yaml:"in_sync"`
+	ImageMismatches int `json:"image_mismatches" yaml:"image_mismatches"`
+	ConfigChanged   int `json:"config_changed" yaml:"config_changed"`
+	NotRunning      int `json:"not_running" yaml:"not_running"`
+	NotDeployed     int `json:"not_deployed" yaml:"not_deployed"`
+	Orphans         int `json:"orphans" yaml:"orphans"`
+	PortDrift       int `json:"port_drift" yaml:"port_drift"`
+}
+
+// driftService is one service's drift status.
+type driftService struct {
+	Name          string      `json:"name" yaml:"name"`
+	Stack         string      `json:"stack,omitempty" yaml:"stack,omitempty"`
+	Status        string      `json:"status" yaml:"status"`
+	ExpectedImage string      `json:"expected_image,omitempty" yaml:"expected_image,omitempty"`
+	RunningImage  string      `json:"running_image,omitempty" yaml:"running_image,omitempty"`
+	Cause         *driftCause `json:"cause,omitempty" yaml:"cause,omitempty"`
+}
+
+// buildDriftReport compares containers (running and stopped) with the
+// rendered stacks in cfg's output directory. events explain findings.
+func buildDriftReport(cfg *config.Config, containers []docker.ContainerInfo, events []docker.ContainerEvent) *driftReport {
+	report := &driftReport{Services: []driftService{}, Orphans: []driftService{}, PortDrift: []string{}}
+
+	allContainers := make(map[string]docker.ContainerInfo)
+	runningNames := make(map[string]string) // name -> image
+	for _, ctr := range containers {
+		allContainers[ctr.Name] = ctr
+		if ctr.State == "running" {
+			runningNames[ctr.Name] = ctr.Image
+		}
+	}
+	report.Running = len(runningNames)
+	if report.Running == 0 {
+		return report
+	}
+
+	stackFiles, _ := filepath.Glob(filepath.Join(cfg.OutputDir(), "compose", "*.yml"))
+	history := newDriftHistory(stackFiles, events)
+
+	allExpected := make(map[string]bool)
+	for _, stackFile := range stackFiles {
+		stackName := strings.TrimSuffix(filepath.Base(stackFile), ".yml")
+		expected := extractServicesFromCompose(stackFile)
+		names := make([]string, 0, len(expected))
+		for svc := range expected {
+			names = append(names, svc)
+		}
+		sort.Strings(names)
+
+		for _, svc := range names {
+			allExpected[svc] = true
+			s := driftService{Name: svc, Stack: stackName, Status: driftStatusOK, ExpectedImage: expected[svc]}
+
+			runningImage, isRunning := runningNames[svc]
+			switch {
+			case !isRunning:
+				s.Status = driftStatusNotRunning
+				var cause driftCause
+				if ctr, exists := allContainers[svc]; exists {
+					cause = history.explainNotRunning(svc, &ctr)
+				} else {
+					cause = history.explainNotRunning(svc, nil)
+				}
+				s.Cause = &cause
+				report.Summary.NotRunning++
+			// Use normalized comparison to avoid false positives from tag vs digest
+			case s.ExpectedImage != "" && normalizeImage(runningImage) != normalizeImage(s.ExpectedImage):
+				s.Status = driftStatusImageMismatch
+				s.RunningImage = runningImage
+				cause := history.explainImageDrift(stackName, allContainers[svc])
+				s.Cause = &cause
+				report.Summary.ImageMismatches++
+			default:
+				s.RunningImage = runningImage
+				report.Summary.InSync++
+			}
+			report.Services = append(report.Services, s)
+		}
+	}
+
+	// Running containers no stack expects, skipping known infrastructure
+	skip := append(cfg.InfraContainers(), "bosun")
+	var portContainers []docker.ContainerInfo
+	for _, ctr := range containers {
+		if ctr.State != "running" || slices.Contains(skip, ctr.Name) {
+			continue
+		}
+		portContainers = append(portContainers, ctr)
+		if !allExpected[ctr.Name] {
+			cause := history.explainOrphan(ctr)
+			report.Orphans = append(report.Orphans, driftService{
+				Name: ctr.Name, Status: driftStatusOrphan, RunningImage: ctr.Image, Cause: &cause,
+			})
+		}
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Name < report.Orphans[j].Name })
+	report.Summary.Orphans = len(report.Orphans)
+
+	// Published ports checked against the port registry
+	if findings := loadPortRegistry(cfg).Drift(portContainers); len(findings) > 0 {
+		report.PortDrift = findings
+	}
+	report.Summary.PortDrift = len(report.PortDrift)
+
+	report.updateDrift()
+	return report
+}
+
+// applyDeployState marks services that aren't running because their stack
+// has never been deployed, per the state file, as not deployed rather than
+// drifted. Without deploy records nothing is marked.
+func applyDeployState(report *driftReport, st *state.State) {
+	for i := range report.Services {
+		s := &report.Services[i]
+		if s.Status != driftStatusNotRunning || !st.NeverDeployed(s.Stack) {
+			continue
+		}
+		s.Status = driftStatusNotDeployed
+		s.Cause = &driftCause{
+			Cause: fmt.Sprintf("stack %s has never been deployed", s.Stack),
+			Fix:   fmt.Sprintf("bosun yacht up --only %s", s.Stack),
+		}
+		report.Summary.NotRunning--
+		report.Summary.NotDeployed++
+	}
+	report.updateDrift()
+}
+
+// loadDeployState loads the state file from dir, or returns nil when the
+// directory doesn't exist, as on a workstation, or can't be read.
+func loadDeployState(dir string) *state.State {
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	st, err := state.NewStore(dir).Load()
+	if err != nil {
+		return nil
+	}
+	return st
+}
+
+// updateDrift sets Drift from the summary counts. Services never deployed
+// aren't drift.
+func (r *driftReport) updateDrift() {
+	s := r.Summary
+	r.Drift = s.ImageMismatches+s.ConfigChanged+s.NotRunning+s.Orphans+s.PortDrift > 0
+}
+
+// findings returns one line per drift finding, for alerts.
+func (r *driftReport) findings() []string {
+	var lines []string
+	for _, svc := range r.Services {
+		if svc.Status != driftStatusOK && svc.Status != driftStatusNotDeployed {
+			lines = append(lines, fmt.Sprintf("%s: %s", svc.Name, strings.ReplaceAll(svc.Status, "_", " ")))
+		}
+	}
+	for _, svc := range r.Orphans {
+		lines = append(lines, fmt.Sprintf("%s: %s", svc.Name, driftStatusOrphan))
+	}
+	return append(lines, r.PortDrift...)
+}
+
+// sendDriftAlert sends the drift findings through the alert providers in
+// alertCfg and returns the providers alerted.
+func sendDriftAlert(ctx context.Context, alertCfg config.AlertConfig, report *driftReport) ([]string, error) {
+	providers, _ := alertProviders(alertCfg, "")
+	mgr := alert.NewManager()
+	for _, p := range providers {
+		mgr.AddProvider(p)
+	}
+	if !mgr.HasProviders() {
+		return nil, fmt.Errorf("no alert providers configured")
+	}
+
+	target, err := os.Hostname()
+	if err != nil {
+		target = "local"
+	}
+	return mgr.ProviderNames(), mgr.SendDrift(ctx, target, report.findings())
+}
+
+// stackConfigHashes returns the config hash compose computes for each
+// service of each rendered stack, by stack then service. Stacks whose
+// hashes can't be computed are left out.
+func stackConfigHashes(ctx context.Context, cfg *config.Config) map[string]map[string]string {
+	stackFiles, _ := filepath.Glob(filepath.Join(cfg.OutputDir(), "compose", "*.yml"))
+	hashes := make(map[string]map[string]string)
+	for _, stackFile := range stackFiles {
+		compose, err := docker.NewComposeClient(stackFile)
+		if err != nil {
+			continue
+		}
+		compose = compose.WithProject(reconcile.ComposeProjectName(stackFile))
+		if stackHashes, err := compose.ConfigHashes(ctx); err == nil {
+			hashes[strings.TrimSuffix(filepath.Base(stackFile), ".yml")] = stackHashes
+		}
+	}
+	return hashes
+}
+
+// applyConfigDrift marks in-sync services whose container was created from
+// a different config than the rendered stack now gives, comparing the
+// expected hashes with the docker.ConfigHashLabel of each container.
+// Containers without the label, such as those not created by compose, are
+// left alone.
+func applyConfigDrift(report *driftReport, containers []docker.ContainerInfo, hashes map[string]map[string]string) {
+	labels := make(map[string]string)
+	for _, ctr := range containers {
+		labels[ctr.Name] = ctr.Labels[docker.ConfigHashLabel]
+	}
+
+	for i := range report.Services {
+		s := &report.Services[i]
+		if s.Status != driftStatusOK {
+			continue
+		}
+		expected, running := hashes[s.Stack][s.Name], labels[s.Name]
+		if expected == "" || running == "" || expected == running {
+			continue
+		}
+		s.Status = driftStatusConfigChanged
+		s.Cause = &driftCause{
+			Cause: "container config differs from the rendered stack (changed since the last deploy)",
+			Fix:   fmt.Sprintf("bosun yacht up --only %s", s.Stack),
+		}
+		report.Summary.InSync--
+		report.Summary.ConfigChanged++
+	}
+	report.updateDrift()
+}
+
+// recordDriftEvent counts detected drift in the weekly digest counters. It
+// does nothing when the state directory doesn't exist, as on a workstation.
+func recordDriftEvent(dir string, now time.Time) {
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	err := state.NewStore(dir).Update(func(st *state.State) error {
+		st.Day(now).DriftEvents++
+		return nil
+	})
+	if err != nil {
+		ui.Warning("Failed to record drift: %v", err)
+	}
+}
+
+// printDriftReport prints a drift report in the given format: table, json, or yaml.
+func printDriftReport(report *driftReport, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(report)
+	case "table":
+		printDriftTable(report)
+		return nil
+	}
+	return fmt.Errorf("invalid format %q (want table, json, or yaml)", format)
+}
+
+// printDriftTable prints a drift report as colored sections.
+func printDriftTable(report *driftReport) {
+	if report.Running == 0 {
+		ui.Yellow.Println("No containers running")
+		fmt.Println()
+		ui.Green.Println("* No drift - running state matches manifests")
+		return
+	}
+
+	ui.Blue.Println("--- Container Drift ---")
+	for _, s := range report.Services {
+		switch s.Status {
+		case driftStatusImageMismatch:
+			ui.Yellow.Printf("  ~ %s: image drift\n", s.Name)
+			fmt.Printf("      Expected: %s\n", s.ExpectedImage)
+			fmt.Printf("      Running:  %s\n", s.RunningImage)
+			printDriftCause(*s.Cause)
+		case driftStatusConfigChanged:
+			ui.Yellow.Printf("  ~ %s: config drift\n", s.Name)
+			printDriftCause(*s.Cause)
+		case driftStatusNotRunning:
+			ui.Red.Printf("  x %s: not running (expected by %s)\n", s.Name, s.Stack)
+			printDriftCause(*s.Cause)
+		case driftStatusNotDeployed:
+			ui.Cyan.Printf("  - %s: not deployed yet (stack %s)\n", s.Name, s.Stack)
+			printDriftCause(*s.Cause)
+		default:
+			ui.Green.Printf("  * %s\n", s.Name)
+		}
+	}
+
+	fmt.Println()
+	ui.Blue.Println("--- Orphaned Containers ---")
+	for _, o := range report.Orphans {
+		ui.Yellow.Printf("  ? %s: not in any manifest\n", o.Name)
+		printDriftCause(*o.Cause)
+	}
+	if len(report.Orphans) == 0 {
+		ui.Green.Println("  * No orphaned containers")
+	}
+
+	fmt.Println()
+	ui.Blue.Println("--- Port Drift ---")
+	for _, finding := range report.PortDrift {
+		ui.Yellow.Printf("  ~ %s\n", finding)
+	}
+	if len(report.PortDrift) == 0 {
+		ui.Green.Println("  * Published ports match the port registry")
+	}
+
+	fmt.Println()
+	if report.Drift {
+		ui.Yellow.Println("Drift detected. Run 'bosun yacht up' to reconcile.")
+	} else {
+		ui.Green.Println("* No drift - running state matches manifests")
+	}
+}
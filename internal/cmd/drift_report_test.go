@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestDriftCmd_FormatFlags(t *testing.T) {
+	output, err := executeCmd(t, "drift", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "--json")
+	assert.Contains(t, output, "--alert")
+	assert.Contains(t, output, "--format")
+}
+
+func TestBuildDriftReport(t *testing.T) {
+	manifestDir := t.TempDir()
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	composeDir := filepath.Join(manifestDir, "output", "compose")
+	require.NoError(t, os.MkdirAll(composeDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "apps.yml"), []byte(`services:
+  web:
+    image: nginx:1.27
+  wiki:
+    image: ghcr.io/requarks/wiki:2
+  db:
+    image: postgres:16
+`), 0644))
+
+	containers := []docker.ContainerInfo{
+		{Name: "web", Image: "nginx:1.27", State: "running"},
+		{Name: "wiki", Image: "caddy:2", State: "running"},
+		{Name: "db", Image: "postgres:16", State: "exited", Status: "Exited (1) 5 minutes ago"},
+		{Name: "scratch", Image: "alpine", State: "running"},
+		{Name: "bosun", Image: "bosun", State: "running"},
+	}
+
+	report := buildDriftReport(cfg, containers, nil)
+	assert.True(t, report.Drift)
+	assert.Equal(t, 4, report.Running)
+	assert.Equal(t, driftSummary{InSync: 1, ImageMismatches: 1, NotRunning: 1, Orphans: 1, PortDrift: 0}, report.Summary)
+
+	require.Len(t, report.Services, 3)
+	assert.Equal(t, "db", report.Services[0].Name)
+	assert.Equal(t, driftStatusNotRunning, report.Services[0].Status)
+	assert.Equal(t, "crashed (exit 1)", report.Services[0].Cause.Cause)
+	assert.Equal(t, driftStatusOK, report.Services[1].Status)
+	assert.Nil(t, report.Services[1].Cause)
+	assert.Equal(t, driftStatusImageMismatch, report.Services[2].Status)
+	assert.Equal(t, "caddy:2", report.Services[2].RunningImage)
+
+	require.Len(t, report.Orphans, 1)
+	assert.Equal(t, "scratch", report.Orphans[0].Name)
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, true, decoded["drift"])
+		assert.Equal(t, []any{}, decoded["port_drift"])
+		assert.Contains(t, string(data), `"expected_image":"ghcr.io/requarks/wiki:2"`)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		data, err := yaml.Marshal(report)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "status: image_mismatch")
+		assert.Contains(t, string(data), "not_running: 1")
+	})
+}
+
+func TestBuildDriftReport_NoContainers(t *testing.T) {
+	report := buildDriftReport(&config.Config{ManifestDir: t.TempDir()}, nil, nil)
+	assert.False(t, report.Drift)
+	assert.Equal(t, 0, report.Running)
+	assert.Empty(t, report.Services)
+}
+
+func TestApplyConfigDrift(t *testing.T) {
+	report := &driftReport{
+		Summary: driftSummary{InSync: 3, NotRunning: 1},
+		Services: []driftService{
+			{Name: "web", Stack: "apps", Status: driftStatusOK},
+			{Name: "wiki", Stack: "apps", Status: driftStatusOK},
+			{Name: "manual", Stack: "apps", Status: driftStatusOK},
+			{Name: "db", Stack: "apps", Status: driftStatusNotRunning},
+		},
+	}
+	containers := []docker.ContainerInfo{
+		{Name: "web", Labels: map[string]string{docker.ConfigHashLabel: "aaa"}},
+		{Name: "wiki", Labels: map[string]string{docker.ConfigHashLabel: "old"}},
+		{Name: "manual"},
+		{Name: "db", Labels: map[string]string{docker.ConfigHashLabel: "old"}},
+	}
+	hashes := map[string]map[string]string{
+		"apps": {"web": "aaa", "wiki": "new", "manual": "ccc", "db": "ddd"},
+	}
+
+	applyConfigDrift(report, containers, hashes)
+	assert.True(t, report.Drift)
+	assert.Equal(t, driftSummary{InSync: 2, ConfigChanged: 1, NotRunning: 1}, report.Summary)
+	assert.Equal(t, driftStatusOK, report.Services[0].Status)
+	assert.Equal(t, driftStatusConfigChanged, report.Services[1].Status)
+	assert.Equal(t, "bosun yacht up --only apps", report.Services[1].Cause.Fix)
+	assert.Equal(t, driftStatusOK, report.Services[2].Status, "unlabeled containers are not compared")
+	assert.Equal(t, driftStatusNotRunning, report.Services[3].Status)
+}
+
+func TestApplyConfigDrift_NoHashes(t *testing.T) {
+	report := &driftReport{
+		Summary:  driftSummary{InSync: 1},
+		Services: []driftService{{Name: "web", Stack: "apps", Status: driftStatusOK}},
+	}
+	containers := []docker.ContainerInfo{{Name: "web", Labels: map[string]string{docker.ConfigHashLabel: "aaa"}}}
+
+	applyConfigDrift(report, containers, nil)
+	assert.False(t, report.Drift)
+	assert.Equal(t, driftStatusOK, report.Services[0].Status)
+}
+
+func TestApplyDeployState(t *testing.T) {
+	newReport := func() *driftReport {
+		return &driftReport{
+			Drift:   true,
+			Summary: driftSummary{InSync: 1, NotRunning: 2},
+			Services: []driftService{
+				{Name: "web", Stack: "apps", Status: driftStatusOK},
+				{Name: "db", Stack: "apps", Status: driftStatusNotRunning},
+				{Name: "jellyfin", Stack: "media", Status: driftStatusNotRunning},
+			},
+		}
+	}
+	st := &state.State{}
+	st.RecordApplied("apps", "aaa", "abc1234", time.Now())
+
+	report := newReport()
+	applyDeployState(report, st)
+	assert.True(t, report.Drift, "a deployed stack's stopped service is still drift")
+	assert.Equal(t, driftSummary{InSync: 1, NotRunning: 1, NotDeployed: 1}, report.Summary)
+	assert.Equal(t, driftStatusNotRunning, report.Services[1].Status)
+	assert.Equal(t, driftStatusNotDeployed, report.Services[2].Status)
+	assert.Equal(t, "bosun yacht up --only media", report.Services[2].Cause.Fix)
+	assert.Equal(t, []string{"db: not running"}, report.findings())
+
+	report = &driftReport{
+		Drift:    true,
+		Summary:  driftSummary{NotRunning: 1},
+		Services: []driftService{{Name: "jellyfin", Stack: "media", Status: driftStatusNotRunning}},
+	}
+	applyDeployState(report, st)
+	assert.False(t, report.Drift, "never-deployed stacks aren't drift")
+
+	report = newReport()
+	applyDeployState(report, &state.State{})
+	assert.Equal(t, driftSummary{InSync: 1, NotRunning: 2}, report.Summary, "without tracking nothing is marked")
+}
+
+func TestPrintDriftReport_InvalidFormat(t *testing.T) {
+	err := printDriftReport(&driftReport{}, "xml")
+	assert.ErrorContains(t, err, "invalid format")
+}
+
+func TestDriftReport_Findings(t *testing.T) {
+	report := &driftReport{
+		Services: []driftService{
+			{Name: "web", Status: driftStatusOK},
+			{Name: "wiki", Status: driftStatusConfigChanged},
+			{Name: "db", Status: driftStatusNotRunning},
+		},
+		Orphans:   []driftService{{Name: "scratch", Status: driftStatusOrphan}},
+		PortDrift: []string{"web: port 8080 not published"},
+	}
+
+	assert.Equal(t, []string{
+		"wiki: config changed",
+		"db: not running",
+		"scratch: orphan",
+		"web: port 8080 not published",
+	}, report.findings())
+}
+
+func TestSendDriftAlert(t *testing.T) {
+	t.Run("sends through configured providers", func(t *testing.T) {
+		var received map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		report := &driftReport{Services: []driftService{{Name: "wiki", Status: driftStatusImageMismatch}}}
+		providers, err := sendDriftAlert(context.Background(), config.AlertConfig{WebhookURL: server.URL}, report)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"webhook"}, providers)
+		assert.Equal(t, "Config Drift Detected", received["title"])
+		assert.Contains(t, received["message"], "wiki: image mismatch")
+	})
+
+	t.Run("respects severity routing", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cfg := config.AlertConfig{WebhookURL: server.URL, MinSeverity: map[string]string{"webhook": "error"}}
+		_, err := sendDriftAlert(context.Background(), cfg, &driftReport{})
+		require.NoError(t, err)
+		assert.False(t, called, "drift alerts are warnings")
+	})
+
+	t.Run("errors without providers", func(t *testing.T) {
+		_, err := sendDriftAlert(context.Background(), config.AlertConfig{}, &driftReport{})
+		assert.ErrorContains(t, err, "no alert providers configured")
+	})
+}
@@ -15,13 +15,16 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/config"
 	"github.com/cameronsjo/bosun/internal/docker"
 	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/cameronsjo/bosun/internal/reconcile"
 	"github.com/cameronsjo/bosun/internal/snapshot"
+	"github.com/cameronsjo/bosun/internal/timezone"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -65,11 +68,37 @@ var maydayCmd = &cobra.Command{
 
 By default, shows recent errors from all running containers.
 Use --list to show available snapshots for rollback.
-Use --rollback to restore a previous snapshot.`,
+Use --rollback to restore a previous snapshot.
+
+Use --all-stop for the big red button: it pauses polling, webhooks, and
+scheduled tasks in one shot for --for (default 1h), then automation resumes
+on its own. Manual triggers still run so a fix can be deployed. Running it
+again during the window extends it; --resume lifts it early. Who ran it,
+when, and why (--reason) are kept in the audit log in the reconciler's
+state directory, so run this where the daemon runs (e.g. docker exec bosun
+bosun mayday --all-stop).
+
+Examples:
+  bosun mayday                                       # Recent errors
+  bosun mayday --all-stop --for 1h --reason "db corruption"
+  bosun mayday --all-stop --for 30m                  # Extend
+  bosun mayday --resume --reason "restored from backup"`,
 	Run: runMayday,
 }
 
 func runMayday(cmd *cobra.Command, args []string) {
+	if maydayAllStop || maydayResume {
+		run := func() error { return runAllStop(maydayFor, maydayReason) }
+		if maydayResume {
+			run = func() error { return runAllStopResume(maydayReason) }
+		}
+		if err := run(); err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		// Config not required for basic error viewing
@@ -163,12 +192,23 @@ func showSnapshots(cfg *config.Config) {
 		}
 
 		ui.Green.Printf("  %s\n", snap.Name)
-		fmt.Printf("    Created: %s\n", snap.Created.Format("2006-01-02 15:04:05"))
+		fmt.Printf("    Created: %s\n", timezone.Format(snap.Created, timezone.DisplayFormatSeconds))
 		fmt.Printf("    Files: %d\n", snap.FileCount)
+		if snap.Pinned {
+			ui.Cyan.Printf("    Pinned: %s\n", pinNote(snap.PinNote))
+		}
 		fmt.Println()
 	}
 }
 
+// pinNote returns a snapshot pin's note for display.
+func pinNote(note string) string {
+	if note == "" {
+		return "yes"
+	}
+	return note
+}
+
 func doRollback(cfg *config.Config, target string) {
 	if cfg == nil {
 		ui.Error("Project root not found")
@@ -211,7 +251,7 @@ func doRollback(cfg *config.Config, target string) {
 	ui.Yellow.Printf("Rolling back to: %s\n", target)
 	fmt.Println()
 
-	if err := snapshot.Restore(cfg.ManifestDir, target); err != nil {
+	if err := snapshot.RestoreTo(cfg.ManifestDir, cfg.OutputDir(), target); err != nil {
 		ui.Error("Rollback failed: %v", err)
 		os.Exit(1)
 	}
@@ -220,7 +260,7 @@ func doRollback(cfg *config.Config, target string) {
 	fmt.Println()
 
 	// Show restored files
-	files, err := snapshot.GetRestoredFiles(cfg.ManifestDir)
+	files, err := snapshot.RestoredFilesIn(cfg.OutputDir())
 	if err != nil {
 		ui.Warning("Could not list restored files: %v", err)
 	} else if len(files) > 0 {
@@ -245,7 +285,7 @@ func promptForSnapshot(snapshots []snapshot.SnapshotInfo) string {
 
 	for i := 0; i < maxShow; i++ {
 		snap := snapshots[i]
-		fmt.Printf("  %d) %s (%s)\n", i+1, snap.Name, snap.Created.Format("2006-01-02 15:04:05"))
+		fmt.Printf("  %d) %s (%s)\n", i+1, snap.Name, timezone.Format(snap.Created, timezone.DisplayFormatSeconds))
 	}
 	fmt.Println()
 
@@ -327,17 +367,36 @@ var restoreCmd = &cobra.Command{
 	Long: `Restore infrastructure configs from a previous backup.
 
 Use 'bosun restore --list' to see available backups.
-Backups are created automatically by the reconcile command before each deployment.`,
+Backups are created automatically by the reconcile command before each deployment.
+They hold the core infrastructure configs plus every path a service manifest
+declares under backup:. With --stack, only that stack's paths are restored
+and only that stack is restarted. Encrypted backups (BOSUN_BACKUP_ENCRYPT)
+are decrypted with the SOPS age key.
+
+After restarting, restore waits for the services to settle and reports
+which came back healthy. It fails if any are crash-looping, unhealthy, or
+exited with an error.
+
+With --verify, the backup (newest if none is named) is restored into a
+throwaway directory instead of appdata, and its configs are parsed and
+checked with 'docker compose config'. Add --boot to also start each restored
+compose file as a temporary project on shifted ports, wait for it to become
+healthy, and tear it down. A DR readiness status is reported at the end:
+
+  READY       Backup restores, validates, and (with --boot) boots
+  DEGRADED    Restorable, but stale or only partially checked
+  NOT READY   Restoring this backup would fail`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runRestore,
 }
 
 // BackupInfo contains information about a backup.
 type BackupInfo struct {
-	Name    string
-	Path    string
-	HasTar  bool
-	ModTime string
+	Name      string
+	Path      string
+	HasTar    bool
+	Encrypted bool // The archive is age-encrypted
+	ModTime   string
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
@@ -348,11 +407,22 @@ func runRestore(cmd *cobra.Command, args []string) error {
 		return listBackups(backupDir)
 	}
 
+	if restoreVerify || restoreBoot {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return runRestoreVerify(backupDir, name)
+	}
+
 	if len(args) == 0 {
 		return fmt.Errorf("backup name required. Use --list to see available backups")
 	}
 
 	backupName := args[0]
+	if restoreStack != "" {
+		return doRestoreStack(backupDir, backupName, restoreStack)
+	}
 	return doRestore(backupDir, backupName)
 }
 
@@ -406,7 +476,12 @@ func listBackups(backupDir string) error {
 		ui.Green.Printf("  %s %s\n", statusIcon, backup.Name)
 		fmt.Printf("      Modified: %s\n", backup.ModTime)
 		if !backup.HasTar {
-			ui.Yellow.Printf("      Warning: configs.tar.gz missing\n")
+			ui.Yellow.Printf("      Warning: backup archive missing\n")
+		} else if backup.Encrypted {
+			fmt.Printf("      Encrypted: yes (age)\n")
+		}
+		if index, err := reconcile.ReadBackupIndex(backup.Path); err == nil {
+			fmt.Printf("      Stacks: %s\n", strings.Join(index.StackNames(), ", "))
 		}
 	}
 
@@ -436,17 +511,14 @@ func getBackups(backupDir string) ([]BackupInfo, error) {
 		}
 
 		backupPath := filepath.Join(backupDir, e.Name())
-		tarPath := filepath.Join(backupPath, "configs.tar.gz")
-		hasTar := false
-		if _, err := os.Stat(tarPath); err == nil {
-			hasTar = true
-		}
+		_, encrypted, hasTar := reconcile.BackupArchivePath(backupPath)
 
 		backups = append(backups, BackupInfo{
-			Name:    e.Name(),
-			Path:    backupPath,
-			HasTar:  hasTar,
-			ModTime: info.ModTime().Format("2006-01-02 15:04:05"),
+			Name:      e.Name(),
+			Path:      backupPath,
+			HasTar:    hasTar,
+			Encrypted: encrypted,
+			ModTime:   timezone.Format(info.ModTime(), timezone.DisplayFormatSeconds),
 		})
 	}
 
@@ -466,9 +538,8 @@ func doRestore(backupDir, backupName string) error {
 		return fmt.Errorf("backup not found: %s", backupName)
 	}
 
-	// Validate configs.tar.gz exists
-	tarPath := filepath.Join(backupPath, "configs.tar.gz")
-	if _, err := os.Stat(tarPath); os.IsNotExist(err) {
+	// Validate the archive exists, plain or encrypted
+	if _, _, ok := reconcile.BackupArchivePath(backupPath); !ok {
 		return fmt.Errorf("backup incomplete: configs.tar.gz not found in %s", backupName)
 	}
 
@@ -490,7 +561,7 @@ func doRestore(backupDir, backupName string) error {
 
 	// Extract backup to staging
 	ui.Info("  Extracting backup...")
-	if err := extractTarGz(tarPath, stagingDir); err != nil {
+	if err := extractBackupArchive(backupPath, stagingDir); err != nil {
 		return fmt.Errorf("failed to extract backup: %w", err)
 	}
 
@@ -534,7 +605,9 @@ func doRestore(backupDir, backupName string) error {
 		ui.Info("  Restarting services...")
 		if err := runComposeUp(composeFile); err != nil {
 			ui.Warning("Could not restart services: %v", err)
-			ui.Yellow.Println("  Run 'docker compose -f " + composeFile + " up -d' manually")
+			ui.Yellow.Println("  Run 'docker compose -p " + reconcile.ComposeProjectName(composeFile) + " -f " + composeFile + " up -d' manually")
+		} else if err := verifyRestoredHealth(composeFile); err != nil {
+			return err
 		}
 	}
 
@@ -562,14 +635,21 @@ func getAppdataDir() string {
 	return ""
 }
 
-func extractTarGz(tarPath, destDir string) error {
-	file, err := os.Open(tarPath)
+// extractBackupArchive extracts a backup's archive into destDir, decrypting
+// it first if it is encrypted.
+func extractBackupArchive(backupPath, destDir string) error {
+	archive, err := reconcile.OpenBackupArchive(backupPath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer archive.Close()
+	return extractTarGzFrom(archive, destDir)
+}
 
-	gzr, err := gzip.NewReader(file)
+// extractTarGzFrom extracts a tar.gz stream into destDir, rejecting paths
+// that escape it and archives over the extraction limits.
+func extractTarGzFrom(r io.Reader, destDir string) error {
+	gzr, err := gzip.NewReader(r)
 	if err != nil {
 		return err
 	}
@@ -682,18 +762,66 @@ func deployRestoredConfigs(stagingDir, targetDir string) error {
 	return fileutil.CopyDir(stagingDir, targetDir)
 }
 
+// runComposeUp brings a stack up as its own compose project, the way
+// reconciles deploy it.
 func runComposeUp(composeFile string) error {
-	cmd := exec.Command("docker", "compose", "-f", composeFile, "up", "-d", "--remove-orphans")
+	cmd := exec.Command("docker", "compose", "-p", reconcile.ComposeProjectName(composeFile), "-f", composeFile, "up", "-d", "--remove-orphans")
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// verifyRestoredHealth waits for a restored stack's services to settle and
+// reports which came back healthy. Returns an error naming any that did not.
+func verifyRestoredHealth(composeFile string) error {
+	ui.Info("  Checking service health...")
+	ctx, cancel := context.WithTimeout(context.Background(), reconcile.HealthWaitTimeout+30*time.Second)
+	defer cancel()
+
+	services, err := reconcile.NewDeployOps(false).WaitForHealth(ctx, composeFile, reconcile.HealthWaitTimeout)
+	if err != nil {
+		return fmt.Errorf("check service health: %w", err)
+	}
+	return reportServiceHealth(services)
+}
+
+// reportServiceHealth prints each service's state and returns an error
+// naming the unhealthy ones.
+func reportServiceHealth(services []reconcile.ServiceHealth) error {
+	var failing []string
+	for _, s := range services {
+		if s.Healthy() {
+			ui.Green.Printf("    ✓ %s: %s\n", s.Service, s.Status())
+		} else {
+			ui.Red.Printf("    ✗ %s: %s\n", s.Service, s.Status())
+			failing = append(failing, s.Service)
+		}
+	}
+	if len(failing) > 0 {
+		fmt.Println()
+		ui.Yellow.Println("  Configs were restored, but these services are not healthy.")
+		ui.Yellow.Println("  Check them with 'bosun logs <service>'.")
+		return fmt.Errorf("%d of %d restored service(s) unhealthy: %s", len(failing), len(services), strings.Join(failing, ", "))
+	}
+	return nil
+}
+
 func init() {
 	maydayCmd.Flags().BoolVarP(&maydayList, "list", "l", false, "List available snapshots")
 	maydayCmd.Flags().StringVarP(&maydayRollback, "rollback", "r", "", "Rollback to a snapshot (use 'interactive' for menu)")
+	maydayCmd.Flags().BoolVar(&maydayAllStop, "all-stop", false, "Pause all automation (polling, webhooks, scheduled tasks) for --for")
+	maydayCmd.Flags().DurationVar(&maydayFor, "for", DefaultAllStopWindow, "How long --all-stop lasts before automation resumes")
+	maydayCmd.Flags().StringVar(&maydayReason, "reason", "", "Why, for the audit log")
+	maydayCmd.Flags().BoolVar(&maydayResume, "resume", false, "Lift an all-stop early")
+	maydayCmd.Flags().StringVar(&maydayStateDir, "state-dir", "", "State directory (default: $BOSUN_STATE_DIR, $STATE_DIR, or /app/state)")
+	maydayCmd.MarkFlagsMutuallyExclusive("all-stop", "resume", "list", "rollback")
 
 	restoreCmd.Flags().BoolVarP(&restoreList, "list", "l", false, "List available backups")
+	restoreCmd.Flags().BoolVar(&restoreVerify, "verify", false, "Restore into a throwaway directory and report DR readiness")
+	restoreCmd.Flags().BoolVar(&restoreBoot, "boot", false, "With --verify, boot restored compose files on alternate ports")
+	restoreCmd.Flags().IntVar(&restorePortOffset, "port-offset", DefaultVerifyPortOffset, "Offset added to published ports with --boot")
+	restoreCmd.Flags().BoolVar(&restoreKeep, "keep", false, "With --verify, keep the throwaway directory")
+	restoreCmd.Flags().StringVar(&restoreStack, "stack", "", "Restore only this stack's configs and restart only it")
 
 	rootCmd.AddCommand(maydayCmd)
 	rootCmd.AddCommand(overboardCmd)
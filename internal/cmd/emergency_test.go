@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
 )
 
 func TestMaydayCmd_Help(t *testing.T) {
@@ -13,6 +15,8 @@ func TestMaydayCmd_Help(t *testing.T) {
 		assert.Contains(t, output, "recent errors")
 		assert.Contains(t, output, "--list")
 		assert.Contains(t, output, "--rollback")
+		assert.Contains(t, output, "--all-stop")
+		assert.Contains(t, output, "--resume")
 	})
 }
 
@@ -125,4 +129,37 @@ func TestRestoreCmd_Flags(t *testing.T) {
 		resetRootCmd(t)
 		assert.False(t, restoreList) // default value
 	})
+
+	t.Run("has verify flags", func(t *testing.T) {
+		resetRootCmd(t)
+		assert.False(t, restoreVerify)
+		assert.False(t, restoreBoot)
+		assert.False(t, restoreKeep)
+		assert.Equal(t, DefaultVerifyPortOffset, restorePortOffset)
+	})
+
+	t.Run("has stack flag", func(t *testing.T) {
+		resetRootCmd(t)
+		assert.Empty(t, restoreStack)
+	})
+}
+
+func TestReportServiceHealth(t *testing.T) {
+	t.Run("all healthy", func(t *testing.T) {
+		err := reportServiceHealth([]reconcile.ServiceHealth{
+			{Service: "web", State: "running", Health: "healthy"},
+			{Service: "migrate", State: "exited"},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("names failing services", func(t *testing.T) {
+		err := reportServiceHealth([]reconcile.ServiceHealth{
+			{Service: "web", State: "running", Health: "healthy"},
+			{Service: "db", State: "restarting", ExitCode: 1},
+			{Service: "api", State: "running", Health: "unhealthy"},
+		})
+		assert.ErrorContains(t, err, "2 of 3 restored service(s) unhealthy")
+		assert.ErrorContains(t, err, "db, api")
+	})
 }
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	execInteractive bool
+	execTTY         bool
+	execUser        string
+	execWorkdir     string
+	execEnv         []string
+)
+
+// execCmd runs a command in a managed container through the Docker API.
+var execCmd = &cobra.Command{
+	Use:     "exec <container|service> [--] [command...]",
+	Aliases: []string{"board"},
+	Short:   "Run a command in a running container",
+	Long: `Run a command in a running container, like docker exec. The target is a
+container name or a compose service, so you don't need to know the
+container_name a service was given. Without a command, an interactive sh
+is started.
+
+-i and -t are on by default when stdin and stdout are terminals, so
+'bosun exec sonarr' drops into a shell. Pass -t=false to run a one-liner
+without a TTY (separate stdout and stderr). bosun exits with the command's
+exit code. bosun's flags go before -- and the command after it, so a
+command with its own flags needs the --.
+
+Honours DOCKER_HOST, including ssh:// hosts.
+
+Examples:
+  bosun exec sonarr                          # Shell in the sonarr container
+  bosun exec postgres -- psql -U postgres    # Interactive psql
+  bosun exec traefik -t=false -- cat /etc/traefik/traefik.yml
+  bosun exec -u root -w /config sonarr -- ls -la`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExec,
+}
+
+func init() {
+	addExecFlags(execCmd)
+	rootCmd.AddCommand(execCmd)
+}
+
+// addExecFlags defines the exec flags on cmd.
+func addExecFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&execInteractive, "interactive", "i", false, "Keep stdin open (default: on when stdin is a terminal)")
+	cmd.Flags().BoolVarP(&execTTY, "tty", "t", false, "Allocate a pseudo-TTY (default: on when stdin and stdout are terminals)")
+	cmd.Flags().StringVarP(&execUser, "user", "u", "", "User to run as (name or uid[:gid])")
+	cmd.Flags().StringVarP(&execWorkdir, "workdir", "w", "", "Working directory inside the container")
+	cmd.Flags().StringArrayVarP(&execEnv, "env", "e", nil, "Set an environment variable (KEY=value, repeatable)")
+}
+
+// execRun runs the command; tests replace it to inspect the options.
+var execRun = execInContainer
+
+func runExec(cmd *cobra.Command, args []string) error {
+	// bosun flags go before --, the command and its flags after it:
+	// bosun exec -t=false db -- psql -U x
+	target, command := args[0], args[1:]
+	if dash := cmd.ArgsLenAtDash(); dash != -1 {
+		if dash != 1 {
+			return fmt.Errorf("name exactly one container or service before --")
+		}
+		command = args[dash:]
+	}
+	if len(command) == 0 {
+		command = []string{"sh"}
+	}
+
+	stdinTTY := term.IsTerminal(int(os.Stdin.Fd()))
+	interactive, tty := execInteractive, execTTY
+	if !cmd.Flags().Changed("interactive") {
+		interactive = stdinTTY
+	}
+	if !cmd.Flags().Changed("tty") {
+		tty = stdinTTY && term.IsTerminal(int(os.Stdout.Fd()))
+	}
+
+	code, err := execRun(target, docker.ExecOptions{
+		Cmd:        command,
+		User:       execUser,
+		WorkingDir: execWorkdir,
+		Env:        execEnv,
+		TTY:        tty,
+	}, interactive)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// execInContainer resolves target and runs the command in it, returning its
+// exit code. With a TTY the local terminal is put in raw mode for the
+// duration and its size follows the local one.
+func execInContainer(target string, opts docker.ExecOptions, interactive bool) (int, error) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	opts.Stdout, opts.Stderr = os.Stdout, os.Stderr
+	if interactive {
+		opts.Stdin = os.Stdin
+	}
+
+	var code int
+	err := withDockerClientContext(ctx, func(client *docker.Client) error {
+		containers, err := client.ListContainers(ctx, true)
+		if err != nil {
+			return fmt.Errorf("list containers: %w", err)
+		}
+		var stacks map[string]string
+		if cfg, err := config.Load(); err == nil {
+			stacks, _ = manifestServices(filepath.Join(cfg.OutputDir(), "compose"))
+		}
+
+		name, err := execTarget(containers, stacks, target)
+		if err != nil {
+			return err
+		}
+
+		if opts.TTY {
+			if size, ok := terminalSize(); ok {
+				opts.Size = size
+			}
+			opts.Resize = watchTerminalSize(ctx)
+
+			if interactive && term.IsTerminal(int(os.Stdin.Fd())) {
+				oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+				if err != nil {
+					return fmt.Errorf("set terminal to raw mode: %w", err)
+				}
+				defer func() { _ = term.Restore(int(os.Stdin.Fd()), oldState) }()
+			}
+		}
+
+		code, err = client.Exec(ctx, name, opts)
+		return err
+	})
+	return code, err
+}
+
+// execTarget returns the running container an exec target names: a
+// container, or a service with a single container. Stacks and scaled
+// services are ambiguous, so the error lists their containers.
+func execTarget(containers []docker.ContainerInfo, stacks map[string]string, target string) (string, error) {
+	names, kind := logTargets(containers, stacks, target)
+	switch {
+	case len(names) == 0:
+		return "", fmt.Errorf("no running container or service named %s", target)
+	case kind == "stack":
+		return "", fmt.Errorf("%s is a stack; name one of its containers: %s", target, strings.Join(names, ", "))
+	case len(names) > 1:
+		return "", fmt.Errorf("service %s has %d containers; name one: %s", target, len(names), strings.Join(names, ", "))
+	}
+	if kind == "service" && names[0] != target {
+		ui.Info("Service %s is container %s", target, names[0])
+	}
+	return names[0], nil
+}
+
+// terminalSize returns the size of the terminal on stdout.
+func terminalSize() (docker.TerminalSize, bool) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return docker.TerminalSize{}, false
+	}
+	return docker.TerminalSize{Height: uint(height), Width: uint(width)}, true
+}
+
+// watchTerminalSize sends the terminal size each time it changes, until ctx
+// is done.
+func watchTerminalSize(ctx context.Context) <-chan docker.TerminalSize {
+	sigs := make(chan os.Signal, 1)
+	notifyResize(sigs)
+
+	sizes := make(chan docker.TerminalSize)
+	go func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigs:
+				size, ok := terminalSize()
+				if !ok {
+					continue
+				}
+				select {
+				case sizes <- size:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return sizes
+}
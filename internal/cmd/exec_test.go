@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+func TestExecCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "exec", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "exec <container|service> [--] [command...]")
+}
+
+func TestExecCmd_Args(t *testing.T) {
+	var gotTarget string
+	var got docker.ExecOptions
+	orig := execRun
+	execRun = func(target string, opts docker.ExecOptions, interactive bool) (int, error) {
+		gotTarget, got = target, opts
+		return 0, nil
+	}
+	t.Cleanup(func() { execRun = orig })
+
+	// A fresh command per run, since other tests reset the shared one's flags
+	run := func(args ...string) error {
+		cmd := &cobra.Command{Use: execCmd.Use, Args: execCmd.Args, RunE: runExec, SilenceUsage: true}
+		addExecFlags(cmd)
+		cmd.SetArgs(args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		return cmd.Execute()
+	}
+
+	require.NoError(t, run("postgres", "--", "psql", "-U", "postgres"))
+	assert.Equal(t, "postgres", gotTarget)
+	assert.Equal(t, []string{"psql", "-U", "postgres"}, got.Cmd, "-- is not part of the command")
+	assert.False(t, got.TTY, "no TTY without a terminal")
+
+	require.NoError(t, run("traefik", "-t=false", "--", "cat", "/etc/traefik/traefik.yml"))
+	assert.Equal(t, "traefik", gotTarget)
+	assert.Equal(t, []string{"cat", "/etc/traefik/traefik.yml"}, got.Cmd, "-t=false is a bosun flag")
+	assert.False(t, got.TTY)
+
+	require.NoError(t, run("-t", "sonarr", "--", "ls"))
+	assert.Equal(t, []string{"ls"}, got.Cmd)
+	assert.True(t, got.TTY)
+
+	require.NoError(t, run("sonarr"))
+	assert.Equal(t, []string{"sh"}, got.Cmd)
+
+	assert.ErrorContains(t, run("a", "b", "--", "ls"), "exactly one")
+}
+
+func TestExecTarget(t *testing.T) {
+	compose := func(project, service string) map[string]string {
+		return map[string]string{"com.docker.compose.project": project, "com.docker.compose.service": service}
+	}
+	containers := []docker.ContainerInfo{
+		{Name: "sonarr", Labels: compose("media", "sonarr")},
+		{Name: "media-postgres", Labels: compose("media", "postgres")},
+		{Name: "media-db-2", Labels: compose("media", "db")},
+		{Name: "media-db-1", Labels: compose("media", "db")},
+	}
+
+	name, err := execTarget(containers, nil, "sonarr")
+	require.NoError(t, err)
+	assert.Equal(t, "sonarr", name)
+
+	name, err = execTarget(containers, nil, "postgres")
+	require.NoError(t, err)
+	assert.Equal(t, "media-postgres", name, "services resolve to their container")
+
+	_, err = execTarget(containers, nil, "db")
+	assert.ErrorContains(t, err, "service db has 2 containers; name one: media-db-1, media-db-2")
+
+	_, err = execTarget(containers, nil, "media")
+	assert.ErrorContains(t, err, "media is a stack")
+
+	_, err = execTarget(containers, nil, "plex")
+	assert.ErrorContains(t, err, "no running container or service named plex")
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package cmd
+
+import "os"
+
+// notifyResize is a no-op: Windows consoles don't signal resizes, so the
+// TTY keeps the size it started with.
+func notifyResize(ch chan<- os.Signal) {}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var exportOutput string
+
+// exportCmd converts manifests to other deploy targets.
+var exportCmd = &cobra.Command{
+	Use:     "export",
+	Aliases: []string{"offload"},
+	Short:   "Export manifests to other deploy targets",
+	Long: `Export commands convert service or stack manifests into formats for
+other platforms, to ease gradual migration off Docker Compose.
+
+Commands:
+  k8s       Kubernetes Deployment/Service/Ingress manifests`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var exportK8sCmd = &cobra.Command{
+	Use:   "k8s <service|stack>",
+	Short: "Export Kubernetes manifests (best-effort)",
+	Long: `Export a service or stack as Kubernetes Deployment, Service, and Ingress
+manifests, derived from the same manifests bosun provisions from.
+
+The conversion is best-effort: image, ports, environment, and Traefik host
+rules carry over; volumes, healthchecks, secrets, and depends_on do not.
+Unconverted keys are listed in the header of the generated file, and every
+object is annotated with bosun.io/export: best-effort. Review before applying.
+
+Examples:
+  bosun export k8s norish                  # Print one service to stdout
+  bosun export k8s apps -o apps.k8s.yml    # Write a whole stack to a file
+  bosun export k8s norish | kubectl apply --dry-run=client -f -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportK8s,
+}
+
+func init() {
+	exportK8sCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (stdout if not set)")
+
+	exportCmd.AddCommand(exportK8sCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportK8s(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	loadHostFacts(cmd.Context())
+	loadServicePorts(cfg.ServicesDir())
+
+	output, err := renderServiceOrStack(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := manifest.RenderK8s(output)
+	if err != nil {
+		return fmt.Errorf("export k8s: %w", err)
+	}
+	if data == nil {
+		return fmt.Errorf("%s has no services to export", args[0])
+	}
+
+	if exportOutput == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", exportOutput, err)
+	}
+	ui.Green.Printf("Exported %s to %s (best-effort, review before applying)\n", args[0], exportOutput)
+	return nil
+}
+
+// renderServiceOrStack renders a stack by name, falling back to a single service.
+func renderServiceOrStack(cfg *config.Config, name string) (*manifest.RenderOutput, error) {
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("invalid stack or service name: %s", name)
+	}
+
+	stackPath := filepath.Join(cfg.StacksDir(), name+".yml")
+	if _, err := os.Stat(stackPath); err == nil {
+		output, err := manifest.RenderStack(stackPath, cfg.ProvisionsDir(), cfg.ServicesDir(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("render stack: %w", err)
+		}
+		return output, nil
+	}
+
+	servicePath := filepath.Join(cfg.ServicesDir(), name+".yml")
+	if _, err := os.Stat(servicePath); err != nil {
+		return nil, fmt.Errorf("stack or service not found: %s", name)
+	}
+
+	svc, err := manifest.LoadServiceManifest(servicePath)
+	if err != nil {
+		return nil, fmt.Errorf("load service: %w", err)
+	}
+	output, err := manifest.RenderService(svc, cfg.ProvisionsDir())
+	if err != nil {
+		return nil, fmt.Errorf("render service: %w", err)
+	}
+	return output, nil
+}
@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/selector"
+)
+
+// Kinds of edge in a service graph.
+const (
+	graphEdgeDepends = "depends_on"
+	graphEdgeNetwork = "network"
+	graphEdgeVolume  = "volume"
+	graphEdgeRoute   = "route"
+)
+
+// graphTraefikNode is the node Traefik routes originate from.
+const graphTraefikNode = "traefik"
+
+var (
+	graphFormat string
+	graphStack  string
+)
+
+// graphCmd draws the service dependency graph.
+var graphCmd = &cobra.Command{
+	Use:     "graph",
+	Aliases: []string{"chart"},
+	Short:   "Draw the service dependency graph as DOT or Mermaid",
+	Long: `Build a graph of services from the rendered compose files and print it as
+Graphviz DOT or a Mermaid flowchart.
+
+Edges come from:
+  depends_on        solid arrow to the dependency
+  networks          dashed arrow to the network (the default network is omitted)
+  Traefik routing   arrow from traefik to each routed service, labeled with its hosts
+
+Services are grouped by stack. depends_on cycles (the same ones lint reports)
+are drawn in red.
+
+Coupling between stacks is drawn too: volumes shared by several stacks
+appear as cylinders, and sharing that breaks per-stack scoped deploys (a
+network or volume created by more than one stack, depends_on across stacks)
+is drawn in orange and listed as comments at the top.
+
+Examples:
+  bosun graph | dot -Tsvg > graph.svg   # Render with Graphviz
+  bosun graph --format mermaid          # Paste into a Markdown doc
+  bosun graph --stack media             # One stack only`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "Output format: dot or mermaid")
+	graphCmd.Flags().StringVar(&graphStack, "stack", "", "Only graph this stack")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	if graphFormat != "dot" && graphFormat != "mermaid" {
+		return fmt.Errorf("invalid format %q (want dot or mermaid)", graphFormat)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	pattern := "*.yml"
+	if graphStack != "" {
+		pattern = graphStack + ".yml"
+	}
+	files, _ := filepath.Glob(filepath.Join(composeDir, pattern))
+	if len(files) == 0 {
+		if graphStack != "" {
+			return fmt.Errorf("stack %s has no rendered compose file in %s (run 'bosun provision %s')", graphStack, composeDir, graphStack)
+		}
+		return fmt.Errorf("no rendered compose files in %s (run 'bosun provision')", composeDir)
+	}
+
+	g, err := buildServiceGraph(files)
+	if err != nil {
+		return err
+	}
+
+	// Coupling needs every stack, even when graphing one
+	all, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	couplings, err := analyzeStackCoupling(all)
+	if err != nil {
+		return err
+	}
+	if graphStack != "" {
+		couplings = slices.DeleteFunc(couplings, func(c stackCoupling) bool {
+			return !slices.Contains(c.stacks, graphStack)
+		})
+	}
+	g.addCouplings(couplings)
+	if graphFormat == "mermaid" {
+		fmt.Print(g.mermaid())
+	} else {
+		fmt.Print(g.dot())
+	}
+	return nil
+}
+
+// serviceGraph is the services of one or more stacks and the edges
+// between them, networks, and Traefik.
+type serviceGraph struct {
+	// stacks maps stack name to its services, sorted.
+	stacks map[string][]string
+	// networks are the networks services join, sorted.
+	networks []string
+	edges    []graphEdge
+	// cycles are depends_on cycles, e.g. "a -> b -> a".
+	cycles []string
+	// couplings are the networks, volumes, and dependencies shared between
+	// stacks.
+	couplings []stackCoupling
+	// volumes are the volumes shared between stacks, sorted.
+	volumes []string
+	// unintended are the network and volume nodes ("net:x", "vol:x") whose
+	// sharing breaks scoped deploys.
+	unintended map[string]bool
+}
+
+// graphEdge is a directed edge of a service graph.
+type graphEdge struct {
+	from, to string
+	kind     string
+	label    string
+	cycle    bool // Part of a depends_on cycle
+	coupling bool // A depends_on across stacks
+}
+
+// graphCompose is the part of a compose file the graph reads.
+type graphCompose struct {
+	Services map[string]struct {
+		DependsOn any `yaml:"depends_on"`
+		Networks  any `yaml:"networks"`
+		Labels    any `yaml:"labels"`
+	} `yaml:"services"`
+}
+
+// traefikHostPattern extracts hostnames from a Traefik rule like Host(`a.example.com`).
+var traefikHostPattern = regexp.MustCompile("Host\\(`([^`]+)`\\)")
+
+// buildServiceGraph reads rendered compose files, one stack per file.
+func buildServiceGraph(files []string) (*serviceGraph, error) {
+	g := &serviceGraph{stacks: make(map[string][]string)}
+	networks := make(map[string]bool)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		var compose graphCompose
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		services := make([]string, 0, len(compose.Services))
+		for name := range compose.Services {
+			services = append(services, name)
+		}
+		sort.Strings(services)
+		g.stacks[stack] = services
+
+		deps := make(map[string][]string)
+		for _, svc := range services {
+			cfg := compose.Services[svc]
+
+			deps[svc] = composeNames(cfg.DependsOn)
+			sort.Strings(deps[svc])
+			for _, dep := range deps[svc] {
+				g.edges = append(g.edges, graphEdge{from: svc, to: dep, kind: graphEdgeDepends})
+			}
+
+			nets := composeNames(cfg.Networks)
+			sort.Strings(nets)
+			for _, net := range nets {
+				if net == "default" {
+					continue
+				}
+				networks[net] = true
+				g.edges = append(g.edges, graphEdge{from: svc, to: net, kind: graphEdgeNetwork})
+			}
+
+			if hosts := traefikHosts(cfg.Labels); len(hosts) > 0 {
+				g.edges = append(g.edges, graphEdge{
+					from: graphTraefikNode, to: svc, kind: graphEdgeRoute,
+					label: strings.Join(hosts, ", "),
+				})
+			}
+		}
+		g.cycles = append(g.cycles, detectCycles(deps)...)
+	}
+
+	for net := range networks {
+		g.networks = append(g.networks, net)
+	}
+	sort.Strings(g.networks)
+	sort.Strings(g.cycles)
+	g.markCycles()
+	return g, nil
+}
+
+// addCouplings records stack couplings in the graph: shared volumes become
+// nodes, and unintended sharing is flagged on its nodes and edges.
+func (g *serviceGraph) addCouplings(couplings []stackCoupling) {
+	g.couplings = couplings
+	g.unintended = make(map[string]bool)
+	for _, c := range couplings {
+		switch c.kind {
+		case couplingNetwork:
+			if c.problem != "" {
+				for _, key := range c.keys {
+					g.unintended["net:"+key] = true
+				}
+			}
+		case couplingVolume:
+			g.volumes = append(g.volumes, c.name)
+			for _, svc := range c.services {
+				g.edges = append(g.edges, graphEdge{from: svc, to: c.name, kind: graphEdgeVolume})
+			}
+			if c.problem != "" {
+				g.unintended["vol:"+c.name] = true
+			}
+		case couplingDepends:
+			for i, e := range g.edges {
+				if e.kind == graphEdgeDepends && e.from == c.services[0] && e.to == c.services[1] {
+					g.edges[i].coupling = true
+				}
+			}
+		}
+	}
+	sort.Strings(g.volumes)
+}
+
+// traefikHosts returns the hosts routed to a service with Traefik enabled.
+func traefikHosts(labels any) []string {
+	l := selector.ComposeLabels(labels)
+	if l["traefik.enable"] != "true" {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var hosts []string
+	for key, value := range l {
+		if !strings.HasPrefix(key, "traefik.http.routers.") || !strings.HasSuffix(key, ".rule") {
+			continue
+		}
+		for _, m := range traefikHostPattern.FindAllStringSubmatch(value, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				hosts = append(hosts, m[1])
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// markCycles flags the depends_on edges that lie on a cycle.
+func (g *serviceGraph) markCycles() {
+	onCycle := make(map[[2]string]bool)
+	for _, cycle := range g.cycles {
+		nodes := strings.Split(cycle, " -> ")
+		for i := 0; i+1 < len(nodes); i++ {
+			onCycle[[2]string{nodes[i], nodes[i+1]}] = true
+		}
+	}
+	for i, e := range g.edges {
+		if e.kind == graphEdgeDepends && onCycle[[2]string{e.from, e.to}] {
+			g.edges[i].cycle = true
+		}
+	}
+}
+
+// sortedStacks returns the graph's stack names, sorted.
+func (g *serviceGraph) sortedStacks() []string {
+	stacks := make([]string, 0, len(g.stacks))
+	for name := range g.stacks {
+		stacks = append(stacks, name)
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+// dot renders the graph in Graphviz DOT.
+func (g *serviceGraph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph bosun {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, cycle := range g.cycles {
+		fmt.Fprintf(&b, "  // cycle: %s\n", cycle)
+	}
+	for _, c := range g.couplings {
+		if c.problem != "" {
+			fmt.Fprintf(&b, "  // unintended coupling: %s\n", c.problem)
+		} else {
+			fmt.Fprintf(&b, "  // coupling: %s\n", c)
+		}
+	}
+	for _, stack := range g.sortedStacks() {
+		fmt.Fprintf(&b, "  subgraph %q {\n    label=%q;\n", "cluster_"+stack, stack)
+		for _, svc := range g.stacks[stack] {
+			fmt.Fprintf(&b, "    %q;\n", svc)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, net := range g.networks {
+		fmt.Fprintf(&b, "  %q [label=%q, shape=ellipse, style=dashed%s];\n", "net:"+net, net, g.dotUnintended("net:"+net))
+	}
+	for _, vol := range g.volumes {
+		fmt.Fprintf(&b, "  %q [label=%q, shape=cylinder%s];\n", "vol:"+vol, vol, g.dotUnintended("vol:"+vol))
+	}
+	for _, e := range g.edges {
+		switch {
+		case e.kind == graphEdgeNetwork:
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed];\n", e.from, "net:"+e.to)
+		case e.kind == graphEdgeVolume:
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed];\n", e.from, "vol:"+e.to)
+		case e.kind == graphEdgeRoute:
+			fmt.Fprintf(&b, "  %q -> %q [label=%q, style=dotted];\n", e.from, e.to, e.label)
+		case e.cycle:
+			fmt.Fprintf(&b, "  %q -> %q [color=red, penwidth=2];\n", e.from, e.to)
+		case e.coupling:
+			fmt.Fprintf(&b, "  %q -> %q [color=orange, penwidth=2];\n", e.from, e.to)
+		default:
+			fmt.Fprintf(&b, "  %q -> %q;\n", e.from, e.to)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotUnintended returns the DOT attributes that flag a node whose sharing
+// breaks scoped deploys.
+func (g *serviceGraph) dotUnintended(node string) string {
+	if g.unintended[node] {
+		return ", color=orange, penwidth=2"
+	}
+	return ""
+}
+
+// mermaidIDPattern matches characters Mermaid doesn't allow in node IDs.
+var mermaidIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// mermaidID returns a Mermaid node ID for a graph node.
+func mermaidID(prefix, name string) string {
+	return prefix + "_" + mermaidIDPattern.ReplaceAllString(name, "_")
+}
+
+// mermaid renders the graph as a Mermaid flowchart.
+func (g *serviceGraph) mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, cycle := range g.cycles {
+		fmt.Fprintf(&b, "  %%%% cycle: %s\n", cycle)
+	}
+	for _, c := range g.couplings {
+		if c.problem != "" {
+			fmt.Fprintf(&b, "  %%%% unintended coupling: %s\n", c.problem)
+		} else {
+			fmt.Fprintf(&b, "  %%%% coupling: %s\n", c)
+		}
+	}
+	for _, stack := range g.sortedStacks() {
+		fmt.Fprintf(&b, "  subgraph %s [%s]\n", mermaidID("stack", stack), stack)
+		for _, svc := range g.stacks[stack] {
+			fmt.Fprintf(&b, "    %s[%s]\n", mermaidID("svc", svc), svc)
+		}
+		b.WriteString("  end\n")
+	}
+	for _, net := range g.networks {
+		fmt.Fprintf(&b, "  %s((%s))\n", mermaidID("net", net), net)
+	}
+	for _, vol := range g.volumes {
+		fmt.Fprintf(&b, "  %s[(%s)]\n", mermaidID("vol", vol), vol)
+	}
+	for _, net := range g.networks {
+		if g.unintended["net:"+net] {
+			fmt.Fprintf(&b, "  style %s stroke:orange,stroke-width:2px\n", mermaidID("net", net))
+		}
+	}
+	for _, vol := range g.volumes {
+		if g.unintended["vol:"+vol] {
+			fmt.Fprintf(&b, "  style %s stroke:orange,stroke-width:2px\n", mermaidID("vol", vol))
+		}
+	}
+
+	var redLinks, orangeLinks []string
+	for i, e := range g.edges {
+		from := mermaidID("svc", e.from)
+		switch e.kind {
+		case graphEdgeNetwork:
+			fmt.Fprintf(&b, "  %s -.-> %s\n", from, mermaidID("net", e.to))
+		case graphEdgeVolume:
+			fmt.Fprintf(&b, "  %s -.-> %s\n", from, mermaidID("vol", e.to))
+		case graphEdgeRoute:
+			fmt.Fprintf(&b, "  %s -->|%q| %s\n", from, e.label, mermaidID("svc", e.to))
+		default:
+			fmt.Fprintf(&b, "  %s --> %s\n", from, mermaidID("svc", e.to))
+		}
+		if e.cycle {
+			redLinks = append(redLinks, fmt.Sprint(i))
+		} else if e.coupling {
+			orangeLinks = append(orangeLinks, fmt.Sprint(i))
+		}
+	}
+	if len(redLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red,stroke-width:2px\n", strings.Join(redLinks, ","))
+	}
+	if len(orangeLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:orange,stroke-width:2px\n", strings.Join(orangeLinks, ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGraphFixture(t *testing.T) []string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"core.yml": `services:
+  traefik:
+    image: traefik:v3
+    networks: [proxynet]
+`,
+		"media.yml": `services:
+  sonarr:
+    image: lscr.io/linuxserver/sonarr
+    depends_on:
+      sonarr-db:
+        condition: service_healthy
+    networks: [proxynet, default]
+    labels:
+      - traefik.enable=true
+      - traefik.http.routers.sonarr.rule=Host(` + "`sonarr.example.com`" + `)
+  sonarr-db:
+    image: postgres:16
+`,
+		"loop.yml": `services:
+  a:
+    image: a
+    depends_on: [b]
+  b:
+    image: b
+    depends_on: [a]
+`,
+	}
+	var paths []string
+	for _, name := range []string{"core.yml", "loop.yml", "media.yml"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(files[name]), 0644))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestBuildServiceGraph(t *testing.T) {
+	g, err := buildServiceGraph(writeGraphFixture(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sonarr", "sonarr-db"}, g.stacks["media"])
+	assert.Equal(t, []string{"proxynet"}, g.networks, "the default network is omitted")
+	assert.Len(t, g.cycles, 1)
+
+	assert.Contains(t, g.edges, graphEdge{from: "sonarr", to: "sonarr-db", kind: graphEdgeDepends})
+	assert.Contains(t, g.edges, graphEdge{from: "sonarr", to: "proxynet", kind: graphEdgeNetwork})
+	assert.Contains(t, g.edges, graphEdge{from: "traefik", to: "sonarr", kind: graphEdgeRoute, label: "sonarr.example.com"})
+	assert.Contains(t, g.edges, graphEdge{from: "a", to: "b", kind: graphEdgeDepends, cycle: true})
+	assert.Contains(t, g.edges, graphEdge{from: "b", to: "a", kind: graphEdgeDepends, cycle: true})
+}
+
+func TestServiceGraph_Output(t *testing.T) {
+	g, err := buildServiceGraph(writeGraphFixture(t))
+	require.NoError(t, err)
+
+	t.Run("dot", func(t *testing.T) {
+		out := g.dot()
+		assert.Contains(t, out, `subgraph "cluster_media" {`)
+		assert.Contains(t, out, `"sonarr" -> "sonarr-db";`)
+		assert.Contains(t, out, `"sonarr" -> "net:proxynet" [style=dashed];`)
+		assert.Contains(t, out, `"traefik" -> "sonarr" [label="sonarr.example.com", style=dotted];`)
+		assert.Contains(t, out, `"a" -> "b" [color=red, penwidth=2];`)
+		assert.Contains(t, out, "// cycle: ")
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		out := g.mermaid()
+		assert.Contains(t, out, "subgraph stack_media [media]")
+		assert.Contains(t, out, "svc_sonarr --> svc_sonarr_db")
+		assert.Contains(t, out, "svc_sonarr -.-> net_proxynet")
+		assert.Contains(t, out, `svc_traefik -->|"sonarr.example.com"| svc_sonarr`)
+		assert.Contains(t, out, "linkStyle 1,2 stroke:red,stroke-width:2px")
+	})
+}
+
+func TestGraphCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "graph", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "mermaid")
+
+	_, err = executeCmd(t, "chart", "--help")
+	assert.NoError(t, err)
+}
@@ -337,7 +337,8 @@ services:
       TZ: ${TZ:-America/Chicago}
       WEBHOOK_SECRET: ${WEBHOOK_SECRET:-change-me}
     volumes:
-      - /var/run/docker.sock:/var/run/docker.sock:ro
+      # Rootless Docker: DOCKER_SOCKET=$XDG_RUNTIME_DIR/docker.sock
+      - ${DOCKER_SOCKET:-/var/run/docker.sock}:/var/run/docker.sock:ro
       - ${APPDATA:-./appdata}/bosun:/app/data
     ports:
       - "8080:8080"
@@ -575,6 +576,11 @@ BOSUN_REPO_URL=https://github.com/your-org/your-infra-repo.git
 # Optional: Discord webhook for notifications
 # DISCORD_WEBHOOK_URL=https://discord.com/api/webhooks/...
 
+# Optional: Slack, ntfy, or generic JSON webhook notifications
+# SLACK_WEBHOOK_URL=https://hooks.slack.com/services/...
+# NTFY_TOPIC=homelab-bosun
+# ALERT_WEBHOOK_URL=https://hooks.example.com/bosun
+
 # Optional: Disable HTTP server (socket-only mode)
 # BOSUN_DISABLE_HTTP=true
 `
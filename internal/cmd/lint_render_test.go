@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
+)
+
+func TestRenderStacksForLint(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestDir := filepath.Join(tmpDir, "manifest")
+	for _, dir := range []string{"provisions", "services", "stacks"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(manifestDir, dir), 0755))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "provisions", "container.yml"), []byte(`compose:
+  services:
+    ${name}:
+      image: ${image}
+      container_name: ${name}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "services", "sonarr.yml"),
+		[]byte("name: sonarr\nprovisions: [container]\nconfig:\n  image: lscr.io/linuxserver/sonarr:4.0.1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "stacks", "media.yml"), []byte("include:\n  - sonarr.yml\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "stacks", "broken.yml"), []byte("include:\n  - missing.yml\n"), 0644))
+
+	cfg := &config.Config{ManifestDir: manifestDir}
+	outDir := t.TempDir()
+
+	failures := renderStacksForLint(cfg, outDir)
+	assert.Equal(t, 1, failures)
+
+	data, err := os.ReadFile(filepath.Join(outDir, "media.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "image: lscr.io/linuxserver/sonarr:4.0.1")
+	assert.NoFileExists(t, filepath.Join(outDir, "broken.yml"))
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// Lint severities. Errors fail lint, warnings fail it with --strict, and
+// info findings are only reported.
+const (
+	lintSeverityError = "error"
+	lintSeverityWarn  = "warn"
+	lintSeverityInfo  = "info"
+)
+
+// Exit codes of bosun lint, so CI can tell failing manifests from a lint
+// that could not run.
+const (
+	lintExitOK       = 0
+	lintExitFindings = 1 // Errors, or warnings with --strict
+	lintExitFailed   = 2 // No config, no manifest directory, or a bad lint: section
+)
+
+// lintChecks are the lint checks by name, as used in the lint: severity
+// section of the project config, with their default severities.
+var lintChecks = map[string]string{
+	"provisions":         lintSeverityError, // Provisions directory exists
+	"schema":             lintSeverityError, // Service and stack manifests match the schema
+	"variables":          lintSeverityError, // Every ${var} is defined
+	"provision-versions": lintSeverityWarn,  // Pinned provisions are current
+	"render":             lintSeverityError, // Every stack renders
+	"dependencies":       lintSeverityWarn,  // depends_on names known services
+	"ports":              lintSeverityError, // No port conflicts
+	"cycles":             lintSeverityError, // No depends_on cycles
+	"coupling":           lintSeverityWarn,  // No sharing between stacks
+}
+
+// lintReport tallies lint findings by severity.
+type lintReport struct {
+	severity map[string]string
+	strict   bool
+
+	errors, warnings, infos int
+}
+
+// newLintReport returns a report using the default severities with
+// overrides applied. With strict, warnings count as errors.
+func newLintReport(overrides map[string]string, strict bool) (*lintReport, error) {
+	r := &lintReport{severity: make(map[string]string, len(lintChecks)), strict: strict}
+	for check, severity := range lintChecks {
+		r.severity[check] = severity
+	}
+
+	for check, severity := range overrides {
+		if _, ok := lintChecks[check]; !ok {
+			return nil, fmt.Errorf("unknown lint check %q (checks: %s)", check, strings.Join(lintCheckNames(), ", "))
+		}
+		switch severity {
+		case lintSeverityError, lintSeverityWarn, lintSeverityInfo:
+			r.severity[check] = severity
+		default:
+			return nil, fmt.Errorf("lint check %s: unknown severity %q (use error, warn, or info)", check, severity)
+		}
+	}
+	return r, nil
+}
+
+// lintCheckNames returns the lint check names, sorted.
+func lintCheckNames() []string {
+	names := make([]string, 0, len(lintChecks))
+	for name := range lintChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// effective returns the severity findings of check count as.
+func (r *lintReport) effective(check string) string {
+	severity := r.severity[check]
+	if r.strict && severity == lintSeverityWarn {
+		return lintSeverityError
+	}
+	return severity
+}
+
+// heading prints a check's section heading, noting the severity when the
+// config or --strict changed it from the default.
+func (r *lintReport) heading(check, title string) {
+	fmt.Println()
+	if severity := r.effective(check); severity != lintChecks[check] {
+		fmt.Printf("%s (%s):\n", title, severity)
+		return
+	}
+	fmt.Printf("%s:\n", title)
+}
+
+// add records a check's findings at the check's severity.
+func (r *lintReport) add(check string, findings int) {
+	switch r.effective(check) {
+	case lintSeverityError:
+		r.errors += findings
+	case lintSeverityWarn:
+		r.warnings += findings
+	default:
+		r.infos += findings
+	}
+}
+
+// exitCode returns the exit code for the findings.
+func (r *lintReport) exitCode() int {
+	if r.errors > 0 {
+		return lintExitFindings
+	}
+	return lintExitOK
+}
+
+// printSummary prints the findings by severity.
+func (r *lintReport) printSummary() {
+	fmt.Println()
+	switch {
+	case r.errors > 0:
+		ui.Red.Printf("Found %d error(s), %d warning(s). Fix before deploying.\n", r.errors, r.warnings)
+	case r.warnings > 0:
+		ui.Yellow.Printf("* All manifests valid, with %d warning(s)\n", r.warnings)
+	default:
+		ui.Green.Println("* All manifests valid!")
+	}
+	if r.infos > 0 {
+		fmt.Printf("  %d info finding(s)\n", r.infos)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintReport(t *testing.T) {
+	r, err := newLintReport(nil, false)
+	require.NoError(t, err)
+	r.add("ports", 2)
+	r.add("coupling", 1)
+	assert.Equal(t, 2, r.errors)
+	assert.Equal(t, 1, r.warnings)
+	assert.Equal(t, lintExitFindings, r.exitCode())
+
+	r, err = newLintReport(map[string]string{"ports": "warn", "dependencies": "info"}, false)
+	require.NoError(t, err)
+	r.add("ports", 2)
+	r.add("dependencies", 3)
+	assert.Equal(t, 0, r.errors)
+	assert.Equal(t, 2, r.warnings)
+	assert.Equal(t, 3, r.infos)
+	assert.Equal(t, lintExitOK, r.exitCode(), "warnings pass without --strict")
+
+	r, err = newLintReport(map[string]string{"ports": "warn", "dependencies": "info"}, true)
+	require.NoError(t, err)
+	r.add("ports", 2)
+	r.add("dependencies", 3)
+	assert.Equal(t, 2, r.errors, "--strict promotes warnings")
+	assert.Equal(t, 3, r.infos, "but not info findings")
+	assert.Equal(t, lintExitFindings, r.exitCode())
+}
+
+func TestNewLintReport_InvalidConfig(t *testing.T) {
+	_, err := newLintReport(map[string]string{"port": "warn"}, false)
+	assert.ErrorContains(t, err, `unknown lint check "port"`)
+
+	_, err = newLintReport(map[string]string{"ports": "fatal"}, false)
+	assert.ErrorContains(t, err, `unknown severity "fatal"`)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/timezone"
+)
+
+var (
+	logSummary  bool
+	logStateDir string
+)
+
+// deploySummary formats the last deploy and the drift status as one line,
+// e.g. "last deploy 3 hours ago by webhook at abc1234, drift: none".
+func deploySummary(st *state.State, drift string, now time.Time) string {
+	last, ok := st.LastDeploy()
+	if !ok {
+		return "no deploys recorded, drift: " + drift
+	}
+	line := "last deploy " + timezone.Ago(last.At, now)
+	if last.Source != "" {
+		line += " by " + last.Source
+	}
+	if last.Commit != "" {
+		line += " at " + shortCommit(last.Commit)
+	}
+	return line + ", drift: " + drift
+}
+
+// driftStatus summarizes drift between the rendered stacks and the running
+// containers: "none", a count of findings, or "unknown" without Docker.
+func driftStatus(cfg *config.Config) string {
+	var report *driftReport
+	err := withDockerClient(func(ctx context.Context, client *docker.Client) error {
+		containers, err := client.ListContainers(ctx, false)
+		if err != nil {
+			return err
+		}
+		report = buildDriftReport(cfg, containers, nil)
+		return nil
+	})
+	if err != nil {
+		return "unknown"
+	}
+	return formatDriftStatus(report)
+}
+
+// formatDriftStatus counts a drift report's findings.
+func formatDriftStatus(report *driftReport) string {
+	if !report.Drift {
+		return "none"
+	}
+	s := report.Summary
+	n := s.ImageMismatches + s.NotRunning + s.Orphans + s.PortDrift
+	if n == 1 {
+		return "1 finding"
+	}
+	return fmt.Sprintf("%d findings", n)
+}
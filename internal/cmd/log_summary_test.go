@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestDeploySummary(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("no deploys", func(t *testing.T) {
+		assert.Equal(t, "no deploys recorded, drift: unknown", deploySummary(&state.State{}, "unknown", now))
+	})
+
+	t.Run("last deploy", func(t *testing.T) {
+		st := &state.State{}
+		st.RecordDeploy(state.Deploy{At: now.Add(-2 * 24 * time.Hour), Source: "poll", Commit: "0123456789abcdef"})
+		st.RecordDeploy(state.Deploy{At: now.Add(-3 * time.Hour), Source: "webhook", Commit: "abc1234def5678"})
+
+		assert.Equal(t, "last deploy 3 hours ago by webhook at abc1234, drift: none", deploySummary(st, "none", now))
+	})
+}
+
+func TestFormatDriftStatus(t *testing.T) {
+	assert.Equal(t, "none", formatDriftStatus(&driftReport{}))
+	assert.Equal(t, "1 finding", formatDriftStatus(&driftReport{Drift: true, Summary: driftSummary{Orphans: 1}}))
+	assert.Equal(t, "3 findings", formatDriftStatus(&driftReport{Drift: true, Summary: driftSummary{ImageMismatches: 1, NotRunning: 2}}))
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/selector"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	logsFollow     bool
+	logsTail       int
+	logsSince      string
+	logsTimestamps bool
+)
+
+// logsCmd streams container logs for a container, service, or stack.
+var logsCmd = &cobra.Command{
+	Use:     "logs <container|service|stack>",
+	Aliases: []string{"scuttlebutt"},
+	Short:   "Stream logs from a container, service, or stack",
+	Long: `Stream container logs. The target is a container name, a compose service,
+or a stack; a service or stack with several containers has each line
+prefixed with its container name, and followed streams are interleaved as
+lines arrive.
+
+--since takes a duration (10m, 2h) or a time (2024-01-15T08:30:00, or
+2024-01-15), read in BOSUN_TIMEZONE.
+
+Examples:
+  bosun logs traefik -f --tail 200 --since 10m
+  bosun logs media -f                  # Every container in the media stack
+  bosun logs sonarr -t --since 2024-01-15`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogs,
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow log output")
+	logsCmd.Flags().IntVarP(&logsTail, "tail", "n", DefaultLogTailLines, "Number of lines to show from the end of each log (0 for all)")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only show logs since a duration ago (10m) or a time (2024-01-15T08:30:00)")
+	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "Show timestamps")
+
+	rootCmd.AddCommand(logsCmd)
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	opts := docker.LogOptions{Tail: logsTail, Follow: logsFollow, Timestamps: logsTimestamps}
+	if logsSince != "" {
+		since, err := parseLogsSince(logsSince, time.Now(), timezone.Location())
+		if err != nil {
+			return err
+		}
+		opts.Since = since
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	return withDockerClientContext(ctx, func(client *docker.Client) error {
+		containers, err := client.ListContainers(ctx, true)
+		if err != nil {
+			return fmt.Errorf("list containers: %w", err)
+		}
+		var stacks map[string]string
+		if cfg, err := config.Load(); err == nil {
+			stacks, _ = manifestServices(filepath.Join(cfg.OutputDir(), "compose"))
+		}
+
+		names, kind := logTargets(containers, stacks, args[0])
+		switch {
+		case len(names) == 0:
+			return fmt.Errorf("no running container, service, or stack named %s", args[0])
+		case len(names) == 1 && kind == "container":
+			return streamLogs(ctx, client, names[0], opts, os.Stdout, os.Stderr)
+		}
+		ui.Info("Streaming %d containers of %s %s", len(names), kind, args[0])
+		return streamContainerLogs(ctx, client, names, opts)
+	})
+}
+
+// logTargets returns the running containers a logs target names, and
+// whether it named a "container", "service", or "stack". A container name
+// wins over a service, and a service over a stack.
+func logTargets(containers []docker.ContainerInfo, stacks map[string]string, target string) ([]string, string) {
+	for _, c := range containers {
+		if c.Name == target {
+			return []string{c.Name}, "container"
+		}
+	}
+	sorted := matchContainers(containers, stacks, selector.Selector{})
+	for _, by := range []struct{ kind, label string }{
+		{"service", selector.ServiceLabel},
+		{"stack", selector.StackLabel},
+	} {
+		var names []string
+		for _, c := range sorted {
+			if containerLabels(c, stacks)[by.label] == target {
+				names = append(names, c.Name)
+			}
+		}
+		if len(names) > 0 {
+			return names, by.kind
+		}
+	}
+	return nil, ""
+}
+
+// parseLogsSince parses --since: a duration before now, or a time in loc.
+func parseLogsSince(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid --since %q: duration must be positive", s)
+		}
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want a duration (10m) or a time (2024-01-15T08:30:00)", s)
+}
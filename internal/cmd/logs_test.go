@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/timezone"
+)
+
+func TestLogTargets(t *testing.T) {
+	compose := func(project, service string) map[string]string {
+		return map[string]string{"com.docker.compose.project": project, "com.docker.compose.service": service}
+	}
+	containers := []docker.ContainerInfo{
+		{Name: "sonarr", Labels: compose("media", "sonarr")},
+		{Name: "media-db-2", Labels: compose("media", "db")},
+		{Name: "media-db-1", Labels: compose("media", "db")},
+		{Name: "traefik", Labels: compose("core", "traefik")},
+	}
+
+	tests := []struct {
+		target    string
+		wantNames []string
+		wantKind  string
+	}{
+		{"traefik", []string{"traefik"}, "container"},
+		{"db", []string{"media-db-1", "media-db-2"}, "service"},
+		{"media", []string{"media-db-1", "media-db-2", "sonarr"}, "stack"},
+		{"plex", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			names, kind := logTargets(containers, nil, tt.target)
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantKind, kind)
+		})
+	}
+}
+
+func TestParseLogsSince(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	chicago, err := timezone.Load("America/Chicago")
+	require.NoError(t, err)
+
+	t.Run("duration", func(t *testing.T) {
+		since, err := parseLogsSince("10m", now, chicago)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-10*time.Minute), since)
+	})
+
+	t.Run("time in the display timezone", func(t *testing.T) {
+		since, err := parseLogsSince("2026-01-15T08:30:00", now, chicago)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 1, 15, 14, 30, 0, 0, time.UTC), since.UTC())
+	})
+
+	t.Run("date", func(t *testing.T) {
+		since, err := parseLogsSince("2026-01-14", now, time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC), since)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseLogsSince("yesterday", now, time.UTC)
+		assert.Error(t, err)
+		_, err = parseLogsSince("-5m", now, time.UTC)
+		assert.Error(t, err)
+	})
+}
+
+func TestLogsCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "logs", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "--since takes a duration")
+
+	_, err = executeCmd(t, "scuttlebutt", "--help")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	maintenanceRemote  string
+	maintenanceURL     string
+	maintenanceAppdata string
+)
+
+// maintenanceCmd flips the Traefik maintenance page on or off.
+var maintenanceCmd = &cobra.Command{
+	Use:       "maintenance <on|off|status>",
+	Aliases:   []string{"drydock"},
+	Short:     "Show a maintenance page instead of services",
+	ValidArgs: []string{"on", "off", "status"},
+	Long: `Turn the maintenance page on or off, or show whether it is up.
+
+While on, a catch-all Traefik router with the highest priority sends every
+request to the maintenance page, so users see a friendly page instead of 502s
+while services are down. The router is added to traefik/dynamic.yml in
+appdata, which Traefik's file provider reloads without a restart.
+
+The page is served at /maintenance by the bosun daemon (BOSUN_MAINTENANCE_HTML
+replaces the built-in page). Point --url at another backend if Traefik cannot
+reach the daemon.
+
+Reconciles do this on their own with BOSUN_MAINTENANCE_PAGE=true: the page
+goes up with the Traefik sync and comes down once services pass the health
+gate. If they don't, it stays up until 'bosun maintenance off'.
+
+Examples:
+  bosun maintenance on                      # Before a big manual rollout
+  bosun maintenance off                     # Back to normal routing
+  bosun maintenance status --remote root@tower`,
+	Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: runMaintenance,
+}
+
+func init() {
+	maintenanceCmd.Flags().StringVar(&maintenanceRemote, "remote", "", "Change the maintenance page on a remote host over SSH (e.g., root@192.168.1.8)")
+	maintenanceCmd.Flags().StringVar(&maintenanceURL, "url", os.Getenv("BOSUN_MAINTENANCE_URL"), "Backend serving the maintenance page (default: $BOSUN_MAINTENANCE_URL or "+reconcile.DefaultMaintenanceURL+")")
+	maintenanceCmd.Flags().StringVar(&maintenanceAppdata, "appdata", "", "Appdata root holding traefik/dynamic.yml (default: $LOCAL_APPDATA or $REMOTE_APPDATA)")
+
+	rootCmd.AddCommand(maintenanceCmd)
+}
+
+func runMaintenance(cmd *cobra.Command, args []string) error {
+	path := filepath.Join(appdataRoot(maintenanceAppdata, maintenanceRemote), reconcile.TraefikDynamicFile)
+	deploy := reconcile.NewDeployOps(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcile.RemoteDeployTimeout)
+	defer cancel()
+
+	switch args[0] {
+	case "status":
+		on, err := deploy.ReadMaintenance(ctx, maintenanceRemote, path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		if on {
+			ui.Yellow.Printf("Maintenance page is on (%s)\n", path)
+		} else {
+			ui.Green.Printf("Maintenance page is off (%s)\n", path)
+		}
+		return nil
+	case "on":
+		if err := deploy.SetMaintenance(ctx, maintenanceRemote, path, maintenanceURL, true); err != nil {
+			return fmt.Errorf("enable maintenance page: %w", err)
+		}
+		ui.Success("Maintenance page is on")
+	case "off":
+		if err := deploy.SetMaintenance(ctx, maintenanceRemote, path, "", false); err != nil {
+			return fmt.Errorf("disable maintenance page: %w", err)
+		}
+		ui.Success("Maintenance page is off")
+	}
+	return nil
+}
+
+// appdataRoot returns the appdata root on the target: override (the
+// --appdata flag) if set, else REMOTE_APPDATA when targeting a remote host
+// or LOCAL_APPDATA otherwise, else the reconcile default.
+func appdataRoot(override, remote string) string {
+	if override != "" {
+		return override
+	}
+	defaults := reconcile.DefaultConfig()
+	if remote != "" {
+		if path := os.Getenv("REMOTE_APPDATA"); path != "" {
+			return path
+		}
+		return defaults.RemoteAppdataPath
+	}
+	if local := os.Getenv("LOCAL_APPDATA"); local != "" {
+		return local
+	}
+	return defaults.LocalAppdataPath
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "maintenance", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "maintenance page")
+	assert.Contains(t, output, "--remote")
+}
+
+func TestMaintenanceCmd_Aliases(t *testing.T) {
+	_, err := executeCmd(t, "drydock", "--help")
+	assert.NoError(t, err)
+}
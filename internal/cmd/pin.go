@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// stackNameRegex matches compose stack names (the basename of unraid/compose/<stack>.yml).
+var stackNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var pinStateDir string
+
+// pinCmd pins a stack to a git ref.
+var pinCmd = &cobra.Command{
+	Use:     "pin <stack> <ref>",
+	Aliases: []string{"anchor"},
+	Short:   "Pin a stack to a git commit or tag",
+	Long: `Pin a stack to a specific git commit, tag, or branch.
+
+Reconciles keep deploying the stack's compose file as it was at the pinned
+ref, even as the tracked branch advances. Everything else keeps flowing.
+
+Pins are recorded in the reconciler's state directory, so run this where
+the daemon runs (e.g. docker exec bosun bosun pin ...).
+
+Examples:
+  bosun pin media v1.4.2           # Pin the media stack to a tag
+  bosun pin media 3f9c2e1          # Pin to a commit
+  bosun unpin media                # Resume tracking the branch`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPin,
+}
+
+// unpinCmd removes a stack pin.
+var unpinCmd = &cobra.Command{
+	Use:     "unpin <stack>",
+	Aliases: []string{"weigh"},
+	Short:   "Remove a stack pin",
+	Long:    `Remove a stack pin so the stack tracks the branch again on the next reconcile.`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runUnpin,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{pinCmd, unpinCmd} {
+		c.Flags().StringVar(&pinStateDir, "state-dir", "", "State directory (default: $BOSUN_STATE_DIR, $STATE_DIR, or /app/state)")
+		rootCmd.AddCommand(c)
+	}
+}
+
+// stateStore returns the state store, honouring the same environment
+// variables as the daemon and reconcile commands.
+func stateStore(dir string) *state.Store {
+	return state.NewStore(reconcile.ResolveStateDir(dir))
+}
+
+func runPin(cmd *cobra.Command, args []string) error {
+	stack, ref := args[0], args[1]
+
+	if !stackNameRegex.MatchString(stack) {
+		return fmt.Errorf("invalid stack name: %s", stack)
+	}
+	if err := reconcile.ValidateRef(ref); err != nil {
+		return err
+	}
+
+	err := stateStore(pinStateDir).Update(func(st *state.State) error {
+		if prev, ok := st.Pins[stack]; ok {
+			ui.Info("Replacing existing pin %s -> %s", stack, prev.Ref)
+		}
+		st.SetPin(stack, ref, time.Now())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ui.Success("Stack %s pinned to %s", stack, ref)
+	ui.Info("Takes effect on the next reconcile")
+	return nil
+}
+
+func runUnpin(cmd *cobra.Command, args []string) error {
+	stack := args[0]
+
+	err := stateStore(pinStateDir).Update(func(st *state.State) error {
+		if !st.RemovePin(stack) {
+			return fmt.Errorf("stack %s is not pinned", stack)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ui.Success("Stack %s unpinned", stack)
+	return nil
+}
+
+// showPinnedStacks prints the pinned stacks section of the status dashboard.
+// Prints nothing if no stacks are pinned.
+func showPinnedStacks() {
+	st, err := stateStore("").Load()
+	if err != nil || len(st.Pins) == 0 {
+		return
+	}
+
+	fmt.Println()
+	ui.Blue.Println("--- Pinned Stacks ---")
+	for _, stack := range st.PinnedStacks() {
+		pin := st.Pins[stack]
+		ui.Yellow.Printf("  ! %s @ %s (since %s)\n", stack, pin.Ref, timezone.Display(pin.PinnedAt))
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestPinCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "pin", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "pin <stack> <ref>")
+}
+
+func TestPinCmd_PinAndUnpin(t *testing.T) {
+	pinStateDir = t.TempDir()
+	defer func() { pinStateDir = "" }()
+
+	require.NoError(t, runPin(pinCmd, []string{"media", "v1.4.2"}))
+
+	st, err := state.NewStore(pinStateDir).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.2", st.Pins["media"].Ref)
+
+	require.NoError(t, runUnpin(unpinCmd, []string{"media"}))
+
+	st, err = state.NewStore(pinStateDir).Load()
+	require.NoError(t, err)
+	assert.Empty(t, st.Pins)
+
+	assert.Error(t, runUnpin(unpinCmd, []string{"media"}), "unpinning twice should fail")
+}
+
+func TestPinCmd_Validation(t *testing.T) {
+	pinStateDir = t.TempDir()
+	defer func() { pinStateDir = "" }()
+
+	assert.Error(t, runPin(pinCmd, []string{"../etc", "v1"}))
+	assert.Error(t, runPin(pinCmd, []string{"media", "--upload-pack=x"}))
+	assert.Error(t, runPin(pinCmd, []string{"media", "v1;rm -rf /"}))
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+// Sources of a port claim.
+const (
+	portSourceCompose  = "compose"  // published in a rendered compose file
+	portSourceTraefik  = "traefik"  // Traefik load balancer port label
+	portSourceManifest = "manifest" // config.port of a service manifest
+)
+
+// portClaim is a host port claimed by a service.
+type portClaim struct {
+	Port    int    `json:"port"`
+	Service string `json:"service"`
+	Stack   string `json:"stack,omitempty"` // Empty for manifest claims
+	Source  string `json:"source"`
+}
+
+// Owner describes who claims the port, e.g. "traefik@core" or "wiki (manifest)".
+func (c portClaim) Owner() string {
+	switch c.Source {
+	case portSourceTraefik:
+		return c.Service + " (traefik)@" + c.Stack
+	case portSourceManifest:
+		return c.Service + " (manifest)"
+	}
+	return c.Service + "@" + c.Stack
+}
+
+// portRegistry is every port claimed by rendered compose files and service
+// manifests. It is persisted to .bosun/ports.json so lint and drift can
+// consult it without re-reading every manifest.
+type portRegistry struct {
+	Updated time.Time   `json:"updated"`
+	Claims  []portClaim `json:"claims"`
+}
+
+// portRegistryPath returns where the registry is persisted.
+func portRegistryPath(cfg *config.Config) string {
+	return filepath.Join(cfg.ManifestDir, ".bosun", "ports.json")
+}
+
+// buildPortRegistry reads port claims from rendered compose files, then from
+// service manifests for services that haven't been provisioned yet. Claims
+// are sorted by port; compose claims come before manifest claims.
+func buildPortRegistry(cfg *config.Config) *portRegistry {
+	registry := &portRegistry{Updated: time.Now().UTC()}
+	rendered := make(map[string]bool)
+
+	composeFiles, _ := filepath.Glob(filepath.Join(cfg.OutputDir(), "compose", "*.yml"))
+	for _, composeFile := range composeFiles {
+		for _, claim := range extractPortClaims(composeFile) {
+			rendered[claim.Service] = true
+			registry.Claims = append(registry.Claims, claim)
+		}
+	}
+
+	serviceFiles, _ := filepath.Glob(filepath.Join(cfg.ServicesDir(), "*.yml"))
+	for _, serviceFile := range serviceFiles {
+		svc, err := manifest.LoadServiceManifest(serviceFile)
+		if err != nil || rendered[svc.Name] {
+			continue
+		}
+		port, ok := svc.Config["port"].(int)
+		if !ok || port <= 0 {
+			continue
+		}
+		registry.Claims = append(registry.Claims, portClaim{Port: port, Service: svc.Name, Source: portSourceManifest})
+	}
+
+	sort.SliceStable(registry.Claims, func(i, j int) bool {
+		return registry.Claims[i].Port < registry.Claims[j].Port
+	})
+	return registry
+}
+
+// extractPortClaims returns the ports each service in a compose file
+// publishes or routes through Traefik. The stack is the file's base name.
+func extractPortClaims(filename string) []portClaim {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	var compose ComposeFileWithPorts
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil
+	}
+
+	stack := strings.TrimSuffix(filepath.Base(filename), ".yml")
+	var claims []portClaim
+	for serviceName, service := range compose.Services {
+		seen := make(map[int]bool)
+		for _, portEntry := range service.Ports {
+			for _, port := range parsePortEntry(portEntry) {
+				if !seen[port] {
+					seen[port] = true
+					claims = append(claims, portClaim{Port: port, Service: serviceName, Stack: stack, Source: portSourceCompose})
+				}
+			}
+		}
+
+		for labelKey, labelValue := range service.Labels {
+			if !strings.Contains(labelKey, "loadbalancer.server.port") {
+				continue
+			}
+			port, err := strconv.Atoi(labelValue)
+			if err == nil && port > 0 && !seen[port] {
+				seen[port] = true
+				claims = append(claims, portClaim{Port: port, Service: serviceName, Stack: stack, Source: portSourceTraefik})
+			}
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Port != claims[j].Port {
+			return claims[i].Port < claims[j].Port
+		}
+		return claims[i].Service < claims[j].Service
+	})
+	return claims
+}
+
+// Owners maps each claimed port to its first owner.
+func (r *portRegistry) Owners() map[int]string {
+	owners := make(map[int]string)
+	for _, c := range r.Claims {
+		if _, ok := owners[c.Port]; !ok {
+			owners[c.Port] = c.Owner()
+		}
+	}
+	return owners
+}
+
+// Service returns the claims held by one service.
+func (r *portRegistry) Service(name string) []portClaim {
+	var claims []portClaim
+	for _, c := range r.Claims {
+		if c.Service == name {
+			claims = append(claims, c)
+		}
+	}
+	return claims
+}
+
+// Conflicts describes each port claimed by more than one service, in port order.
+func (r *portRegistry) Conflicts() []string {
+	var conflicts []string
+	for i := 0; i < len(r.Claims); {
+		j := i
+		for j < len(r.Claims) && r.Claims[j].Port == r.Claims[i].Port {
+			j++
+		}
+		var owners []string
+		services := make(map[string]bool)
+		for _, c := range r.Claims[i:j] {
+			key := c.Service + "@" + c.Stack
+			if !services[key] {
+				services[key] = true
+				owners = append(owners, c.Owner())
+			}
+		}
+		if len(owners) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("Port %d claimed by multiple services (%s)", r.Claims[i].Port, strings.Join(owners, " and ")))
+		}
+		i = j
+	}
+	return conflicts
+}
+
+// savePortRegistry writes the registry to .bosun/ports.json.
+func savePortRegistry(cfg *config.Config, registry *portRegistry) error {
+	path := portRegistryPath(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create registry directory: %w", err)
+	}
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// loadPortRegistry returns the persisted registry, rebuilding and saving it
+// when it is missing or older than any rendered compose file or service
+// manifest. A registry that cannot be saved is still returned.
+func loadPortRegistry(cfg *config.Config) *portRegistry {
+	path := portRegistryPath(cfg)
+	if info, err := os.Stat(path); err == nil && !portSourcesNewer(cfg, info.ModTime()) {
+		if data, err := os.ReadFile(path); err == nil {
+			var registry portRegistry
+			if json.Unmarshal(data, &registry) == nil {
+				return &registry
+			}
+		}
+	}
+
+	registry := buildPortRegistry(cfg)
+	_ = savePortRegistry(cfg, registry)
+	return registry
+}
+
+// portSourcesNewer reports whether a rendered compose file, a service
+// manifest, or either directory changed after t. Directory times catch
+// removed files.
+func portSourcesNewer(cfg *config.Config, t time.Time) bool {
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	paths := []string{composeDir, cfg.ServicesDir()}
+	composeFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	serviceFiles, _ := filepath.Glob(filepath.Join(cfg.ServicesDir(), "*.yml"))
+	paths = append(append(paths, composeFiles...), serviceFiles...)
+
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.ModTime().After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Drift describes ports that running containers publish outside the
+// registry: ports no service claims and ports claimed by another service.
+func (r *portRegistry) Drift(containers []docker.ContainerInfo) []string {
+	claimants := make(map[int][]portClaim)
+	for _, c := range r.Claims {
+		claimants[c.Port] = append(claimants[c.Port], c)
+	}
+
+	var findings []string
+	for _, ctr := range containers {
+		seen := make(map[int]bool)
+		for _, mapping := range ctr.Ports {
+			published, _, ok := strings.Cut(mapping, ":")
+			port, err := strconv.Atoi(published)
+			if !ok || err != nil || seen[port] {
+				continue
+			}
+			seen[port] = true
+
+			claims := claimants[port]
+			switch {
+			case len(claims) == 0:
+				findings = append(findings, fmt.Sprintf("%s: publishes unregistered port %d", ctr.Name, port))
+			case !slices.ContainsFunc(claims, func(c portClaim) bool { return c.Service == ctr.Name }):
+				findings = append(findings, fmt.Sprintf("%s: publishes port %d, registered to %s", ctr.Name, port, claims[0].Owner()))
+			}
+		}
+	}
+	sort.Strings(findings)
+	return findings
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/portscan"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+const (
+	// defaultServicePort is the first port tried for new services.
+	defaultServicePort = 8080
+
+	// portScanTimeout bounds a whole port scan.
+	portScanTimeout = 10 * time.Second
+)
+
+var (
+	portsFree    string
+	portsCount   int
+	portsFrom    int
+	portsTo      int
+	portsService string
+)
+
+// portsFreeDefault is the --free value when no range is given.
+const portsFreeDefault = "-"
+
+// portsCmd lists claimed ports and suggests free ones.
+var portsCmd = &cobra.Command{
+	Use:     "ports [range]",
+	Aliases: []string{"berths"},
+	Short:   "List claimed ports or suggest free ones",
+	Long: `List host ports claimed by manifests, or suggest free ports for a new service.
+
+Claimed ports come from rendered compose files (published ports and Traefik
+service ports) and the port in each service manifest that hasn't been
+provisioned yet. Each is probed on the host to show whether something is
+listening. Ports claimed by more than one service are flagged.
+
+The registry is saved to .bosun/ports.json in the manifest directory, and is
+rebuilt whenever a rendered compose file or service manifest changes. Lint
+checks it for conflicts and drift for ports published outside it.
+
+With --free, candidate ports are checked against that registry and then
+probed with a short TCP dial, so ports held by processes outside Docker are
+skipped too. The range is given as START-END after --free, or with --from
+and --to.
+
+Examples:
+  bosun ports                          # Show claimed ports
+  bosun ports --service wiki           # Show the ports one service claims
+  bosun ports --free                   # Suggest 5 free ports from 8080
+  bosun ports --free 9000-9100         # Suggest free ports in a range
+  bosun ports --free -n 1 --from 9000  # First free port from 9000`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPorts,
+}
+
+func init() {
+	portsCmd.Flags().StringVar(&portsFree, "free", "", "Suggest free ports, optionally in a START-END range")
+	portsCmd.Flags().Lookup("free").NoOptDefVal = portsFreeDefault
+	portsCmd.Flags().IntVarP(&portsCount, "count", "n", 5, "Number of free ports to suggest")
+	portsCmd.Flags().IntVar(&portsFrom, "from", defaultServicePort, "Start of the port range to search")
+	portsCmd.Flags().IntVar(&portsTo, "to", 9999, "End of the port range to search")
+	portsCmd.Flags().StringVar(&portsService, "service", "", "Only show ports claimed by this service")
+
+	rootCmd.AddCommand(portsCmd)
+}
+
+func runPorts(cmd *cobra.Command, args []string) error {
+	free := cmd.Flags().Changed("free")
+	if len(args) == 1 && (!free || portsFree != portsFreeDefault) {
+		return fmt.Errorf("unexpected argument %q (a range only follows --free)", args[0])
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	registry := buildPortRegistry(cfg)
+	if err := savePortRegistry(cfg, registry); err != nil {
+		ui.Warning("Could not save port registry: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), portScanTimeout)
+	defer cancel()
+	scanner := portscan.New()
+
+	if free {
+		from, to := portsFrom, portsTo
+		rangeArg := portsFree
+		if len(args) == 1 {
+			rangeArg = args[0]
+		}
+		if rangeArg != portsFreeDefault {
+			if from, to, err = parsePortRange(rangeArg); err != nil {
+				return err
+			}
+		}
+
+		found, err := scanner.FindFree(ctx, from, to, portsCount, reservedPorts(registry.Owners()))
+		if err != nil {
+			return fmt.Errorf("scan ports: %w", err)
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("no free ports in %d-%d", from, to)
+		}
+
+		ui.Blue.Println("--- Free Ports ---")
+		for _, p := range found {
+			ui.Green.Printf("  * %d\n", p)
+		}
+		if len(found) < portsCount {
+			ui.Warning("Only %d free ports in %d-%d", len(found), from, to)
+		}
+		return nil
+	}
+
+	claims := registry.Claims
+	if portsService != "" {
+		claims = registry.Service(portsService)
+		if len(claims) == 0 {
+			ui.Info("No ports claimed by %s", portsService)
+			return nil
+		}
+	}
+	if len(claims) == 0 {
+		ui.Info("No ports claimed. Run 'bosun provision' to render compose files.")
+		return nil
+	}
+
+	owners := make(map[int]map[string]bool)
+	ports := make([]int, 0, len(claims))
+	for _, c := range registry.Claims {
+		if owners[c.Port] == nil {
+			owners[c.Port] = make(map[string]bool)
+		}
+		owners[c.Port][c.Service+"@"+c.Stack] = true
+	}
+	for _, c := range claims {
+		ports = append(ports, c.Port)
+	}
+	listening := scanner.InUse(ctx, ports)
+
+	ui.Blue.Println("--- Claimed Ports ---")
+	for _, c := range claims {
+		switch {
+		case len(owners[c.Port]) > 1:
+			ui.Red.Printf("  x %-6d %s (claimed by %d services)\n", c.Port, c.Owner(), len(owners[c.Port]))
+		case listening[c.Port]:
+			ui.Green.Printf("  * %-6d %s\n", c.Port, c.Owner())
+		default:
+			ui.Yellow.Printf("  ~ %-6d %s (nothing listening)\n", c.Port, c.Owner())
+		}
+	}
+	return nil
+}
+
+// parsePortRange parses START-END, or a single port, into an inclusive range.
+func parsePortRange(s string) (int, int, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	if !isRange {
+		endStr = startStr
+	}
+	from, err1 := strconv.Atoi(strings.TrimSpace(startStr))
+	to, err2 := strconv.Atoi(strings.TrimSpace(endStr))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q (expected START-END, e.g. 9000-9100)", s)
+	}
+	if from < 1 || to > 65535 || from > to {
+		return 0, 0, fmt.Errorf("invalid port range %q (ports must be 1-65535, start before end)", s)
+	}
+	return from, to, nil
+}
+
+// reservedPorts returns the port set of a registry.
+func reservedPorts(registry map[int]string) map[int]bool {
+	reserved := make(map[int]bool, len(registry))
+	for p := range registry {
+		reserved[p] = true
+	}
+	return reserved
+}
+
+// suggestServicePort returns the first port from defaultServicePort that is
+// neither claimed by a manifest nor listening on the host. Falls back to
+// defaultServicePort if the scan finds nothing.
+func suggestServicePort(cfg *config.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), portScanTimeout)
+	defer cancel()
+
+	free, err := portscan.New().FindFree(ctx, defaultServicePort, 65535, 1, reservedPorts(loadPortRegistry(cfg).Owners()))
+	if err != nil || len(free) == 0 {
+		ui.Warning("Could not find a free port, using %d", defaultServicePort)
+		return defaultServicePort
+	}
+	if free[0] != defaultServicePort {
+		ui.Info("Port %d is taken; using %d", defaultServicePort, free[0])
+	}
+	return free[0]
+}
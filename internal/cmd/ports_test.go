@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+func TestPortsCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "ports", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "claimed by manifests")
+	assert.Contains(t, output, "--free")
+	assert.Contains(t, output, "--service")
+}
+
+func TestPortsCmd_Aliases(t *testing.T) {
+	_, err := executeCmd(t, "berths", "--help")
+	assert.NoError(t, err)
+}
+
+func TestPortRegistry(t *testing.T) {
+	manifestDir := t.TempDir()
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	composeDir := filepath.Join(manifestDir, "output", "compose")
+	require.NoError(t, os.MkdirAll(composeDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "core.yml"), []byte(`services:
+  traefik:
+    ports:
+      - "443:443"
+  whoami:
+    labels:
+      traefik.http.services.whoami.loadbalancer.server.port: "8080"
+`), 0644))
+
+	servicesDir := filepath.Join(manifestDir, "services")
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "wiki.yml"), []byte("name: wiki\nprovisions:\n  - webapp\nconfig:\n  port: 8081\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "dup.yml"), []byte("name: dup\nprovisions:\n  - webapp\nconfig:\n  port: 443\n"), 0644))
+
+	registry := buildPortRegistry(cfg)
+	assert.Equal(t, map[int]string{
+		443:  "traefik@core",
+		8080: "whoami (traefik)@core",
+		8081: "wiki (manifest)",
+	}, registry.Owners())
+	assert.Equal(t, []string{"Port 443 claimed by multiple services (traefik@core and dup (manifest))"}, registry.Conflicts())
+	assert.Equal(t, []portClaim{{Port: 8081, Service: "wiki", Source: portSourceManifest}}, registry.Service("wiki"))
+}
+
+func TestPortRegistry_SkipsProvisionedManifests(t *testing.T) {
+	manifestDir := t.TempDir()
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	composeDir := filepath.Join(manifestDir, "output", "compose")
+	require.NoError(t, os.MkdirAll(composeDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "apps.yml"), []byte(`services:
+  wiki:
+    ports:
+      - "3000:3000"
+  api:
+    ports:
+      - "3000:8080"
+`), 0644))
+
+	servicesDir := filepath.Join(manifestDir, "services")
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "wiki.yml"), []byte("name: wiki\nprovisions:\n  - webapp\nconfig:\n  port: 3000\n"), 0644))
+
+	registry := buildPortRegistry(cfg)
+	assert.Equal(t, []portClaim{
+		{Port: 3000, Service: "api", Stack: "apps", Source: portSourceCompose},
+		{Port: 3000, Service: "wiki", Stack: "apps", Source: portSourceCompose},
+	}, registry.Claims)
+	assert.Equal(t, []string{"Port 3000 claimed by multiple services (api@apps and wiki@apps)"}, registry.Conflicts())
+}
+
+func TestLoadPortRegistry(t *testing.T) {
+	manifestDir := t.TempDir()
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	servicesDir := filepath.Join(manifestDir, "services")
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	wiki := filepath.Join(servicesDir, "wiki.yml")
+	require.NoError(t, os.WriteFile(wiki, []byte("name: wiki\nprovisions:\n  - webapp\nconfig:\n  port: 8081\n"), 0644))
+
+	registry := loadPortRegistry(cfg)
+	assert.Equal(t, map[int]string{8081: "wiki (manifest)"}, registry.Owners())
+	assert.FileExists(t, filepath.Join(manifestDir, ".bosun", "ports.json"))
+
+	// An up-to-date registry is read back as saved
+	saved := &portRegistry{Claims: []portClaim{{Port: 9999, Service: "saved", Source: portSourceManifest}}}
+	require.NoError(t, savePortRegistry(cfg, saved))
+	assert.Equal(t, map[int]string{9999: "saved (manifest)"}, loadPortRegistry(cfg).Owners())
+
+	// A changed manifest rebuilds it
+	require.NoError(t, os.WriteFile(wiki, []byte("name: wiki\nprovisions:\n  - webapp\nconfig:\n  port: 8082\n"), 0644))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(wiki, future, future))
+	assert.Equal(t, map[int]string{8082: "wiki (manifest)"}, loadPortRegistry(cfg).Owners())
+}
+
+func TestPortRegistry_Drift(t *testing.T) {
+	registry := &portRegistry{Claims: []portClaim{
+		{Port: 443, Service: "traefik", Stack: "core", Source: portSourceCompose},
+		{Port: 8080, Service: "wiki", Stack: "apps", Source: portSourceCompose},
+	}}
+
+	findings := registry.Drift([]docker.ContainerInfo{
+		{Name: "traefik", Ports: []string{"443:443/tcp", "443:443/tcp"}},
+		{Name: "api", Ports: []string{"8080:80/tcp", "9000:9000/udp", "5432/tcp"}},
+	})
+	assert.Equal(t, []string{
+		"api: publishes port 8080, registered to wiki@apps",
+		"api: publishes unregistered port 9000",
+	}, findings)
+}
+
+func TestParsePortRange(t *testing.T) {
+	from, to, err := parsePortRange("9000-9100")
+	require.NoError(t, err)
+	assert.Equal(t, 9000, from)
+	assert.Equal(t, 9100, to)
+
+	from, to, err = parsePortRange("9000")
+	require.NoError(t, err)
+	assert.Equal(t, 9000, from)
+	assert.Equal(t, 9000, to)
+
+	for _, bad := range []string{"", "abc", "9100-9000", "0-10", "9000-70000", "9000-"} {
+		_, _, err := parsePortRange(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSuggestServicePort(t *testing.T) {
+	manifestDir := t.TempDir()
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	servicesDir := filepath.Join(manifestDir, "services")
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "web.yml"), []byte("name: web\nprovisions:\n  - webapp\nconfig:\n  port: 8080\n"), 0644))
+
+	// Hold the next port open so the host probe has to skip it
+	ln, err := net.Listen("tcp", "127.0.0.1:8081")
+	if err != nil {
+		t.Skip("port 8081 unavailable for test")
+	}
+	defer ln.Close()
+
+	port := suggestServicePort(cfg)
+	assert.Greater(t, port, 8081)
+}
@@ -1,31 +1,33 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
 	"github.com/cameronsjo/bosun/internal/lock"
 	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/snapshot"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
-// Provision command display limits.
-const (
-	// MaxDiffOutputLines is the maximum number of lines to show in diff output before truncation.
-	MaxDiffOutputLines = 50
-)
-
 var (
-	provisionDryRun bool
-	provisionDiff   bool
-	provisionValues string
+	provisionDryRun  bool
+	provisionDiff    bool
+	provisionValues  string
+	provisionNoCache bool
 )
 
+// renderCacheMaxAge is how long a cached service render is kept unused.
+const renderCacheMaxAge = 30 * 24 * time.Hour
+
 // provisionCmd renders manifest to compose/traefik/gatus.
 var provisionCmd = &cobra.Command{
 	Use:     "provision [stack]",
@@ -33,11 +35,16 @@ var provisionCmd = &cobra.Command{
 	Short:   "Render manifest to compose/traefik/gatus",
 	Long: `Render a stack or service manifest into compose, traefik, and gatus outputs.
 
+Stack services are cached by a hash of their manifest, values, provisions,
+and host facts, so only changed services are re-rendered. --no-cache
+renders everything and leaves the cache alone.
+
 Examples:
   bosun provision core           # Render the 'core' stack
   bosun provision -n core        # Dry run - show output without writing
   bosun provision -d core        # Show diff against existing files
-  bosun provision -f prod.yaml   # Apply values overlay`,
+  bosun provision -f prod.yaml   # Apply values overlay
+  bosun provision --no-cache core  # Re-render every service`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runProvision,
 }
@@ -70,6 +77,7 @@ func init() {
 	provisionCmd.Flags().BoolVarP(&provisionDryRun, "dry-run", "n", false, "Show what would be generated without writing")
 	provisionCmd.Flags().BoolVarP(&provisionDiff, "diff", "d", false, "Show diff against existing output files")
 	provisionCmd.Flags().StringVarP(&provisionValues, "values", "f", "", "Apply values overlay file (YAML)")
+	provisionCmd.Flags().BoolVar(&provisionNoCache, "no-cache", false, "Re-render every service, bypassing the render cache")
 
 	// Add commands to root
 	rootCmd.AddCommand(provisionCmd)
@@ -83,6 +91,9 @@ func runProvision(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("load config: %w", err)
 	}
 
+	loadHostFacts(cmd.Context())
+	loadServicePorts(cfg.ServicesDir())
+
 	// Load values overlay if provided
 	var valuesOverlay map[string]any
 	if provisionValues != "" {
@@ -94,6 +105,7 @@ func runProvision(cmd *cobra.Command, args []string) error {
 
 	var output *manifest.RenderOutput
 	var stackName string
+	var cache *manifest.RenderCache
 
 	if len(args) == 0 {
 		// No argument - look for default stack or show usage
@@ -108,7 +120,10 @@ func runProvision(cmd *cobra.Command, args []string) error {
 
 	if _, err := os.Stat(stackPath); err == nil {
 		// Render stack
-		output, err = manifest.RenderStack(stackPath, cfg.ProvisionsDir(), cfg.ServicesDir(), valuesOverlay)
+		if !provisionNoCache {
+			cache = manifest.NewRenderCache(cfg.RenderCacheDir(), version+"+"+commit)
+		}
+		output, err = manifest.RenderStackWith(stackPath, cfg.ProvisionsDir(), cfg.ServicesDir(), valuesOverlay, cache)
 		if err != nil {
 			return fmt.Errorf("render stack: %w", err)
 		}
@@ -156,10 +171,24 @@ func runProvision(cmd *cobra.Command, args []string) error {
 	}
 	defer func() { _ = provisionLock.Release() }()
 
+	// Snapshot the current output so mayday --rollback can restore it
+	if _, err := snapshot.CreateWith(cfg.ManifestDir, cfg.OutputDir(), snapshotRetention(cfg)); err != nil {
+		ui.Warning("Could not snapshot current output: %v", err)
+	}
+
 	if err := manifest.WriteOutputs(output, cfg.OutputDir(), stackName); err != nil {
 		return fmt.Errorf("write outputs: %w", err)
 	}
 
+	if cache != nil {
+		if cache.Hits > 0 {
+			ui.Info("Reused %d cached service render(s), rendered %d", cache.Hits, cache.Misses)
+		}
+		if _, err := cache.Prune(renderCacheMaxAge); err != nil {
+			ui.Warning("Could not prune render cache: %v", err)
+		}
+	}
+
 	ui.Green.Printf("Successfully provisioned %s\n", stackName)
 	return nil
 }
@@ -215,7 +244,11 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("service already exists: %s", servicePath)
 	}
 
-	content := generateServiceTemplate(template, name)
+	port := defaultServicePort
+	if template == "webapp" || template == "api" {
+		port = suggestServicePort(cfg)
+	}
+	content := generateServiceTemplate(template, name, port)
 
 	if err := os.MkdirAll(cfg.ServicesDir(), 0755); err != nil {
 		return fmt.Errorf("create services directory: %w", err)
@@ -231,29 +264,31 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func generateServiceTemplate(template, name string) string {
+// generateServiceTemplate renders a service manifest for template. port is
+// used by templates that serve HTTP.
+func generateServiceTemplate(template, name string, port int) string {
 	templates := map[string]string{
-		"webapp": `name: %s
+		"webapp": `name: %[1]s
 provisions:
   - webapp
 config:
-  port: 8080
-  domain: %s.example.com
+  port: %[2]d
+  domain: %[1]s.example.com
 `,
-		"api": `name: %s
+		"api": `name: %[1]s
 provisions:
   - api
 config:
-  port: 8080
+  port: %[2]d
   health_path: /health
 `,
-		"worker": `name: %s
+		"worker": `name: %[1]s
 provisions:
   - worker
 config:
   replicas: 1
 `,
-		"static": `name: %s
+		"static": `name: %[1]s
 provisions:
   - static
 config:
@@ -261,49 +296,63 @@ config:
 `,
 	}
 
-	return fmt.Sprintf(templates[template], name, name)
+	return fmt.Sprintf(templates[template], name, port)
 }
 
+// showDiff prints a unified diff of the stack's rendered files against the
+// existing files in the output directory, without writing anything.
 func showDiff(output *manifest.RenderOutput, outputDir, stackName string) error {
-	// For now, just show a placeholder - full diff implementation would compare
-	// generated YAML against existing files
-	ui.Yellow.Println("Diff mode not yet implemented")
-	ui.Blue.Println("Would compare generated output against:")
-
-	targets := []struct {
-		name     string
-		filename string
-	}{
-		{"compose", stackName + ".yml"},
-		{"traefik", "dynamic.yml"},
-		{"gatus", "endpoints.yml"},
-	}
-
-	for _, t := range targets {
-		path := filepath.Join(outputDir, t.name, t.filename)
-		if _, err := os.Stat(path); err == nil {
-			fmt.Printf("  - %s\n", path)
-		} else {
-			fmt.Printf("  - %s (new file)\n", path)
-		}
+	files, err := manifest.RenderFiles(output, stackName)
+	if err != nil {
+		return err
 	}
 
-	// Show what would be generated
-	fmt.Println()
-	ui.Blue.Println("Generated output:")
-	yamlOutput, err := manifest.RenderToYAML(output)
+	root := fileutil.ResolveDir(outputDir)
+	diffs, err := diffFiles(files, func(rel string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	})
 	if err != nil {
 		return err
 	}
 
-	// Truncate long output
-	lines := strings.Split(yamlOutput, "\n")
-	if len(lines) > MaxDiffOutputLines {
-		fmt.Println(strings.Join(lines[:MaxDiffOutputLines], "\n"))
-		fmt.Printf("... (%d more lines)\n", len(lines)-MaxDiffOutputLines)
-	} else {
-		fmt.Print(yamlOutput)
+	printFileDiffs(diffs, outputDir)
+	return nil
+}
+
+// loadHostFacts makes facts about the deploy host available to manifests as
+// ${host.*} variables. Facts come from DEPLOY_TARGET over SSH when set,
+// otherwise from this host.
+func loadHostFacts(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	return nil
+	facts := hostmetrics.LocalFacts(ctx)
+	if target := os.Getenv("DEPLOY_TARGET"); target != "" {
+		remote, err := hostmetrics.RemoteFacts(ctx, target)
+		if err != nil {
+			ui.Warning("Using local host facts: %v", err)
+		} else {
+			facts = remote
+		}
+	}
+
+	manifest.SetGlobalVariables(facts.Variables())
+}
+
+// loadServicePorts makes the config.port of every service manifest in
+// servicesDir available to ${port_of(service)}.
+func loadServicePorts(servicesDir string) {
+	ports := make(map[string]int)
+	files, _ := filepath.Glob(filepath.Join(servicesDir, "*.yml"))
+	for _, file := range files {
+		svc, err := manifest.LoadServiceManifest(file)
+		if err != nil {
+			continue
+		}
+		if port, ok := svc.Config["port"].(int); ok && port > 0 {
+			ports[svc.Name] = port
+		}
+	}
+	manifest.SetServicePorts(ports)
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
 )
 
 func TestProvisionCmd_Help(t *testing.T) {
@@ -53,6 +55,11 @@ func TestProvisionCmd_Flags(t *testing.T) {
 		resetRootCmd(t)
 		assert.Empty(t, provisionValues) // default value
 	})
+
+	t.Run("has no-cache flag", func(t *testing.T) {
+		resetRootCmd(t)
+		assert.False(t, provisionNoCache) // default value
+	})
 }
 
 func TestProvisionCmd_RequiresStackName(t *testing.T) {
@@ -124,7 +131,7 @@ func TestGenerateServiceTemplate(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.template, func(t *testing.T) {
-			result := generateServiceTemplate(tc.template, tc.name)
+			result := generateServiceTemplate(tc.template, tc.name, defaultServicePort)
 
 			for _, exp := range tc.expected {
 				assert.Contains(t, result, exp)
@@ -134,7 +141,7 @@ func TestGenerateServiceTemplate(t *testing.T) {
 }
 
 func TestShowDiff(t *testing.T) {
-	t.Run("shows diff targets", func(t *testing.T) {
+	t.Run("diffs against existing output files", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		// Create existing output files
@@ -142,9 +149,15 @@ func TestShowDiff(t *testing.T) {
 		require.NoError(t, os.MkdirAll(composeDir, 0755))
 		require.NoError(t, os.WriteFile(filepath.Join(composeDir, "test.yml"), []byte("existing"), 0644))
 
-		// The showDiff function requires a non-nil RenderOutput
-		// Skip this test as it requires manifest package mocking
-		t.Skip("requires RenderOutput from manifest package")
+		output := manifest.NewRenderOutput()
+		output.Compose["services"] = map[string]any{"web": map[string]any{"image": "nginx"}}
+
+		require.NoError(t, showDiff(output, tmpDir, "test"))
+
+		// Nothing is written
+		data, err := os.ReadFile(filepath.Join(composeDir, "test.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, "existing", string(data))
 	})
 }
 
@@ -181,7 +194,7 @@ func TestCreateCmd_ValidTemplates(t *testing.T) {
 
 	for _, tmpl := range validTemplates {
 		t.Run(tmpl+" template", func(t *testing.T) {
-			result := generateServiceTemplate(tmpl, "test-service")
+			result := generateServiceTemplate(tmpl, "test-service", defaultServicePort)
 			assert.Contains(t, result, "name: test-service")
 			assert.Contains(t, result, tmpl)
 		})
@@ -220,7 +233,7 @@ func TestGenerateServiceTemplate_AllTypes(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.template, func(t *testing.T) {
-			result := generateServiceTemplate(tc.template, tc.name)
+			result := generateServiceTemplate(tc.template, tc.name, defaultServicePort)
 
 			for _, expected := range tc.mustContain {
 				assert.Contains(t, result, expected,
@@ -244,3 +257,41 @@ func TestCreateCmd_HelpWorks(t *testing.T) {
 		assert.Contains(t, output, "Usage:")
 	})
 }
+
+func TestDocsCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "docs", "--help")
+	assert.NoError(t, err)
+	if len(output) > 0 {
+		assert.Contains(t, output, "docs")
+	}
+}
+
+func TestSearchCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "search", "--help")
+	assert.NoError(t, err)
+	if len(output) > 0 {
+		assert.Contains(t, output, "search")
+	}
+}
+
+func TestExportCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "export", "k8s", "--help")
+	assert.NoError(t, err)
+	if len(output) > 0 {
+		assert.Contains(t, output, "best-effort")
+	}
+}
+
+func TestExportCmd_Aliases(t *testing.T) {
+	_, err := executeCmd(t, "offload", "--help")
+	assert.NoError(t, err)
+}
+
+func TestRunSearch_UnknownLayer(t *testing.T) {
+	searchLayer = "output"
+	defer func() { searchLayer = "" }()
+
+	err := runSearch(searchCmd, []string{"term"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown layer")
+}
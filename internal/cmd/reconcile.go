@@ -4,22 +4,31 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/alert"
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
 	"github.com/cameronsjo/bosun/internal/reconcile"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
 var (
-	reconcileDryRun bool
-	reconcileForce  bool
-	reconcileLocal  bool
-	reconcileRemote string
+	reconcileDryRun         bool
+	reconcileForce          bool
+	reconcileLocal          bool
+	reconcileRemote         string
+	reconcileAll            bool
+	reconcileChaos          string
+	reconcileSkipValidation bool
+	reconcileLintMode       string
+	reconcileProfiles       []string
 )
 
 // reconcileCmd represents the reconcile command.
@@ -31,7 +40,8 @@ var reconcileCmd = &cobra.Command{
 1. Acquire lock (prevent concurrent runs)
 2. Clone/pull repository
 3. Decrypt secrets with SOPS
-4. Render templates with Chezmoi
+4. Render templates with Chezmoi (pinned stacks use their pinned ref)
+   and validate them with the lint gate (--skip-validation to override)
 5. Create backup of current configs
 6. Deploy (native file copy or tar-over-SSH for remote)
 7. Docker compose up
@@ -49,8 +59,26 @@ Directories (defaults for container deployment):
   STAGING_DIR     - Staging directory (default: /app/staging)
   BACKUP_DIR      - Backup directory (default: /app/backups)
   LOG_DIR         - Log directory (default: /app/logs)
+  BOSUN_STATE_DIR - State directory (or STATE_DIR; default: /app/state)
   LOCAL_APPDATA   - Local appdata path (default: /mnt/appdata)
-  REMOTE_APPDATA  - Remote appdata path (default: /mnt/user/appdata)`,
+  REMOTE_APPDATA  - Remote appdata path (default: /mnt/user/appdata)
+
+Backups:
+  BOSUN_BACKUP_ENCRYPT - Set to true to age-encrypt backup archives with the SOPS key
+
+Selective deploys (see docs/gitops.md):
+  BOSUN_SELECTIVE_DEPLOY - Set to false to deploy every stack and config on each change
+
+Compose profiles (--profile overrides):
+  BOSUN_PROFILES  - Comma-separated profiles to enable, e.g. media,dev
+
+Maintenance page (see 'bosun maintenance'):
+  BOSUN_MAINTENANCE_PAGE - Route Traefik to a maintenance page while services reload
+  BOSUN_MAINTENANCE_URL  - Backend serving the page (default: http://bosun:8080)
+
+Workspaces (several projects in one repo, see bosun.workspaces.yml):
+  --project NAME  - Reconcile one project, with its own target and directories
+  --all-projects  - Reconcile every project in the workspace`,
 	Run: runReconcile,
 }
 
@@ -59,12 +87,20 @@ func init() {
 	reconcileCmd.Flags().BoolVarP(&reconcileForce, "force", "f", false, "Force deployment even if no changes detected")
 	reconcileCmd.Flags().BoolVarP(&reconcileLocal, "local", "l", false, "Force local deployment mode")
 	reconcileCmd.Flags().StringVarP(&reconcileRemote, "remote", "r", "", "Target host for remote deployment (e.g., root@192.168.1.8)")
+	reconcileCmd.Flags().BoolVar(&reconcileAll, "all-projects", false, "Reconcile every project in the workspace")
+	reconcileCmd.Flags().StringSliceVar(&reconcileProfiles, "profile", nil, "Enable a compose profile (repeatable or comma-separated; overrides BOSUN_PROFILES)")
+	reconcileCmd.Flags().BoolVar(&reconcileSkipValidation, "skip-validation", false, "Deploy even if rendered compose files fail the lint gate (same as LINT_MODE=off)")
+	reconcileCmd.Flags().StringVar(&reconcileLintMode, "lint-mode", "", "Lint gate mode: block, warn, or off (overrides LINT_MODE)")
+	reconcileCmd.Flags().StringVar(&reconcileChaos, "chaos", "", "Inject deploy failures for testing rollback (staging only), e.g. 0.2 or health-gate=0.5")
+	_ = reconcileCmd.Flags().MarkHidden("chaos")
 
 	rootCmd.AddCommand(reconcileCmd)
 }
 
-func runReconcile(cmd *cobra.Command, args []string) {
-	// Build configuration from environment and flags.
+// reconcileConfigFromEnv builds the reconcile configuration from the
+// environment variables listed in 'bosun reconcile --help'. Invalid values
+// are fatal.
+func reconcileConfigFromEnv() *reconcile.Config {
 	cfg := reconcile.DefaultConfig()
 
 	// Required: repo URL.
@@ -86,9 +122,11 @@ func runReconcile(cmd *cobra.Command, args []string) {
 	if backupDir := os.Getenv("BACKUP_DIR"); backupDir != "" {
 		cfg.BackupDir = backupDir
 	}
+	cfg.EncryptBackups = os.Getenv("BOSUN_BACKUP_ENCRYPT") == "true"
 	if logDir := os.Getenv("LOG_DIR"); logDir != "" {
 		cfg.LogDir = logDir
 	}
+	cfg.StateDir = reconcile.ResolveStateDir("")
 	if localAppdata := os.Getenv("LOCAL_APPDATA"); localAppdata != "" {
 		cfg.LocalAppdataPath = localAppdata
 	}
@@ -96,6 +134,46 @@ func runReconcile(cmd *cobra.Command, args []string) {
 		cfg.RemoteAppdataPath = remoteAppdata
 	}
 
+	// Ownership of deployed files from environment.
+	if ownership := os.Getenv("DEPLOY_OWNERSHIP"); ownership != "" {
+		rules, err := reconcile.ParseOwnershipRules(ownership)
+		if err != nil {
+			ui.Fatal("Invalid DEPLOY_OWNERSHIP: %v", err)
+		}
+		cfg.Ownership = rules
+	}
+
+	// How ownership rules map container IDs on the target's Docker daemon.
+	cfg.Userns = os.Getenv("BOSUN_DOCKER_USERNS")
+	if err := reconcile.ValidateUsernsMode(cfg.Userns); err != nil {
+		ui.Fatal("Invalid BOSUN_DOCKER_USERNS: %v", err)
+	}
+	cfg.OwnershipImage = os.Getenv("BOSUN_OWNERSHIP_IMAGE")
+
+	// Deploy only what the new commits touch unless disabled.
+	cfg.Selective = os.Getenv("BOSUN_SELECTIVE_DEPLOY") != "false"
+
+	// Compose profiles enabled on deploy.
+	if profiles := os.Getenv("BOSUN_PROFILES"); profiles != "" {
+		parsed, err := manifest.ParseProfiles(profiles)
+		if err != nil {
+			ui.Fatal("Invalid BOSUN_PROFILES: %v", err)
+		}
+		cfg.Profiles = parsed
+	}
+
+	// Maintenance page during service reloads.
+	cfg.MaintenancePage = os.Getenv("BOSUN_MAINTENANCE_PAGE") == "true"
+	cfg.MaintenanceURL = os.Getenv("BOSUN_MAINTENANCE_URL")
+
+	// Lint gate enforcement from environment.
+	if lintMode := os.Getenv("LINT_MODE"); lintMode != "" {
+		if err := reconcile.ValidateLintMode(lintMode); err != nil {
+			ui.Fatal("Invalid LINT_MODE: %v", err)
+		}
+		cfg.LintMode = lintMode
+	}
+
 	// Secret files from environment.
 	if secretsFiles := os.Getenv("SECRETS_FILES"); secretsFiles != "" {
 		cfg.SecretsFiles = strings.Split(secretsFiles, ",")
@@ -104,34 +182,116 @@ func runReconcile(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// Target host from environment or flags.
+	// Target host from environment.
 	if target := os.Getenv("DEPLOY_TARGET"); target != "" {
 		cfg.TargetHost = target
 	}
+	// Remote Docker daemon for API calls instead of docker over SSH.
+	cfg.DockerHost = os.Getenv("BOSUN_DOCKER_HOST")
+
+	// Dry run and force from environment.
+	if os.Getenv("DRY_RUN") == "true" {
+		cfg.DryRun = true
+	}
+	if os.Getenv("FORCE") == "true" {
+		cfg.Force = true
+	}
+
+	// Host label selecting per-host compose overrides.
+	cfg.HostLabel = os.Getenv("BOSUN_HOST_LABEL")
+
+	// BuildKit layer cache for images built from source.
+	cfg.BuildCacheDir = os.Getenv("BOSUN_BUILD_CACHE")
+
+	// How built images reach a remote target.
+	cfg.ImageDistribution = os.Getenv("BOSUN_IMAGE_DISTRIBUTION")
+	cfg.Registry = os.Getenv("BOSUN_REGISTRY")
+	if err := reconcile.ValidateImageDistribution(cfg.ImageDistribution, cfg.Registry); err != nil {
+		ui.Fatal("Invalid BOSUN_IMAGE_DISTRIBUTION: %v", err)
+	}
+
+	// Free space thresholds checked before deploying.
+	if root := os.Getenv("DOCKER_ROOT_DIR"); root != "" {
+		cfg.DockerRootDir = root
+	}
+	if root := os.Getenv("BOSUN_DOCKER_ROOT_DIR"); root != "" {
+		cfg.DockerRootDir = root
+	}
+	if minFree := os.Getenv("BOSUN_DISK_MIN_FREE"); minFree != "" {
+		n, err := reconcile.ParseByteSize(minFree)
+		if err != nil {
+			ui.Fatal("Invalid BOSUN_DISK_MIN_FREE: %v", err)
+		}
+		cfg.DiskMinFree = n
+	}
+	if pct := os.Getenv("BOSUN_DISK_MIN_FREE_PERCENT"); pct != "" {
+		n, err := strconv.ParseFloat(pct, 64)
+		if err != nil || n < 0 || n > 100 {
+			ui.Fatal("Invalid BOSUN_DISK_MIN_FREE_PERCENT: %q (expected 0-100)", pct)
+		}
+		cfg.DiskMinFreePercent = n
+	}
+
+	// Age after which crash-leftover temp directories are removed.
+	if age := os.Getenv("BOSUN_STALE_TEMP_AGE"); age != "" {
+		d, err := time.ParseDuration(age)
+		if err != nil || d < 0 {
+			ui.Fatal("Invalid BOSUN_STALE_TEMP_AGE: %q (expected a duration like 1h, or 0 to disable)", age)
+		}
+		cfg.StaleTempAge = d
+	}
+
+	// Chaos mode from environment.
+	if chaosSpec := os.Getenv("BOSUN_CHAOS"); chaosSpec != "" {
+		chaos, err := reconcile.ParseChaos(chaosSpec)
+		if err != nil {
+			ui.Fatal("Invalid chaos spec: %v", err)
+		}
+		cfg.Chaos = chaos
+	}
+
+	return cfg
+}
+
+func runReconcile(cmd *cobra.Command, args []string) {
+	// Build configuration from environment and flags.
+	cfg := reconcileConfigFromEnv()
 	if reconcileRemote != "" {
 		cfg.TargetHost = reconcileRemote
 	}
-
 	// Force local mode if --local flag is set.
 	if reconcileLocal {
 		cfg.TargetHost = ""
 	}
-
-	// Dry run from environment or flags.
-	if os.Getenv("DRY_RUN") == "true" {
-		cfg.DryRun = true
-	}
 	if reconcileDryRun {
 		cfg.DryRun = true
 	}
-
-	// Force from environment or flags.
-	if os.Getenv("FORCE") == "true" {
-		cfg.Force = true
-	}
 	if reconcileForce {
 		cfg.Force = true
 	}
+	if reconcileLintMode != "" {
+		if err := reconcile.ValidateLintMode(reconcileLintMode); err != nil {
+			ui.Fatal("Invalid --lint-mode: %v", err)
+		}
+		cfg.LintMode = reconcileLintMode
+	}
+	if reconcileSkipValidation {
+		cfg.LintMode = reconcile.LintModeOff
+	}
+	if len(reconcileProfiles) > 0 {
+		profiles, err := manifest.ParseProfiles(strings.Join(reconcileProfiles, ","))
+		if err != nil {
+			ui.Fatal("Invalid --profile: %v", err)
+		}
+		cfg.Profiles = profiles
+	}
+	if reconcileChaos != "" {
+		chaos, err := reconcile.ParseChaos(reconcileChaos)
+		if err != nil {
+			ui.Fatal("Invalid chaos spec: %v", err)
+		}
+		cfg.Chaos = chaos
+	}
 
 	// Create context with cancellation on SIGINT/SIGTERM.
 	ctx, cancel := context.WithCancel(context.Background())
@@ -163,6 +323,18 @@ func runReconcile(cmd *cobra.Command, args []string) {
 		opts = append(opts, reconcile.WithAlerter(alerter))
 	}
 
+	// Workspace projects sync once and reconcile each project in its own scope.
+	if project := config.SelectedProject(); project != "" || reconcileAll {
+		var names []string
+		if !reconcileAll {
+			names = []string{project}
+		}
+		if err := reconcile.RunProjects(ctx, cfg, names, opts...); err != nil {
+			ui.Fatal("Reconciliation failed: %v", err)
+		}
+		return
+	}
+
 	r := reconcile.NewReconciler(cfg, opts...)
 	if err := r.Run(ctx); err != nil {
 		ui.Fatal("Reconciliation failed: %v", err)
@@ -171,32 +343,7 @@ func runReconcile(cmd *cobra.Command, args []string) {
 
 // createAlertManager creates an alert manager with configured providers.
 func createAlertManager() *alert.Manager {
-	mgr := alert.NewManager()
-
-	// Add Discord provider.
-	discord := alert.NewDiscordProvider(os.Getenv("DISCORD_WEBHOOK_URL"))
-	mgr.AddProvider(discord)
-
-	// Add SendGrid provider.
-	toEmails := filterEmptyStrings(strings.Split(os.Getenv("SENDGRID_TO_EMAILS"), ","))
-	sendgrid := alert.NewSendGrid(alert.SendGridConfig{
-		APIKey:    os.Getenv("SENDGRID_API_KEY"),
-		FromEmail: os.Getenv("SENDGRID_FROM_EMAIL"),
-		FromName:  os.Getenv("SENDGRID_FROM_NAME"),
-		ToEmails:  toEmails,
-	})
-	mgr.AddProvider(sendgrid)
-
-	// Add Twilio provider.
-	toNumbers := filterEmptyStrings(strings.Split(os.Getenv("TWILIO_TO_NUMBERS"), ","))
-	twilio := alert.NewTwilio(alert.TwilioConfig{
-		AccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
-		AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
-		FromNumber: os.Getenv("TWILIO_FROM_NUMBER"),
-		ToNumbers:  toNumbers,
-	})
-	mgr.AddProvider(twilio)
-
+	mgr := newAlertManager(projectAlertConfig())
 	if !mgr.HasProviders() {
 		return nil
 	}
@@ -204,15 +351,3 @@ func createAlertManager() *alert.Manager {
 	ui.Info("Alert providers: %v", mgr.ProviderNames())
 	return mgr
 }
-
-// filterEmptyStrings removes empty strings from a slice.
-func filterEmptyStrings(ss []string) []string {
-	result := make([]string, 0, len(ss))
-	for _, s := range ss {
-		s = strings.TrimSpace(s)
-		if s != "" {
-			result = append(result, s)
-		}
-	}
-	return result
-}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	replayRepoDir  string
+	replayStateDir string
+	replayJSON     bool
+)
+
+// replayCmd replays a recorded reconcile.
+var replayCmd = &cobra.Command{
+	Use:     "replay [fixture]",
+	Aliases: []string{"wake"},
+	Short:   "Replay a recorded reconcile and show its plan",
+	Long: `Replay re-runs a recorded reconcile in full dry-run and prints the plan it
+would have executed: source files changed, rendered files synced, and which
+compose services would be created, recreated (with the fields that changed),
+or removed.
+
+Every reconcile records its context in the state directory as
+last-reconcile.yml: the commit pair, changed files, pins, host facts, and the
+secrets' structure with fake values. Replay renders both commits from the
+local repo with those fake secrets and compares them, so nothing is
+decrypted, deployed, or restarted.
+
+Use it to answer "why did last night's reconcile restart everything".
+A fixture can also be written by hand to try a commit pair.
+
+Examples:
+  bosun replay                                  # Replay the last reconcile
+  bosun replay fixture.yml --repo ~/infra       # Replay a saved fixture
+  bosun replay --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayRepoDir, "repo", "", "Repository to render from (default: $REPO_DIR or /app/repo)")
+	replayCmd.Flags().StringVar(&replayStateDir, "state-dir", "", "State directory holding last-reconcile.yml (default: $BOSUN_STATE_DIR, $STATE_DIR, or /app/state)")
+	replayCmd.Flags().BoolVar(&replayJSON, "json", false, "Output the plan as JSON")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	fixturePath := filepath.Join(filepath.Dir(stateStore(replayStateDir).Path()), reconcile.FixtureFile)
+	if len(args) > 0 {
+		fixturePath = args[0]
+	}
+
+	fixture, err := reconcile.LoadFixture(fixturePath)
+	if err != nil {
+		return err
+	}
+
+	repoDir := replayRepoDir
+	if repoDir == "" {
+		repoDir = os.Getenv("REPO_DIR")
+	}
+	if repoDir == "" {
+		repoDir = reconcile.DefaultConfig().RepoDir
+	}
+
+	plan, err := reconcile.Replay(cmd.Context(), fixture, repoDir)
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+
+	if replayJSON {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal plan: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printReplayPlan(fixture, plan)
+	return nil
+}
+
+// printReplayPlan prints a replayed plan grouped by stage.
+func printReplayPlan(fixture *reconcile.Fixture, plan *reconcile.Plan) {
+	before := shortCommit(plan.Before)
+	if before == "" {
+		before = "(fresh clone)"
+	}
+	ui.Info("Replaying reconcile %s -> %s (recorded %s)", before, shortCommit(plan.After), timezone.Display(fixture.RecordedAt))
+
+	if plan.Skipped {
+		ui.Info("No changes and not forced: the reconcile skipped deployment")
+		return
+	}
+
+	fmt.Println()
+	ui.Blue.Printf("--- Source changes (%d) ---\n", len(plan.ChangedFiles))
+	for _, f := range plan.ChangedFiles {
+		fmt.Printf("  %s\n", f)
+	}
+
+	fmt.Println()
+	ui.Blue.Printf("--- Rendered files (%d) ---\n", len(plan.Files))
+	for _, f := range plan.Files {
+		switch f.Action {
+		case "add":
+			ui.Green.Printf("  + %s\n", f.Path)
+		case "remove":
+			ui.Red.Printf("  - %s\n", f.Path)
+		default:
+			ui.Yellow.Printf("  ~ %s\n", f.Path)
+		}
+	}
+
+	fmt.Println()
+	ui.Blue.Println("--- Services ---")
+	recreated := 0
+	for _, s := range plan.Services {
+		switch s.Action {
+		case "create":
+			ui.Green.Printf("  + %s/%s would be created\n", s.Stack, s.Service)
+		case "remove":
+			ui.Red.Printf("  - %s/%s would be removed\n", s.Stack, s.Service)
+		default:
+			recreated++
+			ui.Yellow.Printf("  ~ %s/%s would be recreated (%s changed)\n", s.Stack, s.Service, strings.Join(s.Fields, ", "))
+		}
+	}
+	if len(plan.Services) == 0 {
+		fmt.Println("  No service definitions changed")
+	}
+
+	if len(plan.LintErrors) > 0 {
+		fmt.Println()
+		ui.Blue.Println("--- Lint ---")
+		for _, e := range plan.LintErrors {
+			ui.Red.Printf("  x %s\n", e)
+		}
+	}
+
+	fmt.Println()
+	ui.Blue.Println("--- Deploy actions ---")
+	for _, a := range plan.Actions {
+		fmt.Printf("  %s\n", a)
+	}
+
+	fmt.Println()
+	fmt.Printf("Summary: %d rendered files changed, %d services recreated, %d lint errors\n", len(plan.Files), recreated, len(plan.LintErrors))
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
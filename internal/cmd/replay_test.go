@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "replay", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "replay [fixture]")
+	assert.Contains(t, output, "last-reconcile.yml")
+}
+
+func TestRunReplay_MissingFixture(t *testing.T) {
+	err := runReplay(replayCmd, []string{filepath.Join(t.TempDir(), "missing.yml")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read fixture")
+}
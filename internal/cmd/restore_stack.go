@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var restoreStack string
+
+// doRestoreStack restores only the appdata paths a backup holds for one
+// stack, then restarts that stack.
+func doRestoreStack(backupDir, backupName, stack string) error {
+	backupPath := filepath.Join(backupDir, backupName)
+	if _, _, ok := reconcile.BackupArchivePath(backupPath); !ok {
+		return fmt.Errorf("backup incomplete: configs.tar.gz not found in %s", backupName)
+	}
+
+	index, err := reconcile.ReadBackupIndex(backupPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("backup %s has no stack index (it predates per-stack backups); restore it without --stack", backupName)
+	}
+	if err != nil {
+		return fmt.Errorf("read backup index: %w", err)
+	}
+	if _, ok := index.Stacks[stack]; !ok {
+		return fmt.Errorf("stack %s is not in backup %s (has: %s)", stack, backupName, strings.Join(index.StackNames(), ", "))
+	}
+
+	targetDir := getAppdataDir()
+	if targetDir == "" {
+		return fmt.Errorf("could not determine appdata directory")
+	}
+
+	ui.Yellow.Printf("Restoring stack %s from backup: %s\n", stack, backupName)
+	fmt.Println()
+
+	stagingDir, err := os.MkdirTemp("", "bosun-restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	ui.Info("  Extracting backup...")
+	if err := extractBackupArchive(backupPath, stagingDir); err != nil {
+		return fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	ui.Info("  Deploying restored configs...")
+	restored, err := restoreStackPaths(stagingDir, targetDir, index, stack)
+	if err != nil {
+		return fmt.Errorf("failed to deploy restored configs: %w", err)
+	}
+	for _, p := range restored {
+		fmt.Printf("    - %s\n", p)
+	}
+
+	composeFile := filepath.Join(targetDir, "compose", stack+".yml")
+	if _, err := os.Stat(composeFile); err == nil {
+		ui.Info("  Restarting %s...", stack)
+		if err := runComposeUp(composeFile); err != nil {
+			ui.Warning("Could not restart services: %v", err)
+			ui.Yellow.Println("  Run 'docker compose -p " + reconcile.ComposeProjectName(composeFile) + " -f " + composeFile + " up -d' manually")
+		} else if err := verifyRestoredHealth(composeFile); err != nil {
+			return err
+		}
+	}
+
+	ui.Success("Restore of %s complete!", stack)
+	return nil
+}
+
+// restoreStackPaths copies a stack's paths from an extracted backup in
+// stagingDir into targetDir, the local appdata root. Paths missing from the
+// archive (configs that did not exist at backup time) are skipped. Returns
+// the paths restored.
+func restoreStackPaths(stagingDir, targetDir string, index *reconcile.BackupIndex, stack string) ([]string, error) {
+	var restored []string
+	for _, p := range index.Stacks[stack] {
+		src := filepath.Join(stagingDir, filepath.FromSlash(index.ArchivePath(p)))
+		info, err := os.Stat(src)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return restored, err
+		}
+
+		dst := filepath.Join(targetDir, filepath.FromSlash(p))
+		if info.IsDir() {
+			err = fileutil.CopyDir(src, dst)
+		} else {
+			err = fileutil.CopyFile(src, dst)
+		}
+		if err != nil {
+			return restored, fmt.Errorf("restore %s: %w", p, err)
+		}
+		restored = append(restored, p)
+	}
+	return restored, nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+)
+
+func TestRestoreStackPaths(t *testing.T) {
+	stagingDir := t.TempDir()
+	archived := filepath.Join(stagingDir, "mnt", "appdata")
+	require.NoError(t, os.MkdirAll(filepath.Join(archived, "myapp", "conf.d"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(archived, "myapp", "conf.d", "a.conf"), []byte("a"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(archived, "other"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(archived, "other", "config.yaml"), []byte("other"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(archived, "settings.yml"), []byte("s"), 0644))
+
+	index := &reconcile.BackupIndex{
+		Appdata: "/mnt/appdata",
+		Stacks: map[string][]string{
+			"apps":  {"myapp", "settings.yml", "missing"},
+			"other": {"other/config.yaml"},
+		},
+	}
+
+	targetDir := t.TempDir()
+	restored, err := restoreStackPaths(stagingDir, targetDir, index, "apps")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"myapp", "settings.yml"}, restored)
+	assert.FileExists(t, filepath.Join(targetDir, "myapp", "conf.d", "a.conf"))
+	assert.FileExists(t, filepath.Join(targetDir, "settings.yml"))
+	assert.NoFileExists(t, filepath.Join(targetDir, "other", "config.yaml"), "other stacks are left alone")
+}
+
+func TestDoRestoreStack_RequiresIndex(t *testing.T) {
+	backupDir := t.TempDir()
+	backupPath := filepath.Join(backupDir, "backup-20240101-000000")
+	require.NoError(t, os.MkdirAll(backupPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(backupPath, "configs.tar.gz"), []byte("x"), 0644))
+
+	err := doRestoreStack(backupDir, "backup-20240101-000000", "apps")
+	assert.ErrorContains(t, err, "no stack index")
+
+	require.NoError(t, reconcile.WriteBackupIndex(backupPath, &reconcile.BackupIndex{
+		Appdata: "/mnt/appdata",
+		Stacks:  map[string][]string{"core": {"traefik"}},
+	}))
+	err = doRestoreStack(backupDir, "backup-20240101-000000", "apps")
+	assert.ErrorContains(t, err, "not in backup")
+	assert.ErrorContains(t, err, "has: core")
+}
@@ -0,0 +1,508 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DR verification settings.
+const (
+	// DefaultVerifyPortOffset shifts published ports when booting a restored stack.
+	DefaultVerifyPortOffset = 10000
+	// VerifyBootTimeout bounds how long a restored stack may take to become healthy.
+	VerifyBootTimeout = 3 * time.Minute
+	// StaleBackupAge is the backup age after which DR readiness is degraded.
+	StaleBackupAge = 7 * 24 * time.Hour
+)
+
+var (
+	restoreVerify     bool
+	restoreBoot       bool
+	restorePortOffset int
+	restoreKeep       bool
+)
+
+// drStatus is the outcome of one DR verification check.
+type drStatus int
+
+const (
+	drPass drStatus = iota
+	drWarn
+	drFail
+)
+
+// drCheck is one line in a DR verification report.
+type drCheck struct {
+	Name   string
+	Status drStatus
+	Detail string
+}
+
+// drReport collects DR verification checks for a backup.
+type drReport struct {
+	Backup string
+	Checks []drCheck
+}
+
+func (r *drReport) add(name string, status drStatus, detail string) {
+	r.Checks = append(r.Checks, drCheck{Name: name, Status: status, Detail: detail})
+
+	line := name
+	if detail != "" {
+		line += ": " + detail
+	}
+	switch status {
+	case drPass:
+		ui.Green.Printf("  * %s\n", line)
+	case drWarn:
+		ui.Yellow.Printf("  ! %s\n", line)
+	case drFail:
+		ui.Red.Printf("  x %s\n", line)
+	}
+}
+
+// Readiness summarizes the report: READY, DEGRADED, or NOT READY.
+func (r *drReport) Readiness() string {
+	worst := drPass
+	for _, c := range r.Checks {
+		if c.Status > worst {
+			worst = c.Status
+		}
+	}
+	switch worst {
+	case drFail:
+		return "NOT READY"
+	case drWarn:
+		return "DEGRADED"
+	default:
+		return "READY"
+	}
+}
+
+// runRestoreVerify restores a backup into a throwaway directory and checks
+// that it is usable, without touching live appdata.
+func runRestoreVerify(backupDir, backupName string) error {
+	backups, err := getBackups(backupDir)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backup *BackupInfo
+	for i := range backups {
+		if backupName == "" || backups[i].Name == backupName {
+			backup = &backups[i]
+			break
+		}
+	}
+	if backup == nil {
+		if backupName != "" {
+			return fmt.Errorf("backup not found: %s", backupName)
+		}
+		return fmt.Errorf("no backups found in %s", backupDir)
+	}
+
+	ui.Header("DR verification: %s", backup.Name)
+	report := &drReport{Backup: backup.Name}
+
+	ui.Blue.Println("--- Backup ---")
+	checkBackupAge(report, backup)
+
+	if !backup.HasTar {
+		report.add("Archive", drFail, "backup archive missing")
+		return finishDRReport(report)
+	}
+
+	workDir, err := os.MkdirTemp("", "bosun-dr-verify-*")
+	if err != nil {
+		return fmt.Errorf("failed to create throwaway directory: %w", err)
+	}
+	if restoreKeep {
+		defer ui.Info("Restored files kept in %s", workDir)
+	} else {
+		defer os.RemoveAll(workDir)
+	}
+
+	restoredDir := filepath.Join(workDir, "restored")
+	if err := extractBackupArchive(backup.Path, restoredDir); err != nil {
+		report.add("Extract", drFail, err.Error())
+		return finishDRReport(report)
+	}
+
+	files, err := listRestoredFiles(restoredDir)
+	if err != nil || len(files) == 0 {
+		report.add("Extract", drFail, "archive contains no files")
+		return finishDRReport(report)
+	}
+	report.add("Extract", drPass, fmt.Sprintf("%d files", len(files)))
+
+	fmt.Println()
+	ui.Blue.Println("--- Validate ---")
+	composeFiles := validateRestoredFiles(report, restoredDir, files)
+
+	if restoreBoot {
+		fmt.Println()
+		ui.Blue.Println("--- Boot ---")
+		if len(composeFiles) == 0 {
+			report.add("Boot", drWarn, "no compose files in backup")
+		} else if _, err := exec.LookPath("docker"); err != nil {
+			report.add("Boot", drFail, "docker not found in PATH")
+		} else {
+			for _, f := range composeFiles {
+				bootRestoredCompose(report, workDir, restoredDir, f)
+			}
+		}
+	}
+
+	return finishDRReport(report)
+}
+
+// finishDRReport prints the readiness summary and fails on NOT READY.
+func finishDRReport(report *drReport) error {
+	readiness := report.Readiness()
+	fmt.Println()
+	switch readiness {
+	case "READY":
+		ui.Success("DR readiness: %s", readiness)
+	case "DEGRADED":
+		ui.Warning("DR readiness: %s", readiness)
+	default:
+		ui.Error("DR readiness: %s", readiness)
+		return fmt.Errorf("backup %s is not restorable", report.Backup)
+	}
+	return nil
+}
+
+// checkBackupAge warns when the newest restorable state is old.
+func checkBackupAge(report *drReport, backup *BackupInfo) {
+	// Backup names carry their creation time in UTC.
+	created, err := time.Parse("20060102-150405", strings.TrimPrefix(backup.Name, "backup-"))
+	if err != nil {
+		var info os.FileInfo
+		if info, err = os.Stat(backup.Path); err == nil {
+			created = info.ModTime()
+		}
+	}
+	if err != nil {
+		report.add("Age", drWarn, "could not determine backup time")
+		return
+	}
+
+	age := time.Since(created)
+	detail := formatAge(age) + " old"
+	if age > StaleBackupAge {
+		report.add("Age", drWarn, detail+" (stale)")
+		return
+	}
+	report.add("Age", drPass, detail)
+}
+
+// formatAge renders a coarse duration like "3h" or "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// listRestoredFiles returns regular files under dir, relative to it.
+func listRestoredFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// validateRestoredFiles parses every YAML file in the restore and returns
+// those that are compose files. Compose files are also checked with
+// 'docker compose config' when docker is available.
+func validateRestoredFiles(report *drReport, dir string, files []string) []string {
+	var composeFiles []string
+	parsed := 0
+
+	for _, rel := range files {
+		ext := strings.ToLower(filepath.Ext(rel))
+		if ext != ".yml" && ext != ".yaml" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			report.add(rel, drFail, err.Error())
+			continue
+		}
+
+		var doc map[string]any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			report.add(rel, drFail, err.Error())
+			continue
+		}
+		parsed++
+
+		if _, ok := doc["services"].(map[string]any); ok {
+			composeFiles = append(composeFiles, rel)
+		}
+	}
+
+	report.add("YAML", drPass, fmt.Sprintf("%d files parsed", parsed))
+
+	if len(composeFiles) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		report.add("Compose", drWarn, "docker not found, compose files not validated")
+		return composeFiles
+	}
+
+	for _, rel := range composeFiles {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		out, err := exec.CommandContext(ctx, "docker", "compose", "-f", filepath.Join(dir, rel), "config", "--quiet").CombinedOutput()
+		cancel()
+		if err != nil {
+			report.add(rel, drFail, strings.TrimSpace(string(out)))
+			continue
+		}
+		report.add(rel, drPass, "docker compose config")
+	}
+
+	return composeFiles
+}
+
+// bootRestoredCompose starts a restored compose file as a temporary project
+// on shifted ports, waits for it to come up, and tears it down.
+func bootRestoredCompose(report *drReport, workDir, restoredDir, rel string) {
+	name := "Boot " + rel
+
+	data, err := os.ReadFile(filepath.Join(restoredDir, rel))
+	if err != nil {
+		report.add(name, drFail, err.Error())
+		return
+	}
+	var compose map[string]any
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		report.add(name, drFail, err.Error())
+		return
+	}
+
+	if err := isolateCompose(compose, restorePortOffset, restoredDir, filepath.Join(workDir, "data")); err != nil {
+		report.add(name, drFail, err.Error())
+		return
+	}
+
+	out, err := yaml.Marshal(compose)
+	if err != nil {
+		report.add(name, drFail, err.Error())
+		return
+	}
+	composePath := filepath.Join(workDir, "verify-"+strings.ReplaceAll(rel, string(os.PathSeparator), "-"))
+	if err := os.WriteFile(composePath, out, 0600); err != nil {
+		report.add(name, drFail, err.Error())
+		return
+	}
+
+	project := fmt.Sprintf("bosun-dr-verify-%d", time.Now().Unix())
+	defer func() {
+		down := exec.Command("docker", "compose", "-p", project, "-f", composePath, "down", "--volumes", "--remove-orphans")
+		if out, err := down.CombinedOutput(); err != nil {
+			ui.Warning("Teardown of %s failed: %v: %s", project, err, strings.TrimSpace(string(out)))
+			ui.Yellow.Printf("  Run 'docker compose -p %s down -v' manually\n", project)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), VerifyBootTimeout+30*time.Second)
+	defer cancel()
+	up := exec.CommandContext(ctx, "docker", "compose", "-p", project, "-f", composePath,
+		"up", "-d", "--wait", "--wait-timeout", strconv.Itoa(int(VerifyBootTimeout.Seconds())))
+	if out, err := up.CombinedOutput(); err != nil {
+		report.add(name, drFail, lastLine(string(out)))
+		return
+	}
+
+	report.add(name, drPass, fmt.Sprintf("project %s healthy (ports +%d)", project, restorePortOffset))
+}
+
+// isolateCompose rewrites a compose file so it can run next to the live
+// stack: published ports are shifted, container names and networks are
+// dropped, Traefik routing is disabled, and absolute bind mounts point at
+// the restored copy (or an empty dir under dataDir) instead of live appdata.
+func isolateCompose(compose map[string]any, portOffset int, restoredDir, dataDir string) error {
+	services, _ := compose["services"].(map[string]any)
+	for svcName, raw := range services {
+		svc, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		delete(svc, "container_name")
+		delete(svc, "networks")
+		if mode, _ := svc["network_mode"].(string); strings.HasPrefix(mode, "container:") {
+			delete(svc, "network_mode")
+		}
+
+		if ports, ok := svc["ports"].([]any); ok {
+			for i, p := range ports {
+				shifted, err := shiftPublishedPort(p, portOffset)
+				if err != nil {
+					return fmt.Errorf("service %s: %w", svcName, err)
+				}
+				ports[i] = shifted
+			}
+		}
+
+		svc["labels"] = disableTraefikLabels(svc["labels"])
+
+		if volumes, ok := svc["volumes"].([]any); ok {
+			for i, v := range volumes {
+				volumes[i] = redirectBindMount(v, restoredDir, dataDir)
+			}
+		}
+	}
+
+	delete(compose, "networks")
+	return nil
+}
+
+// shiftPublishedPort adds offset to the host side of a compose port entry.
+// Entries without a host port are left alone.
+func shiftPublishedPort(entry any, offset int) (any, error) {
+	switch p := entry.(type) {
+	case string:
+		proto := ""
+		if i := strings.Index(p, "/"); i >= 0 {
+			p, proto = p[:i], p[i:]
+		}
+		parts := strings.Split(p, ":")
+		if len(parts) < 2 {
+			return entry, nil
+		}
+		hostIdx := len(parts) - 2
+		shifted, err := shiftPortRange(parts[hostIdx], offset)
+		if err != nil {
+			return nil, err
+		}
+		parts[hostIdx] = shifted
+		return strings.Join(parts, ":") + proto, nil
+	case map[string]any:
+		published, ok := p["published"]
+		if !ok {
+			return entry, nil
+		}
+		shifted, err := shiftPortRange(fmt.Sprint(published), offset)
+		if err != nil {
+			return nil, err
+		}
+		p["published"] = shifted
+		return p, nil
+	default:
+		return entry, nil
+	}
+}
+
+// shiftPortRange shifts "8080" or "8000-8010" by offset.
+func shiftPortRange(s string, offset int) (string, error) {
+	bounds := strings.Split(s, "-")
+	for i, b := range bounds {
+		port, err := strconv.Atoi(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid port %q", s)
+		}
+		if port+offset > 65535 {
+			return "", fmt.Errorf("port %d + offset %d exceeds 65535", port, offset)
+		}
+		bounds[i] = strconv.Itoa(port + offset)
+	}
+	return strings.Join(bounds, "-"), nil
+}
+
+// disableTraefikLabels drops traefik.* labels and sets traefik.enable=false
+// so a live Traefik doesn't route traffic to the verification containers.
+func disableTraefikLabels(labels any) map[string]any {
+	result := map[string]any{}
+	switch l := labels.(type) {
+	case map[string]any:
+		for k, v := range l {
+			result[k] = v
+		}
+	case []any:
+		for _, entry := range l {
+			k, v, _ := strings.Cut(fmt.Sprint(entry), "=")
+			result[k] = v
+		}
+	}
+	for k := range result {
+		if strings.HasPrefix(k, "traefik.") {
+			delete(result, k)
+		}
+	}
+	result["traefik.enable"] = "false"
+	return result
+}
+
+// isDockerSocket reports whether src is a Docker daemon socket, rootful
+// (/var/run/docker.sock) or rootless ($XDG_RUNTIME_DIR/docker.sock).
+func isDockerSocket(src string) bool {
+	return filepath.Base(src) == "docker.sock"
+}
+
+// redirectBindMount points an absolute bind mount source at its restored
+// copy when the backup contains it, or at an empty path under dataDir.
+// The Docker socket is left alone.
+func redirectBindMount(entry any, restoredDir, dataDir string) any {
+	redirect := func(src string) string {
+		// Archives store absolute paths without the leading slash
+		if restored := filepath.Join(restoredDir, src); pathExists(restored) {
+			return restored
+		}
+		return filepath.Join(dataDir, src)
+	}
+
+	switch v := entry.(type) {
+	case string:
+		src, rest, ok := strings.Cut(v, ":")
+		if !ok || !filepath.IsAbs(src) || isDockerSocket(src) {
+			return entry
+		}
+		return redirect(src) + ":" + rest
+	case map[string]any:
+		src, _ := v["source"].(string)
+		if v["type"] == "bind" && filepath.IsAbs(src) && !isDockerSocket(src) {
+			v["source"] = redirect(src)
+		}
+		return v
+	default:
+		return entry
+	}
+}
+
+// pathExists reports whether path exists.
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// lastLine returns the last non-empty line of command output.
+func lastLine(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return lines[len(lines)-1]
+}
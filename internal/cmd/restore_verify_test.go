@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/seal"
+)
+
+// writeTestBackup creates backupDir/<name>/configs.tar.gz holding files.
+func writeTestBackup(t *testing.T, backupDir, name string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(backupDir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	f, err := os.Create(filepath.Join(dir, "configs.tar.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	for path, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: path, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+}
+
+// encryptTestBackup replaces a backup's archive with its age-encrypted form.
+func encryptTestBackup(t *testing.T, backupPath string, key *seal.Key) {
+	t.Helper()
+	plain := filepath.Join(backupPath, reconcile.BackupArchive)
+	data, err := os.ReadFile(plain)
+	require.NoError(t, err)
+
+	f, err := os.Create(filepath.Join(backupPath, reconcile.EncryptedBackupArchive))
+	require.NoError(t, err)
+	defer f.Close()
+	w, err := key.Encrypt(f)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, os.Remove(plain))
+}
+
+func backupName(age time.Duration) string {
+	return "backup-" + time.Now().UTC().Add(-age).Format("20060102-150405")
+}
+
+func TestRunRestoreVerify(t *testing.T) {
+	t.Run("valid backup is restorable", func(t *testing.T) {
+		backupDir := t.TempDir()
+		writeTestBackup(t, backupDir, backupName(time.Hour), map[string]string{
+			"mnt/appdata/traefik/dynamic.yml": "http:\n  routers: {}\n",
+			"mnt/appdata/gatus/config.yaml":   "endpoints: []\n",
+		})
+
+		assert.NoError(t, runRestoreVerify(backupDir, ""))
+	})
+
+	t.Run("corrupt config is not restorable", func(t *testing.T) {
+		backupDir := t.TempDir()
+		writeTestBackup(t, backupDir, backupName(time.Hour), map[string]string{
+			"mnt/appdata/gatus/config.yaml": "endpoints: [\n",
+		})
+
+		err := runRestoreVerify(backupDir, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not restorable")
+	})
+
+	t.Run("picks the newest backup", func(t *testing.T) {
+		backupDir := t.TempDir()
+		writeTestBackup(t, backupDir, backupName(48*time.Hour), map[string]string{"a.yml": "broken: [\n"})
+		writeTestBackup(t, backupDir, backupName(time.Hour), map[string]string{"a.yml": "ok: true\n"})
+
+		assert.NoError(t, runRestoreVerify(backupDir, ""))
+	})
+
+	t.Run("encrypted backup is restorable with the age key", func(t *testing.T) {
+		id, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		key, err := seal.ParseKey(strings.NewReader(id.String()))
+		require.NoError(t, err)
+
+		backupDir := t.TempDir()
+		name := backupName(time.Hour)
+		writeTestBackup(t, backupDir, name, map[string]string{"mnt/appdata/gatus/config.yaml": "endpoints: []\n"})
+		encryptTestBackup(t, filepath.Join(backupDir, name), key)
+
+		t.Setenv("SOPS_AGE_KEY", id.String())
+		assert.NoError(t, runRestoreVerify(backupDir, ""))
+
+		other, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		t.Setenv("SOPS_AGE_KEY", other.String())
+		assert.Error(t, runRestoreVerify(backupDir, ""))
+	})
+
+	t.Run("missing archive", func(t *testing.T) {
+		backupDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(backupDir, backupName(time.Hour)), 0755))
+
+		assert.Error(t, runRestoreVerify(backupDir, ""))
+	})
+
+	t.Run("no backups", func(t *testing.T) {
+		err := runRestoreVerify(t.TempDir(), "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no backups found")
+	})
+}
+
+func TestDRReport_Readiness(t *testing.T) {
+	report := &drReport{}
+	report.add("a", drPass, "")
+	assert.Equal(t, "READY", report.Readiness())
+
+	report.add("b", drWarn, "stale")
+	assert.Equal(t, "DEGRADED", report.Readiness())
+
+	report.add("c", drFail, "broken")
+	assert.Equal(t, "NOT READY", report.Readiness())
+}
+
+func TestCheckBackupAge(t *testing.T) {
+	report := &drReport{}
+	checkBackupAge(report, &BackupInfo{Name: backupName(10 * 24 * time.Hour)})
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, drWarn, report.Checks[0].Status)
+	assert.Contains(t, report.Checks[0].Detail, "stale")
+}
+
+func TestShiftPublishedPort(t *testing.T) {
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{"8080:80", "18080:80"},
+		{"127.0.0.1:53:53/udp", "127.0.0.1:10053:53/udp"},
+		{"80", "80"},
+		{"8000-8010:8000-8010", "18000-18010:8000-8010"},
+		{map[string]any{"target": 80, "published": 8443}, map[string]any{"target": 80, "published": "18443"}},
+	}
+	for _, tt := range tests {
+		got, err := shiftPublishedPort(tt.in, 10000)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%v", tt.in)
+	}
+
+	_, err := shiftPublishedPort("60000:80", 10000)
+	assert.Error(t, err)
+}
+
+func TestIsolateCompose(t *testing.T) {
+	restoredDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(restoredDir, "mnt", "appdata", "traefik"), 0755))
+
+	compose := map[string]any{
+		"services": map[string]any{
+			"traefik": map[string]any{
+				"image":          "traefik:v3",
+				"container_name": "traefik",
+				"ports":          []any{"443:443"},
+				"networks":       []any{"proxy"},
+				"labels":         []any{"traefik.enable=true", "com.example.team=infra"},
+				"volumes": []any{
+					"/mnt/appdata/traefik:/etc/traefik",
+					"/mnt/appdata/acme:/acme",
+					"/var/run/docker.sock:/var/run/docker.sock:ro",
+					"/run/user/1000/docker.sock:/var/run/docker.sock",
+					"certs:/certs",
+				},
+			},
+		},
+		"networks": map[string]any{"proxy": map[string]any{"external": true}},
+	}
+
+	require.NoError(t, isolateCompose(compose, 10000, restoredDir, "/tmp/dr/data"))
+
+	svc := compose["services"].(map[string]any)["traefik"].(map[string]any)
+	assert.NotContains(t, svc, "container_name")
+	assert.NotContains(t, svc, "networks")
+	assert.NotContains(t, compose, "networks")
+	assert.Equal(t, []any{"10443:443"}, svc["ports"])
+	assert.Equal(t, map[string]any{"traefik.enable": "false", "com.example.team": "infra"}, svc["labels"])
+	assert.Equal(t, []any{
+		filepath.Join(restoredDir, "mnt", "appdata", "traefik") + ":/etc/traefik",
+		"/tmp/dr/data/mnt/appdata/acme:/acme",
+		"/var/run/docker.sock:/var/run/docker.sock:ro",
+		"/run/user/1000/docker.sock:/var/run/docker.sock",
+		"certs:/certs",
+	}, svc["volumes"])
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	rollbackDryRun bool
+	rollbackForce  bool
+	rollbackLocal  bool
+	rollbackRemote string
+)
+
+// rollbackCmd redeploys the repository as of an earlier git revision.
+var rollbackCmd = &cobra.Command{
+	Use:     "rollback <commit|tag>",
+	Aliases: []string{"astern"},
+	Short:   "Redeploy the repository as of a git commit or tag",
+	Long: `Rollback deploys the repository as it was at a commit, tag, or branch.
+
+Where 'mayday --rollback' restores rendered output snapshots, rollback goes
+back to git, the source of truth. The revision is checked out into a
+staging tree, its secrets decrypted and templates rendered, then it is
+linted, backed up, and deployed through the same pipeline as a reconcile.
+The deploy history records it with the source "rollback:<ref>", so
+'bosun log' shows the rollback.
+
+The tracking clone stays on its branch. Revert or fix the bad commit on the
+branch before the next push, or the next reconcile deploys over the
+rollback.
+
+Configuration comes from the same environment variables as 'bosun reconcile'.
+
+Examples:
+  bosun rollback v1.4.2            # Redeploy a tag
+  bosun rollback 3f9c2e1 -n        # Show what would be deployed
+  bosun rollback v1.4.2 --force    # Redeploy every stack, not just the changed ones`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRollback,
+}
+
+func init() {
+	rollbackCmd.Flags().BoolVarP(&rollbackDryRun, "dry-run", "n", false, "Show what would be done without making changes")
+	rollbackCmd.Flags().BoolVarP(&rollbackForce, "force", "f", false, "Deploy every stack, not just those that differ from the deployed commit")
+	rollbackCmd.Flags().BoolVarP(&rollbackLocal, "local", "l", false, "Force local deployment mode")
+	rollbackCmd.Flags().StringVarP(&rollbackRemote, "remote", "r", "", "Target host for remote deployment (e.g., root@192.168.1.8)")
+
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	ref := args[0]
+	if err := reconcile.ValidateRef(ref); err != nil {
+		return err
+	}
+
+	cfg := reconcileConfigFromEnv()
+	if rollbackRemote != "" {
+		cfg.TargetHost = rollbackRemote
+	}
+	if rollbackLocal {
+		cfg.TargetHost = ""
+	}
+	if rollbackDryRun {
+		cfg.DryRun = true
+	}
+	if rollbackForce {
+		cfg.Force = true
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	opts := []reconcile.ReconcilerOption{}
+	if alerter := createAlertManager(); alerter != nil {
+		opts = append(opts, reconcile.WithAlerter(alerter))
+	}
+
+	ui.Yellow.Printf("Rolling back to %s\n", ref)
+	if err := reconcile.Rollback(ctx, cfg, ref, opts...); err != nil {
+		return fmt.Errorf("rollback to %s failed: %w", ref, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "rollback", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "rollback <commit|tag>")
+	assert.Contains(t, output, "rollback:<ref>")
+}
+
+func TestRollbackCmd_Aliases(t *testing.T) {
+	_, err := executeCmd(t, "astern", "--help")
+	assert.NoError(t, err)
+}
+
+func TestRollbackCmd_RequiresRef(t *testing.T) {
+	assert.Error(t, rollbackCmd.Args(rollbackCmd, nil))
+	assert.NoError(t, rollbackCmd.Args(rollbackCmd, []string{"v1.4.2"}))
+}
+
+func TestRollbackCmd_RejectsInvalidRef(t *testing.T) {
+	err := runRollback(rollbackCmd, []string{"--upload-pack=evil"})
+	assert.Error(t, err)
+}
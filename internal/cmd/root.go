@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/cameronsjo/bosun/internal/config"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -17,6 +18,15 @@ var (
 	date    = "unknown"
 )
 
+// projectFlag selects a project in a bosun.workspaces.yml workspace.
+var projectFlag string
+
+// colorFlag controls colored output: auto, always, or never.
+var colorFlag string
+
+// logFormatFlag selects the output format: text or json.
+var logFormatFlag string
+
 // rootCmd represents the base command when called without any subcommands.
 var rootCmd = &cobra.Command{
 	Use:   "bosun",
@@ -29,11 +39,19 @@ with Traefik, Gatus, and Homepage integration.
 SETUP
   init                  Christen your yacht (interactive setup wizard)
     --systemd           Generate systemd unit files for daemon mode
+  config export         Write a portable config bundle for another host
+  config import <file>  Apply a config bundle on this host
+  config seal           Encrypt alert credentials in config files
+  secrets rotate        Re-encrypt SOPS files for a new age key
+    --key-file <file>   New age key (required)
+    --commit            Commit the rotated files
 
 DAEMON COMMANDS
   daemon                Run the GitOps daemon (long-running service)
   trigger               Trigger reconciliation via daemon
   daemon-status         Show daemon status
+  deploy-window         Check whether it is safe to deploy (exit 1 if not)
+  maintenance on|off    Show a maintenance page instead of services
   webhook               Run standalone webhook receiver
   validate              Validate configuration and connectivity
     --full              Run full dry-run reconciliation
@@ -46,17 +64,34 @@ YACHT COMMANDS
 
 CREW COMMANDS
   crew list             Show all hands on deck (docker ps)
+  crew ls               Long listing: sort, filter, --wide, --json
   crew logs [name]      Tail crew member logs
   crew inspect [name]   Detailed crew info
   crew restart [name]   Send crew member for coffee break
+  logs <target>         Stream logs from a container, service, or stack
+    -f, --tail, --since, -t
+  exec <target> [cmd]   Run a command (default: sh) in a container or service
+    -i, -t, -u, -w, -e
 
 MANIFEST COMMANDS
   provision [stack]     Render manifest to compose/traefik/gatus
     --dry-run, -n       Show what would be generated without writing
     --diff, -d          Show diff against existing output files
     --values, -f <file> Apply values overlay (e.g., prod.yaml)
+  diff [stack...]       Diff rendered manifests against deployed appdata
   provisions            List available provisions
   create <tmpl> <name>  Scaffold new service (webapp, api, worker, static)
+  docs [stack]          Generate markdown docs for services
+  search <term>         Search manifests and rendered outputs
+  vars [provision]      List the variables each provision consumes
+  bump <svc> <tag>      Update a service's image tag (lint + render diff, --pr)
+  build [service]       Build images for services with a build context
+  verify [stack]        Run manifest smoke tests against deployed services
+  stacks discover       List compose projects, bosun-managed and foreign
+  stacks adopt <proj>   Import a foreign compose project as a stack
+  export k8s <name>     Export a service or stack as Kubernetes manifests
+  pin <stack> <ref>     Pin a stack to a git commit or tag
+  unpin <stack>         Resume tracking the branch for a stack
 
 TEMPLATE COMMANDS
   render [files...]     Render .tmpl files with SOPS secrets
@@ -69,27 +104,46 @@ COMMS COMMANDS
 
 ALERT COMMANDS
   alert status          Show configured alert providers
-  alert test            Send test alert to providers
+  alert test            Dry-run a synthetic alert through the alert manager
+    --event, -e         Event to simulate (test, drift, reconcile_failure, digest)
+    --send              Actually deliver the alert
     --provider, -p      Test specific provider (discord, sendgrid, twilio)
     --message, -m       Custom test message
-    --severity, -s      Alert severity (info, warning, error)
+    --severity, -s      Override alert severity (info, warning, error)
 
 DIAGNOSTICS
   status                Show yacht health dashboard
   log [n]               Show release history
   drift                 Detect config drift - git vs running state
+  replay [fixture]      Replay the last reconcile and show its plan
   doctor                Pre-flight checks - is the ship seaworthy?
   lint                  Validate all manifests before deploy
+  ports                 List claimed ports
+    --free              Suggest free ports, probing the host
+  graph                 Draw the service dependency graph (DOT or Mermaid)
+  stats                 Live container CPU, memory, network, and disk I/O
 
 EMERGENCY
   mayday                Show recent errors across all crew
     --rollback, -r      Rollback to a previous snapshot
     --list, -l          List available snapshots
+  snapshot pin <name>   Keep a snapshot regardless of retention
+    --note              Why the snapshot is pinned
+  snapshot unpin <name> Return a snapshot to the retention policy
   overboard [name]      Force remove a problematic container
+  restore [name]        Restore configs from a reconcile backup
+    --verify            Prove the newest backup is restorable
+  rollback <ref>        Redeploy the repository as of a git commit or tag
 
 MAINTENANCE
   update                Update bosun to the latest version
-    --check             Only check for updates, don't install`,
+    --check             Only check for updates, don't install
+  completion install    Install shell completion for your shell
+
+GLOBAL FLAGS
+  --project <name>      Operate on one project of a bosun.workspaces.yml workspace
+  --color <mode>        Colorize output: auto, always, or never (honors NO_COLOR)
+  --log-format <fmt>    Output format: text, or json for log shippers (env: BOSUN_LOG_FORMAT)`,
 	Version: version,
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
@@ -108,25 +162,48 @@ var yarrCmd = &cobra.Command{
 		fmt.Println("  init       → christen")
 		fmt.Println("  yacht      → hoist")
 		fmt.Println("  crew       → scallywags")
+		fmt.Println("  logs       → scuttlebutt")
+		fmt.Println("  exec       → board")
 		fmt.Println("  provision  → plunder")
 		fmt.Println("  provisions → loot")
 		fmt.Println("  create     → forge")
+		fmt.Println("  docs       → logbook")
+		fmt.Println("  search     → spyglass")
+		fmt.Println("  vars       → cargo")
+		fmt.Println("  bump       → refit")
+		fmt.Println("  build      → shipwright")
+		fmt.Println("  verify     → soundings")
+		fmt.Println("  stacks     → fleet")
+		fmt.Println("  export     → offload")
+		fmt.Println("  config     → papers")
+		fmt.Println("  secrets    → strongbox")
 		fmt.Println("  radio      → parrot")
 		fmt.Println("  alert      → horn")
 		fmt.Println("  status     → bridge")
 		fmt.Println("  log        → ledger")
 		fmt.Println("  drift      → compass")
+		fmt.Println("  replay     → wake")
+		fmt.Println("  ports      → berths")
+		fmt.Println("  graph      → chart")
+		fmt.Println("  stats      → gauges")
 		fmt.Println("  doctor     → checkup")
 		fmt.Println("  lint       → inspect")
 		fmt.Println("  mayday     → mutiny")
 		fmt.Println("  overboard  → plank")
+		fmt.Println("  rollback   → astern")
+		fmt.Println("  pin        → anchor")
+		fmt.Println("  unpin      → weigh")
+		fmt.Println("  deploy-window → tide")
+		fmt.Println("  maintenance → drydock")
 		fmt.Println("")
 		ui.Blue.Println("Run 'bosun --help' for all commands.")
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// User-defined aliases from the project config are expanded first.
 func Execute() {
+	rootCmd.SetArgs(executeArgs())
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -136,6 +213,21 @@ func init() {
 	// Add hidden yarr command
 	rootCmd.AddCommand(yarrCmd)
 
+	// Workspace project selection applies to every command that finds the project root
+	rootCmd.PersistentFlags().StringVar(&projectFlag, "project", "", "Workspace project to operate on (env: BOSUN_PROJECT)")
+	rootCmd.PersistentFlags().StringVar(&colorFlag, "color", ui.ColorAuto, "Colorize output: auto, always, or never (NO_COLOR disables auto)")
+	rootCmd.PersistentFlags().StringVar(&logFormatFlag, "log-format", "", "Output format: text or json (env: BOSUN_LOG_FORMAT)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		config.SelectProject(projectFlag)
+		if err := ui.SetColorMode(colorFlag); err != nil {
+			return err
+		}
+		if logFormatFlag == "" {
+			logFormatFlag = os.Getenv("BOSUN_LOG_FORMAT")
+		}
+		return ui.SetLogFormat(logFormatFlag)
+	}
+
 	// Version template with build info
 	rootCmd.SetVersionTemplate(fmt.Sprintf("bosun version {{.Version}}\ncommit: %s\nbuilt: %s\n", commit, date))
 
@@ -182,6 +274,9 @@ PowerShell:
   # To load completions for every new session, run:
   PS> bosun completion powershell > bosun.ps1
   # and source this file from your PowerShell profile.
+
+Or let bosun put the script in the right place for bash, zsh, or fish:
+  $ bosun completion install
 `,
 	DisableFlagsInUseLine: true,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRootCmd_Execute(t *testing.T) {
@@ -72,6 +73,27 @@ func TestRootCmd_Structure(t *testing.T) {
 		}
 		assert.True(t, yarrFound, "yarr command should exist")
 	})
+
+	t.Run("has global project flag", func(t *testing.T) {
+		resetRootCmd(t)
+		flag := rootCmd.PersistentFlags().Lookup("project")
+		require.NotNil(t, flag)
+		assert.Equal(t, "", flag.DefValue)
+		assert.Contains(t, rootCmd.Long, "--project <name>")
+	})
+
+	t.Run("has global color flag", func(t *testing.T) {
+		resetRootCmd(t)
+		flag := rootCmd.PersistentFlags().Lookup("color")
+		require.NotNil(t, flag)
+		assert.Equal(t, "auto", flag.DefValue)
+	})
+
+	t.Run("rejects invalid color mode", func(t *testing.T) {
+		_, err := executeCmd(t, "yarr", "--color", "sometimes")
+		assert.Error(t, err)
+		colorFlag = "auto"
+	})
 }
 
 func TestYarrCmd(t *testing.T) {
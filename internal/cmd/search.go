@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	searchCaseSensitive bool
+	searchLayer         string
+)
+
+// searchCmd searches manifests and rendered outputs.
+var searchCmd = &cobra.Command{
+	Use:     "search <term>",
+	Aliases: []string{"spyglass"},
+	Short:   "Search manifests and rendered outputs",
+	Long: `Search service manifests, provisions, stack files, and rendered outputs.
+
+Each match shows the layer it came from:
+  service     manifest/services/*.yml
+  provision   manifest/provisions/*.yml
+  stack       manifest/stacks/*.yml
+  rendered    each service rendered in memory (compose, traefik, gatus)
+
+Rendered matches find values that only exist after interpolation and merging,
+which grepping the repo misses.
+
+Examples:
+  bosun search 8096                # Which service uses port 8096?
+  bosun search traefik.enable      # Where are routers enabled?
+  bosun search -l rendered photos  # Only search rendered output`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().BoolVarP(&searchCaseSensitive, "case-sensitive", "c", false, "Match case exactly")
+	searchCmd.Flags().StringVarP(&searchLayer, "layer", "l", "", "Only show matches from one layer (service, provision, stack, rendered)")
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	term := args[0]
+
+	switch searchLayer {
+	case "", manifest.LayerService, manifest.LayerProvision, manifest.LayerStack, manifest.LayerRendered:
+	default:
+		return fmt.Errorf("unknown layer: %s (available: service, provision, stack, rendered)", searchLayer)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	// Rendering logs apiVersion warnings for every unversioned service; they
+	// are noise here.
+	prevLogOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(prevLogOutput)
+
+	matches, err := manifest.Search(term, manifest.SearchOptions{
+		ServicesDir:   cfg.ServicesDir(),
+		ProvisionsDir: cfg.ProvisionsDir(),
+		StacksDir:     cfg.StacksDir(),
+		CaseSensitive: searchCaseSensitive,
+	})
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	shown := 0
+	lastLayer := ""
+	for _, m := range matches {
+		if searchLayer != "" && m.Layer != searchLayer {
+			continue
+		}
+		if m.Layer != lastLayer {
+			if lastLayer != "" {
+				fmt.Println()
+			}
+			ui.Blue.Printf("--- %s ---\n", m.Layer)
+			lastLayer = m.Layer
+		}
+		fmt.Printf("  %s:%d: %s\n", m.Source, m.Line, m.Text)
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Printf("No matches for %q\n", term)
+		return nil
+	}
+
+	fmt.Println()
+	ui.Info("%d match(es)", shown)
+	return nil
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	secretsKeyFile    string
+	secretsRecipients []string
+	secretsCommit     bool
+	secretsDryRun     bool
+)
+
+// secretsCmd groups SOPS secret management commands.
+var secretsCmd = &cobra.Command{
+	Use:     "secrets",
+	Aliases: []string{"strongbox"},
+	Short:   "Manage SOPS-encrypted secrets",
+	Long: `Secrets commands manage the SOPS-encrypted files in the project.
+
+Commands:
+  rotate    Re-encrypt every SOPS file for a new age key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate [file...]",
+	Short: "Re-encrypt every SOPS file for a new age key",
+	Long: `Re-encrypt SOPS files for a new list of age recipients.
+
+Each file is decrypted with the current age key (SOPS_AGE_KEY,
+SOPS_AGE_KEY_FILE, or ~/.config/sops/age/keys.txt), encrypted again under a
+fresh data key for the new recipients, and checked to decrypt with the new
+key alone. Nothing is written unless every file passes. The recipients
+replace all existing keys, and the age rules in .sops.yaml are updated to
+match so newly encrypted files use them too.
+
+The recipients are the public keys of --key-file plus any --recipient.
+Without file arguments, every SOPS-encrypted YAML or JSON file in the
+project is rotated (hidden directories and rendered output are skipped).
+
+After rotating, point SOPS_AGE_KEY_FILE (or the daemon's key) at the new
+key before the next reconcile.
+
+Examples:
+  age-keygen -o new-key.txt
+  bosun secrets rotate --key-file new-key.txt -n      # Show what would rotate
+  bosun secrets rotate --key-file new-key.txt --commit
+  bosun secrets rotate --key-file new-key.txt -r age1... secrets/prod.sops.yaml`,
+	RunE: runSecretsRotate,
+}
+
+func init() {
+	secretsRotateCmd.Flags().StringVar(&secretsKeyFile, "key-file", "", "New age key file; its public keys become recipients and it verifies the result")
+	secretsRotateCmd.Flags().StringSliceVarP(&secretsRecipients, "recipient", "r", nil, "Additional age recipient (repeatable)")
+	secretsRotateCmd.Flags().BoolVar(&secretsCommit, "commit", false, "Commit the rotated files with git")
+	secretsRotateCmd.Flags().BoolVarP(&secretsDryRun, "dry-run", "n", false, "List the files that would be rotated")
+	_ = secretsRotateCmd.MarkFlagRequired("key-file")
+
+	secretsCmd.AddCommand(secretsRotateCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// rotatedFile is a SOPS file and its re-encrypted content.
+type rotatedFile struct {
+	path string
+	data []byte
+}
+
+func runSecretsRotate(cmd *cobra.Command, args []string) error {
+	root, err := config.FindRoot()
+	if err != nil {
+		return err
+	}
+
+	identities, recipients, err := loadRotationKey(secretsKeyFile)
+	if err != nil {
+		return err
+	}
+	for _, r := range secretsRecipients {
+		if r = strings.TrimSpace(r); r != "" && !slices.Contains(recipients, r) {
+			recipients = append(recipients, r)
+		}
+	}
+
+	files := args
+	if len(files) == 0 {
+		outputDir := ""
+		if cfg, err := config.Load(); err == nil {
+			outputDir = cfg.OutputDir()
+		}
+		if files, err = findSOPSFiles(root, outputDir); err != nil {
+			return err
+		}
+	}
+	if len(files) == 0 {
+		ui.Info("No SOPS-encrypted files found in %s", root)
+		return nil
+	}
+
+	ui.Info("Rotating %d file(s) to %d recipient(s):", len(files), len(recipients))
+	for _, r := range recipients {
+		fmt.Printf("    %s\n", r)
+	}
+
+	if secretsDryRun {
+		for _, f := range files {
+			fmt.Printf("  %s\n", relToRoot(root, f))
+		}
+		return nil
+	}
+
+	// Rotate and verify everything before writing anything
+	var rotated []rotatedFile
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f, err)
+		}
+		out, err := reconcile.RotateSOPS(data, f, recipients)
+		if err != nil {
+			return fmt.Errorf("rotate %s: %w", relToRoot(root, f), err)
+		}
+		if err := reconcile.VerifySOPS(out, f, identities); err != nil {
+			return fmt.Errorf("verify %s with new key: %w", relToRoot(root, f), err)
+		}
+		rotated = append(rotated, rotatedFile{path: f, data: out})
+	}
+
+	changed := make([]string, 0, len(rotated)+1)
+	for _, r := range rotated {
+		if err := writeFileAtomic(r.path, r.data); err != nil {
+			return fmt.Errorf("write %s: %w", relToRoot(root, r.path), err)
+		}
+		ui.Green.Printf("  * %s\n", relToRoot(root, r.path))
+		changed = append(changed, r.path)
+	}
+
+	sopsConfig := filepath.Join(root, ".sops.yaml")
+	if updated, err := updateSOPSRecipients(sopsConfig, recipients); err != nil {
+		ui.Warning("Could not update .sops.yaml: %v", err)
+	} else if updated {
+		ui.Green.Println("  * .sops.yaml")
+		changed = append(changed, sopsConfig)
+	}
+
+	ui.Success("Rotated %d file(s)", len(rotated))
+
+	if secretsCommit {
+		if err := commitFiles(cmd.Context(), root, changed, "Rotate SOPS age recipients"); err != nil {
+			return err
+		}
+		ui.Success("Committed rotated secrets")
+	} else {
+		ui.Info("Review and commit the changes, then switch SOPS_AGE_KEY_FILE to the new key")
+	}
+	return nil
+}
+
+// loadRotationKey reads a new age key file and returns its identities and
+// the public keys of its X25519 identities.
+func loadRotationKey(path string) ([]age.Identity, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open key file: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse key file %s: %w", path, err)
+	}
+	var recipients []string
+	for _, id := range identities {
+		if x, ok := id.(*age.X25519Identity); ok {
+			recipients = append(recipients, x.Recipient().String())
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, nil, fmt.Errorf("key file %s has no age X25519 key", path)
+	}
+	return identities, recipients, nil
+}
+
+// findSOPSFiles returns the SOPS-encrypted YAML and JSON files under root,
+// skipping hidden directories and the rendered output directory.
+func findSOPSFiles(root, outputDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || path == outputDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if d.Type().IsRegular() && reconcile.ValidateSOPSFile(path) == nil {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find SOPS files: %w", err)
+	}
+	return files, nil
+}
+
+// updateSOPSRecipients sets the age recipients of every creation rule in a
+// .sops.yaml that has any. Returns whether the file changed.
+func updateSOPSRecipients(path string, recipients []string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, fmt.Errorf("parse: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return false, nil
+	}
+
+	want := strings.Join(recipients, ",")
+	changed := false
+	rules := yamlMapValue(doc.Content[0], "creation_rules")
+	if rules == nil || rules.Kind != yaml.SequenceNode {
+		return false, nil
+	}
+	for _, rule := range rules.Content {
+		value := yamlMapValue(rule, "age")
+		if value == nil || value.Kind != yaml.ScalarNode || value.Value == want {
+			continue
+		}
+		value.Value = want
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return false, fmt.Errorf("marshal: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return false, fmt.Errorf("marshal: %w", err)
+	}
+	return true, writeFileAtomic(path, buf.Bytes())
+}
+
+// yamlMapValue returns the value of key in a mapping node, or nil.
+func yamlMapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a synced temp file,
+// keeping the file's mode.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Cleanup on failure
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// commitFiles commits files in the git repository at root.
+func commitFiles(ctx context.Context, root string, files []string, message string) error {
+	git := func(args ...string) error {
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	if err := git(append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	return git(append([]string{"commit", "-m", message, "--"}, files...)...)
+}
+
+// relToRoot returns path relative to root for display.
+func relToRoot(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
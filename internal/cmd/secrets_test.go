@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "secrets", "rotate", "--help")
+	assert.NoError(t, err)
+	if len(output) > 0 {
+		assert.Contains(t, output, "decrypt with the new")
+	}
+
+	_, err = executeCmd(t, "strongbox", "--help")
+	assert.NoError(t, err)
+}
+
+func TestFindSOPSFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	encrypted := "password: ENC[AES256_GCM,data:abc]\nsops:\n  version: 3.8.1\n"
+
+	write("secrets/prod.sops.yaml", encrypted)
+	write("config.json", `{"a": 1, "sops": {"version": "3.8.1"}}`)
+	write("manifest/stack.yml", "services: {}\n")
+	write("notes.txt", encrypted)
+	write(".git/secret.yaml", encrypted)
+	write("manifest/output/compose/secret.yml", encrypted)
+
+	files, err := findSOPSFiles(root, filepath.Join(root, "manifest", "output"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "config.json"),
+		filepath.Join(root, "secrets", "prod.sops.yaml"),
+	}, files)
+}
+
+func TestUpdateSOPSRecipients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".sops.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Encryption rules
+creation_rules:
+  - path_regex: .*\.sops\.yaml$
+    age: age1old
+  - path_regex: .*\.pgp\.yaml$
+    pgp: ABCDEF
+`), 0600))
+
+	updated, err := updateSOPSRecipients(path, []string{"age1new", "age1team"})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Encryption rules")
+	assert.Contains(t, string(data), "age: age1new,age1team")
+	assert.Contains(t, string(data), "pgp: ABCDEF")
+	assert.NotContains(t, string(data), "age1old")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Already up to date
+	updated, err = updateSOPSRecipients(path, []string{"age1new", "age1team"})
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	// No .sops.yaml
+	updated, err = updateSOPSRecipients(filepath.Join(t.TempDir(), ".sops.yaml"), []string{"age1new"})
+	require.NoError(t, err)
+	assert.False(t, updated)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/snapshot"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var snapshotPinNote string
+
+// snapshotCmd groups output snapshot commands.
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage output snapshots",
+	Long: `Snapshot commands manage the snapshots of rendered output taken before each
+provision. List and restore them with 'bosun mayday --list' and
+'bosun mayday --rollback'.
+
+Old snapshots are removed by the retention policy in the snapshots: section
+of .bosun/config.yml or bosun.yml:
+
+  snapshots:
+    keep: 20          # Newest snapshots to keep (default: 20)
+    keep_daily: 14    # Also keep the newest snapshot of each of the last 14 days
+
+Pinned snapshots are never removed and don't count toward keep.
+
+Commands:
+  pin      Keep a snapshot regardless of retention
+  unpin    Return a snapshot to the retention policy`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var snapshotPinCmd = &cobra.Command{
+	Use:   "pin <name>",
+	Short: "Keep a snapshot regardless of retention",
+	Long: `Pin a snapshot so retention never ages it out, e.g. the last good output
+before an upgrade. Pinning an already pinned snapshot replaces its note.
+
+Examples:
+  bosun snapshot pin snapshot-20240601-120000.000000000 --note "before postgres 17"
+  bosun snapshot unpin snapshot-20240601-120000.000000000`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnapshotPinNames,
+	RunE:              runSnapshotPin,
+}
+
+var snapshotUnpinCmd = &cobra.Command{
+	Use:               "unpin <name>",
+	Short:             "Return a snapshot to the retention policy",
+	Long:              `Remove a snapshot's pin so the retention policy can remove it on the next provision.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnapshotPinNames,
+	RunE:              runSnapshotUnpin,
+}
+
+func init() {
+	snapshotPinCmd.Flags().StringVar(&snapshotPinNote, "note", "", "Why the snapshot is pinned")
+
+	snapshotCmd.AddCommand(snapshotPinCmd, snapshotUnpinCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+// snapshotRetention returns the configured snapshot retention policy.
+func snapshotRetention(cfg *config.Config) snapshot.Retention {
+	sc := cfg.GetSnapshotConfig()
+	return snapshot.Retention{Keep: sc.Keep, KeepDaily: sc.KeepDaily}
+}
+
+func runSnapshotPin(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := snapshot.Pin(cfg.ManifestDir, args[0], snapshotPinNote); err != nil {
+		return err
+	}
+
+	ui.Success("Snapshot %s pinned", args[0])
+	return nil
+}
+
+func runSnapshotUnpin(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	removed, err := snapshot.Unpin(cfg.ManifestDir, args[0])
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("snapshot %s is not pinned", args[0])
+	}
+
+	ui.Success("Snapshot %s unpinned", args[0])
+	return nil
+}
+
+// completeSnapshotPinNames completes snapshot names for pin and unpin.
+func completeSnapshotPinNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	snapshots, err := snapshot.List(cfg.ManifestDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, snap := range snapshots {
+		if strings.HasPrefix(snap.Name, toComplete) {
+			names = append(names, snap.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/snapshot"
+)
+
+func TestSnapshotCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "snapshot", "pin", "--help")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "pin <name>")
+	assert.Contains(t, output, "--note")
+}
+
+func TestSnapshotCmd_PinAndUnpin(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestDir := filepath.Join(tmpDir, "manifest")
+	name := "snapshot-20240601-120000"
+	require.NoError(t, os.MkdirAll(filepath.Join(manifestDir, ".bosun", "snapshots", name), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bosun.yml"), []byte("snapshots:\n  keep: 5\n  keep_daily: 7\n"), 0644))
+
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.Retention{Keep: 5, KeepDaily: 7}, snapshotRetention(cfg))
+
+	snapshotPinNote = "before postgres 17"
+	defer func() { snapshotPinNote = "" }()
+	require.NoError(t, runSnapshotPin(snapshotPinCmd, []string{name}))
+
+	pins, err := snapshot.Pins(manifestDir)
+	require.NoError(t, err)
+	assert.Equal(t, "before postgres 17", pins[name].Note)
+
+	require.NoError(t, runSnapshotUnpin(snapshotUnpinCmd, []string{name}))
+	assert.Error(t, runSnapshotUnpin(snapshotUnpinCmd, []string{name}), "unpinning twice should fail")
+	assert.ErrorContains(t, runSnapshotPin(snapshotPinCmd, []string{"snapshot-20990101-000000"}), "snapshot not found")
+}
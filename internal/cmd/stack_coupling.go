@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// Kinds of coupling between stacks.
+const (
+	couplingNetwork = "network"
+	couplingVolume  = "volume"
+	couplingDepends = "depends_on"
+)
+
+// stackCoupling is a network, volume, or dependency that ties stacks
+// together. Each stack is its own compose project, so networks and volumes
+// are only shared when they are external or have an explicit name.
+type stackCoupling struct {
+	kind string
+	// name is the Docker network or volume name, or "svc -> dep".
+	name string
+	// keys are the compose keys the stacks declare the network or volume
+	// under.
+	keys []string
+	// stacks are the stacks involved, sorted.
+	stacks []string
+	// services are the services that use the network or volume, or the
+	// dependent service and its dependency, sorted.
+	services []string
+	// problem says why the coupling breaks per-stack scoped deploys; empty
+	// when the sharing is intended.
+	problem string
+}
+
+// String describes the coupling on one line.
+func (c stackCoupling) String() string {
+	if c.kind == couplingDepends {
+		return fmt.Sprintf("depends_on %s crosses stacks %s", c.name, strings.Join(c.stacks, " -> "))
+	}
+	return fmt.Sprintf("%s %s is shared by %s", c.kind, c.name, strings.Join(c.stacks, ", "))
+}
+
+// couplingCompose is the part of a compose file the coupling analysis reads.
+type couplingCompose struct {
+	Services map[string]struct {
+		DependsOn any   `yaml:"depends_on"`
+		Networks  any   `yaml:"networks"`
+		Volumes   []any `yaml:"volumes"`
+	} `yaml:"services"`
+	Networks map[string]*composeResource `yaml:"networks"`
+	Volumes  map[string]*composeResource `yaml:"volumes"`
+}
+
+// composeResource is a top-level network or volume declaration.
+type composeResource struct {
+	Name     string `yaml:"name"`
+	External any    `yaml:"external"` // true, or the legacy {name: ...}
+}
+
+// dockerName returns the Docker name of a resource declared under key, and
+// whether it is external. Resources without an explicit name are scoped to
+// their project and return "".
+func (r *composeResource) dockerName(key string) (name string, external bool) {
+	if r == nil {
+		return "", false
+	}
+	switch ext := r.External.(type) {
+	case bool:
+		external = ext
+	case map[string]any:
+		external = true
+		if n, ok := ext["name"].(string); ok && n != "" {
+			return n, true
+		}
+	}
+	switch {
+	case r.Name != "":
+		return r.Name, external
+	case external:
+		return key, true
+	}
+	return "", false
+}
+
+// sharedResource collects the stacks that declare one named network or
+// volume.
+type sharedResource struct {
+	keys     map[string]bool
+	stacks   map[string]bool
+	owners   []string // Stacks that declare it without external: true
+	services map[string]bool
+}
+
+// analyzeStackCoupling finds the networks and volumes that several stacks
+// share and the depends_on entries that name another stack's service, from
+// rendered compose files (one stack per file).
+func analyzeStackCoupling(files []string) ([]stackCoupling, error) {
+	resources := map[string]map[string]*sharedResource{
+		couplingNetwork: {},
+		couplingVolume:  {},
+	}
+	serviceStack := make(map[string]string)
+	depends := make(map[string]map[string][]string) // stack -> service -> deps
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		var compose couplingCompose
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+
+		declare := func(kind string, decls map[string]*composeResource) map[string]string {
+			named := make(map[string]string) // compose key -> Docker name
+			for key, decl := range decls {
+				name, external := decl.dockerName(key)
+				if name == "" {
+					continue
+				}
+				named[key] = name
+				r := resources[kind][name]
+				if r == nil {
+					r = &sharedResource{keys: map[string]bool{}, stacks: map[string]bool{}, services: map[string]bool{}}
+					resources[kind][name] = r
+				}
+				r.keys[key] = true
+				r.stacks[stack] = true
+				if !external {
+					r.owners = append(r.owners, stack)
+				}
+			}
+			return named
+		}
+		networks := declare(couplingNetwork, compose.Networks)
+		volumes := declare(couplingVolume, compose.Volumes)
+
+		depends[stack] = make(map[string][]string)
+		for svc, cfg := range compose.Services {
+			serviceStack[svc] = stack
+			depends[stack][svc] = composeNames(cfg.DependsOn)
+			for _, key := range composeNames(cfg.Networks) {
+				if name, ok := networks[key]; ok {
+					resources[couplingNetwork][name].services[svc] = true
+				}
+			}
+			for _, key := range serviceVolumeSources(cfg.Volumes) {
+				if name, ok := volumes[key]; ok {
+					resources[couplingVolume][name].services[svc] = true
+				}
+			}
+		}
+	}
+
+	var couplings []stackCoupling
+	for _, kind := range []string{couplingNetwork, couplingVolume} {
+		for name, r := range resources[kind] {
+			if len(r.stacks) < 2 {
+				continue
+			}
+			c := stackCoupling{
+				kind:     kind,
+				name:     name,
+				keys:     sortedKeys(r.keys),
+				stacks:   sortedKeys(r.stacks),
+				services: sortedKeys(r.services),
+			}
+			if len(r.owners) > 1 {
+				sort.Strings(r.owners)
+				c.problem = fmt.Sprintf("%s %s is created by %s; mark it external: true in all but one stack, or each stack's deploy fights over it",
+					kind, name, strings.Join(r.owners, " and "))
+			}
+			couplings = append(couplings, c)
+		}
+	}
+
+	for stack, services := range depends {
+		for svc, deps := range services {
+			for _, dep := range deps {
+				other, ok := serviceStack[dep]
+				if !ok || other == stack {
+					continue
+				}
+				couplings = append(couplings, stackCoupling{
+					kind:     couplingDepends,
+					name:     svc + " -> " + dep,
+					stacks:   []string{stack, other},
+					services: []string{svc, dep},
+					problem: fmt.Sprintf("%s in %s depends on %s in %s; compose can't deploy %s on its own",
+						svc, stack, dep, other, stack),
+				})
+			}
+		}
+	}
+
+	sort.Slice(couplings, func(i, j int) bool {
+		if couplings[i].kind != couplings[j].kind {
+			return couplings[i].kind < couplings[j].kind
+		}
+		return couplings[i].name < couplings[j].name
+	})
+	return couplings, nil
+}
+
+// serviceVolumeSources returns the volume names a service mounts, from the
+// short "name:/path" and long {type: volume, source: name} syntaxes. Bind
+// mounts are skipped.
+func serviceVolumeSources(volumes []any) []string {
+	var sources []string
+	for _, v := range volumes {
+		switch v := v.(type) {
+		case string:
+			source, _, ok := strings.Cut(v, ":")
+			if ok && source != "" && !strings.ContainsAny(source[:1], "/.~$") {
+				sources = append(sources, source)
+			}
+		case map[string]any:
+			if t, _ := v["type"].(string); t != "" && t != "volume" {
+				continue
+			}
+			if source, ok := v["source"].(string); ok && source != "" {
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
+
+// sortedKeys returns the keys of a set, sorted.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkStackCoupling warns about sharing between the stacks in composeDir
+// that would break per-stack scoped deploys. Returns the number of
+// warnings.
+func checkStackCoupling(composeDir string) int {
+	files, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	couplings, err := analyzeStackCoupling(files)
+	if err != nil {
+		ui.Yellow.Printf("  ! %v\n", err)
+		return 1
+	}
+
+	warnings := 0
+	for _, c := range couplings {
+		if c.problem == "" {
+			continue
+		}
+		ui.Yellow.Printf("  ! %s\n", c.problem)
+		warnings++
+	}
+	return warnings
+}
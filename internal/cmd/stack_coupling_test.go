@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCouplingFixture(t *testing.T) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"core.yml": `services:
+  traefik:
+    image: traefik:v3
+    networks: [proxynet]
+  postgres:
+    image: postgres:16
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+networks:
+  proxynet:
+    name: proxynet
+volumes:
+  pgdata:
+    name: shared-pgdata
+  media:
+    name: media-library
+`,
+		"media.yml": `services:
+  sonarr:
+    image: lscr.io/linuxserver/sonarr
+    depends_on: [postgres]
+    networks: [proxy, backend]
+    volumes:
+      - type: volume
+        source: library
+        target: /tv
+      - ./config:/config
+networks:
+  proxy:
+    external: true
+    name: proxynet
+  backend: {}
+volumes:
+  library:
+    name: media-library
+`,
+		"backup.yml": `services:
+  restic:
+    image: restic/restic
+    volumes:
+      - pgdata:/data/pg:ro
+networks:
+  backend: {}
+volumes:
+  pgdata:
+    external: true
+    name: shared-pgdata
+`,
+	}
+	var paths []string
+	for _, name := range []string{"backup.yml", "core.yml", "media.yml"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(files[name]), 0644))
+		paths = append(paths, path)
+	}
+	return dir, paths
+}
+
+func TestAnalyzeStackCoupling(t *testing.T) {
+	_, files := writeCouplingFixture(t)
+	couplings, err := analyzeStackCoupling(files)
+	require.NoError(t, err)
+	require.Len(t, couplings, 4, "project-scoped networks like backend are not shared")
+
+	byName := make(map[string]stackCoupling)
+	for _, c := range couplings {
+		byName[c.name] = c
+	}
+
+	t.Run("external network with one owner is intended", func(t *testing.T) {
+		c := byName["proxynet"]
+		assert.Equal(t, couplingNetwork, c.kind)
+		assert.Equal(t, []string{"proxy", "proxynet"}, c.keys)
+		assert.Equal(t, []string{"core", "media"}, c.stacks)
+		assert.Equal(t, []string{"sonarr", "traefik"}, c.services)
+		assert.Empty(t, c.problem)
+	})
+
+	t.Run("volume shared with an external consumer is intended", func(t *testing.T) {
+		c := byName["shared-pgdata"]
+		assert.Equal(t, []string{"backup", "core"}, c.stacks)
+		assert.Equal(t, []string{"postgres", "restic"}, c.services)
+		assert.Empty(t, c.problem)
+	})
+
+	t.Run("volume created by two stacks is unintended", func(t *testing.T) {
+		c := byName["media-library"]
+		assert.Equal(t, []string{"core", "media"}, c.stacks)
+		assert.Equal(t, []string{"sonarr"}, c.services)
+		assert.Contains(t, c.problem, "created by core and media")
+	})
+
+	t.Run("depends_on across stacks is unintended", func(t *testing.T) {
+		c := byName["sonarr -> postgres"]
+		assert.Equal(t, couplingDepends, c.kind)
+		assert.Equal(t, []string{"media", "core"}, c.stacks)
+		assert.Contains(t, c.problem, "can't deploy media on its own")
+	})
+}
+
+func TestServiceVolumeSources(t *testing.T) {
+	volumes := []any{
+		"data:/data",
+		"./config:/config",
+		"/mnt/user/media:/media",
+		"~/cache:/cache",
+		"/anonymous",
+		map[string]any{"type": "volume", "source": "long", "target": "/long"},
+		map[string]any{"type": "bind", "source": "/srv", "target": "/srv"},
+	}
+	assert.Equal(t, []string{"data", "long"}, serviceVolumeSources(volumes))
+}
+
+func TestCheckStackCoupling(t *testing.T) {
+	dir, _ := writeCouplingFixture(t)
+	assert.Equal(t, 2, checkStackCoupling(dir))
+}
+
+func TestServiceGraph_Couplings(t *testing.T) {
+	_, files := writeCouplingFixture(t)
+	g, err := buildServiceGraph(files)
+	require.NoError(t, err)
+	couplings, err := analyzeStackCoupling(files)
+	require.NoError(t, err)
+	g.addCouplings(couplings)
+
+	assert.Equal(t, []string{"media-library", "shared-pgdata"}, g.volumes)
+	assert.Contains(t, g.edges, graphEdge{from: "restic", to: "shared-pgdata", kind: graphEdgeVolume})
+	assert.Contains(t, g.edges, graphEdge{from: "sonarr", to: "postgres", kind: graphEdgeDepends, coupling: true})
+
+	dot := g.dot()
+	assert.Contains(t, dot, `"vol:media-library" [label="media-library", shape=cylinder, color=orange, penwidth=2];`)
+	assert.Contains(t, dot, `"vol:shared-pgdata" [label="shared-pgdata", shape=cylinder];`)
+	assert.Contains(t, dot, `"sonarr" -> "postgres" [color=orange, penwidth=2];`)
+	assert.Contains(t, dot, "// coupling: network proxynet is shared by core, media")
+	assert.Contains(t, dot, "// unintended coupling: sonarr in media depends on postgres in core")
+
+	mermaid := g.mermaid()
+	assert.Contains(t, mermaid, "vol_media_library[(media-library)]")
+	assert.Contains(t, mermaid, "style vol_media_library stroke:orange,stroke-width:2px")
+	assert.Contains(t, mermaid, "svc_restic -.-> vol_shared_pgdata")
+	assert.Contains(t, mermaid, "stroke:orange")
+}
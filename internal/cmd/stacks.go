@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	stacksRemote string
+	stacksJSON   bool
+	stacksDryRun bool
+	stacksForce  bool
+)
+
+// stacksCmd groups commands for compose projects on the Docker engine.
+var stacksCmd = &cobra.Command{
+	Use:     "stacks",
+	Aliases: []string{"fleet"},
+	Short:   "Discover and adopt compose projects",
+	Long: `Stack commands for compose projects running on a Docker engine.
+
+Commands:
+  discover  List compose projects, bosun-managed and foreign
+  adopt     Import a foreign project into the manifest tree`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+// stackRow is one compose project in the discover listing.
+type stackRow struct {
+	reconcile.ComposeProject
+	Managed bool `json:"managed"`
+}
+
+var stacksDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List compose projects on the Docker engine",
+	Long: `Lists every compose project on the Docker engine, running or stopped, from
+its container labels. A project is bosun-managed when its compose files are
+rendered stacks of this project; anything else is foreign and can be brought
+under bosun with 'bosun stacks adopt'.
+
+Examples:
+  bosun stacks discover
+  bosun stacks discover --remote root@tower
+  bosun stacks discover --json`,
+	Args: cobra.NoArgs,
+	RunE: runStacksDiscover,
+}
+
+var stacksAdoptCmd = &cobra.Command{
+	Use:   "adopt <project>",
+	Short: "Import a foreign compose project as a stack",
+	Long: `Imports a compose project's configuration into the manifest tree: a stack
+manifest named after the project, and one raw service manifest per service.
+Service configs are copied as written (relative paths resolved, ${...}
+variables kept), so the adopted services deploy as they run today.
+
+Review the warnings: env files, build contexts, and named volumes stay in the
+original project directory and need moving or declaring. Then convert
+services to provisions at your own pace, provision, and stop the old project
+before the first deploy so the containers don't clash.
+
+Examples:
+  bosun stacks adopt immich
+  bosun stacks adopt immich --remote root@tower
+  bosun stacks adopt immich --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStacksAdopt,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{stacksDiscoverCmd, stacksAdoptCmd} {
+		c.Flags().StringVar(&stacksRemote, "remote", "", "Remote Docker host over SSH (e.g., root@192.168.1.8)")
+	}
+	stacksDiscoverCmd.Flags().BoolVar(&stacksJSON, "json", false, "Output as JSON")
+	stacksAdoptCmd.Flags().BoolVarP(&stacksDryRun, "dry-run", "n", false, "Print the manifests without writing them")
+	stacksAdoptCmd.Flags().BoolVarP(&stacksForce, "force", "f", false, "Overwrite existing manifests")
+
+	stacksCmd.AddCommand(stacksDiscoverCmd)
+	stacksCmd.AddCommand(stacksAdoptCmd)
+	rootCmd.AddCommand(stacksCmd)
+}
+
+func runStacksDiscover(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	projects, err := reconcile.ListComposeProjects(cmd.Context(), stacksRemote)
+	if err != nil {
+		return err
+	}
+	rows := classifyProjects(projects, renderedStacks(filepath.Join(cfg.OutputDir(), "compose")))
+
+	if stacksJSON {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal projects: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(rows) == 0 {
+		ui.Info("No compose projects found")
+		return nil
+	}
+
+	ui.Blue.Println("--- Compose Projects ---")
+	foreign := 0
+	for _, row := range rows {
+		files := strings.Join(row.ConfigFiles, ", ")
+		if row.Managed {
+			ui.Green.Printf("  * %s (%s) - bosun: %s\n", row.Name, row.Status, files)
+		} else {
+			ui.Yellow.Printf("  ~ %s (%s) - foreign: %s\n", row.Name, row.Status, files)
+			foreign++
+		}
+	}
+	if foreign > 0 {
+		fmt.Println()
+		ui.Info("Adopt a foreign project with: bosun stacks adopt <project>")
+	}
+	return nil
+}
+
+// renderedStacks returns the names of the rendered compose stacks in dir.
+func renderedStacks(dir string) map[string]bool {
+	stacks := make(map[string]bool)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	for _, f := range files {
+		stacks[strings.TrimSuffix(filepath.Base(f), ".yml")] = true
+	}
+	return stacks
+}
+
+// classifyProjects marks projects whose compose files are all rendered
+// stacks as bosun-managed.
+func classifyProjects(projects []reconcile.ComposeProject, stacks map[string]bool) []stackRow {
+	rows := make([]stackRow, 0, len(projects))
+	for _, p := range projects {
+		managed := len(p.ConfigFiles) > 0
+		for _, stack := range p.Stacks() {
+			if !stacks[stack] {
+				managed = false
+			}
+		}
+		rows = append(rows, stackRow{ComposeProject: p, Managed: managed})
+	}
+	return rows
+}
+
+func runStacksAdopt(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !stackNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid stack name: %s", name)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	projects, err := reconcile.ListComposeProjects(cmd.Context(), stacksRemote)
+	if err != nil {
+		return err
+	}
+	var project *reconcile.ComposeProject
+	for i := range projects {
+		if projects[i].Name == name {
+			project = &projects[i]
+		}
+	}
+	if project == nil {
+		return fmt.Errorf("compose project not found: %s (see 'bosun stacks discover')", name)
+	}
+	for _, row := range classifyProjects([]reconcile.ComposeProject{*project}, renderedStacks(filepath.Join(cfg.OutputDir(), "compose"))) {
+		if row.Managed {
+			return fmt.Errorf("compose project %s is already managed by bosun", name)
+		}
+	}
+
+	compose, err := reconcile.ComposeProjectConfig(cmd.Context(), stacksRemote, *project)
+	if err != nil {
+		return err
+	}
+	adoption, err := manifest.AdoptCompose(name, compose)
+	if err != nil {
+		return err
+	}
+	files, err := adoption.Files(name, cfg.StacksDir(), cfg.ServicesDir())
+	if err != nil {
+		return err
+	}
+
+	if stacksDryRun {
+		for _, f := range files {
+			ui.Blue.Printf("--- %s ---\n", f.Path)
+			fmt.Print(string(f.Content))
+		}
+	} else {
+		if err := manifest.WriteAdoptedFiles(files, stacksForce); err != nil {
+			return err
+		}
+		for _, f := range files {
+			ui.Green.Printf("  * Wrote %s\n", f.Path)
+		}
+	}
+
+	if len(adoption.Warnings) > 0 {
+		fmt.Println()
+		ui.Warning("Review before deploying:")
+		for _, w := range adoption.Warnings {
+			ui.Yellow.Printf("  ~ %s\n", w)
+		}
+	}
+
+	if !stacksDryRun {
+		fmt.Println()
+		ui.Success("Adopted %s (%d services)", name, len(adoption.Services))
+		ui.Info("Next: bosun provision %s, then stop the old project before deploying", name)
+	}
+	return nil
+}
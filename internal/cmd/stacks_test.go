@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+)
+
+func TestStacksCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "stacks", "adopt", "--help")
+	require.NoError(t, err)
+	assert.Contains(t, output, "raw service manifest")
+	assert.Contains(t, output, "--remote")
+}
+
+func TestClassifyProjects(t *testing.T) {
+	projects := []reconcile.ComposeProject{
+		{Name: "apps", ConfigFiles: []string{"/mnt/user/appdata/compose/apps.yml"}},
+		{Name: "immich", ConfigFiles: []string{"/opt/immich/docker-compose.yml"}},
+		{Name: "mixed", ConfigFiles: []string{"/mnt/user/appdata/compose/apps.yml", "/opt/extra.yml"}},
+		{Name: "nofiles"},
+	}
+
+	rows := classifyProjects(projects, map[string]bool{"apps": true, "core": true})
+	require.Len(t, rows, 4)
+	assert.True(t, rows[0].Managed)
+	assert.False(t, rows[1].Managed)
+	assert.False(t, rows[2].Managed, "an extra foreign file makes the project foreign")
+	assert.False(t, rows[3].Managed)
+}
+
+func TestRunStacksAdopt_InvalidName(t *testing.T) {
+	err := runStacksAdopt(stacksAdoptCmd, []string{"../etc"})
+	assert.ErrorContains(t, err, "invalid stack name")
+}
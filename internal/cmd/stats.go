@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+var (
+	statsInterval time.Duration
+	statsSort     string
+	statsFilter   string
+	statsOnce     bool
+)
+
+// Columns bosun stats sorts by.
+const (
+	statsSortCPU  = "cpu"
+	statsSortMem  = "mem"
+	statsSortNet  = "net"
+	statsSortIO   = "io"
+	statsSortName = "name"
+)
+
+// statsCmd shows live resource usage per container.
+var statsCmd = &cobra.Command{
+	Use:     "stats",
+	Aliases: []string{"gauges"},
+	Short:   "Live dashboard of container CPU, memory, network, and disk I/O",
+	Long: `Stats shows the CPU, memory, network, and block I/O of every running
+container, refreshed every --interval from Docker's streaming stats. Network
+and block I/O are totals since each container started, as in 'docker stats'.
+
+Keys:
+  c m t d n   Sort by CPU, memory, network (traffic), disk I/O, or name
+  r           Reverse the sort
+  /           Filter by container name (Enter to apply, Esc to clear)
+  q, Ctrl-C   Quit
+
+When stdout isn't a terminal, or with --once, one table is printed instead.
+
+Examples:
+  bosun stats
+  bosun stats --sort mem --filter media
+  bosun stats --once`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().DurationVar(&statsInterval, "interval", 2*time.Second, "Refresh interval")
+	statsCmd.Flags().StringVar(&statsSort, "sort", statsSortCPU, "Sort by cpu, mem, net, io, or name")
+	statsCmd.Flags().StringVar(&statsFilter, "filter", "", "Only show containers whose name contains this")
+	statsCmd.Flags().BoolVar(&statsOnce, "once", false, "Print one table and exit")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	switch statsSort {
+	case statsSortCPU, statsSortMem, statsSortNet, statsSortIO, statsSortName:
+	default:
+		return fmt.Errorf("invalid --sort %q: use cpu, mem, net, io, or name", statsSort)
+	}
+	if statsInterval < 500*time.Millisecond {
+		return fmt.Errorf("--interval must be at least 500ms")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	view := statsView{sort: statsSort, filter: statsFilter}
+	return withDockerClientContext(ctx, func(client *docker.Client) error {
+		interactive := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+		if statsOnce || !interactive {
+			stats, err := client.GetAllContainerStats(ctx)
+			if err != nil {
+				return fmt.Errorf("get container stats: %w", err)
+			}
+			renderStatsTable(os.Stdout, view.rows(stats), 0, "\n")
+			return nil
+		}
+		return runStatsDashboard(ctx, client, view)
+	})
+}
+
+// statsView is how the dashboard sorts and filters containers.
+type statsView struct {
+	sort    string
+	reverse bool
+	filter  string
+}
+
+// rows returns the stats that match the filter, sorted: numbers high to
+// low and names A to Z, unless reversed.
+func (v statsView) rows(stats []docker.ContainerStats) []docker.ContainerStats {
+	filter := strings.ToLower(v.filter)
+	rows := make([]docker.ContainerStats, 0, len(stats))
+	for _, s := range stats {
+		if strings.Contains(strings.ToLower(s.Name), filter) {
+			rows = append(rows, s)
+		}
+	}
+
+	key := func(s docker.ContainerStats) float64 {
+		switch v.sort {
+		case statsSortMem:
+			return float64(s.MemUsage)
+		case statsSortNet:
+			return float64(s.NetRx + s.NetTx)
+		case statsSortIO:
+			return float64(s.BlockRead + s.BlockWrite)
+		}
+		return s.CPUPercent
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if v.reverse {
+			a, b = b, a
+		}
+		if v.sort != statsSortName && key(a) != key(b) {
+			return key(a) > key(b)
+		}
+		return a.Name < b.Name
+	})
+	return rows
+}
+
+// renderStatsTable writes the stats as a table, at most limit rows (0 for
+// all), ending lines with eol ("\r\n" in raw mode).
+func renderStatsTable(w io.Writer, rows []docker.ContainerStats, limit int, eol string) {
+	nameWidth := len("CONTAINER")
+	for _, s := range rows {
+		nameWidth = max(nameWidth, min(len(s.Name), 30))
+	}
+
+	fmt.Fprintf(w, "%-*s  %7s  %-21s  %6s  %-21s  %-21s%s", nameWidth, "CONTAINER", "CPU %", "MEM USAGE / LIMIT", "MEM %", "NET I/O", "BLOCK I/O", eol)
+	for i, s := range rows {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(w, "... %d more%s", len(rows)-limit, eol)
+			break
+		}
+		name := s.Name
+		if len(name) > nameWidth {
+			name = name[:nameWidth-1] + "~"
+		}
+		fmt.Fprintf(w, "%-*s  %6.1f%%  %-21s  %5.1f%%  %-21s  %-21s%s",
+			nameWidth, name,
+			s.CPUPercent,
+			formatBytes(int64(s.MemUsage))+" / "+formatBytes(int64(s.MemLimit)),
+			s.MemPercent,
+			formatBytes(int64(s.NetRx))+" / "+formatBytes(int64(s.NetTx)),
+			formatBytes(int64(s.BlockRead))+" / "+formatBytes(int64(s.BlockWrite)),
+			eol)
+	}
+}
+
+// statsStreams holds the latest sample of every container being streamed.
+type statsStreams struct {
+	mu     sync.Mutex
+	latest map[string]docker.ContainerStats
+	open   map[string]bool
+}
+
+// sync starts a stream for each running container without one. Streams
+// end, and their containers drop out, when the containers stop.
+func (s *statsStreams) sync(ctx context.Context, client *docker.Client) error {
+	names, err := client.RunningContainerNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		s.mu.Lock()
+		started := s.open[name]
+		s.open[name] = true
+		s.mu.Unlock()
+		if started {
+			continue
+		}
+
+		ch, err := client.StreamContainerStats(ctx, name)
+		if err != nil {
+			s.mu.Lock()
+			delete(s.open, name)
+			s.mu.Unlock()
+			continue
+		}
+		go func(name string) {
+			for sample := range ch {
+				s.mu.Lock()
+				s.latest[name] = sample
+				s.mu.Unlock()
+			}
+			s.mu.Lock()
+			delete(s.latest, name)
+			delete(s.open, name)
+			s.mu.Unlock()
+		}(name)
+	}
+	return nil
+}
+
+// snapshot returns the latest samples.
+func (s *statsStreams) snapshot() []docker.ContainerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]docker.ContainerStats, 0, len(s.latest))
+	for _, sample := range s.latest {
+		stats = append(stats, sample)
+	}
+	return stats
+}
+
+// runStatsDashboard redraws the stats every statsInterval in the alternate
+// screen, reading sort and filter keys from the terminal in raw mode.
+func runStatsDashboard(ctx context.Context, client *docker.Client, view statsView) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("set terminal to raw mode: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, oldState) }()
+	fmt.Print("\x1b[?1049h\x1b[?25l") // Alternate screen, hide cursor
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range statsKeys(buf[:n]) {
+				select {
+				case keys <- key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	streams := &statsStreams{latest: make(map[string]docker.ContainerStats), open: make(map[string]bool)}
+	if err := streams.sync(ctx, client); err != nil {
+		return fmt.Errorf("list containers: %w", err)
+	}
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	editing := false // Typing a filter after '/'
+	for {
+		drawStatsDashboard(streams.snapshot(), view, editing)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			_ = streams.sync(ctx, client)
+		case key := <-keys:
+			if editing {
+				switch key {
+				case '\r', '\n':
+					editing = false
+				case 0x1b: // Esc
+					editing, view.filter = false, ""
+				case 0x7f, 0x08: // Backspace
+					if view.filter != "" {
+						view.filter = view.filter[:len(view.filter)-1]
+					}
+				default:
+					if key >= ' ' && key < 0x7f {
+						view.filter += string(key)
+					}
+				}
+				continue
+			}
+			switch key {
+			case 'q', 0x03: // q, Ctrl-C (raw mode delivers it as a key)
+				return nil
+			case 'c':
+				view.sort = statsSortCPU
+			case 'm':
+				view.sort = statsSortMem
+			case 't':
+				view.sort = statsSortNet
+			case 'd':
+				view.sort = statsSortIO
+			case 'n':
+				view.sort = statsSortName
+			case 'r':
+				view.reverse = !view.reverse
+			case '/':
+				editing = true
+			}
+		}
+	}
+}
+
+// statsKeys splits a read from the terminal into key presses. A lone Esc
+// comes back as 0x1b. Escape sequences, such as arrow and function keys
+// (ESC [ A) or Alt chords (ESC x), are dropped whole so their bytes aren't
+// taken for keys. Terminals write each sequence at once, so a read doesn't
+// end partway through one.
+func statsKeys(buf []byte) []byte {
+	var keys []byte
+	for i := 0; i < len(buf); i++ {
+		if buf[i] != 0x1b {
+			keys = append(keys, buf[i])
+			continue
+		}
+		if i+1 == len(buf) {
+			keys = append(keys, 0x1b)
+			break
+		}
+		switch buf[i+1] {
+		case '[': // CSI: parameter bytes up to a final byte in '@'..'~'
+			i += 2
+			for i < len(buf) && (buf[i] < 0x40 || buf[i] > 0x7e) {
+				i++
+			}
+		case 'O': // SS3: one final byte
+			i += 2
+		case 0x1b: // Esc pressed twice; the second starts over
+			keys = append(keys, 0x1b)
+		default: // Alt chord
+			i++
+		}
+	}
+	return keys
+}
+
+// drawStatsDashboard redraws the screen: a status line, then as many
+// table rows as fit.
+func drawStatsDashboard(stats []docker.ContainerStats, view statsView, editing bool) {
+	height := 24
+	if _, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil && h > 0 {
+		height = h
+	}
+	rows := view.rows(stats)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	order := "desc"
+	if (view.sort == statsSortName) != view.reverse {
+		order = "asc"
+	}
+	fmt.Fprintf(&b, "bosun stats  %s  %d container(s), sort %s (%s)", time.Now().Format("15:04:05"), len(rows), view.sort, order)
+	switch {
+	case editing:
+		fmt.Fprintf(&b, "  filter: %s_", view.filter)
+	case view.filter != "":
+		fmt.Fprintf(&b, "  filter: %s", view.filter)
+	}
+	b.WriteString("\r\n")
+	b.WriteString("[c]pu [m]em [t]raffic [d]isk [n]ame [r]everse [/]filter [q]uit\r\n\r\n")
+	renderStatsTable(&b, rows, max(height-5, 1), "\r\n")
+	fmt.Print(b.String())
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+func statsNames(rows []docker.ContainerStats) []string {
+	names := make([]string, len(rows))
+	for i, r := range rows {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func TestStatsView_Rows(t *testing.T) {
+	stats := []docker.ContainerStats{
+		{Name: "sonarr", CPUPercent: 5, MemUsage: 300, NetRx: 10, BlockRead: 900},
+		{Name: "plex", CPUPercent: 40, MemUsage: 100, NetRx: 500, NetTx: 500},
+		{Name: "radarr", CPUPercent: 5, MemUsage: 200, BlockWrite: 50},
+	}
+
+	tests := []struct {
+		name string
+		view statsView
+		want []string
+	}{
+		{"cpu, ties by name", statsView{sort: statsSortCPU}, []string{"plex", "radarr", "sonarr"}},
+		{"memory", statsView{sort: statsSortMem}, []string{"sonarr", "radarr", "plex"}},
+		{"network", statsView{sort: statsSortNet}, []string{"plex", "sonarr", "radarr"}},
+		{"block io", statsView{sort: statsSortIO}, []string{"sonarr", "radarr", "plex"}},
+		{"name", statsView{sort: statsSortName}, []string{"plex", "radarr", "sonarr"}},
+		{"reversed", statsView{sort: statsSortMem, reverse: true}, []string{"plex", "radarr", "sonarr"}},
+		{"filter is case-insensitive", statsView{sort: statsSortName, filter: "ARR"}, []string{"radarr", "sonarr"}},
+		{"filter matches nothing", statsView{sort: statsSortCPU, filter: "nope"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, statsNames(tt.view.rows(stats)))
+		})
+	}
+}
+
+func TestRenderStatsTable(t *testing.T) {
+	rows := []docker.ContainerStats{
+		{Name: "plex", CPUPercent: 12.345, MemUsage: 512 * 1024 * 1024, MemLimit: 2 * 1024 * 1024 * 1024, MemPercent: 25, NetRx: 2048, NetTx: 1024},
+		{Name: "a-very-long-container-name-that-keeps-going", CPUPercent: 1},
+		{Name: "sonarr"},
+	}
+
+	var buf bytes.Buffer
+	renderStatsTable(&buf, rows, 0, "\n")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "CONTAINER")
+	assert.Contains(t, lines[0], "BLOCK I/O")
+	assert.Contains(t, lines[1], "12.3%")
+	assert.Contains(t, lines[1], "512.0 MB / 2.0 GB")
+	assert.Contains(t, lines[1], "25.0%")
+	assert.Contains(t, lines[1], "2.0 KB / 1.0 KB")
+	assert.Contains(t, lines[2], "a-very-long-container-name-th~", "long names are truncated")
+
+	t.Run("limit", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderStatsTable(&buf, rows, 1, "\r\n")
+		assert.Equal(t, 3, strings.Count(buf.String(), "\r\n"))
+		assert.Contains(t, buf.String(), "... 2 more\r\n")
+	})
+}
+
+func TestStatsKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []byte
+	}{
+		{"plain keys", "cm/", []byte("cm/")},
+		{"lone esc", "\x1b", []byte{0x1b}},
+		{"arrow keys", "\x1b[A\x1b[B", nil},
+		{"keys around an arrow", "a\x1b[Db", []byte("ab")},
+		{"function key with parameters", "\x1b[15~q", []byte("q")},
+		{"ss3 arrow", "\x1bOAc", []byte("c")},
+		{"alt chord", "\x1bxq", []byte("q")},
+		{"esc twice", "\x1b\x1b", []byte{0x1b, 0x1b}},
+		{"esc then arrow", "\x1b\x1b[A", []byte{0x1b}},
+		{"truncated sequence", "\x1b[1;", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, statsKeys([]byte(tt.in)))
+		})
+	}
+}
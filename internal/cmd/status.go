@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/timezone"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -29,6 +31,7 @@ status information including:
   - Current state (idle or reconciling)
   - Last reconciliation time and result
   - Daemon uptime
+  - Container health, with how long each container has been unhealthy
 
 Examples:
   bosun daemon-status              # Show daemon status
@@ -38,7 +41,7 @@ Examples:
 }
 
 func init() {
-	daemonStatusCmd.Flags().StringVar(&statusSocket, "socket", "/var/run/bosun.sock", "Path to daemon socket")
+	daemonStatusCmd.Flags().StringVar(&statusSocket, "socket", daemon.DefaultSocketPath(), "Path to daemon socket")
 	daemonStatusCmd.Flags().IntVarP(&statusTimeout, "timeout", "t", 10, "Timeout in seconds")
 	daemonStatusCmd.Flags().BoolVar(&statusJSON, "json", false, "Output as JSON")
 
@@ -89,8 +92,8 @@ func printStatusHuman(status *daemon.StatusResponse, health *daemon.HealthStatus
 
 	// Last reconcile
 	if status.LastReconcile != nil {
-		ago := time.Since(*status.LastReconcile).Round(time.Second)
-		fmt.Printf("    Last Reconcile: %s ago\n", ago)
+		ago := timezone.Ago(*status.LastReconcile, time.Now())
+		fmt.Printf("    Last Reconcile: %s (%s)\n", timezone.Display(*status.LastReconcile), ago)
 	} else {
 		fmt.Printf("    Last Reconcile: never\n")
 	}
@@ -118,11 +121,54 @@ func printStatusHuman(status *daemon.StatusResponse, health *daemon.HealthStatus
 			readyColor = ui.Red
 		}
 		readyColor.Printf("  %s Ready: %v\n", readyIcon, health.Ready)
+
+		if health.Maintenance != "" {
+			ui.Yellow.Printf("  ◐ Maintenance window: %s (drift checks and alerts paused)\n", health.Maintenance)
+		}
+
+		if health.Host != nil {
+			fmt.Println()
+			ui.Blue.Println("--- Host ---")
+			printHostMetrics(health.Host)
+		}
+
+		if len(health.Containers) > 0 {
+			fmt.Println()
+			ui.Blue.Println("--- Container Health ---")
+			printContainerHealth(health.Containers, time.Now())
+		}
+
+		if len(health.StaleMounts) > 0 {
+			fmt.Println()
+			ui.Blue.Println("--- Bind Mounts ---")
+			for _, reason := range health.StaleMounts {
+				ui.Red.Printf("  ✗ %s\n", reason)
+			}
+		}
 	}
 
 	fmt.Println()
 }
 
+// printContainerHealth lists containers that aren't healthy with how long
+// they've been that way, then counts the healthy ones.
+func printContainerHealth(containers []daemon.ContainerHealth, now time.Time) {
+	healthy := 0
+	for _, c := range containers {
+		if c.Health == "healthy" {
+			healthy++
+			continue
+		}
+		line := fmt.Sprintf("  %s %s for %s\n", c.Name, c.Health, formatAge(now.Sub(c.Since)))
+		if c.Health == "unhealthy" {
+			ui.Red.Print(line)
+		} else {
+			ui.Yellow.Print(line)
+		}
+	}
+	ui.Green.Printf("  %d of %d healthy\n", healthy, len(containers))
+}
+
 func printStatusJSON(status *daemon.StatusResponse, health *daemon.HealthStatus) {
 	// Simple JSON output without external deps
 	fmt.Println("{")
@@ -130,7 +176,7 @@ func printStatusJSON(status *daemon.StatusResponse, health *daemon.HealthStatus)
 	fmt.Printf("  \"uptime\": \"%s\",\n", status.Uptime)
 
 	if status.LastReconcile != nil {
-		fmt.Printf("  \"last_reconcile\": \"%s\",\n", status.LastReconcile.Format(time.RFC3339))
+		fmt.Printf("  \"last_reconcile\": \"%s\",\n", status.LastReconcile.UTC().Format(time.RFC3339))
 	} else {
 		fmt.Printf("  \"last_reconcile\": null,\n")
 	}
@@ -143,7 +189,18 @@ func printStatusJSON(status *daemon.StatusResponse, health *daemon.HealthStatus)
 
 	if health != nil {
 		fmt.Printf("  \"health\": \"%s\",\n", health.Status)
-		fmt.Printf("  \"ready\": %v\n", health.Ready)
+		if health.Maintenance != "" {
+			fmt.Printf("  \"maintenance\": \"%s\",\n", escapeJSON(health.Maintenance))
+		}
+		if containers, err := json.Marshal(health.Containers); err == nil && len(health.Containers) > 0 {
+			fmt.Printf("  \"containers\": %s,\n", containers)
+		}
+		if host, err := json.Marshal(health.Host); err == nil && health.Host != nil {
+			fmt.Printf("  \"ready\": %v,\n", health.Ready)
+			fmt.Printf("  \"host\": %s\n", host)
+		} else {
+			fmt.Printf("  \"ready\": %v\n", health.Ready)
+		}
 	} else {
 		fmt.Printf("  \"health\": null,\n")
 		fmt.Printf("  \"ready\": null\n")
@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/timezone"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -17,6 +21,7 @@ var (
 	triggerToken   string
 	triggerSource  string
 	triggerTimeout int
+	triggerWait    bool
 )
 
 // triggerCmd represents the trigger command.
@@ -29,19 +34,25 @@ This command connects to the daemon's Unix socket and requests
 an immediate reconciliation. If a reconcile is already in progress,
 the request is queued and will run after the current one completes.
 
+With --wait, the daemon streams the reconcile's progress until the run
+serving this trigger finishes, and the exit code reflects its outcome.
+Ctrl-C cancels the reconcile on the daemon; press it again to detach.
+
 Examples:
   bosun trigger                    # Trigger with default source "cli"
   bosun trigger -s "github-push"   # Trigger with custom source
+  bosun trigger -w                 # Follow progress until the reconcile finishes
   bosun trigger --socket /tmp/bosun.sock  # Use custom socket path`,
 	Run: runTrigger,
 }
 
 func init() {
-	triggerCmd.Flags().StringVar(&triggerSocket, "socket", "/var/run/bosun.sock", "Path to daemon socket")
+	triggerCmd.Flags().StringVar(&triggerSocket, "socket", daemon.DefaultSocketPath(), "Path to daemon socket")
 	triggerCmd.Flags().StringVar(&triggerTCP, "tcp", "", "TCP address for remote daemon (e.g., host:9090)")
 	triggerCmd.Flags().StringVar(&triggerToken, "token", "", "Bearer token for TCP auth (or BOSUN_BEARER_TOKEN)")
 	triggerCmd.Flags().StringVarP(&triggerSource, "source", "s", "cli", "Source identifier for this trigger")
-	triggerCmd.Flags().IntVarP(&triggerTimeout, "timeout", "t", 30, "Timeout in seconds")
+	triggerCmd.Flags().IntVarP(&triggerTimeout, "timeout", "t", 30, "Timeout in seconds (connecting only with --wait)")
+	triggerCmd.Flags().BoolVarP(&triggerWait, "wait", "w", false, "Stream progress and wait for the reconcile to finish")
 
 	rootCmd.AddCommand(triggerCmd)
 }
@@ -76,6 +87,11 @@ func runTrigger(cmd *cobra.Command, args []string) {
 		ui.Fatal("Cannot connect to daemon at %s: %v", endpoint, err)
 	}
 
+	if triggerWait {
+		waitForTrigger(client)
+		return
+	}
+
 	// Trigger reconciliation
 	resp, err := client.Trigger(ctx, triggerSource)
 	if err != nil {
@@ -84,3 +100,62 @@ func runTrigger(cmd *cobra.Command, args []string) {
 
 	ui.Success("Reconciliation %s: %s", resp.Status, resp.Message)
 }
+
+// waitForTrigger triggers a reconcile and prints its progress until it
+// finishes. The first interrupt cancels the reconcile on the daemon; the
+// second stops following.
+func waitForTrigger(client *daemon.Client) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		<-sigCh
+		ui.Warning("Cancelling reconciliation (interrupt again to detach)...")
+		cancelCtx, cancel := context.WithTimeout(context.Background(), time.Duration(triggerTimeout)*time.Second)
+		if _, err := client.Cancel(cancelCtx); err != nil {
+			ui.Error("Failed to cancel: %v", err)
+		}
+		cancel()
+		<-sigCh
+		stop()
+	}()
+
+	final, err := client.TriggerStream(ctx, triggerSource, printTriggerEvent)
+	if err != nil {
+		if ctx.Err() != nil {
+			ui.Warning("Detached; the reconcile may still be running (see 'bosun daemon-status')")
+			os.Exit(1)
+		}
+		ui.Fatal("Failed to follow reconciliation: %v", err)
+	}
+
+	switch final.Status {
+	case daemon.StatusSucceeded:
+		ui.Success("Reconciliation succeeded")
+	case daemon.StatusCancelled:
+		ui.Fatal("Reconciliation cancelled")
+	default:
+		ui.Fatal("Reconciliation failed: %s", final.Error)
+	}
+}
+
+// printTriggerEvent prints one streamed reconcile event.
+func printTriggerEvent(e daemon.Event) {
+	when := timezone.Format(e.Time, "15:04:05")
+	switch e.Type {
+	case daemon.EventAccepted:
+		ui.Info("%s %s", when, e.Message)
+	case daemon.EventStarted:
+		ui.Info("%s Reconciliation started (source: %s)", when, e.Message)
+	case daemon.EventProgress:
+		ui.Cyan.Printf("%s [%s] ", when, e.Step)
+		fmt.Println(e.Message)
+	case daemon.EventDone:
+		if e.Queued {
+			ui.Info("%s Run %s; a queued trigger runs next", when, e.Status)
+		}
+	}
+}
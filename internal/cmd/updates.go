@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/registry"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var (
+	updatesJSON  bool
+	updatesApply bool
+	updatesPR    bool
+	updatesNotes bool
+)
+
+// updatesCmd checks registries for newer images of the rendered services.
+var updatesCmd = &cobra.Command{
+	Use:     "updates",
+	Aliases: []string{"lookout"},
+	Short:   "Check registries for newer images",
+	Long: `Updates reads the images of the rendered compose files (from 'bosun
+provision') and asks their registries (Docker Hub, ghcr.io, or any registry
+with the v2 API) for newer versions:
+
+  - Version tags (1.2.3, v2.1, 16-alpine) are compared with the repository's
+    tags of the same shape, so 1.2.3 moves to 1.2.4 but never to 1.3,
+    1.2.4-rc1, or 17-alpine.
+  - Images pinned to a digest (1.2.3@sha256:...) report when the tag now
+    points elsewhere.
+  - Floating tags such as latest are compared with the digest the running
+    container's image was pulled by; a new digest means a pull and redeploy
+    picks up a newer image. They are skipped when the service isn't running.
+
+With --release-notes, each newer tag's GitHub release is summarized under
+its update. The source repository comes from the image's
+org.opencontainers.image.source annotation, or from the release_sources:
+map in bosun.yml for images without one. $GITHUB_TOKEN, when set, raises
+the GitHub API rate limit.
+
+With --apply, each update is written to the service manifest that sets the
+image, as 'bosun bump' would, after the render is linted. New digests behind
+floating tags have nothing to write and are left to the next deploy. With --pr, the
+changed manifests are committed on a new branch and a pull request is opened
+(see 'bosun bump --pr').
+
+Examples:
+  bosun updates
+  bosun updates --json
+  bosun updates --release-notes
+  bosun updates --apply
+  bosun updates --pr`,
+	Args: cobra.NoArgs,
+	RunE: runUpdates,
+}
+
+func init() {
+	updatesCmd.Flags().BoolVar(&updatesJSON, "json", false, "Output as JSON")
+	updatesCmd.Flags().BoolVar(&updatesApply, "apply", false, "Write the updates to the service manifests")
+	updatesCmd.Flags().BoolVar(&updatesNotes, "release-notes", false, "Summarize the GitHub release notes of each newer tag")
+	updatesCmd.Flags().BoolVar(&updatesPR, "pr", false, "Apply the updates on a new branch and open a pull request")
+	updatesCmd.Flags().StringVar(&prBase, "base", "", "Base branch for the pull request (default: current branch)")
+	updatesCmd.Flags().StringVar(&prForgeAPI, "forge-api", "", "Forge API base URL (default: derived from the origin remote)")
+
+	rootCmd.AddCommand(updatesCmd)
+}
+
+// imageUpdate is one service's update check, as reported by --json.
+type imageUpdate struct {
+	Stack   string `json:"stack"`
+	Service string `json:"service"`
+	Image   string `json:"image"`
+	Update  string `json:"update,omitempty"` // The image to move to
+	Digest  string `json:"digest,omitempty"` // New digest behind a floating tag
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	// Release and ReleaseError are set with --release-notes.
+	Release      *registry.Release `json:"release,omitempty"`
+	ReleaseError string            `json:"releaseError,omitempty"`
+}
+
+func runUpdates(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	images, err := registry.ComposeImages(composeDir)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no images in %s; run 'bosun provision' first", composeDir)
+	}
+
+	if !updatesJSON {
+		ui.Blue.Printf("Checking %d image(s) for updates...\n", len(images))
+	}
+	setRunningDigests(cmd.Context(), images)
+	client := registry.NewClient()
+	client.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	var sources map[string]string
+	if updatesNotes {
+		sources = config.LoadReleaseSources(cfg.Root)
+	}
+	results := make([]imageUpdate, 0, len(images))
+	for _, img := range images {
+		ctx, cancel := context.WithTimeout(cmd.Context(), registry.DefaultTimeout)
+		res := client.Check(ctx, img)
+		u := toImageUpdate(res)
+		if updatesNotes && u.Update != "" && res.Latest != "" {
+			release, err := releaseNotes(ctx, client, sources, res)
+			if err != nil {
+				u.ReleaseError = err.Error()
+			} else {
+				u.Release = &release
+			}
+		}
+		results = append(results, u)
+		cancel()
+	}
+
+	if updatesJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	var available []imageUpdate
+	redeploys := 0
+	for _, u := range results {
+		name := u.Stack + "/" + u.Service
+		switch {
+		case u.Error != "":
+			ui.Red.Printf("  x %s: %s\n", name, u.Error)
+		case u.Digest != "":
+			ui.Yellow.Printf("  ^ %s: %s has a new digest %s (redeploy to pull)\n", name, u.Image, u.Digest)
+			redeploys++
+		case u.Update != "":
+			ui.Yellow.Printf("  ^ %s: %s -> %s\n", name, u.Image, u.Update)
+			printRelease(u)
+			available = append(available, u)
+		case u.Skipped != "":
+			ui.Blue.Printf("  - %s: skipped (%s)\n", name, u.Skipped)
+		default:
+			ui.Green.Printf("  * %s: %s\n", name, u.Image)
+		}
+	}
+	fmt.Println()
+	if len(available) == 0 && redeploys == 0 {
+		ui.Success("All images are up to date")
+		return nil
+	}
+	if redeploys > 0 {
+		ui.Info("%d floating tag(s) have a newer image; redeploy to pull them", redeploys)
+	}
+	if len(available) == 0 {
+		return nil
+	}
+	ui.Info("%d update(s) available", len(available))
+	if !updatesApply && !updatesPR {
+		ui.Info("Run 'bosun updates --apply' or 'bosun updates --pr' to update the manifests")
+		return nil
+	}
+
+	loadHostFacts(cmd.Context())
+	loadServicePorts(cfg.ServicesDir())
+	paths, applied := applyImageUpdates(cfg, available)
+	if len(paths) == 0 {
+		return fmt.Errorf("no manifests updated")
+	}
+	if !updatesPR {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), DefaultOperationTimeout*2)
+	defer cancel()
+	url, err := openManifestPR(ctx, cfg.Root, manifestPR{
+		Paths:  paths,
+		Branch: "bosun/updates-" + time.Now().Format("20060102-150405"),
+		Title:  fmt.Sprintf("Update %d image(s)", len(applied)),
+		Body:   "Image updates found by `bosun updates`; lint passed.\n\n- " + strings.Join(applied, "\n- "),
+	})
+	if err != nil {
+		return fmt.Errorf("open pull request: %w", err)
+	}
+	ui.Success("Opened pull request: %s", url)
+	return nil
+}
+
+// toImageUpdate reports a registry check result.
+func toImageUpdate(res registry.Result) imageUpdate {
+	u := imageUpdate{Stack: res.Stack, Service: res.Service, Image: res.Image.Image, Skipped: res.Skipped}
+	switch {
+	case res.Err != nil:
+		u.Error = res.Err.Error()
+	case res.Redeploy():
+		u.Digest = res.Digest
+	case res.HasUpdate():
+		if image, err := manifest.WithTag(res.Image.Image, res.Tag()); err == nil {
+			u.Update = image
+		} else {
+			u.Error = err.Error()
+		}
+	}
+	return u
+}
+
+// setRunningDigests records the digests the running services' images were
+// pulled by, so floating tags can be checked. Without Docker they are
+// skipped.
+func setRunningDigests(ctx context.Context, images []registry.Image) {
+	client, err := docker.NewClient()
+	if err != nil {
+		return
+	}
+	defer client.Close()
+	digests, err := client.ServiceRepoDigests(ctx)
+	if err != nil {
+		return
+	}
+	registry.SetRunning(images, digests, func(stack string) string {
+		return reconcile.ComposeProjectName(stack + ".yml")
+	})
+}
+
+// releaseNotes reads the GitHub release of an update's newer tag, from the
+// source repository configured for the image or, failing that, the one its
+// OCI source annotation names.
+func releaseNotes(ctx context.Context, client *registry.Client, sources map[string]string, res registry.Result) (registry.Release, error) {
+	name, _ := splitRef(res.Image.Image)
+	source := sources[name]
+	if source == "" {
+		ref, err := registry.ParseRef(res.Image.Image)
+		if err != nil {
+			return registry.Release{}, err
+		}
+		if source, err = client.Source(ctx, ref, res.Latest); err != nil {
+			return registry.Release{}, fmt.Errorf("%w (map the image under release_sources: in bosun.yml)", err)
+		}
+	}
+	return client.ReleaseNotes(ctx, source, res.Latest)
+}
+
+// printRelease prints an update's release notes summary, indented under it.
+func printRelease(u imageUpdate) {
+	switch {
+	case u.ReleaseError != "":
+		ui.Blue.Printf("      release notes: %s\n", u.ReleaseError)
+	case u.Release != nil:
+		fmt.Printf("      %s  %s\n", u.Release.Name, u.Release.URL)
+		for _, line := range strings.Split(u.Release.Summary, "\n") {
+			if line != "" {
+				fmt.Printf("        %s\n", line)
+			}
+		}
+	}
+}
+
+// applyImageUpdates writes updates to the service manifests that set the
+// images, one file at a time so a manifest with several updated images
+// (its own and sidecars') is linted once. It returns the changed manifests
+// and a line per applied update.
+func applyImageUpdates(cfg *config.Config, updates []imageUpdate) (paths, applied []string) {
+	byPath := make(map[string][]imageUpdate)
+	sidecars := make(map[imageUpdate]string)
+	seen := make(map[string]bool) // Manifest images already queued, for services in several stacks
+	for _, u := range updates {
+		path, sidecar, err := imageManifest(cfg.ServicesDir(), u.Service)
+		if err != nil {
+			ui.Warning("Skipping %s/%s: %v", u.Stack, u.Service, err)
+			continue
+		}
+		if seen[path+"#"+sidecar] {
+			continue
+		}
+		seen[path+"#"+sidecar] = true
+		byPath[path] = append(byPath[path], u)
+		sidecars[u] = sidecar
+	}
+
+	files := make([]string, 0, len(byPath))
+	for path := range byPath {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		before, err := os.ReadFile(path)
+		if err != nil {
+			ui.Warning("Skipping %s: %v", path, err)
+			continue
+		}
+		after := before
+		var lines []string
+		for _, u := range byPath[path] {
+			_, tag := splitRef(u.Update)
+			next, old, err := manifest.SetServiceImage(after, sidecars[u], tag)
+			if err == nil && old != u.Image {
+				err = fmt.Errorf("manifest sets %s, not the rendered %s", old, u.Image)
+			}
+			if err != nil {
+				ui.Warning("Skipping %s/%s: %v", u.Stack, u.Service, err)
+				continue
+			}
+			after = next
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", u.Service, u.Image, u.Update))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		_, lintErrors, err := bumpRenderDiff(before, after, cfg.ProvisionsDir())
+		if err == nil && len(lintErrors) > 0 {
+			err = fmt.Errorf("lint failed: %s", strings.Join(lintErrors, "; "))
+		}
+		if err != nil {
+			ui.Warning("Skipping %s: %v", path, err)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err == nil {
+			err = os.WriteFile(path, after, info.Mode().Perm())
+		}
+		if err != nil {
+			ui.Warning("Failed to write %s: %v", path, err)
+			continue
+		}
+		ui.Success("Updated %s", path)
+		paths = append(paths, path)
+		applied = append(applied, lines...)
+	}
+	return paths, applied
+}
+
+// imageManifest finds the service manifest that sets a rendered service's
+// image: <service>.yml, or the manifest with <service> as a sidecar, which
+// is returned too.
+func imageManifest(servicesDir, service string) (path, sidecar string, err error) {
+	path = filepath.Join(servicesDir, service+".yml")
+	if _, err := os.Stat(path); err == nil {
+		return path, "", nil
+	}
+
+	files, _ := filepath.Glob(filepath.Join(servicesDir, "*.yml"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var m struct {
+			Services map[string]any `yaml:"services"`
+		}
+		if yaml.Unmarshal(data, &m) != nil {
+			continue
+		}
+		if _, ok := m.Services[service]; ok {
+			return file, service, nil
+		}
+	}
+	return "", "", fmt.Errorf("no service manifest sets its image")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/registry"
+)
+
+func TestImageManifest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "immich.yml"), []byte(`name: immich
+config:
+  image: ghcr.io/immich-app/immich-server:v1.2.3
+services:
+  postgres:
+    image: postgres:16-alpine
+`), 0644))
+
+	path, sidecar, err := imageManifest(dir, "immich")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "immich.yml"), path)
+	assert.Empty(t, sidecar)
+
+	path, sidecar, err = imageManifest(dir, "postgres")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "immich.yml"), path)
+	assert.Equal(t, "postgres", sidecar)
+
+	_, _, err = imageManifest(dir, "redis")
+	assert.Error(t, err)
+}
+
+func TestToImageUpdate(t *testing.T) {
+	img := registry.Image{Stack: "media", Service: "sonarr", Image: "lscr.io/linuxserver/sonarr:4.0.2"}
+
+	u := toImageUpdate(registry.Result{Image: img, Latest: "4.0.3"})
+	assert.Equal(t, "lscr.io/linuxserver/sonarr:4.0.3", u.Update)
+
+	u = toImageUpdate(registry.Result{Image: img})
+	assert.Empty(t, u.Update)
+
+	u = toImageUpdate(registry.Result{Image: img, Err: errors.New("registry returned 429")})
+	assert.Equal(t, "registry returned 429", u.Error)
+
+	pinned := registry.Image{Stack: "db", Service: "postgres", Image: "postgres:16@sha256:old"}
+	u = toImageUpdate(registry.Result{Image: pinned, Digest: "sha256:new"})
+	assert.Equal(t, "postgres:16@sha256:new", u.Update)
+
+	floating := registry.Image{Stack: "web", Service: "nginx", Image: "nginx:latest", Running: "sha256:old"}
+	u = toImageUpdate(registry.Result{Image: floating, Digest: "sha256:new"})
+	assert.Equal(t, "sha256:new", u.Digest)
+	assert.Empty(t, u.Update, "nothing to write to the manifest")
+}
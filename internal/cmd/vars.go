@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+var varsJSON bool
+
+// varsCmd lists the variables provisions consume.
+var varsCmd = &cobra.Command{
+	Use:     "vars [provision]",
+	Aliases: []string{"cargo"},
+	Short:   "List the variables each provision consumes",
+	Long: `List every ${var} a provision consumes, including through its includes,
+with its default and the services whose config sets it.
+
+Variables are found by reading the provision files, not by rendering them, so
+the listing covers every service that could use the provision. A variable
+with no default must be set by each service that uses the provision; the
+defaults in parentheses are set by bosun itself.
+
+Examples:
+  bosun vars            # Every provision
+  bosun vars webapp     # One provision
+  bosun vars --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVars,
+}
+
+func init() {
+	varsCmd.Flags().BoolVar(&varsJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(varsCmd)
+}
+
+// provisionVars is one provision in the vars listing.
+type provisionVars struct {
+	Provision string                       `json:"provision"`
+	Variables []manifest.ProvisionVariable `json:"variables"`
+}
+
+func runVars(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	var provisions []string
+	if len(args) == 1 {
+		if !manifest.ProvisionExists(args[0], cfg.ProvisionsDir()) {
+			return fmt.Errorf("provision not found: %s", args[0])
+		}
+		provisions = args
+	} else {
+		if provisions, err = manifest.ListProvisions(cfg.ProvisionsDir()); err != nil {
+			return fmt.Errorf("list provisions: %w", err)
+		}
+		sort.Strings(provisions)
+	}
+
+	services, err := loadServiceManifests(cfg.ServicesDir())
+	if err != nil {
+		return err
+	}
+
+	listing := make([]provisionVars, 0, len(provisions))
+	for _, name := range provisions {
+		vars, err := manifest.ProvisionVariables(name, cfg.ProvisionsDir(), services)
+		if err != nil {
+			return err
+		}
+		listing = append(listing, provisionVars{Provision: name, Variables: vars})
+	}
+
+	if varsJSON {
+		data, err := json.MarshalIndent(listing, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal variables: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(listing) == 0 {
+		fmt.Println("No provisions found")
+		return nil
+	}
+	for i, p := range listing {
+		if i > 0 {
+			fmt.Println()
+		}
+		ui.Blue.Printf("--- %s ---\n", p.Provision)
+		printProvisionVars(cmd.OutOrStdout(), p)
+	}
+	return nil
+}
+
+// printProvisionVars writes a provision's variables as an aligned table.
+// The PROVISION column only appears when includes contribute variables.
+func printProvisionVars(out io.Writer, p provisionVars) {
+	if len(p.Variables) == 0 {
+		fmt.Fprintln(out, "  No variables")
+		return
+	}
+
+	included := false
+	for _, v := range p.Variables {
+		if len(v.Provisions) != 1 || v.Provisions[0] != p.Provision {
+			included = true
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := "  VARIABLE\tDEFAULT\tSET BY"
+	if included {
+		header += "\tPROVISION"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, v := range p.Variables {
+		def := v.Default
+		if v.Required() {
+			def = "required"
+		}
+		setBy := strings.Join(v.SetBy, ", ")
+		if setBy == "" {
+			setBy = "-"
+		}
+		row := fmt.Sprintf("  %s\t%s\t%s", v.Name, def, setBy)
+		if included {
+			row += "\t" + strings.Join(v.Provisions, ", ")
+		}
+		fmt.Fprintln(w, row)
+	}
+	_ = w.Flush()
+}
+
+// loadServiceManifests loads every service manifest in dir, skipping files
+// that fail to parse (lint reports those).
+func loadServiceManifests(dir string) ([]*manifest.ServiceManifest, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Loading logs apiVersion warnings for every unversioned service; they
+	// are noise here.
+	prevLogOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(prevLogOutput)
+
+	var services []*manifest.ServiceManifest
+	for _, file := range files {
+		m, err := manifest.LoadServiceManifest(file)
+		if err != nil {
+			continue
+		}
+		services = append(services, m)
+	}
+	return services, nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+func TestVarsCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "vars", "--help")
+	require.NoError(t, err)
+	assert.Contains(t, output, "vars [provision]")
+	assert.Contains(t, output, "cargo")
+	assert.Contains(t, output, "--json")
+}
+
+func TestPrintProvisionVars(t *testing.T) {
+	t.Run("own variables", func(t *testing.T) {
+		var buf bytes.Buffer
+		printProvisionVars(&buf, provisionVars{Provision: "container", Variables: []manifest.ProvisionVariable{
+			{Name: "image", Provisions: []string{"container"}, SetBy: []string{"myapp", "other"}},
+			{Name: "name", Default: "(service name)", Provisions: []string{"container"}},
+		}})
+
+		out := buf.String()
+		assert.Contains(t, out, "VARIABLE")
+		assert.NotContains(t, out, "PROVISION")
+		assert.Regexp(t, `image\s+required\s+myapp, other`, out)
+		assert.Regexp(t, `name\s+\(service name\)\s+-`, out)
+	})
+
+	t.Run("included variables", func(t *testing.T) {
+		var buf bytes.Buffer
+		printProvisionVars(&buf, provisionVars{Provision: "webapp", Variables: []manifest.ProvisionVariable{
+			{Name: "port", Provisions: []string{"healthcheck", "reverse-proxy"}},
+		}})
+		assert.Regexp(t, `port\s+required\s+-\s+healthcheck, reverse-proxy`, buf.String())
+	})
+
+	t.Run("no variables", func(t *testing.T) {
+		var buf bytes.Buffer
+		printProvisionVars(&buf, provisionVars{Provision: "static"})
+		assert.Contains(t, buf.String(), "No variables")
+	})
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/selector"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/timezone"
+	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/verify"
+)
+
+var (
+	verifyRemote   string
+	verifyJSON     bool
+	verifyHistory  bool
+	verifyStateDir string
+	verifySelector string
+)
+
+// verifyCmd runs the smoke tests declared by service manifests.
+var verifyCmd = &cobra.Command{
+	Use:     "verify [stack]",
+	Aliases: []string{"soundings"},
+	Short:   "Run smoke tests against deployed services",
+	Long: `Run the smoke tests that service manifests declare under verify:.
+
+HTTP tests send a request from this machine and check the status code and
+(optionally) that the body contains a string. Exec tests run a command in
+the service's container and check its exit code. Reconciles run the same
+tests after every deploy, recording results and alerting on failure.
+
+--selector filters tests by their service's compose labels, plus the
+bosun.stack and bosun.service pseudo-labels: key=value, key!=value, or key,
+comma-separated, all of which must match.
+
+Run 'bosun provision' first; tests are read from the rendered compose files.
+
+Examples:
+  bosun verify                        # Run every smoke test
+  bosun verify media                  # Run the media stack's tests
+  bosun verify -l bosun.stack=infra   # Run tests for services matching a selector
+  bosun verify --remote root@tower    # Exec tests on a remote Docker host
+  bosun verify --history              # Show recent post-deploy results`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyRemote, "remote", "", "Run exec tests on a remote Docker host over SSH (e.g., root@192.168.1.8)")
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Output results as JSON")
+	verifyCmd.Flags().BoolVar(&verifyHistory, "history", false, "Show the post-deploy verification history instead of running tests")
+	verifyCmd.Flags().StringVarP(&verifySelector, "selector", "l", "", "Only run tests for services matching a label selector (e.g., bosun.stack=infra,app=arr)")
+	verifyCmd.Flags().StringVar(&verifyStateDir, "state-dir", "", "State directory for --history (default: $BOSUN_STATE_DIR, $STATE_DIR, or /app/state)")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if verifyHistory {
+		return showVerifyHistory()
+	}
+
+	sel, err := selector.Parse(verifySelector)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	stack := ""
+	if len(args) == 1 {
+		stack = args[0]
+		if !stackNameRegex.MatchString(stack) {
+			return fmt.Errorf("invalid stack name: %s", stack)
+		}
+	}
+
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	checks, err := verify.LoadDir(composeDir, stack)
+	if err != nil {
+		return err
+	}
+	checks = verify.Select(checks, sel)
+
+	var results []verify.Result
+	if len(checks) > 0 {
+		results = verify.NewRunner(verifyRemote).Run(cmd.Context(), checks)
+	}
+
+	if verifyJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal results: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		if len(checks) == 0 {
+			if !sel.Empty() {
+				ui.Info("No smoke tests match %s", sel)
+				return nil
+			}
+			ui.Info("No smoke tests declared in %s", composeDir)
+			return nil
+		}
+		ui.Blue.Println("--- Smoke Tests ---")
+		for _, res := range results {
+			if res.OK {
+				ui.Green.Printf("  * %s/%s/%s (%s)\n", res.Stack, res.Service, res.Name, res.Duration.Round(time.Millisecond))
+			} else {
+				ui.Red.Printf("  x %s\n", res)
+			}
+		}
+	}
+
+	if failed := verify.Failed(results); len(failed) > 0 {
+		return fmt.Errorf("%d of %d smoke tests failed", len(failed), len(results))
+	}
+	return nil
+}
+
+// showVerifyHistory prints the post-deploy verification runs recorded by
+// reconciles, newest first.
+func showVerifyHistory() error {
+	st, err := stateStore(verifyStateDir).Load()
+	if err != nil {
+		return err
+	}
+
+	if verifyJSON {
+		for _, v := range st.Verifications {
+			for i, f := range v.Failures {
+				v.Failures[i] = state.Display(f)
+			}
+		}
+		data, err := json.MarshalIndent(st.Verifications, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal history: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(st.Verifications) == 0 {
+		ui.Info("No post-deploy verifications recorded")
+		return nil
+	}
+
+	ui.Blue.Println("--- Verification History ---")
+	for i := len(st.Verifications) - 1; i >= 0; i-- {
+		v := st.Verifications[i]
+		when := timezone.Display(v.At)
+		if v.OK() {
+			ui.Green.Printf("  * %s %s: %d passed\n", when, shortCommit(v.Commit), v.Passed)
+			continue
+		}
+		ui.Red.Printf("  x %s %s: %d passed, %d failed\n", when, shortCommit(v.Commit), v.Passed, len(v.Failures))
+		for _, f := range v.Failures {
+			fmt.Printf("      %s\n", state.Display(f))
+		}
+	}
+	return nil
+}
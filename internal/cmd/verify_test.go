@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCmd_Help(t *testing.T) {
+	output, err := executeCmd(t, "verify", "--help")
+	require.NoError(t, err)
+	assert.Contains(t, output, "smoke tests")
+	assert.Contains(t, output, "--history")
+}
+
+func TestRunVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	composeDir := filepath.Join(tmpDir, "manifest", "output", "compose")
+	require.NoError(t, os.MkdirAll(composeDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "apps.yml"), []byte("services:\n  redis:\n    image: redis:7\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bosun.yaml"), []byte("root: .\nmanifest_dir: manifest\n"), 0644))
+
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	t.Run("no tests declared", func(t *testing.T) {
+		require.NoError(t, runVerify(verifyCmd, nil))
+	})
+
+	t.Run("unknown stack", func(t *testing.T) {
+		err := runVerify(verifyCmd, []string{"media"})
+		assert.ErrorContains(t, err, "stack not found")
+	})
+
+	t.Run("invalid stack name", func(t *testing.T) {
+		err := runVerify(verifyCmd, []string{"../etc"})
+		assert.ErrorContains(t, err, "invalid stack name")
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		verifyHistory, verifyStateDir = true, t.TempDir()
+		t.Cleanup(func() { verifyHistory, verifyStateDir = false, "" })
+		require.NoError(t, runVerify(verifyCmd, nil))
+	})
+}
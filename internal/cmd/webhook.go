@@ -12,13 +12,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/cameronsjo/bosun/internal/alert"
 	"github.com/cameronsjo/bosun/internal/daemon"
+	"github.com/cameronsjo/bosun/internal/reconcile"
 	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/webhook"
 )
 
 var (
@@ -26,6 +31,10 @@ var (
 	webhookSocket     string
 	webhookSecret     string
 	webhookFetchSecret bool
+	webhookQueuePath   string
+	webhookQueueMaxAge time.Duration
+	webhookBranches    []string
+	webhookPaths       []string
 )
 
 // webhookCmd represents the webhook command.
@@ -41,6 +50,19 @@ receiver in a container while the daemon runs on the host.
 The webhook server validates signatures and forwards valid requests
 to the daemon's trigger endpoint.
 
+QUEUED TRIGGERS:
+  Pushes received while the daemon socket is unreachable (e.g. during a
+  daemon restart) are queued on disk and answered with 202 "queued". The
+  queue is retried with backoff and delivered as a single trigger once the
+  daemon is back. Triggers older than --queue-max-age are dropped with an
+  alert. A max age of 0 disables the queue.
+
+PUSH FILTERS:
+  --branches and --paths limit which GitHub, GitLab, Gitea, and Bitbucket
+  pushes trigger a reconcile, so doc-only pushes don't deploy. Paths are
+  globs in which ** matches any number of directories; a push triggers
+  when any changed file matches. Ignored pushes get 200 "ignored".
+
 DAEMON-INJECTED SECRETS:
   Use --fetch-secret to have the webhook server fetch the webhook secret
   from the daemon at startup. This way the secret is never stored on disk
@@ -48,23 +70,37 @@ DAEMON-INJECTED SECRETS:
 
 Configuration:
   --port          HTTP port to listen on (default: 8080)
-  --socket        Path to daemon socket (default: /var/run/bosun.sock)
+  --socket        Path to daemon socket (default: /var/run/bosun.sock, or
+                  $XDG_RUNTIME_DIR/bosun.sock for non-root users)
   --secret        Webhook secret for signature validation
   --fetch-secret  Fetch secret from daemon (never stored on disk)
+  --queue-file    Path to the trigger queue (default: webhook-queue.json
+                  in $BOSUN_STATE_DIR, $STATE_DIR, or /app/state)
+  --queue-max-age How long a queued trigger waits before it is dropped
+                  (default: 1h)
+  --branches      Branch globs that trigger (default: $WEBHOOK_BRANCHES,
+                  or any branch)
+  --paths         Changed-file globs that trigger (default: $WEBHOOK_PATHS,
+                  or any file)
 
 Examples:
   bosun webhook                           # Listen on :8080
   bosun webhook --port 9000               # Listen on :9000
   bosun webhook --secret mywebhooksecret  # With signature validation
-  bosun webhook --fetch-secret            # Fetch secret from daemon`,
+  bosun webhook --fetch-secret            # Fetch secret from daemon
+  bosun webhook --branches main --paths 'manifest/**,infrastructure/**'`,
 	Run: runWebhook,
 }
 
 func init() {
 	webhookCmd.Flags().IntVarP(&webhookPort, "port", "p", 8080, "HTTP port to listen on")
-	webhookCmd.Flags().StringVar(&webhookSocket, "socket", "/var/run/bosun.sock", "Path to daemon socket")
+	webhookCmd.Flags().StringVar(&webhookSocket, "socket", daemon.DefaultSocketPath(), "Path to daemon socket")
 	webhookCmd.Flags().StringVar(&webhookSecret, "secret", "", "Webhook secret for signature validation")
 	webhookCmd.Flags().BoolVar(&webhookFetchSecret, "fetch-secret", false, "Fetch webhook secret from daemon (daemon-injected secrets)")
+	webhookCmd.Flags().StringVar(&webhookQueuePath, "queue-file", "", "Path to the trigger queue (default: webhook-queue.json in the state directory)")
+	webhookCmd.Flags().DurationVar(&webhookQueueMaxAge, "queue-max-age", defaultWebhookQueueMaxAge, "Drop queued triggers older than this (0 disables the queue)")
+	webhookCmd.Flags().StringSliceVar(&webhookBranches, "branches", nil, "Only trigger on pushes to these branch globs (default: $WEBHOOK_BRANCHES)")
+	webhookCmd.Flags().StringSliceVar(&webhookPaths, "paths", nil, "Only trigger when a changed file matches these globs (default: $WEBHOOK_PATHS)")
 
 	rootCmd.AddCommand(webhookCmd)
 }
@@ -106,6 +142,27 @@ func runWebhook(cmd *cobra.Command, args []string) {
 	handler := &webhookHandler{
 		client: client,
 		secret: secret,
+		alerts: createAlertManager(),
+		filter: webhook.FilterFromEnv(webhookBranches, webhookPaths),
+	}
+
+	// Queue triggers while the daemon is unreachable
+	queueCtx, stopQueue := context.WithCancel(context.Background())
+	defer stopQueue()
+	if webhookQueueMaxAge > 0 {
+		path := webhookQueuePath
+		if path == "" {
+			path = filepath.Join(reconcile.ResolveStateDir(""), webhookQueueFile)
+		}
+		queue, err := loadWebhookQueue(path, webhookQueueMaxAge)
+		if err != nil {
+			ui.Warning("Could not load webhook queue, starting empty: %v", err)
+		}
+		if n := queue.Len(); n > 0 {
+			ui.Info("%d queued webhook trigger(s) pending", n)
+		}
+		handler.queue = queue
+		go queue.Run(queueCtx, handler.trigger, handler.alertDropped)
 	}
 
 	mux := http.NewServeMux()
@@ -134,6 +191,15 @@ func runWebhook(cmd *cobra.Command, args []string) {
 			ui.Warning("Signature validation: disabled (set WEBHOOK_SECRET)")
 		}
 		ui.Info("Forwarding to daemon at %s", webhookSocket)
+		if handler.queue != nil {
+			ui.Info("Trigger queue: %s (max age %s)", handler.queue.path, webhookQueueMaxAge)
+		}
+		if branches := handler.filter.Branches; len(branches) > 0 {
+			ui.Info("Branch filter: %s", strings.Join(branches, ", "))
+		}
+		if paths := handler.filter.Paths; len(paths) > 0 {
+			ui.Info("Path filter: %s", strings.Join(paths, ", "))
+		}
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			ui.Fatal("Server error: %v", err)
@@ -158,6 +224,73 @@ func runWebhook(cmd *cobra.Command, args []string) {
 type webhookHandler struct {
 	client *daemon.Client
 	secret string
+	queue  *webhookQueue // Nil when queuing is disabled
+	alerts *alert.Manager
+	filter webhook.Filter
+}
+
+// trigger asks the daemon to reconcile on behalf of source.
+func (h *webhookHandler) trigger(ctx context.Context, source string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	_, err := h.client.Trigger(ctx, source)
+	return err
+}
+
+// forward triggers a reconcile for source and writes the daemon's response.
+// When the daemon is unreachable the trigger is queued and the sender gets
+// 202 with status "queued", so the push isn't lost or retried by the
+// provider. Returns whether the trigger was accepted.
+func (h *webhookHandler) forward(w http.ResponseWriter, r *http.Request, source string) bool {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	resp, err := h.client.Trigger(ctx, source)
+	if err != nil {
+		if h.queue != nil && isDaemonUnreachable(err) {
+			if qerr := h.queue.Add(source); qerr != nil {
+				// Still queued in memory; only a restart would lose it
+				ui.Warning("Could not persist webhook queue: %v", qerr)
+			}
+			ui.Warning("Daemon unreachable, queued trigger from %s (%d pending)", source, h.queue.Len())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "queued", "source": source})
+			return true
+		}
+		ui.Error("Failed to trigger daemon: %v", err)
+		http.Error(w, "Failed to trigger reconciliation", http.StatusBadGateway)
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(resp)
+	return true
+}
+
+// alertDropped reports queued triggers that expired before the daemon came
+// back.
+func (h *webhookHandler) alertDropped(dropped []queuedTrigger) {
+	oldest := dropped[0].ReceivedAt
+	ui.Error("Dropped %d queued webhook trigger(s) older than %s; daemon unreachable since %s",
+		len(dropped), webhookQueueMaxAge, oldest.Local().Format(time.RFC3339))
+
+	if h.alerts == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := h.alerts.Send(ctx, &alert.Alert{
+		Title:    "Webhook Triggers Dropped",
+		Message:  fmt.Sprintf("%d webhook trigger(s) were dropped after waiting %s for the bosun daemon. Pushes since %s may not be deployed; run 'bosun trigger' once the daemon is up.", len(dropped), webhookQueueMaxAge, oldest.Format(time.RFC3339)),
+		Severity: alert.SeverityWarning,
+		Source:   "webhook",
+		Metadata: map[string]string{"dropped": fmt.Sprint(len(dropped)), "oldest": oldest.Format(time.RFC3339)},
+	})
+	if err != nil {
+		ui.Warning("Failed to send alert: %v", err)
+	}
 }
 
 func (h *webhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request) {
@@ -187,21 +320,9 @@ func (h *webhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Forward to daemon
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
-	resp, err := h.client.Trigger(ctx, "webhook")
-	if err != nil {
-		ui.Error("Failed to trigger daemon: %v", err)
-		http.Error(w, "Failed to trigger reconciliation", http.StatusBadGateway)
-		return
+	if h.forward(w, r, "webhook") {
+		ui.Info("Webhook received, triggered reconciliation")
 	}
-
-	ui.Info("Webhook received, triggered reconciliation")
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (h *webhookHandler) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
@@ -242,36 +363,27 @@ func (h *webhookHandler) handleGitHubWebhook(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Extract pusher info for logging
+	// Extract pusher info for logging, and the branch and files to filter on
 	var payload struct {
+		webhook.Commits
 		Pusher struct {
 			Name string `json:"name"`
 		} `json:"pusher"`
-		Ref string `json:"ref"`
 	}
 	if err := json.Unmarshal(body, &payload); err == nil {
 		ui.Info("GitHub push from %s on %s", payload.Pusher.Name, payload.Ref)
 	}
+	if !h.accept(w, payload.Push()) {
+		return
+	}
 
 	// Forward to daemon
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
 	source := "github"
 	if payload.Pusher.Name != "" {
 		source = fmt.Sprintf("github:%s", payload.Pusher.Name)
 	}
 
-	resp, err := h.client.Trigger(ctx, source)
-	if err != nil {
-		ui.Error("Failed to trigger daemon: %v", err)
-		http.Error(w, "Failed to trigger reconciliation", http.StatusBadGateway)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(resp)
+	h.forward(w, r, source)
 }
 
 func (h *webhookHandler) handleGitLabWebhook(w http.ResponseWriter, r *http.Request) {
@@ -307,34 +419,25 @@ func (h *webhookHandler) handleGitLabWebhook(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Extract user info for logging
+	// Extract user info for logging, and the branch and files to filter on
 	var payload struct {
+		webhook.Commits
 		UserName string `json:"user_name"`
-		Ref      string `json:"ref"`
 	}
 	if err := json.Unmarshal(body, &payload); err == nil {
 		ui.Info("GitLab push from %s on %s", payload.UserName, payload.Ref)
 	}
+	if !h.accept(w, payload.Push()) {
+		return
+	}
 
 	// Forward to daemon
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
 	source := "gitlab"
 	if payload.UserName != "" {
 		source = fmt.Sprintf("gitlab:%s", payload.UserName)
 	}
 
-	resp, err := h.client.Trigger(ctx, source)
-	if err != nil {
-		ui.Error("Failed to trigger daemon: %v", err)
-		http.Error(w, "Failed to trigger reconciliation", http.StatusBadGateway)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(resp)
+	h.forward(w, r, source)
 }
 
 func (h *webhookHandler) handleGiteaWebhook(w http.ResponseWriter, r *http.Request) {
@@ -370,36 +473,27 @@ func (h *webhookHandler) handleGiteaWebhook(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Extract pusher info for logging
+	// Extract pusher info for logging, and the branch and files to filter on
 	var payload struct {
+		webhook.Commits
 		Pusher struct {
 			Login string `json:"login"`
 		} `json:"pusher"`
-		Ref string `json:"ref"`
 	}
 	if err := json.Unmarshal(body, &payload); err == nil {
 		ui.Info("Gitea push from %s on %s", payload.Pusher.Login, payload.Ref)
 	}
+	if !h.accept(w, payload.Push()) {
+		return
+	}
 
 	// Forward to daemon
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
 	source := "gitea"
 	if payload.Pusher.Login != "" {
 		source = fmt.Sprintf("gitea:%s", payload.Pusher.Login)
 	}
 
-	resp, err := h.client.Trigger(ctx, source)
-	if err != nil {
-		ui.Error("Failed to trigger daemon: %v", err)
-		http.Error(w, "Failed to trigger reconciliation", http.StatusBadGateway)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(resp)
+	h.forward(w, r, source)
 }
 
 func (h *webhookHandler) handleBitbucketWebhook(w http.ResponseWriter, r *http.Request) {
@@ -439,7 +533,8 @@ func (h *webhookHandler) handleBitbucketWebhook(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Extract actor info for logging
+	// Extract actor info for logging, and the branch to filter on. Bitbucket
+	// push payloads don't list changed files.
 	var payload struct {
 		Actor struct {
 			DisplayName string `json:"display_name"`
@@ -447,38 +542,32 @@ func (h *webhookHandler) handleBitbucketWebhook(w http.ResponseWriter, r *http.R
 		Push struct {
 			Changes []struct {
 				New struct {
+					Type string `json:"type"`
 					Name string `json:"name"`
 				} `json:"new"`
 			} `json:"changes"`
 		} `json:"push"`
 	}
+	var push webhook.Push
 	if err := json.Unmarshal(body, &payload); err == nil {
-		branch := ""
 		if len(payload.Push.Changes) > 0 {
-			branch = payload.Push.Changes[0].New.Name
+			if n := payload.Push.Changes[0].New; n.Type == "branch" {
+				push.Branch = n.Name
+			}
 		}
-		ui.Info("Bitbucket push from %s on %s", payload.Actor.DisplayName, branch)
+		ui.Info("Bitbucket push from %s on %s", payload.Actor.DisplayName, push.Branch)
+	}
+	if !h.accept(w, push) {
+		return
 	}
 
 	// Forward to daemon
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
 	source := "bitbucket"
 	if payload.Actor.DisplayName != "" {
 		source = fmt.Sprintf("bitbucket:%s", payload.Actor.DisplayName)
 	}
 
-	resp, err := h.client.Trigger(ctx, source)
-	if err != nil {
-		ui.Error("Failed to trigger daemon: %v", err)
-		http.Error(w, "Failed to trigger reconciliation", http.StatusBadGateway)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(resp)
+	h.forward(w, r, source)
 }
 
 func (h *webhookHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/webhook"
+)
+
+// accept applies the filter to a push, answering an ignored push with 200
+// so the provider doesn't retry it. Returns whether to trigger.
+func (h *webhookHandler) accept(w http.ResponseWriter, p webhook.Push) bool {
+	ok, reason := h.filter.Allows(p)
+	if ok {
+		return true
+	}
+	ui.Info("Push ignored: %s", reason)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ignored", "reason": reason})
+	return false
+}
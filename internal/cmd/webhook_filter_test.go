@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cameronsjo/bosun/internal/webhook"
+)
+
+func TestWebhookHandler_IgnoresFilteredPush(t *testing.T) {
+	h := &webhookHandler{filter: webhook.Filter{Paths: []string{"manifest/**"}}}
+	body := `{"ref":"refs/heads/main","pusher":{"name":"alice"},"commits":[{"modified":["docs/gitops.md"]}]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	rec := httptest.NewRecorder()
+	h.handleGitHubWebhook(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["status"] != "ignored" {
+		t.Errorf("status = %q, want ignored", resp["status"])
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// webhookQueueFile is the queue's file name within the state directory.
+const webhookQueueFile = "webhook-queue.json"
+
+// Retry bounds for delivering queued triggers. The delay doubles after each
+// failed attempt.
+const (
+	webhookRetryMin = time.Second
+	webhookRetryMax = time.Minute
+)
+
+// defaultWebhookQueueMaxAge is how long a queued trigger waits for the
+// daemon before it is dropped.
+const defaultWebhookQueueMaxAge = time.Hour
+
+// queuedTrigger is a webhook trigger waiting for the daemon.
+type queuedTrigger struct {
+	Source     string    `json:"source"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// webhookQueue holds triggers received while the daemon is unreachable. It is
+// persisted to disk so triggers also survive a webhook server restart.
+type webhookQueue struct {
+	path     string
+	maxAge   time.Duration
+	minDelay time.Duration
+	maxDelay time.Duration
+
+	mu      sync.Mutex
+	pending []queuedTrigger
+	wake    chan struct{}
+}
+
+// loadWebhookQueue returns the queue persisted at path. A missing file is an
+// empty queue.
+func loadWebhookQueue(path string, maxAge time.Duration) (*webhookQueue, error) {
+	q := &webhookQueue{
+		path:     path,
+		maxAge:   maxAge,
+		minDelay: webhookRetryMin,
+		maxDelay: webhookRetryMax,
+		wake:     make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return q, err
+	}
+	if err := json.Unmarshal(data, &q.pending); err != nil {
+		return q, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return q, nil
+}
+
+// Add queues a trigger from source and wakes the retry loop.
+func (q *webhookQueue) Add(source string) error {
+	q.mu.Lock()
+	q.pending = append(q.pending, queuedTrigger{Source: source, ReceivedAt: time.Now().UTC()})
+	err := q.save()
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Len returns the number of queued triggers.
+func (q *webhookQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// save writes the queue to disk. Callers hold q.mu.
+func (q *webhookQueue) save() error {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("create queue directory: %w", err)
+	}
+	data, err := json.MarshalIndent(q.pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+// expire removes and returns the triggers queued for longer than maxAge.
+func (q *webhookQueue) expire(now time.Time) []queuedTrigger {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var kept, expired []queuedTrigger
+	for _, t := range q.pending {
+		if now.Sub(t.ReceivedAt) > q.maxAge {
+			expired = append(expired, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	if len(expired) > 0 {
+		q.pending = kept
+		_ = q.save()
+	}
+	return expired
+}
+
+// flush delivers the queue with a single trigger. A reconcile syncs to the
+// head of the branch, so one delivery covers every queued push; it is sent
+// as the newest push's source. Triggers queued while it is in flight stay
+// queued.
+func (q *webhookQueue) flush(ctx context.Context, trigger func(ctx context.Context, source string) error) (int, error) {
+	q.mu.Lock()
+	n := len(q.pending)
+	if n == 0 {
+		q.mu.Unlock()
+		return 0, nil
+	}
+	source := q.pending[n-1].Source
+	q.mu.Unlock()
+
+	if err := trigger(ctx, source); err != nil {
+		return 0, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = q.pending[n:]
+	return n, q.save()
+}
+
+// Run retries queued triggers with exponential backoff until ctx is done.
+// Triggers older than maxAge are dropped and passed to onDrop.
+func (q *webhookQueue) Run(ctx context.Context, trigger func(ctx context.Context, source string) error, onDrop func([]queuedTrigger)) {
+	delay := q.minDelay
+	for {
+		if dropped := q.expire(time.Now()); len(dropped) > 0 {
+			onDrop(dropped)
+		}
+
+		if q.Len() == 0 {
+			delay = q.minDelay
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		n, err := q.flush(ctx, trigger)
+		if err != nil {
+			delay = min(delay*2, q.maxDelay)
+			continue
+		}
+		if n > 0 {
+			ui.Success("Delivered %d queued webhook trigger(s)", n)
+		}
+		delay = q.minDelay
+	}
+}
+
+// isDaemonUnreachable reports whether err means the daemon could not be
+// dialed (socket missing or refusing connections), as opposed to the daemon
+// rejecting the trigger.
+func isDaemonUnreachable(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
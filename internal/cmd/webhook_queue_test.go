@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/daemon"
+)
+
+func TestWebhookQueue_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", webhookQueueFile)
+
+	q, err := loadWebhookQueue(path, time.Hour)
+	if err != nil {
+		t.Fatalf("loadWebhookQueue() error = %v", err)
+	}
+	if err := q.Add("github:alice"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := q.Add("github:bob"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	reloaded, err := loadWebhookQueue(path, time.Hour)
+	if err != nil {
+		t.Fatalf("loadWebhookQueue() error = %v", err)
+	}
+	if reloaded.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", reloaded.Len())
+	}
+	if got := reloaded.pending[1].Source; got != "github:bob" {
+		t.Errorf("pending[1].Source = %q, want github:bob", got)
+	}
+}
+
+func TestWebhookQueue_Expire(t *testing.T) {
+	q, _ := loadWebhookQueue(filepath.Join(t.TempDir(), webhookQueueFile), time.Hour)
+	now := time.Now()
+	q.pending = []queuedTrigger{
+		{Source: "old", ReceivedAt: now.Add(-2 * time.Hour)},
+		{Source: "new", ReceivedAt: now.Add(-time.Minute)},
+	}
+
+	expired := q.expire(now)
+	if len(expired) != 1 || expired[0].Source != "old" {
+		t.Errorf("expire() = %v, want [old]", expired)
+	}
+	if q.Len() != 1 || q.pending[0].Source != "new" {
+		t.Errorf("pending = %v, want [new]", q.pending)
+	}
+}
+
+func TestWebhookQueue_Flush(t *testing.T) {
+	q, _ := loadWebhookQueue(filepath.Join(t.TempDir(), webhookQueueFile), time.Hour)
+	_ = q.Add("gitea:alice")
+	_ = q.Add("gitea:bob")
+
+	failing := func(ctx context.Context, source string) error { return errors.New("daemon down") }
+	if n, err := q.flush(context.Background(), failing); err == nil || n != 0 {
+		t.Errorf("flush() = %d, %v; want 0 and an error", n, err)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("Len() after failed flush = %d, want 2", q.Len())
+	}
+
+	var sources []string
+	ok := func(ctx context.Context, source string) error {
+		sources = append(sources, source)
+		return nil
+	}
+	n, err := q.flush(context.Background(), ok)
+	if err != nil || n != 2 {
+		t.Errorf("flush() = %d, %v; want 2, nil", n, err)
+	}
+	if len(sources) != 1 || sources[0] != "gitea:bob" {
+		t.Errorf("triggered %v, want one trigger from the newest push", sources)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() after flush = %d, want 0", q.Len())
+	}
+}
+
+func TestWebhookQueue_RunDropsExpired(t *testing.T) {
+	q, _ := loadWebhookQueue(filepath.Join(t.TempDir(), webhookQueueFile), 50*time.Millisecond)
+	q.minDelay = 10 * time.Millisecond
+	q.maxDelay = 20 * time.Millisecond
+	_ = q.Add("webhook")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	dropped := make(chan []queuedTrigger, 1)
+	failing := func(ctx context.Context, source string) error { return errors.New("daemon down") }
+	go q.Run(ctx, failing, func(d []queuedTrigger) { dropped <- d })
+
+	select {
+	case d := <-dropped:
+		if len(d) != 1 || d[0].Source != "webhook" {
+			t.Errorf("dropped %v, want [webhook]", d)
+		}
+	case <-ctx.Done():
+		t.Fatal("queued trigger was never dropped")
+	}
+}
+
+func TestWebhookHandler_QueuesWhenDaemonUnreachable(t *testing.T) {
+	dir := t.TempDir()
+	q, _ := loadWebhookQueue(filepath.Join(dir, webhookQueueFile), time.Hour)
+	h := &webhookHandler{client: daemon.NewClient(filepath.Join(dir, "missing.sock")), queue: q}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	rec := httptest.NewRecorder()
+	h.handleWebhook(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["status"] != "queued" {
+		t.Errorf("status = %q, want queued", body["status"])
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", q.Len())
+	}
+
+	// Without a queue the sender gets a 502 as before
+	h.queue = nil
+	rec = httptest.NewRecorder()
+	h.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status without queue = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 
 	"github.com/cameronsjo/bosun/internal/config"
 	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/reconcile"
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
@@ -22,6 +26,12 @@ const (
 	ComposeCommandTimeout = 5 * time.Minute
 )
 
+// yachtUpOnly names the stack 'yacht up --only' deploys.
+var yachtUpOnly string
+
+// yachtUpProfiles are the compose profiles 'yacht up --profile' enables.
+var yachtUpProfiles []string
+
 var yachtCmd = &cobra.Command{
 	Use:     "yacht",
 	Aliases: []string{"hoist"},
@@ -41,7 +51,21 @@ Commands:
 var yachtUpCmd = &cobra.Command{
 	Use:   "up [services...]",
 	Short: "Start the yacht (docker compose up -d)",
-	Long:  `Starts all services defined in the compose file. Checks for Traefik first.`,
+	Long: `Starts all services defined in the compose file. Checks for Traefik first.
+
+With --only, deploys a single rendered stack instead: its compose file from
+the output directory, under the compose project bosun deploys the stack as.
+The stack is linted first, and the deploy stops on a dependency cycle.
+
+Services in compose profiles (see 'profiles' in service manifests) only
+start when --profile enables one of their profiles.
+
+Examples:
+  bosun yacht up                     # Start everything in the compose file
+  bosun yacht up traefik             # Start one service
+  bosun yacht up --only media        # Deploy only the media stack
+  bosun yacht up --only media plex   # Start one service of the media stack
+  bosun yacht up --profile dev       # Also start services in the dev profile`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), ComposeCommandTimeout)
 		defer cancel()
@@ -51,18 +75,37 @@ var yachtUpCmd = &cobra.Command{
 			return fmt.Errorf("load config: %w", err)
 		}
 
+		for _, p := range yachtUpProfiles {
+			if err := manifest.ValidateProfile(p); err != nil {
+				return err
+			}
+		}
+
+		composeFile := cfg.ComposeFile
+		if yachtUpOnly != "" {
+			if composeFile, err = stackComposeFile(cfg, yachtUpOnly); err != nil {
+				return err
+			}
+		}
+
 		// Validate compose file before operations
-		if err := validateComposeFile(cfg.ComposeFile); err != nil {
+		if err := validateComposeFile(composeFile); err != nil {
 			return fmt.Errorf("%w. Run 'docker compose config' to debug", err)
 		}
 
 		// Validate service names if provided
 		if len(args) > 0 {
-			if err := validateServiceNames(cfg.ComposeFile, args); err != nil {
+			if err := validateServiceNames(composeFile, args); err != nil {
 				return err
 			}
 		}
 
+		if yachtUpOnly != "" {
+			if err := lintStack(cfg, yachtUpOnly, composeFile); err != nil {
+				return fmt.Errorf("lint: %w", err)
+			}
+		}
+
 		// Check traefik status
 		// NOTE: Docker client is optional here - we continue even if it fails.
 		// This allows yacht up to work in environments where Docker API isn't accessible
@@ -74,10 +117,14 @@ var yachtUpCmd = &cobra.Command{
 		}
 
 		ui.Green.Println("Raising anchor...")
-		compose, err := docker.NewComposeClient(cfg.ComposeFile)
+		compose, err := docker.NewComposeClient(composeFile)
 		if err != nil {
 			return fmt.Errorf("compose client: %w", err)
 		}
+		if yachtUpOnly != "" {
+			compose.WithProject(reconcile.ComposeProjectName(composeFile))
+		}
+		compose.WithProfiles(yachtUpProfiles...)
 		if err := compose.Up(ctx, args...); err != nil {
 			return fmt.Errorf("compose up: %w", err)
 		}
@@ -185,6 +232,29 @@ var yachtStatusCmd = &cobra.Command{
 	},
 }
 
+// stackComposeFile returns the rendered compose file of a stack, or an
+// error naming the rendered stacks if there is none.
+func stackComposeFile(cfg *config.Config, stack string) (string, error) {
+	if stack != filepath.Base(stack) {
+		return "", fmt.Errorf("invalid stack name %q", stack)
+	}
+	composeDir := filepath.Join(cfg.OutputDir(), "compose")
+	file := filepath.Join(composeDir, stack+".yml")
+	if _, err := os.Stat(file); err == nil {
+		return file, nil
+	}
+
+	var names []string
+	for name := range renderedStacks(composeDir) {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("stack %q not found: no rendered stacks in %s. Run 'bosun provision' first", stack, composeDir)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("stack %q not found. Rendered stacks: %s", stack, strings.Join(names, ", "))
+}
+
 // validateComposeFile validates that a compose file exists and has valid syntax.
 func validateComposeFile(composePath string) error {
 	// Check file exists
@@ -281,6 +351,8 @@ func checkTraefik(ctx context.Context, client *docker.Client) error {
 }
 
 func init() {
+	yachtUpCmd.Flags().StringVar(&yachtUpOnly, "only", "", "Deploy only this rendered stack")
+	yachtUpCmd.Flags().StringSliceVar(&yachtUpProfiles, "profile", nil, "Enable a compose profile (repeatable or comma-separated)")
 	yachtCmd.AddCommand(yachtUpCmd)
 	yachtCmd.AddCommand(yachtDownCmd)
 	yachtCmd.AddCommand(yachtRestartCmd)
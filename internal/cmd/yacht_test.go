@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
 )
 
 func TestYachtCmd_Help(t *testing.T) {
@@ -380,3 +382,74 @@ func TestValidateServiceNames(t *testing.T) {
 		assert.Contains(t, err.Error(), "parse compose file")
 	})
 }
+
+func TestStackComposeFile(t *testing.T) {
+	manifestDir := t.TempDir()
+	composeDir := filepath.Join(manifestDir, "output", "compose")
+	require.NoError(t, os.MkdirAll(composeDir, 0755))
+	for _, stack := range []string{"media", "core"} {
+		require.NoError(t, os.WriteFile(filepath.Join(composeDir, stack+".yml"), []byte("services: {}\n"), 0644))
+	}
+	cfg := &config.Config{ManifestDir: manifestDir}
+
+	t.Run("rendered stack", func(t *testing.T) {
+		file, err := stackComposeFile(cfg, "media")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(composeDir, "media.yml"), file)
+	})
+
+	t.Run("unknown stack lists rendered stacks", func(t *testing.T) {
+		_, err := stackComposeFile(cfg, "medai")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `stack "medai" not found`)
+		assert.Contains(t, err.Error(), "core, media")
+	})
+
+	t.Run("path is rejected", func(t *testing.T) {
+		_, err := stackComposeFile(cfg, "../media")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid stack name")
+	})
+
+	t.Run("nothing rendered", func(t *testing.T) {
+		_, err := stackComposeFile(&config.Config{ManifestDir: t.TempDir()}, "media")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bosun provision")
+	})
+}
+
+func TestLintStack(t *testing.T) {
+	writeCompose := func(t *testing.T, content string) (*config.Config, string) {
+		manifestDir := t.TempDir()
+		composeDir := filepath.Join(manifestDir, "output", "compose")
+		require.NoError(t, os.MkdirAll(composeDir, 0755))
+		file := filepath.Join(composeDir, "media.yml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		return &config.Config{ManifestDir: manifestDir}, file
+	}
+
+	t.Run("clean stack", func(t *testing.T) {
+		cfg, file := writeCompose(t, `services:
+  plex:
+    image: plexinc/pms-docker
+    depends_on: [postgres]
+  postgres:
+    image: postgres:16
+`)
+		assert.NoError(t, lintStack(cfg, "media", file))
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		cfg, file := writeCompose(t, `services:
+  a:
+    image: alpine
+    depends_on: [b]
+  b:
+    image: alpine
+    depends_on: [a]
+`)
+		err := lintStack(cfg, "media", file)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dependency cycle")
+	})
+}
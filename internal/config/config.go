@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -29,6 +31,9 @@ type Config struct {
 	// SnapshotsDir is the path to the snapshots directory.
 	SnapshotsDir string
 
+	// outputDir overrides the rendered output directory (default: ManifestDir/output).
+	outputDir string
+
 	// infraContainers holds the configured infrastructure container names.
 	infraContainers []string
 
@@ -40,6 +45,18 @@ type Config struct {
 
 	// alertConfig holds alert provider configuration.
 	alertConfig AlertConfig
+
+	// alertSealErr records sealed alert credentials that could not be opened.
+	alertSealErr error
+
+	// snapshotConfig holds the snapshot retention policy.
+	snapshotConfig SnapshotConfig
+
+	// daemonConfig holds daemon settings.
+	daemonConfig DaemonConfig
+
+	// lintConfig holds lint settings.
+	lintConfig LintConfig
 }
 
 // TunnelConfig holds tunnel provider-specific configuration.
@@ -71,13 +88,65 @@ type AlertConfig struct {
 	TwilioFromNumber string   `yaml:"twilio_from_number"`
 	TwilioToNumbers  []string `yaml:"twilio_to_numbers"`
 
+	// Slack
+	SlackWebhookURL string `yaml:"slack_webhook_url"`
+
+	// ntfy
+	NtfyServer string `yaml:"ntfy_server"` // Default: https://ntfy.sh
+	NtfyTopic  string `yaml:"ntfy_topic"`
+	NtfyToken  string `yaml:"ntfy_token"`
+
+	// Generic webhook (JSON POST)
+	WebhookURL   string `yaml:"webhook_url"`
+	WebhookToken string `yaml:"webhook_token"`
+
+	// Routing: minimum severity per provider (info, warning, error,
+	// critical). Providers not listed receive every alert.
+	MinSeverity map[string]string `yaml:"min_severity"`
+
 	// Settings
 	OnSuccess bool `yaml:"on_success"` // Alert on successful deploys
 	OnFailure bool `yaml:"on_failure"` // Alert on failed deploys (default: true)
 }
 
+// SnapshotConfig holds the snapshot retention policy. Zero values use the
+// snapshot package defaults.
+type SnapshotConfig struct {
+	Keep      int `yaml:"keep"`       // Newest snapshots to keep (default: 20)
+	KeepDaily int `yaml:"keep_daily"` // Also keep the newest snapshot of each of the last N days
+}
+
+// DaemonConfig holds daemon settings. Environment variables the daemon
+// reads take precedence.
+type DaemonConfig struct {
+	DriftRemediate     bool          `yaml:"drift_remediate"`     // Redeploy stacks that stay drifted (BOSUN_DRIFT_REMEDIATE)
+	DriftDebounce      time.Duration `yaml:"drift_debounce"`      // How long drift must persist first (BOSUN_DRIFT_DEBOUNCE)
+	MaintenanceWindows string        `yaml:"maintenance_windows"` // Comma-separated, e.g. "03:30-05:00" (BOSUN_MAINTENANCE_WINDOWS)
+}
+
+// LintConfig holds lint settings.
+type LintConfig struct {
+	// Severity overrides the severity (error, warn, or info) of lint checks
+	// by name, e.g. ports: warn.
+	Severity map[string]string `yaml:"severity"`
+}
+
+// Layout holds the project directory names, relative to the project root.
+// Empty fields use the standard layout.
+type Layout struct {
+	Manifest string `yaml:"manifest"` // Service definitions (default: manifest)
+	Bosun    string `yaml:"bosun"`    // Holds docker-compose.yml (default: bosun)
+	Output   string `yaml:"output"`   // Rendered output (default: <manifest>/output)
+}
+
+// DefaultLayout is the standard bosun/ and manifest/ layout.
+var DefaultLayout = Layout{Manifest: "manifest", Bosun: "bosun"}
+
 // configFile represents the structure of .bosun/config.yml or bosun.yml.
 type configFile struct {
+	// Directory layout overrides
+	Layout Layout `yaml:"layout"`
+
 	Infrastructure struct {
 		Containers []string `yaml:"containers"`
 	} `yaml:"infrastructure"`
@@ -92,19 +161,51 @@ type configFile struct {
 
 	// Alerts configuration
 	Alerts AlertConfig `yaml:"alerts"`
+
+	// Snapshot retention
+	Snapshots SnapshotConfig `yaml:"snapshots"`
+
+	// Daemon settings
+	Daemon DaemonConfig `yaml:"daemon"`
+
+	// Lint settings
+	Lint LintConfig `yaml:"lint"`
+
+	// Command aliases: name -> command line, e.g. up: "yacht up traefik authelia"
+	Aliases map[string]string `yaml:"aliases"`
+
+	// Source repositories of images without an OCI source annotation:
+	// image name -> GitHub repository, e.g. traefik: traefik/traefik
+	ReleaseSources map[string]string `yaml:"release_sources"`
 }
 
 // FindRoot searches upward from the current directory to find the project root.
-// The project root is identified by the presence of a bosun/ or manifest/ directory.
+// The project root is identified by the presence of a bosun/ or manifest/ directory,
+// or the directories named by the layout (see LoadLayout).
+// In a workspace (see WorkspaceFile), the selected project's root is returned,
+// falling back to the workspace default.
 func FindRoot() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("get working directory: %w", err)
 	}
 
+	if name := SelectedProject(); name != "" {
+		ws, err := FindWorkspace(dir)
+		if err != nil {
+			return "", err
+		}
+		if ws == nil {
+			return "", fmt.Errorf("project %q selected but no %s found", name, WorkspaceFile)
+		}
+		return ws.ProjectRoot(name)
+	}
+
 	for dir != "/" {
+		layout := LoadLayout(dir)
+
 		// Check for bosun directory with docker-compose.yml
-		bosunDir := filepath.Join(dir, "bosun")
+		bosunDir := layoutPath(dir, layout.Bosun)
 		if info, err := os.Stat(bosunDir); err == nil && info.IsDir() {
 			composeFile := filepath.Join(bosunDir, "docker-compose.yml")
 			if _, err := os.Stat(composeFile); err == nil {
@@ -113,11 +214,23 @@ func FindRoot() (string, error) {
 		}
 
 		// Check for manifest directory
-		manifestDir := filepath.Join(dir, "manifest")
+		manifestDir := layoutPath(dir, layout.Manifest)
 		if info, err := os.Stat(manifestDir); err == nil && info.IsDir() {
 			return dir, nil
 		}
 
+		// Reached a workspace without entering a project: use its default
+		if _, err := os.Stat(filepath.Join(dir, WorkspaceFile)); err == nil {
+			ws, err := LoadWorkspace(filepath.Join(dir, WorkspaceFile))
+			if err != nil {
+				return "", err
+			}
+			if ws.Default == "" {
+				return "", fmt.Errorf("workspace has several projects; select one with --project (available: %s)", strings.Join(ws.ProjectNames(), ", "))
+			}
+			return ws.ProjectRoot(ws.Default)
+		}
+
 		// Move up one directory
 		parent := filepath.Dir(dir)
 		if parent == dir {
@@ -137,22 +250,89 @@ func Load() (*Config, error) {
 	}
 
 	tunnelProvider, tunnelConfig := loadTunnelConfig(root)
-	alertConfig := loadAlertConfig(root)
+	alertConfig, alertSealErr := loadAlertConfig(root)
+	layout := LoadLayout(root)
+	manifestDir := layoutPath(root, layout.Manifest)
 
 	cfg := &Config{
 		Root:            root,
-		ManifestDir:     filepath.Join(root, "manifest"),
-		ComposeFile:     filepath.Join(root, "bosun", "docker-compose.yml"),
-		SnapshotsDir:    filepath.Join(root, "manifest", ".bosun", "snapshots"),
+		ManifestDir:     manifestDir,
+		ComposeFile:     filepath.Join(layoutPath(root, layout.Bosun), "docker-compose.yml"),
+		SnapshotsDir:    filepath.Join(manifestDir, ".bosun", "snapshots"),
 		infraContainers: loadInfraContainers(root),
 		tunnelProvider:  tunnelProvider,
 		tunnelConfig:    tunnelConfig,
 		alertConfig:     alertConfig,
+		alertSealErr:    alertSealErr,
+		snapshotConfig:  loadSnapshotConfig(root),
+		daemonConfig:    loadDaemonConfig(root),
+		lintConfig:      loadLintConfig(root),
+	}
+	if layout.Output != "" {
+		cfg.outputDir = layoutPath(root, layout.Output)
 	}
 
 	return cfg, nil
 }
 
+// LoadLayout returns the directory layout for a project root. The layout:
+// section of .bosun/config.yml or bosun.yml is applied over DefaultLayout,
+// then BOSUN_MANIFEST_DIR, BOSUN_DIR, and BOSUN_OUTPUT_DIR override it.
+func LoadLayout(root string) Layout {
+	layout := DefaultLayout
+
+	configPaths := []string{
+		filepath.Join(root, ".bosun", "config.yml"),
+		filepath.Join(root, "bosun.yml"),
+	}
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			continue
+		}
+
+		if cfg.Layout == (Layout{}) {
+			continue
+		}
+		if cfg.Layout.Manifest != "" {
+			layout.Manifest = cfg.Layout.Manifest
+		}
+		if cfg.Layout.Bosun != "" {
+			layout.Bosun = cfg.Layout.Bosun
+		}
+		layout.Output = cfg.Layout.Output
+		break
+	}
+
+	// Environment variable overrides
+	if v := os.Getenv("BOSUN_MANIFEST_DIR"); v != "" {
+		layout.Manifest = v
+	}
+	if v := os.Getenv("BOSUN_DIR"); v != "" {
+		layout.Bosun = v
+	}
+	if v := os.Getenv("BOSUN_OUTPUT_DIR"); v != "" {
+		layout.Output = v
+	}
+
+	return layout
+}
+
+// layoutPath resolves a layout directory against the project root.
+// Absolute paths are used as-is.
+func layoutPath(root, dir string) string {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(root, dir)
+}
+
 // loadInfraContainers loads infrastructure container names from config files.
 // Checks for .bosun/config.yml or bosun.yml in the project root.
 // Falls back to default list if no config is found.
@@ -182,6 +362,143 @@ func loadInfraContainers(root string) []string {
 	return defaultInfraContainers
 }
 
+// LoadAliases loads the user-defined command aliases from the aliases:
+// section of .bosun/config.yml or bosun.yml in the project root. The first
+// file that defines any wins. Returns nil if neither does.
+func LoadAliases(root string) map[string]string {
+	configPaths := []string{
+		filepath.Join(root, ".bosun", "config.yml"),
+		filepath.Join(root, "bosun.yml"),
+	}
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			continue
+		}
+
+		if len(cfg.Aliases) > 0 {
+			return cfg.Aliases
+		}
+	}
+
+	return nil
+}
+
+// LoadReleaseSources loads the image source repositories from the
+// release_sources: section of .bosun/config.yml or bosun.yml in the project
+// root. The first file that defines any wins. Returns nil if neither does.
+func LoadReleaseSources(root string) map[string]string {
+	configPaths := []string{
+		filepath.Join(root, ".bosun", "config.yml"),
+		filepath.Join(root, "bosun.yml"),
+	}
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			continue
+		}
+
+		if len(cfg.ReleaseSources) > 0 {
+			return cfg.ReleaseSources
+		}
+	}
+
+	return nil
+}
+
+// loadSnapshotConfig loads the snapshots: section of .bosun/config.yml or
+// bosun.yml in the project root. The first file that defines it wins.
+func loadSnapshotConfig(root string) SnapshotConfig {
+	configPaths := []string{
+		filepath.Join(root, ".bosun", "config.yml"),
+		filepath.Join(root, "bosun.yml"),
+	}
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			continue
+		}
+
+		if cfg.Snapshots != (SnapshotConfig{}) {
+			return cfg.Snapshots
+		}
+	}
+
+	return SnapshotConfig{}
+}
+
+// loadDaemonConfig loads the daemon: section of .bosun/config.yml or
+// bosun.yml in the project root. The first file that defines it wins.
+func loadDaemonConfig(root string) DaemonConfig {
+	configPaths := []string{
+		filepath.Join(root, ".bosun", "config.yml"),
+		filepath.Join(root, "bosun.yml"),
+	}
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			continue
+		}
+
+		if cfg.Daemon != (DaemonConfig{}) {
+			return cfg.Daemon
+		}
+	}
+
+	return DaemonConfig{}
+}
+
+// loadLintConfig loads the lint: section of .bosun/config.yml or bosun.yml
+// in the project root. The first file that defines it wins.
+func loadLintConfig(root string) LintConfig {
+	configPaths := []string{
+		filepath.Join(root, ".bosun", "config.yml"),
+		filepath.Join(root, "bosun.yml"),
+	}
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var cfg configFile
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			continue
+		}
+
+		if len(cfg.Lint.Severity) > 0 {
+			return cfg.Lint
+		}
+	}
+
+	return LintConfig{}
+}
+
 // ProvisionsDir returns the path to the provisions directory.
 func (c *Config) ProvisionsDir() string {
 	return filepath.Join(c.ManifestDir, "provisions")
@@ -197,8 +514,16 @@ func (c *Config) StacksDir() string {
 	return filepath.Join(c.ManifestDir, "stacks")
 }
 
+// RenderCacheDir returns the path to the cache of rendered services.
+func (c *Config) RenderCacheDir() string {
+	return filepath.Join(c.ManifestDir, ".bosun", "cache", "render")
+}
+
 // OutputDir returns the path to the output directory.
 func (c *Config) OutputDir() string {
+	if c.outputDir != "" {
+		return c.outputDir
+	}
 	return filepath.Join(c.ManifestDir, "output")
 }
 
@@ -270,14 +595,36 @@ func loadTunnelConfig(root string) (string, TunnelConfig) {
 	return defaultTunnelProvider, TunnelConfig{}
 }
 
+// GetSnapshotConfig returns the snapshot retention policy.
+func (c *Config) GetSnapshotConfig() SnapshotConfig {
+	return c.snapshotConfig
+}
+
+// GetDaemonConfig returns the daemon settings.
+func (c *Config) GetDaemonConfig() DaemonConfig {
+	return c.daemonConfig
+}
+
+// GetLintConfig returns the lint settings.
+func (c *Config) GetLintConfig() LintConfig {
+	return c.lintConfig
+}
+
 // GetAlertConfig returns the alert configuration.
 func (c *Config) GetAlertConfig() AlertConfig {
 	return c.alertConfig
 }
 
-// loadAlertConfig loads alert configuration from config files.
-// Supports environment variable overrides for sensitive values.
-func loadAlertConfig(root string) AlertConfig {
+// AlertSealError returns an error if sealed alert credentials in the config
+// files could not be opened. Those credentials are left empty.
+func (c *Config) AlertSealError() error {
+	return c.alertSealErr
+}
+
+// loadAlertConfig loads alert configuration from config files, opening
+// sealed credentials with the age key. Supports environment variable
+// overrides for sensitive values.
+func loadAlertConfig(root string) (AlertConfig, error) {
 	configPaths := []string{
 		filepath.Join(root, ".bosun", "config.yml"),
 		filepath.Join(root, "bosun.yml"),
@@ -304,8 +651,23 @@ func loadAlertConfig(root string) AlertConfig {
 		}
 		break
 	}
+	sealErr := openAlertSecrets(&alertCfg)
+	applyAlertEnv(&alertCfg)
 
-	// Environment variable overrides
+	return alertCfg, sealErr
+}
+
+// EnvAlertConfig returns alert configuration from environment variables
+// alone, for running outside a project.
+func EnvAlertConfig() AlertConfig {
+	alertCfg := AlertConfig{OnFailure: true}
+	applyAlertEnv(&alertCfg)
+	return alertCfg
+}
+
+// applyAlertEnv overrides alert configuration with environment variables.
+// List values are comma-separated.
+func applyAlertEnv(alertCfg *AlertConfig) {
 	if v := os.Getenv("DISCORD_WEBHOOK_URL"); v != "" {
 		alertCfg.DiscordWebhookURL = v
 	}
@@ -318,6 +680,9 @@ func loadAlertConfig(root string) AlertConfig {
 	if v := os.Getenv("SENDGRID_FROM_NAME"); v != "" {
 		alertCfg.SendGridFromName = v
 	}
+	if v := splitList(os.Getenv("SENDGRID_TO_EMAILS")); len(v) > 0 {
+		alertCfg.SendGridToEmails = v
+	}
 	if v := os.Getenv("TWILIO_ACCOUNT_SID"); v != "" {
 		alertCfg.TwilioAccountSID = v
 	}
@@ -327,6 +692,37 @@ func loadAlertConfig(root string) AlertConfig {
 	if v := os.Getenv("TWILIO_FROM_NUMBER"); v != "" {
 		alertCfg.TwilioFromNumber = v
 	}
+	if v := splitList(os.Getenv("TWILIO_TO_NUMBERS")); len(v) > 0 {
+		alertCfg.TwilioToNumbers = v
+	}
+	if v := os.Getenv("SLACK_WEBHOOK_URL"); v != "" {
+		alertCfg.SlackWebhookURL = v
+	}
+	if v := os.Getenv("NTFY_SERVER"); v != "" {
+		alertCfg.NtfyServer = v
+	}
+	if v := os.Getenv("NTFY_TOPIC"); v != "" {
+		alertCfg.NtfyTopic = v
+	}
+	if v := os.Getenv("NTFY_TOKEN"); v != "" {
+		alertCfg.NtfyToken = v
+	}
+	if v := os.Getenv("ALERT_WEBHOOK_URL"); v != "" {
+		alertCfg.WebhookURL = v
+	}
+	if v := os.Getenv("ALERT_WEBHOOK_TOKEN"); v != "" {
+		alertCfg.WebhookToken = v
+	}
+}
 
-	return alertCfg
+// splitList splits a comma-separated environment value, dropping empty
+// entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -425,3 +426,191 @@ func TestFindRoot_DeepNesting(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, tmpDir, root)
 }
+
+func TestLoad_CustomLayout(t *testing.T) {
+	tmpDir := evalSymlinks(t, t.TempDir())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "infra", "manifests"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bosun.yml"), []byte(`layout:
+  manifest: infra/manifests
+  bosun: infra/bosun
+  output: deploy/rendered
+`), 0644))
+
+	subDir := filepath.Join(tmpDir, "infra", "manifests", "services")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	require.NoError(t, os.Chdir(subDir))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, tmpDir, cfg.Root)
+	assert.Equal(t, filepath.Join(tmpDir, "infra", "manifests"), cfg.ManifestDir)
+	assert.Equal(t, filepath.Join(tmpDir, "infra", "bosun", "docker-compose.yml"), cfg.ComposeFile)
+	assert.Equal(t, filepath.Join(tmpDir, "infra", "manifests", ".bosun", "snapshots"), cfg.SnapshotsDir)
+	assert.Equal(t, filepath.Join(tmpDir, "infra", "manifests", "services"), cfg.ServicesDir())
+	assert.Equal(t, filepath.Join(tmpDir, "deploy", "rendered"), cfg.OutputDir())
+}
+
+func TestLoadLayout(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		assert.Equal(t, DefaultLayout, LoadLayout(t.TempDir()))
+	})
+
+	t.Run("config file overrides manifest only", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte("layout:\n  manifest: k8s\n"), 0644))
+
+		assert.Equal(t, Layout{Manifest: "k8s", Bosun: "bosun"}, LoadLayout(dir))
+	})
+
+	t.Run("environment overrides config file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte("layout:\n  manifest: k8s\n"), 0644))
+		t.Setenv("BOSUN_MANIFEST_DIR", "deploy/manifests")
+		t.Setenv("BOSUN_DIR", "ops")
+		t.Setenv("BOSUN_OUTPUT_DIR", "/srv/rendered")
+
+		layout := LoadLayout(dir)
+		assert.Equal(t, Layout{Manifest: "deploy/manifests", Bosun: "ops", Output: "/srv/rendered"}, layout)
+		assert.Equal(t, "/srv/rendered", layoutPath(dir, layout.Output))
+	})
+}
+
+func TestFindRoot_CustomLayoutFromEnv(t *testing.T) {
+	tmpDir := evalSymlinks(t, t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "deploy"), 0755))
+	t.Setenv("BOSUN_MANIFEST_DIR", "deploy")
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	require.NoError(t, os.Chdir(filepath.Join(tmpDir, "deploy")))
+
+	root, err := FindRoot()
+	require.NoError(t, err)
+	assert.Equal(t, tmpDir, root)
+}
+
+func TestLoadAliases(t *testing.T) {
+	t.Run("none configured", func(t *testing.T) {
+		assert.Nil(t, LoadAliases(t.TempDir()))
+	})
+
+	t.Run("from bosun.yml", func(t *testing.T) {
+		dir := t.TempDir()
+		content := "aliases:\n  up: yacht up traefik authelia\n  errors: logs traefik --since 1h\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte(content), 0644))
+
+		assert.Equal(t, map[string]string{
+			"up":     "yacht up traefik authelia",
+			"errors": "logs traefik --since 1h",
+		}, LoadAliases(dir))
+	})
+
+	t.Run(".bosun/config.yml takes precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".bosun"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".bosun", "config.yml"), []byte("aliases:\n  up: yacht up\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte("aliases:\n  up: yacht restart\n"), 0644))
+
+		assert.Equal(t, map[string]string{"up": "yacht up"}, LoadAliases(dir))
+	})
+}
+
+func TestLoadReleaseSources(t *testing.T) {
+	assert.Nil(t, LoadReleaseSources(t.TempDir()))
+
+	dir := t.TempDir()
+	content := "release_sources:\n  traefik: traefik/traefik\n  lscr.io/linuxserver/sonarr: https://github.com/Sonarr/Sonarr\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte(content), 0644))
+	assert.Equal(t, map[string]string{
+		"traefik":                    "traefik/traefik",
+		"lscr.io/linuxserver/sonarr": "https://github.com/Sonarr/Sonarr",
+	}, LoadReleaseSources(dir))
+}
+
+func TestLoadSnapshotConfig(t *testing.T) {
+	assert.Equal(t, SnapshotConfig{}, loadSnapshotConfig(t.TempDir()))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte("snapshots:\n  keep: 10\n  keep_daily: 14\n"), 0644))
+	assert.Equal(t, SnapshotConfig{Keep: 10, KeepDaily: 14}, loadSnapshotConfig(dir))
+}
+
+func TestLoadDaemonConfig(t *testing.T) {
+	assert.Equal(t, DaemonConfig{}, loadDaemonConfig(t.TempDir()))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte("daemon:\n  drift_remediate: true\n  drift_debounce: 10m\n  maintenance_windows: 03:30-05:00\n"), 0644))
+	assert.Equal(t, DaemonConfig{DriftRemediate: true, DriftDebounce: 10 * time.Minute, MaintenanceWindows: "03:30-05:00"}, loadDaemonConfig(dir))
+}
+
+func TestLoadLintConfig(t *testing.T) {
+	assert.Equal(t, LintConfig{}, loadLintConfig(t.TempDir()))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bosun.yml"), []byte("lint:\n  severity:\n    ports: warn\n    coupling: error\n"), 0644))
+	assert.Equal(t, LintConfig{Severity: map[string]string{"ports": "warn", "coupling": "error"}}, loadLintConfig(dir))
+}
+
+func TestLoadAlertConfig_Notifiers(t *testing.T) {
+	for _, env := range []string{"SLACK_WEBHOOK_URL", "NTFY_SERVER", "NTFY_TOPIC", "NTFY_TOKEN", "ALERT_WEBHOOK_URL", "ALERT_WEBHOOK_TOKEN", "TWILIO_TO_NUMBERS"} {
+		t.Setenv(env, "")
+	}
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bosun.yml"), []byte(`alerts:
+  slack_webhook_url: https://hooks.slack.com/services/T/B/x
+  ntfy_topic: homelab
+  webhook_url: https://hooks.example.com/bosun
+  min_severity:
+    ntfy: error
+    webhook: warning
+`), 0644))
+
+	cfg, err := loadAlertConfig(root)
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/x", cfg.SlackWebhookURL)
+	assert.Equal(t, "homelab", cfg.NtfyTopic)
+	assert.Empty(t, cfg.NtfyServer)
+	assert.Equal(t, "https://hooks.example.com/bosun", cfg.WebhookURL)
+	assert.Equal(t, map[string]string{"ntfy": "error", "webhook": "warning"}, cfg.MinSeverity)
+	assert.True(t, cfg.OnFailure)
+
+	t.Run("environment overrides", func(t *testing.T) {
+		t.Setenv("NTFY_SERVER", "https://ntfy.example.com")
+		t.Setenv("NTFY_TOKEN", "tk_env")
+		t.Setenv("ALERT_WEBHOOK_TOKEN", "s3cret")
+
+		cfg, err := loadAlertConfig(root)
+		require.NoError(t, err)
+		assert.Equal(t, "https://ntfy.example.com", cfg.NtfyServer)
+		assert.Equal(t, "tk_env", cfg.NtfyToken)
+		assert.Equal(t, "s3cret", cfg.WebhookToken)
+	})
+
+	t.Run("environment only", func(t *testing.T) {
+		t.Setenv("NTFY_TOPIC", "env-topic")
+		t.Setenv("TWILIO_TO_NUMBERS", "+15551234567, ,+15559876543")
+
+		cfg := EnvAlertConfig()
+		assert.Equal(t, "env-topic", cfg.NtfyTopic)
+		assert.Equal(t, []string{"+15551234567", "+15559876543"}, cfg.TwilioToNumbers)
+		assert.Empty(t, cfg.SlackWebhookURL)
+		assert.True(t, cfg.OnFailure)
+	})
+
+	t.Run("credentials are secret keys", func(t *testing.T) {
+		for _, key := range []string{"slack_webhook_url", "ntfy_token", "webhook_url", "webhook_token"} {
+			assert.True(t, isSecretConfigKey(key), key)
+		}
+		assert.False(t, isSecretConfigKey("ntfy_topic"))
+	})
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InventoryFile lists the hosts a project deploys to, next to the rendered
+// infrastructure in the repository.
+const InventoryFile = "bosun.inventory.yml"
+
+// Inventory lists deploy hosts and which stacks run on each. Stacks not
+// assigned run on the primary host.
+type Inventory struct {
+	// Hosts maps host names to their settings.
+	Hosts map[string]InventoryHost `yaml:"hosts"`
+
+	// Stacks assigns stacks to a host, by host name or by a label exactly
+	// one host has.
+	Stacks map[string]string `yaml:"stacks"`
+}
+
+// InventoryHost is one deploy host.
+type InventoryHost struct {
+	// Address is "user@host" for SSH. Empty marks the primary host, the
+	// deploy target bosun is configured with (DEPLOY_TARGET or local).
+	Address string `yaml:"address,omitempty"`
+
+	// Appdata is the appdata root on the host (default: the remote appdata
+	// path).
+	Appdata string `yaml:"appdata,omitempty"`
+
+	// Labels describe the host for stack assignment, e.g. gpu or storage.
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+// LoadInventory reads and validates an inventory file.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read inventory: %w", err)
+	}
+
+	var inv Inventory
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("parse inventory: %w", err)
+	}
+
+	if len(inv.Hosts) == 0 {
+		return nil, fmt.Errorf("%s defines no hosts", InventoryFile)
+	}
+	var primary []string
+	for name, h := range inv.Hosts {
+		if !projectNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid host name %q (use lowercase letters, digits, - and _)", name)
+		}
+		if h.Address == "" {
+			primary = append(primary, name)
+		}
+	}
+	if len(primary) > 1 {
+		sort.Strings(primary)
+		return nil, fmt.Errorf("hosts %s have no address; only the primary host may leave it out", strings.Join(primary, ", "))
+	}
+	for stack := range inv.Stacks {
+		if _, err := inv.HostFor(stack); err != nil {
+			return nil, err
+		}
+	}
+
+	return &inv, nil
+}
+
+// HostNames returns the inventory's host names in sorted order.
+func (inv *Inventory) HostNames() []string {
+	names := make([]string, 0, len(inv.Hosts))
+	for name := range inv.Hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HostFor returns the name of the host a stack is assigned to, or "" when
+// it isn't assigned and runs on the primary host.
+func (inv *Inventory) HostFor(stack string) (string, error) {
+	target, ok := inv.Stacks[stack]
+	if !ok {
+		return "", nil
+	}
+	if _, ok := inv.Hosts[target]; ok {
+		return target, nil
+	}
+
+	var matches []string
+	for _, name := range inv.HostNames() {
+		if slices.Contains(inv.Hosts[name].Labels, target) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("stack %s: no host is named or labeled %q", stack, target)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("stack %s: label %q matches hosts %s; assign it by host name", stack, target, strings.Join(matches, ", "))
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeInventory(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), InventoryFile)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadInventory(t *testing.T) {
+	inv, err := LoadInventory(writeInventory(t, `hosts:
+  unraid:
+    labels: [storage]
+  node2:
+    address: deploy@10.0.0.12
+    appdata: /srv/appdata
+    labels: [compute, gpu]
+stacks:
+  media: storage
+  ollama: gpu
+  apps: node2
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node2", "unraid"}, inv.HostNames())
+	assert.Equal(t, "/srv/appdata", inv.Hosts["node2"].Appdata)
+
+	for stack, want := range map[string]string{"media": "unraid", "ollama": "node2", "apps": "node2", "core": ""} {
+		got, err := inv.HostFor(stack)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, stack)
+	}
+}
+
+func TestLoadInventory_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no hosts", "stacks: {}\n", "defines no hosts"},
+		{"bad host name", "hosts:\n  Node2: {address: root@node2}\n", "invalid host name"},
+		{"two primaries", "hosts:\n  a: {}\n  b: {}\n", "hosts a, b have no address"},
+		{"unknown host", "hosts:\n  a: {}\nstacks:\n  media: nas\n", `no host is named or labeled "nas"`},
+		{
+			"ambiguous label",
+			"hosts:\n  a: {labels: [gpu]}\n  b: {address: root@b, labels: [gpu]}\nstacks:\n  ollama: gpu\n",
+			`label "gpu" matches hosts a, b`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadInventory(writeInventory(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := LoadInventory(filepath.Join(t.TempDir(), InventoryFile))
+	assert.Error(t, err)
+}
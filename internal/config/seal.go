@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/seal"
+)
+
+// alertSecrets returns pointers to the credential fields of cfg, keyed by
+// their config file keys (see secretConfigKeys).
+func alertSecrets(cfg *AlertConfig) map[string]*string {
+	return map[string]*string{
+		"discord_webhook_url": &cfg.DiscordWebhookURL,
+		"sendgrid_api_key":    &cfg.SendGridAPIKey,
+		"twilio_account_sid":  &cfg.TwilioAccountSID,
+		"twilio_auth_token":   &cfg.TwilioAuthToken,
+		"slack_webhook_url":   &cfg.SlackWebhookURL,
+		"ntfy_token":          &cfg.NtfyToken,
+		"webhook_url":         &cfg.WebhookURL,
+		"webhook_token":       &cfg.WebhookToken,
+	}
+}
+
+// openAlertSecrets opens the sealed credentials in cfg with the age key.
+// Values that cannot be opened are cleared, so a provider is never sent a
+// sealed value as its credential.
+func openAlertSecrets(cfg *AlertConfig) error {
+	var key *seal.Key
+	var keyErr error
+	var failed []string
+
+	for name, field := range alertSecrets(cfg) {
+		if !seal.IsSealed(*field) {
+			continue
+		}
+		if key == nil && keyErr == nil {
+			key, keyErr = seal.LoadKey()
+		}
+		if keyErr != nil {
+			*field = ""
+			failed = append(failed, name)
+			continue
+		}
+		opened, err := key.Open(*field)
+		if err != nil {
+			*field = ""
+			failed = append(failed, name)
+			continue
+		}
+		*field = opened
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	reason := "wrong age key"
+	if keyErr != nil {
+		reason = keyErr.Error()
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("could not open sealed %s: %s", strings.Join(failed, ", "), reason)
+}
+
+// SealConfigFile seals the plaintext alert credentials in a config file in
+// place, keeping its comments and layout. Returns the keys it sealed.
+func SealConfigFile(path string, key *seal.Key) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	alerts := mappingValue(&doc, "alerts")
+	if alerts == nil || alerts.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var sealed []string
+	for i := 0; i+1 < len(alerts.Content); i += 2 {
+		name, value := alerts.Content[i].Value, alerts.Content[i+1]
+		if !isSecretConfigKey(name) || value.Kind != yaml.ScalarNode || value.Value == "" || seal.IsSealed(value.Value) {
+			continue
+		}
+		if value.Value, err = key.Seal(value.Value); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		value.Style = 0
+		value.Tag = "!!str"
+		sealed = append(sealed, name)
+	}
+	if len(sealed) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+	return sealed, nil
+}
+
+// mappingValue returns the value of key in the top-level mapping of doc.
+func mappingValue(doc *yaml.Node, key string) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isSecretConfigKey(name string) bool {
+	for _, key := range secretConfigKeys {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/seal"
+)
+
+func TestSealConfigFile(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	t.Setenv("SOPS_AGE_KEY", id.String())
+	key, err := seal.ParseKey(strings.NewReader(id.String()))
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	path := filepath.Join(root, "bosun.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Project config
+alerts:
+  # Ops channel
+  discord_webhook_url: https://discord.com/api/webhooks/1/s3cret
+  sendgrid_from_email: bosun@example.com
+  on_success: true
+`), 0644))
+
+	sealed, err := SealConfigFile(path, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"discord_webhook_url"}, sealed)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.Contains(t, string(data), "# Ops channel")
+	assert.Contains(t, string(data), "sendgrid_from_email: bosun@example.com")
+	assert.False(t, hasSecretKeys(string(data)), "sealed values are not plaintext credentials")
+
+	t.Run("opens sealed values on load", func(t *testing.T) {
+		cfg, err := loadAlertConfig(root)
+		require.NoError(t, err)
+		assert.Equal(t, "https://discord.com/api/webhooks/1/s3cret", cfg.DiscordWebhookURL)
+		assert.True(t, cfg.OnSuccess)
+	})
+
+	t.Run("sealing again is a no-op", func(t *testing.T) {
+		sealed, err := SealConfigFile(path, key)
+		require.NoError(t, err)
+		assert.Empty(t, sealed)
+	})
+
+	t.Run("clears values it cannot open", func(t *testing.T) {
+		t.Setenv("SOPS_AGE_KEY", "")
+		t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(root, "missing.txt"))
+
+		cfg, err := loadAlertConfig(root)
+		assert.ErrorContains(t, err, "discord_webhook_url")
+		assert.Empty(t, cfg.DiscordWebhookURL)
+	})
+}
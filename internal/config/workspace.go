@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile marks the top of a repository holding several bosun projects.
+const WorkspaceFile = "bosun.workspaces.yml"
+
+// projectNamePattern restricts project names to safe directory names.
+var projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Workspace lists the bosun projects in one repository.
+type Workspace struct {
+	// Root is the directory containing the workspace file.
+	Root string `yaml:"-"`
+
+	// Default is the project used when none is selected.
+	Default string `yaml:"default,omitempty"`
+
+	// Projects maps project names to their settings.
+	Projects map[string]Project `yaml:"projects"`
+}
+
+// Project is one bosun project within a workspace.
+type Project struct {
+	// Path is the project root, relative to the workspace root.
+	Path string `yaml:"path"`
+
+	// Target is the deploy target: empty for local, or "user@host" for remote.
+	Target string `yaml:"target,omitempty"`
+}
+
+// selectedProject is the workspace project chosen on the command line.
+var selectedProject string
+
+// SelectProject chooses the workspace project that FindRoot resolves to.
+// An empty name falls back to BOSUN_PROJECT.
+func SelectProject(name string) {
+	selectedProject = name
+}
+
+// SelectedProject returns the chosen workspace project, if any.
+func SelectedProject() string {
+	if selectedProject != "" {
+		return selectedProject
+	}
+	return os.Getenv("BOSUN_PROJECT")
+}
+
+// LoadWorkspace reads and validates a workspace file.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read workspace: %w", err)
+	}
+
+	var ws Workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("parse workspace: %w", err)
+	}
+	ws.Root = filepath.Dir(path)
+
+	if len(ws.Projects) == 0 {
+		return nil, fmt.Errorf("%s defines no projects", WorkspaceFile)
+	}
+	for name, p := range ws.Projects {
+		if !projectNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid project name %q (use lowercase letters, digits, - and _)", name)
+		}
+		if p.Path == "" {
+			return nil, fmt.Errorf("project %s: path is required", name)
+		}
+		clean := filepath.Clean(p.Path)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("project %s: path %q must stay inside the workspace", name, p.Path)
+		}
+	}
+	if ws.Default != "" {
+		if _, ok := ws.Projects[ws.Default]; !ok {
+			return nil, fmt.Errorf("default project %q is not defined", ws.Default)
+		}
+	}
+
+	return &ws, nil
+}
+
+// FindWorkspace searches upward from dir for a workspace file.
+// Returns nil without error if there is none.
+func FindWorkspace(dir string) (*Workspace, error) {
+	for {
+		path := filepath.Join(dir, WorkspaceFile)
+		if _, err := os.Stat(path); err == nil {
+			return LoadWorkspace(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// ProjectNames returns the workspace's project names in sorted order.
+func (w *Workspace) ProjectNames() []string {
+	names := make([]string, 0, len(w.Projects))
+	for name := range w.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProjectRoot returns the absolute root directory of a project.
+func (w *Workspace) ProjectRoot(name string) (string, error) {
+	p, ok := w.Projects[name]
+	if !ok {
+		return "", fmt.Errorf("unknown project %q (available: %s)", name, strings.Join(w.ProjectNames(), ", "))
+	}
+	return filepath.Join(w.Root, filepath.Clean(p.Path)), nil
+}
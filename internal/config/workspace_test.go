@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeWorkspace creates a workspace with homelab/ and vps/ projects.
+func writeWorkspace(t *testing.T, content string) string {
+	t.Helper()
+	root := evalSymlinks(t, t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "homelab", "manifest"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "vps", "manifest"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, WorkspaceFile), []byte(content), 0644))
+	return root
+}
+
+const testWorkspace = `projects:
+  homelab:
+    path: homelab
+  vps:
+    path: vps
+    target: root@vps.example.com
+`
+
+func TestLoadWorkspace(t *testing.T) {
+	root := writeWorkspace(t, testWorkspace)
+
+	ws, err := LoadWorkspace(filepath.Join(root, WorkspaceFile))
+	require.NoError(t, err)
+	assert.Equal(t, root, ws.Root)
+	assert.Equal(t, []string{"homelab", "vps"}, ws.ProjectNames())
+	assert.Equal(t, "root@vps.example.com", ws.Projects["vps"].Target)
+
+	projectRoot, err := ws.ProjectRoot("vps")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "vps"), projectRoot)
+
+	_, err = ws.ProjectRoot("cloud")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: homelab, vps")
+}
+
+func TestLoadWorkspace_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no projects", "projects: {}\n", "defines no projects"},
+		{"missing path", "projects:\n  homelab: {}\n", "path is required"},
+		{"escaping path", "projects:\n  homelab:\n    path: ../elsewhere\n", "must stay inside"},
+		{"bad name", "projects:\n  Home Lab:\n    path: homelab\n", "invalid project name"},
+		{"unknown default", "default: cloud\nprojects:\n  homelab:\n    path: homelab\n", "default project"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), WorkspaceFile)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+			_, err := LoadWorkspace(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestFindRoot_Workspace(t *testing.T) {
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(originalWd) }()
+	t.Cleanup(func() { SelectProject("") })
+	t.Setenv("BOSUN_PROJECT", "")
+
+	t.Run("inside a project uses that project", func(t *testing.T) {
+		root := writeWorkspace(t, testWorkspace)
+		require.NoError(t, os.Chdir(filepath.Join(root, "vps", "manifest")))
+
+		got, err := FindRoot()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "vps"), got)
+	})
+
+	t.Run("selected project wins", func(t *testing.T) {
+		root := writeWorkspace(t, testWorkspace)
+		require.NoError(t, os.Chdir(filepath.Join(root, "vps")))
+		SelectProject("homelab")
+		defer SelectProject("")
+
+		got, err := FindRoot()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "homelab"), got)
+	})
+
+	t.Run("project from environment", func(t *testing.T) {
+		root := writeWorkspace(t, testWorkspace)
+		require.NoError(t, os.Chdir(root))
+		t.Setenv("BOSUN_PROJECT", "vps")
+
+		got, err := FindRoot()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "vps"), got)
+	})
+
+	t.Run("workspace root uses default", func(t *testing.T) {
+		root := writeWorkspace(t, "default: homelab\n"+testWorkspace)
+		require.NoError(t, os.Chdir(root))
+
+		got, err := FindRoot()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "homelab"), got)
+	})
+
+	t.Run("workspace root without default asks for a project", func(t *testing.T) {
+		root := writeWorkspace(t, testWorkspace)
+		require.NoError(t, os.Chdir(root))
+
+		_, err := FindRoot()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--project")
+	})
+
+	t.Run("selected project outside a workspace", func(t *testing.T) {
+		require.NoError(t, os.Chdir(evalSymlinks(t, t.TempDir())))
+		SelectProject("homelab")
+		defer SelectProject("")
+
+		_, err := FindRoot()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), WorkspaceFile)
+	})
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// isAutomatedTrigger reports whether a trigger source is automation that an
+// all-stop pauses: polls, the startup reconcile, and git pushes. Manual
+// triggers from the CLI and API still run, so operators can deploy a fix.
+func isAutomatedTrigger(source string) bool {
+	return source == "poll" || source == "startup" || isPushTrigger(source)
+}
+
+// allStopped reports whether an all-stop in force drops a trigger from
+// source, logging it when it does.
+func (d *Daemon) allStopped(source string) bool {
+	if !isAutomatedTrigger(source) {
+		return false
+	}
+	stop := d.allStop(time.Now())
+	if stop == nil {
+		return false
+	}
+	ui.Warning("All-stop by %s until %s; ignoring trigger from %s", stop.By, stop.Until.Format(time.RFC3339), source)
+	return true
+}
+
+// allStop returns the all-stop in force at now, or nil. Once its window
+// passes, the all-stop is cleared from the state and automation resumes.
+func (d *Daemon) allStop(now time.Time) *state.AllStop {
+	dir := d.stateDir()
+	if dir == "" {
+		return nil
+	}
+	store := state.NewStore(dir)
+	st, err := store.Load()
+	if err != nil {
+		ui.Warning("Failed to read all-stop: %v", err)
+		return nil
+	}
+	if stop := st.ActiveAllStop(now); stop != nil {
+		return stop
+	}
+	if st.AllStop == nil {
+		return nil
+	}
+
+	var expired *state.AllStop
+	err = store.Update(func(st *state.State) error {
+		expired = st.ExpireAllStop(now)
+		return nil
+	})
+	if err != nil {
+		ui.Warning("Failed to clear expired all-stop: %v", err)
+		return nil
+	}
+	if expired != nil {
+		ui.Success("All-stop by %s ended at %s; automation resumed", expired.By, expired.Until.Format(time.RFC3339))
+		slog.Info("All-stop expired",
+			slog.String("by", expired.By),
+			slog.Time("until", expired.Until))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/registry"
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestIsAutomatedTrigger(t *testing.T) {
+	for source, want := range map[string]bool{
+		"poll":              true,
+		"startup":           true,
+		"webhook":           true,
+		"github":            true,
+		"manual":            false,
+		"socket (pid:1234)": false,
+		"cli":               false,
+	} {
+		if got := isAutomatedTrigger(source); got != want {
+			t.Errorf("isAutomatedTrigger(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+func TestDaemon_AllStop(t *testing.T) {
+	dir := t.TempDir()
+	d := &Daemon{config: &Config{ReconcileConfig: &reconcile.Config{StateDir: dir}}}
+	store := state.NewStore(dir)
+	now := time.Now()
+
+	if stop := d.allStop(now); stop != nil {
+		t.Fatalf("allStop() = %+v with no state, want nil", stop)
+	}
+
+	err := store.Update(func(st *state.State) error {
+		st.StartAllStop(now, time.Hour, "ops@tower", "incident")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if stop := d.allStop(now.Add(time.Minute)); stop == nil || stop.By != "ops@tower" {
+		t.Fatalf("allStop() = %+v, want the all-stop by ops@tower", stop)
+	}
+	// A poll is dropped; with no reconciler, running it would panic
+	if err := d.TriggerReconcile(context.Background(), "poll"); err != nil {
+		t.Errorf("TriggerReconcile(poll) error = %v", err)
+	}
+
+	// Pushes held before the all-stop are dropped when their batch fires
+	d.runHeldPushes("github:alice")
+	if d.reconciling {
+		t.Error("held pushes started a reconcile during an all-stop")
+	}
+
+	// Scheduled image update checks are skipped
+	appdata := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appdata, "compose"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(appdata, "compose", "apps.yml"), []byte("services:\n  app:\n    image: acme/app:1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d.config.ReconcileConfig.LocalAppdataPath = appdata
+	d.newRegistryClient = func() *registry.Client {
+		t.Error("image update check ran during an all-stop")
+		return registry.NewClient()
+	}
+	d.scheduledUpdateCheck(context.Background(), now.Add(time.Minute))
+
+	// Past the window, the all-stop is cleared and audited
+	if stop := d.allStop(now.Add(2 * time.Hour)); stop != nil {
+		t.Errorf("allStop() after window = %+v, want nil", stop)
+	}
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if st.AllStop != nil {
+		t.Errorf("AllStop = %+v after expiry, want nil", st.AllStop)
+	}
+	if n := len(st.Audit); n != 2 || st.Audit[1].Action != state.AuditExpire {
+		t.Errorf("Audit = %+v, want all-stop then expire", st.Audit)
+	}
+}
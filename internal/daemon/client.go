@@ -8,6 +8,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,7 +25,7 @@ type Client struct {
 // NewClient creates a new daemon client using Unix socket.
 func NewClient(socketPath string) *Client {
 	if socketPath == "" {
-		socketPath = "/var/run/bosun.sock"
+		socketPath = DefaultSocketPath()
 	}
 
 	return &Client{
@@ -88,6 +90,88 @@ func (c *Client) Trigger(ctx context.Context, source string) (*TriggerResponse,
 	return &result, nil
 }
 
+// TriggerStream triggers a reconciliation and streams its progress, calling
+// onEvent for each event until the run serving the trigger is done. It
+// returns the final done event. The client's timeout does not apply; bound
+// the stream with ctx.
+func (c *Client) TriggerStream(ctx context.Context, source string, onEvent func(Event)) (*Event, error) {
+	body, err := json.Marshal(TriggerRequest{Source: source})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/trigger", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Body = io.NopCloser(jsonReader(body))
+	httpReq.ContentLength = int64(len(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", StreamContentType)
+	c.addAuth(httpReq)
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon at %s: %w", c.endpoint(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, string(body))
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), StreamContentType) {
+		return nil, fmt.Errorf("daemon does not support streamed responses (upgrade the daemon)")
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var e Event
+		if err := dec.Decode(&e); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("stream ended before the reconcile finished: %w", err)
+		}
+		if onEvent != nil {
+			onEvent(e)
+		}
+		if e.Final() {
+			return &e, nil
+		}
+	}
+}
+
+// Cancel asks the daemon to cancel the running reconciliation and drop any
+// queued trigger.
+func (c *Client) Cancel(ctx context.Context) (*CancelResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/cancel", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addAuth(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon at %s: %w", c.endpoint(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result CancelResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
 // addAuth adds bearer token authentication if using TCP.
 func (c *Client) addAuth(req *http.Request) {
 	if c.bearerToken != "" {
@@ -158,6 +242,66 @@ func (c *Client) Ping(ctx context.Context) error {
 	return err
 }
 
+// DeployWindow asks the daemon whether it is currently safe to deploy.
+func (c *Client) DeployWindow(ctx context.Context) (*DeployWindowResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/deploy-window", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addAuth(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon at %s: %w", c.endpoint(), err)
+	}
+	defer resp.Body.Close()
+
+	// 503 carries the same body and means unsafe
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result DeployWindowResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// History fetches up to limit of the daemon's most recent reconcile runs,
+// newest first. A limit of 0 uses the daemon's default.
+func (c *Client) History(ctx context.Context, limit int) (*HistoryResponse, error) {
+	url := c.baseURL + "/history"
+	if limit > 0 {
+		url += "?limit=" + strconv.Itoa(limit)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addAuth(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon at %s: %w", c.endpoint(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result HistoryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
 // Config fetches configuration from the daemon.
 // This is used for daemon-injected secrets - the webhook container
 // fetches secrets from the daemon rather than storing them on disk.
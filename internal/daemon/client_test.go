@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 
 func TestNewClient(t *testing.T) {
 	t.Run("default socket path", func(t *testing.T) {
+		t.Setenv("XDG_RUNTIME_DIR", "")
 		client := NewClient("")
 		if client.socketPath != "/var/run/bosun.sock" {
 			t.Errorf("socketPath = %q, want /var/run/bosun.sock", client.socketPath)
@@ -169,6 +172,65 @@ func TestClient_Status(t *testing.T) {
 	})
 }
 
+func TestClient_DeployWindow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deploy-window" {
+			t.Errorf("Path = %s, want /deploy-window", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(DeployWindowResponse{
+			Checks: []DeployCheck{{Name: "reconcile", Detail: "a reconcile is in progress"}},
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	resp, err := client.DeployWindow(context.Background())
+	if err != nil {
+		t.Fatalf("DeployWindow() error = %v", err)
+	}
+	if resp.Safe {
+		t.Error("Safe = true, want false")
+	}
+	if reasons := resp.Reasons(); len(reasons) != 1 {
+		t.Errorf("Reasons() = %v, want one reason", reasons)
+	}
+}
+
+func TestClient_History(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/history" {
+			t.Errorf("Path = %s, want /history", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("limit"); got != "5" {
+			t.Errorf("limit = %q, want 5", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(HistoryResponse{
+			Runs: []ReconcileRun{{Source: "poll", ToCommit: "abc1234", Outcome: StatusSucceeded}},
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	resp, err := client.History(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(resp.Runs) != 1 || resp.Runs[0].ToCommit != "abc1234" {
+		t.Errorf("Runs = %+v", resp.Runs)
+	}
+}
+
 func TestClient_Health(t *testing.T) {
 	t.Run("healthy", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -353,3 +415,26 @@ func TestJsonReader(t *testing.T) {
 		t.Errorf("Read() at EOF error = %v, want EOF", err)
 	}
 }
+
+func TestDefaultSocketPath(t *testing.T) {
+	t.Run("without XDG_RUNTIME_DIR", func(t *testing.T) {
+		t.Setenv("XDG_RUNTIME_DIR", "")
+		if got := DefaultSocketPath(); got != SystemSocketPath {
+			t.Errorf("DefaultSocketPath() = %q, want %q", got, SystemSocketPath)
+		}
+	})
+
+	t.Run("non-root user", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("running as root")
+		}
+		if _, err := os.Stat(SystemSocketPath); err == nil {
+			t.Skip("system socket present")
+		}
+		dir := t.TempDir()
+		t.Setenv("XDG_RUNTIME_DIR", dir)
+		if got, want := DefaultSocketPath(), filepath.Join(dir, "bosun.sock"); got != want {
+			t.Errorf("DefaultSocketPath() = %q, want %q", got, want)
+		}
+	})
+}
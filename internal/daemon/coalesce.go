@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxQuietWait caps how long a batch of push triggers is held back. A push
+// storm that never goes quiet still reconciles at least this often.
+const MaxQuietWait = 5 * time.Minute
+
+// pushSources are the trigger sources that come from git pushes: the
+// daemon's own webhook endpoints and the standalone webhook receiver.
+var pushSources = []string{"webhook", "github", "gitlab", "gitea", "bitbucket"}
+
+// isPushTrigger reports whether a trigger source is a git push, which is
+// held back for the quiet period. Polls, startup, and manual triggers run
+// right away.
+func isPushTrigger(source string) bool {
+	for _, prefix := range pushSources {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// triggerBatch coalesces bursts of triggers into one. Each trigger restarts
+// the quiet period; when it passes without another trigger, or once the
+// batch has waited maxWait, fire runs once for the whole batch.
+type triggerBatch struct {
+	quiet   time.Duration
+	maxWait time.Duration
+	fire    func(source string)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	first   time.Time // When the pending batch started
+	last    string    // Source of the latest trigger in the batch
+	count   int       // Triggers in the pending batch
+	gen     int       // Bumped per timer, so a stale timer can't fire a newer batch
+	stopped bool
+}
+
+func newTriggerBatch(quiet, maxWait time.Duration, fire func(source string)) *triggerBatch {
+	return &triggerBatch{quiet: quiet, maxWait: maxWait, fire: fire}
+}
+
+// add adds a trigger to the pending batch, starting one if needed, and
+// returns how long until the batch fires.
+func (b *triggerBatch) add(source string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return 0
+	}
+
+	now := time.Now()
+	if b.count == 0 {
+		b.first = now
+	}
+	b.last = source
+	b.count++
+
+	wait := b.quiet
+	if deadline := b.first.Add(b.maxWait); now.Add(wait).After(deadline) {
+		wait = max(deadline.Sub(now), 0)
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.gen++
+	gen := b.gen
+	b.timer = time.AfterFunc(wait, func() { b.expire(gen) })
+	return wait
+}
+
+// expire fires the pending batch when its timer, gen, is still current.
+func (b *triggerBatch) expire(gen int) {
+	b.mu.Lock()
+	current := gen == b.gen
+	b.mu.Unlock()
+	if !current {
+		return
+	}
+	if source, ok := b.take(); ok {
+		b.fire(source)
+	}
+}
+
+// take clears the pending batch and returns the source to reconcile it
+// under, e.g. "github:alice (+9 coalesced)".
+func (b *triggerBatch) take() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.gen++
+	if b.count == 0 {
+		return "", false
+	}
+	source := b.last
+	if b.count > 1 {
+		source = fmt.Sprintf("%s (+%d coalesced)", source, b.count-1)
+	}
+	b.count = 0
+	return source, true
+}
+
+// stop discards the pending batch and ignores later triggers.
+func (b *triggerBatch) stop() {
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+	b.take()
+}
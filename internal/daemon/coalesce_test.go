@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// collectFires returns a fire func and a channel of the sources it fired.
+func collectFires() (func(string), chan string) {
+	fired := make(chan string, 10)
+	return func(source string) { fired <- source }, fired
+}
+
+func TestTriggerBatch_CoalescesBurst(t *testing.T) {
+	fire, fired := collectFires()
+	b := newTriggerBatch(50*time.Millisecond, time.Minute, fire)
+
+	for _, source := range []string{"github:a", "github:b", "github:c"} {
+		b.add(source)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case source := <-fired:
+		if source != "github:c (+2 coalesced)" {
+			t.Errorf("fired source = %q, want github:c (+2 coalesced)", source)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("batch never fired")
+	}
+
+	select {
+	case source := <-fired:
+		t.Errorf("batch fired twice, second time for %q", source)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestTriggerBatch_MaxWait(t *testing.T) {
+	fire, fired := collectFires()
+	b := newTriggerBatch(time.Hour, 30*time.Millisecond, fire)
+
+	if wait := b.add("webhook"); wait > 30*time.Millisecond {
+		t.Errorf("add() wait = %s, want at most the max wait", wait)
+	}
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("batch was held past the max wait")
+	}
+}
+
+func TestTriggerBatch_Take(t *testing.T) {
+	fire, fired := collectFires()
+	b := newTriggerBatch(30*time.Millisecond, time.Minute, fire)
+
+	if _, ok := b.take(); ok {
+		t.Error("take() on an empty batch should report nothing pending")
+	}
+
+	b.add("gitea:x")
+	source, ok := b.take()
+	if !ok || source != "gitea:x" {
+		t.Errorf("take() = %q, %v, want gitea:x, true", source, ok)
+	}
+
+	// A taken batch no longer fires
+	select {
+	case source := <-fired:
+		t.Errorf("taken batch fired for %q", source)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestTriggerBatch_Stop(t *testing.T) {
+	fire, fired := collectFires()
+	b := newTriggerBatch(10*time.Millisecond, time.Minute, fire)
+
+	b.add("github:a")
+	b.stop()
+	b.add("github:b")
+
+	select {
+	case source := <-fired:
+		t.Errorf("stopped batch fired for %q", source)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestIsPushTrigger(t *testing.T) {
+	tests := map[string]bool{
+		"webhook":                  true,
+		"github:alice":             true,
+		"gitlab (pid:123 uid:0)":   true,
+		"bitbucket:Jane Doe":       true,
+		"poll":                     false,
+		"startup":                  false,
+		"manual":                   false,
+		"socket (pid:42 uid:1000)": false,
+	}
+	for source, want := range tests {
+		if got := isPushTrigger(source); got != want {
+			t.Errorf("isPushTrigger(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+func TestConfigFromEnv_QuietPeriod(t *testing.T) {
+	t.Setenv("BOSUN_QUIET_PERIOD", "30s")
+	if cfg := ConfigFromEnv(); cfg.QuietPeriod != 30*time.Second {
+		t.Errorf("QuietPeriod = %s, want 30s", cfg.QuietPeriod)
+	}
+
+	t.Setenv("BOSUN_QUIET_PERIOD", "soon")
+	if cfg := ConfigFromEnv(); cfg.QuietPeriod != 0 {
+		t.Errorf("QuietPeriod = %s, want 0 for an invalid value", cfg.QuietPeriod)
+	}
+}
+
+func TestTriggerReconcile_HoldsPushes(t *testing.T) {
+	fire, fired := collectFires()
+	d := &Daemon{config: DefaultConfig(), pushes: newTriggerBatch(time.Hour, time.Hour, fire)}
+
+	if err := d.TriggerReconcile(context.Background(), "github:alice"); err != nil {
+		t.Fatalf("TriggerReconcile() error = %v", err)
+	}
+	if d.reconciling {
+		t.Error("a push trigger should not start a reconcile during the quiet period")
+	}
+
+	// Nothing is running, but cancelling drops the held push
+	d.CancelReconcile()
+	if _, ok := d.pushes.take(); ok {
+		t.Error("CancelReconcile() should drop held pushes")
+	}
+	select {
+	case source := <-fired:
+		t.Errorf("held push fired for %q", source)
+	default:
+	}
+}
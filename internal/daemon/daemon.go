@@ -8,20 +8,27 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/cameronsjo/bosun/internal/alert"
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+	"github.com/cameronsjo/bosun/internal/manifest"
 	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/registry"
+	"github.com/cameronsjo/bosun/internal/timezone"
 	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/webhook"
 )
 
 // Config holds daemon configuration.
 type Config struct {
 	// Socket API settings (primary)
-	SocketPath string // Path to Unix socket (default: /var/run/bosun.sock)
+	SocketPath string // Path to Unix socket (default: DefaultSocketPath)
 
 	// TCP API settings (optional, for remote access)
 	EnableTCP   bool   // Enable TCP listener (default: false)
@@ -29,20 +36,57 @@ type Config struct {
 	BearerToken string // Bearer token for TCP authentication (required if EnableTCP)
 
 	// HTTP server settings (for webhooks, kept for backwards compatibility)
-	Port          int    // HTTP port for webhooks and health (default: 8080)
-	EnableHTTP    bool   // Enable HTTP server (default: true for backwards compat)
-	WebhookPath   string // Path for webhook endpoint (default: /webhook)
-	HealthPath    string // Path for health endpoint (default: /health)
-	ReadyPath     string // Path for readiness endpoint (default: /ready)
-	WebhookSecret string // Secret for validating webhook signatures
+	Port            int            // HTTP port for webhooks and health (default: 8080)
+	EnableHTTP      bool           // Enable HTTP server (default: true for backwards compat)
+	WebhookPath     string         // Path for webhook endpoint (default: /webhook)
+	HealthPath      string         // Path for health endpoint (default: /health)
+	ReadyPath       string         // Path for readiness endpoint (default: /ready)
+	WebhookSecret   string         // Secret for validating webhook signatures
+	WebhookClients  []PeerIdentity // Socket clients allowed to fetch WebhookSecret (default: root and the daemon's user)
+	WebhookFilter   webhook.Filter // Branches and paths a push must touch to trigger a reconcile (default: all)
+	MaintenanceHTML string         // Custom page served at /maintenance (default: built-in page)
 
 	// Polling settings
 	PollInterval time.Duration // Interval between polls (0 disables polling)
 	InitialDelay time.Duration // Delay before first poll (default: 10s)
 
+	// Trigger coalescing
+	QuietPeriod time.Duration // Hold push triggers until no push for this long (0 reconciles each push at once)
+
 	// Reconcile settings
 	ReconcileConfig *reconcile.Config
 
+	// Workspace settings (several bosun projects in one repo)
+	Workspace bool     // Reconcile projects from bosun.workspaces.yml instead of the repo as a whole
+	Projects  []string // Projects to reconcile in workspace mode (empty means all)
+
+	// Host metrics
+	DockerRootDir string // Docker data root reported in health disk usage (default: /var/lib/docker, or the rootless data root)
+
+	// Container health watch
+	WatchInterval time.Duration // Interval between container health polls (0 disables the watch)
+
+	// Drift remediation (checked on each health watch poll)
+	DriftRemediate bool          // Redeploy stacks that stay drifted instead of only warning
+	DriftDebounce  time.Duration // How long a stack must stay drifted before it is redeployed
+
+	// Weekly digest
+	Digest *DigestSchedule // When the weekly digest is sent (nil disables it)
+
+	// Image update checks
+	UpdateCheckInterval time.Duration // Interval between registry checks of the deployed images (0 disables them)
+
+	// Deploy window settings (reported by /deploy-window)
+	FreezeWindows     []FreezeWindow // Recurring periods when deploys are unsafe
+	Timezone          *time.Location // Zone freeze windows are evaluated in (nil: local time)
+	MoverPIDFile      string         // Present while the Unraid mover runs (default: /var/run/mover.pid)
+	ErrorBudget       int            // Failed reconciles allowed within ErrorBudgetWindow (0 disables)
+	ErrorBudgetWindow time.Duration  // Window for counting failed reconciles (default: 24h)
+
+	// Maintenance windows: recurring periods, in Timezone, when drift checks
+	// and alerts pause and poll reconciles wait for the window to end
+	MaintenanceWindows []FreezeWindow
+
 	// Alerting
 	AlertManager *alert.Manager
 }
@@ -50,9 +94,9 @@ type Config struct {
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		SocketPath:   "/var/run/bosun.sock",
-		EnableTCP:    false,                  // Disabled by default for security
-		TCPAddr:      "127.0.0.1:9090",       // Localhost only by default
+		SocketPath:   DefaultSocketPath(),
+		EnableTCP:    false,            // Disabled by default for security
+		TCPAddr:      "127.0.0.1:9090", // Localhost only by default
 		Port:         8080,
 		EnableHTTP:   true, // Backwards compat: enable HTTP by default for now
 		WebhookPath:  "/webhook",
@@ -60,6 +104,14 @@ func DefaultConfig() *Config {
 		ReadyPath:    "/ready",
 		PollInterval: time.Hour,
 		InitialDelay: 10 * time.Second,
+
+		DockerRootDir: docker.DefaultRootDir(),
+		WatchInterval: DefaultWatchInterval,
+		DriftDebounce: DefaultDriftDebounce,
+
+		MoverPIDFile:      "/var/run/mover.pid",
+		ErrorBudget:       DefaultErrorBudget,
+		ErrorBudgetWindow: DefaultErrorBudgetWindow,
 	}
 }
 
@@ -70,7 +122,13 @@ type Daemon struct {
 	tcpServer     *TCPServer    // TCP API with bearer auth (optional)
 	httpServer    *Server       // HTTP server for webhooks (optional)
 	reconciler    *reconcile.Reconciler
+	reconcileOpts []reconcile.ReconcilerOption
 	alerter       *alert.Manager
+	requests      *RequestMetrics   // Socket and TCP API request counters
+	events        *eventHub         // Reconcile events for streamed API responses
+	watch         *healthWatch      // Container health across watch polls
+	drift         *driftWatch       // Drifted stacks across watch polls
+	updates       map[string]string // Stack/service -> image update last alerted on
 	ready         bool
 	readyMu       sync.RWMutex
 	stopPoll      chan struct{}
@@ -79,12 +137,34 @@ type Daemon struct {
 	stateMu       sync.RWMutex
 	lastReconcile time.Time
 	lastError     error
+	failures      []time.Time          // Recent failed reconciles, oldest first, for the error budget
+	history       []ReconcileRun       // Recent runs, oldest first, for /history
+	maintenance   string               // Maintenance window the daemon is in, if any
+	host          *hostmetrics.Metrics // Host metrics from the last collection, served by health
 
 	// Concurrency control: single-flight reconcile with coalescing
 	reconcileMu    sync.Mutex // Guards reconcile execution
 	reconciling    bool       // True while reconcile is in progress
 	pendingTrigger bool       // Dirty flag: another trigger arrived during reconcile
 	triggerSource  string     // Source of pending trigger (for logging)
+	cancelRun      func()     // Cancels the running reconcile (nil when idle)
+	cancelled      bool       // The running reconcile was cancelled via the API
+
+	// Push triggers held back for QuietPeriod (nil when disabled)
+	pushes *triggerBatch
+
+	// newDockerClient connects to Docker for the health watch (tests
+	// substitute a fake).
+	newDockerClient func() (*docker.Client, error)
+
+	// configHashes and composeUp compute expected config hashes and
+	// redeploy a stack for drift remediation (tests substitute fakes).
+	configHashes func(ctx context.Context, composeFile string) (map[string]string, error)
+	composeUp    func(ctx context.Context, composeFile string) error
+
+	// newRegistryClient creates the client for each image update check
+	// (tests point it at a fake registry).
+	newRegistryClient func() *registry.Client
 }
 
 // New creates a new Daemon with the given configuration.
@@ -97,17 +177,46 @@ func New(cfg *Config) (*Daemon, error) {
 		cfg.ReconcileConfig = reconcile.DefaultConfig()
 	}
 
-	// Create reconciler with alerter if available
-	var opts []reconcile.ReconcilerOption
+	events := newEventHub()
+
+	// Create reconciler with alerter if available; progress feeds streams
+	opts := []reconcile.ReconcilerOption{
+		reconcile.WithProgress(func(p reconcile.Progress) {
+			events.publish(Event{Type: EventProgress, Step: p.Step, Message: p.Message})
+		}),
+	}
 	if cfg.AlertManager != nil {
 		opts = append(opts, reconcile.WithAlerter(cfg.AlertManager))
 	}
 
+	deploy := reconcile.NewDeployOps(false)
+	deploy.Profiles = cfg.ReconcileConfig.Profiles
+
 	d := &Daemon{
-		config:     cfg,
-		reconciler: reconcile.NewReconciler(cfg.ReconcileConfig, opts...),
-		alerter:    cfg.AlertManager,
-		stopPoll:   make(chan struct{}),
+		config:        cfg,
+		reconciler:    reconcile.NewReconciler(cfg.ReconcileConfig, opts...),
+		reconcileOpts: opts,
+		alerter:       cfg.AlertManager,
+		requests:      NewRequestMetrics(),
+		events:        events,
+		watch:         newHealthWatch(),
+		drift:         newDriftWatch(),
+		updates:       make(map[string]string),
+		stopPoll:      make(chan struct{}),
+
+		newDockerClient: func() (*docker.Client, error) { return docker.NewClient() },
+		configHashes: func(ctx context.Context, composeFile string) (map[string]string, error) {
+			compose, err := docker.NewComposeClient(composeFile)
+			if err != nil {
+				return nil, err
+			}
+			return compose.WithProject(reconcile.ComposeProjectName(composeFile)).WithProfiles(cfg.ReconcileConfig.Profiles...).ConfigHashes(ctx)
+		},
+		composeUp:         deploy.ComposeUp,
+		newRegistryClient: registry.NewClient,
+	}
+	if cfg.QuietPeriod > 0 {
+		d.pushes = newTriggerBatch(cfg.QuietPeriod, MaxQuietWait, d.runHeldPushes)
 	}
 
 	// Create Unix socket server (primary API)
@@ -154,6 +263,35 @@ func (d *Daemon) Run(ctx context.Context) error {
 		ui.Info("HTTP Port: %d", d.config.Port)
 	}
 	ui.Info("Poll interval: %s", d.config.PollInterval)
+	if d.config.QuietPeriod > 0 {
+		ui.Info("Push quiet period: %s", d.config.QuietPeriod)
+	}
+	if d.config.WatchInterval > 0 {
+		ui.Info("Health watch interval: %s", d.config.WatchInterval)
+	}
+	if d.config.DriftRemediate {
+		switch {
+		case d.config.WatchInterval == 0:
+			ui.Warning("Drift remediation needs the health watch; set BOSUN_WATCH_INTERVAL")
+		case d.deployedComposeDir() == "":
+			ui.Warning("Drift remediation only covers local single-project deploys; it is off")
+		default:
+			ui.Info("Drift remediation: on (debounce %s)", d.config.DriftDebounce)
+		}
+	}
+	if d.config.Digest != nil {
+		ui.Info("Weekly digest: %s", d.config.Digest.Spec)
+	}
+	for _, w := range d.config.MaintenanceWindows {
+		ui.Info("Maintenance window: %s", w.Spec)
+	}
+	if d.config.UpdateCheckInterval > 0 {
+		if d.deployedComposeDir() == "" {
+			ui.Warning("Image update checks only cover local single-project deploys; they are off")
+		} else {
+			ui.Info("Image update checks: every %s", d.config.UpdateCheckInterval)
+		}
+	}
 
 	// Setup signal handling
 	ctx, cancel := context.WithCancel(ctx)
@@ -205,6 +343,25 @@ func (d *Daemon) Run(ctx context.Context) error {
 		go d.pollLoop(ctx)
 	}
 
+	// Start the container health watch if enabled
+	if d.config.WatchInterval > 0 {
+		go d.watchLoop(ctx)
+	}
+
+	// Collect host metrics in the background so health requests don't
+	// wait on a slow disk
+	go d.hostMetricsLoop(ctx)
+
+	// Start the weekly digest if scheduled
+	if d.config.Digest != nil {
+		go d.digestLoop(ctx)
+	}
+
+	// Start image update checks if enabled
+	if d.config.UpdateCheckInterval > 0 && d.deployedComposeDir() != "" {
+		go d.updatesLoop(ctx)
+	}
+
 	ui.Success("Daemon ready")
 
 	// Wait for shutdown signal or error
@@ -225,6 +382,9 @@ func (d *Daemon) Run(ctx context.Context) error {
 // shutdown performs graceful shutdown of all components.
 func (d *Daemon) shutdown() error {
 	ui.Info("Shutting down...")
+	if d.pushes != nil {
+		d.pushes.stop()
+	}
 
 	// Stop polling
 	close(d.stopPoll)
@@ -259,9 +419,46 @@ func (d *Daemon) shutdown() error {
 }
 
 // TriggerReconcile triggers a reconciliation run.
+// While an all-stop is in force, automated triggers are dropped.
+// With a QuietPeriod, push triggers are held until pushes stop for that
+// long and then reconciled once; any other trigger runs at once and takes
+// the held pushes with it, since every run reconciles the latest commit.
 // If a reconcile is already in progress, it sets the pending flag and returns immediately.
 // The running reconcile will check the pending flag and re-run if set.
 func (d *Daemon) TriggerReconcile(ctx context.Context, source string) error {
+	if d.allStopped(source) {
+		return nil
+	}
+	if d.pushes != nil {
+		if isPushTrigger(source) {
+			wait := d.pushes.add(source)
+			ui.Info("Holding trigger from %s; reconciling in %s unless more pushes arrive", source, wait.Round(time.Second))
+			d.events.publish(Event{Type: EventProgress, Step: "coalesce", Message: fmt.Sprintf("Waiting %s for more pushes", wait.Round(time.Second))})
+			return nil
+		}
+		if held, ok := d.pushes.take(); ok {
+			ui.Info("Trigger from %s also covers held pushes from %s", source, held)
+		}
+	}
+	return d.runTrigger(ctx, source)
+}
+
+// runHeldPushes reconciles the pushes a QuietPeriod held, once they settle.
+func (d *Daemon) runHeldPushes(source string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if err := d.runTrigger(ctx, source); err != nil {
+		ui.Error("Coalesced reconciliation failed: %v", err)
+	}
+}
+
+// runTrigger runs a reconcile for source, or queues it behind the running
+// one. Automated triggers are checked for an all-stop again here, since
+// held pushes and queued triggers run after one may have started.
+func (d *Daemon) runTrigger(ctx context.Context, source string) error {
+	if d.allStopped(source) {
+		return nil
+	}
 	d.reconcileMu.Lock()
 
 	if d.reconciling {
@@ -294,12 +491,19 @@ func (d *Daemon) reconcileLoop(ctx context.Context, source string) error {
 
 		// Check for pending trigger
 		d.reconcileMu.Lock()
+		cancelled := d.cancelled
+		d.cancelled = false
+		if d.pendingTrigger && d.allStopped(d.triggerSource) {
+			d.pendingTrigger = false
+			d.triggerSource = ""
+		}
 		if d.pendingTrigger {
 			// Another trigger arrived - reset flag and run again
 			source = d.triggerSource
 			d.pendingTrigger = false
 			d.triggerSource = ""
 			d.reconcileMu.Unlock()
+			d.publishDone(err, cancelled, true)
 			ui.Info("Processing queued trigger from %s", source)
 			continue
 		}
@@ -307,6 +511,7 @@ func (d *Daemon) reconcileLoop(ctx context.Context, source string) error {
 		// No pending trigger - we're done
 		d.reconciling = false
 		d.reconcileMu.Unlock()
+		d.publishDone(err, cancelled, false)
 		return lastErr
 	}
 }
@@ -316,35 +521,115 @@ func (d *Daemon) executeReconcile(ctx context.Context, source string) error {
 	start := time.Now()
 	ui.Info("Starting reconciliation (source: %s)", source)
 
-	err := d.reconciler.Run(ctx)
+	// Register a cancel func so /cancel can stop this run
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.reconcileMu.Lock()
+	d.cancelRun = cancel
+	d.reconcileMu.Unlock()
+	defer func() {
+		d.reconcileMu.Lock()
+		d.cancelRun = nil
+		d.reconcileMu.Unlock()
+	}()
+	d.events.publish(Event{Type: EventStarted, Message: source})
+	ctx = reconcile.WithTrigger(ctx, source)
+
+	var err error
+	if d.config.Workspace {
+		err = reconcile.RunProjects(ctx, d.config.ReconcileConfig, d.config.Projects, d.reconcileOpts...)
+	} else {
+		err = d.reconciler.Run(ctx)
+	}
 
 	// Update state (use stateMu for thread-safe reads from health checks)
 	d.stateMu.Lock()
-	d.lastReconcile = time.Now()
+	d.lastReconcile = time.Now().UTC()
 	d.lastError = err
 	d.stateMu.Unlock()
 
+	run := ReconcileRun{
+		Source:     source,
+		StartedAt:  start.UTC(),
+		DurationMS: time.Since(start).Milliseconds(),
+		Outcome:    StatusSucceeded,
+	}
+	if !d.config.Workspace {
+		run.FromCommit, run.ToCommit = d.reconciler.CommitRange()
+	}
+
 	if err != nil {
+		d.reconcileMu.Lock()
+		cancelled := d.cancelled
+		d.reconcileMu.Unlock()
+		d.recordReconcile(err, cancelled)
+		run.Outcome, run.Error = StatusFailed, err.Error()
+		if cancelled {
+			run.Outcome = StatusCancelled
+		}
+		d.recordRun(run)
+		if cancelled {
+			// Cancelled runs do not count against the error budget
+			ui.Warning("Reconciliation cancelled after %s", time.Since(start))
+			return err
+		}
+		d.recordFailure(time.Now())
 		ui.Error("Reconciliation failed after %s: %v", time.Since(start), err)
 		return err
 	}
 
+	d.recordReconcile(nil, false)
+	d.recordRun(run)
 	ui.Success("Reconciliation completed in %s", time.Since(start))
 	return nil
 }
 
-// pollLoop runs periodic reconciliation.
+// publishDone publishes the end of a run; queued means another run follows.
+func (d *Daemon) publishDone(err error, cancelled, queued bool) {
+	e := Event{Type: EventDone, Status: StatusSucceeded, Queued: queued}
+	switch {
+	case cancelled:
+		e.Status = StatusCancelled
+	case err != nil:
+		e.Status = StatusFailed
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	d.events.publish(e)
+}
+
+// pollLoop runs periodic reconciliation. A poll inside a maintenance
+// window is deferred to the end of the window.
 func (d *Daemon) pollLoop(ctx context.Context) {
 	ticker := time.NewTicker(d.config.PollInterval)
 	defer ticker.Stop()
 
+	var deferred <-chan time.Time
+	poll := func() {
+		deferred = nil
+		ui.Info("Poll triggered")
+		if err := d.TriggerReconcile(ctx, "poll"); err != nil {
+			ui.Error("Poll reconciliation failed: %v", err)
+		}
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			ui.Info("Poll triggered")
-			if err := d.TriggerReconcile(ctx, "poll"); err != nil {
-				ui.Error("Poll reconciliation failed: %v", err)
+			now := time.Now()
+			if !d.inMaintenance(now) {
+				poll()
+				continue
 			}
+			if deferred == nil {
+				if end := d.maintenanceEnd(now); !end.IsZero() {
+					ui.Info("Poll deferred until %s (maintenance window)", end.In(d.location()).Format("15:04"))
+					deferred = time.After(end.Sub(now))
+				}
+			}
+		case <-deferred:
+			poll()
 		case <-d.stopPoll:
 			return
 		case <-ctx.Done():
@@ -383,23 +668,93 @@ func (d *Daemon) HealthStatus() HealthStatus {
 		Ready:         d.IsReady(),
 		LastReconcile: lastReconcile,
 		Uptime:        time.Since(startTime),
+		Host:          d.hostMetrics(),
+		Containers:    d.watch.snapshot(),
+		StaleMounts:   d.watch.staleMounts(),
+	}
+	if w, ok := d.maintenanceWindow(time.Now()); ok {
+		status.Maintenance = w.Spec
 	}
 
 	if lastError != nil {
 		status.Status = "degraded"
 		status.LastError = lastError.Error()
 	}
+	if len(status.StaleMounts) > 0 {
+		status.Status = "degraded"
+	}
 
 	return status
 }
 
+// hostMetrics returns the host metrics from the last collection, or nil
+// before the first one.
+func (d *Daemon) hostMetrics() *hostmetrics.Metrics {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.host
+}
+
+// collectHostMetrics collects host metrics for health. It can take up to
+// hostmetrics.DiskProbeTimeout per path on a slow mount.
+func (d *Daemon) collectHostMetrics() {
+	host := hostmetrics.Collect(d.hostMetricPaths())
+	d.stateMu.Lock()
+	d.host = host
+	d.stateMu.Unlock()
+}
+
+// hostMetricsLoop collects host metrics every WatchInterval, or every
+// DefaultWatchInterval when the health watch is off, until ctx ends.
+func (d *Daemon) hostMetricsLoop(ctx context.Context) {
+	interval := d.config.WatchInterval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.collectHostMetrics()
+		select {
+		case <-ticker.C:
+		case <-d.stopPoll:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// hostMetricPaths returns the paths whose disk usage is reported in health.
+// Appdata is only included for local deploys; remote appdata lives on another host.
+func (d *Daemon) hostMetricPaths() []hostmetrics.Path {
+	paths := []hostmetrics.Path{{Label: "docker", Path: d.config.DockerRootDir}}
+	if rcfg := d.config.ReconcileConfig; rcfg != nil && rcfg.TargetHost == "" {
+		paths = append(paths, hostmetrics.Path{Label: "appdata", Path: rcfg.LocalAppdataPath})
+	}
+	return paths
+}
+
 // HealthStatus represents the daemon health.
 type HealthStatus struct {
-	Status        string        `json:"status"`
-	Ready         bool          `json:"ready"`
-	LastReconcile time.Time     `json:"last_reconcile,omitempty"`
-	LastError     string        `json:"last_error,omitempty"`
-	Uptime        time.Duration `json:"uptime"`
+	Status        string               `json:"status"`
+	Ready         bool                 `json:"ready"`
+	LastReconcile time.Time            `json:"last_reconcile,omitempty"`
+	LastError     string               `json:"last_error,omitempty"`
+	Uptime        time.Duration        `json:"uptime"`
+	Host          *hostmetrics.Metrics `json:"host,omitempty"`
+
+	// Containers is the health of every container with a healthcheck, as
+	// tracked by the health watch.
+	Containers []ContainerHealth `json:"containers,omitempty"`
+
+	// StaleMounts are the deployed bind mounts that failed the health
+	// watch's last probe, as stale or hung.
+	StaleMounts []string `json:"stale_mounts,omitempty"`
+
+	// Maintenance is the maintenance window the daemon is in, if any.
+	Maintenance string `json:"maintenance,omitempty"`
 }
 
 var startTime = time.Now()
@@ -445,6 +800,14 @@ func ConfigFromEnv() *Config {
 	if secret := os.Getenv("GITHUB_WEBHOOK_SECRET"); secret != "" {
 		cfg.WebhookSecret = secret
 	}
+	cfg.WebhookFilter = webhook.FilterFromEnv(nil, nil)
+	if clients := os.Getenv("BOSUN_WEBHOOK_CLIENTS"); clients != "" {
+		if ids, err := ParsePeerIdentities(clients); err != nil {
+			ui.Warning("Ignoring invalid BOSUN_WEBHOOK_CLIENTS: %v", err)
+		} else {
+			cfg.WebhookClients = ids
+		}
+	}
 
 	if interval := os.Getenv("POLL_INTERVAL"); interval != "" {
 		if secs, err := time.ParseDuration(interval + "s"); err == nil {
@@ -488,6 +851,163 @@ func ConfigFromEnv() *Config {
 		rcfg.InfraSubDir = infraDir
 	}
 
+	// BOSUN_PROJECTS=all reconciles every workspace project; a comma list picks some
+	if projects := os.Getenv("BOSUN_PROJECTS"); projects != "" {
+		cfg.Workspace = true
+		if projects != "all" {
+			cfg.Projects = splitAndTrim(projects)
+		}
+	}
+
+	rcfg.StateDir = reconcile.ResolveStateDir("")
+
+	ownership := os.Getenv("DEPLOY_OWNERSHIP")
+	if o := os.Getenv("BOSUN_DEPLOY_OWNERSHIP"); o != "" {
+		ownership = o
+	}
+	if ownership != "" {
+		if rules, err := reconcile.ParseOwnershipRules(ownership); err != nil {
+			ui.Warning("Ignoring invalid deploy ownership: %v", err)
+		} else {
+			rcfg.Ownership = rules
+		}
+	}
+	rcfg.Userns = os.Getenv("BOSUN_DOCKER_USERNS")
+	rcfg.OwnershipImage = os.Getenv("BOSUN_OWNERSHIP_IMAGE")
+
+	rcfg.Selective = os.Getenv("BOSUN_SELECTIVE_DEPLOY") != "false"
+	if profiles := os.Getenv("BOSUN_PROFILES"); profiles != "" {
+		if parsed, err := manifest.ParseProfiles(profiles); err != nil {
+			ui.Warning("Ignoring BOSUN_PROFILES: %v", err)
+		} else {
+			rcfg.Profiles = parsed
+		}
+	}
+	rcfg.EncryptBackups = os.Getenv("BOSUN_BACKUP_ENCRYPT") == "true"
+	rcfg.MaintenancePage = os.Getenv("BOSUN_MAINTENANCE_PAGE") == "true"
+	rcfg.MaintenanceURL = os.Getenv("BOSUN_MAINTENANCE_URL")
+	cfg.MaintenanceHTML = os.Getenv("BOSUN_MAINTENANCE_HTML")
+
+	if lintMode := os.Getenv("LINT_MODE"); lintMode != "" {
+		rcfg.LintMode = lintMode
+	}
+	if lintMode := os.Getenv("BOSUN_LINT_MODE"); lintMode != "" {
+		rcfg.LintMode = lintMode
+	}
+
+	if dockerRoot := os.Getenv("DOCKER_ROOT_DIR"); dockerRoot != "" {
+		cfg.DockerRootDir = dockerRoot
+	}
+	if dockerRoot := os.Getenv("BOSUN_DOCKER_ROOT_DIR"); dockerRoot != "" {
+		cfg.DockerRootDir = dockerRoot
+	}
+
+	if windows := os.Getenv("BOSUN_FREEZE_WINDOWS"); windows != "" {
+		if parsed, err := ParseFreezeWindows(windows); err != nil {
+			ui.Warning("Ignoring invalid freeze windows: %v", err)
+		} else {
+			cfg.FreezeWindows = parsed
+		}
+	}
+	if windows := os.Getenv("BOSUN_MAINTENANCE_WINDOWS"); windows != "" {
+		if parsed, err := ParseFreezeWindows(windows); err != nil {
+			ui.Warning("Ignoring invalid maintenance windows: %v", err)
+		} else {
+			cfg.MaintenanceWindows = parsed
+		}
+	}
+	if tz := os.Getenv(timezone.EnvVar); tz != "" {
+		if loc, err := timezone.Load(tz); err != nil {
+			ui.Warning("Ignoring %s: %v", timezone.EnvVar, err)
+		} else {
+			cfg.Timezone = loc
+		}
+	}
+	if pidFile, ok := os.LookupEnv("BOSUN_MOVER_PID_FILE"); ok {
+		cfg.MoverPIDFile = pidFile
+	}
+	if budget := os.Getenv("BOSUN_ERROR_BUDGET"); budget != "" {
+		_, _ = fmt.Sscanf(budget, "%d", &cfg.ErrorBudget)
+	}
+	if window := os.Getenv("BOSUN_ERROR_BUDGET_WINDOW"); window != "" {
+		if d, err := time.ParseDuration(window); err == nil {
+			cfg.ErrorBudgetWindow = d
+		}
+	}
+	if quiet := os.Getenv("BOSUN_QUIET_PERIOD"); quiet != "" {
+		if d, err := time.ParseDuration(quiet); err != nil || d < 0 {
+			ui.Warning("Ignoring invalid BOSUN_QUIET_PERIOD: %q", quiet)
+		} else {
+			cfg.QuietPeriod = d
+		}
+	}
+	if interval := os.Getenv("BOSUN_WATCH_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err != nil || d < 0 {
+			ui.Warning("Ignoring invalid BOSUN_WATCH_INTERVAL: %q", interval)
+		} else {
+			cfg.WatchInterval = d
+		}
+	}
+	cfg.DriftRemediate = os.Getenv("BOSUN_DRIFT_REMEDIATE") == "true"
+	if debounce := os.Getenv("BOSUN_DRIFT_DEBOUNCE"); debounce != "" {
+		if d, err := time.ParseDuration(debounce); err != nil || d < 0 {
+			ui.Warning("Ignoring invalid BOSUN_DRIFT_DEBOUNCE: %q", debounce)
+		} else {
+			cfg.DriftDebounce = d
+		}
+	}
+	if interval := os.Getenv("BOSUN_UPDATE_CHECK_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err != nil || d < 0 {
+			ui.Warning("Ignoring invalid BOSUN_UPDATE_CHECK_INTERVAL: %q", interval)
+		} else {
+			cfg.UpdateCheckInterval = d
+		}
+	}
+	if spec := os.Getenv("BOSUN_DIGEST"); spec != "" {
+		if schedule, err := ParseDigestSchedule(spec); err != nil {
+			ui.Warning("Ignoring %v", err)
+		} else {
+			cfg.Digest = schedule
+		}
+	}
+
+	rcfg.DockerRootDir = cfg.DockerRootDir
+	if minFree := os.Getenv("BOSUN_DISK_MIN_FREE"); minFree != "" {
+		if n, err := reconcile.ParseByteSize(minFree); err != nil {
+			ui.Warning("Ignoring invalid BOSUN_DISK_MIN_FREE: %v", err)
+		} else {
+			rcfg.DiskMinFree = n
+		}
+	}
+	if pct := os.Getenv("BOSUN_DISK_MIN_FREE_PERCENT"); pct != "" {
+		if n, err := strconv.ParseFloat(pct, 64); err != nil || n < 0 || n > 100 {
+			ui.Warning("Ignoring invalid BOSUN_DISK_MIN_FREE_PERCENT: %q", pct)
+		} else {
+			rcfg.DiskMinFreePercent = n
+		}
+	}
+
+	if age := os.Getenv("BOSUN_STALE_TEMP_AGE"); age != "" {
+		if d, err := time.ParseDuration(age); err != nil || d < 0 {
+			ui.Warning("Ignoring invalid BOSUN_STALE_TEMP_AGE: %q", age)
+		} else {
+			rcfg.StaleTempAge = d
+		}
+	}
+
+	rcfg.BuildCacheDir = os.Getenv("BOSUN_BUILD_CACHE")
+	rcfg.HostLabel = os.Getenv("BOSUN_HOST_LABEL")
+	rcfg.ImageDistribution = os.Getenv("BOSUN_IMAGE_DISTRIBUTION")
+	rcfg.Registry = os.Getenv("BOSUN_REGISTRY")
+
+	if spec := os.Getenv("BOSUN_CHAOS"); spec != "" {
+		if chaos, err := reconcile.ParseChaos(spec); err != nil {
+			ui.Warning("Ignoring invalid chaos spec: %v", err)
+		} else {
+			rcfg.Chaos = chaos
+		}
+	}
+
 	cfg.ReconcileConfig = rcfg
 
 	return cfg
@@ -518,6 +1038,17 @@ func ValidateConfig(cfg *Config) error {
 		if cfg.ReconcileConfig.RepoURL == "" {
 			errs = append(errs, "REPO_URL or BOSUN_REPO_URL is required")
 		}
+		if mode := cfg.ReconcileConfig.LintMode; mode != "" {
+			if err := reconcile.ValidateLintMode(mode); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if err := reconcile.ValidateImageDistribution(cfg.ReconcileConfig.ImageDistribution, cfg.ReconcileConfig.Registry); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if err := reconcile.ValidateUsernsMode(cfg.ReconcileConfig.Userns); err != nil {
+			errs = append(errs, err.Error())
+		}
 	}
 
 	if len(errs) > 0 {
@@ -10,6 +10,7 @@ import (
 )
 
 func TestDefaultConfig(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	cfg := DefaultConfig()
 
 	if cfg.SocketPath != "/var/run/bosun.sock" {
@@ -76,6 +77,39 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid lint mode",
+			cfg: &Config{
+				Port: 8080,
+				ReconcileConfig: &reconcile.Config{
+					RepoURL:  "https://github.com/example/repo",
+					LintMode: "strict",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "registry distribution without a registry",
+			cfg: &Config{
+				Port: 8080,
+				ReconcileConfig: &reconcile.Config{
+					RepoURL:           "https://github.com/example/repo",
+					ImageDistribution: "registry",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid userns mode",
+			cfg: &Config{
+				Port: 8080,
+				ReconcileConfig: &reconcile.Config{
+					RepoURL: "https://github.com/example/repo",
+					Userns:  "rootful",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,6 +245,7 @@ func TestConfigResponse_JSON(t *testing.T) {
 
 func TestConfigFromEnv(t *testing.T) {
 	t.Run("default values when no env vars", func(t *testing.T) {
+		t.Setenv("XDG_RUNTIME_DIR", "")
 		cfg := ConfigFromEnv()
 
 		if cfg.SocketPath != "/var/run/bosun.sock" {
@@ -490,3 +525,211 @@ func TestConfigFromEnv_InfraDir(t *testing.T) {
 		}
 	})
 }
+
+func TestConfigFromEnv_Projects(t *testing.T) {
+	t.Run("single repo by default", func(t *testing.T) {
+		t.Setenv("BOSUN_PROJECTS", "")
+		cfg := ConfigFromEnv()
+		if cfg.Workspace {
+			t.Error("Workspace = true, want false")
+		}
+	})
+
+	t.Run("all projects", func(t *testing.T) {
+		t.Setenv("BOSUN_PROJECTS", "all")
+		cfg := ConfigFromEnv()
+		if !cfg.Workspace {
+			t.Error("Workspace = false, want true")
+		}
+		if len(cfg.Projects) != 0 {
+			t.Errorf("Projects = %v, want empty (all)", cfg.Projects)
+		}
+	})
+
+	t.Run("selected projects", func(t *testing.T) {
+		t.Setenv("BOSUN_PROJECTS", "homelab, vps")
+		cfg := ConfigFromEnv()
+		if !cfg.Workspace {
+			t.Error("Workspace = false, want true")
+		}
+		if len(cfg.Projects) != 2 || cfg.Projects[0] != "homelab" || cfg.Projects[1] != "vps" {
+			t.Errorf("Projects = %v, want [homelab vps]", cfg.Projects)
+		}
+	})
+}
+
+func TestConfigFromEnv_DeployOwnership(t *testing.T) {
+	t.Setenv("DEPLOY_OWNERSHIP", "traefik=1000:1000")
+	t.Setenv("BOSUN_DEPLOY_OWNERSHIP", "")
+
+	t.Run("parses DEPLOY_OWNERSHIP", func(t *testing.T) {
+		cfg := ConfigFromEnv()
+		rules := cfg.ReconcileConfig.Ownership
+		if len(rules) != 1 || rules[0].Path != "traefik" || rules[0].UID != 1000 {
+			t.Errorf("Ownership = %v, want [traefik=1000:1000]", rules)
+		}
+	})
+
+	t.Run("BOSUN_DEPLOY_OWNERSHIP takes precedence", func(t *testing.T) {
+		t.Setenv("BOSUN_DEPLOY_OWNERSHIP", "gatus=568:568:0640")
+		cfg := ConfigFromEnv()
+		rules := cfg.ReconcileConfig.Ownership
+		if len(rules) != 1 || rules[0].String() != "gatus=568:568:0640" {
+			t.Errorf("Ownership = %v, want [gatus=568:568:0640]", rules)
+		}
+	})
+
+	t.Run("ignores invalid value", func(t *testing.T) {
+		t.Setenv("BOSUN_DEPLOY_OWNERSHIP", "../etc=0:0")
+		cfg := ConfigFromEnv()
+		if len(cfg.ReconcileConfig.Ownership) != 0 {
+			t.Errorf("Ownership = %v, want none", cfg.ReconcileConfig.Ownership)
+		}
+	})
+}
+
+func TestDaemon_HostMetricPaths(t *testing.T) {
+	t.Run("local deploy includes appdata", func(t *testing.T) {
+		rcfg := reconcile.DefaultConfig()
+		d := &Daemon{config: &Config{DockerRootDir: "/var/lib/docker", ReconcileConfig: rcfg}}
+
+		paths := d.hostMetricPaths()
+		if len(paths) != 2 {
+			t.Fatalf("hostMetricPaths() = %v, want docker and appdata", paths)
+		}
+		if paths[0].Label != "docker" || paths[0].Path != "/var/lib/docker" {
+			t.Errorf("paths[0] = %v, want docker=/var/lib/docker", paths[0])
+		}
+		if paths[1].Label != "appdata" || paths[1].Path != rcfg.LocalAppdataPath {
+			t.Errorf("paths[1] = %v, want appdata=%s", paths[1], rcfg.LocalAppdataPath)
+		}
+	})
+
+	t.Run("remote deploy omits appdata", func(t *testing.T) {
+		rcfg := reconcile.DefaultConfig()
+		rcfg.TargetHost = "root@tower"
+		d := &Daemon{config: &Config{DockerRootDir: "/var/lib/docker", ReconcileConfig: rcfg}}
+
+		if paths := d.hostMetricPaths(); len(paths) != 1 {
+			t.Errorf("hostMetricPaths() = %v, want docker only", paths)
+		}
+	})
+}
+
+func TestDaemon_HostMetricsCached(t *testing.T) {
+	d := &Daemon{config: &Config{DockerRootDir: t.TempDir()}}
+
+	if got := d.HealthStatus().Host; got != nil {
+		t.Errorf("Host before collection = %+v, want nil", got)
+	}
+
+	d.collectHostMetrics()
+	first := d.HealthStatus().Host
+	if first == nil || len(first.Disks) != 1 {
+		t.Fatalf("Host after collection = %+v, want the docker disk", first)
+	}
+	if got := d.HealthStatus().Host; got != first {
+		t.Error("HealthStatus() collected host metrics again instead of serving the cached copy")
+	}
+}
+
+func TestConfigFromEnv_DockerRootDir(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+	t.Setenv("DOCKER_ROOT_DIR", "")
+	t.Setenv("BOSUN_DOCKER_ROOT_DIR", "")
+
+	if cfg := ConfigFromEnv(); cfg.DockerRootDir != "/var/lib/docker" {
+		t.Errorf("DockerRootDir = %q, want default /var/lib/docker", cfg.DockerRootDir)
+	}
+
+	t.Setenv("DOCKER_ROOT_DIR", "/mnt/cache/docker")
+	if cfg := ConfigFromEnv(); cfg.DockerRootDir != "/mnt/cache/docker" {
+		t.Errorf("DockerRootDir = %q, want /mnt/cache/docker", cfg.DockerRootDir)
+	}
+
+	t.Setenv("BOSUN_DOCKER_ROOT_DIR", "/mnt/docker")
+	if cfg := ConfigFromEnv(); cfg.DockerRootDir != "/mnt/docker" {
+		t.Errorf("DockerRootDir = %q, want /mnt/docker", cfg.DockerRootDir)
+	}
+}
+
+func TestConfigFromEnv_Chaos(t *testing.T) {
+	t.Setenv("BOSUN_CHAOS", "")
+	if cfg := ConfigFromEnv(); cfg.ReconcileConfig.Chaos != nil {
+		t.Errorf("Chaos = %v, want nil by default", cfg.ReconcileConfig.Chaos)
+	}
+
+	t.Setenv("BOSUN_CHAOS", "health-gate=0.5")
+	cfg := ConfigFromEnv()
+	if got := cfg.ReconcileConfig.Chaos.String(); got != "health-gate=0.5" {
+		t.Errorf("Chaos = %q, want health-gate=0.5", got)
+	}
+
+	t.Setenv("BOSUN_CHAOS", "sometimes")
+	if cfg := ConfigFromEnv(); cfg.ReconcileConfig.Chaos != nil {
+		t.Errorf("Chaos = %v, want nil for an invalid spec", cfg.ReconcileConfig.Chaos)
+	}
+}
+
+func TestConfigFromEnv_DiskThresholds(t *testing.T) {
+	t.Setenv("BOSUN_DISK_MIN_FREE", "")
+	t.Setenv("BOSUN_DISK_MIN_FREE_PERCENT", "")
+	cfg := ConfigFromEnv()
+	if cfg.ReconcileConfig.DiskMinFree != reconcile.DefaultDiskMinFree {
+		t.Errorf("DiskMinFree = %d, want default %d", cfg.ReconcileConfig.DiskMinFree, reconcile.DefaultDiskMinFree)
+	}
+
+	t.Setenv("BOSUN_DISK_MIN_FREE", "2G")
+	t.Setenv("BOSUN_DISK_MIN_FREE_PERCENT", "10")
+	cfg = ConfigFromEnv()
+	if cfg.ReconcileConfig.DiskMinFree != 2<<30 {
+		t.Errorf("DiskMinFree = %d, want %d", cfg.ReconcileConfig.DiskMinFree, uint64(2<<30))
+	}
+	if cfg.ReconcileConfig.DiskMinFreePercent != 10 {
+		t.Errorf("DiskMinFreePercent = %v, want 10", cfg.ReconcileConfig.DiskMinFreePercent)
+	}
+
+	t.Setenv("BOSUN_DISK_MIN_FREE", "lots")
+	t.Setenv("BOSUN_DISK_MIN_FREE_PERCENT", "150")
+	cfg = ConfigFromEnv()
+	if cfg.ReconcileConfig.DiskMinFree != reconcile.DefaultDiskMinFree {
+		t.Errorf("DiskMinFree = %d, want default for an invalid size", cfg.ReconcileConfig.DiskMinFree)
+	}
+	if cfg.ReconcileConfig.DiskMinFreePercent != reconcile.DefaultDiskMinFreePercent {
+		t.Errorf("DiskMinFreePercent = %v, want default for an invalid percentage", cfg.ReconcileConfig.DiskMinFreePercent)
+	}
+}
+
+func TestConfigFromEnv_StaleTempAge(t *testing.T) {
+	t.Setenv("BOSUN_STALE_TEMP_AGE", "")
+	if cfg := ConfigFromEnv(); cfg.ReconcileConfig.StaleTempAge != reconcile.DefaultStaleTempAge {
+		t.Errorf("StaleTempAge = %v, want default %v", cfg.ReconcileConfig.StaleTempAge, reconcile.DefaultStaleTempAge)
+	}
+
+	t.Setenv("BOSUN_STALE_TEMP_AGE", "0")
+	if cfg := ConfigFromEnv(); cfg.ReconcileConfig.StaleTempAge != 0 {
+		t.Errorf("StaleTempAge = %v, want 0 to disable", cfg.ReconcileConfig.StaleTempAge)
+	}
+
+	t.Setenv("BOSUN_STALE_TEMP_AGE", "soon")
+	if cfg := ConfigFromEnv(); cfg.ReconcileConfig.StaleTempAge != reconcile.DefaultStaleTempAge {
+		t.Errorf("StaleTempAge = %v, want default for an invalid duration", cfg.ReconcileConfig.StaleTempAge)
+	}
+}
+
+func TestConfigFromEnv_WatchInterval(t *testing.T) {
+	t.Setenv("BOSUN_WATCH_INTERVAL", "")
+	if cfg := ConfigFromEnv(); cfg.WatchInterval != DefaultWatchInterval {
+		t.Errorf("WatchInterval = %v, want default %v", cfg.WatchInterval, DefaultWatchInterval)
+	}
+
+	t.Setenv("BOSUN_WATCH_INTERVAL", "0")
+	if cfg := ConfigFromEnv(); cfg.WatchInterval != 0 {
+		t.Errorf("WatchInterval = %v, want 0 to disable", cfg.WatchInterval)
+	}
+
+	t.Setenv("BOSUN_WATCH_INTERVAL", "often")
+	if cfg := ConfigFromEnv(); cfg.WatchInterval != DefaultWatchInterval {
+		t.Errorf("WatchInterval = %v, want default for an invalid duration", cfg.WatchInterval)
+	}
+}
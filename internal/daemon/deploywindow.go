@@ -0,0 +1,228 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default error budget: deploys are unsafe after this many failed
+// reconciles within the window.
+const (
+	DefaultErrorBudget       = 3
+	DefaultErrorBudgetWindow = 24 * time.Hour
+)
+
+// FreezeWindow is a recurring period during which deploys are unsafe,
+// e.g. "Fri 17:00-23:59", "Sat-Sun", or "Mon-Fri 22:00-06:00". A time
+// range that ends before it starts runs past midnight into the next day.
+type FreezeWindow struct {
+	Days  [7]bool       // Indexed by time.Weekday
+	Start time.Duration // Offset from midnight
+	End   time.Duration // Offset from midnight; equal to Start means all day
+	Spec  string        // Original text, for display
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseFreezeWindows parses a comma-separated list of freeze windows.
+func ParseFreezeWindows(s string) ([]FreezeWindow, error) {
+	var windows []FreezeWindow
+	for _, spec := range splitAndTrim(s) {
+		w, err := parseFreezeWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// parseFreezeWindow parses "[days] [HH:MM-HH:MM]"; at least one part is required.
+func parseFreezeWindow(spec string) (FreezeWindow, error) {
+	w := FreezeWindow{Spec: spec}
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("invalid freeze window %q: want \"[days] [HH:MM-HH:MM]\"", spec)
+	}
+
+	daysSet := false
+	for _, field := range fields {
+		if strings.Contains(field, ":") {
+			start, end, ok := strings.Cut(field, "-")
+			if !ok {
+				return w, fmt.Errorf("invalid freeze window %q: time range must be HH:MM-HH:MM", spec)
+			}
+			var err error
+			if w.Start, err = parseClock(start); err != nil {
+				return w, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+			}
+			if w.End, err = parseClock(end); err != nil {
+				return w, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+			}
+			continue
+		}
+
+		first, last, isRange := strings.Cut(strings.ToLower(field), "-")
+		from, ok := weekdays[first]
+		if !ok {
+			return w, fmt.Errorf("invalid freeze window %q: unknown day %q", spec, first)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[last]; !ok {
+				return w, fmt.Errorf("invalid freeze window %q: unknown day %q", spec, last)
+			}
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == to {
+				break
+			}
+		}
+		daysSet = true
+	}
+
+	if !daysSet {
+		for d := range w.Days {
+			w.Days[d] = true
+		}
+	}
+	return w, nil
+}
+
+// parseClock parses HH:MM into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window, in t's location.
+func (w FreezeWindow) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	switch {
+	case w.Start == w.End:
+		return w.Days[t.Weekday()]
+	case w.Start < w.End:
+		return w.Days[t.Weekday()] && offset >= w.Start && offset < w.End
+	default:
+		// Wraps past midnight: the late part belongs to today, the early part to yesterday.
+		yesterday := (t.Weekday() + 6) % 7
+		return (w.Days[t.Weekday()] && offset >= w.Start) || (w.Days[yesterday] && offset < w.End)
+	}
+}
+
+// DeployCheck is one condition of the deploy window.
+type DeployCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// DeployWindowResponse is the response body for /deploy-window.
+type DeployWindowResponse struct {
+	Safe   bool          `json:"safe"`
+	Checks []DeployCheck `json:"checks"`
+}
+
+// Reasons returns the details of the checks that failed.
+func (r *DeployWindowResponse) Reasons() []string {
+	var reasons []string
+	for _, c := range r.Checks {
+		if !c.OK {
+			reasons = append(reasons, c.Detail)
+		}
+	}
+	return reasons
+}
+
+// DeployWindow reports whether it is safe to deploy at now: no Unraid mover
+// running, outside every freeze window (in Config.Timezone), no reconcile in
+// flight, and fewer failed reconciles than the error budget allows.
+func (d *Daemon) DeployWindow(now time.Time) *DeployWindowResponse {
+	resp := &DeployWindowResponse{Safe: true}
+	add := func(c DeployCheck) {
+		resp.Checks = append(resp.Checks, c)
+		if !c.OK {
+			resp.Safe = false
+		}
+	}
+
+	mover := DeployCheck{Name: "mover", OK: true}
+	if path := d.config.MoverPIDFile; path != "" {
+		if _, err := os.Stat(path); err == nil {
+			mover.OK = false
+			mover.Detail = "Unraid mover is running"
+		}
+	}
+	add(mover)
+
+	freeze := DeployCheck{Name: "freeze", OK: true}
+	local := now
+	if d.config.Timezone != nil {
+		local = now.In(d.config.Timezone)
+	}
+	for _, w := range d.config.FreezeWindows {
+		if w.Contains(local) {
+			freeze.OK = false
+			freeze.Detail = "inside freeze window " + w.Spec
+			break
+		}
+	}
+	add(freeze)
+
+	d.reconcileMu.Lock()
+	reconciling := d.reconciling
+	d.reconcileMu.Unlock()
+	inflight := DeployCheck{Name: "reconcile", OK: !reconciling}
+	if reconciling {
+		inflight.Detail = "a reconcile is in progress"
+	}
+	add(inflight)
+
+	failures := d.recentFailures(now)
+	budget := DeployCheck{Name: "error_budget", OK: true}
+	if d.config.ErrorBudget > 0 {
+		budget.Detail = fmt.Sprintf("%d of %d failed reconciles in the last %s", failures, d.config.ErrorBudget, d.config.ErrorBudgetWindow)
+		if failures >= d.config.ErrorBudget {
+			budget.OK = false
+			budget.Detail = "error budget exhausted: " + budget.Detail
+		}
+	}
+	add(budget)
+
+	return resp
+}
+
+// recordFailure notes a failed reconcile for the error budget, dropping
+// failures older than the budget window.
+func (d *Daemon) recordFailure(at time.Time) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.failures = append(pruneFailures(d.failures, at, d.config.ErrorBudgetWindow), at)
+}
+
+// recentFailures counts failed reconciles within the budget window.
+func (d *Daemon) recentFailures(now time.Time) int {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return len(pruneFailures(d.failures, now, d.config.ErrorBudgetWindow))
+}
+
+// pruneFailures returns the failures within window of now.
+func pruneFailures(failures []time.Time, now time.Time, window time.Duration) []time.Time {
+	cutoff := now.Add(-window)
+	for i, t := range failures {
+		if t.After(cutoff) {
+			return failures[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFreezeWindows(t *testing.T) {
+	// 2026-01-02 is a Friday.
+	at := func(day int, clock string) time.Time {
+		tm, _ := time.Parse("15:04", clock)
+		return time.Date(2026, 1, day, tm.Hour(), tm.Minute(), 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		spec string
+		in   []time.Time
+		out  []time.Time
+	}{
+		{"Fri 17:00-23:59", []time.Time{at(2, "17:00"), at(2, "23:30")}, []time.Time{at(2, "16:59"), at(3, "18:00")}},
+		{"Sat-Sun", []time.Time{at(3, "00:00"), at(4, "23:59")}, []time.Time{at(2, "23:59"), at(5, "00:00")}},
+		{"Mon-Fri 22:00-06:00", []time.Time{at(2, "23:00"), at(3, "05:59")}, []time.Time{at(3, "23:00"), at(4, "05:00"), at(2, "06:00")}},
+		{"12:00-13:00", []time.Time{at(4, "12:30")}, []time.Time{at(4, "13:00")}},
+		{"Fri-Mon", []time.Time{at(4, "10:00"), at(5, "10:00")}, []time.Time{at(6, "10:00")}},
+	}
+	for _, tt := range tests {
+		windows, err := ParseFreezeWindows(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseFreezeWindows(%q) error = %v", tt.spec, err)
+		}
+		if len(windows) != 1 {
+			t.Fatalf("ParseFreezeWindows(%q) = %d windows, want 1", tt.spec, len(windows))
+		}
+		for _, tm := range tt.in {
+			if !windows[0].Contains(tm) {
+				t.Errorf("%q should contain %s", tt.spec, tm.Format("Mon 15:04"))
+			}
+		}
+		for _, tm := range tt.out {
+			if windows[0].Contains(tm) {
+				t.Errorf("%q should not contain %s", tt.spec, tm.Format("Mon 15:04"))
+			}
+		}
+	}
+
+	for _, bad := range []string{"Funday", "Fri 25:00-26:00", "Fri 17:00", "Fri 17:00-18:00 extra"} {
+		if _, err := ParseFreezeWindows(bad); err == nil {
+			t.Errorf("ParseFreezeWindows(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDaemon_DeployWindow(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	newDaemon := func() *Daemon {
+		cfg := DefaultConfig()
+		cfg.MoverPIDFile = filepath.Join(t.TempDir(), "mover.pid")
+		return &Daemon{config: cfg}
+	}
+
+	t.Run("safe by default", func(t *testing.T) {
+		resp := newDaemon().DeployWindow(now)
+		if !resp.Safe {
+			t.Errorf("DeployWindow() unsafe: %v", resp.Reasons())
+		}
+		if len(resp.Checks) != 4 {
+			t.Errorf("Checks = %d, want 4", len(resp.Checks))
+		}
+	})
+
+	t.Run("mover running", func(t *testing.T) {
+		d := newDaemon()
+		if err := os.WriteFile(d.config.MoverPIDFile, []byte("123"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if resp := d.DeployWindow(now); resp.Safe {
+			t.Error("DeployWindow() should be unsafe while the mover runs")
+		}
+	})
+
+	t.Run("freeze window", func(t *testing.T) {
+		d := newDaemon()
+		d.config.FreezeWindows, _ = ParseFreezeWindows("Fri")
+		resp := d.DeployWindow(now)
+		if resp.Safe {
+			t.Error("DeployWindow() should be unsafe inside a freeze window")
+		}
+		if reasons := resp.Reasons(); len(reasons) != 1 || reasons[0] != "inside freeze window Fri" {
+			t.Errorf("Reasons() = %v", reasons)
+		}
+	})
+
+	t.Run("freeze window in the configured timezone", func(t *testing.T) {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		if err != nil {
+			t.Skip("tzdata not available")
+		}
+		d := newDaemon()
+		d.config.FreezeWindows, _ = ParseFreezeWindows("Fri")
+		d.config.Timezone = tokyo
+		lateFriday := time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC) // Saturday 05:00 in Tokyo
+		if resp := d.DeployWindow(lateFriday); !resp.Safe {
+			t.Errorf("DeployWindow() unsafe outside the window in Tokyo: %v", resp.Reasons())
+		}
+	})
+
+	t.Run("reconcile in flight", func(t *testing.T) {
+		d := newDaemon()
+		d.reconciling = true
+		if resp := d.DeployWindow(now); resp.Safe {
+			t.Error("DeployWindow() should be unsafe during a reconcile")
+		}
+	})
+
+	t.Run("error budget", func(t *testing.T) {
+		d := newDaemon()
+		d.recordFailure(now.Add(-48 * time.Hour)) // outside the window
+		d.recordFailure(now.Add(-2 * time.Hour))
+		d.recordFailure(now.Add(-time.Hour))
+		if resp := d.DeployWindow(now); !resp.Safe {
+			t.Errorf("DeployWindow() with 2 recent failures unsafe: %v", resp.Reasons())
+		}
+
+		d.recordFailure(now.Add(-time.Minute))
+		if resp := d.DeployWindow(now); resp.Safe {
+			t.Error("DeployWindow() should be unsafe once the error budget is spent")
+		}
+		if got := d.recentFailures(now.Add(25 * time.Hour)); got != 0 {
+			t.Errorf("recentFailures() a day later = %d, want 0", got)
+		}
+	})
+}
+
+func TestServeDeployWindow(t *testing.T) {
+	d := &Daemon{config: DefaultConfig()}
+	d.config.MoverPIDFile = ""
+	d.reconciling = true
+
+	rec := httptest.NewRecorder()
+	serveDeployWindow(d, rec, httptest.NewRequest(http.MethodGet, "/deploy-window", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 when unsafe", rec.Code)
+	}
+
+	d.reconciling = false
+	rec = httptest.NewRecorder()
+	serveDeployWindow(d, rec, httptest.NewRequest(http.MethodGet, "/deploy-window", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 when safe", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	serveDeployWindow(d, rec, httptest.NewRequest(http.MethodPost, "/deploy-window", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405 for POST", rec.Code)
+	}
+}
+
+func TestConfigFromEnv_DeployWindow(t *testing.T) {
+	t.Setenv("BOSUN_FREEZE_WINDOWS", "Sat-Sun, Fri 17:00-23:59")
+	t.Setenv("BOSUN_ERROR_BUDGET", "5")
+	t.Setenv("BOSUN_ERROR_BUDGET_WINDOW", "6h")
+
+	cfg := ConfigFromEnv()
+	if len(cfg.FreezeWindows) != 2 {
+		t.Errorf("FreezeWindows = %d, want 2", len(cfg.FreezeWindows))
+	}
+	if cfg.ErrorBudget != 5 {
+		t.Errorf("ErrorBudget = %d, want 5", cfg.ErrorBudget)
+	}
+	if cfg.ErrorBudgetWindow != 6*time.Hour {
+		t.Errorf("ErrorBudgetWindow = %s, want 6h", cfg.ErrorBudgetWindow)
+	}
+	if cfg.MoverPIDFile != "/var/run/mover.pid" {
+		t.Errorf("MoverPIDFile = %q, want default", cfg.MoverPIDFile)
+	}
+}
@@ -0,0 +1,149 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/alert"
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DigestPeriod is the span of activity a digest reports.
+const DigestPeriod = 7 * 24 * time.Hour
+
+// DigestSchedule is the weekly time the digest is sent, e.g. "Mon 09:00".
+type DigestSchedule struct {
+	Day  time.Weekday
+	At   time.Duration // Offset from midnight
+	Spec string        // Original text, for display
+}
+
+// ParseDigestSchedule parses "<day> HH:MM", e.g. "Mon 09:00".
+func ParseDigestSchedule(spec string) (*DigestSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid digest schedule %q: want \"<day> HH:MM\"", spec)
+	}
+	day, ok := weekdays[strings.ToLower(fields[0])]
+	if !ok {
+		return nil, fmt.Errorf("invalid digest schedule %q: unknown day %q", spec, fields[0])
+	}
+	at, err := parseClock(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest schedule %q: %w", spec, err)
+	}
+	return &DigestSchedule{Day: day, At: at, Spec: spec}, nil
+}
+
+// Next returns the first scheduled time after t, in t's location.
+func (s *DigestSchedule) Next(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	days := (int(s.Day) - int(t.Weekday()) + 7) % 7
+	next := midnight.AddDate(0, 0, days).Add(s.At)
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// digestLoop sends the weekly digest on schedule until ctx ends.
+func (d *Daemon) digestLoop(ctx context.Context) {
+	for {
+		next := d.config.Digest.Next(time.Now().In(d.location()))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			if stop := d.allStop(time.Now()); stop != nil {
+				ui.Info("Skipping weekly digest: all-stop by %s", stop.By)
+				continue
+			}
+			if err := d.sendDigest(ctx, time.Now()); err != nil {
+				ui.Warning("Weekly digest failed: %v", err)
+			}
+		case <-d.stopPoll:
+			timer.Stop()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// location returns the zone schedules are evaluated in.
+func (d *Daemon) location() *time.Location {
+	if d.config.Timezone != nil {
+		return d.config.Timezone
+	}
+	return time.Local
+}
+
+// sendDigest sends a digest of the activity over the DigestPeriod before
+// now through the alert providers.
+func (d *Daemon) sendDigest(ctx context.Context, now time.Time) error {
+	if d.alerter == nil || !d.alerter.HasProviders() {
+		ui.Info("Skipping weekly digest: no alert providers configured")
+		return nil
+	}
+	dir := d.stateDir()
+	if dir == "" {
+		return fmt.Errorf("no state directory")
+	}
+	st, err := state.NewStore(dir).Load()
+	if err != nil {
+		return err
+	}
+
+	target := "local"
+	if d.config.ReconcileConfig.TargetHost != "" {
+		target = d.config.ReconcileConfig.TargetHost
+	}
+	digest := st.Digest(now, DigestPeriod)
+	return d.alerter.Send(ctx, alert.DigestAlert(target, "the last 7 days", digest.Lines()))
+}
+
+// stateDir returns the reconciler's state directory, or "" when unset.
+func (d *Daemon) stateDir() string {
+	if d.config == nil || d.config.ReconcileConfig == nil {
+		return ""
+	}
+	return d.config.ReconcileConfig.StateDir
+}
+
+// recordStats applies fn to today's digest counters in the state store.
+func (d *Daemon) recordStats(fn func(day *state.DayStats)) {
+	dir := d.stateDir()
+	if dir == "" {
+		return
+	}
+	err := state.NewStore(dir).Update(func(st *state.State) error {
+		fn(st.Day(time.Now()))
+		return nil
+	})
+	if err != nil {
+		ui.Warning("Failed to record digest counters: %v", err)
+	}
+}
+
+// recordReconcile counts a finished reconcile, and samples Docker root disk
+// usage for the digest's trend. Cancelled runs don't count as failures.
+func (d *Daemon) recordReconcile(err error, cancelled bool) {
+	if d.stateDir() == "" {
+		return
+	}
+	var diskPercent float64
+	if disk, err := hostmetrics.ProbeDisk(hostmetrics.Path{Label: "docker", Path: d.config.DockerRootDir}); err == nil {
+		diskPercent = disk.UsedPercent()
+	}
+	d.recordStats(func(day *state.DayStats) {
+		day.Reconciles++
+		if err != nil && !cancelled {
+			day.Failures++
+		}
+		day.DiskPercent = max(day.DiskPercent, diskPercent)
+	})
+}
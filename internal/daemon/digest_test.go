@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestParseDigestSchedule(t *testing.T) {
+	schedule, err := ParseDigestSchedule("Mon 09:30")
+	if err != nil {
+		t.Fatalf("ParseDigestSchedule() error = %v", err)
+	}
+	if schedule.Day != time.Monday || schedule.At != 9*time.Hour+30*time.Minute {
+		t.Errorf("ParseDigestSchedule() = %s %s, want Monday 9h30m", schedule.Day, schedule.At)
+	}
+
+	for _, spec := range []string{"Mon", "Funday 09:00", "Mon 25:00", "Mon 09:00 UTC"} {
+		if _, err := ParseDigestSchedule(spec); err == nil {
+			t.Errorf("ParseDigestSchedule(%q) should fail", spec)
+		}
+	}
+}
+
+func TestDigestSchedule_Next(t *testing.T) {
+	schedule, _ := ParseDigestSchedule("Mon 09:00")
+	// 2026-01-05 is a Monday.
+	monday9 := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"earlier in the week", time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC), monday9},
+		{"same day before", time.Date(2026, 1, 5, 8, 59, 0, 0, time.UTC), monday9},
+		{"exactly at", monday9, monday9.AddDate(0, 0, 7)},
+		{"same day after", time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC), monday9.AddDate(0, 0, 7)},
+	}
+	for _, tt := range tests {
+		if got := schedule.Next(tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%s) = %s, want %s", tt.name, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestDaemon_RecordReconcile(t *testing.T) {
+	dir := t.TempDir()
+	d := &Daemon{config: &Config{
+		ReconcileConfig: &reconcile.Config{StateDir: dir},
+		DockerRootDir:   dir,
+	}}
+
+	boom := errors.New("boom")
+	d.recordReconcile(nil, false)
+	d.recordReconcile(boom, false)
+	d.recordReconcile(boom, true) // Cancelled: not a failure
+
+	st, err := state.NewStore(dir).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	day := st.Day(time.Now())
+	if day.Reconciles != 3 || day.Failures != 1 {
+		t.Errorf("day = %d reconciles, %d failures, want 3 and 1", day.Reconciles, day.Failures)
+	}
+	if day.DiskPercent <= 0 {
+		t.Errorf("DiskPercent = %v, want a sample", day.DiskPercent)
+	}
+}
+
+func TestConfigFromEnv_Digest(t *testing.T) {
+	t.Setenv("BOSUN_DIGEST", "Fri 17:00")
+	if cfg := ConfigFromEnv(); cfg.Digest == nil || cfg.Digest.Day != time.Friday {
+		t.Errorf("Digest = %+v, want Friday", cfg.Digest)
+	}
+
+	t.Setenv("BOSUN_DIGEST", "someday")
+	if cfg := ConfigFromEnv(); cfg.Digest != nil {
+		t.Errorf("Digest = %+v, want nil for an invalid schedule", cfg.Digest)
+	}
+}
@@ -0,0 +1,343 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DefaultDriftDebounce is how long a stack must stay drifted before drift
+// remediation redeploys it.
+const DefaultDriftDebounce = 5 * time.Minute
+
+// driftWatch tracks drifted stacks across health watch polls. It is only
+// used from the watch goroutine.
+type driftWatch struct {
+	since map[string]time.Time // Stack -> when its current drift was first seen
+	// remediated holds the findings each stack was last redeployed for.
+	// The same drift coming back means compose up doesn't fix it, so it is
+	// alerted on once instead of redeployed again.
+	remediated map[string]string
+	gaveUp     map[string]bool
+	hashes     map[string]cachedHashes // Compose file -> expected config hashes
+	// undeployed holds the stacks noted as never deployed, so each is
+	// logged once rather than on every poll.
+	undeployed map[string]bool
+}
+
+// cachedHashes are the config hashes of a compose file as of its
+// modification time.
+type cachedHashes struct {
+	modTime time.Time
+	hashes  map[string]string
+}
+
+// newDriftWatch creates an empty drift watch.
+func newDriftWatch() *driftWatch {
+	return &driftWatch{
+		since:      make(map[string]time.Time),
+		remediated: make(map[string]string),
+		gaveUp:     make(map[string]bool),
+		hashes:     make(map[string]cachedHashes),
+		undeployed: make(map[string]bool),
+	}
+}
+
+// forget drops what the watch knows about a stack that is back in sync.
+func (w *driftWatch) forget(stack string) {
+	delete(w.since, stack)
+	delete(w.remediated, stack)
+	delete(w.gaveUp, stack)
+}
+
+// driftCompose is the part of a deployed compose file drift checks read.
+type driftCompose struct {
+	Services map[string]struct {
+		ContainerName string   `yaml:"container_name"`
+		Profiles      []string `yaml:"profiles"`
+	} `yaml:"services"`
+}
+
+// composeDrift compares the running containers with a deployed compose
+// file and returns one finding per drifted service, sorted. A service's
+// container is found by container_name, or by the compose project and
+// service labels. expected maps services to the config hash compose gives
+// them now; services missing from it are only checked for running.
+// Profiled services are checked only when one of their profiles is enabled.
+func composeDrift(file string, running []docker.ContainerInfo, expected map[string]string, profiles []string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+	var compose driftCompose
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	project := reconcile.ComposeProjectName(file)
+	byName := make(map[string]docker.ContainerInfo, len(running))
+	byService := make(map[string]docker.ContainerInfo)
+	for _, ctr := range running {
+		byName[ctr.Name] = ctr
+		if ctr.Labels[docker.ProjectLabel] == project {
+			byService[ctr.Labels[docker.ServiceLabel]] = ctr
+		}
+	}
+
+	var findings []string
+	for service, svc := range compose.Services {
+		// Profiled services only start when asked for
+		if len(svc.Profiles) > 0 && !profileEnabled(svc.Profiles, profiles) {
+			continue
+		}
+		ctr, ok := byService[service]
+		if svc.ContainerName != "" {
+			ctr, ok = byName[svc.ContainerName]
+		}
+		switch {
+		case !ok:
+			findings = append(findings, service+": not running")
+		case expected[service] != "" && ctr.Labels[docker.ConfigHashLabel] != "" && expected[service] != ctr.Labels[docker.ConfigHashLabel]:
+			findings = append(findings, service+": config changed")
+		}
+	}
+	sort.Strings(findings)
+	return findings, nil
+}
+
+// profileEnabled reports whether any of a service's profiles is enabled.
+func profileEnabled(service, enabled []string) bool {
+	for _, p := range service {
+		if slices.Contains(enabled, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// deployedComposeDir returns where the reconciler deploys compose files, or
+// "" when drift can't be checked from here: remote deploys run their
+// containers on another host, and workspaces deploy several projects.
+func (d *Daemon) deployedComposeDir() string {
+	rcfg := d.config.ReconcileConfig
+	if d.config.Workspace || rcfg == nil || rcfg.TargetHost != "" || rcfg.LocalAppdataPath == "" {
+		return ""
+	}
+	return filepath.Join(rcfg.LocalAppdataPath, "compose")
+}
+
+// expectedHashes returns the config hashes compose gives the services of a
+// compose file, recomputed only when the file changes. It returns nil when
+// compose can't compute them.
+func (d *Daemon) expectedHashes(ctx context.Context, file string) map[string]string {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil
+	}
+	if cached, ok := d.drift.hashes[file]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.hashes
+	}
+	hashes, err := d.configHashes(ctx, file)
+	if err != nil {
+		ui.Warning("Drift check: %v", err)
+		return nil
+	}
+	d.drift.hashes[file] = cachedHashes{modTime: info.ModTime(), hashes: hashes}
+	return hashes
+}
+
+// checkDrift compares the running containers with the deployed stacks and
+// redeploys the stacks that have stayed drifted for DriftDebounce. Stacks
+// the state file says were never deployed aren't drift and are skipped.
+func (d *Daemon) checkDrift(ctx context.Context, running []docker.ContainerInfo, now time.Time) {
+	composeDir := d.deployedComposeDir()
+	if composeDir == "" {
+		return
+	}
+	files, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	st := d.loadState()
+
+	due := make(map[string][]string) // Compose file -> findings
+	present := make(map[string]bool)
+	for _, file := range files {
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		present[stack] = true
+		if st.NeverDeployed(stack) {
+			if !d.drift.undeployed[stack] {
+				d.drift.undeployed[stack] = true
+				ui.Info("Stack %s has never been deployed; not checking it for drift", stack)
+			}
+			d.drift.forget(stack)
+			continue
+		}
+		delete(d.drift.undeployed, stack)
+
+		findings, err := composeDrift(file, running, d.expectedHashes(ctx, file), d.config.ReconcileConfig.Profiles)
+		if err != nil {
+			ui.Warning("Drift check: %v", err)
+			continue
+		}
+		if len(findings) == 0 {
+			d.drift.forget(stack)
+			continue
+		}
+
+		first, ok := d.drift.since[stack]
+		if !ok {
+			d.drift.since[stack] = now
+			ui.Warning("Stack %s drifted: %s", stack, strings.Join(findings, ", "))
+			d.recordStats(func(day *state.DayStats) { day.DriftEvents++ })
+			continue
+		}
+		if now.Sub(first) < d.config.DriftDebounce {
+			continue
+		}
+		if d.drift.remediated[stack] == strings.Join(findings, "\n") {
+			d.giveUpDrift(ctx, stack, findings)
+			continue
+		}
+		due[file] = findings
+	}
+
+	for stack := range d.drift.since {
+		if !present[stack] {
+			d.drift.forget(stack)
+		}
+	}
+	if len(due) > 0 {
+		d.remediateDrift(ctx, due, now)
+	}
+}
+
+// giveUpDrift alerts, once, on drift that remediation already failed to fix.
+func (d *Daemon) giveUpDrift(ctx context.Context, stack string, findings []string) {
+	if d.drift.gaveUp[stack] {
+		return
+	}
+	d.drift.gaveUp[stack] = true
+	ui.Warning("Stack %s is still drifted after remediation; not redeploying it again: %s", stack, strings.Join(findings, ", "))
+	d.sendDriftAlert(ctx, stack, findings, "still drifted after redeploy")
+}
+
+// remediateDrift redeploys drifted stacks with compose up and records each
+// remediation in the state log. It runs as a reconcile would, so triggers
+// that arrive meanwhile queue behind it; it is skipped while a reconcile
+// runs (which redeploys anyway) or an all-stop is in force.
+func (d *Daemon) remediateDrift(ctx context.Context, due map[string][]string, now time.Time) {
+	if stop := d.allStop(now); stop != nil {
+		ui.Warning("All-stop by %s; not remediating drift", stop.By)
+		return
+	}
+
+	d.reconcileMu.Lock()
+	if d.reconciling {
+		d.reconcileMu.Unlock()
+		return
+	}
+	d.reconciling = true
+	d.reconcileMu.Unlock()
+	defer d.releaseAfterRemediation()
+
+	files := make([]string, 0, len(due))
+	for file := range due {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		findings := due[file]
+		ui.Info("Remediating drift in %s: %s", stack, strings.Join(findings, ", "))
+
+		r := state.Remediation{At: now, Stack: stack, Findings: findings}
+		if err := d.composeUp(ctx, file); err != nil {
+			r.Error = err.Error()
+			ui.Error("Drift remediation of %s failed: %v", stack, err)
+			d.sendDriftAlert(ctx, stack, findings, "redeploy failed: "+err.Error())
+		} else {
+			ui.Success("Redeployed drifted stack %s", stack)
+		}
+		d.recordRemediation(r)
+
+		// Give the redeploy a full debounce window before judging it
+		d.drift.since[stack] = now
+		d.drift.remediated[stack] = strings.Join(findings, "\n")
+	}
+}
+
+// releaseAfterRemediation ends a remediation, running a trigger that queued
+// behind it.
+func (d *Daemon) releaseAfterRemediation() {
+	d.reconcileMu.Lock()
+	if !d.pendingTrigger {
+		d.reconciling = false
+		d.reconcileMu.Unlock()
+		return
+	}
+	source := d.triggerSource
+	d.pendingTrigger = false
+	d.triggerSource = ""
+	d.reconcileMu.Unlock()
+
+	ui.Info("Processing queued trigger from %s", source)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if err := d.reconcileLoop(ctx, source); err != nil {
+			ui.Error("Queued reconciliation failed: %v", err)
+		}
+	}()
+}
+
+// loadState loads the state store, or returns an empty state when there
+// is none or it can't be read.
+func (d *Daemon) loadState() *state.State {
+	if dir := d.stateDir(); dir != "" {
+		if st, err := state.NewStore(dir).Load(); err == nil {
+			return st
+		}
+	}
+	return &state.State{}
+}
+
+// recordRemediation appends a remediation to the state log.
+func (d *Daemon) recordRemediation(r state.Remediation) {
+	dir := d.stateDir()
+	if dir == "" {
+		return
+	}
+	err := state.NewStore(dir).Update(func(st *state.State) error {
+		st.RecordRemediation(r)
+		return nil
+	})
+	if err != nil {
+		ui.Warning("Failed to record drift remediation: %v", err)
+	}
+}
+
+// sendDriftAlert alerts on drift remediation couldn't fix.
+func (d *Daemon) sendDriftAlert(ctx context.Context, stack string, findings []string, outcome string) {
+	if d.alerter == nil || !d.alerter.HasProviders() {
+		return
+	}
+	lines := make([]string, 0, len(findings)+1)
+	for _, f := range findings {
+		lines = append(lines, stack+"/"+f)
+	}
+	lines = append(lines, stack+": "+outcome)
+	if err := d.alerter.SendDrift(ctx, "local", lines); err != nil {
+		ui.Warning("Failed to send drift alert: %v", err)
+	}
+}
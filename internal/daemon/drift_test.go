@@ -0,0 +1,251 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+const driftTestCompose = `services:
+  sonarr:
+    image: linuxserver/sonarr
+    container_name: sonarr
+  radarr:
+    image: linuxserver/radarr
+  debug:
+    image: busybox
+    profiles: [debug]
+`
+
+func TestComposeDrift(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "media.yml")
+	if err := os.WriteFile(file, []byte(driftTestCompose), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project := reconcile.ComposeProjectName(file)
+	radarr := docker.ContainerInfo{Name: "media-radarr-1", Labels: map[string]string{
+		docker.ProjectLabel:    project,
+		docker.ServiceLabel:    "radarr",
+		docker.ConfigHashLabel: "aaa",
+	}}
+	sonarr := docker.ContainerInfo{Name: "sonarr", Labels: map[string]string{docker.ConfigHashLabel: "bbb"}}
+
+	tests := []struct {
+		name     string
+		running  []docker.ContainerInfo
+		expected map[string]string
+		want     []string
+	}{
+		{"in sync", []docker.ContainerInfo{radarr, sonarr}, map[string]string{"radarr": "aaa", "sonarr": "bbb"}, nil},
+		{"no hashes", []docker.ContainerInfo{radarr, sonarr}, nil, nil},
+		{"stopped", []docker.ContainerInfo{sonarr}, nil, []string{"radarr: not running"}},
+		{"changed", []docker.ContainerInfo{radarr, sonarr}, map[string]string{"radarr": "aaa", "sonarr": "ccc"}, []string{"sonarr: config changed"}},
+		{"other project", []docker.ContainerInfo{{Name: "radarr", Labels: map[string]string{docker.ServiceLabel: "radarr"}}}, nil, []string{"radarr: not running", "sonarr: not running"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := composeDrift(file, tt.running, tt.expected, nil)
+			if err != nil {
+				t.Fatalf("composeDrift() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("composeDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got, err := composeDrift(file, []docker.ContainerInfo{radarr, sonarr}, nil, []string{"debug"})
+	if err != nil {
+		t.Fatalf("composeDrift() error = %v", err)
+	}
+	if want := []string{"debug: not running"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("composeDrift() with the debug profile = %v, want %v", got, want)
+	}
+
+	if _, err := composeDrift(filepath.Join(t.TempDir(), "missing.yml"), nil, nil, nil); err == nil {
+		t.Error("composeDrift() of a missing file succeeded, want an error")
+	}
+}
+
+func TestConfigFromEnv_Profiles(t *testing.T) {
+	t.Setenv("BOSUN_PROFILES", "media, debug")
+	if got, want := ConfigFromEnv().ReconcileConfig.Profiles, []string{"media", "debug"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Profiles = %v, want %v", got, want)
+	}
+
+	t.Setenv("BOSUN_PROFILES", "media,not a profile")
+	if got := ConfigFromEnv().ReconcileConfig.Profiles; got != nil {
+		t.Errorf("Profiles = %v, want nil for an invalid list", got)
+	}
+}
+
+// newDriftDaemon returns a daemon with a deployed media stack whose
+// remediations are counted instead of run.
+func newDriftDaemon(t *testing.T, upErr error) (*Daemon, *int) {
+	t.Helper()
+	appdata := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appdata, "compose"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(appdata, "compose", "media.yml"), []byte(driftTestCompose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ups := 0
+	d := &Daemon{
+		config: &Config{
+			DriftRemediate: true,
+			DriftDebounce:  5 * time.Minute,
+			ReconcileConfig: &reconcile.Config{
+				LocalAppdataPath: appdata,
+				StateDir:         t.TempDir(),
+			},
+		},
+		drift: newDriftWatch(),
+		configHashes: func(ctx context.Context, composeFile string) (map[string]string, error) {
+			return nil, nil
+		},
+		composeUp: func(ctx context.Context, composeFile string) error {
+			ups++
+			return upErr
+		},
+	}
+	return d, &ups
+}
+
+func TestDaemon_CheckDrift_Remediates(t *testing.T) {
+	d, ups := newDriftDaemon(t, nil)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	running := []docker.ContainerInfo{{Name: "sonarr"}}
+
+	// Drift is first noted, then waits out the debounce
+	d.checkDrift(ctx, running, now)
+	d.checkDrift(ctx, running, now.Add(4*time.Minute))
+	if *ups != 0 {
+		t.Fatalf("compose up ran %d times within the debounce, want 0", *ups)
+	}
+
+	d.checkDrift(ctx, running, now.Add(5*time.Minute))
+	if *ups != 1 {
+		t.Fatalf("compose up ran %d times after the debounce, want 1", *ups)
+	}
+	if d.reconciling {
+		t.Error("reconciling still set after remediation")
+	}
+
+	st, err := state.NewStore(d.stateDir()).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(st.Remediations) != 1 {
+		t.Fatalf("Remediations = %+v, want 1", st.Remediations)
+	}
+	r := st.Remediations[0]
+	if r.Stack != "media" || !r.OK() || !reflect.DeepEqual(r.Findings, []string{"radarr: not running"}) {
+		t.Errorf("Remediation = %+v, want a successful media redeploy for radarr", r)
+	}
+	if got := st.Day(now).DriftEvents; got != 1 {
+		t.Errorf("DriftEvents = %d, want 1", got)
+	}
+
+	// The same drift after the redeploy is not redeployed again
+	d.checkDrift(ctx, running, now.Add(11*time.Minute))
+	if *ups != 1 {
+		t.Errorf("compose up ran %d times for drift it didn't fix, want 1", *ups)
+	}
+	if !d.drift.gaveUp["media"] {
+		t.Error("stack not given up on after remediation didn't fix it")
+	}
+
+	// Back in sync, the stack is forgotten
+	running = append(running, docker.ContainerInfo{Name: "media-radarr-1", Labels: map[string]string{
+		docker.ProjectLabel: reconcile.ComposeProjectName(filepath.Join(d.deployedComposeDir(), "media.yml")),
+		docker.ServiceLabel: "radarr",
+	}})
+	d.checkDrift(ctx, running, now.Add(12*time.Minute))
+	if _, ok := d.drift.since["media"]; ok || d.drift.gaveUp["media"] {
+		t.Error("in-sync stack still tracked as drifted")
+	}
+}
+
+func TestDaemon_CheckDrift_RecordsFailure(t *testing.T) {
+	d, ups := newDriftDaemon(t, errors.New("pull access denied"))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	d.checkDrift(ctx, nil, now)
+	d.checkDrift(ctx, nil, now.Add(5*time.Minute))
+	if *ups != 1 {
+		t.Fatalf("compose up ran %d times, want 1", *ups)
+	}
+
+	st, err := state.NewStore(d.stateDir()).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(st.Remediations) != 1 || st.Remediations[0].Error != "pull access denied" {
+		t.Errorf("Remediations = %+v, want one failed remediation", st.Remediations)
+	}
+}
+
+func TestDaemon_CheckDrift_SkipsWhileReconciling(t *testing.T) {
+	d, ups := newDriftDaemon(t, nil)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	d.reconciling = true
+
+	d.checkDrift(ctx, nil, now)
+	d.checkDrift(ctx, nil, now.Add(5*time.Minute))
+	if *ups != 0 {
+		t.Errorf("compose up ran %d times during a reconcile, want 0", *ups)
+	}
+	if !d.reconciling {
+		t.Error("remediation cleared a running reconcile's flag")
+	}
+}
+
+func TestDaemon_CheckDrift_SkipsNeverDeployed(t *testing.T) {
+	d, ups := newDriftDaemon(t, nil)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	err := state.NewStore(d.stateDir()).Update(func(st *state.State) error {
+		st.RecordApplied("core", "aaa", "abc1234", now)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	d.checkDrift(ctx, nil, now)
+	d.checkDrift(ctx, nil, now.Add(5*time.Minute))
+	if *ups != 0 {
+		t.Errorf("compose up ran %d times for a never-deployed stack, want 0", *ups)
+	}
+	if _, ok := d.drift.since["media"]; ok {
+		t.Error("never-deployed stack tracked as drifted")
+	}
+	if !d.drift.undeployed["media"] {
+		t.Error("never-deployed stack not noted")
+	}
+}
+
+func TestDaemon_DeployedComposeDir(t *testing.T) {
+	d := &Daemon{config: &Config{ReconcileConfig: &reconcile.Config{LocalAppdataPath: "/mnt/appdata"}}}
+	if got := d.deployedComposeDir(); got != "/mnt/appdata/compose" {
+		t.Errorf("deployedComposeDir() = %q, want /mnt/appdata/compose", got)
+	}
+
+	d.config.ReconcileConfig.TargetHost = "tower.local"
+	if got := d.deployedComposeDir(); got != "" {
+		t.Errorf("deployedComposeDir() for a remote deploy = %q, want empty", got)
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxHistory is how many reconcile runs the daemon remembers.
+const maxHistory = 100
+
+// defaultHistoryLimit is how many runs /history returns without ?limit.
+const defaultHistoryLimit = 20
+
+// ReconcileRun records one reconcile run for /history.
+type ReconcileRun struct {
+	Source     string    `json:"source"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	FromCommit string    `json:"from_commit,omitempty"` // Commit before the sync
+	ToCommit   string    `json:"to_commit,omitempty"`   // Commit the run reconciled
+	Outcome    string    `json:"outcome"`               // succeeded, failed, cancelled
+	Error      string    `json:"error,omitempty"`
+}
+
+// Duration returns how long the run took.
+func (r ReconcileRun) Duration() time.Duration {
+	return time.Duration(r.DurationMS) * time.Millisecond
+}
+
+// HistoryResponse is the response body for /history.
+type HistoryResponse struct {
+	Runs []ReconcileRun `json:"runs"` // Newest first
+}
+
+// recordRun adds a finished run to the history, dropping the oldest runs
+// beyond maxHistory.
+func (d *Daemon) recordRun(run ReconcileRun) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.history = append(d.history, run)
+	if extra := len(d.history) - maxHistory; extra > 0 {
+		d.history = append([]ReconcileRun(nil), d.history[extra:]...)
+	}
+}
+
+// History returns up to limit of the most recent runs since the daemon
+// started, newest first.
+func (d *Daemon) History(limit int) []ReconcileRun {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	n := min(limit, len(d.history))
+	runs := make([]ReconcileRun, 0, n)
+	for i := len(d.history) - 1; i >= len(d.history)-n; i-- {
+		runs = append(runs, d.history[i])
+	}
+	return runs
+}
+
+// serveHistory handles GET /history?limit=N.
+func serveHistory(d *Daemon, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(HistoryResponse{Runs: d.History(limit)})
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDaemon_History(t *testing.T) {
+	d := &Daemon{config: DefaultConfig()}
+	if runs := d.History(10); len(runs) != 0 {
+		t.Fatalf("History() = %d runs, want 0", len(runs))
+	}
+
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	for i := range maxHistory + 5 {
+		d.recordRun(ReconcileRun{Source: "poll", StartedAt: start.Add(time.Duration(i) * time.Minute), Outcome: StatusSucceeded})
+	}
+
+	runs := d.History(3)
+	if len(runs) != 3 {
+		t.Fatalf("History(3) = %d runs, want 3", len(runs))
+	}
+	if want := start.Add((maxHistory + 4) * time.Minute); !runs[0].StartedAt.Equal(want) {
+		t.Errorf("History()[0].StartedAt = %s, want newest %s", runs[0].StartedAt, want)
+	}
+	if !runs[1].StartedAt.Before(runs[0].StartedAt) {
+		t.Error("History() should be newest first")
+	}
+
+	if all := d.History(1000); len(all) != maxHistory {
+		t.Errorf("History(1000) = %d runs, want %d", len(all), maxHistory)
+	}
+}
+
+func TestServeHistory(t *testing.T) {
+	d := &Daemon{config: DefaultConfig()}
+	d.recordRun(ReconcileRun{Source: "webhook", FromCommit: "abc1234", ToCommit: "def5678", DurationMS: 1500, Outcome: StatusSucceeded})
+	d.recordRun(ReconcileRun{Source: "poll", Outcome: StatusFailed, Error: "failed to sync repository"})
+
+	t.Run("returns runs newest first", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveHistory(d, w, httptest.NewRequest(http.MethodGet, "/history", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+		var resp HistoryResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Runs) != 2 || resp.Runs[0].Outcome != StatusFailed {
+			t.Fatalf("Runs = %+v, want the failed run first", resp.Runs)
+		}
+		if resp.Runs[1].Duration() != 1500*time.Millisecond {
+			t.Errorf("Duration() = %s, want 1.5s", resp.Runs[1].Duration())
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveHistory(d, w, httptest.NewRequest(http.MethodGet, "/history?limit=1", nil))
+		var resp HistoryResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Runs) != 1 {
+			t.Errorf("Runs = %d, want 1", len(resp.Runs))
+		}
+	})
+
+	t.Run("bad limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveHistory(d, w, httptest.NewRequest(http.MethodGet, "/history?limit=0", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveHistory(d, w, httptest.NewRequest(http.MethodPost, "/history", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want 405", w.Code)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// maintenanceRetryAfter is the Retry-After hint, in seconds, sent with the
+// maintenance page.
+const maintenanceRetryAfter = "30"
+
+// defaultMaintenancePage is served when no custom page is configured.
+const defaultMaintenancePage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="30">
+<title>Down for maintenance</title>
+<style>
+body { font-family: system-ui, sans-serif; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; background: #0f172a; color: #e2e8f0; }
+main { text-align: center; padding: 2rem; }
+h1 { font-size: 1.75rem; margin-bottom: 0.5rem; }
+p { color: #94a3b8; }
+</style>
+</head>
+<body>
+<main>
+<h1>&#9875; Down for maintenance</h1>
+<p>An update is being rolled out. This page will refresh when we're back.</p>
+</main>
+</body>
+</html>
+`
+
+// handleMaintenance serves the maintenance page that Traefik routes every
+// request to while a deploy has it enabled. It answers 503 so clients and
+// crawlers treat the outage as temporary.
+func (s *Server) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	page := []byte(defaultMaintenancePage)
+	if path := s.daemon.config.MaintenanceHTML; path != "" {
+		custom, err := os.ReadFile(path)
+		if err != nil {
+			ui.Warning("Could not read maintenance page %s: %v", path, err)
+		} else {
+			page = custom
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Retry-After", maintenanceRetryAfter)
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(page)
+	}
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleMaintenance(t *testing.T) {
+	s := &Server{daemon: &Daemon{config: DefaultConfig()}}
+
+	rec := httptest.NewRecorder()
+	s.handleMaintenance(rec, httptest.NewRequest(http.MethodPost, "/maintenance", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != maintenanceRetryAfter {
+		t.Errorf("Retry-After = %q, want %q", got, maintenanceRetryAfter)
+	}
+	if !strings.Contains(rec.Body.String(), "Down for maintenance") {
+		t.Error("body should be the built-in maintenance page")
+	}
+
+	custom := filepath.Join(t.TempDir(), "maintenance.html")
+	if err := os.WriteFile(custom, []byte("<h1>Back soon</h1>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.daemon.config.MaintenanceHTML = custom
+
+	rec = httptest.NewRecorder()
+	s.handleMaintenance(rec, httptest.NewRequest(http.MethodGet, "/maintenance", nil))
+	if got := rec.Body.String(); got != "<h1>Back soon</h1>" {
+		t.Errorf("body = %q, want the custom page", got)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleMaintenance(rec, httptest.NewRequest(http.MethodHead, "/maintenance", nil))
+	if rec.Body.Len() != 0 {
+		t.Errorf("HEAD body = %q, want empty", rec.Body.String())
+	}
+}
+
+func TestConfigFromEnv_Maintenance(t *testing.T) {
+	t.Setenv("BOSUN_MAINTENANCE_PAGE", "true")
+	t.Setenv("BOSUN_MAINTENANCE_URL", "http://pages:80")
+	t.Setenv("BOSUN_MAINTENANCE_HTML", "/config/maintenance.html")
+
+	cfg := ConfigFromEnv()
+	if !cfg.ReconcileConfig.MaintenancePage {
+		t.Error("MaintenancePage should be set")
+	}
+	if cfg.ReconcileConfig.MaintenanceURL != "http://pages:80" {
+		t.Errorf("MaintenanceURL = %q, want http://pages:80", cfg.ReconcileConfig.MaintenanceURL)
+	}
+	if cfg.MaintenanceHTML != "/config/maintenance.html" {
+		t.Errorf("MaintenanceHTML = %q, want /config/maintenance.html", cfg.MaintenanceHTML)
+	}
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// maintenanceWindow returns the maintenance window now falls in, in
+// Config.Timezone, if any.
+func (d *Daemon) maintenanceWindow(now time.Time) (FreezeWindow, bool) {
+	if d.config == nil {
+		return FreezeWindow{}, false
+	}
+	local := now.In(d.location())
+	for _, w := range d.config.MaintenanceWindows {
+		if w.Contains(local) {
+			return w, true
+		}
+	}
+	return FreezeWindow{}, false
+}
+
+// maintenanceEnd returns the first minute after now outside every
+// maintenance window, or the zero time when the windows cover the whole
+// week.
+func (d *Daemon) maintenanceEnd(now time.Time) time.Time {
+	t := now.Truncate(time.Minute).Add(time.Minute)
+	for limit := t.Add(8 * 24 * time.Hour); t.Before(limit); t = t.Add(time.Minute) {
+		if _, ok := d.maintenanceWindow(t); !ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// inMaintenance reports whether now falls in a maintenance window, logging
+// when the daemon enters or leaves one.
+func (d *Daemon) inMaintenance(now time.Time) bool {
+	w, ok := d.maintenanceWindow(now)
+
+	d.stateMu.Lock()
+	was := d.maintenance
+	if ok {
+		d.maintenance = w.Spec
+	} else {
+		d.maintenance = ""
+	}
+	d.stateMu.Unlock()
+
+	switch {
+	case ok && was != w.Spec:
+		ui.Info("Maintenance window %s: drift checks and alerts paused, poll reconciles deferred", w.Spec)
+	case !ok && was != "":
+		ui.Info("Maintenance window %s ended", was)
+	}
+	return ok
+}
@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDaemon_MaintenanceWindow(t *testing.T) {
+	windows, err := ParseFreezeWindows("03:30-05:00, Sun 23:00-01:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := time.FixedZone("test", -5*60*60)
+	d := &Daemon{config: &Config{MaintenanceWindows: windows, Timezone: loc}}
+
+	tests := []struct {
+		now     time.Time
+		in      bool
+		wantEnd time.Time
+	}{
+		{time.Date(2026, 10, 14, 4, 10, 0, 0, loc), true, time.Date(2026, 10, 14, 5, 0, 0, 0, loc)},
+		{time.Date(2026, 10, 14, 5, 0, 0, 0, loc), false, time.Time{}},
+		{time.Date(2026, 10, 18, 23, 30, 0, 0, loc), true, time.Date(2026, 10, 19, 1, 0, 0, 0, loc)},     // Sunday, past midnight
+		{time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC), true, time.Date(2026, 10, 14, 5, 0, 0, 0, loc)}, // 04:30 in the daemon's zone
+	}
+	for _, tt := range tests {
+		_, in := d.maintenanceWindow(tt.now)
+		if in != tt.in {
+			t.Errorf("maintenanceWindow(%s) = %v, want %v", tt.now, in, tt.in)
+		}
+		if !tt.in {
+			continue
+		}
+		if end := d.maintenanceEnd(tt.now); !end.Equal(tt.wantEnd) {
+			t.Errorf("maintenanceEnd(%s) = %s, want %s", tt.now, end, tt.wantEnd)
+		}
+	}
+
+	always, _ := ParseFreezeWindows("Mon-Sun")
+	d.config.MaintenanceWindows = always
+	if end := d.maintenanceEnd(time.Now()); !end.IsZero() {
+		t.Errorf("maintenanceEnd() = %s, want zero for a window that never ends", end)
+	}
+}
+
+func TestDaemon_PollHealth_PausedForMaintenance(t *testing.T) {
+	always, _ := ParseFreezeWindows("Mon-Sun")
+	d := &Daemon{
+		config: &Config{MaintenanceWindows: always},
+		drift:  newDriftWatch(),
+		watch:  newHealthWatch(),
+	}
+	d.drift.since["media"] = time.Now()
+
+	// No Docker client is set: a poll inside the window must not need one.
+	d.pollHealth(context.Background())
+	if len(d.drift.since) != 0 {
+		t.Errorf("drift.since = %v, want drift forgotten in a maintenance window", d.drift.since)
+	}
+	if got := d.HealthStatus().Maintenance; got != "Mon-Sun" {
+		t.Errorf("HealthStatus().Maintenance = %q, want Mon-Sun", got)
+	}
+}
+
+func TestConfigFromEnv_MaintenanceWindows(t *testing.T) {
+	t.Setenv("BOSUN_MAINTENANCE_WINDOWS", "03:30-05:00, Sat 02:00-04:00")
+	if cfg := ConfigFromEnv(); len(cfg.MaintenanceWindows) != 2 {
+		t.Errorf("MaintenanceWindows = %d, want 2", len(cfg.MaintenanceWindows))
+	}
+
+	t.Setenv("BOSUN_MAINTENANCE_WINDOWS", "sometimes")
+	if cfg := ConfigFromEnv(); cfg.MaintenanceWindows != nil {
+		t.Errorf("MaintenanceWindows = %v, want nil for an invalid spec", cfg.MaintenanceWindows)
+	}
+}
@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiOps are the operation labels used for socket and TCP API requests.
+// Anything else is counted as "unknown" to keep metric cardinality bounded.
+var apiOps = map[string]bool{
+	"trigger":       true,
+	"status":        true,
+	"health":        true,
+	"config":        true,
+	"deploy-window": true,
+	"cancel":        true,
+	"history":       true,
+}
+
+// requestKey identifies one counter series.
+type requestKey struct {
+	server  string // socket, tcp
+	op      string // An apiOps label, or unknown
+	outcome string // ok, denied, client_error, server_error
+}
+
+// requestStats accumulates requests for one series.
+type requestStats struct {
+	count    uint64
+	duration time.Duration
+}
+
+// RequestMetrics counts daemon API requests by server, operation, and outcome.
+// A nil *RequestMetrics discards observations.
+type RequestMetrics struct {
+	mu       sync.Mutex
+	requests map[requestKey]*requestStats
+}
+
+// NewRequestMetrics creates an empty request counter set.
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{requests: make(map[requestKey]*requestStats)}
+}
+
+// Observe records one request.
+func (m *RequestMetrics) Observe(server, op, outcome string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := requestKey{server: server, op: op, outcome: outcome}
+	stats, ok := m.requests[key]
+	if !ok {
+		stats = &requestStats{}
+		m.requests[key] = stats
+	}
+	stats.count++
+	stats.duration += duration
+}
+
+// Count returns the number of requests observed for a series.
+func (m *RequestMetrics) Count(server, op, outcome string) uint64 {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stats, ok := m.requests[requestKey{server: server, op: op, outcome: outcome}]; ok {
+		return stats.count
+	}
+	return 0
+}
+
+// WritePrometheus writes the counters in Prometheus text format.
+func (m *RequestMetrics) WritePrometheus(w io.Writer) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	keys := make([]requestKey, 0, len(m.requests))
+	snapshot := make(map[requestKey]requestStats, len(m.requests))
+	for k, v := range m.requests {
+		keys = append(keys, k)
+		snapshot[k] = *v
+	}
+	m.mu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.server != b.server {
+			return a.server < b.server
+		}
+		if a.op != b.op {
+			return a.op < b.op
+		}
+		return a.outcome < b.outcome
+	})
+
+	fmt.Fprintf(w, "# HELP bosun_api_requests_total Daemon API requests by server, operation, and outcome\n")
+	fmt.Fprintf(w, "# TYPE bosun_api_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(w, "bosun_api_requests_total{server=%q,op=%q,outcome=%q} %d\n",
+			k.server, k.op, k.outcome, snapshot[k].count)
+	}
+
+	fmt.Fprintf(w, "# HELP bosun_api_request_duration_seconds Daemon API request duration\n")
+	fmt.Fprintf(w, "# TYPE bosun_api_request_duration_seconds summary\n")
+	for _, k := range keys {
+		labels := fmt.Sprintf("server=%q,op=%q,outcome=%q", k.server, k.op, k.outcome)
+		fmt.Fprintf(w, "bosun_api_request_duration_seconds_sum{%s} %f\n", labels, snapshot[k].duration.Seconds())
+		fmt.Fprintf(w, "bosun_api_request_duration_seconds_count{%s} %d\n", labels, snapshot[k].count)
+	}
+}
+
+// requestOp maps a request path to its operation label.
+func requestOp(path string) string {
+	op := strings.Trim(path, "/")
+	if apiOps[op] {
+		return op
+	}
+	return "unknown"
+}
+
+// requestOutcome classifies an HTTP status code.
+func requestOutcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "denied"
+	case status >= 500:
+		return "server_error"
+	case status >= 400:
+		return "client_error"
+	default:
+		return "ok"
+	}
+}
+
+// observeRequest logs a completed API request and records it in metrics.
+func observeRequest(metrics *RequestMetrics, server string, r *http.Request, source string, status int, duration time.Duration) {
+	op := requestOp(r.URL.Path)
+	outcome := requestOutcome(status)
+	metrics.Observe(server, op, outcome, duration)
+
+	level := slog.LevelInfo
+	if outcome != "ok" {
+		level = slog.LevelWarn
+	}
+	slog.Log(r.Context(), level, "API request",
+		slog.String("server", server),
+		slog.String("op", op),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("source", source),
+		slog.Int("status", status),
+		slog.String("outcome", outcome),
+		slog.Duration("duration", duration))
+}
@@ -0,0 +1,139 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestOutcome(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusOK, "ok"},
+		{http.StatusAccepted, "ok"},
+		{http.StatusBadRequest, "client_error"},
+		{http.StatusMethodNotAllowed, "client_error"},
+		{http.StatusUnauthorized, "denied"},
+		{http.StatusForbidden, "denied"},
+		{http.StatusServiceUnavailable, "server_error"},
+	}
+
+	for _, tt := range tests {
+		if got := requestOutcome(tt.status); got != tt.want {
+			t.Errorf("requestOutcome(%d) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRequestOp(t *testing.T) {
+	tests := map[string]string{
+		"/trigger":      "trigger",
+		"/status":       "status",
+		"/health":       "health",
+		"/config":       "config",
+		"/history":      "history",
+		"/":             "unknown",
+		"/../etc/shell": "unknown",
+	}
+
+	for path, want := range tests {
+		if got := requestOp(path); got != want {
+			t.Errorf("requestOp(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRequestOp_CoversRoutes(t *testing.T) {
+	servers := map[string]map[string]http.HandlerFunc{
+		"socket": (&SocketServer{}).routes(),
+		"tcp":    (&TCPServer{}).routes(),
+	}
+	for server, routes := range servers {
+		for path := range routes {
+			if got := requestOp(path); got == "unknown" {
+				t.Errorf("%s route %s has no operation label in apiOps", server, path)
+			}
+		}
+	}
+}
+
+func TestRequestMetrics_WritePrometheus(t *testing.T) {
+	m := NewRequestMetrics()
+	m.Observe("socket", "trigger", "ok", 100*time.Millisecond)
+	m.Observe("socket", "trigger", "ok", 300*time.Millisecond)
+	m.Observe("tcp", "status", "denied", time.Millisecond)
+
+	var b strings.Builder
+	m.WritePrometheus(&b)
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE bosun_api_requests_total counter",
+		`bosun_api_requests_total{server="socket",op="trigger",outcome="ok"} 2`,
+		`bosun_api_requests_total{server="tcp",op="status",outcome="denied"} 1`,
+		`bosun_api_request_duration_seconds_sum{server="socket",op="trigger",outcome="ok"} 0.400000`,
+		`bosun_api_request_duration_seconds_count{server="socket",op="trigger",outcome="ok"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if strings.Index(out, `server="socket"`) > strings.Index(out, `server="tcp"`) {
+		t.Errorf("series should be sorted by server:\n%s", out)
+	}
+}
+
+func TestRequestMetrics_Nil(t *testing.T) {
+	var m *RequestMetrics
+	m.Observe("socket", "trigger", "ok", time.Second)
+
+	var b strings.Builder
+	m.WritePrometheus(&b)
+	if b.Len() != 0 {
+		t.Errorf("nil metrics wrote %q", b.String())
+	}
+	if got := m.Count("socket", "trigger", "ok"); got != 0 {
+		t.Errorf("Count() = %d, want 0", got)
+	}
+}
+
+func TestTCPServer_auditMiddleware_CountsDenied(t *testing.T) {
+	server := &TCPServer{bearerToken: "token", requests: NewRequestMetrics()}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := server.auditMiddleware(server.authMiddleware(ok))
+
+	for _, auth := range []string{"Bearer token", "Bearer wrong", ""} {
+		req := httptest.NewRequest("GET", "/status", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := server.requests.Count("tcp", "status", "ok"); got != 1 {
+		t.Errorf("ok count = %d, want 1", got)
+	}
+	if got := server.requests.Count("tcp", "status", "denied"); got != 2 {
+		t.Errorf("denied count = %d, want 2", got)
+	}
+}
+
+func TestSocketServer_auditMiddleware(t *testing.T) {
+	server := &SocketServer{requests: NewRequestMetrics()}
+	handler := server.auditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/health", nil))
+
+	if got := server.requests.Count("socket", "health", "client_error"); got != 1 {
+		t.Errorf("client_error count = %d, want 1", got)
+	}
+}
@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PeerCred is the kernel-reported identity of a socket client (SO_PEERCRED).
+type PeerCred struct {
+	UID uint32
+	GID uint32
+	PID int32
+}
+
+// String formats the credentials for audit logs.
+func (p PeerCred) String() string {
+	return fmt.Sprintf("uid=%d,gid=%d,pid=%d", p.UID, p.GID, p.PID)
+}
+
+// PeerIdentity matches socket clients by user or group ID.
+type PeerIdentity struct {
+	Group bool // Match GID instead of UID
+	ID    uint32
+}
+
+// String formats the identity as uid=N or gid=N.
+func (i PeerIdentity) String() string {
+	if i.Group {
+		return fmt.Sprintf("gid=%d", i.ID)
+	}
+	return fmt.Sprintf("uid=%d", i.ID)
+}
+
+// Matches reports whether p has the identity's UID or GID.
+func (i PeerIdentity) Matches(p PeerCred) bool {
+	if i.Group {
+		return p.GID == i.ID
+	}
+	return p.UID == i.ID
+}
+
+// ParsePeerIdentities parses a comma-separated list of identities such as
+// "uid=1000,gid=1001". A bare number is a UID.
+func ParsePeerIdentities(s string) ([]PeerIdentity, error) {
+	var ids []PeerIdentity
+	for _, part := range splitAndTrim(s) {
+		kind, value, found := strings.Cut(part, "=")
+		if !found {
+			kind, value = "uid", part
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity %q: %s is not a numeric ID", part, value)
+		}
+		switch kind {
+		case "uid":
+			ids = append(ids, PeerIdentity{ID: uint32(n)})
+		case "gid":
+			ids = append(ids, PeerIdentity{Group: true, ID: uint32(n)})
+		default:
+			return nil, fmt.Errorf("invalid identity %q: want uid=N or gid=N", part)
+		}
+	}
+	return ids, nil
+}
+
+// defaultWebhookClients are allowed to fetch the webhook secret when none are
+// configured: root and the daemon's own user.
+func defaultWebhookClients() []PeerIdentity {
+	ids := []PeerIdentity{{ID: 0}}
+	if uid := os.Geteuid(); uid > 0 {
+		ids = append(ids, PeerIdentity{ID: uint32(uid)})
+	}
+	return ids
+}
+
+// peerCred returns the credentials of the socket client that sent r. ok is
+// false when the platform does not report them (SO_PEERCRED is Linux-only).
+func peerCred(r *http.Request) (cred PeerCred, ok bool) {
+	cred, ok = r.Context().Value(peerCredKey).(PeerCred)
+	return cred, ok
+}
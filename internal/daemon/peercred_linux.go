@@ -4,7 +4,6 @@ package daemon
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"net/http"
 
@@ -28,7 +27,7 @@ func (l *peerCredListener) Accept() (net.Conn, error) {
 
 	// Get peer credentials using SO_PEERCRED
 	if unixConn, ok := conn.(*net.UnixConn); ok {
-		if cred := getPeerCredentials(unixConn); cred != "" {
+		if cred, ok := getPeerCredentials(unixConn); ok {
 			return &peerCredConn{Conn: conn, peerCred: cred}, nil
 		}
 	}
@@ -38,14 +37,14 @@ func (l *peerCredListener) Accept() (net.Conn, error) {
 
 type peerCredConn struct {
 	net.Conn
-	peerCred string
+	peerCred PeerCred
 }
 
 // getPeerCredentials extracts UID/GID/PID from a Unix socket connection.
-func getPeerCredentials(conn *net.UnixConn) string {
+func getPeerCredentials(conn *net.UnixConn) (PeerCred, bool) {
 	raw, err := conn.SyscallConn()
 	if err != nil {
-		return ""
+		return PeerCred{}, false
 	}
 
 	var cred *unix.Ucred
@@ -56,10 +55,10 @@ func getPeerCredentials(conn *net.UnixConn) string {
 	})
 
 	if err != nil || credErr != nil || cred == nil {
-		return ""
+		return PeerCred{}, false
 	}
 
-	return fmt.Sprintf("uid=%d,gid=%d,pid=%d", cred.Uid, cred.Gid, cred.Pid)
+	return PeerCred{UID: cred.Uid, GID: cred.Gid, PID: cred.Pid}, true
 }
 
 // InjectPeerCred is a ConnContext function for http.Server that injects peer credentials.
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePeerIdentities(t *testing.T) {
+	ids, err := ParsePeerIdentities("uid=1000, gid=1001, 33")
+	if err != nil {
+		t.Fatalf("ParsePeerIdentities() error = %v", err)
+	}
+	want := []PeerIdentity{{ID: 1000}, {Group: true, ID: 1001}, {ID: 33}}
+	if len(ids) != len(want) {
+		t.Fatalf("ParsePeerIdentities() = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("ids[%d] = %v, want %v", i, ids[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"user=1000", "uid=bosun", "gid=-1"} {
+		if _, err := ParsePeerIdentities(bad); err == nil {
+			t.Errorf("ParsePeerIdentities(%q) expected error", bad)
+		}
+	}
+}
+
+func TestPeerIdentity_Matches(t *testing.T) {
+	cred := PeerCred{UID: 1000, GID: 1001, PID: 42}
+
+	if !(PeerIdentity{ID: 1000}).Matches(cred) {
+		t.Error("uid=1000 should match")
+	}
+	if !(PeerIdentity{Group: true, ID: 1001}).Matches(cred) {
+		t.Error("gid=1001 should match")
+	}
+	if (PeerIdentity{ID: 1001}).Matches(cred) {
+		t.Error("uid=1001 should not match")
+	}
+	if got := cred.String(); got != "uid=1000,gid=1001,pid=42" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestHandleConfig_ScopesWebhookSecret(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.WebhookSecret = "s3cret"
+	cfg.WebhookClients = []PeerIdentity{{ID: 1000}}
+	s := &SocketServer{daemon: &Daemon{config: cfg}}
+
+	fetch := func(cred *PeerCred) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/config", nil)
+		if cred != nil {
+			req = req.WithContext(context.WithValue(req.Context(), peerCredKey, *cred))
+		}
+		rec := httptest.NewRecorder()
+		s.handleConfig(rec, req)
+		return rec
+	}
+
+	t.Run("webhook identity gets the secret", func(t *testing.T) {
+		rec := fetch(&PeerCred{UID: 1000, GID: 1000, PID: 7})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var resp ConfigResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.WebhookSecret != "s3cret" {
+			t.Errorf("WebhookSecret = %q, want s3cret", resp.WebhookSecret)
+		}
+	})
+
+	t.Run("other clients are refused", func(t *testing.T) {
+		rec := fetch(&PeerCred{UID: 2000, GID: 2000, PID: 8})
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("default clients are root and the daemon user", func(t *testing.T) {
+		cfg.WebhookClients = nil
+		defer func() { cfg.WebhookClients = []PeerIdentity{{ID: 1000}} }()
+
+		if rec := fetch(&PeerCred{UID: 0}); rec.Code != http.StatusOK {
+			t.Errorf("root status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("no peer credentials falls back to socket permissions", func(t *testing.T) {
+		if rec := fetch(nil); rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestConfigFromEnv_WebhookClients(t *testing.T) {
+	t.Setenv("BOSUN_WEBHOOK_CLIENTS", "uid=1000,gid=1001")
+
+	cfg := ConfigFromEnv()
+	if len(cfg.WebhookClients) != 2 || cfg.WebhookClients[1] != (PeerIdentity{Group: true, ID: 1001}) {
+		t.Errorf("WebhookClients = %v", cfg.WebhookClients)
+	}
+}
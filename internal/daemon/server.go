@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cameronsjo/bosun/internal/reconcile"
 	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/webhook"
 )
 
 // Server handles HTTP requests for webhooks and health checks.
@@ -39,6 +41,9 @@ func NewServer(d *Daemon) *Server {
 	// Metrics (placeholder for future)
 	mux.HandleFunc("/metrics", s.handleMetrics)
 
+	// Maintenance page, routed to by Traefik during deploys
+	mux.HandleFunc(reconcile.MaintenancePath, s.handleMaintenance)
+
 	s.server = &http.Server{
 		Handler:      s.loggingMiddleware(mux),
 		ReadTimeout:  10 * time.Second,
@@ -81,6 +86,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController flush and extend deadlines on the
+// underlying writer for streamed responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // handleHealth handles the health check endpoint.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -121,6 +132,12 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
 	// Validate webhook secret if configured
 	if s.daemon.config.WebhookSecret != "" {
 		sig := r.Header.Get("X-Signature")
@@ -128,14 +145,17 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 			sig = r.Header.Get("X-Hub-Signature-256")
 		}
 
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Failed to read body", http.StatusBadRequest)
+		if !s.validateSignature(body, sig) {
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
 			return
 		}
+	}
 
-		if !s.validateSignature(body, sig) {
-			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+	// Filter GitHub, GitLab, and Gitea style push payloads; anything else
+	// (an empty body from curl or a CI job) always triggers
+	var push webhook.Commits
+	if err := json.Unmarshal(body, &push); err == nil && push.Ref != "" {
+		if !s.acceptPush(w, push.Push()) {
 			return
 		}
 	}
@@ -214,6 +234,10 @@ func (s *Server) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.acceptPush(w, payload.Push()) {
+		return
+	}
+
 	ui.Info("GitHub push to %s by %s: %s", payload.Ref, payload.Pusher.Name, payload.HeadCommit.Message)
 
 	// Trigger reconciliation
@@ -234,6 +258,23 @@ func (s *Server) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// acceptPush applies the configured webhook filter to a push, answering an
+// ignored push with 200 so the provider doesn't retry it. Returns whether to
+// trigger.
+func (s *Server) acceptPush(w http.ResponseWriter, p webhook.Push) bool {
+	ok, reason := s.daemon.config.WebhookFilter.Allows(p)
+	if ok {
+		return true
+	}
+	ui.Info("Push ignored: %s", reason)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":  "ignored",
+		"message": fmt.Sprintf("Push ignored: %s", reason),
+	})
+	return false
+}
+
 // handleManualTrigger handles manual reconciliation triggers.
 func (s *Server) handleManualTrigger(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -306,6 +347,8 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "# TYPE bosun_reconcile_errors_total counter\n")
 		fmt.Fprintf(w, "bosun_reconcile_errors_total 1\n")
 	}
+
+	s.daemon.requests.WritePrometheus(w)
 }
 
 // validateSignature validates a generic HMAC-SHA256 signature.
@@ -342,7 +385,8 @@ func (s *Server) validateGitHubSignature(body []byte, signature string) bool {
 
 // GitHubPushPayload represents a GitHub push webhook payload.
 type GitHubPushPayload struct {
-	Ref    string `json:"ref"`
+	webhook.Commits // Ref and the files each commit touches
+
 	Before string `json:"before"`
 	After  string `json:"after"`
 	Pusher struct {
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/webhook"
+)
+
+// newFilteredServer returns a server whose daemon only reconciles pushes to
+// main that touch the manifest. The daemon looks busy, so an accepted push
+// queues a trigger instead of running a reconcile.
+func newFilteredServer(t *testing.T) (*Server, *Daemon) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.ReconcileConfig = &reconcile.Config{RepoBranch: "main", StateDir: t.TempDir()}
+	cfg.WebhookFilter = webhook.Filter{Branches: []string{"main"}, Paths: []string{"manifest/**"}}
+	d := &Daemon{config: cfg, reconciling: true}
+	return &Server{daemon: d}, d
+}
+
+// triggered waits briefly for a webhook's background trigger to reach the
+// busy daemon.
+func triggered(d *Daemon) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		d.reconcileMu.Lock()
+		pending := d.pendingTrigger
+		d.reconcileMu.Unlock()
+		if pending {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestServer_WebhookFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		event  string
+		body   string
+		status int
+	}{
+		{
+			name:   "github doc-only push",
+			path:   "/webhook/github",
+			event:  "push",
+			body:   `{"ref":"refs/heads/main","pusher":{"name":"alice"},"commits":[{"modified":["docs/gitops.md"]}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "github manifest push",
+			path:   "/webhook/github",
+			event:  "push",
+			body:   `{"ref":"refs/heads/main","pusher":{"name":"alice"},"commits":[{"modified":["manifest/stacks/media.yml"]}]}`,
+			status: http.StatusAccepted,
+		},
+		{
+			name:   "generic doc-only push",
+			path:   "/webhook",
+			body:   `{"ref":"refs/heads/main","commits":[{"added":["README.md"]}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "generic push to another branch",
+			path:   "/webhook",
+			body:   `{"ref":"refs/heads/feature","commits":[{"added":["manifest/stacks/media.yml"]}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "generic manifest push",
+			path:   "/webhook",
+			body:   `{"ref":"refs/heads/main","commits":[{"added":["manifest/stacks/media.yml"]}]}`,
+			status: http.StatusAccepted,
+		},
+		{
+			name:   "generic trigger without a push payload",
+			path:   "/webhook",
+			status: http.StatusAccepted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, d := newFilteredServer(t)
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.event != "" {
+				req.Header.Set("X-GitHub-Event", tt.event)
+			}
+			rec := httptest.NewRecorder()
+			if tt.event != "" {
+				s.handleGitHubWebhook(rec, req)
+			} else {
+				s.handleWebhook(rec, req)
+			}
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusAccepted {
+				if !triggered(d) {
+					t.Error("accepted push did not trigger a reconcile")
+				}
+				return
+			}
+
+			var resp map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp["status"] != "ignored" {
+				t.Errorf("status = %q, want ignored", resp["status"])
+			}
+			// Ignored pushes return before starting a trigger
+			if d.pendingTrigger {
+				t.Error("ignored push triggered a reconcile")
+			}
+		})
+	}
+}
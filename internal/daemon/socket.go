@@ -5,21 +5,45 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/cameronsjo/bosun/internal/ui"
 )
 
+// SystemSocketPath is the daemon socket of a daemon running as root.
+const SystemSocketPath = "/var/run/bosun.sock"
+
+// DefaultSocketPath returns the daemon socket path. Non-root users with
+// XDG_RUNTIME_DIR set (as on rootless Docker hosts, where bosun runs as the
+// Docker user) use $XDG_RUNTIME_DIR/bosun.sock, unless only the system
+// socket exists.
+func DefaultSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if os.Geteuid() == 0 || dir == "" {
+		return SystemSocketPath
+	}
+	userSocket := filepath.Join(dir, "bosun.sock")
+	if _, err := os.Stat(userSocket); err != nil {
+		if _, err := os.Stat(SystemSocketPath); err == nil {
+			return SystemSocketPath
+		}
+	}
+	return userSocket
+}
+
 // SocketServer handles Unix socket connections for the trigger API.
 type SocketServer struct {
 	daemon     *Daemon
 	socketPath string
 	listener   net.Listener
 	httpServer *http.Server
+	requests   *RequestMetrics
 }
 
 // SocketConfig holds socket server configuration.
@@ -31,7 +55,7 @@ type SocketConfig struct {
 // DefaultSocketConfig returns default socket configuration.
 func DefaultSocketConfig() *SocketConfig {
 	return &SocketConfig{
-		SocketPath: "/var/run/bosun.sock",
+		SocketPath: DefaultSocketPath(),
 		SocketMode: 0660,
 	}
 }
@@ -45,14 +69,14 @@ func NewSocketServer(d *Daemon, cfg *SocketConfig) (*SocketServer, error) {
 	s := &SocketServer{
 		daemon:     d,
 		socketPath: cfg.SocketPath,
+		requests:   d.requests,
 	}
 
 	// Create HTTP handler for socket
 	mux := http.NewServeMux()
-	mux.HandleFunc("/trigger", s.handleTrigger)
-	mux.HandleFunc("/status", s.handleStatus)
-	mux.HandleFunc("/health", s.handleHealth)
-	mux.HandleFunc("/config", s.handleConfig)
+	for path, handler := range s.routes() {
+		mux.HandleFunc(path, handler)
+	}
 
 	s.httpServer = &http.Server{
 		Handler:      s.auditMiddleware(mux),
@@ -63,6 +87,20 @@ func NewSocketServer(d *Daemon, cfg *SocketConfig) (*SocketServer, error) {
 	return s, nil
 }
 
+// routes returns the socket API's handlers by path. Each path needs an
+// operation label in apiOps.
+func (s *SocketServer) routes() map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"/trigger":       s.handleTrigger,
+		"/status":        s.handleStatus,
+		"/health":        s.handleHealth,
+		"/config":        s.handleConfig,
+		"/deploy-window": s.handleDeployWindow,
+		"/cancel":        s.handleCancel,
+		"/history":       s.handleHistory,
+	}
+}
+
 // Start starts the Unix socket server.
 func (s *SocketServer) Start() error {
 	// Ensure socket directory exists
@@ -167,6 +205,11 @@ func (s *SocketServer) handleTrigger(w http.ResponseWriter, r *http.Request) {
 		source = fmt.Sprintf("%s (pid:%s)", source, peerInfo)
 	}
 
+	if wantsStream(r) {
+		serveTriggerStream(s.daemon, w, r, source)
+		return
+	}
+
 	// Trigger reconcile
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -237,9 +280,43 @@ func (s *SocketServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(status)
 }
 
+// handleDeployWindow handles GET /deploy-window requests.
+func (s *SocketServer) handleDeployWindow(w http.ResponseWriter, r *http.Request) {
+	serveDeployWindow(s.daemon, w, r)
+}
+
+// handleCancel handles POST /cancel requests.
+func (s *SocketServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	serveCancel(s.daemon, w, r)
+}
+
+// handleHistory handles GET /history requests.
+func (s *SocketServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	serveHistory(s.daemon, w, r)
+}
+
+// serveDeployWindow reports whether it is safe to deploy. Unsafe responses
+// use 503 so callers can gate on the status code alone (e.g. curl -f).
+func serveDeployWindow(d *Daemon, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := d.DeployWindow(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	if !resp.Safe {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
 // handleConfig handles GET /config requests.
 // This endpoint allows the webhook container to fetch secrets from the daemon
-// without storing them on disk (daemon-injected secrets pattern).
+// without storing them on disk (daemon-injected secrets pattern). The webhook
+// secret is only handed to the webhook identity (see Config.WebhookClients);
+// every fetch is audit logged.
 func (s *SocketServer) handleConfig(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -248,8 +325,16 @@ func (s *SocketServer) handleConfig(w http.ResponseWriter, r *http.Request) {
 
 	// Build config response from daemon config
 	cfg := s.daemon.config
-	resp := ConfigResponse{
-		WebhookSecret: cfg.WebhookSecret,
+	resp := ConfigResponse{}
+
+	if cfg.WebhookSecret != "" {
+		if !s.daemon.webhookClientAllowed(r) {
+			auditSecretFetch(r, "webhook_secret", false)
+			http.Error(w, "Webhook secret is restricted to the webhook identity", http.StatusForbidden)
+			return
+		}
+		auditSecretFetch(r, "webhook_secret", true)
+		resp.WebhookSecret = cfg.WebhookSecret
 	}
 
 	// Include poll interval in seconds
@@ -267,33 +352,59 @@ func (s *SocketServer) handleConfig(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-// auditMiddleware logs all requests with peer credentials.
+// webhookClientAllowed reports whether the socket client that sent r may
+// fetch the webhook secret. Without peer credentials (non-Linux) the client
+// can't be identified, so only the socket permissions apply.
+func (d *Daemon) webhookClientAllowed(r *http.Request) bool {
+	cred, ok := peerCred(r)
+	if !ok {
+		return true
+	}
+	clients := d.config.WebhookClients
+	if len(clients) == 0 {
+		clients = defaultWebhookClients()
+	}
+	return slices.ContainsFunc(clients, func(id PeerIdentity) bool { return id.Matches(cred) })
+}
+
+// auditSecretFetch logs a secret fetch with the caller's identity.
+func auditSecretFetch(r *http.Request, secret string, granted bool) {
+	level, outcome := slog.LevelInfo, "granted"
+	if !granted {
+		level, outcome = slog.LevelWarn, "denied"
+	}
+	source := "unverified"
+	if peerInfo := getPeerInfo(r); peerInfo != "" {
+		source = peerInfo
+	}
+	slog.Log(r.Context(), level, "Secret fetch",
+		slog.String("secret", secret),
+		slog.String("source", source),
+		slog.String("outcome", outcome))
+}
+
+// auditMiddleware logs all requests with peer credentials and records request metrics.
 func (s *SocketServer) auditMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		// Get peer credentials for audit
-		peerInfo := getPeerInfo(r)
-
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		next.ServeHTTP(wrapped, r)
 
-		// Audit log
-		if peerInfo != "" {
-			ui.Info("AUDIT: %s %s from %s -> %d (%s)",
-				r.Method, r.URL.Path, peerInfo, wrapped.statusCode, time.Since(start))
-		} else {
-			ui.Info("AUDIT: %s %s -> %d (%s)",
-				r.Method, r.URL.Path, wrapped.statusCode, time.Since(start))
+		// Peer credentials identify the caller; fall back to the transport
+		source := "unix"
+		if peerInfo := getPeerInfo(r); peerInfo != "" {
+			source = peerInfo
 		}
+		observeRequest(s.requests, "socket", r, source, wrapped.statusCode, time.Since(start))
 	})
 }
 
 // getPeerInfo extracts peer information from the request context.
 // This is set by platform-specific code using SO_PEERCRED.
 func getPeerInfo(r *http.Request) string {
-	if info := r.Context().Value(peerCredKey); info != nil {
-		return info.(string)
+	if cred, ok := peerCred(r); ok {
+		return cred.String()
 	}
 	return ""
 }
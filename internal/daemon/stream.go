@@ -0,0 +1,223 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// StreamContentType is the media type of streamed operation responses: one
+// JSON Event per line. Clients ask for a stream with an Accept header.
+const StreamContentType = "application/x-ndjson"
+
+// Event types in a streamed operation response.
+const (
+	// EventAccepted is the first event: the trigger was accepted or queued.
+	EventAccepted = "accepted"
+	// EventStarted marks the start of a run; Message is the trigger source.
+	EventStarted = "started"
+	// EventProgress marks the start of a step within a run.
+	EventProgress = "progress"
+	// EventDone ends a run with its Status. The stream ends after the first
+	// done event that is not followed by a queued run.
+	EventDone = "done"
+)
+
+// Run outcomes carried by done events.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// OperationReconcile is the only long-running operation the daemon runs.
+const OperationReconcile = "reconcile"
+
+// eventBuffer bounds the events held for a slow stream reader; progress is
+// dropped rather than stalling the reconcile, but done events always
+// arrive.
+const eventBuffer = 64
+
+// Event is one line of a streamed operation response.
+type Event struct {
+	Type      string    `json:"type"`
+	Operation string    `json:"operation"`
+	Step      string    `json:"step,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Queued    bool      `json:"queued,omitempty"` // Done: another run follows
+	Time      time.Time `json:"time"`
+}
+
+// Final reports whether e ends a stream.
+func (e Event) Final() bool {
+	return e.Type == EventDone && !e.Queued
+}
+
+// CancelResponse is the response body for /cancel.
+type CancelResponse struct {
+	Cancelled bool   `json:"cancelled"`
+	Message   string `json:"message"`
+}
+
+// eventHub fans daemon events out to stream subscribers.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan Event]struct{})}
+}
+
+// subscribe returns a channel of events and a function that ends the
+// subscription.
+func (h *eventHub) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBuffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+// publish sends e to every subscriber without blocking. A full subscriber
+// misses progress events; a done event instead displaces the oldest
+// buffered event, so a stream always sees how its run ended. A nil hub (a
+// Daemon built without New) drops every event.
+func (h *eventHub) publish(e Event) {
+	if h == nil {
+		return
+	}
+	if e.Operation == "" {
+		e.Operation = OperationReconcile
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+			continue
+		default:
+		}
+		if e.Type != EventDone {
+			continue
+		}
+		// Make room by dropping the oldest event. publish is the only
+		// sender, so the send can't block once one is taken.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- e
+	}
+}
+
+// CancelReconcile cancels the running reconcile and drops any queued
+// trigger. It reports whether a reconcile was running.
+func (d *Daemon) CancelReconcile() bool {
+	d.reconcileMu.Lock()
+	defer d.reconcileMu.Unlock()
+
+	d.pendingTrigger = false
+	d.triggerSource = ""
+	if d.pushes != nil {
+		d.pushes.take()
+	}
+	if d.cancelRun == nil {
+		return false
+	}
+	d.cancelled = true
+	d.cancelRun()
+	return true
+}
+
+// wantsStream reports whether the client asked for a streamed response.
+func wantsStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), StreamContentType)
+}
+
+// serveTriggerStream triggers a reconcile and streams its events until the
+// run serving this trigger is done. Disconnecting detaches the client but
+// leaves the reconcile running; use /cancel to stop it.
+func serveTriggerStream(d *Daemon, w http.ResponseWriter, r *http.Request, source string) {
+	// Streams outlive the server's write timeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	events, unsubscribe := d.events.subscribe()
+	defer unsubscribe()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if err := d.TriggerReconcile(ctx, source); err != nil {
+			ui.Error("Streamed reconciliation failed: %v", err)
+		}
+	}()
+
+	w.Header().Set("Content-Type", StreamContentType)
+	w.WriteHeader(http.StatusAccepted)
+
+	enc := json.NewEncoder(w)
+	send := func(e Event) bool {
+		if err := enc.Encode(e); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send(Event{Type: EventAccepted, Operation: OperationReconcile, Message: "Reconciliation triggered", Time: time.Now().UTC()}) {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			if !send(e) || e.Final() {
+				return
+			}
+		}
+	}
+}
+
+// serveCancel handles POST /cancel.
+func serveCancel(d *Daemon, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := CancelResponse{Message: "No reconciliation in progress"}
+	if d.CancelReconcile() {
+		resp = CancelResponse{Cancelled: true, Message: "Reconciliation cancelled"}
+		ui.Warning("Reconciliation cancelled via API (%s)", requestSource(r))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// requestSource identifies the caller of a socket or TCP request for logs.
+func requestSource(r *http.Request) string {
+	if peerInfo := getPeerInfo(r); peerInfo != "" {
+		return fmt.Sprintf("pid:%s", peerInfo)
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,258 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventHub(t *testing.T) {
+	h := newEventHub()
+	events, unsubscribe := h.subscribe()
+
+	h.publish(Event{Type: EventProgress, Step: "sync"})
+	e := <-events
+	if e.Operation != OperationReconcile {
+		t.Errorf("Operation = %q, want %q", e.Operation, OperationReconcile)
+	}
+	if e.Time.IsZero() {
+		t.Error("Time not set")
+	}
+
+	// A full subscriber must not block the publisher
+	for i := 0; i < eventBuffer+10; i++ {
+		h.publish(Event{Type: EventProgress})
+	}
+	if len(events) != eventBuffer {
+		t.Errorf("buffered %d events, want %d", len(events), eventBuffer)
+	}
+
+	// A done event still reaches a full subscriber, as its last event
+	h.publish(Event{Type: EventDone, Status: StatusSucceeded})
+	if len(events) != eventBuffer {
+		t.Errorf("buffered %d events after done, want %d", len(events), eventBuffer)
+	}
+	var last Event
+	for len(events) > 0 {
+		last = <-events
+	}
+	if !last.Final() || last.Status != StatusSucceeded {
+		t.Errorf("last event = %+v, want the done event", last)
+	}
+
+	unsubscribe()
+	if len(h.subs) != 0 {
+		t.Errorf("%d subscribers after unsubscribe, want 0", len(h.subs))
+	}
+
+	var nilHub *eventHub
+	nilHub.publish(Event{Type: EventDone})
+}
+
+func TestDaemon_CancelReconcile(t *testing.T) {
+	d := &Daemon{events: newEventHub()}
+	if d.CancelReconcile() {
+		t.Error("CancelReconcile() = true while idle, want false")
+	}
+
+	called := false
+	d.cancelRun = func() { called = true }
+	d.pendingTrigger = true
+	d.triggerSource = "queued"
+
+	if !d.CancelReconcile() {
+		t.Error("CancelReconcile() = false while running, want true")
+	}
+	if !called || !d.cancelled {
+		t.Errorf("cancel called = %v, cancelled = %v, want both true", called, d.cancelled)
+	}
+	if d.pendingTrigger || d.triggerSource != "" {
+		t.Error("queued trigger was not dropped")
+	}
+}
+
+func TestDaemon_publishDone(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		cancelled bool
+		want      string
+	}{
+		{"success", nil, false, StatusSucceeded},
+		{"failure", errors.New("boom"), false, StatusFailed},
+		{"cancelled", context.Canceled, true, StatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Daemon{events: newEventHub()}
+			events, unsubscribe := d.events.subscribe()
+			defer unsubscribe()
+
+			d.publishDone(tt.err, tt.cancelled, false)
+			e := <-events
+			if e.Type != EventDone || e.Status != tt.want {
+				t.Errorf("event = %s/%s, want done/%s", e.Type, e.Status, tt.want)
+			}
+			if (tt.err != nil) != (e.Error != "") {
+				t.Errorf("Error = %q for err %v", e.Error, tt.err)
+			}
+			if !e.Final() {
+				t.Error("Final() = false for unqueued done")
+			}
+		})
+	}
+}
+
+func TestServeTriggerStream(t *testing.T) {
+	// A reconcile is in flight, so the trigger queues behind it
+	d := &Daemon{events: newEventHub(), reconciling: true}
+
+	req := httptest.NewRequest(http.MethodPost, "/trigger", nil)
+	req.Header.Set("Accept", StreamContentType)
+	rec := httptest.NewRecorder()
+
+	finished := make(chan struct{})
+	go func() {
+		serveTriggerStream(d, rec, req, "test")
+		close(finished)
+	}()
+
+	// Wait for the handler to subscribe before publishing
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		d.events.mu.Lock()
+		n := len(d.events.subs)
+		d.events.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("handler never subscribed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	d.events.publish(Event{Type: EventProgress, Step: "deploy", Message: "Deploying services"})
+	d.events.publish(Event{Type: EventDone, Status: StatusSucceeded, Queued: true})
+	d.events.publish(Event{Type: EventStarted, Message: "test"})
+	d.events.publish(Event{Type: EventDone, Status: StatusFailed, Error: "boom"})
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after the final done event")
+	}
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != StreamContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, StreamContentType)
+	}
+
+	var types []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		types = append(types, e.Type)
+	}
+	want := "accepted,progress,done,started,done"
+	if got := strings.Join(types, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestServeCancel(t *testing.T) {
+	d := &Daemon{events: newEventHub()}
+
+	rec := httptest.NewRecorder()
+	serveCancel(d, rec, httptest.NewRequest(http.MethodGet, "/cancel", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+
+	d.cancelRun = func() {}
+	rec = httptest.NewRecorder()
+	serveCancel(d, rec, httptest.NewRequest(http.MethodPost, "/cancel", nil))
+
+	var resp CancelResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Cancelled {
+		t.Errorf("Cancelled = false, want true")
+	}
+}
+
+func TestClient_TriggerStream(t *testing.T) {
+	t.Run("streams until the final event", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept") != StreamContentType {
+				t.Errorf("Accept = %q, want %q", r.Header.Get("Accept"), StreamContentType)
+			}
+			w.Header().Set("Content-Type", StreamContentType)
+			w.WriteHeader(http.StatusAccepted)
+			enc := json.NewEncoder(w)
+			_ = enc.Encode(Event{Type: EventAccepted})
+			_ = enc.Encode(Event{Type: EventProgress, Step: "sync"})
+			_ = enc.Encode(Event{Type: EventDone, Status: StatusCancelled})
+			_ = enc.Encode(Event{Type: EventProgress, Step: "ignored"})
+		}))
+		defer server.Close()
+
+		client := &Client{baseURL: server.URL, httpClient: server.Client()}
+
+		var seen []string
+		final, err := client.TriggerStream(context.Background(), "test", func(e Event) {
+			seen = append(seen, e.Type)
+		})
+		if err != nil {
+			t.Fatalf("TriggerStream() error = %v", err)
+		}
+		if final.Status != StatusCancelled {
+			t.Errorf("Status = %q, want %q", final.Status, StatusCancelled)
+		}
+		if got := strings.Join(seen, ","); got != "accepted,progress,done" {
+			t.Errorf("events = %s, want accepted,progress,done", got)
+		}
+	})
+
+	t.Run("stream ends early", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", StreamContentType)
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(Event{Type: EventAccepted})
+		}))
+		defer server.Close()
+
+		client := &Client{baseURL: server.URL, httpClient: server.Client()}
+		if _, err := client.TriggerStream(context.Background(), "test", nil); err == nil {
+			t.Error("TriggerStream() error = nil, want error")
+		}
+	})
+
+	t.Run("daemon without streaming", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(TriggerResponse{Status: "accepted"})
+		}))
+		defer server.Close()
+
+		client := &Client{baseURL: server.URL, httpClient: server.Client()}
+		_, err := client.TriggerStream(context.Background(), "test", nil)
+		if err == nil || !strings.Contains(err.Error(), "does not support") {
+			t.Errorf("TriggerStream() error = %v, want unsupported error", err)
+		}
+	})
+}
@@ -21,6 +21,7 @@ type TCPServer struct {
 	bearerToken string
 	listener    net.Listener
 	httpServer  *http.Server
+	requests    *RequestMetrics
 }
 
 // NewTCPServer creates a new TCP server with bearer token auth.
@@ -29,17 +30,18 @@ func NewTCPServer(d *Daemon, addr, bearerToken string) (*TCPServer, error) {
 		daemon:      d,
 		addr:        addr,
 		bearerToken: bearerToken,
+		requests:    d.requests,
 	}
 
 	// Create HTTP handler with auth middleware
 	mux := http.NewServeMux()
-	mux.HandleFunc("/trigger", s.handleTrigger)
-	mux.HandleFunc("/status", s.handleStatus)
-	mux.HandleFunc("/health", s.handleHealth)
-	// Note: /config endpoint is NOT exposed over TCP for security
+	for path, handler := range s.routes() {
+		mux.HandleFunc(path, handler)
+	}
 
+	// Audit wraps auth so rejected requests are logged and counted too
 	s.httpServer = &http.Server{
-		Handler:      s.authMiddleware(s.auditMiddleware(mux)),
+		Handler:      s.auditMiddleware(s.authMiddleware(mux)),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
@@ -47,6 +49,20 @@ func NewTCPServer(d *Daemon, addr, bearerToken string) (*TCPServer, error) {
 	return s, nil
 }
 
+// routes returns the TCP API's handlers by path. Each path needs an
+// operation label in apiOps.
+func (s *TCPServer) routes() map[string]http.HandlerFunc {
+	// Note: /config endpoint is NOT exposed over TCP for security
+	return map[string]http.HandlerFunc{
+		"/trigger":       s.handleTrigger,
+		"/status":        s.handleStatus,
+		"/health":        s.handleHealth,
+		"/deploy-window": s.handleDeployWindow,
+		"/cancel":        s.handleCancel,
+		"/history":       s.handleHistory,
+	}
+}
+
 // Start starts the TCP server.
 func (s *TCPServer) Start() error {
 	listener, err := net.Listen("tcp", s.addr)
@@ -104,7 +120,7 @@ func (s *TCPServer) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// auditMiddleware logs all requests.
+// auditMiddleware logs all requests and records request metrics.
 func (s *TCPServer) auditMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -112,8 +128,7 @@ func (s *TCPServer) auditMiddleware(next http.Handler) http.Handler {
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		next.ServeHTTP(wrapped, r)
 
-		ui.Info("TCP AUDIT: %s %s from %s -> %d (%s)",
-			r.Method, r.URL.Path, r.RemoteAddr, wrapped.statusCode, time.Since(start))
+		observeRequest(s.requests, "tcp", r, r.RemoteAddr, wrapped.statusCode, time.Since(start))
 	})
 }
 
@@ -140,6 +155,11 @@ func (s *TCPServer) handleTrigger(w http.ResponseWriter, r *http.Request) {
 	}
 	source = source + " (tcp:" + r.RemoteAddr + ")"
 
+	if wantsStream(r) {
+		serveTriggerStream(s.daemon, w, r, source)
+		return
+	}
+
 	// Trigger reconcile
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -208,3 +228,18 @@ func (s *TCPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 	_ = json.NewEncoder(w).Encode(status)
 }
+
+// handleDeployWindow handles GET /deploy-window requests.
+func (s *TCPServer) handleDeployWindow(w http.ResponseWriter, r *http.Request) {
+	serveDeployWindow(s.daemon, w, r)
+}
+
+// handleCancel handles POST /cancel requests.
+func (s *TCPServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	serveCancel(s.daemon, w, r)
+}
+
+// handleHistory handles GET /history requests.
+func (s *TCPServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	serveHistory(s.daemon, w, r)
+}
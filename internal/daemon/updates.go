@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/alert"
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/registry"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// updatesLoop checks the registries for newer deployed images every
+// UpdateCheckInterval until ctx ends.
+func (d *Daemon) updatesLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.config.UpdateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.scheduledUpdateCheck(ctx, time.Now())
+		case <-d.stopPoll:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scheduledUpdateCheck runs checkUpdates unless a maintenance window or an
+// all-stop is in force.
+func (d *Daemon) scheduledUpdateCheck(ctx context.Context, now time.Time) {
+	if d.inMaintenance(now) {
+		return
+	}
+	if stop := d.allStop(now); stop != nil {
+		ui.Info("Skipping image update check: all-stop by %s", stop.By)
+		return
+	}
+	d.checkUpdates(ctx)
+}
+
+// checkUpdates looks up newer versions of the images the deployed compose
+// files run and alerts on updates it hasn't alerted on before. It returns
+// the new updates, as "stack/service: old -> new" lines, or
+// "stack/service: image -> new digest sha256:..." for a newer image behind
+// the floating tag a service runs.
+func (d *Daemon) checkUpdates(ctx context.Context) []string {
+	composeDir := d.deployedComposeDir()
+	if composeDir == "" {
+		return nil
+	}
+	images, err := registry.ComposeImages(composeDir)
+	if err != nil {
+		ui.Warning("Image update check: %v", err)
+		return nil
+	}
+	d.setRunningDigests(ctx, images)
+
+	client := d.newRegistryClient()
+	var found []string
+	for _, img := range images {
+		checkCtx, cancel := context.WithTimeout(ctx, registry.DefaultTimeout)
+		res := client.Check(checkCtx, img)
+		cancel()
+
+		key := img.Stack + "/" + img.Service
+		if res.Err != nil {
+			ui.Warning("Image update check of %s: %v", key, res.Err)
+			continue
+		}
+		if !res.HasUpdate() {
+			delete(d.updates, key)
+			continue
+		}
+		if res.Redeploy() {
+			if d.updates[key] == res.Digest {
+				continue
+			}
+			d.updates[key] = res.Digest
+			found = append(found, fmt.Sprintf("%s: %s -> new digest %s", key, img.Image, res.Digest))
+			continue
+		}
+		image, err := manifest.WithTag(img.Image, res.Tag())
+		if err != nil || d.updates[key] == image {
+			continue
+		}
+		d.updates[key] = image
+		found = append(found, fmt.Sprintf("%s: %s -> %s", key, img.Image, image))
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+	ui.Info("%d image update(s) available; see 'bosun updates'", len(found))
+	if d.alerter != nil && d.alerter.HasProviders() {
+		if err := d.alerter.Send(ctx, alert.UpdatesAlert("local", found)); err != nil {
+			ui.Warning("Failed to send image update alert: %v", err)
+		}
+	}
+	return found
+}
+
+// setRunningDigests records the digests the running services' images were
+// pulled by, so floating tags can be checked. Without Docker they are
+// skipped.
+func (d *Daemon) setRunningDigests(ctx context.Context, images []registry.Image) {
+	if d.newDockerClient == nil {
+		return
+	}
+	client, err := d.newDockerClient()
+	if err != nil {
+		ui.Warning("Image update check: %v", err)
+		return
+	}
+	defer client.Close()
+	digests, err := client.ServiceRepoDigests(ctx)
+	if err != nil {
+		ui.Warning("Image update check: %v", err)
+		return
+	}
+	registry.SetRunning(images, digests, func(stack string) string {
+		return reconcile.ComposeProjectName(stack + ".yml")
+	})
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/docker/dockertest"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+	"github.com/cameronsjo/bosun/internal/registry"
+)
+
+func TestDaemon_CheckUpdates(t *testing.T) {
+	tags := []string{"1.0.0", "1.0.1"}
+	webDigest := "sha256:new"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/acme/app/tags/list":
+			_ = json.NewEncoder(w).Encode(map[string]any{"tags": tags})
+		case "/v2/acme/web/manifests/latest":
+			w.Header().Set("Docker-Content-Digest", webDigest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	appdata := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appdata, "compose"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	compose := "services:\n  app:\n    image: " + host + "/acme/app:1.0.0\n  web:\n    image: " + host + "/acme/web:latest\n  proxy:\n    image: nginx:latest\n"
+	if err := os.WriteFile(filepath.Join(appdata, "compose", "apps.yml"), []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Daemon{
+		config:  &Config{ReconcileConfig: &reconcile.Config{LocalAppdataPath: appdata}},
+		updates: make(map[string]string),
+		newDockerClient: func() (*docker.Client, error) {
+			scenario := dockertest.NewScenario().WithContainer(dockertest.Container{
+				Name:        "apps-web-1",
+				Image:       host + "/acme/web:latest",
+				RepoDigests: []string{host + "/acme/web@sha256:old"},
+				Labels:      map[string]string{docker.ProjectLabel: "apps", docker.ServiceLabel: "web"},
+			})
+			return scenario.Client(), nil
+		},
+		newRegistryClient: func() *registry.Client {
+			c := registry.NewClient()
+			c.Scheme = "http"
+			return c
+		},
+	}
+	ctx := context.Background()
+
+	want := []string{
+		"apps/app: " + host + "/acme/app:1.0.0 -> " + host + "/acme/app:1.0.1",
+		"apps/web: " + host + "/acme/web:latest -> new digest sha256:new",
+	}
+	if got := d.checkUpdates(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkUpdates() = %v, want %v", got, want)
+	}
+	if got := d.checkUpdates(ctx); got != nil {
+		t.Errorf("checkUpdates() again = %v, want nothing new", got)
+	}
+
+	tags = append(tags, "1.0.2")
+	webDigest = "sha256:newer"
+	want = []string{
+		"apps/app: " + host + "/acme/app:1.0.0 -> " + host + "/acme/app:1.0.2",
+		"apps/web: " + host + "/acme/web:latest -> new digest sha256:newer",
+	}
+	if got := d.checkUpdates(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkUpdates() after a new release = %v, want %v", got, want)
+	}
+
+	d.config.ReconcileConfig.TargetHost = "tower.local"
+	if got := d.checkUpdates(ctx); got != nil {
+		t.Errorf("checkUpdates() for a remote deploy = %v, want nil", got)
+	}
+}
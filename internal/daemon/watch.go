@@ -0,0 +1,260 @@
+package daemon
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/preflight"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DefaultWatchInterval is how often the health watch polls container health.
+const DefaultWatchInterval = 30 * time.Second
+
+// MaxHealthTransitions is how many health transitions the watch keeps per
+// container.
+const MaxHealthTransitions = 20
+
+// ContainerHealth is a container's current health and how long it has had it.
+type ContainerHealth struct {
+	Name   string    `json:"name"`
+	Health string    `json:"health"` // healthy, unhealthy, starting, or unknown
+	Since  time.Time `json:"since"`  // When the container entered this health state
+	// Transitions are the container's recent health changes, oldest first.
+	Transitions []HealthTransition `json:"transitions,omitempty"`
+}
+
+// HealthTransition records a container changing health state.
+type HealthTransition struct {
+	At   time.Time `json:"at"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// healthWatch tracks container health across polls. Only containers with a
+// healthcheck are tracked; a container that disappears is forgotten. It also
+// keeps the deployed bind mounts that failed their last probe.
+type healthWatch struct {
+	mu         sync.RWMutex
+	containers map[string]*ContainerHealth
+	mounts     map[string]string // Path -> probe error, for failing bind mounts
+}
+
+// newHealthWatch creates an empty health watch.
+func newHealthWatch() *healthWatch {
+	return &healthWatch{
+		containers: make(map[string]*ContainerHealth),
+		mounts:     make(map[string]string),
+	}
+}
+
+// observe records one poll of container health at now, returning the
+// containers that became unhealthy.
+func (w *healthWatch) observe(containers []docker.ContainerInfo, now time.Time) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var degraded []string
+	seen := make(map[string]bool, len(containers))
+	for _, ctr := range containers {
+		if ctr.Health == "" {
+			continue
+		}
+		seen[ctr.Name] = true
+
+		h, ok := w.containers[ctr.Name]
+		if !ok {
+			w.containers[ctr.Name] = &ContainerHealth{Name: ctr.Name, Health: ctr.Health, Since: now}
+			if ctr.Health == "unhealthy" {
+				degraded = append(degraded, ctr.Name)
+			}
+			continue
+		}
+		if h.Health == ctr.Health {
+			continue
+		}
+
+		h.Transitions = append(h.Transitions, HealthTransition{At: now, From: h.Health, To: ctr.Health})
+		if extra := len(h.Transitions) - MaxHealthTransitions; extra > 0 {
+			h.Transitions = append([]HealthTransition(nil), h.Transitions[extra:]...)
+		}
+		h.Health, h.Since = ctr.Health, now
+		if ctr.Health == "unhealthy" {
+			degraded = append(degraded, ctr.Name)
+		}
+	}
+
+	for name := range w.containers {
+		if !seen[name] {
+			delete(w.containers, name)
+		}
+	}
+	sort.Strings(degraded)
+	return degraded
+}
+
+// observeMounts records one probe of the deployed bind mounts, returning the
+// mounts that started failing and the ones that recovered.
+func (w *healthWatch) observeMounts(failed []preflight.MountCheck) (stale, recovered []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := make(map[string]string, len(failed))
+	for _, f := range failed {
+		current[f.Path] = f.Error.Error()
+		if _, ok := w.mounts[f.Path]; !ok {
+			stale = append(stale, f.Path)
+		}
+	}
+	for path := range w.mounts {
+		if _, ok := current[path]; !ok {
+			recovered = append(recovered, path)
+		}
+	}
+	w.mounts = current
+	sort.Strings(recovered)
+	return stale, recovered
+}
+
+// staleMounts returns why each failing bind mount failed its last probe,
+// sorted by path.
+func (w *healthWatch) staleMounts() []string {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	paths := make([]string, 0, len(w.mounts))
+	for path := range w.mounts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	reasons := make([]string, 0, len(paths))
+	for _, path := range paths {
+		reasons = append(reasons, w.mounts[path])
+	}
+	return reasons
+}
+
+// snapshot returns a copy of every tracked container's health, by name.
+func (w *healthWatch) snapshot() []ContainerHealth {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	result := make([]ContainerHealth, 0, len(w.containers))
+	for _, h := range w.containers {
+		c := *h
+		c.Transitions = append([]HealthTransition(nil), h.Transitions...)
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// watchLoop polls container health every WatchInterval until ctx ends.
+func (d *Daemon) watchLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.config.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		d.pollHealth(ctx)
+		select {
+		case <-ticker.C:
+		case <-d.stopPoll:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollHealth records the current health of every running container and of
+// the deployed bind mounts and, with DriftRemediate, checks the deployed
+// stacks for drift. Inside a maintenance window it does none of these, and
+// drift seen before the window starts over.
+func (d *Daemon) pollHealth(ctx context.Context) {
+	if d.inMaintenance(time.Now()) {
+		for stack := range d.drift.since {
+			d.drift.forget(stack)
+		}
+		return
+	}
+
+	d.probeMounts()
+
+	client, err := d.newDockerClient()
+	if err != nil {
+		ui.Warning("Health watch: %v", err)
+		return
+	}
+	defer client.Close()
+
+	containers, err := client.ListContainers(ctx, true)
+	if err != nil {
+		ui.Warning("Health watch: %v", err)
+		return
+	}
+	now := time.Now().UTC()
+	degraded := d.watch.observe(containers, now)
+	for _, name := range degraded {
+		ui.Warning("Container %s is unhealthy", name)
+	}
+	if len(degraded) > 0 {
+		d.recordStats(func(day *state.DayStats) {
+			if day.Unhealthy == nil {
+				day.Unhealthy = make(map[string]int)
+			}
+			for _, name := range degraded {
+				day.Unhealthy[name]++
+			}
+		})
+	}
+
+	if d.config != nil && d.config.DriftRemediate {
+		d.checkDrift(ctx, containers, now)
+	}
+}
+
+// probeMounts probes the bind-mount sources of the deployed compose files,
+// so a mount that goes stale between reconciles shows up in health. A mount
+// that hangs is probed by one stat at a time, however many polls it spans.
+func (d *Daemon) probeMounts() {
+	if d.config == nil {
+		return
+	}
+	composeDir := d.deployedComposeDir()
+	if composeDir == "" {
+		return
+	}
+	files, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+
+	var paths []string
+	for _, file := range files {
+		sources, err := preflight.BindMountSources(file)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, sources...)
+	}
+
+	failed := preflight.CheckMountPaths(paths, preflight.DefaultMountProbeTimeout)
+	stale, recovered := d.watch.observeMounts(failed)
+	for _, f := range failed {
+		if slices.Contains(stale, f.Path) {
+			ui.Warning("Bind mount failed its probe: %v", f.Error)
+		}
+	}
+	for _, path := range recovered {
+		ui.Info("Bind mount %s recovered", path)
+	}
+}
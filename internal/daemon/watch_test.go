@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/docker/dockertest"
+	"github.com/cameronsjo/bosun/internal/preflight"
+	"github.com/cameronsjo/bosun/internal/reconcile"
+)
+
+func TestHealthWatch_Observe(t *testing.T) {
+	w := newHealthWatch()
+	start := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	degraded := w.observe([]docker.ContainerInfo{
+		{Name: "web", Health: "healthy"},
+		{Name: "db", Health: "starting"},
+		{Name: "worker"}, // No healthcheck
+	}, start)
+	if len(degraded) != 0 {
+		t.Errorf("degraded = %v, want none", degraded)
+	}
+
+	later := start.Add(12 * time.Minute)
+	degraded = w.observe([]docker.ContainerInfo{
+		{Name: "web", Health: "unhealthy"},
+		{Name: "db", Health: "starting"},
+	}, later)
+	if len(degraded) != 1 || degraded[0] != "web" {
+		t.Errorf("degraded = %v, want [web]", degraded)
+	}
+
+	got := w.snapshot()
+	if len(got) != 2 {
+		t.Fatalf("snapshot has %d containers, want 2 (no worker)", len(got))
+	}
+	db, web := got[0], got[1]
+	if !db.Since.Equal(start) || len(db.Transitions) != 0 {
+		t.Errorf("db = %+v, want unchanged since start", db)
+	}
+	if web.Health != "unhealthy" || !web.Since.Equal(later) {
+		t.Errorf("web = %+v, want unhealthy since %v", web, later)
+	}
+	if len(web.Transitions) != 1 || web.Transitions[0].From != "healthy" || web.Transitions[0].To != "unhealthy" {
+		t.Errorf("web transitions = %+v, want healthy -> unhealthy", web.Transitions)
+	}
+
+	// Staying unhealthy is not a new transition, and gone containers are forgotten
+	if degraded := w.observe([]docker.ContainerInfo{{Name: "web", Health: "unhealthy"}}, later.Add(time.Minute)); len(degraded) != 0 {
+		t.Errorf("degraded = %v, want none while still unhealthy", degraded)
+	}
+	if got := w.snapshot(); len(got) != 1 || !got[0].Since.Equal(later) {
+		t.Errorf("snapshot = %+v, want only web, unhealthy since %v", got, later)
+	}
+}
+
+func TestHealthWatch_TransitionsCapped(t *testing.T) {
+	w := newHealthWatch()
+	at := time.Now()
+	for i := 0; i < MaxHealthTransitions+5; i++ {
+		health := "healthy"
+		if i%2 == 1 {
+			health = "unhealthy"
+		}
+		w.observe([]docker.ContainerInfo{{Name: "web", Health: health}}, at.Add(time.Duration(i)*time.Minute))
+	}
+	if got := w.snapshot()[0].Transitions; len(got) != MaxHealthTransitions {
+		t.Errorf("kept %d transitions, want %d", len(got), MaxHealthTransitions)
+	}
+}
+
+func TestHealthWatch_ObserveMounts(t *testing.T) {
+	w := newHealthWatch()
+	nfs := preflight.MountCheck{Path: "/mnt/nfs", Error: errors.New("/mnt/nfs: stale file handle")}
+	smb := preflight.MountCheck{Path: "/mnt/smb", Error: errors.New("/mnt/smb: mount probe timed out after 3s")}
+
+	stale, recovered := w.observeMounts([]preflight.MountCheck{nfs, smb})
+	if len(stale) != 2 || len(recovered) != 0 {
+		t.Errorf("first probe: stale = %v, recovered = %v, want both stale", stale, recovered)
+	}
+
+	stale, recovered = w.observeMounts([]preflight.MountCheck{smb})
+	if len(stale) != 0 || len(recovered) != 1 || recovered[0] != "/mnt/nfs" {
+		t.Errorf("second probe: stale = %v, recovered = %v, want /mnt/nfs recovered", stale, recovered)
+	}
+	if got := w.staleMounts(); len(got) != 1 || got[0] != smb.Error.Error() {
+		t.Errorf("staleMounts() = %v, want [%s]", got, smb.Error)
+	}
+}
+
+func TestDaemon_ProbeMounts(t *testing.T) {
+	appdata := t.TempDir()
+	composeDir := filepath.Join(appdata, "compose")
+	if err := os.MkdirAll(composeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	compose := "services:\n  app:\n    image: app\n    volumes:\n      - " + t.TempDir() + ":/data\n"
+	if err := os.WriteFile(filepath.Join(composeDir, "app.yml"), []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ReconcileConfig = &reconcile.Config{LocalAppdataPath: appdata}
+	d := &Daemon{config: cfg, watch: newHealthWatch()}
+	d.watch.mounts["/mnt/gone"] = "/mnt/gone: stale file handle"
+
+	d.probeMounts()
+
+	if got := d.watch.staleMounts(); len(got) != 0 {
+		t.Errorf("staleMounts() = %v, want none for a healthy bind mount", got)
+	}
+	if got := d.HealthStatus().StaleMounts; len(got) != 0 {
+		t.Errorf("HealthStatus().StaleMounts = %v, want none", got)
+	}
+}
+
+func TestDaemon_PollHealth(t *testing.T) {
+	scenario := dockertest.NewScenario().WithHealthyContainer("web").WithUnhealthy("api")
+	d := &Daemon{
+		watch:           newHealthWatch(),
+		newDockerClient: func() (*docker.Client, error) { return scenario.Client(), nil },
+	}
+
+	d.pollHealth(context.Background())
+
+	got := d.watch.snapshot()
+	if len(got) != 2 {
+		t.Fatalf("snapshot has %d containers, want 2", len(got))
+	}
+	if got[0].Name != "api" || got[0].Health != "unhealthy" {
+		t.Errorf("got[0] = %+v, want api unhealthy", got[0])
+	}
+	if got[1].Name != "web" || got[1].Health != "healthy" {
+		t.Errorf("got[1] = %+v, want web healthy", got[1])
+	}
+}
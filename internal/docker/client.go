@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ type statsJSON struct {
 			PercpuUsage []uint64 `json:"percpu_usage"`
 		} `json:"cpu_usage"`
 		SystemUsage uint64 `json:"system_cpu_usage"`
+		OnlineCPUs  int    `json:"online_cpus"`
 	} `json:"cpu_stats"`
 	PreCPUStats struct {
 		CPUUsage struct {
@@ -35,28 +37,64 @@ type statsJSON struct {
 		Usage uint64 `json:"usage"`
 		Limit uint64 `json:"limit"`
 	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
 }
 
 // Client wraps the Docker SDK client.
 type Client struct {
-	cli *client.Client
-	api DockerAPI // interface for testing
+	cli      *client.Client
+	api      DockerAPI // interface for testing
+	timeouts Timeouts
 }
 
 // NewClient creates a new Docker client connection and validates daemon connectivity.
-func NewClient() (*Client, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// Read-only calls are retried on transient connection errors and guarded by a
+// circuit breaker (DefaultRetryPolicy, DefaultBreakerConfig), and every call is
+// bounded by DefaultTimeouts; opts override these.
+//
+// The daemon comes from the environment (DOCKER_HOST, DOCKER_CERT_PATH, ...);
+// an ssh:// DOCKER_HOST is tunneled over SSH as the docker CLI does.
+func NewClient(opts ...ClientOption) (*Client, error) {
+	clientOpts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host := os.Getenv(client.EnvOverrideHost); strings.HasPrefix(host, "ssh://") {
+		sshOpts, err := remoteHostOpts(RemoteConfig{Host: host})
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts, sshOpts...)
+	} else if host := DetectHost(); host != "" {
+		// Fall back to a rootless daemon's socket when there is no rootful one.
+		clientOpts = append(clientOpts, client.WithHost(host))
+	}
+	return newClient(clientOpts, opts)
+}
+
+// newClient creates a client with clientOpts, applies the default and given
+// options, and validates daemon connectivity.
+func newClient(clientOpts []client.Opt, opts []ClientOption) (*Client, error) {
+	cli, err := client.NewClientWithOpts(clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("create docker client: %w", err)
 	}
 
-	c := &Client{cli: cli, api: cli}
+	defaults := []ClientOption{WithRetryPolicy(DefaultRetryPolicy), WithCircuitBreaker(DefaultBreakerConfig)}
+	c := NewClientWithAPI(cli, append(defaults, opts...)...)
+	c.cli = cli
 
 	// Validate daemon is reachable before returning client
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if _, err := cli.Ping(ctx); err != nil {
+	if _, err := c.api.Ping(ctx); err != nil {
 		cli.Close()
 		return nil, fmt.Errorf("docker daemon not reachable: %w", err)
 	}
@@ -65,14 +103,19 @@ func NewClient() (*Client, error) {
 }
 
 // NewClientWithAPI creates a new Docker client with a custom API implementation.
-// This is primarily used for testing with mock implementations.
-func NewClientWithAPI(api DockerAPI) *Client {
-	return &Client{api: api}
+// This is primarily used for testing with mock implementations. Unlike NewClient,
+// no retries or circuit breaker are applied unless requested via opts.
+func NewClientWithAPI(api DockerAPI, opts ...ClientOption) *Client {
+	c := &Client{api: api, timeouts: DefaultTimeouts}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Ping tests the connection to the Docker daemon.
 func (c *Client) Ping(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := withTimeout(ctx, c.timeouts.Query)
 	defer cancel()
 
 	_, err := c.api.Ping(ctx)
@@ -85,6 +128,9 @@ func (c *Client) Ping(ctx context.Context) error {
 
 // Info returns system-wide information about the Docker daemon.
 func (c *Client) Info(ctx context.Context) (system.Info, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+
 	return c.api.Info(ctx)
 }
 
@@ -106,12 +152,18 @@ type ContainerInfo struct {
 	ID      string
 	Name    string
 	Image   string
+	ImageID string
 	Status  string
 	State   string
 	Health  string
 	Created time.Time
 	Uptime  string
 	Ports   []string
+	Labels  map[string]string
+
+	// Started and Restarts are only set for running or restarting containers.
+	Started  time.Time
+	Restarts int
 }
 
 // ContainerStats holds resource usage statistics.
@@ -121,13 +173,19 @@ type ContainerStats struct {
 	MemUsage   uint64
 	MemLimit   uint64
 	MemPercent float64
+
+	// Cumulative network and block I/O bytes since the container started.
+	NetRx, NetTx          uint64
+	BlockRead, BlockWrite uint64
 }
 
 // ListContainers returns all containers (running and stopped).
 func (c *Client) ListContainers(ctx context.Context, runningOnly bool) ([]ContainerInfo, error) {
-	containers, err := c.api.ContainerList(ctx, container.ListOptions{
+	listCtx, cancel := withTimeout(ctx, c.timeouts.Query)
+	containers, err := c.api.ContainerList(listCtx, container.ListOptions{
 		All: !runningOnly,
 	})
+	cancel()
 	if err != nil {
 		return nil, fmt.Errorf("list containers: %w", err)
 	}
@@ -140,14 +198,22 @@ func (c *Client) ListContainers(ctx context.Context, runningOnly bool) ([]Contai
 		}
 
 		health := ""
-		if ctr.State == "running" {
-			// Get health status from inspection
-			inspect, err := c.api.ContainerInspect(ctx, ctr.ID)
+		var started time.Time
+		restarts := 0
+		if ctr.State == "running" || ctr.State == "restarting" {
+			// Get health status and restart history from inspection
+			inspectCtx, cancel := withTimeout(ctx, c.timeouts.Query)
+			inspect, err := c.api.ContainerInspect(inspectCtx, ctr.ID)
+			cancel()
 			if err != nil {
 				// Indicate health status could not be determined
 				health = "unknown"
-			} else if inspect.State.Health != nil {
-				health = inspect.State.Health.Status
+			} else if inspect.ContainerJSONBase != nil && inspect.State != nil {
+				if inspect.State.Health != nil && ctr.State == "running" {
+					health = inspect.State.Health.Status
+				}
+				started = parseTimeOrZero(inspect.State.StartedAt)
+				restarts = inspect.RestartCount
 			}
 		}
 
@@ -161,20 +227,43 @@ func (c *Client) ListContainers(ctx context.Context, runningOnly bool) ([]Contai
 		}
 
 		result = append(result, ContainerInfo{
-			ID:      ctr.ID[:12],
-			Name:    name,
-			Image:   ctr.Image,
-			Status:  ctr.Status,
-			State:   ctr.State,
-			Health:  health,
-			Created: time.Unix(ctr.Created, 0),
-			Ports:   ports,
+			ID:       ctr.ID[:12],
+			Name:     name,
+			Image:    ctr.Image,
+			ImageID:  ctr.ImageID,
+			Status:   ctr.Status,
+			State:    ctr.State,
+			Health:   health,
+			Created:  time.Unix(ctr.Created, 0),
+			Ports:    ports,
+			Labels:   ctr.Labels,
+			Started:  started,
+			Restarts: restarts,
 		})
 	}
 
 	return result, nil
 }
 
+// RunningContainerNames returns the names of the running containers. Unlike
+// ListContainers it doesn't inspect each one, so it is cheap to poll.
+func (c *Client) RunningContainerNames(ctx context.Context) ([]string, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+
+	containers, err := c.api.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	names := make([]string, 0, len(containers))
+	for _, ctr := range containers {
+		if len(ctr.Names) > 0 {
+			names = append(names, strings.TrimPrefix(ctr.Names[0], "/"))
+		}
+	}
+	return names, nil
+}
+
 // CountContainers returns counts of running, total, and unhealthy containers.
 func (c *Client) CountContainers(ctx context.Context) (running, total, unhealthy int, err error) {
 	containers, err := c.ListContainers(ctx, false)
@@ -229,8 +318,50 @@ func (c *Client) GetContainerImage(ctx context.Context, name string) (string, er
 	return ctr.Image, nil
 }
 
+// ImageRepoDigests returns the registry digests an image was pulled by,
+// e.g. nginx@sha256:..., or none for an image built locally.
+func (c *Client) ImageRepoDigests(ctx context.Context, imageID string) ([]string, error) {
+	inspectCtx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+	img, err := c.api.ImageInspect(inspectCtx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("inspect image %s: %w", imageID, err)
+	}
+	return img.RepoDigests, nil
+}
+
+// ServiceRepoDigests returns the repo digests of the images the running
+// compose services run, keyed by "project/service". Images that can't be
+// inspected are left out.
+func (c *Client) ServiceRepoDigests(ctx context.Context) (map[string][]string, error) {
+	containers, err := c.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	digests := make(map[string][]string)
+	byImage := make(map[string][]string)
+	for _, ctr := range containers {
+		project, service := ctr.Labels[ProjectLabel], ctr.Labels[ServiceLabel]
+		if project == "" || service == "" || ctr.ImageID == "" {
+			continue
+		}
+		repoDigests, ok := byImage[ctr.ImageID]
+		if !ok {
+			repoDigests, _ = c.ImageRepoDigests(ctx, ctr.ImageID)
+			byImage[ctr.ImageID] = repoDigests
+		}
+		if len(repoDigests) > 0 {
+			digests[project+"/"+service] = repoDigests
+		}
+	}
+	return digests, nil
+}
+
 // RemoveContainer forcefully removes a container by name.
 func (c *Client) RemoveContainer(ctx context.Context, name string) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Lifecycle)
+	defer cancel()
+
 	return c.api.ContainerRemove(ctx, name, container.RemoveOptions{
 		Force: true,
 	})
@@ -238,6 +369,9 @@ func (c *Client) RemoveContainer(ctx context.Context, name string) error {
 
 // RestartContainer restarts a container by name.
 func (c *Client) RestartContainer(ctx context.Context, name string) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Lifecycle)
+	defer cancel()
+
 	timeout := 10
 	return c.api.ContainerRestart(ctx, name, container.StopOptions{Timeout: &timeout})
 }
@@ -254,6 +388,9 @@ func (c *Client) GetContainerLogs(ctx context.Context, name string, tail int) (s
 		Tail:       fmt.Sprintf("%d", tail),
 	}
 
+	ctx, cancel := withTimeout(ctx, c.timeouts.Logs)
+	defer cancel()
+
 	reader, err := c.api.ContainerLogs(ctx, name, options)
 	if err != nil {
 		return "", fmt.Errorf("get container logs: %w", err)
@@ -274,6 +411,9 @@ func (c *Client) GetContainerLogs(ctx context.Context, name string, tail int) (s
 
 // GetContainerStats returns resource usage for a container.
 func (c *Client) GetContainerStats(ctx context.Context, name string) (*ContainerStats, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Stats)
+	defer cancel()
+
 	stats, err := c.api.ContainerStats(ctx, name, false)
 	if err != nil {
 		return nil, fmt.Errorf("get container stats: %w", err)
@@ -290,12 +430,23 @@ func (c *Client) GetContainerStats(ctx context.Context, name string) (*Container
 		return nil, fmt.Errorf("parse stats: %w", err)
 	}
 
-	// Calculate CPU percentage
+	s := statsFromJSON(name, v)
+	return &s, nil
+}
+
+// statsFromJSON computes a container's stats from a stats sample.
+func statsFromJSON(name string, v statsJSON) ContainerStats {
+	// Calculate CPU percentage. cgroup v2 hosts report online_cpus but no
+	// per-CPU usage.
 	cpuPercent := 0.0
+	cpus := v.CPUStats.OnlineCPUs
+	if cpus == 0 {
+		cpus = len(v.CPUStats.CPUUsage.PercpuUsage)
+	}
 	cpuDelta := float64(v.CPUStats.CPUUsage.TotalUsage - v.PreCPUStats.CPUUsage.TotalUsage)
 	systemDelta := float64(v.CPUStats.SystemUsage - v.PreCPUStats.SystemUsage)
 	if systemDelta > 0 && cpuDelta > 0 {
-		cpuPercent = (cpuDelta / systemDelta) * float64(len(v.CPUStats.CPUUsage.PercpuUsage)) * 100.0
+		cpuPercent = (cpuDelta / systemDelta) * float64(cpus) * 100.0
 	}
 
 	// Calculate memory percentage
@@ -304,13 +455,26 @@ func (c *Client) GetContainerStats(ctx context.Context, name string) (*Container
 		memPercent = float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100.0
 	}
 
-	return &ContainerStats{
+	s := ContainerStats{
 		Name:       name,
 		CPUPercent: cpuPercent,
 		MemUsage:   v.MemoryStats.Usage,
 		MemLimit:   v.MemoryStats.Limit,
 		MemPercent: memPercent,
-	}, nil
+	}
+	for _, n := range v.Networks {
+		s.NetRx += n.RxBytes
+		s.NetTx += n.TxBytes
+	}
+	for _, entry := range v.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			s.BlockRead += entry.Value
+		case "write":
+			s.BlockWrite += entry.Value
+		}
+	}
+	return s
 }
 
 // GetAllContainerStats returns stats for all running containers.
@@ -338,6 +502,9 @@ func (c *Client) GetAllContainerStats(ctx context.Context) ([]ContainerStats, er
 
 // DiskUsage returns Docker system disk usage information.
 func (c *Client) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.DiskUsage)
+	defer cancel()
+
 	return c.api.DiskUsage(ctx, types.DiskUsageOptions{})
 }
 
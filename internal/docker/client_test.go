@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -237,6 +238,44 @@ func TestClient_ListContainers(t *testing.T) {
 	}
 }
 
+func TestClient_RunningContainerNames(t *testing.T) {
+	mock := NewMockDockerAPI()
+	mock.ContainerListFunc = func(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+		assert.False(t, options.All, "only running containers are listed")
+		return []container.Summary{
+			makeTestContainer("abc123456789", "web", "nginx:latest", "running"),
+			makeTestContainer("def123456789", "db", "postgres:16", "running"),
+		}, nil
+	}
+	mock.ContainerInspectFunc = func(ctx context.Context, containerID string) (container.InspectResponse, error) {
+		t.Errorf("ContainerInspect(%s) called; names need only the list", containerID)
+		return container.InspectResponse{}, nil
+	}
+
+	got, err := NewClientWithAPI(mock).RunningContainerNames(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web", "db"}, got)
+}
+
+func TestClient_ListContainers_RestartHistory(t *testing.T) {
+	mock := NewMockDockerAPI()
+	mock.ContainerListFunc = func(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+		return []container.Summary{makeTestContainer("abc123456789", "web", "nginx:latest", "restarting")}, nil
+	}
+	mock.ContainerInspectFunc = func(ctx context.Context, containerID string) (container.InspectResponse, error) {
+		resp := makeTestContainerJSONWithHealth("abc123456789", "web", "nginx:latest", "restarting", "unhealthy", false)
+		resp.RestartCount = 7
+		return resp, nil
+	}
+
+	got, err := NewClientWithAPI(mock).ListContainers(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 7, got[0].Restarts)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), got[0].Started)
+	assert.Empty(t, got[0].Health, "health of a restarting container is stale")
+}
+
 func TestClient_CountContainers(t *testing.T) {
 	tests := []struct {
 		name          string
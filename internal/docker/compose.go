@@ -18,12 +18,28 @@ type ServiceStatus struct {
 	Running bool
 }
 
-// ComposeClient handles docker compose operations.
+// ConfigHashLabel is the container label where compose records the hash of
+// the service config the container was created from.
+const ConfigHashLabel = "com.docker.compose.config-hash"
+
+// ProjectLabel and ServiceLabel are the container labels where compose
+// records the project and service a container belongs to.
+const (
+	ProjectLabel = "com.docker.compose.project"
+	ServiceLabel = "com.docker.compose.service"
+)
+
+// ComposeClient handles docker compose operations, through the docker
+// compose CLI or, with WithNative, in process (see compose_native.go).
 type ComposeClient struct {
-	file string
+	file     string
+	project  string   // Compose project name, "" to let compose derive it
+	profiles []string // Compose profiles to enable
+	native   bool     // Use compose-go and the compose SDK instead of the CLI
 }
 
-// NewComposeClient creates a new compose client for the given compose file.
+// NewComposeClient creates a new compose client for the given compose file,
+// on the native path when BOSUN_COMPOSE_NATIVE=true (see WithNative).
 // Returns an error if the compose file does not exist.
 func NewComposeClient(file string) (*ComposeClient, error) {
 	if _, err := os.Stat(file); err != nil {
@@ -32,12 +48,49 @@ func NewComposeClient(file string) (*ComposeClient, error) {
 		}
 		return nil, fmt.Errorf("cannot access compose file %s: %w", file, err)
 	}
-	return &ComposeClient{file: file}, nil
+	return &ComposeClient{file: file, native: nativeFromEnv()}, nil
+}
+
+// WithProject sets the compose project the client runs commands against, so
+// a rendered stack is managed under the project bosun deploys it as.
+func (c *ComposeClient) WithProject(project string) *ComposeClient {
+	c.project = project
+	return c
+}
+
+// WithProfiles enables compose profiles, so services in them start along
+// with the services that have none.
+func (c *ComposeClient) WithProfiles(profiles ...string) *ComposeClient {
+	c.profiles = profiles
+	return c
+}
+
+// args returns the docker arguments for a compose subcommand.
+func (c *ComposeClient) args(subcommand ...string) []string {
+	args := []string{"compose"}
+	if c.project != "" {
+		args = append(args, "-p", c.project)
+	}
+	args = append(args, "-f", c.file)
+	args = append(args, ProfileArgs(c.profiles)...)
+	return append(args, subcommand...)
+}
+
+// ProfileArgs returns the docker compose flags that enable profiles.
+func ProfileArgs(profiles []string) []string {
+	args := make([]string, 0, 2*len(profiles))
+	for _, p := range profiles {
+		args = append(args, "--profile", p)
+	}
+	return args
 }
 
 // Up starts services defined in the compose file.
 func (c *ComposeClient) Up(ctx context.Context, services ...string) error {
-	args := []string{"compose", "-f", c.file, "up", "-d"}
+	if c.native {
+		return c.nativeUp(ctx, services...)
+	}
+	args := c.args("up", "-d")
 	args = append(args, services...)
 
 	cmd := exec.CommandContext(ctx, "docker", args...)
@@ -49,9 +102,36 @@ func (c *ComposeClient) Up(ctx context.Context, services ...string) error {
 	return nil
 }
 
+// Deploy brings the project up the way a reconcile deploys it: up -d
+// --remove-orphans --wait, so it returns once the services are running or
+// healthy.
+func (c *ComposeClient) Deploy(ctx context.Context) error {
+	if c.native {
+		if err := c.nativeDeploy(ctx); err != nil {
+			return fmt.Errorf("docker compose up: %w", err)
+		}
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "docker", c.args("up", "-d", "--remove-orphans", "--wait")...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker compose up: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// Native reports whether the client runs compose in process (see
+// WithNative).
+func (c *ComposeClient) Native() bool {
+	return c.native
+}
+
 // Down stops and removes services defined in the compose file.
 func (c *ComposeClient) Down(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", c.file, "down")
+	if c.native {
+		return c.nativeDown(ctx)
+	}
+	cmd := exec.CommandContext(ctx, "docker", c.args("down")...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("docker compose down: %w\n%s", err, output)
@@ -62,7 +142,10 @@ func (c *ComposeClient) Down(ctx context.Context) error {
 
 // Restart restarts services defined in the compose file.
 func (c *ComposeClient) Restart(ctx context.Context, services ...string) error {
-	args := []string{"compose", "-f", c.file, "restart"}
+	if c.native {
+		return c.nativeRestart(ctx, services...)
+	}
+	args := c.args("restart")
 	args = append(args, services...)
 
 	cmd := exec.CommandContext(ctx, "docker", args...)
@@ -76,7 +159,14 @@ func (c *ComposeClient) Restart(ctx context.Context, services ...string) error {
 
 // Status returns the status of services in the compose file.
 func (c *ComposeClient) Status(ctx context.Context) ([]ServiceStatus, error) {
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", c.file, "ps", "--format", "{{.Name}}\t{{.State}}\t{{.Status}}\t{{.Ports}}")
+	if c.native {
+		containers, err := c.nativePs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return serviceStatuses(containers), nil
+	}
+	cmd := exec.CommandContext(ctx, "docker", c.args("ps", "--format", "{{.Name}}\t{{.State}}\t{{.Status}}\t{{.Ports}}")...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -113,9 +203,17 @@ func (c *ComposeClient) Status(ctx context.Context) ([]ServiceStatus, error) {
 	return services, nil
 }
 
-// Ps runs docker compose ps and returns the raw output.
+// Ps runs docker compose ps and returns the raw output, or the same table
+// on the native path.
 func (c *ComposeClient) Ps(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", c.file, "ps")
+	if c.native {
+		containers, err := c.nativePs(ctx)
+		if err != nil {
+			return "", err
+		}
+		return formatPs(containers), nil
+	}
+	cmd := exec.CommandContext(ctx, "docker", c.args("ps")...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("docker compose ps: %w\n%s", err, output)
@@ -123,3 +221,37 @@ func (c *ComposeClient) Ps(ctx context.Context) (string, error) {
 
 	return string(output), nil
 }
+
+// ConfigHashes returns the config hash of each service in the compose file,
+// computed by compose the same way as the ConfigHashLabel it stamps on the
+// containers it creates. A container whose label differs runs an older or
+// hand-changed config. The native path hashes with the compose library
+// bosun is built with, so it matches containers that library (or a compose
+// CLI hashing the same way) created.
+func (c *ComposeClient) ConfigHashes(ctx context.Context) (map[string]string, error) {
+	if c.native {
+		return c.nativeConfigHashes(ctx)
+	}
+	cmd := exec.CommandContext(ctx, "docker", c.args("config", "--hash", "*")...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("docker compose config --hash: %w\n%s", err, stderr.String())
+	}
+	return parseConfigHashes(stdout.String()), nil
+}
+
+// parseConfigHashes parses docker compose config --hash output, one
+// "service hash" pair per line.
+func parseConfigHashes(output string) map[string]string {
+	hashes := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			hashes[fields[0]] = fields[1]
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,241 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/flags"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
+)
+
+// ComposeNativeEnv selects the native compose path for every ComposeClient
+// when set to true (see WithNative).
+const ComposeNativeEnv = "BOSUN_COMPOSE_NATIVE"
+
+// WithNative runs up, down, restart, ps, and config hashing in process,
+// loading the compose file with compose-go and driving the daemon through
+// the compose SDK and the Docker API, instead of shelling out to the
+// docker compose CLI. The daemon is the one the docker CLI would use
+// (DOCKER_HOST or the current docker context).
+func (c *ComposeClient) WithNative(native bool) *ComposeClient {
+	c.native = native
+	return c
+}
+
+// nativeFromEnv reports whether ComposeNativeEnv selects the native path.
+func nativeFromEnv() bool {
+	return os.Getenv(ComposeNativeEnv) == "true"
+}
+
+// loadProject loads the compose file with compose-go the way docker compose
+// does: interpolated from the environment and a .env file next to it, with
+// the client's project name and profiles, and stamped with the labels
+// compose puts on the containers it creates.
+func (c *ComposeClient) loadProject(ctx context.Context, services ...string) (*types.Project, error) {
+	opts, err := cli.NewProjectOptions([]string{c.file},
+		cli.WithOsEnv,
+		cli.WithDotEnv,
+		cli.WithName(c.project),
+		cli.WithProfiles(c.profiles),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("compose options: %w", err)
+	}
+	project, err := opts.LoadProject(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", c.file, err)
+	}
+	if project, err = project.WithServicesEnabled(services...); err != nil {
+		return nil, err
+	}
+
+	for name, svc := range project.Services {
+		svc.CustomLabels = map[string]string{
+			api.ProjectLabel:     project.Name,
+			api.ServiceLabel:     name,
+			api.VersionLabel:     api.ComposeVersion,
+			api.WorkingDirLabel:  project.WorkingDir,
+			api.ConfigFilesLabel: strings.Join(project.ComposeFiles, ","),
+			api.OneoffLabel:      "False",
+		}
+		project.Services[name] = svc
+	}
+	return project.WithSelectedServices(services)
+}
+
+// composeService connects the compose SDK to the Docker daemon. Its
+// progress output is discarded, as the CLI path's is on success. Call
+// done when finished to release the daemon connection.
+func composeService() (svc api.Compose, done func(), err error) {
+	dockerCli, err := command.NewDockerCli(command.WithCombinedStreams(io.Discard))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create docker cli: %w", err)
+	}
+	if err := dockerCli.Initialize(flags.NewClientOptions()); err != nil {
+		return nil, nil, fmt.Errorf("connect to docker: %w", err)
+	}
+	return compose.NewComposeService(dockerCli), func() { _ = dockerCli.Client().Close() }, nil
+}
+
+// nativeUp creates and starts the project's services, recreating those
+// whose config changed, as docker compose up -d does.
+func (c *ComposeClient) nativeUp(ctx context.Context, services ...string) error {
+	return c.nativeUpWith(ctx, false, services...)
+}
+
+// nativeDeploy is nativeUp that also removes orphaned containers and waits
+// for the services to be running or healthy, as docker compose up -d
+// --remove-orphans --wait does.
+func (c *ComposeClient) nativeDeploy(ctx context.Context) error {
+	return c.nativeUpWith(ctx, true)
+}
+
+func (c *ComposeClient) nativeUpWith(ctx context.Context, deploy bool, services ...string) error {
+	project, err := c.loadProject(ctx, services...)
+	if err != nil {
+		return err
+	}
+	svc, closeSvc, err := composeService()
+	if err != nil {
+		return err
+	}
+	defer closeSvc()
+	err = svc.Up(ctx, project, api.UpOptions{
+		Create: api.CreateOptions{
+			Services:             services,
+			Recreate:             api.RecreateDiverged,
+			RecreateDependencies: api.RecreateDiverged,
+			Inherit:              true,
+			AssumeYes:            true,
+			RemoveOrphans:        deploy,
+		},
+		Start: api.StartOptions{Project: project, Services: services, Wait: deploy},
+	})
+	if err != nil {
+		return fmt.Errorf("compose up: %w", err)
+	}
+	return nil
+}
+
+// nativeDown stops and removes the project's containers and networks.
+func (c *ComposeClient) nativeDown(ctx context.Context) error {
+	project, err := c.loadProject(ctx)
+	if err != nil {
+		return err
+	}
+	svc, closeSvc, err := composeService()
+	if err != nil {
+		return err
+	}
+	defer closeSvc()
+	if err := svc.Down(ctx, project.Name, api.DownOptions{Project: project}); err != nil {
+		return fmt.Errorf("compose down: %w", err)
+	}
+	return nil
+}
+
+// nativeRestart restarts the project's services, or the given ones.
+func (c *ComposeClient) nativeRestart(ctx context.Context, services ...string) error {
+	project, err := c.loadProject(ctx)
+	if err != nil {
+		return err
+	}
+	svc, closeSvc, err := composeService()
+	if err != nil {
+		return err
+	}
+	defer closeSvc()
+	if err := svc.Restart(ctx, project.Name, api.RestartOptions{Project: project, Services: services}); err != nil {
+		return fmt.Errorf("compose restart: %w", err)
+	}
+	return nil
+}
+
+// nativePs lists the project's running containers.
+func (c *ComposeClient) nativePs(ctx context.Context) ([]api.ContainerSummary, error) {
+	project, err := c.loadProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	svc, closeSvc, err := composeService()
+	if err != nil {
+		return nil, err
+	}
+	defer closeSvc()
+	containers, err := svc.Ps(ctx, project.Name, api.PsOptions{Project: project})
+	if err != nil {
+		return nil, fmt.Errorf("compose ps: %w", err)
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+	return containers, nil
+}
+
+// serviceStatuses converts compose SDK container summaries to statuses.
+func serviceStatuses(containers []api.ContainerSummary) []ServiceStatus {
+	statuses := make([]ServiceStatus, 0, len(containers))
+	for _, ctr := range containers {
+		statuses = append(statuses, ServiceStatus{
+			Name:    ctr.Name,
+			State:   ctr.State,
+			Status:  ctr.Status,
+			Ports:   formatPublishers(ctr.Publishers),
+			Running: ctr.State == "running",
+		})
+	}
+	return statuses
+}
+
+// formatPublishers formats published ports as docker compose ps does,
+// e.g. "0.0.0.0:8080->80/tcp, 443/tcp".
+func formatPublishers(publishers api.PortPublishers) string {
+	ports := make([]string, 0, len(publishers))
+	for _, p := range publishers {
+		if p.PublishedPort == 0 {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.TargetPort, p.Protocol))
+			continue
+		}
+		ports = append(ports, fmt.Sprintf("%s:%d->%d/%s", p.URL, p.PublishedPort, p.TargetPort, p.Protocol))
+	}
+	return strings.Join(ports, ", ")
+}
+
+// formatPs renders container summaries as the docker compose ps table.
+func formatPs(containers []api.ContainerSummary) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tIMAGE\tSERVICE\tSTATUS\tPORTS")
+	for _, ctr := range containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ctr.Name, ctr.Image, ctr.Service, ctr.Status, formatPublishers(ctr.Publishers))
+	}
+	_ = w.Flush()
+	return buf.String()
+}
+
+// nativeConfigHashes computes each enabled service's config hash with the
+// compose library's ServiceHash, the hash compose stamps on the containers
+// it creates as ConfigHashLabel. No daemon or CLI is needed.
+func (c *ComposeClient) nativeConfigHashes(ctx context.Context) (map[string]string, error) {
+	project, err := c.loadProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(project.Services))
+	for name, svc := range project.Services {
+		hash, err := compose.ServiceHash(svc)
+		if err != nil {
+			return nil, fmt.Errorf("hash %s: %w", name, err)
+		}
+		hashes[name] = hash
+	}
+	return hashes, nil
+}
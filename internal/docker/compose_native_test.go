@@ -0,0 +1,167 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nativeTestCompose = `services:
+  web:
+    image: nginx:${NGINX_TAG:-alpine}
+    ports:
+      - "8080:80"
+  worker:
+    image: busybox
+    deploy:
+      replicas: 2
+  debug:
+    image: busybox
+    profiles: [dev]
+`
+
+func writeNativeCompose(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "media.yml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestNewComposeClient_NativeFromEnv(t *testing.T) {
+	file := writeNativeCompose(t, nativeTestCompose)
+
+	client, err := NewComposeClient(file)
+	require.NoError(t, err)
+	assert.False(t, client.Native())
+
+	t.Setenv(ComposeNativeEnv, "true")
+	client, err = NewComposeClient(file)
+	require.NoError(t, err)
+	assert.True(t, client.Native())
+}
+
+func TestComposeClient_LoadProject(t *testing.T) {
+	file := writeNativeCompose(t, nativeTestCompose)
+	ctx := context.Background()
+
+	client, err := NewComposeClient(file)
+	require.NoError(t, err)
+	project, err := client.WithProject("media").loadProject(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "media", project.Name)
+	assert.ElementsMatch(t, []string{"web", "worker"}, project.ServiceNames(), "services in inactive profiles are left out")
+	assert.Equal(t, "nginx:alpine", project.Services["web"].Image, "variables are interpolated")
+	assert.Equal(t, "media", project.Services["web"].CustomLabels[api.ProjectLabel])
+	assert.Equal(t, "web", project.Services["web"].CustomLabels[api.ServiceLabel])
+
+	project, err = client.WithProfiles("dev").loadProject(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, project.ServiceNames(), "debug")
+
+	project, err = client.WithProfiles().loadProject(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, project.ServiceNames())
+
+	bad, err := NewComposeClient(writeNativeCompose(t, "services:\n  web:\n    ports: {bad: map}\n"))
+	require.NoError(t, err)
+	_, err = bad.loadProject(ctx)
+	assert.Error(t, err)
+}
+
+func TestComposeClient_NativeConfigHashes(t *testing.T) {
+	ctx := context.Background()
+	hashesOf := func(content string) map[string]string {
+		t.Helper()
+		client, err := NewComposeClient(writeNativeCompose(t, content))
+		require.NoError(t, err)
+		hashes, err := client.WithProject("media").WithNative(true).ConfigHashes(ctx)
+		require.NoError(t, err)
+		return hashes
+	}
+
+	base := hashesOf(nativeTestCompose)
+	assert.Len(t, base, 2)
+	assert.Len(t, base["web"], 64, "sha256 hex")
+	assert.Equal(t, base, hashesOf(nativeTestCompose), "hashes are stable")
+
+	t.Setenv("NGINX_TAG", "1.27")
+	changed := hashesOf(nativeTestCompose)
+	assert.NotEqual(t, base["web"], changed["web"], "a changed image changes the hash")
+	assert.Equal(t, base["worker"], changed["worker"])
+
+	replicas := hashesOf(`services:
+  worker:
+    image: busybox
+    deploy:
+      replicas: 5
+`)
+	assert.Equal(t, base["worker"], replicas["worker"], "replicas don't change the hash, as in compose")
+}
+
+func TestFormatPs(t *testing.T) {
+	containers := []api.ContainerSummary{{
+		Name:    "media-web-1",
+		Image:   "nginx:alpine",
+		Service: "web",
+		State:   "running",
+		Status:  "Up 5 minutes",
+		Publishers: api.PortPublishers{
+			{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 8080, Protocol: "tcp"},
+			{TargetPort: 443, Protocol: "tcp"},
+		},
+	}}
+
+	assert.Equal(t, "0.0.0.0:8080->80/tcp, 443/tcp", formatPublishers(containers[0].Publishers))
+	assert.Equal(t, []ServiceStatus{{
+		Name:    "media-web-1",
+		State:   "running",
+		Status:  "Up 5 minutes",
+		Ports:   "0.0.0.0:8080->80/tcp, 443/tcp",
+		Running: true,
+	}}, serviceStatuses(containers))
+
+	table := formatPs(containers)
+	assert.Contains(t, table, "NAME")
+	assert.Contains(t, table, "media-web-1")
+	assert.Contains(t, table, "0.0.0.0:8080->80/tcp")
+}
+
+// TestComposeClient_NativeIntegration runs the native path against a real
+// daemon and checks its hashes match what the containers it created carry.
+func TestComposeClient_NativeIntegration(t *testing.T) {
+	if os.Getenv("DOCKER_INTEGRATION_TESTS") != "1" {
+		t.Skip("Skipping integration test. Set DOCKER_INTEGRATION_TESTS=1 to run.")
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("Docker not available")
+	}
+
+	file := writeNativeCompose(t, "services:\n  test-nginx:\n    image: nginx:alpine\n")
+	client, err := NewComposeClient(file)
+	require.NoError(t, err)
+	client.WithProject("bosun-native-test").WithNative(true)
+	ctx := context.Background()
+
+	require.NoError(t, client.Up(ctx))
+	t.Cleanup(func() { _ = client.Down(context.Background()) })
+
+	hashes, err := client.ConfigHashes(ctx)
+	require.NoError(t, err)
+	out, err := exec.Command("docker", "ps", "--filter", "label="+ProjectLabel+"=bosun-native-test",
+		"--format", `{{.Label "`+ConfigHashLabel+`"}}`).Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), hashes["test-nginx"])
+
+	services, err := client.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.True(t, services[0].Running)
+
+	require.NoError(t, client.Restart(ctx, "test-nginx"))
+}
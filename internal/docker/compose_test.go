@@ -326,3 +326,26 @@ func TestComposeClient_RestartCommandBuilding(t *testing.T) {
 		})
 	}
 }
+
+func TestComposeClient_Args(t *testing.T) {
+	t.Run("without project", func(t *testing.T) {
+		c := &ComposeClient{file: "compose.yml"}
+		assert.Equal(t, []string{"compose", "-f", "compose.yml", "up", "-d"}, c.args("up", "-d"))
+	})
+
+	t.Run("with project", func(t *testing.T) {
+		c := (&ComposeClient{file: "output/compose/media.yml"}).WithProject("media")
+		assert.Equal(t, []string{"compose", "-p", "media", "-f", "output/compose/media.yml", "ps"}, c.args("ps"))
+	})
+
+	t.Run("with profiles", func(t *testing.T) {
+		c := (&ComposeClient{file: "compose.yml"}).WithProfiles("media", "dev")
+		assert.Equal(t, []string{"compose", "-f", "compose.yml", "--profile", "media", "--profile", "dev", "up", "-d"}, c.args("up", "-d"))
+	})
+}
+
+func TestParseConfigHashes(t *testing.T) {
+	output := "plex 3f9c2e1a0b\npostgres 9d8e7f6a5b\n\nnot a hash line\n"
+	assert.Equal(t, map[string]string{"plex": "3f9c2e1a0b", "postgres": "9d8e7f6a5b"}, parseConfigHashes(output))
+	assert.Empty(t, parseConfigHashes(""))
+}
@@ -45,17 +45,34 @@ type PortBinding struct {
 //
 // For non-streaming use cases, prefer GetContainerLogs which handles cleanup automatically.
 func (c *Client) Logs(ctx context.Context, name string, tail int, follow bool) (io.ReadCloser, error) {
+	return c.LogsWith(ctx, name, LogOptions{Tail: tail, Follow: follow})
+}
+
+// LogOptions selects the logs LogsWith returns.
+type LogOptions struct {
+	Tail       int       // Last N lines; 0 returns all
+	Follow     bool      // Keep streaming new lines
+	Since      time.Time // Only lines after this time; zero returns all
+	Timestamps bool      // Prefix each line with its RFC3339Nano timestamp
+}
+
+// LogsWith returns a reader for container logs selected by opts. The same
+// rules as Logs apply: the caller MUST close the reader.
+func (c *Client) LogsWith(ctx context.Context, name string, opts LogOptions) (io.ReadCloser, error) {
 	tailStr := "all"
-	if tail > 0 {
-		tailStr = fmt.Sprintf("%d", tail)
+	if opts.Tail > 0 {
+		tailStr = fmt.Sprintf("%d", opts.Tail)
 	}
 
 	options := container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Follow:     follow,
+		Follow:     opts.Follow,
 		Tail:       tailStr,
-		Timestamps: false,
+		Timestamps: opts.Timestamps,
+	}
+	if !opts.Since.IsZero() {
+		options.Since = opts.Since.UTC().Format(time.RFC3339Nano)
 	}
 
 	reader, err := c.api.ContainerLogs(ctx, name, options)
@@ -68,6 +85,9 @@ func (c *Client) Logs(ctx context.Context, name string, tail int, follow bool) (
 
 // Inspect returns detailed information about a container.
 func (c *Client) Inspect(ctx context.Context, name string) (*ContainerDetails, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+
 	info, err := c.api.ContainerInspect(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("inspect container %s: %w", name, err)
@@ -138,6 +158,9 @@ func (c *Client) Remove(ctx context.Context, name string, force bool) error {
 		RemoveVolumes: false,
 	}
 
+	ctx, cancel := withTimeout(ctx, c.timeouts.Lifecycle)
+	defer cancel()
+
 	if err := c.api.ContainerRemove(ctx, name, options); err != nil {
 		return fmt.Errorf("remove container %s: %w", name, err)
 	}
@@ -147,14 +170,31 @@ func (c *Client) Remove(ctx context.Context, name string, force bool) error {
 
 // Start starts a stopped container.
 func (c *Client) Start(ctx context.Context, name string) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Lifecycle)
+	defer cancel()
+
 	if err := c.api.ContainerStart(ctx, name, container.StartOptions{}); err != nil {
 		return fmt.Errorf("start container %s: %w", name, err)
 	}
 	return nil
 }
 
+// Signal sends a signal (e.g. "SIGHUP") to a running container.
+func (c *Client) Signal(ctx context.Context, name, signal string) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Lifecycle)
+	defer cancel()
+
+	if err := c.api.ContainerKill(ctx, name, signal); err != nil {
+		return fmt.Errorf("signal container %s: %w", name, err)
+	}
+	return nil
+}
+
 // Exists checks if a container with the given name exists (running or stopped).
 func (c *Client) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+
 	containers, err := c.api.ContainerList(ctx, container.ListOptions{All: true})
 	if err != nil {
 		return false, fmt.Errorf("list containers: %w", err)
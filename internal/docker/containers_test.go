@@ -103,6 +103,23 @@ func TestClient_Logs(t *testing.T) {
 	}
 }
 
+func TestClient_LogsWith(t *testing.T) {
+	since := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	mock := NewMockDockerAPI()
+	mock.ContainerLogsFunc = func(ctx context.Context, containerName string, options container.LogsOptions) (io.ReadCloser, error) {
+		assert.Equal(t, "200", options.Tail)
+		assert.True(t, options.Follow)
+		assert.True(t, options.Timestamps)
+		assert.Equal(t, "2026-01-05T09:30:00Z", options.Since)
+		return io.NopCloser(bytes.NewReader([]byte("line\n"))), nil
+	}
+	client := NewClientWithAPI(mock)
+
+	reader, err := client.LogsWith(context.Background(), "traefik", LogOptions{Tail: 200, Follow: true, Since: since, Timestamps: true})
+	require.NoError(t, err)
+	defer reader.Close()
+}
+
 func TestClient_Inspect(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -2,12 +2,15 @@
 //
 // The Client type handles container lifecycle operations including listing,
 // inspecting, starting, stopping, and collecting stats. The ComposeClient
-// type manages docker-compose orchestration for stack deployments.
+// type manages docker-compose orchestration for stack deployments, through
+// the docker compose CLI or natively through compose-go and the compose SDK.
 //
 // # Interface Abstraction
 //
 // The DockerAPI interface abstracts the Docker SDK, enabling mock injection
-// for testing. Use NewTestableClient for test scenarios.
+// for testing. Use NewTestableClient for test scenarios, or the dockertest
+// package to fake a whole daemon (containers, health, stats, logs, events)
+// from one description.
 //
 // # Example
 //
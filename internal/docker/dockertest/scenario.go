@@ -0,0 +1,660 @@
+// Package dockertest builds fake Docker daemons for tests.
+//
+// A Scenario describes the containers a daemon has, with their health,
+// stats, logs, and events, and answers list, inspect, stats, logs, and
+// events calls consistently from that one description. Packages above
+// docker (cmd, daemon, reconcile) can test against a real *docker.Client
+// without wiring a mock per call:
+//
+//	client := dockertest.NewScenario().
+//	    WithHealthyContainer("web").
+//	    WithUnhealthy("api").
+//	    WithStats("web", 12.5, 256<<20, 1<<30).
+//	    Client()
+package dockertest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+// Operations that can be made to fail with WithError.
+const (
+	OpPing      = "ping"
+	OpList      = "list"
+	OpInspect   = "inspect"
+	OpImage     = "image_inspect"
+	OpLogs      = "logs"
+	OpStart     = "start"
+	OpRestart   = "restart"
+	OpRemove    = "remove"
+	OpKill      = "kill"
+	OpExec      = "exec"
+	OpStats     = "stats"
+	OpDiskUsage = "disk_usage"
+	OpInfo      = "info"
+	OpEvents    = "events"
+)
+
+// startedAt is when scenario containers started unless set otherwise.
+var startedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Container is one container in a scenario.
+type Container struct {
+	Name  string
+	Image string // Default: <name>:latest
+	// RepoDigests are the registry digests of the container's image, e.g.
+	// nginx@sha256:..., as image inspect reports them.
+	RepoDigests []string
+	// State is the Docker state: running, exited, restarting, ...
+	State string
+	// Health is the healthcheck status (healthy, unhealthy, starting), or
+	// empty for containers without a healthcheck.
+	Health   string
+	ExitCode int
+	Restarts int
+	Started  time.Time
+	Labels   map[string]string
+	Ports    []container.Port
+
+	// Logs is what the logs endpoint returns.
+	Logs string
+
+	// Signals records the signals sent to the container with ContainerKill.
+	Signals []string
+
+	// Execs records the commands run in the container with exec. Each
+	// prints ExecOutput (to stdout) and exits with ExecExitCode.
+	Execs        [][]string
+	ExecOutput   string
+	ExecExitCode int
+
+	CPUPercent float64
+	MemUsage   uint64
+	MemLimit   uint64
+}
+
+// Scenario is a fake Docker daemon. It implements docker.DockerAPI.
+// Builder methods return the scenario so they can be chained; lifecycle
+// calls (start, restart, remove) update it like a daemon would.
+type Scenario struct {
+	mu         sync.Mutex
+	containers []*Container
+	events     []events.Message
+	info       system.Info
+	diskUsage  types.DiskUsage
+	errs       map[string]error
+	calls      map[string]int
+	execs      map[string]*scenarioExec
+}
+
+// scenarioExec is an exec created in a scenario container.
+type scenarioExec struct {
+	container *Container
+	tty       bool
+	done      bool
+}
+
+// Verify Scenario implements DockerAPI.
+var _ docker.DockerAPI = (*Scenario)(nil)
+
+// NewScenario returns a daemon with no containers.
+func NewScenario() *Scenario {
+	return &Scenario{
+		info:  system.Info{ServerVersion: "28.5.2", OperatingSystem: "dockertest"},
+		errs:  make(map[string]error),
+		calls: make(map[string]int),
+		execs: make(map[string]*scenarioExec),
+	}
+}
+
+// Client returns a docker.Client backed by the scenario, without retries
+// or a circuit breaker.
+func (s *Scenario) Client(opts ...docker.ClientOption) *docker.Client {
+	return docker.NewClientWithAPI(s, opts...)
+}
+
+// WithContainer adds a container, replacing one with the same name.
+func (s *Scenario) WithContainer(c Container) *Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.Image == "" {
+		c.Image = c.Name + ":latest"
+	}
+	if c.State == "" {
+		c.State = "running"
+	}
+	if c.Started.IsZero() && c.State == "running" {
+		c.Started = startedAt
+	}
+	s.containers = slices.DeleteFunc(s.containers, func(o *Container) bool { return o.Name == c.Name })
+	s.containers = append(s.containers, &c)
+	return s
+}
+
+// WithRunning adds a running container without a healthcheck.
+func (s *Scenario) WithRunning(name string) *Scenario {
+	return s.WithContainer(Container{Name: name, State: "running"})
+}
+
+// WithHealthyContainer adds a running container whose healthcheck passes.
+func (s *Scenario) WithHealthyContainer(name string) *Scenario {
+	return s.WithContainer(Container{Name: name, State: "running", Health: "healthy"})
+}
+
+// WithUnhealthy adds a running container whose healthcheck fails.
+func (s *Scenario) WithUnhealthy(name string) *Scenario {
+	return s.WithContainer(Container{Name: name, State: "running", Health: "unhealthy"})
+}
+
+// WithStopped adds a container that exited with exitCode.
+func (s *Scenario) WithStopped(name string, exitCode int) *Scenario {
+	return s.WithContainer(Container{Name: name, State: "exited", ExitCode: exitCode})
+}
+
+// WithImage sets a container's image.
+func (s *Scenario) WithImage(name, image string) *Scenario {
+	return s.update(name, func(c *Container) { c.Image = image })
+}
+
+// WithStats sets a container's CPU (percent of one core) and memory usage.
+func (s *Scenario) WithStats(name string, cpuPercent float64, memUsage, memLimit uint64) *Scenario {
+	return s.update(name, func(c *Container) {
+		c.CPUPercent, c.MemUsage, c.MemLimit = cpuPercent, memUsage, memLimit
+	})
+}
+
+// WithLogs sets a container's logs.
+func (s *Scenario) WithLogs(name string, lines ...string) *Scenario {
+	return s.update(name, func(c *Container) { c.Logs = strings.Join(lines, "\n") + "\n" })
+}
+
+// WithEvent adds a container event. "die" events carry the container's exit code.
+func (s *Scenario) WithEvent(name string, action events.Action, at time.Time) *Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attrs := map[string]string{"name": name}
+	if c := s.find(name); c != nil {
+		attrs["image"] = c.Image
+		if action == events.ActionDie {
+			attrs["exitCode"] = strconv.Itoa(c.ExitCode)
+		}
+	}
+	s.events = append(s.events, events.Message{
+		Type:     events.ContainerEventType,
+		Action:   action,
+		Actor:    events.Actor{ID: idFor(name), Attributes: attrs},
+		Time:     at.Unix(),
+		TimeNano: at.UnixNano(),
+	})
+	return s
+}
+
+// WithInfo sets what the info endpoint returns.
+func (s *Scenario) WithInfo(info system.Info) *Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = info
+	return s
+}
+
+// WithDiskUsage sets what the disk usage endpoint returns.
+func (s *Scenario) WithDiskUsage(usage types.DiskUsage) *Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diskUsage = usage
+	return s
+}
+
+// WithError makes every call of op (OpPing, OpList, ...) fail with err.
+func (s *Scenario) WithError(op string, err error) *Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs[op] = err
+	return s
+}
+
+// Calls returns how many times op was called.
+func (s *Scenario) Calls(op string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[op]
+}
+
+// Container returns a copy of the named container's current state.
+func (s *Scenario) Container(name string) (Container, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.find(name); c != nil {
+		return *c, true
+	}
+	return Container{}, false
+}
+
+// update applies fn to the named container, adding it as running if missing.
+func (s *Scenario) update(name string, fn func(*Container)) *Scenario {
+	if _, ok := s.Container(name); !ok {
+		s.WithRunning(name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.find(name))
+	return s
+}
+
+// find returns the container with the given name or ID. Callers hold mu.
+func (s *Scenario) find(ref string) *Container {
+	ref = strings.TrimPrefix(ref, "/")
+	for _, c := range s.containers {
+		if c.Name == ref || (len(ref) >= 12 && strings.HasPrefix(idFor(c.Name), ref)) {
+			return c
+		}
+	}
+	return nil
+}
+
+// call records a call of op and returns its configured error.
+func (s *Scenario) call(op string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[op]++
+	return s.errs[op]
+}
+
+// lookup records a call of op on a container and returns the container.
+func (s *Scenario) lookup(op, ref string) (*Container, error) {
+	if err := s.call(op); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(ref)
+	if c == nil {
+		return nil, errdefs.NotFound(fmt.Errorf("No such container: %s", ref))
+	}
+	return c, nil
+}
+
+// imageIDFor derives a stable image ID from a container name.
+func imageIDFor(name string) string {
+	return "sha256:" + idFor("image:"+name)
+}
+
+// idFor derives a stable 64-character ID from a container name.
+func idFor(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+// Ping implements DockerAPI.
+func (s *Scenario) Ping(ctx context.Context) (types.Ping, error) {
+	if err := s.call(OpPing); err != nil {
+		return types.Ping{}, err
+	}
+	return types.Ping{APIVersion: "1.51", OSType: "linux"}, nil
+}
+
+// ContainerList implements DockerAPI. Without options.All only running
+// containers are listed.
+func (s *Scenario) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	if err := s.call(OpList); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []container.Summary
+	for _, c := range s.containers {
+		if !options.All && c.State != "running" {
+			continue
+		}
+		list = append(list, container.Summary{
+			ID:      idFor(c.Name),
+			Names:   []string{"/" + c.Name},
+			Image:   c.Image,
+			ImageID: imageIDFor(c.Name),
+			State:   c.State,
+			Status:  status(c),
+			Created: startedAt.Unix(),
+			Labels:  c.Labels,
+			Ports:   c.Ports,
+		})
+	}
+	return list, nil
+}
+
+// ContainerInspect implements DockerAPI.
+func (s *Scenario) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	c, err := s.lookup(OpInspect, containerID)
+	if err != nil {
+		return container.InspectResponse{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := &container.State{
+		Status:     container.ContainerState(c.State),
+		Running:    c.State == "running",
+		Restarting: c.State == "restarting",
+		ExitCode:   c.ExitCode,
+	}
+	if !c.Started.IsZero() {
+		state.StartedAt = c.Started.Format(time.RFC3339Nano)
+	}
+	if c.Health != "" {
+		state.Health = &container.Health{Status: container.HealthStatus(c.Health)}
+	}
+
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:           idFor(c.Name),
+			Name:         "/" + c.Name,
+			Created:      startedAt.Format(time.RFC3339Nano),
+			State:        state,
+			RestartCount: c.Restarts,
+			Driver:       "overlay2",
+		},
+		Config: &container.Config{Image: c.Image, Labels: c.Labels},
+		NetworkSettings: &container.NetworkSettings{
+			NetworkSettingsBase: container.NetworkSettingsBase{ //nolint:staticcheck // deprecated but still required
+				Ports: nat.PortMap{},
+			},
+			Networks: map[string]*network.EndpointSettings{"bridge": {}},
+		},
+		Mounts: []container.MountPoint{},
+	}, nil
+}
+
+// ImageInspect implements DockerAPI. Each container runs its own image,
+// whose ID is derived from the container name.
+func (s *Scenario) ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error) {
+	if err := s.call(OpImage); err != nil {
+		return image.InspectResponse{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.containers {
+		if imageIDFor(c.Name) == imageID {
+			return image.InspectResponse{ID: imageID, RepoTags: []string{c.Image}, RepoDigests: c.RepoDigests}, nil
+		}
+	}
+	return image.InspectResponse{}, errdefs.NotFound(fmt.Errorf("No such image: %s", imageID))
+}
+
+// ContainerLogs implements DockerAPI.
+func (s *Scenario) ContainerLogs(ctx context.Context, ctr string, options container.LogsOptions) (io.ReadCloser, error) {
+	c, err := s.lookup(OpLogs, ctr)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return io.NopCloser(strings.NewReader(c.Logs)), nil
+}
+
+// ContainerStart implements DockerAPI.
+func (s *Scenario) ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error {
+	c, err := s.lookup(OpStart, containerID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.State, c.ExitCode, c.Started = "running", 0, time.Now()
+	return nil
+}
+
+// ContainerRestart implements DockerAPI.
+func (s *Scenario) ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error {
+	c, err := s.lookup(OpRestart, containerID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.State, c.ExitCode, c.Started = "running", 0, time.Now()
+	c.Restarts++
+	return nil
+}
+
+// ContainerRemove implements DockerAPI. Running containers need options.Force.
+func (s *Scenario) ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error {
+	c, err := s.lookup(OpRemove, containerID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.State == "running" && !options.Force {
+		return errdefs.Conflict(fmt.Errorf("cannot remove container %q: container is running", c.Name))
+	}
+	s.containers = slices.DeleteFunc(s.containers, func(o *Container) bool { return o == c })
+	return nil
+}
+
+// ContainerKill implements DockerAPI. The signal is recorded in the
+// container's Signals; SIGKILL (the default) stops it with exit code 137.
+func (s *Scenario) ContainerKill(ctx context.Context, containerID, signal string) error {
+	c, err := s.lookup(OpKill, containerID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.State != "running" {
+		return errdefs.Conflict(fmt.Errorf("cannot kill container %q: container is not running", c.Name))
+	}
+	c.Signals = append(c.Signals, signal)
+	switch signal {
+	case "", "KILL", "SIGKILL", "9":
+		c.State, c.ExitCode = "exited", 137
+	}
+	return nil
+}
+
+// ContainerExecCreate implements DockerAPI. The command is recorded in the
+// container's Execs.
+func (s *Scenario) ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+	c, err := s.lookup(OpExec, containerID)
+	if err != nil {
+		return container.ExecCreateResponse{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.State != "running" {
+		return container.ExecCreateResponse{}, errdefs.Conflict(fmt.Errorf("container %s is not running", idFor(c.Name)))
+	}
+	c.Execs = append(c.Execs, options.Cmd)
+	id := fmt.Sprintf("exec-%d", len(s.execs)+1)
+	s.execs[id] = &scenarioExec{container: c, tty: options.Tty}
+	return container.ExecCreateResponse{ID: id}, nil
+}
+
+// ContainerExecAttach implements DockerAPI. The exec discards its input and
+// writes the container's ExecOutput, multiplexed unless it has a TTY.
+func (s *Scenario) ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error) {
+	if err := s.call(OpExec); err != nil {
+		return types.HijackedResponse{}, err
+	}
+	s.mu.Lock()
+	e, ok := s.execs[execID]
+	var output string
+	if ok {
+		output = e.container.ExecOutput
+	}
+	s.mu.Unlock()
+	if !ok {
+		return types.HijackedResponse{}, errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		go func() { _, _ = io.Copy(io.Discard, server) }()
+		var w io.Writer = server
+		if !e.tty {
+			w = stdcopy.NewStdWriter(server, stdcopy.Stdout)
+		}
+		_, _ = io.WriteString(w, output)
+		s.mu.Lock()
+		e.done = true
+		s.mu.Unlock()
+	}()
+	return types.NewHijackedResponse(client, ""), nil
+}
+
+// ContainerExecInspect implements DockerAPI. Finished execs report the
+// container's ExecExitCode.
+func (s *Scenario) ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error) {
+	if err := s.call(OpExec); err != nil {
+		return container.ExecInspect{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.execs[execID]
+	if !ok {
+		return container.ExecInspect{}, errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+	inspect := container.ExecInspect{ExecID: execID, ContainerID: idFor(e.container.Name), Running: !e.done}
+	if e.done {
+		inspect.ExitCode = e.container.ExecExitCode
+	}
+	return inspect, nil
+}
+
+// ContainerExecResize implements DockerAPI.
+func (s *Scenario) ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error {
+	return s.call(OpExec)
+}
+
+// ContainerStats implements DockerAPI. The stats decode to the container's
+// CPUPercent, MemUsage, and MemLimit.
+func (s *Scenario) ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+	c, err := s.lookup(OpStats, containerID)
+	if err != nil {
+		return container.StatsResponseReader{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// One CPU with a 1e9 system delta, so usage deltas are percent * 1e7.
+	const systemDelta = 1_000_000_000
+	stats := map[string]any{
+		"cpu_stats": map[string]any{
+			"cpu_usage":        map[string]any{"total_usage": uint64(c.CPUPercent * 1e7), "percpu_usage": []uint64{0}},
+			"system_cpu_usage": uint64(systemDelta),
+		},
+		"precpu_stats": map[string]any{
+			"cpu_usage":        map[string]any{"total_usage": 0},
+			"system_cpu_usage": 0,
+		},
+		"memory_stats": map[string]any{"usage": c.MemUsage, "limit": c.MemLimit},
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return container.StatsResponseReader{}, err
+	}
+	return container.StatsResponseReader{Body: io.NopCloser(bytes.NewReader(data)), OSType: "linux"}, nil
+}
+
+// DiskUsage implements DockerAPI.
+func (s *Scenario) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	if err := s.call(OpDiskUsage); err != nil {
+		return types.DiskUsage{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.diskUsage, nil
+}
+
+// Info implements DockerAPI.
+func (s *Scenario) Info(ctx context.Context) (system.Info, error) {
+	if err := s.call(OpInfo); err != nil {
+		return system.Info{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := s.info
+	info.Containers = len(s.containers)
+	info.ContainersRunning = 0
+	for _, c := range s.containers {
+		if c.State == "running" {
+			info.ContainersRunning++
+		}
+	}
+	return info, nil
+}
+
+// Events implements DockerAPI. It delivers the scenario's events then ends
+// the stream, like a bounded (since/until) query does.
+func (s *Scenario) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	err := s.call(OpEvents)
+	s.mu.Lock()
+	msgs := slices.Clone(s.events)
+	s.mu.Unlock()
+	if err != nil {
+		msgs = nil
+	}
+
+	msgCh := make(chan events.Message)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		for _, msg := range msgs {
+			select {
+			case msgCh <- msg:
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			}
+		}
+		errCh <- err
+	}()
+	return msgCh, errCh
+}
+
+// Close implements DockerAPI.
+func (s *Scenario) Close() error {
+	return nil
+}
+
+// status formats a container's human-readable status like docker ps.
+func status(c *Container) string {
+	switch c.State {
+	case "running":
+		if c.Health != "" {
+			return fmt.Sprintf("Up 10 minutes (%s)", c.Health)
+		}
+		return "Up 10 minutes"
+	case "exited":
+		return fmt.Sprintf("Exited (%d) 5 minutes ago", c.ExitCode)
+	}
+	return c.State
+}
@@ -0,0 +1,154 @@
+package dockertest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+func TestScenario_Client(t *testing.T) {
+	ctx := context.Background()
+	s := NewScenario().
+		WithHealthyContainer("web").
+		WithUnhealthy("api").
+		WithStopped("worker", 137).
+		WithImage("web", "nginx:1.27").
+		WithStats("web", 12.5, 256<<20, 1<<30).
+		WithLogs("api", "listening on :8080", "healthcheck failed")
+	client := s.Client()
+
+	running, err := client.ListContainers(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, running, 2)
+	assert.Equal(t, "web", running[0].Name)
+	assert.Equal(t, "nginx:1.27", running[0].Image)
+	assert.Equal(t, "healthy", running[0].Health)
+	assert.Equal(t, "unhealthy", running[1].Health)
+
+	total, err := client.ListContainers(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, total, 3)
+
+	_, _, unhealthy, err := client.CountContainers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, unhealthy)
+
+	stats, err := client.GetContainerStats(ctx, "web")
+	require.NoError(t, err)
+	assert.InDelta(t, 12.5, stats.CPUPercent, 0.01)
+	assert.InDelta(t, 25.0, stats.MemPercent, 0.01)
+
+	logs, err := client.GetContainerLogs(ctx, "api", 10)
+	require.NoError(t, err)
+	assert.Contains(t, logs, "healthcheck failed")
+
+	details, err := client.Inspect(ctx, "worker")
+	require.NoError(t, err)
+	assert.Equal(t, "exited", details.State)
+	assert.Equal(t, "worker:latest", details.Image)
+}
+
+func TestScenario_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := NewScenario().WithStopped("worker", 1).WithRunning("web")
+	client := s.Client()
+
+	require.NoError(t, client.Start(ctx, "worker"))
+	assert.True(t, client.IsContainerRunning(ctx, "worker"))
+
+	require.NoError(t, client.RestartContainer(ctx, "web"))
+	web, _ := s.Container("web")
+	assert.Equal(t, 1, web.Restarts)
+
+	assert.Error(t, client.Remove(ctx, "web", false), "running containers need force")
+	require.NoError(t, client.RemoveContainer(ctx, "web"))
+	_, ok := s.Container("web")
+	assert.False(t, ok)
+
+	err := client.Start(ctx, "missing")
+	assert.True(t, errdefs.IsNotFound(err))
+	assert.Equal(t, 3, s.Calls(OpStart)+s.Calls(OpRestart))
+}
+
+func TestScenario_Signal(t *testing.T) {
+	ctx := context.Background()
+	s := NewScenario().WithRunning("traefik").WithStopped("worker", 0)
+	client := s.Client()
+
+	require.NoError(t, client.Signal(ctx, "traefik", "SIGHUP"))
+	traefik, _ := s.Container("traefik")
+	assert.Equal(t, []string{"SIGHUP"}, traefik.Signals)
+	assert.Equal(t, "running", traefik.State)
+
+	require.NoError(t, client.Signal(ctx, "traefik", "SIGKILL"))
+	traefik, _ = s.Container("traefik")
+	assert.Equal(t, "exited", traefik.State)
+	assert.Equal(t, 137, traefik.ExitCode)
+
+	err := client.Signal(ctx, "worker", "SIGHUP")
+	assert.True(t, errdefs.IsConflict(err), "stopped containers can't be signaled")
+	assert.Equal(t, 3, s.Calls(OpKill))
+}
+
+func TestScenario_Exec(t *testing.T) {
+	ctx := context.Background()
+	s := NewScenario().
+		WithContainer(Container{Name: "postgres", ExecOutput: "1 row\n", ExecExitCode: 3}).
+		WithStopped("worker", 0)
+	client := s.Client()
+
+	for _, tty := range []bool{false, true} {
+		var stdout, stderr bytes.Buffer
+		code, err := client.Exec(ctx, "postgres", docker.ExecOptions{
+			Cmd:    []string{"psql", "-c", "select 1"},
+			TTY:    tty,
+			Stdin:  strings.NewReader("\\q\n"),
+			Stdout: &stdout,
+			Stderr: &stderr,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, code)
+		assert.Equal(t, "1 row\n", stdout.String(), "tty=%v", tty)
+		assert.Empty(t, stderr.String())
+	}
+
+	postgres, _ := s.Container("postgres")
+	assert.Equal(t, [][]string{{"psql", "-c", "select 1"}, {"psql", "-c", "select 1"}}, postgres.Execs)
+
+	_, err := client.Exec(ctx, "worker", docker.ExecOptions{Cmd: []string{"sh"}})
+	assert.True(t, errdefs.IsConflict(err), "stopped containers can't exec")
+}
+
+func TestScenario_Events(t *testing.T) {
+	at := time.Now().Add(-time.Minute)
+	s := NewScenario().
+		WithStopped("db", 1).
+		WithEvent("db", events.ActionDie, at)
+
+	got, err := s.Client().RecentEvents(context.Background(), at.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "db", got[0].Name)
+	assert.Equal(t, "1", got[0].ExitCode)
+	assert.Equal(t, "db:latest", got[0].Image)
+}
+
+func TestScenario_WithError(t *testing.T) {
+	errDown := errors.New("daemon down")
+	s := NewScenario().WithRunning("web").WithError(OpList, errDown)
+
+	_, err := s.Client().ListContainers(context.Background(), false)
+	assert.ErrorIs(t, err, errDown)
+	assert.Equal(t, 1, s.Calls(OpList))
+	assert.NoError(t, s.Client().Ping(context.Background()))
+}
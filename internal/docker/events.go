@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ContainerEvent is a container lifecycle event reported by the Docker daemon.
+type ContainerEvent struct {
+	// Name is the container name.
+	Name string
+	// Action is the event action (e.g. "start", "stop", "die", "kill").
+	Action string
+	// Image is the container image at the time of the event.
+	Image string
+	// ExitCode is set on "die" events.
+	ExitCode string
+	Time     time.Time
+}
+
+// RecentEvents returns container events between since and now, oldest first.
+func (c *Client) RecentEvents(ctx context.Context, since time.Time) ([]ContainerEvent, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+
+	msgs, errs := c.api.Events(ctx, events.ListOptions{
+		Since:   strconv.FormatInt(since.Unix(), 10),
+		Until:   strconv.FormatInt(time.Now().Unix(), 10),
+		Filters: filters.NewArgs(filters.Arg("type", string(events.ContainerEventType))),
+	})
+
+	var result []ContainerEvent
+	for {
+		select {
+		case msg := <-msgs:
+			result = append(result, ContainerEvent{
+				Name:     msg.Actor.Attributes["name"],
+				Action:   string(msg.Action),
+				Image:    msg.Actor.Attributes["image"],
+				ExitCode: msg.Actor.Attributes["exitCode"],
+				Time:     eventTime(msg),
+			})
+		case err := <-errs:
+			if err == nil || errors.Is(err, io.EOF) {
+				return result, nil
+			}
+			return nil, fmt.Errorf("read events: %w", err)
+		}
+	}
+}
+
+// eventTime returns the event timestamp, preferring nanosecond precision.
+func eventTime(msg events.Message) time.Time {
+	if msg.TimeNano != 0 {
+		return time.Unix(0, msg.TimeNano)
+	}
+	return time.Unix(msg.Time, 0)
+}
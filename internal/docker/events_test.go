@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RecentEvents(t *testing.T) {
+	t.Run("collects container events until EOF", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		var gotOptions events.ListOptions
+		mock.EventsFunc = func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+			gotOptions = options
+			return mockEventStream([]events.Message{
+				{
+					Type:   events.ContainerEventType,
+					Action: events.ActionKill,
+					Actor:  events.Actor{Attributes: map[string]string{"name": "web", "image": "nginx:1.25"}},
+					Time:   1700000000,
+				},
+				{
+					Type:     events.ContainerEventType,
+					Action:   events.ActionDie,
+					Actor:    events.Actor{Attributes: map[string]string{"name": "web", "exitCode": "143"}},
+					TimeNano: 1700000001000000000,
+				},
+			}, io.EOF)
+		}
+
+		client := NewClientWithAPI(mock)
+		got, err := client.RecentEvents(context.Background(), time.Unix(1699990000, 0))
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		assert.Equal(t, "1699990000", gotOptions.Since)
+		assert.Equal(t, []string{"container"}, gotOptions.Filters.Get("type"))
+
+		assert.Equal(t, ContainerEvent{Name: "web", Action: "kill", Image: "nginx:1.25", Time: time.Unix(1700000000, 0)}, got[0])
+		assert.Equal(t, "die", got[1].Action)
+		assert.Equal(t, "143", got[1].ExitCode)
+		assert.Equal(t, time.Unix(1700000001, 0), got[1].Time)
+	})
+
+	t.Run("stream error", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.EventsFunc = func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+			return mockEventStream(nil, errors.New("connection reset"))
+		}
+
+		client := NewClientWithAPI(mock)
+		_, err := client.RecentEvents(context.Background(), time.Now().Add(-time.Hour))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection reset")
+	})
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// TerminalSize is the size of a TTY in character cells.
+type TerminalSize struct {
+	Height uint
+	Width  uint
+}
+
+// ExecOptions configures a command run with Exec.
+type ExecOptions struct {
+	Cmd        []string
+	User       string   // User to run as; empty uses the container's user
+	WorkingDir string   // Working directory; empty uses the container's
+	Env        []string // Extra KEY=value environment variables
+
+	// TTY allocates a pseudo-terminal. Its output is a single raw stream
+	// written to Stdout.
+	TTY bool
+	// Size is the initial TTY size; zero leaves it to the daemon.
+	Size TerminalSize
+	// Resize delivers TTY size changes until it is closed or Exec returns.
+	Resize <-chan TerminalSize
+
+	// Stdin, if set, is copied to the command's standard input.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Exec runs a command in a running container, streaming its input and
+// output, and returns the command's exit code. Like Logs it is not bound by
+// a client timeout; cancel ctx to stop waiting.
+func (c *Client) Exec(ctx context.Context, name string, opts ExecOptions) (int, error) {
+	if len(opts.Cmd) == 0 {
+		return 0, errors.New("exec: no command")
+	}
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	var consoleSize *[2]uint
+	if opts.TTY && opts.Size.Height > 0 && opts.Size.Width > 0 {
+		consoleSize = &[2]uint{opts.Size.Height, opts.Size.Width}
+	}
+
+	createCtx, cancelCreate := withTimeout(ctx, c.timeouts.Query)
+	created, err := c.api.ContainerExecCreate(createCtx, name, container.ExecOptions{
+		Cmd:          opts.Cmd,
+		User:         opts.User,
+		WorkingDir:   opts.WorkingDir,
+		Env:          opts.Env,
+		Tty:          opts.TTY,
+		ConsoleSize:  consoleSize,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	cancelCreate()
+	if err != nil {
+		return 0, fmt.Errorf("exec in container %s: %w", name, err)
+	}
+
+	resp, err := c.api.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{Tty: opts.TTY, ConsoleSize: consoleSize})
+	if err != nil {
+		return 0, fmt.Errorf("attach to exec in container %s: %w", name, err)
+	}
+	defer resp.Close()
+
+	if opts.Stdin != nil {
+		go func() {
+			// The command may exit before its input ends; write errors
+			// after that are expected.
+			_, _ = io.Copy(resp.Conn, opts.Stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	if opts.Resize != nil {
+		resizeCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go c.forwardResize(resizeCtx, created.ID, opts.Resize)
+	}
+
+	outputDone := make(chan error, 1)
+	go func() {
+		var err error
+		if opts.TTY {
+			_, err = io.Copy(stdout, resp.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(stdout, stderr, resp.Reader)
+		}
+		outputDone <- err
+	}()
+
+	select {
+	case err := <-outputDone:
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("read exec output from container %s: %w", name, err)
+		}
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	inspectCtx, cancel := withTimeout(ctx, c.timeouts.Query)
+	defer cancel()
+	inspect, err := c.api.ContainerExecInspect(inspectCtx, created.ID)
+	if err != nil {
+		return 0, fmt.Errorf("inspect exec in container %s: %w", name, err)
+	}
+	return inspect.ExitCode, nil
+}
+
+// forwardResize applies TTY size changes to an exec until ctx is done or
+// sizes is closed. Failed resizes are ignored; the next one may succeed.
+func (c *Client) forwardResize(ctx context.Context, execID string, sizes <-chan TerminalSize) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case size, ok := <-sizes:
+			if !ok {
+				return
+			}
+			_ = c.api.ContainerExecResize(ctx, execID, container.ResizeOptions{Height: size.Height, Width: size.Width})
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Exec(t *testing.T) {
+	t.Run("requires a command", func(t *testing.T) {
+		_, err := NewClientWithAPI(NewMockDockerAPI()).Exec(context.Background(), "postgres", ExecOptions{})
+		assert.ErrorContains(t, err, "no command")
+	})
+
+	t.Run("create errors name the container", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.ContainerExecCreateFunc = func(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+			return container.ExecCreateResponse{}, errors.New("container is not running")
+		}
+		_, err := NewClientWithAPI(mock).Exec(context.Background(), "postgres", ExecOptions{Cmd: []string{"sh"}})
+		assert.ErrorContains(t, err, "exec in container postgres: container is not running")
+		assert.Equal(t, 0, mock.ContainerExecAttachCalls)
+	})
+
+	t.Run("tty streams raw output and forwards resizes", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		var created container.ExecOptions
+		mock.ContainerExecCreateFunc = func(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+			created = options
+			return container.ExecCreateResponse{ID: "exec123"}, nil
+		}
+
+		resized := make(chan container.ResizeOptions, 1)
+		mock.ContainerExecResizeFunc = func(ctx context.Context, execID string, options container.ResizeOptions) error {
+			resized <- options
+			return nil
+		}
+
+		release := make(chan struct{})
+		mock.ContainerExecAttachFunc = func(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error) {
+			client, server := net.Pipe()
+			go func() {
+				defer server.Close()
+				<-release
+				_, _ = server.Write([]byte("root@postgres:/# "))
+			}()
+			return types.NewHijackedResponse(client, ""), nil
+		}
+		mock.ContainerExecInspectFunc = func(ctx context.Context, execID string) (container.ExecInspect, error) {
+			return container.ExecInspect{ExecID: execID, ExitCode: 130}, nil
+		}
+
+		sizes := make(chan TerminalSize, 1)
+		sizes <- TerminalSize{Height: 50, Width: 200}
+		go func() {
+			<-resized
+			close(release)
+		}()
+
+		var stdout bytes.Buffer
+		code, err := NewClientWithAPI(mock).Exec(context.Background(), "postgres", ExecOptions{
+			Cmd:    []string{"bash"},
+			User:   "postgres",
+			TTY:    true,
+			Size:   TerminalSize{Height: 24, Width: 80},
+			Resize: sizes,
+			Stdout: &stdout,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 130, code)
+		assert.Equal(t, "root@postgres:/# ", stdout.String())
+
+		assert.Equal(t, []string{"bash"}, created.Cmd)
+		assert.Equal(t, "postgres", created.User)
+		assert.True(t, created.Tty)
+		assert.False(t, created.AttachStdin, "stdin is only attached when given")
+		require.NotNil(t, created.ConsoleSize)
+		assert.Equal(t, [2]uint{24, 80}, *created.ConsoleSize)
+	})
+}
@@ -7,7 +7,10 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
 )
 
 // DockerAPI defines the interface for Docker client operations.
@@ -22,6 +25,9 @@ type DockerAPI interface {
 	// ContainerInspect returns detailed information about a container.
 	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
 
+	// ImageInspect returns detailed information about an image.
+	ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error)
+
 	// ContainerLogs returns logs from a container.
 	ContainerLogs(ctx context.Context, container string, options container.LogsOptions) (io.ReadCloser, error)
 
@@ -34,6 +40,21 @@ type DockerAPI interface {
 	// ContainerRemove removes a container.
 	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
 
+	// ContainerKill sends a signal to a container.
+	ContainerKill(ctx context.Context, containerID, signal string) error
+
+	// ContainerExecCreate creates a command to run in a running container.
+	ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error)
+
+	// ContainerExecAttach starts an exec and attaches to its streams.
+	ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error)
+
+	// ContainerExecInspect returns the state of an exec, including its exit code.
+	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
+
+	// ContainerExecResize resizes the TTY of an exec.
+	ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error
+
 	// ContainerStats returns container resource usage statistics.
 	ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
 
@@ -43,6 +64,9 @@ type DockerAPI interface {
 	// Info returns system-wide information about the Docker daemon.
 	Info(ctx context.Context) (system.Info, error)
 
+	// Events returns a stream of daemon events.
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+
 	// Close closes the client connection.
 	Close() error
 }
@@ -57,13 +81,20 @@ type dockerAPIAdapter interface {
 	Ping(ctx context.Context) (types.Ping, error)
 	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
 	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error)
 	ContainerLogs(ctx context.Context, ctr string, options container.LogsOptions) (io.ReadCloser, error)
 	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
 	ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error
 	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerKill(ctx context.Context, containerID, signal string) error
+	ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
+	ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error
 	ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
 	DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
 	Info(ctx context.Context) (system.Info, error)
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
 	Close() error
 }
 
@@ -9,8 +9,11 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 )
 
@@ -34,26 +37,40 @@ type MockDockerAPI struct {
 	PingFunc            func(ctx context.Context) (types.Ping, error)
 	ContainerListFunc   func(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
 	ContainerInspectFunc func(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ImageInspectFunc    func(ctx context.Context, imageID string) (image.InspectResponse, error)
 	ContainerLogsFunc   func(ctx context.Context, ctr string, options container.LogsOptions) (io.ReadCloser, error)
 	ContainerStartFunc  func(ctx context.Context, containerID string, options container.StartOptions) error
 	ContainerRestartFunc func(ctx context.Context, containerID string, options container.StopOptions) error
 	ContainerRemoveFunc func(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerKillFunc   func(ctx context.Context, containerID, signal string) error
+	ContainerExecCreateFunc func(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error)
+	ContainerExecAttachFunc func(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error)
+	ContainerExecInspectFunc func(ctx context.Context, execID string) (container.ExecInspect, error)
+	ContainerExecResizeFunc func(ctx context.Context, execID string, options container.ResizeOptions) error
 	ContainerStatsFunc  func(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
 	DiskUsageFunc       func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
 	InfoFunc            func(ctx context.Context) (system.Info, error)
+	EventsFunc          func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
 	CloseFunc           func() error
 
 	// Call tracking
 	PingCalls           int
 	ContainerListCalls  int
 	ContainerInspectCalls int
+	ImageInspectCalls   int
 	ContainerLogsCalls  int
 	ContainerStartCalls int
 	ContainerRestartCalls int
 	ContainerRemoveCalls int
+	ContainerKillCalls  int
+	ContainerExecCreateCalls int
+	ContainerExecAttachCalls int
+	ContainerExecInspectCalls int
+	ContainerExecResizeCalls int
 	ContainerStatsCalls int
 	DiskUsageCalls      int
 	InfoCalls           int
+	EventsCalls         int
 	CloseCalls          int
 }
 
@@ -89,6 +106,15 @@ func (m *MockDockerAPI) ContainerInspect(ctx context.Context, containerID string
 	return container.InspectResponse{}, nil
 }
 
+// ImageInspect implements DockerAPI.
+func (m *MockDockerAPI) ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error) {
+	m.ImageInspectCalls++
+	if m.ImageInspectFunc != nil {
+		return m.ImageInspectFunc(ctx, imageID)
+	}
+	return image.InspectResponse{}, nil
+}
+
 // ContainerLogs implements DockerAPI.
 func (m *MockDockerAPI) ContainerLogs(ctx context.Context, containerName string, options container.LogsOptions) (io.ReadCloser, error) {
 	m.ContainerLogsCalls++
@@ -125,6 +151,51 @@ func (m *MockDockerAPI) ContainerRemove(ctx context.Context, containerID string,
 	return nil
 }
 
+// ContainerKill implements DockerAPI.
+func (m *MockDockerAPI) ContainerKill(ctx context.Context, containerID, signal string) error {
+	m.ContainerKillCalls++
+	if m.ContainerKillFunc != nil {
+		return m.ContainerKillFunc(ctx, containerID, signal)
+	}
+	return nil
+}
+
+// ContainerExecCreate implements DockerAPI.
+func (m *MockDockerAPI) ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+	m.ContainerExecCreateCalls++
+	if m.ContainerExecCreateFunc != nil {
+		return m.ContainerExecCreateFunc(ctx, containerID, options)
+	}
+	return container.ExecCreateResponse{ID: "exec123"}, nil
+}
+
+// ContainerExecAttach implements DockerAPI.
+func (m *MockDockerAPI) ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error) {
+	m.ContainerExecAttachCalls++
+	if m.ContainerExecAttachFunc != nil {
+		return m.ContainerExecAttachFunc(ctx, execID, options)
+	}
+	return types.HijackedResponse{}, errors.New("mock: exec attach not configured")
+}
+
+// ContainerExecInspect implements DockerAPI.
+func (m *MockDockerAPI) ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error) {
+	m.ContainerExecInspectCalls++
+	if m.ContainerExecInspectFunc != nil {
+		return m.ContainerExecInspectFunc(ctx, execID)
+	}
+	return container.ExecInspect{ExecID: execID}, nil
+}
+
+// ContainerExecResize implements DockerAPI.
+func (m *MockDockerAPI) ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error {
+	m.ContainerExecResizeCalls++
+	if m.ContainerExecResizeFunc != nil {
+		return m.ContainerExecResizeFunc(ctx, execID, options)
+	}
+	return nil
+}
+
 // ContainerStats implements DockerAPI.
 func (m *MockDockerAPI) ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
 	m.ContainerStatsCalls++
@@ -157,6 +228,15 @@ func (m *MockDockerAPI) Info(ctx context.Context) (system.Info, error) {
 	return system.Info{}, nil
 }
 
+// Events implements DockerAPI. By default the stream ends immediately.
+func (m *MockDockerAPI) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	m.EventsCalls++
+	if m.EventsFunc != nil {
+		return m.EventsFunc(ctx, options)
+	}
+	return mockEventStream(nil, io.EOF)
+}
+
 // Close implements DockerAPI.
 func (m *MockDockerAPI) Close() error {
 	m.CloseCalls++
@@ -171,6 +251,7 @@ func (m *MockDockerAPI) Reset() {
 	m.PingCalls = 0
 	m.ContainerListCalls = 0
 	m.ContainerInspectCalls = 0
+	m.ImageInspectCalls = 0
 	m.ContainerLogsCalls = 0
 	m.ContainerStartCalls = 0
 	m.ContainerRestartCalls = 0
@@ -178,6 +259,7 @@ func (m *MockDockerAPI) Reset() {
 	m.ContainerStatsCalls = 0
 	m.DiskUsageCalls = 0
 	m.InfoCalls = 0
+	m.EventsCalls = 0
 	m.CloseCalls = 0
 }
 
@@ -272,3 +354,18 @@ func makeStatsJSON(cpuTotal, cpuSystem, preCPUTotal, preSystem, memUsage, memLim
 
 // Verify MockDockerAPI implements DockerAPI.
 var _ DockerAPI = (*MockDockerAPI)(nil)
+
+// mockEventStream returns channels that deliver msgs and then err, like the
+// SDK does once a bounded event query has been read.
+func mockEventStream(msgs []events.Message, err error) (<-chan events.Message, <-chan error) {
+	msgCh := make(chan events.Message)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		for _, msg := range msgs {
+			msgCh <- msg
+		}
+		errCh <- err
+	}()
+	return msgCh, errCh
+}
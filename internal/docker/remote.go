@@ -0,0 +1,219 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// sshDialHost is the placeholder host the client addresses when requests are
+// tunneled over SSH; the dialer ignores it.
+const sshDialHost = "http://docker.example.com"
+
+// RemoteConfig selects the Docker daemon a client talks to.
+type RemoteConfig struct {
+	// Host is the daemon address: ssh://[user@]host[:port], tcp://host:port,
+	// or unix:///path. A bare [user@]host, as in DEPLOY_TARGET, means SSH.
+	Host string
+	// TLSCertPath is a directory holding ca.pem, cert.pem, and key.pem for a
+	// tcp:// host. Empty uses DOCKER_CERT_PATH; with neither, tcp:// connects
+	// without TLS.
+	TLSCertPath string
+}
+
+// NewRemoteClient creates a client for the daemon at remote.Host and
+// validates connectivity, like NewClient. Over SSH the remote side runs
+// "docker system dial-stdio", so it needs only the docker CLI and key-based
+// SSH access, no exposed daemon port.
+func NewRemoteClient(remote RemoteConfig, opts ...ClientOption) (*Client, error) {
+	hostOpts, err := remoteHostOpts(remote)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(append([]client.Opt{client.WithAPIVersionNegotiation()}, hostOpts...), opts)
+}
+
+// NormalizeHost returns host as a daemon URL: a bare [user@]host becomes
+// ssh://[user@]host, anything with a scheme is returned as is.
+func NormalizeHost(host string) string {
+	if host == "" || strings.Contains(host, "://") {
+		return host
+	}
+	return "ssh://" + host
+}
+
+// remoteHostOpts returns the client options that connect to remote.Host.
+func remoteHostOpts(remote RemoteConfig) ([]client.Opt, error) {
+	host := NormalizeHost(remote.Host)
+	if host == "" {
+		return nil, errors.New("docker host is empty")
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("parse docker host %q: %w", remote.Host, err)
+	}
+
+	switch u.Scheme {
+	case "ssh":
+		return sshHostOpts(u)
+	case "tcp":
+		opts := []client.Opt{client.WithHost(host)}
+		certPath := remote.TLSCertPath
+		if certPath == "" {
+			certPath = os.Getenv(client.EnvOverrideCertPath)
+		}
+		if certPath != "" {
+			opts = append(opts, client.WithTLSClientConfig(
+				filepath.Join(certPath, "ca.pem"),
+				filepath.Join(certPath, "cert.pem"),
+				filepath.Join(certPath, "key.pem"),
+			))
+		}
+		return opts, nil
+	case "unix", "npipe":
+		return []client.Opt{client.WithHost(host)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q (use ssh://, tcp://, or unix://)", u.Scheme)
+	}
+}
+
+// sshHostOpts returns client options that tunnel the API over SSH to the
+// host in u.
+func sshHostOpts(u *url.URL) ([]client.Opt, error) {
+	args, err := sshDialArgs(u)
+	if err != nil {
+		return nil, err
+	}
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialCommand("ssh", args...)
+	}
+	return []client.Opt{client.WithHost(sshDialHost), client.WithDialContext(dial)}, nil
+}
+
+// sshDialArgs returns the ssh arguments that run "docker system dial-stdio"
+// on the host in an ssh:// URL.
+func sshDialArgs(u *url.URL) ([]string, error) {
+	hostname := u.Hostname()
+	if hostname == "" || strings.HasPrefix(hostname, "-") {
+		return nil, fmt.Errorf("invalid ssh host %q", u.Host)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("ssh docker host %q must not have a path", u.String())
+	}
+
+	args := []string{"-o", "BatchMode=yes"}
+	if user := u.User.Username(); user != "" {
+		args = append(args, "-l", user)
+	}
+	if port := u.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	return append(args, "--", hostname, "docker", "system", "dial-stdio"), nil
+}
+
+// commandConn is a net.Conn over a command's stdin and stdout, such as ssh
+// running "docker system dial-stdio". Deadlines are not supported.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+
+	stderrMu sync.Mutex
+	stderr   bytes.Buffer
+
+	closeOnce sync.Once
+	waitOnce  sync.Once
+}
+
+// dialCommand starts name with args and returns a connection to it. The
+// command runs until the connection is closed.
+func dialCommand(name string, args ...string) (net.Conn, error) {
+	c := &commandConn{cmd: exec.Command(name, args...)}
+	var err error
+	if c.stdin, err = c.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if c.stdout, err = c.cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	c.cmd.Stderr = &lockedWriter{mu: &c.stderrMu, w: &c.stderr}
+	if err := c.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", name, err)
+	}
+	return c, nil
+}
+
+// Read reads from the command's stdout. When the command exits, the error
+// carries what it printed to stderr.
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if errors.Is(err, io.EOF) {
+		// stderr is only fully copied once the command has exited.
+		c.wait()
+		c.stderrMu.Lock()
+		msg := strings.TrimSpace(c.stderr.String())
+		c.stderrMu.Unlock()
+		if msg != "" {
+			return n, fmt.Errorf("%s: %s: %w", c.cmd.Path, msg, io.EOF)
+		}
+	}
+	return n, err
+}
+
+// Write writes to the command's stdin.
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Close closes stdin and stops the command.
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		_ = c.stdin.Close()
+		if c.cmd.Process != nil {
+			_ = c.cmd.Process.Kill()
+		}
+		c.wait()
+	})
+	return nil
+}
+
+// wait waits for the command to exit, once.
+func (c *commandConn) wait() {
+	c.waitOnce.Do(func() { _ = c.cmd.Wait() })
+}
+
+func (c *commandConn) LocalAddr() net.Addr                { return commandAddr{} }
+func (c *commandConn) RemoteAddr() net.Addr               { return commandAddr{} }
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// commandAddr is the address of both ends of a commandConn.
+type commandAddr struct{}
+
+func (commandAddr) Network() string { return "command" }
+func (commandAddr) String() string  { return "command" }
+
+// lockedWriter serializes writes to w.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	assert.Equal(t, "ssh://root@tower", NormalizeHost("root@tower"))
+	assert.Equal(t, "ssh://tower", NormalizeHost("tower"))
+	assert.Equal(t, "tcp://tower:2376", NormalizeHost("tcp://tower:2376"))
+	assert.Empty(t, NormalizeHost(""))
+}
+
+func TestSSHDialArgs(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    []string
+		wantErr bool
+	}{
+		{host: "ssh://tower", want: []string{"-o", "BatchMode=yes", "--", "tower", "docker", "system", "dial-stdio"}},
+		{host: "ssh://root@tower:2222", want: []string{"-o", "BatchMode=yes", "-l", "root", "-p", "2222", "--", "tower", "docker", "system", "dial-stdio"}},
+		{host: "ssh://-oProxyCommand=evil", wantErr: true},
+		{host: "ssh://tower/var/run/docker.sock", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			u, err := url.Parse(tt.host)
+			require.NoError(t, err)
+			args, err := sshDialArgs(u)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+}
+
+func TestRemoteHostOpts(t *testing.T) {
+	for _, host := range []string{"root@tower", "ssh://root@tower", "tcp://tower:2375", "unix:///run/docker.sock"} {
+		opts, err := remoteHostOpts(RemoteConfig{Host: host})
+		require.NoError(t, err, host)
+		assert.NotEmpty(t, opts, host)
+	}
+
+	t.Run("TLS from the cert path", func(t *testing.T) {
+		t.Setenv("DOCKER_CERT_PATH", "")
+		plain, err := remoteHostOpts(RemoteConfig{Host: "tcp://tower:2376"})
+		require.NoError(t, err)
+		tls, err := remoteHostOpts(RemoteConfig{Host: "tcp://tower:2376", TLSCertPath: "/certs"})
+		require.NoError(t, err)
+		assert.Len(t, tls, len(plain)+1)
+	})
+
+	_, err := remoteHostOpts(RemoteConfig{})
+	assert.Error(t, err)
+	_, err = remoteHostOpts(RemoteConfig{Host: "http://tower"})
+	assert.ErrorContains(t, err, "unsupported docker host scheme")
+}
+
+func TestCommandConn(t *testing.T) {
+	t.Run("round trips through the command", func(t *testing.T) {
+		conn, err := dialCommand("cat")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf))
+	})
+
+	t.Run("reports stderr when the command exits", func(t *testing.T) {
+		conn, err := dialCommand("sh", "-c", "echo 'docker: command not found' >&2")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = io.ReadAll(conn)
+		assert.ErrorContains(t, err, "docker: command not found")
+	})
+
+	t.Run("close is idempotent", func(t *testing.T) {
+		conn, err := dialCommand("cat")
+		require.NoError(t, err)
+		assert.NoError(t, conn.Close())
+		assert.NoError(t, conn.Close())
+	})
+}
+
+func TestClient_Signal(t *testing.T) {
+	mock := NewMockDockerAPI()
+	var gotName, gotSignal string
+	mock.ContainerKillFunc = func(ctx context.Context, containerID, signal string) error {
+		gotName, gotSignal = containerID, signal
+		return nil
+	}
+
+	client := NewClientWithAPI(mock)
+	require.NoError(t, client.Signal(context.Background(), "agentgateway", "SIGHUP"))
+	assert.Equal(t, "agentgateway", gotName)
+	assert.Equal(t, "SIGHUP", gotSignal)
+	assert.Equal(t, 1, mock.ContainerKillCalls)
+
+	mock.ContainerKillFunc = func(ctx context.Context, containerID, signal string) error {
+		return errMockRemove
+	}
+	assert.ErrorContains(t, client.Signal(context.Background(), "agentgateway", "SIGHUP"), "signal container agentgateway")
+}
@@ -0,0 +1,333 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+)
+
+// ErrDaemonRestarting is returned without contacting Docker while the circuit
+// breaker is open, i.e. after repeated connection failures that typically mean
+// the daemon is restarting.
+var ErrDaemonRestarting = errors.New("docker daemon restarting")
+
+// RetryPolicy controls retries of read-only Docker API calls on transient
+// connection errors.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values below 1 mean 1.
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles per attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy rides out a brief daemon restart without noticeably
+// delaying commands when Docker is simply down.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     500 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+}
+
+// BreakerConfig controls the circuit breaker around Docker API calls.
+type BreakerConfig struct {
+	// Threshold is the number of consecutive transient failures that opens the circuit.
+	Threshold int
+	// Cooldown is how long the circuit stays open before a trial call is allowed.
+	Cooldown time.Duration
+}
+
+// DefaultBreakerConfig opens after three failed calls and probes again after 30s.
+var DefaultBreakerConfig = BreakerConfig{
+	Threshold: 3,
+	Cooldown:  30 * time.Second,
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithRetryPolicy sets the retry policy for read-only API calls.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.resilient().retry = p
+	}
+}
+
+// WithCircuitBreaker sets the circuit breaker thresholds.
+func WithCircuitBreaker(cfg BreakerConfig) ClientOption {
+	return func(c *Client) {
+		c.resilient().breaker = newCircuitBreaker(cfg)
+	}
+}
+
+// resilient wraps the client's API in a resilientAPI if it isn't already.
+func (c *Client) resilient() *resilientAPI {
+	if r, ok := c.api.(*resilientAPI); ok {
+		return r
+	}
+	r := &resilientAPI{
+		inner:   c.api,
+		retry:   RetryPolicy{MaxAttempts: 1},
+		breaker: newCircuitBreaker(BreakerConfig{}),
+	}
+	c.api = r
+	return r
+}
+
+// IsTransient reports whether err looks like a dropped or refused daemon
+// connection (EOF, connection reset, socket missing) rather than an API error.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ENOENT) ||
+		client.IsErrConnectionFailed(err) {
+		return true
+	}
+	// The SDK flattens some transport errors into strings.
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset by peer") ||
+		strings.Contains(msg, "broken pipe") ||
+		strings.HasSuffix(msg, ": EOF")
+}
+
+// circuitBreaker fails calls fast after repeated transient failures.
+// After Cooldown it lets one trial call through: success closes the
+// circuit, failure keeps it open for another Cooldown.
+type circuitBreaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // Half-open: the one trial call is in flight
+}
+
+func newCircuitBreaker(cfg BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{cfg: cfg, now: time.Now}
+}
+
+// allow returns ErrDaemonRestarting while the circuit is open. Once the
+// cooldown has passed, the first caller becomes the trial call and the
+// rest are refused until record settles it. A zero Threshold disables the
+// breaker.
+func (b *circuitBreaker) allow() error {
+	return b.admit(true)
+}
+
+// check is allow for calls that never report back through record, such as
+// streams: it refuses them while the circuit is open but never makes them
+// the trial call.
+func (b *circuitBreaker) check() error {
+	return b.admit(false)
+}
+
+func (b *circuitBreaker) admit(trial bool) error {
+	if b.cfg.Threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		return fmt.Errorf("%w: %d consecutive connection failures, retrying in %s",
+			ErrDaemonRestarting, b.failures, wait.Round(time.Second))
+	}
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if b.trial {
+		return fmt.Errorf("%w: %d consecutive connection failures, trial call in flight",
+			ErrDaemonRestarting, b.failures)
+	}
+	b.trial = trial
+	return nil
+}
+
+// record updates breaker state with the outcome of a call.
+// Only transient errors count as failures; API errors mean the daemon answered.
+func (b *circuitBreaker) record(err error) {
+	if b.cfg.Threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !IsTransient(err) {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.Threshold {
+		b.openUntil = b.now().Add(b.cfg.Cooldown)
+	}
+}
+
+// resilientAPI decorates a DockerAPI with retries and a circuit breaker.
+// Read-only calls are retried on transient errors; mutating calls are not,
+// since a dropped connection doesn't say whether the daemon acted.
+type resilientAPI struct {
+	inner   DockerAPI
+	retry   RetryPolicy
+	breaker *circuitBreaker
+}
+
+// do runs fn through the breaker, retrying transient failures if retry is set.
+func do[T any](ctx context.Context, r *resilientAPI, retry bool, fn func() (T, error)) (T, error) {
+	var zero T
+	attempts := r.retry.MaxAttempts
+	if !retry || attempts < 1 {
+		attempts = 1
+	}
+	backoff := r.retry.Backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err := r.breaker.allow(); err != nil {
+			return zero, err
+		}
+
+		var result T
+		result, err = fn()
+		r.breaker.record(err)
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= attempts || !IsTransient(err) {
+			return zero, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return zero, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if r.retry.MaxBackoff > 0 && backoff > r.retry.MaxBackoff {
+			backoff = r.retry.MaxBackoff
+		}
+	}
+}
+
+// doErr adapts do for calls that only return an error.
+func doErr(ctx context.Context, r *resilientAPI, retry bool, fn func() error) error {
+	_, err := do(ctx, r, retry, func() (struct{}, error) { return struct{}{}, fn() })
+	return err
+}
+
+// Ping implements DockerAPI.
+func (r *resilientAPI) Ping(ctx context.Context) (types.Ping, error) {
+	return do(ctx, r, true, func() (types.Ping, error) { return r.inner.Ping(ctx) })
+}
+
+// ContainerList implements DockerAPI.
+func (r *resilientAPI) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	return do(ctx, r, true, func() ([]container.Summary, error) { return r.inner.ContainerList(ctx, options) })
+}
+
+// ContainerInspect implements DockerAPI.
+func (r *resilientAPI) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	return do(ctx, r, true, func() (container.InspectResponse, error) { return r.inner.ContainerInspect(ctx, containerID) })
+}
+
+// ImageInspect implements DockerAPI.
+func (r *resilientAPI) ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error) {
+	return do(ctx, r, true, func() (image.InspectResponse, error) { return r.inner.ImageInspect(ctx, imageID, opts...) })
+}
+
+// ContainerLogs implements DockerAPI. Only opening the stream is retried.
+func (r *resilientAPI) ContainerLogs(ctx context.Context, ctr string, options container.LogsOptions) (io.ReadCloser, error) {
+	return do(ctx, r, true, func() (io.ReadCloser, error) { return r.inner.ContainerLogs(ctx, ctr, options) })
+}
+
+// ContainerStart implements DockerAPI.
+func (r *resilientAPI) ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error {
+	return doErr(ctx, r, false, func() error { return r.inner.ContainerStart(ctx, containerID, options) })
+}
+
+// ContainerRestart implements DockerAPI.
+func (r *resilientAPI) ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error {
+	return doErr(ctx, r, false, func() error { return r.inner.ContainerRestart(ctx, containerID, options) })
+}
+
+// ContainerRemove implements DockerAPI.
+func (r *resilientAPI) ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error {
+	return doErr(ctx, r, false, func() error { return r.inner.ContainerRemove(ctx, containerID, options) })
+}
+
+// ContainerKill implements DockerAPI.
+func (r *resilientAPI) ContainerKill(ctx context.Context, containerID, signal string) error {
+	return doErr(ctx, r, false, func() error { return r.inner.ContainerKill(ctx, containerID, signal) })
+}
+
+// ContainerExecCreate implements DockerAPI.
+func (r *resilientAPI) ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+	return do(ctx, r, false, func() (container.ExecCreateResponse, error) {
+		return r.inner.ContainerExecCreate(ctx, containerID, options)
+	})
+}
+
+// ContainerExecAttach implements DockerAPI.
+func (r *resilientAPI) ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error) {
+	return do(ctx, r, false, func() (types.HijackedResponse, error) { return r.inner.ContainerExecAttach(ctx, execID, options) })
+}
+
+// ContainerExecInspect implements DockerAPI.
+func (r *resilientAPI) ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error) {
+	return do(ctx, r, true, func() (container.ExecInspect, error) { return r.inner.ContainerExecInspect(ctx, execID) })
+}
+
+// ContainerExecResize implements DockerAPI.
+func (r *resilientAPI) ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error {
+	return doErr(ctx, r, false, func() error { return r.inner.ContainerExecResize(ctx, execID, options) })
+}
+
+// ContainerStats implements DockerAPI.
+func (r *resilientAPI) ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+	return do(ctx, r, true, func() (container.StatsResponseReader, error) { return r.inner.ContainerStats(ctx, containerID, stream) })
+}
+
+// DiskUsage implements DockerAPI.
+func (r *resilientAPI) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	return do(ctx, r, true, func() (types.DiskUsage, error) { return r.inner.DiskUsage(ctx, options) })
+}
+
+// Info implements DockerAPI.
+func (r *resilientAPI) Info(ctx context.Context) (system.Info, error) {
+	return do(ctx, r, true, func() (system.Info, error) { return r.inner.Info(ctx) })
+}
+
+// Events implements DockerAPI. Streams are not retried, but an open circuit
+// fails the stream immediately.
+func (r *resilientAPI) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	if err := r.breaker.check(); err != nil {
+		errs := make(chan error, 1)
+		errs <- err
+		close(errs)
+		return make(chan events.Message), errs
+	}
+	return r.inner.Events(ctx, options)
+}
+
+// Close implements DockerAPI.
+func (r *resilientAPI) Close() error {
+	return r.inner.Close()
+}
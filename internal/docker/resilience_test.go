@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetry retries without real delays.
+var fastRetry = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+func TestIsTransient(t *testing.T) {
+	connReset := &net.OpError{Op: "read", Net: "unix", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"EOF", io.EOF, true},
+		{"wrapped EOF", fmt.Errorf("list containers: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", connReset, true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"flattened EOF", fmt.Errorf("error during connect: Get \"http://docker/v1.45/containers/json\": EOF"), true},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"API error", errMockList, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IsTransient(tc.err))
+		})
+	}
+}
+
+func TestResilientAPI_Retry(t *testing.T) {
+	t.Run("retries transient errors on reads", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.ContainerListFunc = func(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+			if mock.ContainerListCalls < 3 {
+				return nil, io.EOF
+			}
+			return []container.Summary{makeTestContainer("abc123456789", "web", "nginx", "exited")}, nil
+		}
+
+		client := NewClientWithAPI(mock, fastRetry)
+		got, err := client.ListContainers(context.Background(), false)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, 3, mock.ContainerListCalls)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+			return types.Ping{}, io.EOF
+		}
+
+		client := NewClientWithAPI(mock, fastRetry)
+		err := client.Ping(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, 3, mock.PingCalls)
+	})
+
+	t.Run("does not retry API errors", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.ContainerInspectFunc = func(ctx context.Context, containerID string) (container.InspectResponse, error) {
+			return container.InspectResponse{}, errMockInspect
+		}
+
+		client := NewClientWithAPI(mock, fastRetry)
+		_, err := client.Inspect(context.Background(), "web")
+		require.Error(t, err)
+		assert.Equal(t, 1, mock.ContainerInspectCalls)
+	})
+
+	t.Run("does not retry mutations", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.ContainerRestartFunc = func(ctx context.Context, containerID string, options container.StopOptions) error {
+			return io.EOF
+		}
+
+		client := NewClientWithAPI(mock, fastRetry)
+		err := client.RestartContainer(context.Background(), "web")
+		require.Error(t, err)
+		assert.Equal(t, 1, mock.ContainerRestartCalls)
+	})
+
+	t.Run("stops retrying when context is done", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+			return types.Ping{}, io.EOF
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		client := NewClientWithAPI(mock, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, Backoff: time.Hour}))
+		err := client.Ping(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 1, mock.PingCalls)
+	})
+}
+
+func TestResilientAPI_CircuitBreaker(t *testing.T) {
+	mock := NewMockDockerAPI()
+	daemonDown := true
+	mock.InfoFunc = func(ctx context.Context) (system.Info, error) {
+		if daemonDown {
+			return system.Info{}, syscall.ECONNREFUSED
+		}
+		return system.Info{ID: "daemon"}, nil
+	}
+
+	client := NewClientWithAPI(mock, WithCircuitBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute}))
+	breaker := client.api.(*resilientAPI).breaker
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := client.Info(ctx)
+	require.ErrorIs(t, err, syscall.ECONNREFUSED)
+	_, err = client.Info(ctx)
+	require.ErrorIs(t, err, syscall.ECONNREFUSED)
+	assert.Equal(t, 2, mock.InfoCalls)
+
+	// Circuit is open: calls fail fast without reaching the daemon
+	_, err = client.Info(ctx)
+	require.ErrorIs(t, err, ErrDaemonRestarting)
+	assert.Contains(t, err.Error(), "retrying in 1m0s")
+	assert.Equal(t, 2, mock.InfoCalls)
+
+	_, errs := client.api.Events(ctx, events.ListOptions{})
+	assert.ErrorIs(t, <-errs, ErrDaemonRestarting)
+
+	// After the cooldown a trial call goes through and closes the circuit
+	daemonDown = false
+	now = now.Add(time.Minute)
+	info, err := client.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "daemon", info.ID)
+
+	daemonDown = true
+	_, err = client.Info(ctx)
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED, "one failure after recovery should not reopen the circuit")
+}
+
+func TestResilientAPI_CircuitBreakerSingleTrial(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	mock := NewMockDockerAPI()
+	mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+		if calls.Add(1) <= 2 {
+			return types.Ping{}, syscall.ECONNREFUSED
+		}
+		<-release
+		return types.Ping{}, nil
+	}
+
+	client := NewClientWithAPI(mock, WithCircuitBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute}))
+	breaker := client.api.(*resilientAPI).breaker
+	var mu sync.Mutex
+	now := time.Now()
+	breaker.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	ctx := context.Background()
+	require.ErrorIs(t, client.Ping(ctx), syscall.ECONNREFUSED)
+	require.ErrorIs(t, client.Ping(ctx), syscall.ECONNREFUSED)
+
+	mu.Lock()
+	now = now.Add(time.Minute)
+	mu.Unlock()
+
+	// After the cooldown only one of many concurrent callers probes the
+	// daemon; the others fail fast until it settles
+	const callers = 10
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() { errs <- client.Ping(ctx) }()
+	}
+	for i := 0; i < callers-1; i++ {
+		err := <-errs
+		require.ErrorIs(t, err, ErrDaemonRestarting)
+		assert.Contains(t, err.Error(), "trial call in flight")
+	}
+	close(release)
+	require.NoError(t, <-errs, "the trial call")
+	assert.Equal(t, int32(3), calls.Load())
+
+	require.NoError(t, client.Ping(ctx), "a successful trial closes the circuit")
+}
+
+func TestResilientAPI_DisabledBreaker(t *testing.T) {
+	mock := NewMockDockerAPI()
+	mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+		return types.Ping{}, io.EOF
+	}
+
+	client := NewClientWithAPI(mock, WithCircuitBreaker(BreakerConfig{}))
+	for i := 0; i < 5; i++ {
+		assert.NotErrorIs(t, client.Ping(context.Background()), ErrDaemonRestarting)
+	}
+	assert.Equal(t, 5, mock.PingCalls)
+}
@@ -0,0 +1,114 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultSocket is the rootful Docker daemon socket.
+const DefaultSocket = "/var/run/docker.sock"
+
+// User namespace modes reported by ParseUsernsMode.
+const (
+	// UsernsRootless is a daemon running as an unprivileged user; container
+	// root maps to that user.
+	UsernsRootless = "rootless"
+	// UsernsRemap is a rootful daemon with userns-remap; container IDs are
+	// offset into a subordinate ID range.
+	UsernsRemap = "userns"
+)
+
+// RootlessSocket returns the socket a rootless daemon for the current user
+// listens on: $XDG_RUNTIME_DIR/docker.sock, or /run/user/<uid>/docker.sock.
+func RootlessSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "docker.sock")
+	}
+	return fmt.Sprintf("/run/user/%d/docker.sock", os.Getuid())
+}
+
+// DetectHost returns the daemon address to use when DOCKER_HOST is unset:
+// the rootless socket when it exists and the rootful one does not, else "".
+func DetectHost() string {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return ""
+	}
+	if _, err := os.Stat(DefaultSocket); err == nil {
+		return ""
+	}
+	sock := RootlessSocket()
+	if _, err := os.Stat(sock); err == nil {
+		return "unix://" + sock
+	}
+	return ""
+}
+
+// SocketPath returns the filesystem path of the daemon socket in use, for
+// bind-mounting into containers. Non-unix DOCKER_HOST values return "".
+func SocketPath() string {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = DetectHost()
+	}
+	if host == "" {
+		return DefaultSocket
+	}
+	if path, ok := strings.CutPrefix(host, "unix://"); ok {
+		return path
+	}
+	return ""
+}
+
+// ParseUsernsMode returns UsernsRootless or UsernsRemap from the daemon's
+// security options (docker info), or "" for a rootful daemon without remap.
+func ParseUsernsMode(securityOptions []string) string {
+	var mode string
+	for _, opt := range securityOptions {
+		for _, field := range strings.Split(opt, ",") {
+			switch field {
+			case "name=rootless":
+				return UsernsRootless
+			case "name=userns":
+				mode = UsernsRemap
+			}
+		}
+	}
+	return mode
+}
+
+// ParseRemapRoot returns the host UID and GID that container root maps to
+// under userns-remap, read from the daemon's data root, which Docker names
+// <root>/<uid>.<gid> in that mode.
+func ParseRemapRoot(dockerRootDir string) (uid, gid int, err error) {
+	base := filepath.Base(dockerRootDir)
+	u, g, ok := strings.Cut(base, ".")
+	if ok {
+		uid, err = strconv.Atoi(u)
+		if err == nil {
+			gid, err = strconv.Atoi(g)
+		}
+	}
+	if !ok || err != nil || uid < 0 || gid < 0 {
+		return 0, 0, fmt.Errorf("cannot read userns-remap range from docker root %q", dockerRootDir)
+	}
+	return uid, gid, nil
+}
+
+// DefaultRootDir returns Docker's data root for the daemon in use: the
+// rootless default ($XDG_DATA_HOME/docker, or ~/.local/share/docker) when
+// it is the current user's rootless daemon, else /var/lib/docker.
+func DefaultRootDir() string {
+	if SocketPath() != RootlessSocket() {
+		return "/var/lib/docker"
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "docker")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "docker")
+	}
+	return "/var/lib/docker"
+}
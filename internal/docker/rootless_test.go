@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootlessSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	assert.Equal(t, "/run/user/1000/docker.sock", RootlessSocket())
+}
+
+func TestDetectHost(t *testing.T) {
+	t.Run("DOCKER_HOST wins", func(t *testing.T) {
+		t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375")
+		assert.Empty(t, DetectHost())
+	})
+
+	t.Run("rootless socket", func(t *testing.T) {
+		if _, err := os.Stat(DefaultSocket); err == nil {
+			t.Skip("rootful socket present")
+		}
+		dir := t.TempDir()
+		t.Setenv("DOCKER_HOST", "")
+		t.Setenv("XDG_RUNTIME_DIR", dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "docker.sock"), nil, 0600))
+
+		assert.Equal(t, "unix://"+filepath.Join(dir, "docker.sock"), DetectHost())
+		assert.Equal(t, filepath.Join(dir, "docker.sock"), SocketPath())
+	})
+
+	t.Run("no socket", func(t *testing.T) {
+		if _, err := os.Stat(DefaultSocket); err == nil {
+			t.Skip("rootful socket present")
+		}
+		t.Setenv("DOCKER_HOST", "")
+		t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+		assert.Empty(t, DetectHost())
+		assert.Equal(t, DefaultSocket, SocketPath())
+	})
+}
+
+func TestParseUsernsMode(t *testing.T) {
+	assert.Equal(t, UsernsRootless, ParseUsernsMode([]string{"name=seccomp,profile=builtin", "name=rootless", "name=cgroupns"}))
+	assert.Equal(t, UsernsRemap, ParseUsernsMode([]string{"name=apparmor", "name=userns"}))
+	assert.Empty(t, ParseUsernsMode([]string{"name=seccomp,profile=builtin"}))
+	assert.Empty(t, ParseUsernsMode(nil))
+}
+
+func TestParseRemapRoot(t *testing.T) {
+	uid, gid, err := ParseRemapRoot("/var/lib/docker/100000.100000")
+	require.NoError(t, err)
+	assert.Equal(t, 100000, uid)
+	assert.Equal(t, 100000, gid)
+
+	_, _, err = ParseRemapRoot("/var/lib/docker")
+	assert.Error(t, err)
+}
+
+func TestDefaultRootDir(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+	assert.Equal(t, "/var/lib/docker", DefaultRootDir())
+
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	t.Setenv("XDG_DATA_HOME", "/home/deploy/.local/share")
+	t.Setenv("DOCKER_HOST", "unix:///run/user/1000/docker.sock")
+	assert.Equal(t, "/home/deploy/.local/share/docker", DefaultRootDir())
+}
@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// StreamContainerStats streams a container's stats, a sample about every
+// second, until ctx ends or the container stops, when the channel is
+// closed.
+func (c *Client) StreamContainerStats(ctx context.Context, name string) (<-chan ContainerStats, error) {
+	stats, err := c.api.ContainerStats(ctx, name, true)
+	if err != nil {
+		return nil, fmt.Errorf("stream container stats: %w", err)
+	}
+
+	ch := make(chan ContainerStats)
+	go func() {
+		defer close(ch)
+		defer stats.Body.Close()
+
+		dec := json.NewDecoder(stats.Body)
+		for {
+			var v statsJSON
+			if err := dec.Decode(&v); err != nil {
+				return
+			}
+			select {
+			case ch <- statsFromJSON(name, v):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
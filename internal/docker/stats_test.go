@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_StreamContainerStats(t *testing.T) {
+	samples := string(makeStatsJSON(200, 2000, 100, 1000, 256, 1024, 2)) +
+		`{"cpu_stats":{"cpu_usage":{"total_usage":300},"system_cpu_usage":3000,"online_cpus":2},` +
+		`"precpu_stats":{"cpu_usage":{"total_usage":200},"system_cpu_usage":2000},` +
+		`"memory_stats":{"usage":512,"limit":1024},` +
+		`"networks":{"eth0":{"rx_bytes":100,"tx_bytes":50},"eth1":{"rx_bytes":1,"tx_bytes":2}},` +
+		`"blkio_stats":{"io_service_bytes_recursive":[{"op":"read","value":4096},{"op":"Write","value":8192},{"op":"sync","value":1}]}}`
+
+	var streamed bool
+	mock := NewMockDockerAPI()
+	mock.ContainerStatsFunc = func(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+		streamed = stream
+		return container.StatsResponseReader{Body: io.NopCloser(strings.NewReader(samples))}, nil
+	}
+	client := NewClientWithAPI(mock)
+
+	ch, err := client.StreamContainerStats(context.Background(), "web")
+	require.NoError(t, err)
+	var got []ContainerStats
+	for s := range ch {
+		got = append(got, s)
+	}
+	assert.True(t, streamed)
+
+	require.Len(t, got, 2, "the channel closes when the stream ends")
+	assert.InDelta(t, 20.0, got[0].CPUPercent, 0.1)
+	assert.Equal(t, uint64(256), got[0].MemUsage)
+
+	assert.InDelta(t, 20.0, got[1].CPUPercent, 0.1, "online_cpus without per-CPU usage")
+	assert.Equal(t, uint64(101), got[1].NetRx)
+	assert.Equal(t, uint64(52), got[1].NetTx)
+	assert.Equal(t, uint64(4096), got[1].BlockRead)
+	assert.Equal(t, uint64(8192), got[1].BlockWrite)
+}
+
+func TestClient_StreamContainerStats_Error(t *testing.T) {
+	mock := NewMockDockerAPI()
+	mock.ContainerStatsFunc = func(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+		return container.StatsResponseReader{}, errMockStats
+	}
+
+	_, err := NewClientWithAPI(mock).StreamContainerStats(context.Background(), "web")
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds each Docker API call by operation category, so callers
+// don't have to remember to wrap their contexts. A zero duration leaves the
+// call bounded only by the caller's context. The caller's deadline still
+// applies when it is shorter.
+type Timeouts struct {
+	// Query covers quick metadata calls: ping, info, list, inspect, events,
+	// and creating and inspecting execs.
+	Query time.Duration
+	// Stats covers one-shot container stats sampling.
+	Stats time.Duration
+	// Lifecycle covers start, restart, remove, and signals.
+	Lifecycle time.Duration
+	// DiskUsage covers system df, which walks every image, container, and volume.
+	DiskUsage time.Duration
+	// Logs covers GetContainerLogs. Streams from Logs are never bounded here,
+	// since a followed stream runs until the caller cancels.
+	Logs time.Duration
+}
+
+// DefaultTimeouts are applied by NewClient and NewClientWithAPI.
+var DefaultTimeouts = Timeouts{
+	Query:     5 * time.Second,
+	Stats:     10 * time.Second,
+	Lifecycle: 30 * time.Second,
+	DiskUsage: 60 * time.Second,
+	Logs:      0,
+}
+
+// WithTimeouts overrides the per-category timeouts.
+func WithTimeouts(t Timeouts) ClientOption {
+	return func(c *Client) {
+		c.timeouts = t
+	}
+}
+
+// withTimeout derives a context bounded by d, or only cancellable when d is zero.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineIn returns how far away ctx's deadline is, or zero if it has none.
+func deadlineIn(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	return time.Until(deadline)
+}
+
+func TestClient_DefaultTimeouts(t *testing.T) {
+	mock := NewMockDockerAPI()
+	var inspect, stats, restart, disk time.Duration
+	mock.ContainerInspectFunc = func(ctx context.Context, containerID string) (container.InspectResponse, error) {
+		inspect = deadlineIn(ctx)
+		return makeTestContainerJSON("abc123456789", "web", "nginx", "running", true), nil
+	}
+	mock.ContainerStatsFunc = func(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+		stats = deadlineIn(ctx)
+		return container.StatsResponseReader{Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+	mock.ContainerRestartFunc = func(ctx context.Context, containerID string, options container.StopOptions) error {
+		restart = deadlineIn(ctx)
+		return nil
+	}
+	mock.DiskUsageFunc = func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+		disk = deadlineIn(ctx)
+		return types.DiskUsage{}, nil
+	}
+
+	client := NewClientWithAPI(mock)
+	ctx := context.Background()
+
+	_, err := client.Inspect(ctx, "web")
+	require.NoError(t, err)
+	_, err = client.GetContainerStats(ctx, "web")
+	require.NoError(t, err)
+	require.NoError(t, client.RestartContainer(ctx, "web"))
+	_, err = client.DiskUsage(ctx)
+	require.NoError(t, err)
+
+	assert.InDelta(t, DefaultTimeouts.Query, inspect, float64(time.Second))
+	assert.InDelta(t, DefaultTimeouts.Stats, stats, float64(time.Second))
+	assert.InDelta(t, DefaultTimeouts.Lifecycle, restart, float64(time.Second))
+	assert.InDelta(t, DefaultTimeouts.DiskUsage, disk, float64(time.Second))
+}
+
+func TestClient_LogsUnbounded(t *testing.T) {
+	mock := NewMockDockerAPI()
+	var logsCtx context.Context
+	mock.ContainerLogsFunc = func(ctx context.Context, ctr string, options container.LogsOptions) (io.ReadCloser, error) {
+		logsCtx = ctx
+		return io.NopCloser(strings.NewReader("line\n")), nil
+	}
+
+	client := NewClientWithAPI(mock)
+	reader, err := client.Logs(context.Background(), "web", 10, true)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, hasDeadline := logsCtx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.NoError(t, logsCtx.Err(), "stream context must outlive the call")
+}
+
+func TestWithTimeouts(t *testing.T) {
+	t.Run("overrides a category", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		var got time.Duration
+		mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+			got = deadlineIn(ctx)
+			return types.Ping{}, nil
+		}
+
+		client := NewClientWithAPI(mock, WithTimeouts(Timeouts{Query: time.Minute}))
+		require.NoError(t, client.Ping(context.Background()))
+		assert.InDelta(t, time.Minute, got, float64(time.Second))
+	})
+
+	t.Run("zero leaves the caller's context alone", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		var hasDeadline bool
+		mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+			_, hasDeadline = ctx.Deadline()
+			return types.Ping{}, nil
+		}
+
+		client := NewClientWithAPI(mock, WithTimeouts(Timeouts{}))
+		require.NoError(t, client.Ping(context.Background()))
+		assert.False(t, hasDeadline)
+	})
+
+	t.Run("shorter caller deadline wins", func(t *testing.T) {
+		mock := NewMockDockerAPI()
+		var got time.Duration
+		mock.PingFunc = func(ctx context.Context) (types.Ping, error) {
+			got = deadlineIn(ctx)
+			return types.Ping{}, nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		client := NewClientWithAPI(mock)
+		require.NoError(t, client.Ping(ctx))
+		assert.LessOrEqual(t, got, time.Second)
+	})
+}
@@ -0,0 +1,173 @@
+package fileutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// GenerationPrefix is the prefix for generation directory names.
+	GenerationPrefix = "gen-"
+	// KeepGenerations is how many generations CommitGeneration keeps,
+	// counting the current one.
+	KeepGenerations = 3
+)
+
+// GenerationsDir returns the directory holding dir's generations: a hidden
+// sibling, so swapping generations never crosses a filesystem.
+func GenerationsDir(dir string) string {
+	return filepath.Join(filepath.Dir(dir), "."+filepath.Base(dir)+"-generations")
+}
+
+// CommitGeneration replaces the contents of dir in one step. build fills a
+// new, empty generation directory; once every file in it is synced to disk,
+// dir is pointed at it by atomically renaming a symlink over dir. A crash
+// at any point leaves dir on either the old generation or the new one,
+// never a mix.
+//
+// A dir that is still a plain directory is moved into the generations
+// directory when it is first replaced; only that one swap is not atomic.
+// Generations older than the last KeepGenerations are removed.
+func CommitGeneration(dir string, build func(genDir string) error) error {
+	genRoot := GenerationsDir(dir)
+	if err := os.MkdirAll(genRoot, 0755); err != nil {
+		return fmt.Errorf("create generations directory: %w", err)
+	}
+
+	stamp := time.Now().UTC().Format("20060102-150405.000000000")
+	genDir, err := os.MkdirTemp(genRoot, GenerationPrefix+stamp+"-")
+	if err != nil {
+		return fmt.Errorf("create generation: %w", err)
+	}
+	if err := os.Chmod(genDir, 0755); err != nil {
+		os.RemoveAll(genDir)
+		return fmt.Errorf("create generation: %w", err)
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			os.RemoveAll(genDir)
+		}
+	}()
+
+	if err := build(genDir); err != nil {
+		return err
+	}
+	if err := syncTree(genDir); err != nil {
+		return fmt.Errorf("sync generation: %w", err)
+	}
+	if err := syncPath(genRoot); err != nil {
+		return fmt.Errorf("sync generations directory: %w", err)
+	}
+
+	target, err := filepath.Rel(filepath.Dir(dir), genDir)
+	if err != nil {
+		return fmt.Errorf("generation path: %w", err)
+	}
+	link := filepath.Join(filepath.Dir(dir), ".tmp-"+filepath.Base(genDir))
+	if err := os.Symlink(target, link); err != nil {
+		return fmt.Errorf("link generation: %w", err)
+	}
+
+	// Move a plain directory out of the way; rename can't replace it.
+	legacy := ""
+	if info, err := os.Lstat(dir); err == nil && info.IsDir() {
+		legacy = filepath.Join(genRoot, GenerationPrefix+"0-legacy")
+		os.RemoveAll(legacy)
+		if err := os.Rename(dir, legacy); err != nil {
+			os.Remove(link)
+			return fmt.Errorf("move %s aside: %w", dir, err)
+		}
+	}
+
+	if err := os.Rename(link, dir); err != nil {
+		os.Remove(link)
+		if legacy != "" {
+			if recoverErr := os.Rename(legacy, dir); recoverErr != nil {
+				return fmt.Errorf("switch to generation: %w (recovery also failed: %v)", err, recoverErr)
+			}
+		}
+		return fmt.Errorf("switch to generation: %w", err)
+	}
+	success = true
+
+	if err := syncPath(filepath.Dir(dir)); err != nil {
+		return fmt.Errorf("sync %s: %w", filepath.Dir(dir), err)
+	}
+
+	if err := pruneGenerations(genRoot, filepath.Base(genDir), KeepGenerations); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to remove old generations: %v\n", err)
+	}
+	return nil
+}
+
+// ResolveDir returns the directory dir points at when it is a symlink, such
+// as a directory managed by CommitGeneration, and dir itself otherwise.
+// Walks of dir should start from here: filepath.WalkDir does not follow a
+// symlinked root.
+func ResolveDir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return dir
+}
+
+// pruneGenerations removes all but the newest keep generations up to and
+// including current. Generations newer than current belong to a commit
+// still in progress (or one that crashed) and are left alone.
+func pruneGenerations(genRoot, current string, keep int) error {
+	entries, err := os.ReadDir(genRoot)
+	if err != nil {
+		return err
+	}
+
+	var older []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() && strings.HasPrefix(name, GenerationPrefix) && name < current {
+			older = append(older, name)
+		}
+	}
+	sort.Strings(older)
+
+	var errs []string
+	for len(older) > keep-1 {
+		if err := os.RemoveAll(filepath.Join(genRoot, older[0])); err != nil {
+			errs = append(errs, err.Error())
+		}
+		older = older[1:]
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// syncTree fsyncs every file and directory under root.
+func syncTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		return syncPath(path)
+	})
+}
+
+// syncPath fsyncs a file or directory.
+func syncPath(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
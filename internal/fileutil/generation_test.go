@@ -0,0 +1,107 @@
+package fileutil_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitGeneration(t *testing.T) {
+	t.Parallel()
+
+	writeFile := func(name, content string) func(string) error {
+		return func(genDir string) error {
+			return os.WriteFile(filepath.Join(genDir, name), []byte(content), 0644)
+		}
+	}
+
+	t.Run("points dir at the new generation", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "output")
+		require.NoError(t, fileutil.CommitGeneration(dir, writeFile("a.yml", "one")))
+
+		info, err := os.Lstat(dir)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+		target, err := os.Readlink(dir)
+		require.NoError(t, err)
+		assert.False(t, filepath.IsAbs(target), "link should be relative")
+		assert.True(t, strings.HasPrefix(filepath.Base(target), fileutil.GenerationPrefix))
+
+		data, err := os.ReadFile(filepath.Join(dir, "a.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, "one", string(data))
+	})
+
+	t.Run("replaces a plain directory", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "output")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "old.yml"), []byte("old"), 0644))
+
+		require.NoError(t, fileutil.CommitGeneration(dir, writeFile("new.yml", "new")))
+
+		assert.NoFileExists(t, filepath.Join(dir, "old.yml"))
+		assert.FileExists(t, filepath.Join(dir, "new.yml"))
+		assert.DirExists(t, filepath.Join(fileutil.GenerationsDir(dir), fileutil.GenerationPrefix+"0-legacy"))
+	})
+
+	t.Run("failed build leaves the current generation", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "output")
+		require.NoError(t, fileutil.CommitGeneration(dir, writeFile("a.yml", "one")))
+
+		err := fileutil.CommitGeneration(dir, func(genDir string) error {
+			require.NoError(t, os.WriteFile(filepath.Join(genDir, "a.yml"), []byte("half"), 0644))
+			return errors.New("render failed")
+		})
+		require.Error(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "a.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, "one", string(data))
+
+		entries, err := os.ReadDir(fileutil.GenerationsDir(dir))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "failed generation should be removed")
+	})
+
+	t.Run("keeps the newest generations", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "output")
+		for i := 0; i < fileutil.KeepGenerations+2; i++ {
+			require.NoError(t, fileutil.CommitGeneration(dir, writeFile("a.yml", "x")))
+		}
+
+		entries, err := os.ReadDir(fileutil.GenerationsDir(dir))
+		require.NoError(t, err)
+		assert.Len(t, entries, fileutil.KeepGenerations)
+
+		target, err := os.Readlink(dir)
+		require.NoError(t, err)
+		assert.Equal(t, entries[len(entries)-1].Name(), filepath.Base(target))
+	})
+}
+
+func TestResolveDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "output")
+	assert.Equal(t, dir, fileutil.ResolveDir(dir), "missing dir is returned as is")
+
+	require.NoError(t, fileutil.CommitGeneration(dir, func(string) error { return nil }))
+	resolved := fileutil.ResolveDir(dir)
+	assert.NotEqual(t, dir, resolved)
+	assert.Equal(t, filepath.Dir(resolved), fileutil.GenerationsDir(dir))
+}
@@ -0,0 +1,175 @@
+package hostmetrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FactsTimeout bounds how long gathering host facts may take.
+const FactsTimeout = 10 * time.Second
+
+// factsScript prints host facts as key=value lines on a remote Linux host.
+const factsScript = `echo hostname=$(hostname)
+echo ip=$(hostname -I 2>/dev/null | awk '{print $1}')
+echo cpus=$(nproc 2>/dev/null)
+echo memory_kb=$(awk '/^MemTotal:/ {print $2}' /proc/meminfo 2>/dev/null)
+echo docker_version=$(docker version --format '{{.Server.Version}}' 2>/dev/null)`
+
+// Facts describes the host services are deployed to, for use as template
+// variables so manifests don't hardcode addresses that change with the network.
+// Facts that could not be read are left zero.
+type Facts struct {
+	Hostname      string
+	IP            string
+	CPUs          int
+	MemoryMB      int64
+	DockerVersion string
+}
+
+// LocalFacts gathers facts about this host. Collection is best-effort.
+func LocalFacts(ctx context.Context) *Facts {
+	f := &Facts{
+		IP:   primaryIP(),
+		CPUs: runtime.NumCPU(),
+	}
+	if host, err := os.Hostname(); err == nil {
+		f.Hostname = host
+	}
+	if mem, err := readMemory(); err == nil {
+		f.MemoryMB = int64(mem.Total / (1024 * 1024))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, FactsTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").Output(); err == nil {
+		f.DockerVersion = strings.TrimSpace(string(out))
+	}
+
+	f.applyOverrides()
+	return f
+}
+
+// RemoteFacts gathers facts about a remote host ("user@host") over SSH.
+// If the host reports no address, the address from the SSH target is used.
+func RemoteFacts(ctx context.Context, target string) (*Facts, error) {
+	ctx, cancel := context.WithTimeout(ctx, FactsTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", target, factsScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("gather host facts from %s: %w", target, err)
+	}
+
+	f := parseFacts(string(out))
+	if f.IP == "" {
+		host := target[strings.LastIndex(target, "@")+1:]
+		if net.ParseIP(host) != nil {
+			f.IP = host
+		}
+	}
+
+	f.applyOverrides()
+	return f, nil
+}
+
+// parseFacts parses key=value lines printed by factsScript.
+func parseFacts(output string) *Facts {
+	f := &Facts{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || value == "" {
+			continue
+		}
+		switch key {
+		case "hostname":
+			f.Hostname = value
+		case "ip":
+			f.IP = value
+		case "cpus":
+			f.CPUs, _ = strconv.Atoi(value)
+		case "memory_kb":
+			if kb, err := strconv.ParseInt(value, 10, 64); err == nil {
+				f.MemoryMB = kb / 1024
+			}
+		case "docker_version":
+			f.DockerVersion = value
+		}
+	}
+	return f
+}
+
+// applyOverrides replaces detected facts with BOSUN_HOST_* environment
+// variables, e.g. BOSUN_HOST_IP when the primary interface is the wrong one.
+func (f *Facts) applyOverrides() {
+	if v := os.Getenv("BOSUN_HOST_HOSTNAME"); v != "" {
+		f.Hostname = v
+	}
+	if v := os.Getenv("BOSUN_HOST_IP"); v != "" {
+		f.IP = v
+	}
+}
+
+// Map returns the facts keyed by name (hostname, ip, cpus, memory_mb,
+// docker_version). Facts that could not be read are omitted, so templates
+// referencing them fail loudly instead of rendering an empty value.
+func (f *Facts) Map() map[string]any {
+	m := make(map[string]any)
+	if f.Hostname != "" {
+		m["hostname"] = f.Hostname
+	}
+	if f.IP != "" {
+		m["ip"] = f.IP
+	}
+	if f.CPUs > 0 {
+		m["cpus"] = f.CPUs
+	}
+	if f.MemoryMB > 0 {
+		m["memory_mb"] = f.MemoryMB
+	}
+	if f.DockerVersion != "" {
+		m["docker_version"] = f.DockerVersion
+	}
+	return m
+}
+
+// Variables returns the facts as "host."-prefixed manifest variables,
+// e.g. host.ip for ${host.ip}.
+func (f *Facts) Variables() map[string]any {
+	vars := make(map[string]any)
+	for k, v := range f.Map() {
+		vars["host."+k] = v
+	}
+	return vars
+}
+
+// primaryIP returns the address of the interface used for outbound traffic,
+// falling back to the first non-loopback IPv4 address. Dialing UDP sends
+// no packets; it only selects a route.
+func primaryIP() string {
+	if conn, err := net.Dial("udp", "192.0.2.1:80"); err == nil {
+		defer conn.Close()
+		if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsLoopback() {
+			return addr.IP.String()
+		}
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package hostmetrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFacts(t *testing.T) {
+	f := parseFacts("hostname=tower\nip=192.168.1.8\ncpus=8\nmemory_kb=32768000\ndocker_version=27.1.1\n")
+	assert.Equal(t, &Facts{
+		Hostname:      "tower",
+		IP:            "192.168.1.8",
+		CPUs:          8,
+		MemoryMB:      32000,
+		DockerVersion: "27.1.1",
+	}, f)
+
+	f = parseFacts("hostname=tower\nip=\ndocker_version=\n")
+	assert.Equal(t, &Facts{Hostname: "tower"}, f)
+}
+
+func TestFacts_Variables(t *testing.T) {
+	f := &Facts{Hostname: "tower", IP: "192.168.1.8", CPUs: 8}
+	assert.Equal(t, map[string]any{"hostname": "tower", "ip": "192.168.1.8", "cpus": 8}, f.Map(), "unread facts are omitted")
+	assert.Equal(t, map[string]any{"host.hostname": "tower", "host.ip": "192.168.1.8", "host.cpus": 8}, f.Variables())
+}
+
+func TestLocalFacts(t *testing.T) {
+	t.Setenv("BOSUN_HOST_IP", "10.9.8.7")
+	f := LocalFacts(context.Background())
+	assert.Positive(t, f.CPUs)
+	assert.NotEmpty(t, f.Hostname)
+	assert.Equal(t, "10.9.8.7", f.IP, "BOSUN_HOST_IP overrides detection")
+}
@@ -0,0 +1,192 @@
+// Package hostmetrics reads host-level load, memory, and disk usage.
+// Container stats alone don't show that the host itself is out of memory
+// or disk.
+package hostmetrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiskProbeTimeout bounds how long a single statfs may take. A hung network
+// or FUSE mount must not stall a health check.
+const DiskProbeTimeout = 3 * time.Second
+
+// errUnsupported is returned by collectors on platforms without an implementation.
+var errUnsupported = errors.New("host metrics not supported on this platform")
+
+// Metrics is a point-in-time snapshot of host resource usage.
+// Sections that could not be read are left nil.
+type Metrics struct {
+	Load   *Load       `json:"load,omitempty"`
+	Memory *Memory     `json:"memory,omitempty"`
+	Disks  []DiskUsage `json:"disks,omitempty"`
+}
+
+// Load holds the 1, 5, and 15 minute load averages.
+type Load struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// Memory holds host memory usage in bytes.
+type Memory struct {
+	Total     uint64 `json:"total"`
+	Available uint64 `json:"available"`
+}
+
+// Used returns the memory in use (total minus available).
+func (m Memory) Used() uint64 {
+	if m.Available > m.Total {
+		return 0
+	}
+	return m.Total - m.Available
+}
+
+// UsedPercent returns the percentage of memory in use.
+func (m Memory) UsedPercent() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Used()) / float64(m.Total) * 100
+}
+
+// DiskUsage holds usage of the filesystem containing a path.
+type DiskUsage struct {
+	// Label names the path's role (e.g. "docker", "appdata").
+	Label string `json:"label"`
+	Path  string `json:"path"`
+	// Total, Used, and Available are in bytes. Available is what an
+	// unprivileged user can still write, so Used+Available may be less than Total.
+	Total     uint64 `json:"total"`
+	Used      uint64 `json:"used"`
+	Available uint64 `json:"available"`
+}
+
+// UsedPercent returns the percentage of usable space consumed, matching df.
+func (d DiskUsage) UsedPercent() float64 {
+	if d.Used+d.Available == 0 {
+		return 0
+	}
+	return float64(d.Used) / float64(d.Used+d.Available) * 100
+}
+
+// Path is a filesystem path to report disk usage for.
+type Path struct {
+	Label string
+	Path  string
+}
+
+// Collect gathers load, memory, and disk usage for the given paths.
+// Collection is best-effort: unreadable sections, and paths that error or
+// exceed DiskProbeTimeout, are omitted.
+func Collect(paths []Path) *Metrics {
+	m := &Metrics{}
+	if load, err := readLoad(); err == nil {
+		m.Load = load
+	}
+	if mem, err := readMemory(); err == nil {
+		m.Memory = mem
+	}
+	for _, p := range paths {
+		if p.Path == "" {
+			continue
+		}
+		usage, err := probeDisk(p.Path, DiskProbeTimeout)
+		if err != nil {
+			continue
+		}
+		usage.Label = p.Label
+		m.Disks = append(m.Disks, *usage)
+	}
+	return m
+}
+
+// ProbeDisk returns usage of the filesystem containing p.Path, giving up
+// after DiskProbeTimeout.
+func ProbeDisk(p Path) (*DiskUsage, error) {
+	usage, err := probeDisk(p.Path, DiskProbeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	usage.Label = p.Label
+	return usage, nil
+}
+
+// probeDisk runs diskUsage with a timeout.
+func probeDisk(path string, timeout time.Duration) (*DiskUsage, error) {
+	type result struct {
+		usage *DiskUsage
+		err   error
+	}
+	// Buffered so the goroutine can exit even if the probe times out.
+	ch := make(chan result, 1)
+	go func() {
+		usage, err := diskUsage(path)
+		ch <- result{usage, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.usage, r.err
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("statfs %s timed out after %s", path, timeout)
+	}
+}
+
+// parseLoadAvg parses the contents of /proc/loadavg.
+func parseLoadAvg(content string) (*Load, error) {
+	fields := strings.Fields(content)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("unexpected loadavg format: %q", content)
+	}
+	var vals [3]float64
+	for i := range vals {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse load average %q: %w", fields[i], err)
+		}
+		vals[i] = v
+	}
+	return &Load{Load1: vals[0], Load5: vals[1], Load15: vals[2]}, nil
+}
+
+// parseMemInfo parses the contents of /proc/meminfo. Kernels without
+// MemAvailable fall back to MemFree+Buffers+Cached.
+func parseMemInfo(content string) (*Memory, error) {
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			v *= 1024
+		}
+		values[key] = v
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok {
+		return nil, errors.New("meminfo missing MemTotal")
+	}
+	available, ok := values["MemAvailable"]
+	if !ok {
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	return &Memory{Total: total, Available: available}, nil
+}
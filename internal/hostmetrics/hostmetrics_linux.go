@@ -0,0 +1,42 @@
+//go:build linux
+
+package hostmetrics
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// readLoad reads load averages from /proc/loadavg.
+func readLoad() (*Load, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil, fmt.Errorf("read loadavg: %w", err)
+	}
+	return parseLoadAvg(string(data))
+}
+
+// readMemory reads memory usage from /proc/meminfo.
+func readMemory() (*Memory, error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return nil, fmt.Errorf("read meminfo: %w", err)
+	}
+	return parseMemInfo(string(data))
+}
+
+// diskUsage returns usage of the filesystem containing path.
+func diskUsage(path string) (*DiskUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return nil, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	bsize := uint64(st.Bsize)
+	return &DiskUsage{
+		Path:      path,
+		Total:     st.Blocks * bsize,
+		Used:      (st.Blocks - st.Bfree) * bsize,
+		Available: st.Bavail * bsize,
+	}, nil
+}
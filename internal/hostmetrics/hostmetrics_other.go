@@ -0,0 +1,18 @@
+//go:build !linux
+
+package hostmetrics
+
+// readLoad is unsupported outside Linux.
+func readLoad() (*Load, error) {
+	return nil, errUnsupported
+}
+
+// readMemory is unsupported outside Linux.
+func readMemory() (*Memory, error) {
+	return nil, errUnsupported
+}
+
+// diskUsage is unsupported outside Linux.
+func diskUsage(_ string) (*DiskUsage, error) {
+	return nil, errUnsupported
+}
@@ -0,0 +1,106 @@
+package hostmetrics
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLoadAvg(t *testing.T) {
+	load, err := parseLoadAvg("0.52 1.25 2.00 3/812 12345\n")
+	require.NoError(t, err)
+	assert.Equal(t, &Load{Load1: 0.52, Load5: 1.25, Load15: 2.00}, load)
+
+	_, err = parseLoadAvg("0.52")
+	assert.Error(t, err)
+
+	_, err = parseLoadAvg("a b c")
+	assert.Error(t, err)
+}
+
+func TestParseMemInfo(t *testing.T) {
+	t.Run("with MemAvailable", func(t *testing.T) {
+		mem, err := parseMemInfo(`MemTotal:       16000000 kB
+MemFree:         1000000 kB
+MemAvailable:    4000000 kB
+Buffers:          200000 kB
+Cached:          2000000 kB
+`)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(16000000*1024), mem.Total)
+		assert.Equal(t, uint64(4000000*1024), mem.Available)
+		assert.InDelta(t, 75.0, mem.UsedPercent(), 0.001)
+	})
+
+	t.Run("legacy kernel without MemAvailable", func(t *testing.T) {
+		mem, err := parseMemInfo(`MemTotal: 1000 kB
+MemFree: 100 kB
+Buffers: 50 kB
+Cached: 250 kB
+`)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(400*1024), mem.Available)
+	})
+
+	t.Run("missing MemTotal", func(t *testing.T) {
+		_, err := parseMemInfo("MemFree: 100 kB\n")
+		assert.Error(t, err)
+	})
+}
+
+func TestMemory_Used(t *testing.T) {
+	assert.Equal(t, uint64(0), Memory{Total: 10, Available: 20}.Used())
+	assert.Equal(t, 0.0, Memory{}.UsedPercent())
+}
+
+func TestDiskUsage_UsedPercent(t *testing.T) {
+	// Reserved blocks make Used+Available smaller than Total; df reports against the usable space.
+	d := DiskUsage{Total: 100, Used: 45, Available: 45}
+	assert.InDelta(t, 50.0, d.UsedPercent(), 0.001)
+	assert.Equal(t, 0.0, DiskUsage{}.UsedPercent())
+}
+
+func TestCollect(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("host metrics are only collected on Linux")
+	}
+
+	dir := t.TempDir()
+	m := Collect([]Path{
+		{Label: "temp", Path: dir},
+		{Label: "missing", Path: "/nonexistent/bosun/path"},
+		{Label: "unset", Path: ""},
+	})
+
+	require.NotNil(t, m.Load)
+	require.NotNil(t, m.Memory)
+	assert.Greater(t, m.Memory.Total, uint64(0))
+	require.Len(t, m.Disks, 1)
+	assert.Equal(t, "temp", m.Disks[0].Label)
+	assert.Equal(t, dir, m.Disks[0].Path)
+	assert.Greater(t, m.Disks[0].Total, uint64(0))
+}
+
+func TestParseRemoteDisks(t *testing.T) {
+	paths := []Path{
+		{Label: "appdata", Path: "/mnt/user/appdata"},
+		{Label: "docker", Path: "/var/lib/docker"},
+		{Label: "missing", Path: "/nope"},
+	}
+	output := "0 shfs 1000 400 600 40% /mnt/user\n" +
+		"1 /dev/loop2 2000 1500 500 75% /var/lib/docker\n" +
+		"2 \n"
+
+	disks := parseRemoteDisks(output, paths)
+	require.Len(t, disks, 2)
+	assert.Equal(t, DiskUsage{Label: "appdata", Path: "/mnt/user/appdata", Total: 1000 * 1024, Used: 400 * 1024, Available: 600 * 1024}, disks[0])
+	assert.Equal(t, "docker", disks[1].Label)
+	assert.Equal(t, uint64(500*1024), disks[1].Available)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/mnt/user/app data'`, shellQuote("/mnt/user/app data"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
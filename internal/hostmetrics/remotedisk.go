@@ -0,0 +1,76 @@
+package hostmetrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// RemoteDisks returns usage of the filesystems containing paths on a remote
+// host ("user@host"), read with df over SSH. Paths that don't exist on the
+// host are omitted.
+func RemoteDisks(ctx context.Context, target string, paths []Path) ([]DiskUsage, error) {
+	var script strings.Builder
+	for i, p := range paths {
+		if p.Path == "" {
+			continue
+		}
+		// One "<index> <df line>" per path, so missing paths can't shift the rest.
+		fmt.Fprintf(&script, "echo \"%d $(df -Pk -- %s 2>/dev/null | tail -n 1)\"\n", i, shellQuote(p.Path))
+	}
+	if script.Len() == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, FactsTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", target, script.String()).Output()
+	if err != nil {
+		return nil, fmt.Errorf("read disk usage from %s: %w", target, err)
+	}
+	return parseRemoteDisks(string(out), paths), nil
+}
+
+// parseRemoteDisks parses the "<index> <df -Pk line>" output of RemoteDisks.
+func parseRemoteDisks(output string, paths []Path) []DiskUsage {
+	var disks []DiskUsage
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// index, filesystem, 1024-blocks, used, available, capacity, mount
+		if len(fields) < 7 {
+			continue
+		}
+		i, err := strconv.Atoi(fields[0])
+		if err != nil || i < 0 || i >= len(paths) {
+			continue
+		}
+		var kb [3]uint64
+		ok := true
+		for j := range kb {
+			if kb[j], err = strconv.ParseUint(fields[2+j], 10, 64); err != nil {
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+		disks = append(disks, DiskUsage{
+			Label:     paths[i].Label,
+			Path:      paths[i].Path,
+			Total:     kb[0] * 1024,
+			Used:      kb[1] * 1024,
+			Available: kb[2] * 1024,
+		})
+	}
+	return disks
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
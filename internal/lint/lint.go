@@ -0,0 +1,315 @@
+// Package lint checks rendered compose files and Traefik dynamic config for
+// problems that would break or half-complete a deploy.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+// Severity of a lint finding.
+type Severity string
+
+const (
+	// SeverityError findings would break the deploy.
+	SeverityError Severity = "error"
+	// SeverityWarning findings are suspicious but deployable.
+	SeverityWarning Severity = "warning"
+)
+
+// Rule names reported in findings.
+const (
+	RuleParse           = "parse"
+	RuleValues          = "values"
+	RuleDependsOn       = "depends-on"
+	RuleDependencyCycle = "dependency-cycle"
+	RulePortConflict    = "port-conflict"
+	RuleSchema          = "schema"
+
+	// Traefik dynamic config rules (see TraefikFile).
+	RuleTraefikRef        = "traefik-ref"
+	RuleTraefikRule       = "traefik-rule"
+	RuleTraefikEntryPoint = "traefik-entrypoint"
+)
+
+// Finding is a single lint result.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// File is the file name (e.g. "core.yml" or "traefik/dynamic.yml").
+	File    string
+	Message string
+}
+
+// String formats the finding for logs and alerts.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s [%s]", f.File, f.Message, f.Rule)
+}
+
+// Result holds the findings of a lint run.
+type Result struct {
+	Findings []Finding
+}
+
+// Errors returns the error-severity findings.
+func (r *Result) Errors() []Finding {
+	return r.filter(SeverityError)
+}
+
+// Warnings returns the warning-severity findings.
+func (r *Result) Warnings() []Finding {
+	return r.filter(SeverityWarning)
+}
+
+func (r *Result) filter(severity Severity) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (r *Result) add(rule string, severity Severity, file, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{
+		Rule:     rule,
+		Severity: severity,
+		File:     file,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// ComposeDir lints every *.yml compose file in dir. A missing directory
+// yields no findings.
+func ComposeDir(dir string) (*Result, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("list compose files: %w", err)
+	}
+	sort.Strings(files)
+	return ComposeFiles(files)
+}
+
+// ComposeFiles lints the given compose files. Port conflicts are checked
+// across all files, since every stack shares the host's ports.
+func ComposeFiles(files []string) (*Result, error) {
+	result := &Result{}
+	ports := make(map[string][]portBinding) // "8080/tcp" -> bindings
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		file := filepath.Base(path)
+
+		var compose map[string]any
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			result.add(RuleParse, SeverityError, file, "invalid YAML: %v", err)
+			continue
+		}
+
+		services, ok := compose["services"].(map[string]any)
+		if raw, present := compose["services"]; present && raw != nil && !ok {
+			result.add(RuleSchema, SeverityError, file, "services must be a mapping, got %s", shapeOf(raw))
+			continue
+		}
+		if len(services) == 0 {
+			continue
+		}
+
+		for _, name := range sortedKeys(services) {
+			for _, problem := range checkSchema(name, services[name]) {
+				result.add(RuleSchema, SeverityError, file, "%s", problem)
+			}
+		}
+
+		for _, e := range manifest.ValidateOutputValues(&manifest.RenderOutput{Compose: compose}) {
+			result.add(RuleValues, SeverityError, file, "%s", strings.TrimPrefix(e.Error(), "compose.services."))
+		}
+
+		graph := make(map[string][]string, len(services))
+		for _, name := range sortedKeys(services) {
+			svc, _ := services[name].(map[string]any)
+			graph[name] = dependsOn(svc["depends_on"])
+
+			for _, dep := range graph[name] {
+				if _, ok := services[dep]; !ok {
+					result.add(RuleDependsOn, SeverityError, file, "%s depends on undefined service %s", name, dep)
+				}
+			}
+
+			if list, ok := svc["ports"].([]any); ok {
+				for _, entry := range list {
+					for _, b := range hostBindings(entry) {
+						b.file, b.service = file, name
+						key := fmt.Sprintf("%d/%s", b.port, b.proto)
+						for _, other := range ports[key] {
+							if other.service != name && other.overlaps(b) {
+								result.add(RulePortConflict, SeverityError, file,
+									"host port %s published by %s and %s (%s)", key, name, other.service, other.file)
+							}
+						}
+						ports[key] = append(ports[key], b)
+					}
+				}
+			}
+		}
+
+		for _, cycle := range findCycles(graph) {
+			result.add(RuleDependencyCycle, SeverityError, file, "dependency cycle: %s", cycle)
+		}
+	}
+
+	return result, nil
+}
+
+// dependsOn returns the service names from a depends_on list or map.
+func dependsOn(v any) []string {
+	var deps []string
+	switch d := v.(type) {
+	case []any:
+		for _, name := range d {
+			if s, ok := name.(string); ok {
+				deps = append(deps, s)
+			}
+		}
+	case map[string]any:
+		deps = sortedKeys(d)
+	}
+	return deps
+}
+
+// findCycles returns each dependency cycle in graph as "a -> b -> a".
+func findCycles(graph map[string][]string) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var cycles []string
+
+	var visit func(node string, path []string)
+	visit = func(node string, path []string) {
+		state[node] = inProgress
+		path = append(path, node)
+		for _, next := range graph[node] {
+			switch state[next] {
+			case inProgress:
+				for i, n := range path {
+					if n == next {
+						cycle := strings.Join(append(append([]string{}, path[i:]...), next), " -> ")
+						if !seen[cycle] {
+							seen[cycle] = true
+							cycles = append(cycles, cycle)
+						}
+						break
+					}
+				}
+			case unvisited:
+				visit(next, path)
+			}
+		}
+		state[node] = done
+	}
+
+	nodes := make([]string, 0, len(graph))
+	for n := range graph {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			visit(n, nil)
+		}
+	}
+	return cycles
+}
+
+// portBinding is a published host port.
+type portBinding struct {
+	ip      string // Empty binds all interfaces
+	port    int
+	proto   string
+	file    string
+	service string
+}
+
+// overlaps reports whether two bindings of the same port/protocol collide.
+// Bindings on different specific IPs can coexist.
+func (b portBinding) overlaps(other portBinding) bool {
+	return b.ip == "" || other.ip == "" || b.ip == other.ip
+}
+
+// hostBindings returns the host ports published by a compose port entry.
+// Entries that only expose a container port publish nothing.
+func hostBindings(entry any) []portBinding {
+	switch v := entry.(type) {
+	case map[string]any:
+		published, ok := v["published"]
+		if !ok {
+			return nil
+		}
+		proto, _ := v["protocol"].(string)
+		ip, _ := v["host_ip"].(string)
+		return portRange(ip, fmt.Sprint(published), proto)
+	case string:
+		spec, proto, _ := strings.Cut(v, "/")
+		parts := strings.Split(spec, ":")
+		switch len(parts) {
+		case 2:
+			return portRange("", parts[0], proto)
+		case 3:
+			return portRange(parts[0], parts[1], proto)
+		}
+	}
+	return nil
+}
+
+// portRange expands "8080" or "8000-8010" into bindings. Unparseable values yield none.
+func portRange(ip, spec, proto string) []portBinding {
+	if proto == "" {
+		proto = "tcp"
+	}
+	if ip == "0.0.0.0" {
+		ip = ""
+	}
+
+	startStr, endStr, isRange := strings.Cut(spec, "-")
+	start, err := strconv.Atoi(startStr)
+	if err != nil || start <= 0 {
+		return nil
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(endStr); err != nil || end < start {
+			return nil
+		}
+	}
+
+	bindings := make([]portBinding, 0, end-start+1)
+	for p := start; p <= end; p++ {
+		bindings = append(bindings, portBinding{ip: ip, port: p, proto: proto})
+	}
+	return bindings
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCompose(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func rules(findings []Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, f.Rule)
+	}
+	return out
+}
+
+func TestComposeDir(t *testing.T) {
+	t.Run("missing directory", func(t *testing.T) {
+		result, err := ComposeDir(filepath.Join(t.TempDir(), "missing"))
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("clean compose", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  app:
+    image: app
+    mem_limit: 512m
+    ports: ["8080:80"]
+    depends_on:
+      db:
+        condition: service_healthy
+  db:
+    image: postgres
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("invalid YAML", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", "services: [unclosed")
+
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		require.Len(t, result.Errors(), 1)
+		assert.Equal(t, RuleParse, result.Errors()[0].Rule)
+		assert.Equal(t, "core.yml", result.Errors()[0].File)
+	})
+
+	t.Run("bad values", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  app:
+    image: app
+    mem_limit: 512
+    stop_grace_period: 30
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{RuleValues, RuleValues}, rules(result.Errors()))
+		assert.Contains(t, result.Errors()[0].String(), "app.mem_limit")
+	})
+
+	t.Run("undefined dependency", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  app:
+    image: app
+    depends_on: [db]
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		require.Len(t, result.Errors(), 1)
+		assert.Equal(t, "core.yml: app depends on undefined service db [depends-on]", result.Errors()[0].String())
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  a:
+    depends_on: [b]
+  b:
+    depends_on: [a]
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		require.Len(t, result.Errors(), 1)
+		assert.Equal(t, RuleDependencyCycle, result.Errors()[0].Rule)
+		assert.Contains(t, result.Errors()[0].Message, "a -> b -> a")
+	})
+
+	t.Run("port conflict across files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  traefik:
+    ports: ["80:80", "443:443"]
+`)
+		writeCompose(t, dir, "media.yml", `
+services:
+  nginx:
+    ports:
+      - published: 443
+        target: 443
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		require.Len(t, result.Errors(), 1)
+		assert.Equal(t, RulePortConflict, result.Errors()[0].Rule)
+		assert.Contains(t, result.Errors()[0].Message, "443/tcp published by nginx and traefik (core.yml)")
+	})
+
+	t.Run("service schema", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  app:
+    image: app
+    ports:
+      http: "8080:80"
+    environment:
+      - TZ=UTC
+    restart: sometimes
+    command:
+  broken: app
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{RuleSchema, RuleSchema, RuleSchema}, rules(result.Errors()))
+		assert.Equal(t, "core.yml: app.ports must be a list, got map [schema]", result.Errors()[0].String())
+		assert.Contains(t, result.Errors()[1].Message, `app.restart "sometimes" is not a restart policy`)
+		assert.Equal(t, "broken must be a mapping, got string", result.Errors()[2].Message)
+	})
+
+	t.Run("services not a mapping", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", "services:\n  - app\n")
+
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		require.Len(t, result.Errors(), 1)
+		assert.Equal(t, "services must be a mapping, got list", result.Errors()[0].Message)
+	})
+
+	t.Run("ports that can coexist", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCompose(t, dir, "core.yml", `
+services:
+  dns:
+    ports: ["53:53/tcp", "53:53/udp"]
+  a:
+    ports: ["127.0.0.1:8080:80", "9000"]
+  b:
+    ports: ["192.168.1.2:8080:80", "9000"]
+  c:
+    ports: ["5353:53/udp"]
+`)
+		result, err := ComposeDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings)
+	})
+}
+
+func TestHostBindings(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry any
+		want  []portBinding
+	}{
+		{"container only", "80", nil},
+		{"int container only", 80, nil},
+		{"mapping", "8080:80", []portBinding{{port: 8080, proto: "tcp"}}},
+		{"udp", "53:53/udp", []portBinding{{port: 53, proto: "udp"}}},
+		{"host ip", "127.0.0.1:8080:80", []portBinding{{ip: "127.0.0.1", port: 8080, proto: "tcp"}}},
+		{"all interfaces", "0.0.0.0:8080:80", []portBinding{{port: 8080, proto: "tcp"}}},
+		{"range", "8000-8001:8000-8001", []portBinding{{port: 8000, proto: "tcp"}, {port: 8001, proto: "tcp"}}},
+		{"long syntax", map[string]any{"published": "8443", "target": 443}, []portBinding{{port: 8443, proto: "tcp"}}},
+		{"long syntax unpublished", map[string]any{"target": 443}, nil},
+		{"garbage", "abc:80", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, hostBindings(tc.entry))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Shapes a compose service field can take.
+const (
+	shapeString = "string"
+	shapeList   = "list"
+	shapeMap    = "map"
+)
+
+// serviceFieldShapes lists the shapes compose accepts for common service
+// fields. Fields not listed are not checked.
+var serviceFieldShapes = map[string][]string{
+	"image":          {shapeString},
+	"container_name": {shapeString},
+	"restart":        {shapeString},
+	"command":        {shapeString, shapeList},
+	"entrypoint":     {shapeString, shapeList},
+	"env_file":       {shapeString, shapeList},
+	"environment":    {shapeList, shapeMap},
+	"labels":         {shapeList, shapeMap},
+	"depends_on":     {shapeList, shapeMap},
+	"networks":       {shapeList, shapeMap},
+	"ports":          {shapeList},
+	"volumes":        {shapeList},
+	"devices":        {shapeList},
+	"extra_hosts":    {shapeList, shapeMap},
+	"healthcheck":    {shapeMap},
+	"deploy":         {shapeMap},
+	"logging":        {shapeMap},
+}
+
+// restartPolicy matches the restart policies compose accepts.
+var restartPolicy = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:\d+)?)$`)
+
+// checkSchema reports service fields whose shape compose would reject, such
+// as a ports mapping instead of a list, or an unknown restart policy. Null
+// fields are left to compose.
+func checkSchema(name string, svc any) []string {
+	fields, ok := svc.(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s must be a mapping, got %s", name, shapeOf(svc))}
+	}
+
+	var problems []string
+	for _, field := range sortedKeys(fields) {
+		want, known := serviceFieldShapes[field]
+		if !known || fields[field] == nil {
+			continue
+		}
+		got := shapeOf(fields[field])
+		if !slices.Contains(want, got) {
+			problems = append(problems, fmt.Sprintf("%s.%s must be a %s, got %s", name, field, strings.Join(want, " or "), got))
+		}
+	}
+	if policy, ok := fields["restart"].(string); ok && !restartPolicy.MatchString(policy) {
+		problems = append(problems, fmt.Sprintf("%s.restart %q is not a restart policy (want no, always, on-failure[:N], or unless-stopped)", name, policy))
+	}
+	return problems
+}
+
+// shapeOf names the shape of a decoded YAML value. Other scalars, such as
+// numbers and booleans, count as strings, since compose reads them as text.
+func shapeOf(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return shapeMap
+	case []any:
+		return shapeList
+	}
+	return shapeString
+}
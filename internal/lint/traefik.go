@@ -0,0 +1,436 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Matchers Traefik accepts in HTTP router rules, in v2 and v3.
+var httpMatchers = map[string]bool{
+	"ClientIP": true, "Method": true,
+	"Host": true, "HostHeader": true, "HostRegexp": true,
+	"Path": true, "PathPrefix": true, "PathRegexp": true,
+	"Header": true, "HeaderRegexp": true, "Headers": true, "HeadersRegexp": true,
+	"Query": true, "QueryRegexp": true,
+}
+
+// Matchers Traefik accepts in TCP router rules.
+var tcpMatchers = map[string]bool{
+	"HostSNI": true, "HostSNIRegexp": true, "ClientIP": true, "ALPN": true,
+}
+
+// TraefikFile lints a rendered Traefik dynamic config (traefik/dynamic.yml):
+// router rules must parse, and the services, middlewares, and TLS options
+// routers and services refer to must be defined in the file or, since the
+// file provider may load the whole directory, in another YAML file beside
+// it other than the static config (traefik.yml). References to another
+// provider (name@docker) can't be checked and are skipped. When
+// entryPoints is non-empty, routers may only use those entrypoints. A
+// missing file yields no findings.
+func TraefikFile(path string, entryPoints []string) (*Result, error) {
+	result := &Result{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	file := filepath.Join(filepath.Base(filepath.Dir(path)), filepath.Base(path))
+
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		result.add(RuleParse, SeverityError, file, "invalid YAML: %v", err)
+		return result, nil
+	}
+
+	known := make(map[string]bool, len(entryPoints))
+	for _, ep := range entryPoints {
+		known[ep] = true
+	}
+	siblings := traefikSiblings(path)
+	tlsOptions := defined(root, siblings, "tls", "options")
+
+	for _, proto := range []string{"http", "tcp", "udp"} {
+		section := mapping(field(root, proto))
+		routers := mapping(field(section, "routers"))
+		services := defined(root, siblings, proto, "services")
+		middlewares := defined(root, siblings, proto, "middlewares")
+		prefix := proto + " "
+		if proto == "http" {
+			prefix = ""
+		}
+
+		for _, name := range sortedKeys(routers) {
+			router := mapping(routers[name])
+			if router == nil {
+				result.add(RuleSchema, SeverityError, file, "%srouter %s must be a mapping", prefix, name)
+				continue
+			}
+			where := fmt.Sprintf("%srouter %s", prefix, name)
+
+			if proto != "udp" {
+				matchers := httpMatchers
+				if proto == "tcp" {
+					matchers = tcpMatchers
+				}
+				rule, _ := field(router, "rule").(string)
+				if strings.TrimSpace(rule) == "" {
+					result.add(RuleTraefikRule, SeverityError, file, "%s has no rule", where)
+				} else if err := parseTraefikRule(rule, matchers); err != nil {
+					result.add(RuleTraefikRule, SeverityError, file, "%s: invalid rule %q: %v", where, rule, err)
+				}
+			}
+
+			if service, _ := field(router, "service").(string); service == "" {
+				result.add(RuleTraefikRef, SeverityError, file, "%s has no service", where)
+			} else {
+				checkTraefikRef(result, file, where, "service", service, services)
+			}
+			for _, mw := range stringList(field(router, "middlewares")) {
+				checkTraefikRef(result, file, where, "middleware", mw, middlewares)
+			}
+			if options, _ := field(mapping(field(router, "tls")), "options").(string); options != "" {
+				checkTraefikRef(result, file, where, "TLS option", options, tlsOptions)
+			}
+
+			if len(known) > 0 {
+				for _, ep := range stringList(field(router, "entryPoints")) {
+					if !known[ep] {
+						result.add(RuleTraefikEntryPoint, SeverityError, file, "%s uses undefined entrypoint %s", where, ep)
+					}
+				}
+			}
+		}
+
+		own := mapping(field(section, "services"))
+		for _, name := range sortedKeys(own) {
+			where := fmt.Sprintf("%sservice %s", prefix, name)
+			for _, ref := range serviceRefs(mapping(own[name])) {
+				checkTraefikRef(result, file, where, "service", ref, services)
+			}
+		}
+		own = mapping(field(section, "middlewares"))
+		for _, name := range sortedKeys(own) {
+			where := fmt.Sprintf("%smiddleware %s", prefix, name)
+			chain := mapping(field(mapping(own[name]), "chain"))
+			for _, ref := range stringList(field(chain, "middlewares")) {
+				checkTraefikRef(result, file, where, "middleware", ref, middlewares)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// TraefikEntryPoints returns the entrypoints Traefik's static config
+// defines, sorted: those in traefik.yml (or traefik.yaml) in dir, and those
+// set with --entrypoints.<name>.address in the command of a service in the
+// compose files. It returns nil when none are found.
+func TraefikEntryPoints(dir string, composeFiles []string) []string {
+	found := make(map[string]bool)
+	for _, name := range []string{"traefik.yml", "traefik.yaml"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		var static map[string]any
+		if yaml.Unmarshal(data, &static) == nil {
+			for ep := range mapping(field(static, "entryPoints")) {
+				found[ep] = true
+			}
+		}
+	}
+
+	for _, path := range composeFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var compose map[string]any
+		if yaml.Unmarshal(data, &compose) != nil {
+			continue
+		}
+		services, _ := compose["services"].(map[string]any)
+		for _, svc := range services {
+			command := mapping(svc)["command"]
+			args := stringList(command)
+			if s, ok := command.(string); ok {
+				args = strings.Fields(s)
+			}
+			for _, arg := range args {
+				flag, _, _ := strings.Cut(arg, "=")
+				parts := strings.Split(flag, ".")
+				if len(parts) >= 3 && strings.EqualFold(parts[0], "--entrypoints") {
+					found[parts[1]] = true
+				}
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+	eps := make([]string, 0, len(found))
+	for ep := range found {
+		eps = append(eps, ep)
+	}
+	sort.Strings(eps)
+	return eps
+}
+
+// traefikSiblings parses the other YAML files in path's directory, except
+// the static config. Files that don't parse are skipped.
+func traefikSiblings(path string) []map[string]any {
+	var configs []map[string]any
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), pattern))
+		for _, file := range files {
+			switch filepath.Base(file) {
+			case filepath.Base(path), "traefik.yml", "traefik.yaml":
+				continue
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			var config map[string]any
+			if yaml.Unmarshal(data, &config) == nil {
+				configs = append(configs, config)
+			}
+		}
+	}
+	return configs
+}
+
+// defined returns the objects of a kind (such as http services) defined in
+// root or any of the sibling configs, by name.
+func defined(root map[string]any, siblings []map[string]any, section, kind string) map[string]any {
+	objects := make(map[string]any)
+	for _, config := range append([]map[string]any{root}, siblings...) {
+		for name, v := range mapping(field(mapping(field(config, section)), kind)) {
+			if _, ok := objects[name]; !ok {
+				objects[name] = v
+			}
+		}
+	}
+	return objects
+}
+
+// checkTraefikRef reports a reference to a file-provider object that isn't
+// defined. name@file refers to the same object as name; other providers'
+// objects are skipped.
+func checkTraefikRef(result *Result, file, where, kind, ref string, defined map[string]any) {
+	name, provider, qualified := strings.Cut(ref, "@")
+	if qualified && provider != "file" {
+		return
+	}
+	if _, ok := defined[name]; !ok {
+		result.add(RuleTraefikRef, SeverityError, file, "%s references undefined %s %s", where, kind, ref)
+	}
+}
+
+// serviceRefs returns the services a weighted, mirroring, or failover
+// service refers to.
+func serviceRefs(service map[string]any) []string {
+	var refs []string
+	for _, entry := range list(field(mapping(field(service, "weighted")), "services")) {
+		if name, _ := field(mapping(entry), "name").(string); name != "" {
+			refs = append(refs, name)
+		}
+	}
+	mirroring := mapping(field(service, "mirroring"))
+	if name, _ := field(mirroring, "service").(string); name != "" {
+		refs = append(refs, name)
+	}
+	for _, entry := range list(field(mirroring, "mirrors")) {
+		if name, _ := field(mapping(entry), "name").(string); name != "" {
+			refs = append(refs, name)
+		}
+	}
+	failover := mapping(field(service, "failover"))
+	for _, key := range []string{"service", "fallback"} {
+		if name, _ := field(failover, key).(string); name != "" {
+			refs = append(refs, name)
+		}
+	}
+	return refs
+}
+
+// field returns the value of key in m, matching case-insensitively as
+// Traefik does (entryPoints and entrypoints are the same key).
+func field(m map[string]any, key string) any {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// mapping returns v as a mapping, or nil.
+func mapping(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+// stringList returns the strings of a sequence, or of a comma-separated
+// string.
+func stringList(v any) []string {
+	if s, ok := v.(string); ok {
+		var out []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+	var out []string
+	for _, item := range list(v) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// list returns v as a sequence, or nil.
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+// parseTraefikRule checks the syntax of a router rule: matchers such as
+// Host(`example.com`), each with one or more quoted arguments, combined
+// with &&, ||, !, and parentheses. matchers are the names allowed.
+func parseTraefikRule(rule string, matchers map[string]bool) error {
+	p := &ruleParser{src: rule, matchers: matchers}
+	if err := p.expr(); err != nil {
+		return err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:], p.pos)
+	}
+	return nil
+}
+
+// ruleParser is a recursive-descent parser over a router rule.
+type ruleParser struct {
+	src      string
+	pos      int
+	matchers map[string]bool
+}
+
+func (p *ruleParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume skips spaces and then tok, reporting whether it was there.
+func (p *ruleParser) consume(tok string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// expr := and ("||" and)*
+func (p *ruleParser) expr() error {
+	if err := p.and(); err != nil {
+		return err
+	}
+	for p.consume("||") {
+		if err := p.and(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// and := unary ("&&" unary)*
+func (p *ruleParser) and() error {
+	if err := p.unary(); err != nil {
+		return err
+	}
+	for p.consume("&&") {
+		if err := p.unary(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unary := "!" unary | "(" expr ")" | matcher
+func (p *ruleParser) unary() error {
+	switch {
+	case p.consume("!"):
+		return p.unary()
+	case p.consume("("):
+		if err := p.expr(); err != nil {
+			return err
+		}
+		if !p.consume(")") {
+			return fmt.Errorf("missing ) at offset %d", p.pos)
+		}
+		return nil
+	}
+	return p.matcher()
+}
+
+// matcher := name "(" arg ("," arg)* ")"
+func (p *ruleParser) matcher() error {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && isLetter(p.src[p.pos]) {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	switch {
+	case name == "" && p.pos == len(p.src):
+		return fmt.Errorf("unexpected end of rule")
+	case name == "":
+		return fmt.Errorf("expected a matcher at offset %d", start)
+	case !p.matchers[name]:
+		return fmt.Errorf("unknown matcher %s", name)
+	}
+
+	if !p.consume("(") {
+		return fmt.Errorf("%s: missing ( at offset %d", name, p.pos)
+	}
+	for {
+		p.skipSpace()
+		if p.pos == len(p.src) || (p.src[p.pos] != '`' && p.src[p.pos] != '"') {
+			return fmt.Errorf("%s: expected a quoted argument at offset %d", name, p.pos)
+		}
+		quote := p.src[p.pos]
+		end := strings.IndexByte(p.src[p.pos+1:], quote)
+		if end < 0 {
+			return fmt.Errorf("%s: unterminated %c at offset %d", name, quote, p.pos)
+		}
+		p.pos += end + 2
+		if !p.consume(",") {
+			break
+		}
+	}
+	if !p.consume(")") {
+		return fmt.Errorf("%s: missing ) at offset %d", name, p.pos)
+	}
+	return nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
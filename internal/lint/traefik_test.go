@@ -0,0 +1,242 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTraefik(t *testing.T, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "traefik")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	return writeCompose(t, dir, "dynamic.yml", content)
+}
+
+func TestTraefikFile(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		result, err := TraefikFile(filepath.Join(t.TempDir(), "dynamic.yml"), nil)
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("clean config", func(t *testing.T) {
+		path := writeTraefik(t, `
+http:
+  routers:
+    web:
+      rule: Host(`+"`web.example.com`"+`) && (PathPrefix(`+"`/api`"+`) || !Path("/health"))
+      service: web
+      entrypoints: [websecure]
+      middlewares: [secure, auth@docker]
+      tls:
+        options: modern@file
+    dashboard:
+      rule: Host(`+"`traefik.example.com`"+`)
+      service: api@internal
+  services:
+    web:
+      loadBalancer:
+        servers:
+          - url: http://web:8080
+    canary:
+      weighted:
+        services:
+          - name: web
+            weight: 3
+  middlewares:
+    headers:
+      headers:
+        stsSeconds: 31536000
+    secure:
+      chain:
+        middlewares: [headers]
+tcp:
+  routers:
+    db:
+      rule: HostSNI(`+"`*`"+`)
+      service: db
+  services:
+    db:
+      loadBalancer:
+        servers:
+          - address: db:5432
+tls:
+  options:
+    modern:
+      minVersion: VersionTLS13
+`)
+		result, err := TraefikFile(path, []string{"web", "websecure"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("undefined references", func(t *testing.T) {
+		path := writeTraefik(t, `
+http:
+  routers:
+    web:
+      rule: Host(`+"`web.example.com`"+`)
+      service: missing
+      middlewares: auth, headers@file
+      tls:
+        options: strict
+    nosvc:
+      rule: Host(`+"`x.example.com`"+`)
+  services:
+    mirror:
+      mirroring:
+        service: gone
+  middlewares:
+    secure:
+      chain:
+        middlewares: [absent]
+`)
+		result, err := TraefikFile(path, nil)
+		require.NoError(t, err)
+		require.Len(t, result.Errors(), 7)
+		msgs := make([]string, 0, len(result.Findings))
+		for _, f := range result.Findings {
+			assert.Equal(t, "traefik/dynamic.yml", f.File)
+			msgs = append(msgs, f.Message)
+		}
+		assert.Equal(t, []string{
+			"router nosvc has no service",
+			"router web references undefined service missing",
+			"router web references undefined middleware auth",
+			"router web references undefined middleware headers@file",
+			"router web references undefined TLS option strict",
+			"service mirror references undefined service gone",
+			"middleware secure references undefined middleware absent",
+		}, msgs)
+	})
+
+	t.Run("rule syntax", func(t *testing.T) {
+		path := writeTraefik(t, `
+http:
+  routers:
+    typo:
+      rule: Hots(`+"`a.example.com`"+`)
+      service: web
+    unbalanced:
+      rule: (Host(`+"`a.example.com`"+`) || Host(`+"`b.example.com`"+`)
+      service: web
+    unquoted:
+      rule: Host(a.example.com)
+      service: web
+    dangling:
+      rule: Host(`+"`a.example.com`"+`) &&
+      service: web
+    norule:
+      service: web
+  services:
+    web:
+      loadBalancer:
+        servers:
+          - url: http://web
+tcp:
+  routers:
+    db:
+      rule: Host(`+"`db.example.com`"+`)
+      service: db
+  services:
+    db:
+      loadBalancer:
+        servers:
+          - address: db:5432
+`)
+		result, err := TraefikFile(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{RuleTraefikRule, RuleTraefikRule, RuleTraefikRule, RuleTraefikRule, RuleTraefikRule, RuleTraefikRule}, rules(result.Findings))
+		assert.Contains(t, result.Findings[0].Message, "router dangling: invalid rule")
+		assert.Contains(t, result.Findings[1].Message, "router norule has no rule")
+		assert.Contains(t, result.Findings[2].Message, "unknown matcher Hots")
+		assert.Contains(t, result.Findings[5].Message, "tcp router db: invalid rule")
+	})
+
+	t.Run("references resolve across the directory", func(t *testing.T) {
+		path := writeTraefik(t, `
+http:
+  routers:
+    web:
+      rule: Host(`+"`web.example.com`"+`)
+      service: web
+      middlewares: [auth]
+`)
+		writeCompose(t, filepath.Dir(path), "shared.yml", `
+http:
+  middlewares:
+    auth:
+      forwardAuth:
+        address: http://authelia:9091
+  services:
+    web:
+      loadBalancer:
+        servers:
+          - url: http://web
+`)
+		writeCompose(t, filepath.Dir(path), "traefik.yml", "http:\n  services:\n    ignored: {}\n")
+		result, err := TraefikFile(path, nil)
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("undefined entrypoints", func(t *testing.T) {
+		path := writeTraefik(t, `
+http:
+  routers:
+    web:
+      rule: Host(`+"`web.example.com`"+`)
+      service: web
+      entryPoints: [websecure, https]
+  services:
+    web:
+      loadBalancer:
+        servers:
+          - url: http://web
+`)
+		result, err := TraefikFile(path, []string{"web", "websecure"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{RuleTraefikEntryPoint}, rules(result.Findings))
+		assert.Contains(t, result.Findings[0].Message, "undefined entrypoint https")
+
+		result, err = TraefikFile(path, nil)
+		require.NoError(t, err)
+		assert.Empty(t, result.Findings, "entrypoints aren't checked when unknown")
+	})
+
+	t.Run("invalid YAML", func(t *testing.T) {
+		path := writeTraefik(t, "http: [unclosed\n")
+		result, err := TraefikFile(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{RuleParse}, rules(result.Findings))
+	})
+}
+
+func TestTraefikEntryPoints(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, TraefikEntryPoints(dir, nil))
+
+	writeCompose(t, dir, "traefik.yml", `
+entryPoints:
+  web:
+    address: ":80"
+  websecure:
+    address: ":443"
+`)
+	compose := writeCompose(t, dir, "core.yml", `
+services:
+  traefik:
+    image: traefik:v3
+    command:
+      - --providers.file.filename=/etc/traefik/dynamic.yml
+      - --entrypoints.metrics.address=:8082
+  other:
+    image: app
+    command: serve --entryPoints.admin.address=:9000
+`)
+	assert.Equal(t, []string{"admin", "metrics", "web", "websecure"}, TraefikEntryPoints(dir, []string{compose}))
+}
@@ -0,0 +1,138 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Adoption is a foreign compose project converted to a stack of raw
+// service manifests.
+type Adoption struct {
+	// Stack includes one service manifest per compose service.
+	Stack *Stack
+	// Services are raw manifests carrying each service's compose config.
+	Services []*ServiceManifest
+	// Warnings lists parts of the project that need review because they
+	// don't carry over as-is.
+	Warnings []string
+}
+
+// AdoptCompose converts a compose project's configuration into a stack named
+// project with one raw service manifest per service. Service configs are
+// copied verbatim, so adopted services deploy as before; converting them to
+// provisions is left to the operator.
+func AdoptCompose(project string, compose map[string]any) (*Adoption, error) {
+	services, _ := compose["services"].(map[string]any)
+	if len(services) == 0 {
+		return nil, fmt.Errorf("compose project %s has no services", project)
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	a := &Adoption{Stack: &Stack{APIVersion: APIVersionV1, Kind: KindStack}}
+	for _, name := range names {
+		svc, ok := services[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("service %s: expected a map", name)
+		}
+		a.Stack.Include = append(a.Stack.Include, name+".yml")
+		a.Services = append(a.Services, &ServiceManifest{
+			APIVersion: APIVersionV1,
+			Kind:       KindService,
+			Name:       name,
+			Type:       "raw",
+			Compose:    map[string]any{name: svc},
+		})
+		a.Warnings = append(a.Warnings, adoptServiceWarnings(name, svc)...)
+	}
+
+	if networks, ok := compose["networks"].(map[string]any); ok && len(networks) > 0 {
+		a.Stack.Networks = networks
+	}
+	for _, key := range []string{"volumes", "secrets", "configs"} {
+		if section, ok := compose[key].(map[string]any); ok && len(section) > 0 {
+			a.Warnings = append(a.Warnings, fmt.Sprintf("top-level %s (%s) are not carried over; stacks only declare networks", key, strings.Join(sortedKeys(section), ", ")))
+		}
+	}
+	return a, nil
+}
+
+// adoptServiceWarnings flags service settings that depend on the original
+// project directory, which the rendered compose file no longer lives in.
+func adoptServiceWarnings(name string, svc map[string]any) []string {
+	var warnings []string
+	if _, ok := svc["env_file"]; ok {
+		warnings = append(warnings, fmt.Sprintf("%s: env_file is read from the original project directory", name))
+	}
+	if build, ok := svc["build"]; ok {
+		warnings = append(warnings, fmt.Sprintf("%s: build context %v is in the original project directory", name, build))
+	}
+	if volumes, ok := svc["volumes"].([]any); ok {
+		for _, v := range volumes {
+			if s, ok := v.(string); ok && (strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../")) {
+				warnings = append(warnings, fmt.Sprintf("%s: relative bind mount %s", name, s))
+			}
+		}
+	}
+	if data, err := yaml.Marshal(svc); err == nil && strings.Contains(string(data), "${") {
+		warnings = append(warnings, fmt.Sprintf("%s: uses ${...} variables the original project's .env may have set", name))
+	}
+	return warnings
+}
+
+// AdoptedFile is a manifest file to be written by an adoption.
+type AdoptedFile struct {
+	Path    string
+	Content []byte
+}
+
+// Files returns the stack file in stacksDir and the service files in
+// servicesDir that make up the adoption.
+func (a *Adoption) Files(project, stacksDir, servicesDir string) ([]AdoptedFile, error) {
+	header := fmt.Sprintf("# Adopted from compose project %s by bosun stacks adopt\n", project)
+
+	stack, err := yaml.Marshal(a.Stack)
+	if err != nil {
+		return nil, fmt.Errorf("marshal stack: %w", err)
+	}
+	files := []AdoptedFile{{Path: filepath.Join(stacksDir, project+".yml"), Content: append([]byte(header), stack...)}}
+
+	for _, svc := range a.Services {
+		data, err := yaml.Marshal(svc)
+		if err != nil {
+			return nil, fmt.Errorf("marshal service %s: %w", svc.Name, err)
+		}
+		files = append(files, AdoptedFile{Path: filepath.Join(servicesDir, svc.Name+".yml"), Content: append([]byte(header), data...)})
+	}
+	return files, nil
+}
+
+// WriteAdoptedFiles writes files, refusing to overwrite existing manifests
+// unless force is set. Nothing is written if any file would be overwritten.
+func WriteAdoptedFiles(files []AdoptedFile, force bool) error {
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(f.Path); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", f.Path)
+			}
+		}
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.Path, f.Content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAdoptCompose(t *testing.T) {
+	compose := map[string]any{
+		"name": "immich",
+		"services": map[string]any{
+			"server": map[string]any{
+				"image":    "ghcr.io/immich-app/immich-server:${IMMICH_VERSION:-release}",
+				"env_file": []any{"/opt/immich/.env"},
+				"volumes":  []any{"./library:/usr/src/app/upload"},
+			},
+			"redis": map[string]any{"image": "redis:7"},
+		},
+		"networks": map[string]any{"default": map[string]any{"name": "immich_default"}},
+		"volumes":  map[string]any{"model-cache": nil},
+	}
+
+	a, err := AdoptCompose("immich", compose)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"redis.yml", "server.yml"}, a.Stack.Include)
+	assert.Equal(t, compose["networks"], a.Stack.Networks)
+	require.Len(t, a.Services, 2)
+	assert.Equal(t, "raw", a.Services[0].Type)
+	assert.Equal(t, map[string]any{"redis": map[string]any{"image": "redis:7"}}, a.Services[0].Compose)
+
+	assert.Contains(t, a.Warnings, "server: env_file is read from the original project directory")
+	assert.Contains(t, a.Warnings, "server: relative bind mount ./library:/usr/src/app/upload")
+	assert.Contains(t, a.Warnings, "server: uses ${...} variables the original project's .env may have set")
+	assert.Contains(t, a.Warnings, "top-level volumes (model-cache) are not carried over; stacks only declare networks")
+
+	_, err = AdoptCompose("empty", map[string]any{})
+	assert.ErrorContains(t, err, "has no services")
+}
+
+func TestAdoption_RendersAsBefore(t *testing.T) {
+	dir := t.TempDir()
+	server := map[string]any{"image": "nginx:1.27", "ports": []any{"8080:80"}}
+	a, err := AdoptCompose("web", map[string]any{"services": map[string]any{"server": server}})
+	require.NoError(t, err)
+
+	stacksDir, servicesDir := filepath.Join(dir, "stacks"), filepath.Join(dir, "services")
+	files, err := a.Files("web", stacksDir, servicesDir)
+	require.NoError(t, err)
+	require.NoError(t, WriteAdoptedFiles(files, false))
+
+	output, err := RenderStack(filepath.Join(stacksDir, "web.yml"), filepath.Join(dir, "provisions"), servicesDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, server, output.Compose["services"].(map[string]any)["server"])
+
+	t.Run("refuses to overwrite", func(t *testing.T) {
+		err := WriteAdoptedFiles(files, false)
+		assert.ErrorContains(t, err, "already exists")
+
+		require.NoError(t, os.WriteFile(files[1].Path, []byte("name: server\n"), 0644))
+		require.NoError(t, WriteAdoptedFiles(files, true))
+		data, err := os.ReadFile(files[1].Path)
+		require.NoError(t, err)
+		var m ServiceManifest
+		require.NoError(t, yaml.Unmarshal(data, &m))
+		assert.Equal(t, "raw", m.Type)
+	})
+}
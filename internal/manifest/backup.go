@@ -0,0 +1,56 @@
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BackupExtension is the compose service extension that carries a service's
+// config paths into the rendered compose file, where reconcile backups read
+// them.
+const BackupExtension = "x-bosun-backup"
+
+// ValidateBackupPath checks that p is a path under the appdata root: relative,
+// and not escaping it with "..".
+func ValidateBackupPath(p string) error {
+	if p == "" {
+		return fmt.Errorf("backup path is empty")
+	}
+	if path.IsAbs(p) {
+		return fmt.Errorf("backup path %s must be relative to appdata", p)
+	}
+	clean := path.Clean(p)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("backup path %s must be under appdata", p)
+	}
+	return nil
+}
+
+// applyBackup adds the manifest's config paths to its compose service,
+// interpolating variables such as ${name}.
+func applyBackup(output *RenderOutput, m *ServiceManifest, variables map[string]any) error {
+	if len(m.Backup) == 0 {
+		return nil
+	}
+
+	services, _ := output.Compose["services"].(map[string]any)
+	service, _ := services[m.Name].(map[string]any)
+	if service == nil {
+		return fmt.Errorf("backup: no compose service named %s", m.Name)
+	}
+
+	paths := make([]any, 0, len(m.Backup))
+	for _, p := range m.Backup {
+		p, err := Interpolate(p, variables)
+		if err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		if err := ValidateBackupPath(p); err != nil {
+			return fmt.Errorf("backup: %w", err)
+		}
+		paths = append(paths, path.Clean(p))
+	}
+	service[BackupExtension] = paths
+	return nil
+}
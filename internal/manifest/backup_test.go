@@ -0,0 +1,82 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBackupPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{"traefik", ""},
+		{"authelia/configuration.yml", ""},
+		{"app/../other", ""},
+		{"", "empty"},
+		{"/mnt/appdata/traefik", "relative"},
+		{".", "under appdata"},
+		{"../etc", "under appdata"},
+		{"app/../../etc", "under appdata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := ValidateBackupPath(tt.path)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderService_Backup(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("adds interpolated paths to the service", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "myapp"},
+			Backup:     []string{"${name}/config.yaml", "shared/./myapp"},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.Equal(t, []any{"myapp/config.yaml", "shared/myapp"}, svc[BackupExtension])
+	})
+
+	t.Run("raw services", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:    "myapp",
+			Type:    "raw",
+			Compose: map[string]any{"myapp": map[string]any{"image": "myapp"}},
+			Backup:  []string{"myapp"},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.Equal(t, []any{"myapp"}, svc[BackupExtension])
+	})
+
+	t.Run("rejects paths outside appdata", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "myapp"},
+			Backup:     []string{"/etc/myapp"},
+		}
+
+		_, err := RenderService(m, provisionsDir)
+		assert.ErrorContains(t, err, "relative to appdata")
+	})
+}
@@ -0,0 +1,89 @@
+package manifest
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalImageRegistry prefixes the default image name of services built from
+// source, e.g. bosun.local/myapp. Images under it are never pulled.
+const LocalImageRegistry = "bosun.local"
+
+// BuildConfig describes how to build a service's image from source instead
+// of pulling it. In YAML it is either a context path or a map.
+type BuildConfig struct {
+	// Context is the build context, relative to the rendered compose file
+	// (as with compose's build.context).
+	Context string `yaml:"context"`
+
+	// Dockerfile is the Dockerfile path within the context.
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+
+	// Target is the build stage to stop at.
+	Target string `yaml:"target,omitempty"`
+
+	// Args are build-time variables.
+	Args map[string]string `yaml:"args,omitempty"`
+}
+
+// UnmarshalYAML accepts "build: ./path" as shorthand for a context.
+func (b *BuildConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		b.Context = node.Value
+		return nil
+	}
+	type plain BuildConfig
+	return node.Decode((*plain)(b))
+}
+
+// compose returns the build as a compose build section.
+func (b *BuildConfig) compose() map[string]any {
+	section := map[string]any{"context": b.Context}
+	if b.Dockerfile != "" {
+		section["dockerfile"] = b.Dockerfile
+	}
+	if b.Target != "" {
+		section["target"] = b.Target
+	}
+	if len(b.Args) > 0 {
+		args := make(map[string]any, len(b.Args))
+		for k, v := range b.Args {
+			args[k] = v
+		}
+		section["args"] = args
+	}
+	return section
+}
+
+// applyBuildVariables defaults ${image} to a local image name for services
+// built from source.
+func applyBuildVariables(m *ServiceManifest, variables map[string]any) {
+	if m.Build == nil {
+		return
+	}
+	if _, ok := variables["image"]; !ok {
+		variables["image"] = LocalImageRegistry + "/" + m.Name
+	}
+}
+
+// applyBuild adds the manifest's build section to its compose service.
+func applyBuild(output *RenderOutput, m *ServiceManifest) error {
+	if m.Build == nil {
+		return nil
+	}
+	if m.Build.Context == "" {
+		return fmt.Errorf("build: context is required")
+	}
+
+	services, _ := output.Compose["services"].(map[string]any)
+	service, _ := services[m.Name].(map[string]any)
+	if service == nil {
+		return fmt.Errorf("build: no compose service named %s to build", m.Name)
+	}
+	service["build"] = m.Build.compose()
+	if _, ok := service["image"]; !ok {
+		service["image"] = LocalImageRegistry + "/" + m.Name
+	}
+	return nil
+}
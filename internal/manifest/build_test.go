@@ -0,0 +1,81 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildConfig_UnmarshalYAML(t *testing.T) {
+	var m ServiceManifest
+	require.NoError(t, yaml.Unmarshal([]byte("name: app\nbuild: ../../images/app\n"), &m))
+	assert.Equal(t, &BuildConfig{Context: "../../images/app"}, m.Build)
+
+	m = ServiceManifest{}
+	require.NoError(t, yaml.Unmarshal([]byte("name: app\nbuild:\n  context: ../../images/app\n  dockerfile: Dockerfile.prod\n  args:\n    VERSION: \"2\"\n"), &m))
+	assert.Equal(t, &BuildConfig{
+		Context:    "../../images/app",
+		Dockerfile: "Dockerfile.prod",
+		Args:       map[string]string{"VERSION": "2"},
+	}, m.Build)
+}
+
+func TestRenderService_Build(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("defaults the image to a local name", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Build:      &BuildConfig{Context: "../../images/myapp", Target: "runtime"},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.Equal(t, "bosun.local/myapp", svc["image"])
+		assert.Equal(t, map[string]any{"context": "../../images/myapp", "target": "runtime"}, svc["build"])
+	})
+
+	t.Run("keeps a configured image", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "registry.local/myapp"},
+			Build:      &BuildConfig{Context: "."},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.Equal(t, "registry.local/myapp", svc["image"])
+	})
+
+	t.Run("raw manifest", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:    "legacy",
+			Type:    "raw",
+			Compose: map[string]any{"legacy": map[string]any{"restart": "always"}},
+			Build:   &BuildConfig{Context: "./legacy"},
+		}
+
+		output, err := RenderService(m, "")
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["legacy"].(map[string]any)
+		assert.Equal(t, "bosun.local/legacy", svc["image"])
+		assert.Equal(t, map[string]any{"context": "./legacy"}, svc["build"])
+	})
+
+	t.Run("requires a context", func(t *testing.T) {
+		m := &ServiceManifest{Name: "myapp", Provisions: []string{"container"}, Build: &BuildConfig{Dockerfile: "Dockerfile"}}
+
+		_, err := RenderService(m, provisionsDir)
+		assert.ErrorContains(t, err, "context is required")
+	})
+}
@@ -0,0 +1,202 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// renderCacheFormat is part of every cache key. Bump it when the cached
+// entry format or the render logic changes in a way that would make old
+// entries wrong.
+const renderCacheFormat = "bosun-render-cache/v1"
+
+// RenderCache stores rendered service outputs on disk, keyed by the SHA-256
+// of everything a service render reads: the service manifest with its
+// values overlay applied, the content of every provision it uses (includes,
+// needs, and sidecars too), and the global variables. RenderStackWith
+// renders only the services whose key isn't cached.
+type RenderCache struct {
+	// Dir holds one file per cached render, named by its key.
+	Dir string
+	// Version is mixed into every key so a new bosun build doesn't reuse
+	// renders from an older one.
+	Version string
+
+	// Hits and Misses count the services served from and added to the
+	// cache.
+	Hits   int
+	Misses int
+}
+
+// renderCacheEntry is the on-disk form of a cached render. YAML keeps the
+// value types (ints stay ints) that JSON would turn into floats.
+type renderCacheEntry struct {
+	Compose map[string]any `yaml:"compose,omitempty"`
+	Traefik map[string]any `yaml:"traefik,omitempty"`
+	Gatus   map[string]any `yaml:"gatus,omitempty"`
+}
+
+// NewRenderCache returns a render cache in dir for a bosun version.
+func NewRenderCache(dir, version string) *RenderCache {
+	return &RenderCache{Dir: dir, Version: version}
+}
+
+// Key returns the cache key for rendering m with provisions from
+// provisionsDir. m must already have any values overlay applied.
+func (c *RenderCache) Key(m *ServiceManifest, provisionsDir string) (string, error) {
+	h := sha256.New()
+	writeKeyPart(h, "format", []byte(renderCacheFormat))
+	writeKeyPart(h, "version", []byte(c.Version))
+
+	manifestYAML, err := yaml.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("hash manifest: %w", err)
+	}
+	writeKeyPart(h, "manifest", manifestYAML)
+
+	globalsYAML, err := yaml.Marshal(globalVariables)
+	if err != nil {
+		return "", fmt.Errorf("hash global variables: %w", err)
+	}
+	writeKeyPart(h, "globals", globalsYAML)
+
+	portsYAML, err := yaml.Marshal(servicePorts)
+	if err != nil {
+		return "", fmt.Errorf("hash service ports: %w", err)
+	}
+	writeKeyPart(h, "ports", portsYAML)
+
+	refs := make([]string, 0)
+	for ref := range serviceProvisions(m, provisionsDir) {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		content, err := ReadProvision(ref, provisionsDir)
+		if err != nil {
+			// A missing provision fails the render; hash its absence so
+			// adding it later changes the key.
+			writeKeyPart(h, "missing "+ref, nil)
+			continue
+		}
+		writeKeyPart(h, "provision "+ref, content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeKeyPart adds a labelled, length-prefixed value to a key hash, so
+// values can't run into each other.
+func writeKeyPart(h hash.Hash, label string, data []byte) {
+	fmt.Fprintf(h, "%s %d\n", label, len(data))
+	h.Write(data)
+}
+
+// Get returns the cached render for key. Each call returns a fresh copy,
+// so callers may merge into it. A hit refreshes the entry's modification
+// time, which Prune uses to find unused entries.
+func (c *RenderCache) Get(key string) (*RenderOutput, bool) {
+	path := c.entryPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry renderCacheEntry
+	if err := yaml.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	output := NewRenderOutput()
+	if entry.Compose != nil {
+		output.Compose = entry.Compose
+	}
+	if entry.Traefik != nil {
+		output.Traefik = entry.Traefik
+	}
+	if entry.Gatus != nil {
+		output.Gatus = entry.Gatus
+	}
+	return output, true
+}
+
+// Put stores a render under key.
+func (c *RenderCache) Put(key string, output *RenderOutput) error {
+	data, err := yaml.Marshal(renderCacheEntry{
+		Compose: output.Compose,
+		Traefik: output.Traefik,
+		Gatus:   output.Gatus,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal cached render: %w", err)
+	}
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return fmt.Errorf("create render cache directory: %w", err)
+	}
+
+	// Write atomically so a concurrent render never reads a partial entry.
+	tmpFile, err := os.CreateTemp(c.Dir, ".render-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // Cleanup on failure
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("write cached render: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.entryPath(key)); err != nil {
+		return fmt.Errorf("save cached render: %w", err)
+	}
+	return nil
+}
+
+// Prune removes entries that haven't been used for maxAge, and returns how
+// many it removed. A missing cache directory is empty.
+func (c *RenderCache) Prune(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read render cache: %w", err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yml") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.Dir, e.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// entryPath returns the file holding the entry for key.
+func (c *RenderCache) entryPath(key string) string {
+	return filepath.Join(c.Dir, key+".yml")
+}
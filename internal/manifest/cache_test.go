@@ -0,0 +1,120 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/fileutil"
+)
+
+// cacheTestTree copies the test provisions and services to a temp dir, so
+// tests can change them, and returns the stack, provisions, and services
+// paths.
+func cacheTestTree(t *testing.T) (stackPath, provisionsDir, servicesDir string) {
+	t.Helper()
+	dir := t.TempDir()
+	provisionsDir = filepath.Join(dir, "provisions")
+	servicesDir = filepath.Join(dir, "services")
+	require.NoError(t, fileutil.CopyDir(filepath.Join("testdata", "provisions"), provisionsDir))
+	require.NoError(t, fileutil.CopyDir(filepath.Join("testdata", "services"), servicesDir))
+
+	stackPath = filepath.Join(dir, "stack.yml")
+	require.NoError(t, os.WriteFile(stackPath, []byte(`apiVersion: bosun.io/v1
+kind: Stack
+include:
+  - simple-service.yml
+  - webapp-service.yml
+`), 0644))
+	return stackPath, provisionsDir, servicesDir
+}
+
+func TestRenderStackWith_Cache(t *testing.T) {
+	stackPath, provisionsDir, servicesDir := cacheTestTree(t)
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+
+	want, err := RenderStack(stackPath, provisionsDir, servicesDir, nil)
+	require.NoError(t, err)
+
+	cache := NewRenderCache(cacheDir, "test")
+	got, err := RenderStackWith(stackPath, provisionsDir, servicesDir, nil, cache)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 0, cache.Hits)
+	assert.Equal(t, 2, cache.Misses)
+
+	t.Run("unchanged services come from the cache", func(t *testing.T) {
+		cache := NewRenderCache(cacheDir, "test")
+		got, err := RenderStackWith(stackPath, provisionsDir, servicesDir, nil, cache)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 2, cache.Hits)
+		assert.Equal(t, 0, cache.Misses)
+	})
+
+	t.Run("values and versions change the key", func(t *testing.T) {
+		cache := NewRenderCache(cacheDir, "test")
+		_, err := RenderStackWith(stackPath, provisionsDir, servicesDir, map[string]any{"image": "other:tag"}, cache)
+		require.NoError(t, err)
+		assert.Equal(t, 2, cache.Misses)
+
+		cache = NewRenderCache(cacheDir, "next")
+		_, err = RenderStackWith(stackPath, provisionsDir, servicesDir, nil, cache)
+		require.NoError(t, err)
+		assert.Equal(t, 2, cache.Misses)
+	})
+
+	t.Run("a changed provision re-renders only its services", func(t *testing.T) {
+		path := filepath.Join(provisionsDir, "reverse-proxy.yml")
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, append(content, []byte("# changed\n")...), 0644))
+
+		cache := NewRenderCache(cacheDir, "test")
+		_, err = RenderStackWith(stackPath, provisionsDir, servicesDir, nil, cache)
+		require.NoError(t, err)
+		assert.Equal(t, 1, cache.Hits)
+		assert.Equal(t, 1, cache.Misses)
+	})
+}
+
+func TestRenderCache_GetReturnsCopies(t *testing.T) {
+	cache := NewRenderCache(t.TempDir(), "test")
+	output := NewRenderOutput()
+	output.Compose["services"] = map[string]any{"app": map[string]any{"image": "app:1"}}
+	require.NoError(t, cache.Put("key", output))
+
+	first, ok := cache.Get("key")
+	require.True(t, ok)
+	first.Compose["services"] = nil
+
+	second, ok := cache.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, output.Compose, second.Compose)
+
+	_, ok = cache.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestRenderCache_Prune(t *testing.T) {
+	cache := NewRenderCache(t.TempDir(), "test")
+	require.NoError(t, cache.Put("old", NewRenderOutput()))
+	require.NoError(t, cache.Put("new", NewRenderOutput()))
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(cache.entryPath("old"), old, old))
+
+	removed, err := cache.Prune(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoFileExists(t, cache.entryPath("old"))
+	assert.FileExists(t, cache.entryPath("new"))
+
+	removed, err = NewRenderCache(filepath.Join(t.TempDir(), "none"), "test").Prune(time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
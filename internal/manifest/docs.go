@@ -0,0 +1,218 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ServiceDoc holds the documentation metadata for a rendered service.
+type ServiceDoc struct {
+	Name         string
+	Description  string
+	Image        string
+	URL          string
+	Ports        []string
+	Volumes      []string
+	Backup       string
+	Dependencies []string
+}
+
+// BuildServiceDoc collects documentation metadata from a service manifest and its
+// rendered output. Manifest config supplies description, url (or subdomain + domain),
+// and backup; the rendered compose service supplies image, ports, volumes, and depends_on.
+func BuildServiceDoc(m *ServiceManifest, output *RenderOutput) ServiceDoc {
+	doc := ServiceDoc{
+		Name:        m.Name,
+		Description: configString(m.Config, "description"),
+		URL:         configString(m.Config, "url"),
+		Backup:      describeBackup(m.Config["backup"]),
+	}
+
+	if doc.URL == "" {
+		subdomain, domain := configString(m.Config, "subdomain"), configString(m.Config, "domain")
+		if subdomain != "" && domain != "" {
+			doc.URL = fmt.Sprintf("https://%s.%s", subdomain, domain)
+		}
+	}
+
+	deps := make(map[string]bool)
+	for _, need := range m.Needs {
+		deps[need] = true
+	}
+	for sidecar := range m.Services {
+		deps[sidecar] = true
+	}
+
+	services, _ := output.Compose["services"].(map[string]any)
+	if svc, ok := services[m.Name].(map[string]any); ok {
+		doc.Image = configString(svc, "image")
+		doc.Ports = stringList(svc["ports"])
+		doc.Volumes = stringList(svc["volumes"])
+
+		var dependsOn []string
+		switch d := svc["depends_on"].(type) {
+		case []any:
+			for _, name := range d {
+				dependsOn = append(dependsOn, toString(name))
+			}
+		case map[string]any:
+			dependsOn = sortedKeys(d)
+		}
+
+		for _, d := range dependsOn {
+			// Sidecar containers (<name>-db) are already listed by their
+			// need or sidecar type, so only record external dependencies.
+			if _, isSidecar := services[d]; isSidecar && strings.HasPrefix(d, m.Name+"-") {
+				continue
+			}
+			deps[d] = true
+		}
+	}
+
+	for d := range deps {
+		doc.Dependencies = append(doc.Dependencies, d)
+	}
+	sort.Strings(doc.Dependencies)
+
+	return doc
+}
+
+// Markdown renders the service documentation page.
+func (d ServiceDoc) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", d.Name)
+	if d.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", d.Description)
+	}
+
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| **Image** | %s |\n", orNone(code(d.Image)))
+	fmt.Fprintf(&b, "| **URL** | %s |\n", orNone(d.URL))
+	fmt.Fprintf(&b, "| **Backup** | %s |\n", orNone(d.Backup))
+
+	writeList(&b, "Ports", d.Ports)
+	writeList(&b, "Volumes", d.Volumes)
+	writeList(&b, "Dependencies", d.Dependencies)
+
+	b.WriteString("\n_Generated by bosun from the service manifest. Do not edit by hand._\n")
+	return b.String()
+}
+
+// WriteServiceDocs writes one markdown page per service plus a README.md index
+// to docsDir. Nothing is written when a service name would put its page
+// outside docsDir; the error wraps ErrPathTraversal.
+func WriteServiceDocs(docs []ServiceDoc, docsDir string) error {
+	paths := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		path, err := validatePathWithinDir(docsDir, doc.Name+".md")
+		if err != nil {
+			return fmt.Errorf("service %s: %w", doc.Name, err)
+		}
+		paths[doc.Name] = path
+	}
+
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return fmt.Errorf("create docs directory: %w", err)
+	}
+
+	sorted := make([]ServiceDoc, len(docs))
+	copy(sorted, docs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var index strings.Builder
+	index.WriteString("# Services\n\n| Service | Description | URL |\n|---|---|---|\n")
+
+	for _, doc := range sorted {
+		path := paths[doc.Name]
+		if err := os.WriteFile(path, []byte(doc.Markdown()), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		fmt.Fprintf(&index, "| [%s](%s.md) | %s | %s |\n", doc.Name, doc.Name, doc.Description, doc.URL)
+	}
+
+	indexPath := filepath.Join(docsDir, "README.md")
+	if err := os.WriteFile(indexPath, []byte(index.String()), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", indexPath, err)
+	}
+
+	fmt.Printf("Wrote: %s (%d services)\n", docsDir, len(sorted))
+	return nil
+}
+
+// configString returns a map value as a string, or "" if unset.
+func configString(config map[string]any, key string) string {
+	v, ok := config[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return toString(v)
+}
+
+// describeBackup formats a backup declaration: a string ("daily") or a map
+// of settings ({schedule: daily, keep: 7}).
+func describeBackup(v any) string {
+	switch b := v.(type) {
+	case nil:
+		return ""
+	case map[string]any:
+		parts := make([]string, 0, len(b))
+		for _, k := range sortedKeys(b) {
+			parts = append(parts, fmt.Sprintf("%s: %s", k, toString(b[k])))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return toString(b)
+	}
+}
+
+// stringList converts a compose list (ports, volumes) into display strings.
+// Long-syntax entries are shown as source:target.
+func stringList(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			source := m["source"]
+			if source == nil {
+				source = m["published"]
+			}
+			target := m["target"]
+			result = append(result, fmt.Sprintf("%s:%s", toString(source), toString(target)))
+			continue
+		}
+		result = append(result, toString(item))
+	}
+	return result
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- `%s`\n", item)
+	}
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "_not declared_"
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildServiceDoc(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("webapp metadata", func(t *testing.T) {
+		m, err := LoadServiceManifest(filepath.Join("testdata", "services", "webapp-service.yml"))
+		require.NoError(t, err)
+		m.Config["backup"] = map[string]any{"schedule": "daily", "keep": 7}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		doc := BuildServiceDoc(m, output)
+		assert.Equal(t, "mywebapp", doc.Name)
+		assert.Equal(t, "My Web Application", doc.Description)
+		assert.Equal(t, "ghcr.io/example/mywebapp:latest", doc.Image)
+		assert.Equal(t, "https://mywebapp.example.com", doc.URL)
+		assert.Equal(t, "keep: 7, schedule: daily", doc.Backup)
+	})
+
+	t.Run("explicit url wins", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name: "app",
+			Config: map[string]any{
+				"url":       "http://10.0.0.5:8080",
+				"subdomain": "app",
+				"domain":    "example.com",
+			},
+		}
+		doc := BuildServiceDoc(m, NewRenderOutput())
+		assert.Equal(t, "http://10.0.0.5:8080", doc.URL)
+	})
+
+	t.Run("needs listed by type, not sidecar container", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "dbapp",
+			Provisions: []string{"container"},
+			Needs:      []string{"postgres"},
+			Config: map[string]any{
+				"image":       "ghcr.io/example/dbapp:latest",
+				"db_password": "secret123",
+			},
+		}
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		doc := BuildServiceDoc(m, output)
+		assert.Equal(t, []string{"postgres"}, doc.Dependencies)
+	})
+
+	t.Run("raw compose ports, volumes, and depends_on", func(t *testing.T) {
+		m := &ServiceManifest{Name: "proxy", Type: "raw"}
+		output := NewRenderOutput()
+		output.Compose["services"] = map[string]any{
+			"proxy": map[string]any{
+				"image": "nginx:alpine",
+				"ports": []any{"80:80", map[string]any{"published": 443, "target": 443}},
+				"volumes": []any{
+					"./conf:/etc/nginx/conf.d:ro",
+					map[string]any{"type": "volume", "source": "certs", "target": "/certs"},
+				},
+				"depends_on": map[string]any{"authelia": map[string]any{"condition": "service_healthy"}},
+			},
+		}
+
+		doc := BuildServiceDoc(m, output)
+		assert.Equal(t, []string{"80:80", "443:443"}, doc.Ports)
+		assert.Equal(t, []string{"./conf:/etc/nginx/conf.d:ro", "certs:/certs"}, doc.Volumes)
+		assert.Equal(t, []string{"authelia"}, doc.Dependencies)
+		assert.Empty(t, doc.URL)
+		assert.Empty(t, doc.Backup)
+	})
+}
+
+func TestServiceDoc_Markdown(t *testing.T) {
+	doc := ServiceDoc{
+		Name:         "photos",
+		Description:  "Photo library",
+		Image:        "immich:release",
+		URL:          "https://photos.example.com",
+		Ports:        []string{"2283:2283"},
+		Dependencies: []string{"postgres", "redis"},
+	}
+
+	md := doc.Markdown()
+	assert.Contains(t, md, "# photos\n")
+	assert.Contains(t, md, "Photo library")
+	assert.Contains(t, md, "| **Image** | `immich:release` |")
+	assert.Contains(t, md, "| **URL** | https://photos.example.com |")
+	assert.Contains(t, md, "| **Backup** | _not declared_ |")
+	assert.Contains(t, md, "## Ports\n\n- `2283:2283`")
+	assert.Contains(t, md, "- `postgres`\n- `redis`")
+	assert.NotContains(t, md, "## Volumes")
+}
+
+func TestWriteServiceDocs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	docs := []ServiceDoc{
+		{Name: "zulu", Description: "Last"},
+		{Name: "alpha", Description: "First", URL: "https://alpha.example.com"},
+	}
+
+	require.NoError(t, WriteServiceDocs(docs, dir))
+
+	for _, name := range []string{"alpha.md", "zulu.md", "README.md"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)\[alpha\]\(alpha\.md\) \| First \| https://alpha\.example\.com.*\[zulu\]\(zulu\.md\)`, string(index))
+}
+
+func TestWriteServiceDocs_RejectsEscapingNames(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "docs")
+
+	for _, name := range []string{"../../x", "../x", "a/../../x"} {
+		err := WriteServiceDocs([]ServiceDoc{{Name: "ok"}, {Name: name}}, dir)
+		assert.ErrorIs(t, err, ErrPathTraversal, name)
+	}
+	assert.NoFileExists(t, filepath.Join(root, "x.md"))
+	assert.NoDirExists(t, dir, "nothing is written")
+}
@@ -0,0 +1,239 @@
+package manifest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// templateFuncs are the functions callable from ${fn(args)} placeholders,
+// with the number of arguments each takes.
+var templateFuncs = map[string]int{
+	"default": 2, // default(var, fallback): var, or fallback when var is undefined or empty
+	"upper":   1,
+	"lower":   1,
+	"b64":     1, // Standard base64 encoding
+	"port_of": 1, // port_of(service): the service's config.port
+}
+
+// servicePorts are the config.port of every service manifest, for port_of.
+var servicePorts map[string]int
+
+// SetServicePorts sets the ports port_of(service) resolves, usually the
+// config.port of every service manifest.
+func SetServicePorts(ports map[string]int) {
+	servicePorts = ports
+}
+
+// templateExpr is a parsed function argument: a quoted string, a variable,
+// or a nested function call.
+type templateExpr struct {
+	literal   string
+	isLiteral bool
+	variable  string
+	fn        string
+	args      []templateExpr
+	src       string // Source text, for messages
+}
+
+// parseCall parses the arguments of a ${fn(args)} placeholder.
+func parseCall(fn, args string) (templateExpr, error) {
+	p := &exprParser{s: args}
+	e, err := p.call(fn)
+	if err != nil {
+		return templateExpr{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return templateExpr{}, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+	return e, nil
+}
+
+// exprParser reads function arguments from a placeholder.
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// call reads a comma-separated argument list up to a closing parenthesis or
+// the end of the input.
+func (p *exprParser) call(fn string) (templateExpr, error) {
+	e := templateExpr{fn: fn}
+	arity, ok := templateFuncs[fn]
+	if !ok {
+		names := make([]string, 0, len(templateFuncs))
+		for name := range templateFuncs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return e, fmt.Errorf("unknown function %q (available: %s)", fn, strings.Join(names, ", "))
+	}
+
+	start := p.pos
+	for {
+		if p.skipSpace(); p.pos == len(p.s) || p.s[p.pos] == ')' {
+			break
+		}
+		if len(e.args) > 0 {
+			if p.s[p.pos] != ',' {
+				return e, fmt.Errorf("expected ',' at %q", p.s[p.pos:])
+			}
+			p.pos++
+		}
+		arg, err := p.expr()
+		if err != nil {
+			return e, err
+		}
+		e.args = append(e.args, arg)
+	}
+	e.src = fn + "(" + strings.TrimSpace(p.s[start:p.pos]) + ")"
+
+	if len(e.args) != arity {
+		return e, fmt.Errorf("%s takes %d argument(s), got %d", fn, arity, len(e.args))
+	}
+	return e, nil
+}
+
+// expr reads one argument.
+func (p *exprParser) expr() (templateExpr, error) {
+	p.skipSpace()
+	if p.pos == len(p.s) {
+		return templateExpr{}, fmt.Errorf("missing argument")
+	}
+
+	if p.s[p.pos] == '"' {
+		quoted, err := strconv.QuotedPrefix(p.s[p.pos:])
+		if err != nil {
+			return templateExpr{}, fmt.Errorf("bad string at %q", p.s[p.pos:])
+		}
+		p.pos += len(quoted)
+		value, _ := strconv.Unquote(quoted)
+		return templateExpr{literal: value, isLiteral: true, src: quoted}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] == '_' || p.s[p.pos] == '.' || p.s[p.pos] == '-' ||
+		unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos]))) {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	if name == "" {
+		return templateExpr{}, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		e, err := p.call(name)
+		if err != nil {
+			return e, err
+		}
+		if p.pos == len(p.s) {
+			return e, fmt.Errorf("missing ')' after %s", name)
+		}
+		p.pos++
+		return e, nil
+	}
+	return templateExpr{variable: name, src: "${" + name + "}"}, nil
+}
+
+// eval evaluates the expression. Undefined variables are passed to missing
+// and evaluate to "".
+func (e templateExpr) eval(variables map[string]any, missing func(string)) (string, error) {
+	switch {
+	case e.isLiteral:
+		return e.literal, nil
+	case e.fn == "":
+		value, ok := variables[e.variable]
+		if !ok {
+			missing(e.variable)
+			return "", nil
+		}
+		return toString(value), nil
+	}
+
+	switch e.fn {
+	case "default":
+		value, err := e.args[0].eval(variables, func(string) {})
+		if err != nil || value != "" {
+			return value, err
+		}
+		return e.args[1].eval(variables, missing)
+	case "port_of":
+		service := e.args[0].literal
+		if !e.args[0].isLiteral {
+			service = e.args[0].variable
+		}
+		if service == "" {
+			return "", fmt.Errorf("port_of takes a service name")
+		}
+		// The service being rendered may set its port without being saved yet.
+		if port, ok := variables["port"]; ok && variables["name"] == service {
+			return toString(port), nil
+		}
+		port, ok := servicePorts[service]
+		if !ok {
+			return "", fmt.Errorf("no config.port for service %q", service)
+		}
+		return strconv.Itoa(port), nil
+	}
+
+	value, err := e.args[0].eval(variables, missing)
+	if err != nil {
+		return "", err
+	}
+	switch e.fn {
+	case "upper":
+		return strings.ToUpper(value), nil
+	case "lower":
+		return strings.ToLower(value), nil
+	case "b64":
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	}
+	return "", fmt.Errorf("unknown function %q", e.fn)
+}
+
+// walkVariables calls fn for each variable the expression reads, with
+// fallback set to the default() fallback guarding it, if any. port_of
+// arguments are service names, not variables.
+func (e templateExpr) walkVariables(fn func(name string, fallback *templateExpr)) {
+	switch {
+	case e.isLiteral:
+	case e.fn == "":
+		fn(e.variable, nil)
+	case e.fn == "port_of":
+		if e.args[0].fn != "" {
+			e.args[0].walkVariables(fn)
+		}
+	case e.fn == "default":
+		fallback := e.args[1]
+		e.args[0].walkVariables(func(name string, _ *templateExpr) {
+			fn(name, &fallback)
+		})
+		fallback.walkVariables(fn)
+	default:
+		for _, arg := range e.args {
+			arg.walkVariables(fn)
+		}
+	}
+}
+
+// display returns how the expression reads as a default value: a string as
+// itself, anything else as a placeholder.
+func (e templateExpr) display() string {
+	switch {
+	case e.isLiteral:
+		return e.literal
+	case e.fn == "":
+		return e.src
+	}
+	return "${" + e.src + "}"
+}
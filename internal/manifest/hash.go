@@ -0,0 +1,58 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// hashHeaderPrefix starts the content hash comment on the first line of a
+// rendered YAML file, e.g. "# bosun:hash sha256:3f9c...".
+const hashHeaderPrefix = "# bosun:hash sha256:"
+
+// HashableFile reports whether path is a YAML file, which can carry a hash
+// header as a comment.
+func HashableFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return true
+	}
+	return false
+}
+
+// ContentHash returns the hex SHA-256 of data without its hash header, so a
+// file hashes the same before and after it is stamped.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(StripHashHeader(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// WithHashHeader returns data with a hash header of its content as the
+// first line, replacing an existing header.
+func WithHashHeader(data []byte) []byte {
+	body := StripHashHeader(data)
+	header := hashHeaderPrefix + ContentHash(body) + "\n"
+	return append([]byte(header), body...)
+}
+
+// HashHeader returns the hash recorded in data's header. ok is false when
+// data has no header.
+func HashHeader(data []byte) (hash string, ok bool) {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	hash, ok = strings.CutPrefix(strings.TrimSpace(string(line)), hashHeaderPrefix)
+	if !ok || hash == "" {
+		return "", false
+	}
+	return hash, true
+}
+
+// StripHashHeader returns data without its hash header line.
+func StripHashHeader(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte(hashHeaderPrefix)) {
+		return data
+	}
+	_, body, _ := bytes.Cut(data, []byte("\n"))
+	return body
+}
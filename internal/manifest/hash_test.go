@@ -0,0 +1,35 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHashHeader(t *testing.T) {
+	body := []byte("http:\n  routers: {}\n")
+
+	stamped := WithHashHeader(body)
+	hash, ok := HashHeader(stamped)
+	require.True(t, ok)
+	assert.Equal(t, ContentHash(body), hash)
+	assert.Equal(t, ContentHash(body), ContentHash(stamped), "the header is not part of the content")
+
+	// Restamping replaces the header instead of stacking another
+	assert.Equal(t, stamped, WithHashHeader(stamped))
+
+	changed := WithHashHeader([]byte("http:\n  routers:\n    web: {}\n"))
+	changedHash, _ := HashHeader(changed)
+	assert.NotEqual(t, hash, changedHash)
+
+	_, ok = HashHeader(body)
+	assert.False(t, ok)
+}
+
+func TestHashableFile(t *testing.T) {
+	assert.True(t, HashableFile("traefik/dynamic.yml"))
+	assert.True(t, HashableFile("gatus/config.YAML"))
+	assert.False(t, HashableFile("tailscale-gateway/serve.json"))
+	assert.False(t, HashableFile("units/edge/web.service"))
+}
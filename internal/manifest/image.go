@@ -0,0 +1,150 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetServiceImage updates the image in a service manifest's source and
+// returns the new source and the image it replaced. Only the image value
+// changes; comments, key order, and quoting are preserved.
+//
+// The image is config.image, or services.<sidecar>.image for a sidecar. Raw
+// manifests keep it at compose.<name>.image. It must be a literal
+// single-line reference (registry/name:tag, optionally @sha256:digest), not
+// built from ${variables}, so tools like Renovate can edit it too.
+//
+// tag replaces the tag and digest of the current image: "1.2.3",
+// "1.2.3@sha256:...", or "@sha256:..." to pin the current tag to a digest.
+func SetServiceImage(source []byte, sidecar, tag string) ([]byte, string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, "", fmt.Errorf("parse manifest: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, "", fmt.Errorf("manifest is empty")
+	}
+	root := doc.Content[0]
+
+	var path []string
+	switch {
+	case sidecar != "":
+		path = []string{"services", sidecar, "image"}
+	case scalarValue(mappingValue(root, "type")) == "raw":
+		path = []string{"compose", scalarValue(mappingValue(root, "name")), "image"}
+	default:
+		path = []string{"config", "image"}
+	}
+
+	node := root
+	for _, key := range path {
+		if node = mappingValue(node, key); node == nil {
+			return nil, "", fmt.Errorf("no image at %s", strings.Join(path, "."))
+		}
+	}
+
+	old := node.Value
+	if node.Kind != yaml.ScalarNode || old == "" {
+		return nil, "", fmt.Errorf("image at %s is not a string", strings.Join(path, "."))
+	}
+	if strings.Contains(old, "${") {
+		return nil, "", fmt.Errorf("image %q uses variables; set a literal image to bump it", old)
+	}
+
+	updated, err := WithTag(old, tag)
+	if err != nil {
+		return nil, "", err
+	}
+
+	out, err := replaceScalar(source, node, updated)
+	if err != nil {
+		return nil, "", err
+	}
+	return out, old, nil
+}
+
+// WithTag returns image with its tag and digest replaced by tag (see
+// SetServiceImage for the accepted forms).
+func WithTag(image, tag string) (string, error) {
+	if tag == "" || strings.ContainsAny(tag, " \t\n/") {
+		return "", fmt.Errorf("invalid tag %q", tag)
+	}
+
+	name, currentTag := splitImageTag(image)
+	if strings.HasPrefix(tag, "@") {
+		if currentTag == "" {
+			return name + tag, nil
+		}
+		return name + ":" + currentTag + tag, nil
+	}
+	return name + ":" + tag, nil
+}
+
+// splitImageTag splits an image reference into its name and tag, dropping
+// any digest. A colon before the last slash is a registry port, not a tag.
+func splitImageTag(image string) (name, tag string) {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// replaceScalar swaps the text of a single-line scalar node in source,
+// keeping its quote style.
+func replaceScalar(source []byte, node *yaml.Node, value string) ([]byte, error) {
+	lines := bytes.SplitAfter(source, []byte("\n"))
+	if node.Line < 1 || node.Line > len(lines) {
+		return nil, fmt.Errorf("image is not on a single line")
+	}
+	line := lines[node.Line-1]
+
+	var current, replacement string
+	switch node.Style {
+	case 0:
+		current, replacement = node.Value, value
+	case yaml.DoubleQuotedStyle:
+		current, replacement = `"`+node.Value+`"`, `"`+value+`"`
+	case yaml.SingleQuotedStyle:
+		current, replacement = "'"+node.Value+"'", "'"+value+"'"
+	default:
+		return nil, fmt.Errorf("image must be a plain or quoted scalar on one line")
+	}
+
+	start := node.Column - 1
+	if start < 0 || start+len(current) > len(line) || string(line[start:start+len(current)]) != current {
+		return nil, fmt.Errorf("image is not on a single line")
+	}
+
+	edited := make([]byte, 0, len(line)+len(replacement)-len(current))
+	edited = append(edited, line[:start]...)
+	edited = append(edited, replacement...)
+	edited = append(edited, line[start+len(current):]...)
+	lines[node.Line-1] = edited
+	return bytes.Join(lines, nil), nil
+}
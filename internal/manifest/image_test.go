@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetServiceImage(t *testing.T) {
+	source := `apiVersion: bosun.io/v1
+kind: Service
+name: sonarr
+provisions: [webapp]
+config:
+  # renovate: datasource=docker
+  image: lscr.io/linuxserver/sonarr:4.0.1 # pinned
+  port: 8989
+services:
+  postgres:
+    image: "postgres:16-alpine"
+`
+
+	t.Run("config image", func(t *testing.T) {
+		out, old, err := SetServiceImage([]byte(source), "", "4.0.2")
+		require.NoError(t, err)
+		assert.Equal(t, "lscr.io/linuxserver/sonarr:4.0.1", old)
+		assert.Contains(t, string(out), "  image: lscr.io/linuxserver/sonarr:4.0.2 # pinned\n")
+		assert.Contains(t, string(out), "# renovate: datasource=docker")
+		assert.Equal(t, len(source), len(out), "only the tag changes")
+	})
+
+	t.Run("sidecar keeps quotes", func(t *testing.T) {
+		out, old, err := SetServiceImage([]byte(source), "postgres", "17-alpine")
+		require.NoError(t, err)
+		assert.Equal(t, "postgres:16-alpine", old)
+		assert.Contains(t, string(out), `    image: "postgres:17-alpine"`)
+	})
+
+	t.Run("raw manifest", func(t *testing.T) {
+		raw := "name: legacy\ntype: raw\ncompose:\n  legacy:\n    image: legacy/app:v1\n"
+		out, _, err := SetServiceImage([]byte(raw), "", "v2")
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "image: legacy/app:v2")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := SetServiceImage([]byte(source), "redis", "7")
+		assert.ErrorContains(t, err, "no image at services.redis.image")
+
+		_, _, err = SetServiceImage([]byte("name: x\nconfig:\n  image: ${registry}/x:1\n"), "", "2")
+		assert.ErrorContains(t, err, "uses variables")
+
+		_, _, err = SetServiceImage([]byte("name: x\nconfig:\n  image: >-\n    x:1\n"), "", "2")
+		assert.Error(t, err)
+	})
+}
+
+func TestWithTag(t *testing.T) {
+	tests := []struct {
+		image, tag, want string
+	}{
+		{"nginx", "1.27", "nginx:1.27"},
+		{"nginx:1.25", "1.27", "nginx:1.27"},
+		{"registry:5000/team/app:1.0", "1.1", "registry:5000/team/app:1.1"},
+		{"registry:5000/team/app", "1.1", "registry:5000/team/app:1.1"},
+		{"app:1.0@sha256:aaa", "1.1", "app:1.1"},
+		{"app:1.0", "1.1@sha256:bbb", "app:1.1@sha256:bbb"},
+		{"app:1.0@sha256:aaa", "@sha256:bbb", "app:1.0@sha256:bbb"},
+	}
+	for _, tt := range tests {
+		got, err := WithTag(tt.image, tt.tag)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s + %s", tt.image, tt.tag)
+	}
+
+	_, err := WithTag("app:1.0", "bad tag")
+	assert.Error(t, err)
+}
@@ -3,38 +3,216 @@ package manifest
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
-// varPattern matches ${varname} placeholders.
-var varPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+// varPattern matches ${varname} placeholders, including dotted names like
+// ${host.ip}, and ${fn(args)} function calls (see templateFuncs).
+var varPattern = regexp.MustCompile(`\$\{(?:(\w+(?:\.\w+)*)|(\w+)\(([^{}]*?)\))\}`)
 
-// Interpolate replaces ${var} placeholders with values from the variables map.
-// Returns an error if any referenced variable is missing.
+// globalVariables are available to every service, beneath its own config.
+var globalVariables map[string]any
+
+// SetGlobalVariables sets variables available to every service, such as
+// host facts (${host.ip}). A service's config overrides them.
+func SetGlobalVariables(vars map[string]any) {
+	globalVariables = vars
+}
+
+// baseVariables returns a fresh variables map seeded with the global variables.
+func baseVariables() map[string]any {
+	vars := make(map[string]any, len(globalVariables))
+	for k, v := range globalVariables {
+		vars[k] = v
+	}
+	return vars
+}
+
+// Interpolate replaces ${var} placeholders with values from the variables map
+// and ${fn(args)} placeholders with the function's result.
+// Returns an *UndefinedVariablesError if any referenced variable is missing.
 // This function operates on raw strings BEFORE YAML parsing.
 func Interpolate(template string, variables map[string]any) (string, error) {
 	var missingVars []string
+	var funcErr error
+	seen := make(map[string]bool)
+	missing := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			missingVars = append(missingVars, key)
+		}
+	}
 
 	result := varPattern.ReplaceAllStringFunc(template, func(match string) string {
+		m := varPattern.FindStringSubmatch(match)
+		if m[2] != "" {
+			call, err := parseCall(m[2], m[3])
+			var value string
+			if err == nil {
+				value, err = call.eval(variables, missing)
+			}
+			if err != nil {
+				if funcErr == nil {
+					funcErr = fmt.Errorf("%s: %w", match, err)
+				}
+				return match
+			}
+			return value
+		}
+
 		// Extract variable name from ${varname}
-		key := varPattern.FindStringSubmatch(match)[1]
+		key := m[1]
 
 		value, ok := variables[key]
 		if !ok {
-			missingVars = append(missingVars, key)
+			missing(key)
 			return match // Keep original if missing
 		}
 
 		return toString(value)
 	})
 
+	if funcErr != nil {
+		return "", funcErr
+	}
 	if len(missingVars) > 0 {
-		return "", fmt.Errorf("missing variables: ${%s}", strings.Join(missingVars, "}, ${"))
+		return "", newUndefinedVariablesError(missingVars, variables)
 	}
 
 	return result, nil
 }
 
+// UndefinedVariablesError reports ${var} references with no value,
+// with a did-you-mean suggestion for each where a close match exists.
+type UndefinedVariablesError struct {
+	// Names are the undefined variable names in order of first reference.
+	Names []string
+	// Suggestions maps an undefined name to the closest defined variable.
+	Suggestions map[string]string
+}
+
+func newUndefinedVariablesError(names []string, variables map[string]any) *UndefinedVariablesError {
+	e := &UndefinedVariablesError{Names: names, Suggestions: make(map[string]string)}
+	for _, name := range names {
+		if suggestion := SuggestVariable(name, variables); suggestion != "" {
+			e.Suggestions[name] = suggestion
+		}
+	}
+	return e
+}
+
+// Error implements the error interface.
+func (e *UndefinedVariablesError) Error() string {
+	parts := make([]string, 0, len(e.Names))
+	for _, name := range e.Names {
+		if suggestion, ok := e.Suggestions[name]; ok {
+			parts = append(parts, fmt.Sprintf("${%s} (did you mean ${%s}?)", name, suggestion))
+		} else {
+			parts = append(parts, fmt.Sprintf("${%s}", name))
+		}
+	}
+	return "missing variables: " + strings.Join(parts, ", ")
+}
+
+// ReferencedVariables returns the unique ${var} names referenced in content,
+// including those read by function calls, in order of first reference.
+func ReferencedVariables(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	walkReferences(content, func(name string, _ *templateExpr) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// DefaultedVariables maps the variables in content that are only read
+// through default(var, fallback) to their fallback, so they need not be set.
+func DefaultedVariables(content string) map[string]string {
+	defaults := make(map[string]string)
+	required := make(map[string]bool)
+	walkReferences(content, func(name string, fallback *templateExpr) {
+		if fallback == nil {
+			required[name] = true
+		} else if _, ok := defaults[name]; !ok {
+			defaults[name] = fallback.display()
+		}
+	})
+	for name := range required {
+		delete(defaults, name)
+	}
+	return defaults
+}
+
+// walkReferences calls fn for each variable reference in content, with the
+// default() fallback guarding it, if any. Calls that don't parse are
+// skipped; rendering reports them.
+func walkReferences(content string, fn func(name string, fallback *templateExpr)) {
+	for _, m := range varPattern.FindAllStringSubmatch(content, -1) {
+		if m[2] == "" {
+			fn(m[1], nil)
+			continue
+		}
+		if call, err := parseCall(m[2], m[3]); err == nil {
+			call.walkVariables(fn)
+		}
+	}
+}
+
+// SuggestVariable returns the defined variable name closest to name, or ""
+// if nothing is close enough to be a plausible typo.
+func SuggestVariable(name string, variables map[string]any) string {
+	// Allow roughly one typo per three characters.
+	maxDist := max(1, len(name)/3)
+	best, bestDist := "", maxDist+1
+
+	candidates := make([]string, 0, len(variables))
+	for k := range variables {
+		candidates = append(candidates, k)
+	}
+	sort.Strings(candidates) // Deterministic tie-breaking
+
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, name) {
+			return candidate
+		}
+		if d := editDistance(name, candidate); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// Levenshtein distance where swapping two adjacent characters counts as one edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
 // toString converts any value to its string representation.
 func toString(v any) string {
 	switch val := v.(type) {
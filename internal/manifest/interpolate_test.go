@@ -300,3 +300,99 @@ func TestInterpolateValue_NonStringNonMapNonList(t *testing.T) {
 	assert.Equal(t, true, result["bool"])
 	assert.Nil(t, result["nil"])
 }
+
+func TestInterpolate_UndefinedVariablesError(t *testing.T) {
+	_, err := Interpolate("${imgae} ${imgae} ${zzz}", map[string]any{"image": "nginx", "port": 80})
+	require.Error(t, err)
+
+	var undefined *UndefinedVariablesError
+	require.ErrorAs(t, err, &undefined)
+	assert.Equal(t, []string{"imgae", "zzz"}, undefined.Names)
+	assert.Equal(t, "image", undefined.Suggestions["imgae"])
+	assert.NotContains(t, undefined.Suggestions, "zzz")
+	assert.Equal(t, "missing variables: ${imgae} (did you mean ${image}?), ${zzz}", err.Error())
+}
+
+func TestSuggestVariable(t *testing.T) {
+	variables := map[string]any{"subdomain": "", "domain": "", "port": "", "image": ""}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"subdomian", "subdomain"},
+		{"Domain", "domain"},
+		{"prot", "port"},
+		{"database_url", ""},
+		{"x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SuggestVariable(tt.name, variables))
+		})
+	}
+}
+
+func TestReferencedVariables(t *testing.T) {
+	assert.Equal(t, []string{"name", "image"}, ReferencedVariables("${name}: ${image} ${name}"))
+	assert.Empty(t, ReferencedVariables("no variables here"))
+}
+
+func TestInterpolate_DottedNames(t *testing.T) {
+	got, err := Interpolate("url: http://${host.ip}:${port}", map[string]any{"host.ip": "10.0.0.5", "port": 8080})
+	require.NoError(t, err)
+	assert.Equal(t, "url: http://10.0.0.5:8080", got)
+
+	assert.Equal(t, []string{"host.ip", "name"}, ReferencedVariables("${host.ip} ${name}"))
+}
+
+func TestInterpolate_Functions(t *testing.T) {
+	SetServicePorts(map[string]int{"postgres": 5432})
+	t.Cleanup(func() { SetServicePorts(nil) })
+
+	variables := map[string]any{"name": "wiki", "port": 3000, "env": "prod", "empty": "", "password": "s3cret"}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`${default(tag, "latest")}`, "latest"},
+		{`${default(env, "dev")}`, "prod"},
+		{`${default(empty, "x")}`, "x"},
+		{`${default(tag, env)}`, "prod"},
+		{`${upper(env)}`, "PROD"},
+		{`${lower("MiXeD")}`, "mixed"},
+		{`${b64(password)}`, "czNjcmV0"},
+		{`${upper(default(tag, "edge"))}`, "EDGE"},
+		{`db:${port_of(postgres)} self:${port_of("wiki")}`, "db:5432 self:3000"},
+		{`${ upper( env ) }`, "${ upper( env ) }"}, // Not a placeholder
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.template, variables)
+		require.NoError(t, err, tt.template)
+		assert.Equal(t, tt.want, got, tt.template)
+	}
+
+	_, err := Interpolate(`${upper(missing)}`, variables)
+	var undefined *UndefinedVariablesError
+	require.ErrorAs(t, err, &undefined)
+	assert.Equal(t, []string{"missing"}, undefined.Names)
+
+	for template, msg := range map[string]string{
+		`${shout(env)}`:         `unknown function "shout"`,
+		`${upper(env, "x")}`:    "upper takes 1 argument(s), got 2",
+		`${port_of(redis)}`:     `no config.port for service "redis"`,
+		`${default(env, "x)}`:   "bad string",
+		`${upper(default(env)}`: "default takes 2 argument(s), got 1",
+	} {
+		_, err := Interpolate(template, variables)
+		assert.ErrorContains(t, err, msg, template)
+	}
+}
+
+func TestReferencedVariables_Functions(t *testing.T) {
+	content := `${upper(env)} ${default(tag, "latest")} ${default(domain, host.ip)} ${port_of(postgres)} ${tag}`
+	assert.Equal(t, []string{"env", "tag", "domain", "host.ip"}, ReferencedVariables(content))
+	assert.Equal(t, map[string]string{"domain": "${host.ip}"}, DefaultedVariables(content),
+		"tag is also read without a default")
+}
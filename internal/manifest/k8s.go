@@ -0,0 +1,439 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// K8sExportAnnotation marks every exported object as a best-effort conversion.
+const K8sExportAnnotation = "bosun.io/export"
+
+// k8sConvertedKeys are the compose service keys the Kubernetes export understands.
+// Anything else is listed in the file header as not converted. Labels become
+// annotations (Traefik labels feed the Ingress), and a restart policy is
+// converted only when it matches a Deployment's (see k8sRestartPolicies).
+var k8sConvertedKeys = map[string]bool{
+	"image":       true,
+	"ports":       true,
+	"environment": true,
+	"labels":      true,
+	"restart":     true,
+}
+
+// k8sRestartPolicies are the compose restart policies a Deployment, whose
+// pods always restart, carries out.
+var k8sRestartPolicies = map[string]bool{
+	"always":         true,
+	"unless-stopped": true,
+}
+
+// k8sAnnotationKey matches a valid annotation key: an optional DNS subdomain
+// prefix and a name of at most 63 characters.
+var k8sAnnotationKey = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+// hostRulePattern extracts hostnames from a Traefik rule like Host(`a.example.com`).
+var hostRulePattern = regexp.MustCompile("Host\\(`([^`]+)`\\)")
+
+func init() {
+	RegisterRenderer(k8sRenderer{})
+}
+
+// k8sRenderer converts compose services into Kubernetes Deployment, Service,
+// and Ingress manifests. The conversion is best-effort: volumes, healthchecks,
+// secrets, and dependencies have no direct equivalent and are left for review.
+type k8sRenderer struct{}
+
+// Name implements Renderer.
+func (k8sRenderer) Name() string {
+	return "k8s"
+}
+
+// Render implements Renderer.
+func (k8sRenderer) Render(output *RenderOutput, stackName string) (map[string][]byte, error) {
+	data, err := RenderK8s(output)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return map[string][]byte{"k8s/" + stackName + ".yml": data}, nil
+}
+
+// RenderK8s converts the compose services in output into a multi-document
+// Kubernetes YAML file. It returns nil if there are no services.
+func RenderK8s(output *RenderOutput) ([]byte, error) {
+	services, _ := output.Compose["services"].(map[string]any)
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by bosun export k8s (best-effort).\n")
+	buf.WriteString("# Review before applying: volumes, healthchecks, secrets, and depends_on are not converted.\n")
+
+	var docs []map[string]any
+	for _, name := range sortedKeys(services) {
+		svc, _ := services[name].(map[string]any)
+		if svc == nil {
+			continue
+		}
+
+		if skipped := unconvertedKeys(svc); len(skipped) > 0 {
+			fmt.Fprintf(&buf, "# %s: not converted: %s\n", name, strings.Join(skipped, ", "))
+		}
+
+		backend := backendPort(output.Traefik, svc, name)
+		ports := k8sContainerPorts(svc, backend)
+		docs = append(docs, k8sDeployment(name, svc, ports))
+		if len(ports) > 0 {
+			docs = append(docs, k8sService(name, ports))
+		}
+		if backend == 0 && len(ports) > 0 {
+			backend = ports[0].port
+		}
+
+		if hosts := ingressHosts(output.Traefik, svc, name); len(hosts) > 0 {
+			if backend == 0 {
+				fmt.Fprintf(&buf, "# %s: ingress skipped: no container port known\n", name)
+			} else {
+				docs = append(docs, k8sIngress(name, hosts, backend))
+			}
+		}
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("marshal k8s manifest: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshal k8s manifest: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// k8sPort is a container port and protocol.
+type k8sPort struct {
+	port     int
+	protocol string
+}
+
+// k8sName converts a compose service name into a DNS-1123 label.
+func k8sName(name string) string {
+	return strings.Trim(strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name)), "-")
+}
+
+func k8sMetadata(name string) map[string]any {
+	return map[string]any{
+		"name":        k8sName(name),
+		"labels":      map[string]any{"app.kubernetes.io/name": k8sName(name)},
+		"annotations": map[string]any{K8sExportAnnotation: "best-effort"},
+	}
+}
+
+func k8sDeployment(name string, svc map[string]any, ports []k8sPort) map[string]any {
+	container := map[string]any{
+		"name":  k8sName(name),
+		"image": configString(svc, "image"),
+	}
+
+	if len(ports) > 0 {
+		var list []any
+		for _, p := range ports {
+			list = append(list, map[string]any{"containerPort": p.port, "protocol": p.protocol})
+		}
+		container["ports"] = list
+	}
+
+	if env := k8sEnv(svc["environment"]); len(env) > 0 {
+		container["env"] = env
+	}
+
+	selector := map[string]any{"app.kubernetes.io/name": k8sName(name)}
+	podMeta := map[string]any{"labels": selector}
+	if annotations := k8sAnnotations(svc["labels"]); len(annotations) > 0 {
+		podMeta["annotations"] = annotations
+	}
+	return map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   k8sMetadata(name),
+		"spec": map[string]any{
+			"replicas": 1,
+			"selector": map[string]any{"matchLabels": selector},
+			"template": map[string]any{
+				"metadata": podMeta,
+				"spec":     map[string]any{"containers": []any{container}},
+			},
+		},
+	}
+}
+
+// composeLabels reads a compose labels map or KEY=VALUE list.
+func composeLabels(v any) map[string]string {
+	labels := make(map[string]string)
+	switch l := v.(type) {
+	case map[string]any:
+		for k, val := range l {
+			if val != nil {
+				labels[k] = toString(val)
+			} else {
+				labels[k] = ""
+			}
+		}
+	case []any:
+		for _, entry := range l {
+			k, val, _ := strings.Cut(toString(entry), "=")
+			labels[k] = val
+		}
+	}
+	return labels
+}
+
+// k8sAnnotations converts compose labels other than Traefik's, which the
+// Ingress already covers, into pod annotations. Keys that aren't valid
+// annotation keys are skipped and listed by unconvertedKeys.
+func k8sAnnotations(v any) map[string]any {
+	annotations := make(map[string]any)
+	for k, val := range composeLabels(v) {
+		if !strings.HasPrefix(k, "traefik.") && k8sAnnotationKey.MatchString(k) {
+			annotations[k] = val
+		}
+	}
+	return annotations
+}
+
+func k8sService(name string, ports []k8sPort) map[string]any {
+	var list []any
+	for _, p := range ports {
+		list = append(list, map[string]any{
+			"name":       fmt.Sprintf("%s-%d", strings.ToLower(p.protocol), p.port),
+			"port":       p.port,
+			"targetPort": p.port,
+			"protocol":   p.protocol,
+		})
+	}
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   k8sMetadata(name),
+		"spec": map[string]any{
+			"selector": map[string]any{"app.kubernetes.io/name": k8sName(name)},
+			"ports":    list,
+		},
+	}
+}
+
+func k8sIngress(name string, hosts []string, port int) map[string]any {
+	var rules []any
+	for _, host := range hosts {
+		rules = append(rules, map[string]any{
+			"host": host,
+			"http": map[string]any{
+				"paths": []any{map[string]any{
+					"path":     "/",
+					"pathType": "Prefix",
+					"backend": map[string]any{
+						"service": map[string]any{
+							"name": k8sName(name),
+							"port": map[string]any{"number": port},
+						},
+					},
+				}},
+			},
+		})
+	}
+	return map[string]any{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata":   k8sMetadata(name),
+		"spec":       map[string]any{"rules": rules},
+	}
+}
+
+// composeEnv reads a compose environment map or KEY=VALUE list. Variables
+// without a value (FOO: in a map, FOO in a list), which compose takes from
+// the shell, are returned in unset.
+func composeEnv(v any) (values map[string]string, unset []string) {
+	values = make(map[string]string)
+	switch env := v.(type) {
+	case map[string]any:
+		for k, val := range env {
+			if val == nil {
+				unset = append(unset, k)
+				continue
+			}
+			values[k] = toString(val)
+		}
+	case []any:
+		for _, entry := range env {
+			k, val, ok := strings.Cut(toString(entry), "=")
+			if !ok {
+				unset = append(unset, k)
+				continue
+			}
+			values[k] = val
+		}
+	}
+	sort.Strings(unset)
+	return values, unset
+}
+
+// k8sEnv converts a compose environment map or KEY=VALUE list into container
+// env entries. Variables without a value are left out and listed by
+// unconvertedKeys.
+func k8sEnv(v any) []any {
+	values, _ := composeEnv(v)
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	list := make([]any, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, map[string]any{"name": k, "value": values[k]})
+	}
+	return list
+}
+
+// k8sContainerPorts returns the container side of published compose ports,
+// plus the Traefik backend port if it isn't already published.
+func k8sContainerPorts(svc map[string]any, backend int) []k8sPort {
+	var ports []k8sPort
+	seen := make(map[k8sPort]bool)
+	add := func(p k8sPort) {
+		if p.port > 0 && !seen[p] {
+			seen[p] = true
+			ports = append(ports, p)
+		}
+	}
+
+	if list, ok := svc["ports"].([]any); ok {
+		for _, entry := range list {
+			add(containerPort(entry))
+		}
+	}
+	if backend > 0 {
+		add(k8sPort{port: backend, protocol: "TCP"})
+	}
+	return ports
+}
+
+// containerPort parses the container side of a compose port entry
+// ("8080:80", "127.0.0.1:53:53/udp", "80", or long syntax). Ranges are skipped.
+func containerPort(entry any) k8sPort {
+	spec, proto := "", ""
+	switch v := entry.(type) {
+	case map[string]any:
+		spec, proto = configString(v, "target"), configString(v, "protocol")
+	default:
+		s := toString(v)
+		spec, proto, _ = strings.Cut(s, "/")
+		if i := strings.LastIndex(spec, ":"); i >= 0 {
+			spec = spec[i+1:]
+		}
+	}
+
+	port, err := strconv.Atoi(spec)
+	if err != nil {
+		return k8sPort{}
+	}
+	if proto == "" {
+		proto = "tcp"
+	}
+	return k8sPort{port: port, protocol: strings.ToUpper(proto)}
+}
+
+// backendPort finds the port Traefik routes to for a service, from the
+// Traefik dynamic config or the compose loadbalancer label.
+func backendPort(traefik map[string]any, svc map[string]any, name string) int {
+	labels := composeLabels(svc["labels"])
+	if port, err := strconv.Atoi(labels["traefik.http.services."+name+".loadbalancer.server.port"]); err == nil {
+		return port
+	}
+
+	http, _ := traefik["http"].(map[string]any)
+	services, _ := http["services"].(map[string]any)
+	lb, _ := services[name].(map[string]any)
+	loadBalancer, _ := lb["loadBalancer"].(map[string]any)
+	servers, _ := loadBalancer["servers"].([]any)
+	for _, s := range servers {
+		server, _ := s.(map[string]any)
+		if u, err := url.Parse(configString(server, "url")); err == nil {
+			if port, err := strconv.Atoi(u.Port()); err == nil {
+				return port
+			}
+		}
+	}
+	return 0
+}
+
+// ingressHosts returns the hostnames routed to a service by Traefik, from
+// the service's router labels or the Traefik dynamic config.
+func ingressHosts(traefik map[string]any, svc map[string]any, name string) []string {
+	rules := []string{composeLabels(svc["labels"])["traefik.http.routers."+name+".rule"]}
+
+	http, _ := traefik["http"].(map[string]any)
+	routers, _ := http["routers"].(map[string]any)
+	for _, routerName := range sortedKeys(routers) {
+		router, _ := routers[routerName].(map[string]any)
+		if router == nil {
+			continue
+		}
+		if target := configString(router, "service"); target == name || (target == "" && routerName == name) {
+			rules = append(rules, configString(router, "rule"))
+		}
+	}
+
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		for _, m := range hostRulePattern.FindAllStringSubmatch(rule, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				hosts = append(hosts, m[1])
+			}
+		}
+	}
+	return hosts
+}
+
+// unconvertedKeys lists compose service keys the export drops, including a
+// restart policy a Deployment can't honour, labels that can't be
+// annotations, and environment variables without a value.
+func unconvertedKeys(svc map[string]any) []string {
+	var skipped []string
+	for _, key := range sortedKeys(svc) {
+		switch {
+		case !k8sConvertedKeys[key]:
+			skipped = append(skipped, key)
+		case key == "restart" && !k8sRestartPolicies[toString(svc[key])]:
+			skipped = append(skipped, "restart: "+toString(svc[key]))
+		case key == "labels":
+			labels := composeLabels(svc[key])
+			for _, k := range slices.Sorted(maps.Keys(labels)) {
+				if !strings.HasPrefix(k, "traefik.") && !k8sAnnotationKey.MatchString(k) {
+					skipped = append(skipped, "label "+k)
+				}
+			}
+		case key == "environment":
+			_, unset := composeEnv(svc[key])
+			for _, k := range unset {
+				skipped = append(skipped, "environment "+k+" (no value)")
+			}
+		}
+	}
+	return skipped
+}
@@ -0,0 +1,221 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeK8sDocs splits multi-document output into objects keyed by "Kind/name".
+func decodeK8sDocs(t *testing.T, data []byte) map[string]map[string]any {
+	t.Helper()
+	docs := make(map[string]map[string]any)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		meta := doc["metadata"].(map[string]any)
+		docs[doc["kind"].(string)+"/"+meta["name"].(string)] = doc
+	}
+	return docs
+}
+
+func TestRenderK8s(t *testing.T) {
+	output := &RenderOutput{
+		Compose: map[string]any{
+			"services": map[string]any{
+				"web_app": map[string]any{
+					"image":       "ghcr.io/example/web:1.2",
+					"ports":       []any{"8080:80", "127.0.0.1:5353:53/udp"},
+					"environment": map[string]any{"TZ": "UTC", "WORKERS": 4},
+					"volumes":     []any{"/mnt/appdata/web:/data"},
+					"labels": map[string]any{
+						"traefik.http.routers.web_app.rule": "Host(`web.example.com`)",
+					},
+				},
+				"worker": map[string]any{
+					"image":       "ghcr.io/example/worker:1.2",
+					"environment": []any{"QUEUE=default"},
+				},
+			},
+		},
+		Traefik: map[string]any{
+			"http": map[string]any{
+				"routers": map[string]any{
+					"web_app": map[string]any{"rule": "Host(`www.example.com`)", "service": "web_app"},
+				},
+				"services": map[string]any{
+					"web_app": map[string]any{
+						"loadBalancer": map[string]any{"servers": []any{map[string]any{"url": "http://web_app:80"}}},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := RenderK8s(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "best-effort")
+	assert.Contains(t, string(data), "# web_app: not converted: volumes")
+
+	docs := decodeK8sDocs(t, data)
+	require.Contains(t, docs, "Deployment/web-app")
+	require.Contains(t, docs, "Service/web-app")
+	require.Contains(t, docs, "Ingress/web-app")
+	require.Contains(t, docs, "Deployment/worker")
+	assert.NotContains(t, docs, "Service/worker", "no ports means no Service")
+	assert.NotContains(t, docs, "Ingress/worker")
+
+	deploy := docs["Deployment/web-app"]
+	annotations := deploy["metadata"].(map[string]any)["annotations"].(map[string]any)
+	assert.Equal(t, "best-effort", annotations[K8sExportAnnotation])
+
+	container := deploy["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)["containers"].([]any)[0].(map[string]any)
+	assert.Equal(t, "ghcr.io/example/web:1.2", container["image"])
+	assert.Equal(t, []any{
+		map[string]any{"containerPort": 80, "protocol": "TCP"},
+		map[string]any{"containerPort": 53, "protocol": "UDP"},
+	}, container["ports"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "TZ", "value": "UTC"},
+		map[string]any{"name": "WORKERS", "value": "4"},
+	}, container["env"])
+
+	rules := docs["Ingress/web-app"]["spec"].(map[string]any)["rules"].([]any)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "web.example.com", rules[0].(map[string]any)["host"])
+	assert.Equal(t, "www.example.com", rules[1].(map[string]any)["host"])
+	backend := rules[0].(map[string]any)["http"].(map[string]any)["paths"].([]any)[0].(map[string]any)["backend"].(map[string]any)
+	assert.Equal(t, map[string]any{"name": "web-app", "port": map[string]any{"number": 80}}, backend["service"])
+}
+
+func TestRenderK8s_IngressWithoutPort(t *testing.T) {
+	output := &RenderOutput{
+		Compose: map[string]any{
+			"services": map[string]any{
+				"app": map[string]any{
+					"image":  "app:1",
+					"labels": map[string]any{"traefik.http.routers.app.rule": "Host(`app.example.com`)"},
+				},
+			},
+		},
+	}
+
+	data, err := RenderK8s(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# app: ingress skipped: no container port known")
+	assert.NotContains(t, decodeK8sDocs(t, data), "Ingress/app")
+}
+
+func TestRenderK8s_LabelsAndRestart(t *testing.T) {
+	output := &RenderOutput{
+		Compose: map[string]any{
+			"services": map[string]any{
+				"app": map[string]any{
+					"image":          "app:1",
+					"container_name": "app",
+					"restart":        "unless-stopped",
+					"networks":       []any{"proxy"},
+					"labels": []any{
+						"com.centurylinklabs.watchtower.enable=true",
+						"traefik.enable=true",
+						"bad key=x",
+					},
+				},
+				"job": map[string]any{
+					"image":   "job:1",
+					"restart": "no",
+				},
+			},
+		},
+	}
+
+	data, err := RenderK8s(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# app: not converted: container_name, label bad key, networks\n")
+	assert.Contains(t, string(data), "# job: not converted: restart: no\n")
+
+	template := decodeK8sDocs(t, data)["Deployment/app"]["spec"].(map[string]any)["template"].(map[string]any)
+	assert.Equal(t, map[string]any{"com.centurylinklabs.watchtower.enable": "true"},
+		template["metadata"].(map[string]any)["annotations"])
+}
+
+func TestRenderK8s_UnsetEnvAndListLabels(t *testing.T) {
+	output := &RenderOutput{
+		Compose: map[string]any{
+			"services": map[string]any{
+				"app": map[string]any{
+					"image":       "app:1",
+					"environment": map[string]any{"TZ": "UTC", "API_KEY": nil},
+					"labels": []any{
+						"traefik.http.routers.app.rule=Host(`app.example.com`)",
+						"traefik.http.services.app.loadbalancer.server.port=8080",
+					},
+				},
+				"worker": map[string]any{
+					"image":       "worker:1",
+					"environment": []any{"QUEUE=default", "TOKEN"},
+				},
+			},
+		},
+	}
+
+	data, err := RenderK8s(output)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "<nil>")
+	assert.Contains(t, string(data), "# app: not converted: environment API_KEY (no value)\n")
+	assert.Contains(t, string(data), "# worker: not converted: environment TOKEN (no value)\n")
+
+	docs := decodeK8sDocs(t, data)
+	container := docs["Deployment/app"]["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)["containers"].([]any)[0].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"name": "TZ", "value": "UTC"}}, container["env"])
+
+	ingress := docs["Ingress/app"]
+	require.NotNil(t, ingress, "list-form Traefik labels give the Ingress")
+	rules := ingress["spec"].(map[string]any)["rules"].([]any)
+	assert.Equal(t, "app.example.com", rules[0].(map[string]any)["host"])
+	assert.Contains(t, string(data), "number: 8080")
+}
+
+func TestRenderK8s_NoServices(t *testing.T) {
+	data, err := RenderK8s(NewRenderOutput())
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestContainerPort(t *testing.T) {
+	testCases := []struct {
+		entry any
+		want  k8sPort
+	}{
+		{"80", k8sPort{80, "TCP"}},
+		{"8080:80", k8sPort{80, "TCP"}},
+		{"0.0.0.0:53:53/udp", k8sPort{53, "UDP"}},
+		{3000, k8sPort{3000, "TCP"}},
+		{map[string]any{"target": 443, "published": 8443}, k8sPort{443, "TCP"}},
+		{"8000-8010:8000-8010", k8sPort{}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, containerPort(tc.entry), "%v", tc.entry)
+	}
+}
+
+func TestK8sRenderer_Registered(t *testing.T) {
+	r, err := LookupRenderer("k8s")
+	require.NoError(t, err)
+
+	files, err := r.Render(&RenderOutput{
+		Compose: map[string]any{"services": map[string]any{"app": map[string]any{"image": "app:1"}}},
+	}, "edge")
+	require.NoError(t, err)
+	assert.Contains(t, files, "k8s/edge.yml")
+}
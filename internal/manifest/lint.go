@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VariableIssue is an undefined ${var} reference found while linting a service.
+type VariableIssue struct {
+	// Service is the service manifest name.
+	Service string
+	// Provision is the provision file containing the reference.
+	Provision string
+	// Entry is the index in the service's provisions of the provision that
+	// references the variable, or that includes the one that does.
+	Entry int
+	// Variable is the undefined variable name.
+	Variable string
+	// Suggestion is the closest defined variable, or empty if none is close.
+	Suggestion string
+}
+
+// String formats the issue for display, including any did-you-mean suggestion.
+func (i VariableIssue) String() string {
+	msg := fmt.Sprintf("${%s} is undefined (provision %s)", i.Variable, i.Provision)
+	if i.Suggestion != "" {
+		msg += fmt.Sprintf(" - did you mean ${%s}?", i.Suggestion)
+	}
+	return msg
+}
+
+// LintServiceVariables checks every ${var} referenced by a service's provisions
+// (including included provisions) against the service's config and reports the
+// undefined ones. Unlike rendering, it collects all issues instead of stopping
+// at the first provision that fails. Raw services have no provisions to check.
+func LintServiceVariables(m *ServiceManifest, provisionsDir string) ([]VariableIssue, error) {
+	if m.Type == "raw" {
+		return nil, nil
+	}
+
+	variables := baseVariables()
+	for k, v := range m.Config {
+		variables[k] = v
+	}
+	variables["name"] = m.Name
+	applyBuildVariables(m, variables)
+
+	var issues []VariableIssue
+	visited := make(map[string]bool)
+	entry := 0
+
+	var lint func(provisionName string) error
+	lint = func(provisionName string) error {
+		if visited[provisionName] {
+			return nil
+		}
+		visited[provisionName] = true
+
+		content, err := ReadProvision(provisionName, provisionsDir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("provision not found: %s", provisionName)
+			}
+			return fmt.Errorf("read provision %s: %w", provisionName, err)
+		}
+
+		defaulted := DefaultedVariables(string(content))
+		for _, name := range ReferencedVariables(string(content)) {
+			if _, ok := variables[name]; ok {
+				continue
+			}
+			if _, ok := defaulted[name]; ok {
+				continue
+			}
+			issues = append(issues, VariableIssue{
+				Service:    m.Name,
+				Provision:  provisionName,
+				Entry:      entry,
+				Variable:   name,
+				Suggestion: SuggestVariable(name, variables),
+			})
+		}
+
+		_, version := ParseProvisionRef(provisionName)
+		for _, included := range provisionIncludes(content) {
+			if err := lint(withVersion(included, version)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, provisionName := range m.Provisions {
+		entry = i
+		if err := lint(m.provisionRef(provisionName)); err != nil {
+			return issues, err
+		}
+	}
+
+	return issues, nil
+}
+
+// provisionIncludes returns the provisions listed under 'includes' in raw provision content.
+// Content that fails to parse yields no includes; rendering reports the parse error.
+func provisionIncludes(content []byte) []string {
+	var raw struct {
+		Includes []string `yaml:"includes"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil
+	}
+	return raw.Includes
+}
+
+// formatVariableIssues joins issues into a single error message.
+func formatVariableIssues(issues []VariableIssue) string {
+	msgs := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		msgs = append(msgs, issue.String())
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,141 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintServiceVariables(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("fully configured service has no issues", func(t *testing.T) {
+		m, err := LoadServiceManifest(filepath.Join("testdata", "services", "webapp-service.yml"))
+		require.NoError(t, err)
+
+		issues, err := LintServiceVariables(m, provisionsDir)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("reports undefined variables across included provisions", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "app",
+			Provisions: []string{"webapp"},
+			Config: map[string]any{
+				"imgae":     "nginx",
+				"port":      "80",
+				"subdomain": "app",
+				"domain":    "example.com",
+			},
+		}
+
+		issues, err := LintServiceVariables(m, provisionsDir)
+		require.NoError(t, err)
+		require.NotEmpty(t, issues)
+
+		var image *VariableIssue
+		for i := range issues {
+			if issues[i].Variable == "image" {
+				image = &issues[i]
+			}
+		}
+		require.NotNil(t, image, "expected ${image} to be reported")
+		assert.Equal(t, "app", image.Service)
+		assert.Equal(t, "container", image.Provision)
+		assert.Equal(t, "imgae", image.Suggestion)
+		assert.Contains(t, image.String(), "did you mean ${imgae}?")
+	})
+
+	t.Run("raw services are skipped", func(t *testing.T) {
+		issues, err := LintServiceVariables(&ServiceManifest{Name: "raw", Type: "raw"}, provisionsDir)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("missing provision returns error", func(t *testing.T) {
+		m := &ServiceManifest{Name: "app", Provisions: []string{"nonexistent"}}
+		_, err := LintServiceVariables(m, provisionsDir)
+		assert.Error(t, err)
+	})
+
+	t.Run("provision names can't escape the provisions directory", func(t *testing.T) {
+		dir := t.TempDir()
+		provisions := filepath.Join(dir, "provisions")
+		require.NoError(t, os.MkdirAll(provisions, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.yml"), []byte("compose:\n  x: ${y}\n"), 0644))
+
+		for _, name := range []string{"../secret", "a/../../secret"} {
+			_, err := LintServiceVariables(&ServiceManifest{Name: "app", Provisions: []string{name}}, provisions)
+			assert.ErrorIs(t, err, ErrPathTraversal, name)
+		}
+	})
+
+	t.Run("circular includes terminate", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("includes: [b]\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yml"), []byte("includes: [a]\ncompose:\n  x: ${y}\n"), 0644))
+
+		issues, err := LintServiceVariables(&ServiceManifest{Name: "app", Provisions: []string{"a"}}, dir)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "y", issues[0].Variable)
+	})
+	t.Run("variables with a default are optional", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("compose:\n  x: ${default(tag, \"latest\")}\n  y: ${upper(env)}\n"), 0644))
+
+		issues, err := LintServiceVariables(&ServiceManifest{Name: "app", Provisions: []string{"a"}}, dir)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "env", issues[0].Variable)
+	})
+}
+
+func TestRenderService_ReportsAllUndefinedVariables(t *testing.T) {
+	m := &ServiceManifest{
+		Name:       "app",
+		Provisions: []string{"webapp"},
+		Config:     map[string]any{"port": "80"},
+	}
+
+	_, err := RenderService(m, filepath.Join("testdata", "provisions"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "${image}")
+	assert.Contains(t, err.Error(), "${domain}")
+}
+
+func TestSetGlobalVariables(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lan.yml"), []byte(`compose:
+  services:
+    ${name}:
+      environment:
+        ADVERTISE_IP: ${host.ip}
+        WORKERS: ${host.cpus}
+`), 0644))
+
+	SetGlobalVariables(map[string]any{"host.ip": "10.0.0.5", "host.cpus": 4})
+	defer SetGlobalVariables(nil)
+
+	m := &ServiceManifest{Name: "app", Provisions: []string{"lan"}, Config: map[string]any{"host.cpus": 2}}
+
+	issues, err := LintServiceVariables(m, dir)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	output, err := RenderService(m, dir)
+	require.NoError(t, err)
+	env := output.Compose["services"].(map[string]any)["app"].(map[string]any)["environment"].(map[string]any)
+	assert.Equal(t, "10.0.0.5", env["ADVERTISE_IP"])
+	assert.Equal(t, 2, env["WORKERS"], "service config overrides global variables")
+
+	SetGlobalVariables(nil)
+	issues, err = LintServiceVariables(m, dir)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "host.ip", issues[0].Variable)
+}
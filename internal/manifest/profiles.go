@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// profileName is the compose profile name format.
+var profileName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidateProfile checks that name is a valid compose profile name.
+func ValidateProfile(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile %q (use letters, digits, '_', '.', and '-', starting with a letter or digit)", name)
+	}
+	return nil
+}
+
+// ParseProfiles parses a comma-separated list of compose profiles, such as
+// BOSUN_PROFILES, skipping empty entries.
+func ParseProfiles(list string) ([]string, error) {
+	var profiles []string
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if err := ValidateProfile(p); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// applyProfiles adds the manifest's profiles to every compose service it
+// renders, sidecars included, so they start only when a profile is
+// enabled. Profiles a provision already sets are kept.
+func applyProfiles(output *RenderOutput, m *ServiceManifest) error {
+	if len(m.Profiles) == 0 {
+		return nil
+	}
+	for _, p := range m.Profiles {
+		if err := ValidateProfile(p); err != nil {
+			return fmt.Errorf("profiles: %w", err)
+		}
+	}
+
+	services, _ := output.Compose["services"].(map[string]any)
+	for _, svc := range services {
+		service, ok := svc.(map[string]any)
+		if !ok {
+			continue
+		}
+		seen := make(map[string]bool)
+		var profiles []string
+		existing, _ := service["profiles"].([]any)
+		for _, p := range append(existing, toAnySlice(m.Profiles)...) {
+			name := toString(p)
+			if !seen[name] {
+				seen[name] = true
+				profiles = append(profiles, name)
+			}
+		}
+		sort.Strings(profiles)
+		service["profiles"] = toAnySlice(profiles)
+	}
+	return nil
+}
+
+// toAnySlice converts strings to the []any YAML decodes sequences into.
+func toAnySlice(s []string) []any {
+	out := make([]any, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderService_Profiles(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("applies to the service and its sidecars", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "dbapp",
+			Provisions: []string{"container"},
+			Needs:      []string{"postgres"},
+			Profiles:   []string{"media", "dev"},
+			Config: map[string]any{
+				"image":       "ghcr.io/example/dbapp:latest",
+				"db_password": "secret123",
+			},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		services := output.Compose["services"].(map[string]any)
+		for _, name := range []string{"dbapp", "dbapp-db"} {
+			svc := services[name].(map[string]any)
+			assert.Equal(t, []any{"dev", "media"}, svc["profiles"], name)
+		}
+	})
+
+	t.Run("merges with profiles in raw compose", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:     "tools",
+			Type:     "raw",
+			Profiles: []string{"dev"},
+			Compose: map[string]any{
+				"tools": map[string]any{"image": "busybox", "profiles": []any{"debug", "dev"}},
+			},
+		}
+
+		output, err := RenderService(m, "")
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["tools"].(map[string]any)
+		assert.Equal(t, []any{"debug", "dev"}, svc["profiles"])
+	})
+
+	t.Run("without profiles the service always starts", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "nginx"},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.NotContains(t, svc, "profiles")
+	})
+
+	t.Run("invalid profile name", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Profiles:   []string{"-media"},
+			Config:     map[string]any{"image": "nginx"},
+		}
+
+		_, err := RenderService(m, provisionsDir)
+		assert.ErrorContains(t, err, `profiles: invalid profile "-media"`)
+	})
+}
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := ParseProfiles(" media, dev ,,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"media", "dev"}, profiles)
+
+	profiles, err = ParseProfiles("")
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	_, err = ParseProfiles("media,bad profile")
+	assert.ErrorContains(t, err, `invalid profile "bad profile"`)
+}
@@ -1,7 +1,9 @@
 package manifest
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -11,6 +13,8 @@ import (
 
 // LoadProvision loads a provision file, interpolates variables, and parses YAML.
 // Supports inheritance via 'includes' key with circular include protection.
+// provisionName may pin a version (webapp@v2); its includes resolve in the
+// same version unless they pin their own.
 func LoadProvision(provisionName string, variables map[string]any, provisionsDir string) (*Provision, error) {
 	loaded := make(map[string]bool)
 	return loadProvisionInternal(provisionName, variables, provisionsDir, loaded)
@@ -23,13 +27,16 @@ func loadProvisionInternal(provisionName string, variables map[string]any, provi
 	}
 	loaded[provisionName] = true
 
-	provisionPath := filepath.Join(provisionsDir, provisionName+".yml")
-	rawContent, err := os.ReadFile(provisionPath)
+	_, version := ParseProvisionRef(provisionName)
+	rawContent, err := ReadProvision(provisionName, provisionsDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("provision not found: %s", provisionPath)
+		if errors.Is(err, fs.ErrNotExist) && version == "" {
+			return nil, fmt.Errorf("provision not found: %s", filepath.Join(provisionsDir, provisionName+".yml"))
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("provision not found: %s", provisionName)
 		}
-		return nil, fmt.Errorf("read provision %s: %w", provisionPath, err)
+		return nil, fmt.Errorf("read provision %s: %w", provisionName, err)
 	}
 
 	// Validate apiVersion if present (soft validation for backwards compatibility)
@@ -87,7 +94,7 @@ func loadProvisionInternal(provisionName string, variables map[string]any, provi
 		}
 
 		for _, included := range includes {
-			includedProvision, err := loadProvisionInternal(included, variables, provisionsDir, loaded)
+			includedProvision, err := loadProvisionInternal(withVersion(included, version), variables, provisionsDir, loaded)
 			if err != nil {
 				return nil, fmt.Errorf("include %s in %s: %w", included, provisionName, err)
 			}
@@ -157,9 +164,9 @@ func ListProvisions(provisionsDir string) ([]string, error) {
 	return provisions, nil
 }
 
-// ProvisionExists checks if a provision file exists.
+// ProvisionExists checks if a provision file exists. provisionName may pin a
+// version (webapp@v2).
 func ProvisionExists(provisionName, provisionsDir string) bool {
-	provisionPath := filepath.Join(provisionsDir, provisionName+".yml")
-	_, err := os.Stat(provisionPath)
+	_, err := ReadProvision(provisionName, provisionsDir)
 	return err == nil
 }
@@ -0,0 +1,196 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// provisionVersionPattern matches version directory names and git refs:
+// v2, 2025.01, release/v3, a1b2c3d.
+var provisionVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// ParseProvisionRef splits a provision reference like "webapp@v2" into the
+// provision name and its pinned version, which is empty when unpinned.
+func ParseProvisionRef(ref string) (name, version string) {
+	name, version, _ = strings.Cut(ref, "@")
+	return name, version
+}
+
+// ValidateProvisionVersion checks that a pinned version is a plausible
+// directory name or git ref.
+func ValidateProvisionVersion(version string) error {
+	if !provisionVersionPattern.MatchString(version) || strings.Contains(version, "..") {
+		return fmt.Errorf("invalid provision version %q", version)
+	}
+	return nil
+}
+
+// withVersion pins an unpinned provision name to version; pinned names and
+// an empty version leave it unchanged. Includes of a pinned provision
+// resolve in the same version.
+func withVersion(name, version string) string {
+	if version == "" || strings.Contains(name, "@") {
+		return name
+	}
+	return name + "@" + version
+}
+
+// provisionRef returns the reference to load for one of the service's
+// provisions, applying ProvisionsVersion to unpinned names.
+func (m *ServiceManifest) provisionRef(name string) string {
+	return withVersion(name, m.ProvisionsVersion)
+}
+
+// ReadProvision returns the raw content of a provision reference. An
+// unpinned provision is read from <provisionsDir>/<name>.yml. A pinned one
+// (name@version) is read from the version directory
+// <provisionsDir>/<version>/<name>.yml when it exists, and otherwise from
+// the git ref <version> of the repository holding provisionsDir. Missing
+// provisions return an error wrapping fs.ErrNotExist, and names that
+// escape provisionsDir one wrapping ErrPathTraversal.
+func ReadProvision(ref, provisionsDir string) ([]byte, error) {
+	name, version := ParseProvisionRef(ref)
+	path, err := validatePathWithinDir(provisionsDir, name+".yml")
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		return os.ReadFile(path)
+	}
+	if err := ValidateProvisionVersion(version); err != nil {
+		return nil, err
+	}
+
+	versionDir := filepath.Join(provisionsDir, version)
+	if info, err := os.Stat(versionDir); err == nil && info.IsDir() {
+		versionPath, err := validatePathWithinDir(versionDir, name+".yml")
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(versionPath)
+	}
+	return readProvisionAtRef(provisionsDir, name, version)
+}
+
+// readProvisionAtRef reads <name>.yml in provisionsDir as of a git ref.
+func readProvisionAtRef(provisionsDir, name, ref string) ([]byte, error) {
+	verify := exec.Command("git", "-C", provisionsDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err := verify.Run(); err != nil {
+		return nil, fmt.Errorf("unknown provision version %q: not a version directory or git ref", ref)
+	}
+
+	show := exec.Command("git", "-C", provisionsDir, "show", ref+":./"+name+".yml")
+	content, err := show.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s@%s: %w", name, ref, fs.ErrNotExist)
+	}
+	return content, nil
+}
+
+// OutdatedProvision is a pinned provision whose files differ from the
+// current, unpinned provision library.
+type OutdatedProvision struct {
+	// Service is the service manifest name.
+	Service string
+	// Ref is the pinned reference, e.g. "webapp@v1".
+	Ref string
+	// Changed lists the provisions, the pinned one or its includes, that
+	// differ from their current version.
+	Changed []string
+}
+
+// String formats the finding for display.
+func (o OutdatedProvision) String() string {
+	return fmt.Sprintf("%s is outdated (changed since: %s)", o.Ref, strings.Join(o.Changed, ", "))
+}
+
+// OutdatedProvisions returns the service's pinned provisions whose files, or
+// included files, differ from the current provision library, so services
+// left behind by a provision refactor stand out.
+func OutdatedProvisions(m *ServiceManifest, provisionsDir string) ([]OutdatedProvision, error) {
+	if m.Type == "raw" {
+		return nil, nil
+	}
+
+	var outdated []OutdatedProvision
+	for ref := range serviceProvisionRefs(m) {
+		if _, version := ParseProvisionRef(ref); version == "" {
+			continue
+		}
+
+		changed, err := changedSinceRef(ref, provisionsDir)
+		if err != nil {
+			return outdated, err
+		}
+		if len(changed) > 0 {
+			outdated = append(outdated, OutdatedProvision{Service: m.Name, Ref: ref, Changed: changed})
+		}
+	}
+
+	sort.Slice(outdated, func(i, j int) bool { return outdated[i].Ref < outdated[j].Ref })
+	return outdated, nil
+}
+
+// serviceProvisionRefs returns the references of the provisions a service
+// loads directly: its provisions, needs, and sidecars.
+func serviceProvisionRefs(m *ServiceManifest) map[string]bool {
+	refs := make(map[string]bool)
+	for _, name := range m.Provisions {
+		refs[m.provisionRef(name)] = true
+	}
+	for _, need := range m.Needs {
+		refs[m.provisionRef(need)] = true
+	}
+	for sidecar := range m.Services {
+		refs[m.provisionRef(sidecar)] = true
+	}
+	return refs
+}
+
+// changedSinceRef returns the provisions in ref's include tree whose pinned
+// content differs from the current library's, sorted by name.
+func changedSinceRef(ref, provisionsDir string) ([]string, error) {
+	var changed []string
+	visited := make(map[string]bool)
+	var walk func(ref string) error
+	walk = func(ref string) error {
+		if visited[ref] {
+			return nil
+		}
+		visited[ref] = true
+
+		name, version := ParseProvisionRef(ref)
+		pinned, err := ReadProvision(ref, provisionsDir)
+		if err != nil {
+			return fmt.Errorf("read provision %s: %w", ref, err)
+		}
+		current, err := ReadProvision(name, provisionsDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("read provision %s: %w", name, err)
+		}
+		if err != nil || !bytes.Equal(pinned, current) {
+			changed = append(changed, name)
+		}
+
+		for _, included := range provisionIncludes(pinned) {
+			if err := walk(withVersion(included, version)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(ref); err != nil {
+		return nil, err
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
@@ -0,0 +1,150 @@
+package manifest
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProvisions writes name -> content provision files under dir.
+func writeProvisions(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".yml"), []byte(content), 0644))
+	}
+}
+
+const (
+	baseV1 = "compose:\n  services:\n    ${name}:\n      image: ${image}\n"
+	baseV2 = "compose:\n  services:\n    ${name}:\n      image: ${image}\n      restart: always\n"
+	appYML = "includes:\n  - base\ncompose:\n  services:\n    ${name}:\n      container_name: ${name}\n"
+)
+
+func TestParseProvisionRef(t *testing.T) {
+	name, version := ParseProvisionRef("webapp@v2")
+	assert.Equal(t, "webapp", name)
+	assert.Equal(t, "v2", version)
+
+	name, version = ParseProvisionRef("webapp")
+	assert.Equal(t, "webapp", name)
+	assert.Empty(t, version)
+}
+
+func TestValidateProvisionVersion(t *testing.T) {
+	for _, v := range []string{"v2", "2025.01", "release/v3", "a1b2c3d"} {
+		assert.NoError(t, ValidateProvisionVersion(v), v)
+	}
+	for _, v := range []string{"", "../x", "-v", "v2 x"} {
+		assert.Error(t, ValidateProvisionVersion(v), v)
+	}
+}
+
+func TestReadProvision_VersionDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeProvisions(t, dir, map[string]string{"base": baseV2, "app": appYML})
+	writeProvisions(t, filepath.Join(dir, "v1"), map[string]string{"base": baseV1, "app": appYML})
+
+	content, err := ReadProvision("base@v1", dir)
+	require.NoError(t, err)
+	assert.Equal(t, baseV1, string(content))
+
+	content, err = ReadProvision("base", dir)
+	require.NoError(t, err)
+	assert.Equal(t, baseV2, string(content))
+
+	_, err = ReadProvision("missing@v1", dir)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	t.Run("includes resolve in the pinned version", func(t *testing.T) {
+		p, err := LoadProvision("app@v1", map[string]any{"name": "myapp", "image": "nginx"}, dir)
+		require.NoError(t, err)
+		svc := p.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.NotContains(t, svc, "restart")
+		assert.Equal(t, "myapp", svc["container_name"])
+	})
+}
+
+func TestReadProvision_GitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	dir := filepath.Join(repo, "manifest", "provisions")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	git("init", "-q")
+	writeProvisions(t, dir, map[string]string{"base": baseV1})
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "provisions-v1")
+	writeProvisions(t, dir, map[string]string{"base": baseV2})
+
+	content, err := ReadProvision("base@provisions-v1", dir)
+	require.NoError(t, err)
+	assert.Equal(t, baseV1, string(content))
+
+	_, err = ReadProvision("missing@provisions-v1", dir)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = ReadProvision("base@no-such-ref", dir)
+	assert.ErrorContains(t, err, "not a version directory or git ref")
+}
+
+func TestRenderService_ProvisionsVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeProvisions(t, dir, map[string]string{"base": baseV2})
+	writeProvisions(t, filepath.Join(dir, "v1"), map[string]string{"base": baseV1})
+
+	m := &ServiceManifest{Name: "myapp", Provisions: []string{"base"}, ProvisionsVersion: "v1", Config: map[string]any{"image": "nginx"}}
+	output, err := RenderService(m, dir)
+	require.NoError(t, err)
+	assert.NotContains(t, output.Compose["services"].(map[string]any)["myapp"], "restart")
+
+	m.ProvisionsVersion = ""
+	output, err = RenderService(m, dir)
+	require.NoError(t, err)
+	assert.Contains(t, output.Compose["services"].(map[string]any)["myapp"], "restart")
+}
+
+func TestOutdatedProvisions(t *testing.T) {
+	dir := t.TempDir()
+	writeProvisions(t, dir, map[string]string{"base": baseV2, "app": appYML})
+	writeProvisions(t, filepath.Join(dir, "v1"), map[string]string{"base": baseV1, "app": appYML})
+	writeProvisions(t, filepath.Join(dir, "v2"), map[string]string{"base": baseV2, "app": appYML})
+
+	t.Run("changed include", func(t *testing.T) {
+		m := &ServiceManifest{Name: "myapp", Provisions: []string{"app@v1"}}
+		outdated, err := OutdatedProvisions(m, dir)
+		require.NoError(t, err)
+		require.Len(t, outdated, 1)
+		assert.Equal(t, "app@v1", outdated[0].Ref)
+		assert.Equal(t, []string{"base"}, outdated[0].Changed)
+		assert.Equal(t, "app@v1 is outdated (changed since: base)", outdated[0].String())
+	})
+
+	t.Run("current pin", func(t *testing.T) {
+		m := &ServiceManifest{Name: "myapp", Provisions: []string{"app"}, ProvisionsVersion: "v2"}
+		outdated, err := OutdatedProvisions(m, dir)
+		require.NoError(t, err)
+		assert.Empty(t, outdated)
+	})
+
+	t.Run("unpinned", func(t *testing.T) {
+		m := &ServiceManifest{Name: "myapp", Provisions: []string{"app"}}
+		outdated, err := OutdatedProvisions(m, dir)
+		require.NoError(t, err)
+		assert.Empty(t, outdated)
+	})
+}
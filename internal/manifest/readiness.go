@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadinessExtension is the compose service extension that carries a
+// service's readiness probe into the rendered compose file, where the
+// deploy health gate reads it.
+const ReadinessExtension = "x-bosun-readiness"
+
+// Readiness probe defaults.
+const (
+	DefaultReadinessTimeout  = 5 * time.Minute
+	DefaultReadinessInterval = 5 * time.Second
+)
+
+// ReadinessProbe is an app-specific check that must pass before a deploy's
+// health gate does: a command run in a container, or on the deploy host,
+// retried until it exits 0 or the timeout passes. It covers apps that
+// report healthy before they can serve, such as while migrations run.
+type ReadinessProbe struct {
+	// Exec runs a command in the container.
+	Exec []string `yaml:"exec,omitempty"`
+	// Container runs Exec in another container (default: the service's).
+	Container string `yaml:"container,omitempty"`
+
+	// Host runs a command on the deploy host.
+	Host []string `yaml:"host,omitempty"`
+
+	// Timeout bounds the whole wait, e.g. "10m" (default 5m).
+	Timeout string `yaml:"timeout,omitempty"`
+	// Interval is the pause between attempts, e.g. "10s" (default 5s).
+	Interval string `yaml:"interval,omitempty"`
+}
+
+// Validate checks that the probe has exactly one command and valid
+// durations.
+func (p ReadinessProbe) Validate() error {
+	if (len(p.Exec) == 0) == (len(p.Host) == 0) {
+		return fmt.Errorf("readiness: set exactly one of exec or host")
+	}
+	if p.Container != "" && len(p.Exec) == 0 {
+		return fmt.Errorf("readiness: container only applies to exec")
+	}
+	for field, value := range map[string]string{"timeout": p.Timeout, "interval": p.Interval} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("readiness: invalid %s %q", field, value)
+		}
+	}
+	return nil
+}
+
+// Durations returns the probe's timeout and interval, with defaults for
+// those not set.
+func (p ReadinessProbe) Durations() (timeout, interval time.Duration) {
+	timeout, interval = DefaultReadinessTimeout, DefaultReadinessInterval
+	if d, err := time.ParseDuration(p.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+	if d, err := time.ParseDuration(p.Interval); err == nil && d > 0 {
+		interval = d
+	}
+	return timeout, interval
+}
+
+// applyReadiness adds the manifest's readiness probe to its compose
+// service, interpolating variables in the command.
+func applyReadiness(output *RenderOutput, m *ServiceManifest, variables map[string]any) error {
+	if m.Readiness == nil {
+		return nil
+	}
+	if err := m.Readiness.Validate(); err != nil {
+		return err
+	}
+
+	services, _ := output.Compose["services"].(map[string]any)
+	service, _ := services[m.Name].(map[string]any)
+	if service == nil {
+		return fmt.Errorf("readiness: no compose service named %s", m.Name)
+	}
+
+	probe := *m.Readiness
+	var err error
+	if probe.Exec, err = interpolateArgs(probe.Exec, variables); err != nil {
+		return fmt.Errorf("readiness: %w", err)
+	}
+	if probe.Host, err = interpolateArgs(probe.Host, variables); err != nil {
+		return fmt.Errorf("readiness: %w", err)
+	}
+
+	// Round-trip through YAML so the output holds plain maps like the rest
+	// of the compose content.
+	data, err := yaml.Marshal(probe)
+	if err != nil {
+		return fmt.Errorf("readiness: %w", err)
+	}
+	var section map[string]any
+	if err := yaml.Unmarshal(data, &section); err != nil {
+		return fmt.Errorf("readiness: %w", err)
+	}
+	service[ReadinessExtension] = section
+	return nil
+}
+
+// interpolateArgs interpolates variables in each command argument.
+func interpolateArgs(args []string, variables map[string]any) ([]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		arg, err := Interpolate(arg, variables)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, arg)
+	}
+	return out, nil
+}
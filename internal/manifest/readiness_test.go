@@ -0,0 +1,95 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessProbe_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		probe   ReadinessProbe
+		wantErr string
+	}{
+		{"exec", ReadinessProbe{Exec: []string{"php", "artisan", "migrate:status"}}, ""},
+		{"host", ReadinessProbe{Host: []string{"curl", "-fsS", "http://localhost:8080/ready"}, Timeout: "10m", Interval: "10s"}, ""},
+		{"exec in another container", ReadinessProbe{Exec: []string{"true"}, Container: "app-worker"}, ""},
+		{"neither", ReadinessProbe{}, "exactly one"},
+		{"both", ReadinessProbe{Exec: []string{"true"}, Host: []string{"true"}}, "exactly one"},
+		{"container without exec", ReadinessProbe{Host: []string{"true"}, Container: "app"}, "only applies to exec"},
+		{"bad timeout", ReadinessProbe{Exec: []string{"true"}, Timeout: "soon"}, "invalid timeout"},
+		{"zero interval", ReadinessProbe{Exec: []string{"true"}, Interval: "0s"}, "invalid interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.probe.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadinessProbe_Durations(t *testing.T) {
+	timeout, interval := ReadinessProbe{}.Durations()
+	assert.Equal(t, DefaultReadinessTimeout, timeout)
+	assert.Equal(t, DefaultReadinessInterval, interval)
+
+	timeout, interval = ReadinessProbe{Timeout: "10m", Interval: "1s"}.Durations()
+	assert.Equal(t, 10*time.Minute, timeout)
+	assert.Equal(t, time.Second, interval)
+}
+
+func TestRenderService_Readiness(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("adds the interpolated probe to the service", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "myapp"},
+			Readiness: &ReadinessProbe{
+				Exec:    []string{"${name}", "migrate", "--check"},
+				Timeout: "10m",
+			},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.Equal(t, map[string]any{
+			"exec":    []any{"myapp", "migrate", "--check"},
+			"timeout": "10m",
+		}, svc[ReadinessExtension])
+	})
+
+	t.Run("no probe", func(t *testing.T) {
+		m := &ServiceManifest{Name: "myapp", Provisions: []string{"container"}, Config: map[string]any{"image": "myapp"}}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.NotContains(t, svc, ReadinessExtension)
+	})
+
+	t.Run("invalid probe", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "myapp"},
+			Readiness:  &ReadinessProbe{},
+		}
+
+		_, err := RenderService(m, provisionsDir)
+		assert.ErrorContains(t, err, "exactly one")
+	})
+}
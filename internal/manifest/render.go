@@ -6,9 +6,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/fileutil"
 )
 
 // ErrPathTraversal indicates an attempted path traversal attack.
@@ -45,24 +48,49 @@ func validatePathWithinDir(baseDir, relativePath string) (string, error) {
 func RenderService(manifest *ServiceManifest, provisionsDir string) (*RenderOutput, error) {
 	output := NewRenderOutput()
 
-	// Build variables from config + name
-	variables := make(map[string]any)
+	// Build variables from globals + config + name
+	variables := baseVariables()
 	for k, v := range manifest.Config {
 		variables[k] = v
 	}
 	variables["name"] = manifest.Name
+	applyBuildVariables(manifest, variables)
 
 	// Handle raw passthrough mode
 	if manifest.Type == "raw" {
 		if manifest.Compose != nil {
 			output.Compose["services"] = manifest.Compose
 		}
+		if err := applyBuild(output, manifest); err != nil {
+			return nil, err
+		}
+		if err := applyProfiles(output, manifest); err != nil {
+			return nil, err
+		}
+		if err := applyVerify(output, manifest, variables); err != nil {
+			return nil, err
+		}
+		if err := applyReadiness(output, manifest, variables); err != nil {
+			return nil, err
+		}
+		if err := applyBackup(output, manifest, variables); err != nil {
+			return nil, err
+		}
+		if err := checkOutputValues(output); err != nil {
+			return nil, err
+		}
 		return output, nil
 	}
 
+	// Report every undefined variable up front, rather than failing on the
+	// first provision that references one.
+	if issues, err := LintServiceVariables(manifest, provisionsDir); err == nil && len(issues) > 0 {
+		return nil, fmt.Errorf("undefined variables: %s", formatVariableIssues(issues))
+	}
+
 	// Load and merge provisions
 	for _, provisionName := range manifest.Provisions {
-		provision, err := LoadProvision(provisionName, variables, provisionsDir)
+		provision, err := LoadProvision(manifest.provisionRef(provisionName), variables, provisionsDir)
 		if err != nil {
 			return nil, fmt.Errorf("load provision %s: %w", provisionName, err)
 		}
@@ -76,12 +104,12 @@ func RenderService(manifest *ServiceManifest, provisionsDir string) (*RenderOutp
 			continue
 		}
 
-		if !ProvisionExists(need, provisionsDir) {
+		if !ProvisionExists(manifest.provisionRef(need), provisionsDir) {
 			continue
 		}
 
 		// Build sidecar variables: defaults + config + overrides
-		sidecarVars := make(map[string]any)
+		sidecarVars := baseVariables()
 		sidecarVars["name"] = manifest.Name
 		sidecarVars["sidecar"] = need
 
@@ -105,7 +133,7 @@ func RenderService(manifest *ServiceManifest, provisionsDir string) (*RenderOutp
 			sidecarVars[k] = v
 		}
 
-		provision, err := LoadProvision(need, sidecarVars, provisionsDir)
+		provision, err := LoadProvision(manifest.provisionRef(need), sidecarVars, provisionsDir)
 		if err != nil {
 			return nil, fmt.Errorf("load need %s: %w", need, err)
 		}
@@ -114,7 +142,7 @@ func RenderService(manifest *ServiceManifest, provisionsDir string) (*RenderOutp
 
 	// Handle sidecar services with explicit config
 	for sidecarType, sidecarConfig := range manifest.Services {
-		sidecarVars := make(map[string]any)
+		sidecarVars := baseVariables()
 		sidecarVars["name"] = manifest.Name
 		sidecarVars["sidecar"] = sidecarType
 
@@ -128,16 +156,51 @@ func RenderService(manifest *ServiceManifest, provisionsDir string) (*RenderOutp
 			sidecarVars[k] = v
 		}
 
-		provision, err := LoadProvision(sidecarType, sidecarVars, provisionsDir)
+		provision, err := LoadProvision(manifest.provisionRef(sidecarType), sidecarVars, provisionsDir)
 		if err != nil {
 			return nil, fmt.Errorf("load sidecar %s: %w", sidecarType, err)
 		}
 		mergeProvision(output, provision)
 	}
 
+	if err := applyBuild(output, manifest); err != nil {
+		return nil, err
+	}
+	if err := applyProfiles(output, manifest); err != nil {
+		return nil, err
+	}
+	if err := applyVerify(output, manifest, variables); err != nil {
+		return nil, err
+	}
+	if err := applyReadiness(output, manifest, variables); err != nil {
+		return nil, err
+	}
+	if err := applyBackup(output, manifest, variables); err != nil {
+		return nil, err
+	}
+
+	if err := checkOutputValues(output); err != nil {
+		return nil, err
+	}
+
 	return output, nil
 }
 
+// checkOutputValues returns an error listing every invalid memory size,
+// duration, port, or URL in the rendered output.
+func checkOutputValues(output *RenderOutput) error {
+	valueErrs := ValidateOutputValues(output)
+	if len(valueErrs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(valueErrs))
+	for _, e := range valueErrs {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Errorf("invalid values: %s", strings.Join(msgs, "; "))
+}
+
 // mergeProvision merges a provision's outputs into the render output.
 func mergeProvision(output *RenderOutput, provision *Provision) {
 	if provision.Compose != nil {
@@ -153,6 +216,12 @@ func mergeProvision(output *RenderOutput, provision *Provision) {
 
 // RenderStack renders a stack file into compose/traefik/gatus outputs.
 func RenderStack(stackPath, provisionsDir, servicesDir string, valuesOverlay map[string]any) (*RenderOutput, error) {
+	return RenderStackWith(stackPath, provisionsDir, servicesDir, valuesOverlay, nil)
+}
+
+// RenderStackWith renders a stack like RenderStack, reusing cached service
+// renders from cache when it is not nil and caching the services it renders.
+func RenderStackWith(stackPath, provisionsDir, servicesDir string, valuesOverlay map[string]any, cache *RenderCache) (*RenderOutput, error) {
 	stackContent, err := os.ReadFile(stackPath)
 	if err != nil {
 		return nil, fmt.Errorf("read stack file: %w", err)
@@ -171,12 +240,21 @@ func RenderStack(stackPath, provisionsDir, servicesDir string, valuesOverlay map
 		log.Printf("Warning: stack %s has kind %s, expected %s", stackPath, meta.Kind, KindStack)
 	}
 
+	if errs := ValidateStackSchema(stackPath, stackContent); len(errs) > 0 {
+		return nil, errs
+	}
+
 	var stack Stack
 	if err := yaml.Unmarshal(stackContent, &stack); err != nil {
 		return nil, fmt.Errorf("parse stack file: %w", err)
 	}
 
+	if _, err := resolveRenderers(stack.Renderers); err != nil {
+		return nil, fmt.Errorf("stack %s: %w", stackPath, err)
+	}
+
 	output := NewRenderOutput()
+	output.Renderers = stack.Renderers
 
 	for _, serviceFile := range stack.Include {
 		// Validate path to prevent path traversal attacks
@@ -203,6 +281,10 @@ func RenderStack(stackPath, provisionsDir, servicesDir string, valuesOverlay map
 			log.Printf("Warning: service %s has kind %s, expected %s", serviceFile, serviceMeta.Kind, KindService)
 		}
 
+		if errs := ValidateServiceSchema(servicePath, serviceContent); len(errs) > 0 {
+			return nil, errs
+		}
+
 		var manifest ServiceManifest
 		if err := yaml.Unmarshal(serviceContent, &manifest); err != nil {
 			return nil, fmt.Errorf("parse service %s: %w", serviceFile, err)
@@ -216,7 +298,11 @@ func RenderStack(stackPath, provisionsDir, servicesDir string, valuesOverlay map
 			manifest.Config = DeepMerge(manifest.Config, valuesOverlay)
 		}
 
-		serviceOutput, err := RenderService(&manifest, provisionsDir)
+		if errs, _ := ValidateServiceProvisions(servicePath, serviceContent, &manifest, provisionsDir); len(errs) > 0 {
+			return nil, errs
+		}
+
+		serviceOutput, err := renderServiceCached(&manifest, provisionsDir, cache)
 		if err != nil {
 			return nil, fmt.Errorf("render service %s: %w", manifest.Name, err)
 		}
@@ -234,45 +320,117 @@ func RenderStack(stackPath, provisionsDir, servicesDir string, valuesOverlay map
 	return output, nil
 }
 
-// WriteOutputs writes rendered outputs to files in the output directory.
+// renderServiceCached renders a service, or returns its cached render. A
+// cache that can't be read or written only costs a re-render.
+func renderServiceCached(m *ServiceManifest, provisionsDir string, cache *RenderCache) (*RenderOutput, error) {
+	if cache == nil {
+		return RenderService(m, provisionsDir)
+	}
+
+	key, err := cache.Key(m, provisionsDir)
+	if err != nil {
+		return RenderService(m, provisionsDir)
+	}
+	if output, ok := cache.Get(key); ok {
+		cache.Hits++
+		return output, nil
+	}
+
+	output, err := RenderService(m, provisionsDir)
+	if err != nil {
+		return nil, err
+	}
+	cache.Misses++
+	if err := cache.Put(key, output); err != nil {
+		log.Printf("Warning: cache render of service %s: %v", m.Name, err)
+	}
+	return output, nil
+}
+
+// WriteOutputs writes rendered outputs to files in the output directory
+// using the output's renderers (DefaultRenderers if none are set). YAML
+// files get a content hash header (see WithHashHeader) so deploys can tell
+// unchanged files apart without diffing them.
+//
+// The output directory is replaced as a whole (see
+// fileutil.CommitGeneration): the current outputs are copied into a new
+// generation, the stack's files are written over them, and the generation
+// is swapped in only once it is complete and synced. A crash mid-provision
+// leaves the previous outputs in place rather than a mix of old and new.
 func WriteOutputs(output *RenderOutput, outputDir, stackName string) error {
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(outputDir), 0755); err != nil {
 		return fmt.Errorf("create output directory: %w", err)
 	}
 
-	targets := map[string]struct {
-		content  map[string]any
-		filename string
-	}{
-		"compose": {output.Compose, stackName + ".yml"},
-		"traefik": {output.Traefik, "dynamic.yml"},
-		"gatus":   {output.Gatus, "endpoints.yml"},
+	files, err := RenderFiles(output, stackName)
+	if err != nil {
+		return err
 	}
 
-	for target, cfg := range targets {
-		if len(cfg.content) == 0 {
-			continue
-		}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
 
-		targetDir := filepath.Join(outputDir, target)
-		if err := os.MkdirAll(targetDir, 0755); err != nil {
-			return fmt.Errorf("create %s directory: %w", target, err)
+	var written []string
+	err = fileutil.CommitGeneration(outputDir, func(genDir string) error {
+		if info, err := os.Stat(outputDir); err == nil && info.IsDir() {
+			if err := fileutil.CopyDir(fileutil.ResolveDir(outputDir), genDir); err != nil {
+				return fmt.Errorf("copy current outputs: %w", err)
+			}
 		}
 
-		outputPath := filepath.Join(targetDir, cfg.filename)
-		data, err := yaml.Marshal(cfg.content)
-		if err != nil {
-			return fmt.Errorf("marshal %s output: %w", target, err)
+		for _, p := range paths {
+			genPath := filepath.Join(genDir, filepath.FromSlash(p))
+			if err := os.MkdirAll(filepath.Dir(genPath), 0755); err != nil {
+				return fmt.Errorf("create %s directory: %w", filepath.Dir(p), err)
+			}
+			if err := os.WriteFile(genPath, files[p], 0644); err != nil {
+				return fmt.Errorf("write %s: %w", p, err)
+			}
+			written = append(written, filepath.Join(outputDir, filepath.FromSlash(p)))
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		if err := os.WriteFile(outputPath, data, 0644); err != nil {
-			return fmt.Errorf("write %s output: %w", target, err)
-		}
+	for _, path := range written {
+		fmt.Printf("Wrote: %s\n", path)
+	}
+	return nil
+}
 
-		fmt.Printf("Wrote: %s\n", outputPath)
+// RenderFiles returns the files the output's renderers produce for a stack,
+// keyed by slash-separated path relative to the output directory, as
+// WriteOutputs would write them (YAML files carry a hash header). A path
+// that escapes the output directory is an error. Renderers later in the
+// list overwrite files of earlier ones.
+func RenderFiles(output *RenderOutput, stackName string) (map[string][]byte, error) {
+	renderers, err := resolveRenderers(output.Renderers)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	result := make(map[string][]byte)
+	for _, r := range renderers {
+		files, err := r.Render(output, stackName)
+		if err != nil {
+			return nil, fmt.Errorf("render %s: %w", r.Name(), err)
+		}
+		for p, data := range files {
+			if _, err := validatePathWithinDir(".", p); err != nil {
+				return nil, fmt.Errorf("%s output: %w", r.Name(), err)
+			}
+			if HashableFile(p) {
+				data = WithHashHeader(data)
+			}
+			result[p] = data
+		}
+	}
+	return result, nil
 }
 
 // RenderToYAML renders an output to YAML string for dry-run display.
@@ -311,6 +469,10 @@ func LoadServiceManifest(path string) (*ServiceManifest, error) {
 		log.Printf("Warning: service %s has kind %s, expected %s", path, meta.Kind, KindService)
 	}
 
+	if errs := ValidateServiceSchema(path, content); len(errs) > 0 {
+		return nil, errs
+	}
+
 	var manifest ServiceManifest
 	if err := yaml.Unmarshal(content, &manifest); err != nil {
 		return nil, fmt.Errorf("parse manifest: %w", err)
@@ -503,6 +503,11 @@ func TestWriteOutputs(t *testing.T) {
 	composeContent, err := os.ReadFile(composePath)
 	require.NoError(t, err)
 	assert.Contains(t, string(composeContent), "test:latest")
+
+	// Every YAML output carries a hash of its content
+	hash, ok := HashHeader(composeContent)
+	require.True(t, ok)
+	assert.Equal(t, ContentHash(composeContent), hash)
 }
 
 func TestWriteOutputs_EmptyOutput(t *testing.T) {
@@ -672,3 +677,34 @@ func TestLoadServiceManifest_InvalidYAML(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "parse manifest")
 }
+
+func TestWriteOutputs_CommitsGeneration(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "output")
+	stack := func(image string) *RenderOutput {
+		return &RenderOutput{Compose: map[string]any{
+			"services": map[string]any{"app": map[string]any{"image": image}},
+		}}
+	}
+
+	require.NoError(t, WriteOutputs(stack("one:1"), outputDir, "one"))
+	require.NoError(t, WriteOutputs(stack("two:1"), outputDir, "two"))
+
+	// The output directory is a symlink to a complete generation
+	info, err := os.Lstat(outputDir)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	// Provisioning one stack carries the others forward
+	assert.FileExists(t, filepath.Join(outputDir, "compose", "one.yml"))
+	assert.FileExists(t, filepath.Join(outputDir, "compose", "two.yml"))
+
+	// A failed write leaves the previous generation untouched
+	registerTestRenderer(t, escapingRenderer{})
+	failing := stack("one:2")
+	failing.Renderers = []string{"compose", "escape"}
+	require.Error(t, WriteOutputs(failing, outputDir, "one"))
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "compose", "one.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "one:1")
+}
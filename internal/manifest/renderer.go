@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Renderer converts a rendered stack into files for one deploy target.
+// New targets (Kubernetes, Nomad, systemd) implement Renderer and register
+// themselves with RegisterRenderer; stacks opt in via their renderers list.
+type Renderer interface {
+	// Name identifies the renderer in stack manifests (e.g. "compose").
+	Name() string
+
+	// Render returns the files to write, keyed by slash-separated path relative
+	// to the output directory. An empty result writes nothing.
+	Render(output *RenderOutput, stackName string) (map[string][]byte, error)
+}
+
+// DefaultRenderers are used for stacks that don't list renderers.
+var DefaultRenderers = []string{"compose", "traefik", "gatus"}
+
+var renderers = map[string]Renderer{}
+
+func init() {
+	RegisterRenderer(yamlRenderer{name: "compose", dir: "compose", section: func(o *RenderOutput) map[string]any { return o.Compose }})
+	RegisterRenderer(yamlRenderer{name: "traefik", dir: "traefik", filename: "dynamic.yml", section: func(o *RenderOutput) map[string]any { return o.Traefik }})
+	RegisterRenderer(yamlRenderer{name: "gatus", dir: "gatus", filename: "endpoints.yml", section: func(o *RenderOutput) map[string]any { return o.Gatus }})
+}
+
+// RegisterRenderer makes a renderer available to stacks by name,
+// replacing any renderer already registered under that name.
+func RegisterRenderer(r Renderer) {
+	renderers[r.Name()] = r
+}
+
+// LookupRenderer returns the renderer registered under name.
+func LookupRenderer(name string) (Renderer, error) {
+	r, ok := renderers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown renderer %q (available: %s)", name, strings.Join(RendererNames(), ", "))
+	}
+	return r, nil
+}
+
+// RendererNames returns the registered renderer names in sorted order.
+func RendererNames() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveRenderers looks up renderers by name, defaulting to DefaultRenderers.
+func resolveRenderers(names []string) ([]Renderer, error) {
+	if len(names) == 0 {
+		names = DefaultRenderers
+	}
+	result := make([]Renderer, 0, len(names))
+	for _, name := range names {
+		r, err := LookupRenderer(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// yamlRenderer writes one section of the render output as a YAML file.
+type yamlRenderer struct {
+	name string
+	dir  string
+	// filename is fixed for shared targets; empty means "<stack>.yml".
+	filename string
+	section  func(*RenderOutput) map[string]any
+}
+
+// Name implements Renderer.
+func (r yamlRenderer) Name() string {
+	return r.name
+}
+
+// Render implements Renderer.
+func (r yamlRenderer) Render(output *RenderOutput, stackName string) (map[string][]byte, error) {
+	content := r.section(output)
+	if len(content) == 0 {
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s output: %w", r.name, err)
+	}
+
+	filename := r.filename
+	if filename == "" {
+		filename = stackName + ".yml"
+	}
+	return map[string][]byte{path.Join(r.dir, filename): data}, nil
+}
@@ -0,0 +1,157 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unitRenderer is a test renderer that emits one file per compose service.
+type unitRenderer struct{}
+
+func (unitRenderer) Name() string { return "test-units" }
+
+func (unitRenderer) Render(output *RenderOutput, stackName string) (map[string][]byte, error) {
+	services, _ := output.Compose["services"].(map[string]any)
+	files := make(map[string][]byte, len(services))
+	for name := range services {
+		files["units/"+stackName+"/"+name+".service"] = []byte("[Unit]\nDescription=" + name + "\n")
+	}
+	return files, nil
+}
+
+func registerTestRenderer(t *testing.T, r Renderer) {
+	t.Helper()
+	RegisterRenderer(r)
+	t.Cleanup(func() { delete(renderers, r.Name()) })
+}
+
+func TestLookupRenderer(t *testing.T) {
+	for _, name := range DefaultRenderers {
+		r, err := LookupRenderer(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, r.Name())
+	}
+
+	_, err := LookupRenderer("nomad")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown renderer "nomad"`)
+	assert.Contains(t, err.Error(), "compose, gatus, k8s, traefik")
+}
+
+func TestYAMLRenderer_Render(t *testing.T) {
+	output := &RenderOutput{
+		Compose: map[string]any{"services": map[string]any{"app": map[string]any{"image": "app:1"}}},
+		Traefik: map[string]any{},
+	}
+
+	compose, _ := LookupRenderer("compose")
+	files, err := compose.Render(output, "apps")
+	require.NoError(t, err)
+	require.Contains(t, files, "compose/apps.yml")
+	assert.Contains(t, string(files["compose/apps.yml"]), "app:1")
+
+	traefik, _ := LookupRenderer("traefik")
+	files, err = traefik.Render(output, "apps")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestWriteOutputs_CustomRenderers(t *testing.T) {
+	registerTestRenderer(t, unitRenderer{})
+	tmpDir := t.TempDir()
+
+	output := &RenderOutput{
+		Compose:   map[string]any{"services": map[string]any{"web": map[string]any{"image": "nginx"}}},
+		Traefik:   map[string]any{"http": map[string]any{}},
+		Renderers: []string{"compose", "test-units"},
+	}
+
+	require.NoError(t, WriteOutputs(output, tmpDir, "edge"))
+
+	_, err := os.Stat(filepath.Join(tmpDir, "compose", "edge.yml"))
+	require.NoError(t, err)
+
+	unit, err := os.ReadFile(filepath.Join(tmpDir, "units", "edge", "web.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(unit), "Description=web")
+
+	// Traefik is not in the stack's renderer list
+	_, err = os.Stat(filepath.Join(tmpDir, "traefik", "dynamic.yml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteOutputs_RejectsEscapingPaths(t *testing.T) {
+	registerTestRenderer(t, escapingRenderer{})
+
+	output := &RenderOutput{Renderers: []string{"escape"}}
+	err := WriteOutputs(output, t.TempDir(), "edge")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPathTraversal)
+}
+
+func TestRenderFiles(t *testing.T) {
+	output := NewRenderOutput()
+	output.Compose["services"] = map[string]any{"web": map[string]any{"image": "nginx"}}
+
+	files, err := RenderFiles(output, "edge")
+	require.NoError(t, err)
+	require.Contains(t, files, "compose/edge.yml")
+	_, ok := HashHeader(files["compose/edge.yml"])
+	assert.True(t, ok)
+
+	registerTestRenderer(t, escapingRenderer{})
+	_, err = RenderFiles(&RenderOutput{Renderers: []string{"escape"}}, "edge")
+	assert.ErrorIs(t, err, ErrPathTraversal)
+}
+
+type escapingRenderer struct{}
+
+func (escapingRenderer) Name() string { return "escape" }
+
+func (escapingRenderer) Render(*RenderOutput, string) (map[string][]byte, error) {
+	return map[string][]byte{"../outside.yml": []byte("x")}, nil
+}
+
+func TestRenderStack_Renderers(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+	servicesDir := filepath.Join("testdata", "services")
+
+	t.Run("stack renderers are carried to the output", func(t *testing.T) {
+		registerTestRenderer(t, unitRenderer{})
+		stackPath := filepath.Join(t.TempDir(), "edge.yml")
+		require.NoError(t, os.WriteFile(stackPath, []byte(`apiVersion: bosun.io/v1
+kind: Stack
+include:
+  - simple-service.yml
+renderers: [compose, test-units]
+`), 0644))
+
+		output, err := RenderStack(stackPath, provisionsDir, servicesDir, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compose", "test-units"}, output.Renderers)
+	})
+
+	t.Run("unknown renderer is rejected", func(t *testing.T) {
+		stackPath := filepath.Join(t.TempDir(), "edge.yml")
+		require.NoError(t, os.WriteFile(stackPath, []byte(`apiVersion: bosun.io/v1
+kind: Stack
+include:
+  - simple-service.yml
+renderers: [nomad]
+`), 0644))
+
+		_, err := RenderStack(stackPath, provisionsDir, servicesDir, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown renderer "nomad"`)
+	})
+
+	t.Run("no renderers means defaults", func(t *testing.T) {
+		output, err := RenderStack(filepath.Join("testdata", "stacks", "test-stack.yml"), provisionsDir, servicesDir, nil)
+		require.NoError(t, err)
+		assert.Empty(t, output.Renderers)
+	})
+}
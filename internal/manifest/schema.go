@@ -0,0 +1,314 @@
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaError is a manifest field that doesn't match the manifest schema,
+// with its location in the file.
+type SchemaError struct {
+	File   string
+	Line   int // 1-based; 0 when the error is about the whole file
+	Column int
+	// Field is the path to the field, e.g. "verify[0].http.status". Empty
+	// for errors about the whole manifest.
+	Field   string
+	Message string
+}
+
+// Error formats the error as file:line:column: field: message.
+func (e SchemaError) Error() string {
+	loc := e.File
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", loc, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, e.Field, e.Message)
+}
+
+// SchemaErrors is every schema error found in a manifest.
+type SchemaErrors []SchemaError
+
+// Error joins the errors, one per line.
+func (e SchemaErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ValidateServiceSchema checks a service manifest against the
+// ServiceManifest schema: field types, unknown fields (with a did-you-mean
+// for typos like provsions), and the fields each service type requires.
+// file is used only to locate errors.
+func ValidateServiceSchema(file string, data []byte) SchemaErrors {
+	root, errs := schemaRoot(file, data)
+	if root == nil {
+		return errs
+	}
+
+	errs = append(errs, checkSchemaNode(file, root, reflect.TypeOf(ServiceManifest{}), "")...)
+
+	fields := mappingFields(root)
+	if isBlank(fields["name"]) {
+		errs = append(errs, schemaErrorAt(file, root, "name", "is required"))
+	}
+	typ := ""
+	if !isBlank(fields["type"]) {
+		typ = scalarValue(fields["type"])
+	}
+	switch typ {
+	case "":
+		if isBlank(fields["provisions"]) && isBlank(fields["needs"]) && isBlank(fields["services"]) {
+			errs = append(errs, schemaErrorAt(file, root, "provisions", "is required (or needs or services); without it the service renders nothing"))
+		}
+	case "raw":
+		if isBlank(fields["compose"]) {
+			errs = append(errs, schemaErrorAt(file, root, "compose", "is required for raw services"))
+		}
+	default:
+		errs = append(errs, schemaErrorAt(file, fields["type"], "type", fmt.Sprintf("%q is not a service type (want raw, or omit it)", typ)))
+	}
+	return errs
+}
+
+// ValidateServiceProvisions checks that m's config sets every ${var} its
+// provisions need, reporting each missing one at the provisions entry that
+// pulls it in. data is m's manifest file, used only to locate errors; m
+// may carry config merged in since, such as a values overlay. The error is
+// for a provision that can't be read, which rendering reports.
+func ValidateServiceProvisions(file string, data []byte, m *ServiceManifest, provisionsDir string) (SchemaErrors, error) {
+	issues, err := LintServiceVariables(m, provisionsDir)
+	if len(issues) == 0 {
+		return nil, err
+	}
+
+	root, _ := schemaRoot(file, data)
+	if root == nil {
+		root = &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1}
+	}
+	var entries []*yaml.Node
+	if provisions := mappingFields(root)["provisions"]; provisions != nil && provisions.Kind == yaml.SequenceNode {
+		entries = provisions.Content
+	}
+
+	errs := make(SchemaErrors, 0, len(issues))
+	for _, issue := range issues {
+		node := root
+		if issue.Entry < len(entries) {
+			node = entries[issue.Entry]
+		}
+		provision := m.Provisions[issue.Entry]
+		if ref := m.provisionRef(provision); issue.Provision != ref {
+			provision += fmt.Sprintf(" (via %s)", issue.Provision)
+		}
+		msg := fmt.Sprintf("%s uses ${%s}, which config doesn't set", provision, issue.Variable)
+		if issue.Suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean ${%s}?)", issue.Suggestion)
+		}
+		errs = append(errs, schemaErrorAt(file, node, fmt.Sprintf("provisions[%d]", issue.Entry), msg))
+	}
+	return errs, err
+}
+
+// ValidateStackSchema checks a stack manifest against the Stack schema:
+// field types and unknown fields. file is used only to locate errors.
+func ValidateStackSchema(file string, data []byte) SchemaErrors {
+	root, errs := schemaRoot(file, data)
+	if root == nil {
+		return errs
+	}
+	return append(errs, checkSchemaNode(file, root, reflect.TypeOf(Stack{}), "")...)
+}
+
+// schemaRoot parses data and returns its top-level node, or nil with the
+// errors when there is nothing to check. An empty stack is valid; an empty
+// service is reported by the required-field checks.
+func schemaRoot(file string, data []byte) (*yaml.Node, SchemaErrors) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, SchemaErrors{{File: file, Message: err.Error()}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1}, nil
+	}
+	return doc.Content[0], nil
+}
+
+// checkSchemaNode checks node against the Go type t that it decodes into,
+// following yaml tags. Null values are accepted anywhere, as they decode to
+// the zero value.
+func checkSchemaNode(file string, node *yaml.Node, t reflect.Type, path string) SchemaErrors {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Types with their own decoding, such as build, may take a scalar
+	// shorthand; a mapping is checked as usual.
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) && node.Kind == yaml.ScalarNode {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return nil
+
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return SchemaErrors{schemaTypeError(file, node, path, "a mapping")}
+		}
+		fields := yamlFields(t)
+		var errs SchemaErrors
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				msg := "unknown field"
+				if suggestion := suggestField(key.Value, fields); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %s?)", suggestion)
+				}
+				errs = append(errs, schemaErrorAt(file, key, joinField(path, key.Value), msg))
+				continue
+			}
+			errs = append(errs, checkSchemaNode(file, value, field, joinField(path, key.Value))...)
+		}
+		return errs
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return SchemaErrors{schemaTypeError(file, node, path, "a mapping")}
+		}
+		var errs SchemaErrors
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			errs = append(errs, checkSchemaNode(file, node.Content[i+1], t.Elem(), joinField(path, node.Content[i].Value))...)
+		}
+		return errs
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return SchemaErrors{schemaTypeError(file, node, path, "a list")}
+		}
+		var errs SchemaErrors
+		for i, item := range node.Content {
+			errs = append(errs, checkSchemaNode(file, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			return SchemaErrors{schemaTypeError(file, node, path, "a string")}
+		}
+
+	case reflect.Int, reflect.Int64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return SchemaErrors{schemaTypeError(file, node, path, "an integer")}
+		}
+
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return SchemaErrors{schemaTypeError(file, node, path, "true or false")}
+		}
+	}
+	return nil
+}
+
+// yamlFields maps the yaml keys of a struct's fields to their types.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// suggestField returns the known field closest to name, if it is close
+// enough to be a typo.
+func suggestField(name string, fields map[string]reflect.Type) string {
+	candidates := make(map[string]any, len(fields))
+	for k := range fields {
+		candidates[k] = nil
+	}
+	return SuggestVariable(name, candidates)
+}
+
+// mappingFields returns a mapping node's values by key.
+func mappingFields(node *yaml.Node) map[string]*yaml.Node {
+	fields := make(map[string]*yaml.Node)
+	if node.Kind != yaml.MappingNode {
+		return fields
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+	return fields
+}
+
+// isBlank reports whether a field is missing, null, or empty.
+func isBlank(node *yaml.Node) bool {
+	if node == nil {
+		return true
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Tag == "!!null" || node.Value == ""
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
+
+// joinField appends key to a field path.
+func joinField(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// schemaErrorAt returns an error located at node.
+func schemaErrorAt(file string, node *yaml.Node, field, msg string) SchemaError {
+	return SchemaError{File: file, Line: node.Line, Column: node.Column, Field: field, Message: msg}
+}
+
+// schemaTypeError reports a field of the wrong type.
+func schemaTypeError(file string, node *yaml.Node, field, want string) SchemaError {
+	return schemaErrorAt(file, node, field, fmt.Sprintf("must be %s, got %s", want, describeNode(node)))
+}
+
+// describeNode names the type of a YAML value for error messages.
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!int", "!!float":
+		return fmt.Sprintf("the number %s", node.Value)
+	case "!!bool":
+		return fmt.Sprintf("the boolean %s", node.Value)
+	}
+	return fmt.Sprintf("the string %q", node.Value)
+}
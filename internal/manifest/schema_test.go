@@ -0,0 +1,188 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateServiceSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "valid service",
+			content: `apiVersion: bosun.io/v1
+kind: Service
+name: wiki
+provisions:
+  - webapp
+config:
+  port: 8080
+build: ./wiki
+verify:
+  - name: home
+    http:
+      url: http://wiki:8080
+      status: 200
+`,
+		},
+		{
+			name: "misspelled provisions",
+			content: `name: wiki
+provsions:
+  - webapp
+`,
+			want: []string{
+				"wiki.yml:2:1: provsions: unknown field (did you mean provisions?)",
+				"wiki.yml:1:1: provisions: is required (or needs or services); without it the service renders nothing",
+			},
+		},
+		{
+			name: "wrong types",
+			content: `name: wiki
+provisions: webapp
+verify:
+  - name: home
+    http:
+      url: http://wiki
+      status: ok
+`,
+			want: []string{
+				`wiki.yml:2:13: provisions: must be a list, got the string "webapp"`,
+				`wiki.yml:7:15: verify[0].http.status: must be an integer, got the string "ok"`,
+			},
+		},
+		{
+			name: "nested unknown field",
+			content: `name: wiki
+provisions: [webapp]
+build:
+  contxt: ./wiki
+`,
+			want: []string{"wiki.yml:4:3: build.contxt: unknown field (did you mean context?)"},
+		},
+		{
+			name:    "needs alone is enough",
+			content: "name: wiki\nneeds: [postgres]\n",
+		},
+		{
+			name:    "raw service requires compose",
+			content: "name: wiki\ntype: raw\n",
+			want:    []string{"wiki.yml:1:1: compose: is required for raw services"},
+		},
+		{
+			name:    "unknown type",
+			content: "name: wiki\ntype: helm\nprovisions: [webapp]\n",
+			want:    []string{`wiki.yml:2:7: type: "helm" is not a service type (want raw, or omit it)`},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want: []string{
+				"wiki.yml:1:1: name: is required",
+				"wiki.yml:1:1: provisions: is required (or needs or services); without it the service renders nothing",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateServiceSchema("wiki.yml", []byte(tt.content))
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateStackSchema(t *testing.T) {
+	assert.Empty(t, ValidateStackSchema("apps.yml", []byte("include:\n  - wiki.yml\nnetworks:\n  proxy:\n    external: true\n")))
+	assert.Empty(t, ValidateStackSchema("apps.yml", nil))
+
+	errs := ValidateStackSchema("apps.yml", []byte("includes:\n  - wiki.yml\n"))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "apps.yml:1:1: includes: unknown field (did you mean include?)", errs[0].Error())
+
+	errs = ValidateStackSchema("apps.yml", []byte("include:\n  - wiki.yml\n  - file: db.yml\n"))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "include[1]", errs[0].Field)
+	assert.Equal(t, 3, errs[0].Line)
+
+	errs = ValidateStackSchema("apps.yml", []byte("include: [unclosed\n"))
+	require.Len(t, errs, 1)
+	assert.Zero(t, errs[0].Line)
+}
+
+func TestLoadServiceManifest_SchemaErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wiki.yml")
+	require.NoError(t, os.WriteFile(path, []byte("name: wiki\nprovsions: [webapp]\n"), 0644))
+
+	_, err := LoadServiceManifest(path)
+	var schemaErrs SchemaErrors
+	require.ErrorAs(t, err, &schemaErrs)
+	assert.Equal(t, path, schemaErrs[0].File)
+	assert.Equal(t, 2, schemaErrs[0].Line)
+}
+
+func TestValidateServiceProvisions(t *testing.T) {
+	provisionsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(provisionsDir, "container.yml"),
+		[]byte("compose:\n  services:\n    ${name}:\n      image: ${image}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(provisionsDir, "webapp.yml"),
+		[]byte("includes: [container]\ncompose:\n  services:\n    ${name}:\n      ports: [\"${port}:80\"]\n"), 0644))
+
+	data := []byte("name: wiki\nprovisions:\n  - webapp\nconfig:\n  imgae: nginx\n")
+	var m ServiceManifest
+	require.NoError(t, yaml.Unmarshal(data, &m))
+
+	errs, err := ValidateServiceProvisions("wiki.yml", data, &m, provisionsDir)
+	require.NoError(t, err)
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	assert.Equal(t, []string{
+		"wiki.yml:3:5: provisions[0]: webapp uses ${port}, which config doesn't set",
+		"wiki.yml:3:5: provisions[0]: webapp (via container) uses ${image}, which config doesn't set (did you mean ${imgae}?)",
+	}, got)
+
+	// Config merged in after loading, such as a values overlay, counts
+	m.Config["image"] = "nginx"
+	m.Config["port"] = 8080
+	errs, err = ValidateServiceProvisions("wiki.yml", data, &m, provisionsDir)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestRenderStack_ProvisionErrors(t *testing.T) {
+	dir := t.TempDir()
+	provisionsDir := filepath.Join(dir, "provisions")
+	servicesDir := filepath.Join(dir, "services")
+	require.NoError(t, os.MkdirAll(provisionsDir, 0755))
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(provisionsDir, "container.yml"),
+		[]byte("compose:\n  services:\n    ${name}:\n      image: ${image}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(servicesDir, "wiki.yml"),
+		[]byte("name: wiki\nprovisions: [container]\n"), 0644))
+	stackPath := filepath.Join(dir, "apps.yml")
+	require.NoError(t, os.WriteFile(stackPath, []byte("include: [wiki.yml]\n"), 0644))
+
+	_, err := RenderStack(stackPath, provisionsDir, servicesDir, nil)
+	var schemaErrs SchemaErrors
+	require.ErrorAs(t, err, &schemaErrs)
+	assert.Equal(t, "provisions[0]", schemaErrs[0].Field)
+	assert.Equal(t, 2, schemaErrs[0].Line)
+
+	// The values overlay can supply what the service's config leaves out
+	_, err = RenderStack(stackPath, provisionsDir, servicesDir, map[string]any{"image": "nginx"})
+	require.NoError(t, err)
+}
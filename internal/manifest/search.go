@@ -0,0 +1,178 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Search layers, in the order a value flows through the manifest system.
+const (
+	LayerService   = "service"
+	LayerProvision = "provision"
+	LayerStack     = "stack"
+	LayerRendered  = "rendered"
+)
+
+// SearchMatch is a single line matching a search term.
+type SearchMatch struct {
+	// Layer is where the match was found (service, provision, stack, rendered).
+	Layer string
+	// Source is the file path, or for rendered matches the service and target
+	// (e.g. "myapp compose").
+	Source string
+	// Line is the 1-based line number within the source.
+	Line int
+	// Text is the matching line with surrounding whitespace trimmed.
+	Text string
+}
+
+// SearchOptions configures a manifest search.
+type SearchOptions struct {
+	ServicesDir   string
+	ProvisionsDir string
+	StacksDir     string
+	// CaseSensitive disables the default case-insensitive matching.
+	CaseSensitive bool
+}
+
+// Search looks for term in service manifests, provisions, stack files, and the
+// rendered output of every service. Rendered matches catch values that only
+// exist after interpolation and merging, which grepping the repo misses.
+// Services that fail to render are skipped; lint reports those.
+func Search(term string, opts SearchOptions) ([]SearchMatch, error) {
+	match := func(line string) bool {
+		if opts.CaseSensitive {
+			return strings.Contains(line, term)
+		}
+		return strings.Contains(strings.ToLower(line), strings.ToLower(term))
+	}
+
+	var matches []SearchMatch
+	for _, layer := range []struct {
+		name string
+		dir  string
+	}{
+		{LayerService, opts.ServicesDir},
+		{LayerProvision, opts.ProvisionsDir},
+		{LayerStack, opts.StacksDir},
+	} {
+		found, err := searchDir(layer.name, layer.dir, match)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, found...)
+	}
+
+	rendered, err := searchRendered(opts.ServicesDir, opts.ProvisionsDir, match)
+	if err != nil {
+		return nil, err
+	}
+	return append(matches, rendered...), nil
+}
+
+// searchDir searches every YAML file in dir. A missing directory yields no matches.
+func searchDir(layer, dir string, match func(string) bool) ([]SearchMatch, error) {
+	files, err := yamlFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []SearchMatch
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		matches = append(matches, searchContent(layer, path, content, match)...)
+	}
+	return matches, nil
+}
+
+// searchRendered renders each service and searches its compose, traefik, and gatus output.
+func searchRendered(servicesDir, provisionsDir string, match func(string) bool) ([]SearchMatch, error) {
+	files, err := yamlFiles(servicesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []SearchMatch
+	for _, path := range files {
+		m, err := LoadServiceManifest(path)
+		if err != nil {
+			continue
+		}
+		output, err := RenderService(m, provisionsDir)
+		if err != nil {
+			continue
+		}
+
+		for _, target := range []struct {
+			name    string
+			content map[string]any
+		}{
+			{"compose", output.Compose},
+			{"traefik", output.Traefik},
+			{"gatus", output.Gatus},
+		} {
+			if len(target.content) == 0 {
+				continue
+			}
+			data, err := yaml.Marshal(target.content)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s output for %s: %w", target.name, m.Name, err)
+			}
+			source := m.Name + " " + target.name
+			matches = append(matches, searchContent(LayerRendered, source, data, match)...)
+		}
+	}
+	return matches, nil
+}
+
+// searchContent returns the lines of content that match.
+func searchContent(layer, source string, content []byte, match func(string) bool) []SearchMatch {
+	var matches []SearchMatch
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if match(text) {
+			matches = append(matches, SearchMatch{
+				Layer:  layer,
+				Source: source,
+				Line:   line,
+				Text:   strings.TrimSpace(text),
+			})
+		}
+	}
+	return matches
+}
+
+// yamlFiles returns the sorted .yml and .yaml files directly under dir.
+func yamlFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read directory %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if !e.IsDir() && (ext == ".yml" || ext == ".yaml") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
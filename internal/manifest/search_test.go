@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSearchOptions() SearchOptions {
+	return SearchOptions{
+		ServicesDir:   filepath.Join("testdata", "services"),
+		ProvisionsDir: filepath.Join("testdata", "provisions"),
+		StacksDir:     filepath.Join("testdata", "stacks"),
+	}
+}
+
+func matchLayers(matches []SearchMatch) map[string]int {
+	layers := make(map[string]int)
+	for _, m := range matches {
+		layers[m.Layer]++
+	}
+	return layers
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("finds values that only exist after rendering", func(t *testing.T) {
+		matches, err := Search("mywebapp.example.com", testSearchOptions())
+		require.NoError(t, err)
+
+		layers := matchLayers(matches)
+		assert.Zero(t, layers[LayerService])
+		assert.Positive(t, layers[LayerRendered])
+
+		for _, m := range matches {
+			assert.Contains(t, m.Source, "mywebapp")
+			assert.Positive(t, m.Line)
+		}
+	})
+
+	t.Run("reports the layer of each match", func(t *testing.T) {
+		matches, err := Search("ghcr.io/example/mywebapp", testSearchOptions())
+		require.NoError(t, err)
+
+		layers := matchLayers(matches)
+		assert.Equal(t, 1, layers[LayerService])
+		assert.Positive(t, layers[LayerRendered])
+
+		matches, err = Search("driver: bridge", testSearchOptions())
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, LayerStack, matches[0].Layer)
+		assert.Equal(t, filepath.Join("testdata", "stacks", "test-stack.yml"), matches[0].Source)
+		assert.Equal(t, "driver: bridge", matches[0].Text)
+	})
+
+	t.Run("case sensitivity", func(t *testing.T) {
+		matches, err := Search("MY WEB APPLICATION", testSearchOptions())
+		require.NoError(t, err)
+		assert.NotEmpty(t, matches)
+
+		opts := testSearchOptions()
+		opts.CaseSensitive = true
+		matches, err = Search("MY WEB APPLICATION", opts)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("missing directories yield no matches", func(t *testing.T) {
+		dir := t.TempDir()
+		matches, err := Search("anything", SearchOptions{
+			ServicesDir:   filepath.Join(dir, "services"),
+			ProvisionsDir: filepath.Join(dir, "provisions"),
+			StacksDir:     filepath.Join(dir, "stacks"),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
@@ -37,9 +37,14 @@ type ServiceManifest struct {
 	// Type is "raw" for passthrough mode, empty for normal provisioning.
 	Type string `yaml:"type,omitempty"`
 
-	// Provisions is the list of provision templates to apply.
+	// Provisions is the list of provision templates to apply. An entry may
+	// pin a version: webapp@v2 (see ReadProvision).
 	Provisions []string `yaml:"provisions,omitempty"`
 
+	// ProvisionsVersion pins every unpinned provision, need, and sidecar of
+	// the service to a version directory or git ref of the provision library.
+	ProvisionsVersion string `yaml:"provisions_version,omitempty"`
+
 	// Config holds variables for interpolation into provisions.
 	Config map[string]any `yaml:"config,omitempty"`
 
@@ -52,6 +57,24 @@ type ServiceManifest struct {
 
 	// Compose is used in raw mode to pass through compose config directly.
 	Compose map[string]any `yaml:"compose,omitempty"`
+
+	// Build builds the service's image from source instead of pulling it.
+	Build *BuildConfig `yaml:"build,omitempty"`
+
+	// Verify lists smoke tests run after each deploy and by bosun verify.
+	Verify []SmokeTest `yaml:"verify,omitempty"`
+
+	// Readiness is a probe that must pass before the deploy health gate does.
+	Readiness *ReadinessProbe `yaml:"readiness,omitempty"`
+
+	// Backup lists config paths, relative to the appdata root, included in
+	// every reconcile backup.
+	Backup []string `yaml:"backup,omitempty"`
+
+	// Profiles are compose profiles the service and its sidecars belong to.
+	// A profiled service only starts when one of its profiles is enabled,
+	// e.g. with bosun reconcile --profile.
+	Profiles []string `yaml:"profiles,omitempty"`
 }
 
 // Provision represents a loaded provision template with outputs for each target.
@@ -85,6 +108,10 @@ type RenderOutput struct {
 
 	// Gatus output for endpoints.yml.
 	Gatus map[string]any
+
+	// Renderers lists the renderers that write this output.
+	// Empty means DefaultRenderers.
+	Renderers []string
 }
 
 // NewRenderOutput creates an initialized RenderOutput with empty maps.
@@ -109,6 +136,10 @@ type Stack struct {
 
 	// Networks defines network configurations for the stack.
 	Networks map[string]any `yaml:"networks,omitempty"`
+
+	// Renderers lists the output targets for the stack (e.g. ["compose", "k8s"]).
+	// Empty means DefaultRenderers.
+	Renderers []string `yaml:"renderers,omitempty"`
 }
 
 // SidecarDefaults provides default configuration for common sidecars.
@@ -0,0 +1,291 @@
+package manifest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// memoryPattern matches compose memory sizes the way Docker parses them: a number
+// with an optional unit (k, m, g, t, p, optionally followed by "ib" or "b").
+var memoryPattern = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([kmgtp]?i?b?)$`)
+
+// memoryUnits maps a normalized unit prefix to its size in bytes.
+var memoryUnits = map[string]int64{
+	"":  1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+	"p": 1 << 50,
+}
+
+// minBareMemory is the smallest unitless memory value accepted. Compose reads
+// bare numbers as bytes, so "memory: 512" is almost always a missing "m".
+const minBareMemory = 1 << 20
+
+// ValueError describes an invalid value at a path in rendered output.
+type ValueError struct {
+	// Path locates the value, e.g. "compose.services.app.mem_limit".
+	Path string
+	// Value is the offending value.
+	Value any
+	// Message explains the problem and, where possible, the fix.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s: %v: %s", e.Path, e.Value, e.Message)
+}
+
+// ParseMemory parses a compose memory size (512m, 2g, 1.5GB, 256MiB, 1073741824) into bytes.
+// Unitless values below 1MiB are rejected as likely missing a unit.
+func ParseMemory(value any) (int64, error) {
+	s := strings.TrimSpace(toString(value))
+	m := memoryPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid memory size %q (expected e.g. 512m, 2g)", s)
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size %q: %w", s, err)
+	}
+
+	unit := strings.TrimRight(strings.ToLower(m[2]), "ib")
+	bytes := int64(n * float64(memoryUnits[unit]))
+
+	if m[2] == "" && bytes < minBareMemory {
+		return 0, fmt.Errorf("memory size %s has no unit and would be %s bytes; did you mean %sm?", s, s, s)
+	}
+	return bytes, nil
+}
+
+// ParseDuration parses a compose duration (30s, 1m30s, 500ms).
+// Unitless numbers are rejected since compose requires a unit.
+func ParseDuration(value any) (time.Duration, error) {
+	s := strings.TrimSpace(toString(value))
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return 0, fmt.Errorf("duration %s has no unit; did you mean %ss?", s, s)
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (expected e.g. 30s, 1m30s)", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration %s cannot be negative", s)
+	}
+	return d, nil
+}
+
+// ValidatePort checks that a port number or range ("8000-8010") is within 1-65535.
+func ValidatePort(value any) error {
+	s := strings.TrimSpace(toString(value))
+	start, end, isRange := strings.Cut(s, "-")
+
+	ports := []string{start}
+	if isRange {
+		ports = append(ports, end)
+	}
+
+	for _, p := range ports {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return fmt.Errorf("invalid port %q", s)
+		}
+		if n < 1 || n > 65535 {
+			return fmt.Errorf("port %d out of range (1-65535)", n)
+		}
+	}
+	return nil
+}
+
+// ValidatePortMapping checks a compose port entry in short syntax
+// ("80", "8080:80", "127.0.0.1:8080:80/tcp", "[::1]:8080:80") or long
+// syntax (target/published). Values compose interpolates at deploy time
+// ("${PORT:-8080}:80") can't be checked and pass.
+func ValidatePortMapping(entry any) error {
+	switch v := entry.(type) {
+	case map[string]any:
+		for _, key := range []string{"target", "published"} {
+			if p, ok := v[key]; ok && !interpolated(p) {
+				if err := ValidatePort(p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	default:
+		s := toString(v)
+		if interpolated(s) {
+			return nil
+		}
+		s, _, _ = strings.Cut(s, "/") // Strip protocol
+		if rest, ok := strings.CutPrefix(s, "["); ok {
+			// IPv6 host IP in brackets: [::1]:8080:80
+			_, ports, found := strings.Cut(rest, "]:")
+			if !found {
+				return fmt.Errorf("invalid port mapping %q", toString(v))
+			}
+			s = "::" + ports // Placeholder IP part, dropped below
+		}
+		parts := strings.Split(s, ":")
+		// IP address (first part of a three-part mapping) is not a port.
+		if len(parts) == 3 {
+			parts = parts[1:]
+		} else if len(parts) > 3 {
+			parts = parts[len(parts)-2:] // "::" placeholder for an IPv6 host IP
+		}
+		for _, p := range parts {
+			if p == "" {
+				continue // "::80" style or empty host port
+			}
+			if err := ValidatePort(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// interpolated reports whether a value holds a compose variable ($VAR or
+// ${VAR:-default}) that is only resolved at deploy time.
+func interpolated(value any) bool {
+	s, ok := value.(string)
+	return ok && strings.Contains(s, "$")
+}
+
+// ValidateURL checks that a value is an absolute URL with a scheme and host.
+func ValidateURL(value any) error {
+	s := toString(value)
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", s, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("URL %q must include a scheme and host (e.g. https://app.example.com)", s)
+	}
+	return nil
+}
+
+// composeMemoryFields are compose service keys holding memory sizes.
+var composeMemoryFields = []string{"mem_limit", "mem_reservation", "memswap_limit", "shm_size"}
+
+// unlimitedSwap is the memswap_limit value Docker reads as unlimited swap.
+const unlimitedSwap = "-1"
+
+// validateSwapLimit checks a memswap_limit: a memory size, or -1 for
+// unlimited swap.
+func validateSwapLimit(value any) error {
+	if strings.TrimSpace(toString(value)) == unlimitedSwap {
+		return nil
+	}
+	_, err := ParseMemory(value)
+	return err
+}
+
+// composeDurationFields are compose service keys holding durations.
+var composeDurationFields = []string{"stop_grace_period"}
+
+// healthcheckDurationFields are healthcheck keys holding durations.
+var healthcheckDurationFields = []string{"interval", "timeout", "start_period", "start_interval"}
+
+// ValidateOutputValues checks memory sizes, durations, ports, and URLs in rendered
+// output so unit mistakes are caught before compose rejects (or misreads) them.
+// Values holding compose variables are left for compose to resolve.
+func ValidateOutputValues(output *RenderOutput) []*ValueError {
+	var errs []*ValueError
+	check := func(path string, value any, fn func(any) error) {
+		if interpolated(value) {
+			return
+		}
+		if err := fn(value); err != nil {
+			errs = append(errs, &ValueError{Path: path, Value: value, Message: err.Error()})
+		}
+	}
+	memory := func(v any) error { _, err := ParseMemory(v); return err }
+	duration := func(v any) error { _, err := ParseDuration(v); return err }
+
+	services, _ := output.Compose["services"].(map[string]any)
+	for _, name := range sortedKeys(services) {
+		svc, ok := services[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		base := "compose.services." + name
+
+		for _, field := range composeMemoryFields {
+			if v, ok := svc[field]; ok {
+				if field == "memswap_limit" {
+					check(base+"."+field, v, validateSwapLimit)
+				} else {
+					check(base+"."+field, v, memory)
+				}
+			}
+		}
+		for _, field := range composeDurationFields {
+			if v, ok := svc[field]; ok {
+				check(base+"."+field, v, duration)
+			}
+		}
+
+		if hc, ok := svc["healthcheck"].(map[string]any); ok {
+			for _, field := range healthcheckDurationFields {
+				if v, ok := hc[field]; ok {
+					check(base+".healthcheck."+field, v, duration)
+				}
+			}
+		}
+
+		if deploy, ok := svc["deploy"].(map[string]any); ok {
+			if resources, ok := deploy["resources"].(map[string]any); ok {
+				for _, kind := range []string{"limits", "reservations"} {
+					if r, ok := resources[kind].(map[string]any); ok {
+						if v, ok := r["memory"]; ok {
+							check(base+".deploy.resources."+kind+".memory", v, memory)
+						}
+					}
+				}
+			}
+		}
+
+		if ports, ok := svc["ports"].([]any); ok {
+			for i, p := range ports {
+				check(fmt.Sprintf("%s.ports[%d]", base, i), p, ValidatePortMapping)
+			}
+		}
+	}
+
+	if endpoints, ok := output.Gatus["endpoints"].([]any); ok {
+		for i, e := range endpoints {
+			endpoint, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			if v, ok := endpoint["url"]; ok {
+				check(fmt.Sprintf("gatus.endpoints[%d].url", i), v, ValidateURL)
+			}
+			if v, ok := endpoint["interval"]; ok {
+				check(fmt.Sprintf("gatus.endpoints[%d].interval", i), v, duration)
+			}
+		}
+	}
+
+	return errs
+}
+
+// sortedKeys returns the keys of m in sorted order for stable output.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+package manifest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		input   any
+		want    int64
+		wantErr string
+	}{
+		{"512m", 512 << 20, ""},
+		{"2g", 2 << 30, ""},
+		{"1.5GB", 3 << 29, ""},
+		{"256MiB", 256 << 20, ""},
+		{"1mb", 1 << 20, ""},
+		{"128k", 128 << 10, ""},
+		{"1073741824", 1 << 30, ""},
+		{512, 0, "did you mean 512m?"},
+		{"512", 0, "did you mean 512m?"},
+		{"lots", 0, "invalid memory size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(toString(tt.input), func(t *testing.T) {
+			got, err := ParseMemory(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration("1m30s")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+
+	_, err = ParseDuration(30)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did you mean 30s?")
+
+	_, err = ParseDuration("soon")
+	assert.Error(t, err)
+
+	_, err = ParseDuration("-5s")
+	assert.Error(t, err)
+}
+
+func TestValidatePortMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   any
+		wantErr bool
+	}{
+		{name: "container port", entry: 80},
+		{name: "host and container", entry: "8080:80"},
+		{name: "host IP and protocol", entry: "127.0.0.1:8080:80/tcp"},
+		{name: "range", entry: "8000-8010:8000-8010"},
+		{name: "udp", entry: "53:53/udp"},
+		{name: "long syntax", entry: map[string]any{"target": 80, "published": "8080"}},
+		{name: "IPv6 host IP", entry: "[::1]:8080:80"},
+		{name: "IPv6 host IP and protocol", entry: "[2001:db8::1]:53:53/udp"},
+		{name: "IPv6 host IP, ephemeral host port", entry: "[::1]::80"},
+		{name: "interpolated host port", entry: "${PORT:-8080}:80"},
+		{name: "interpolated mapping", entry: "${WEB_PORTS}"},
+		{name: "bare variable", entry: "$PORT:80"},
+		{name: "interpolated long syntax", entry: map[string]any{"target": 80, "published": "${PORT}"}},
+		{name: "zero", entry: 0, wantErr: true},
+		{name: "out of range", entry: "70000:80", wantErr: true},
+		{name: "not a number", entry: "http:80", wantErr: true},
+		{name: "long syntax out of range", entry: map[string]any{"target": 99999}, wantErr: true},
+		{name: "IPv6 out of range", entry: "[::1]:70000:80", wantErr: true},
+		{name: "unclosed IPv6 bracket", entry: "[::1:8080:80", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePortMapping(tt.entry)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	assert.NoError(t, ValidateURL("https://app.example.com/health"))
+	assert.Error(t, ValidateURL("app.example.com"))
+	assert.Error(t, ValidateURL("https://"))
+}
+
+func TestValidateOutputValues(t *testing.T) {
+	output := NewRenderOutput()
+	output.Compose["services"] = map[string]any{
+		"app": map[string]any{
+			"mem_limit": 512,
+			"healthcheck": map[string]any{
+				"interval": "30s",
+				"timeout":  5,
+			},
+			"deploy": map[string]any{
+				"resources": map[string]any{
+					"limits": map[string]any{"memory": "1g"},
+				},
+			},
+			"ports": []any{"8080:80", "99999:80", "${PORT:-8080}:80", "[::1]:9090:90"},
+		},
+		"db": map[string]any{
+			"mem_limit":         "${DB_MEMORY:-1g}",
+			"stop_grace_period": "${GRACE}",
+			"memswap_limit":     -1,
+		},
+		"cache": map[string]any{
+			"memswap_limit":   "-1",
+			"mem_reservation": -1,
+		},
+		"worker": map[string]any{
+			"memswap_limit": "2g",
+		},
+	}
+	output.Gatus["endpoints"] = []any{
+		map[string]any{"url": "app.example.com", "interval": "60s"},
+	}
+
+	errs := ValidateOutputValues(output)
+	paths := make([]string, 0, len(errs))
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"compose.services.app.mem_limit",
+		"compose.services.app.healthcheck.timeout",
+		"compose.services.app.ports[1]",
+		"compose.services.cache.mem_reservation",
+		"gatus.endpoints[0].url",
+	}, paths, "memswap_limit accepts -1 for unlimited swap")
+}
+
+func TestRenderService_RejectsInvalidValues(t *testing.T) {
+	m := &ServiceManifest{
+		Name: "app",
+		Type: "raw",
+		Compose: map[string]any{
+			"app": map[string]any{"image": "nginx", "mem_limit": 512},
+		},
+	}
+
+	_, err := RenderService(m, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did you mean 512m?")
+}
@@ -0,0 +1,186 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Defaults shown for variables bosun sets itself.
+const (
+	defaultServiceName = "(service name)"
+	defaultSidecarType = "(sidecar type)"
+	defaultHostFact    = "(host fact)"
+)
+
+// ProvisionVariable documents a ${var} that a provision consumes, found by
+// reading the provision and its includes without rendering them.
+type ProvisionVariable struct {
+	// Name is the variable name, e.g. "port" or "host.ip".
+	Name string `json:"name"`
+	// Default is the value used when no service sets it: a sidecar default
+	// for needs, or a placeholder for variables bosun sets itself. Empty
+	// means every service using the provision must set it.
+	Default string `json:"default,omitempty"`
+	// Provisions are the provision files that reference it: the provision
+	// itself or any provision it includes.
+	Provisions []string `json:"provisions"`
+	// SetBy are the services using the provision whose config sets it.
+	SetBy []string `json:"set_by,omitempty"`
+}
+
+// Required reports whether services must set the variable themselves.
+func (v ProvisionVariable) Required() bool {
+	return v.Default == ""
+}
+
+// ProvisionVariables returns the variables provisionName consumes, sorted
+// by name. services are the service manifests searched for SetBy; a service
+// uses the provision when it lists it (directly or through an include) in
+// provisions, needs, or services.
+func ProvisionVariables(provisionName, provisionsDir string, services []*ServiceManifest) ([]ProvisionVariable, error) {
+	refs, defaults, err := provisionReferences(provisionName, provisionsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Which provisions each service reaches, for SetBy.
+	reaches := make(map[string]map[string]bool, len(services))
+	for _, svc := range services {
+		reaches[svc.Name] = serviceProvisions(svc, provisionsDir)
+	}
+
+	vars := make([]ProvisionVariable, 0, len(refs))
+	for name, sources := range refs {
+		v := ProvisionVariable{Name: name, Default: variableDefault(provisionName, name), Provisions: sources}
+		if v.Default == "" {
+			v.Default = defaults[name]
+		}
+		for _, svc := range services {
+			if reaches[svc.Name][provisionName] && serviceSets(svc, provisionName, name) {
+				v.SetBy = append(v.SetBy, svc.Name)
+			}
+		}
+		sort.Strings(v.SetBy)
+		vars = append(vars, v)
+	}
+
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars, nil
+}
+
+// provisionReferences maps each variable referenced by provisionName or its
+// includes to the provisions that reference it, and each variable that is
+// only read through default() to its fallback.
+func provisionReferences(provisionName, provisionsDir string) (map[string][]string, map[string]string, error) {
+	refs := make(map[string][]string)
+	defaults := make(map[string]string)
+	required := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var walk func(name string) error
+	walk = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+
+		content, err := ReadProvision(name, provisionsDir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("provision not found: %s", name)
+			}
+			return fmt.Errorf("read provision %s: %w", name, err)
+		}
+
+		defaulted := DefaultedVariables(string(content))
+		for _, v := range ReferencedVariables(string(content)) {
+			refs[v] = append(refs[v], name)
+			if fallback, ok := defaulted[v]; !ok {
+				required[v] = true
+			} else if _, ok := defaults[v]; !ok {
+				defaults[v] = fallback
+			}
+		}
+		_, version := ParseProvisionRef(name)
+		for _, included := range provisionIncludes(content) {
+			if err := walk(withVersion(included, version)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(provisionName); err != nil {
+		return nil, nil, err
+	}
+	for v := range required {
+		delete(defaults, v)
+	}
+	return refs, defaults, nil
+}
+
+// serviceProvisions returns every provision a service renders, including
+// sidecars and included provisions. Unreadable provisions are skipped.
+func serviceProvisions(m *ServiceManifest, provisionsDir string) map[string]bool {
+	if m.Type == "raw" {
+		return nil
+	}
+
+	reached := make(map[string]bool)
+	var walk func(ref string)
+	walk = func(ref string) {
+		if reached[ref] {
+			return
+		}
+		reached[ref] = true
+		content, err := ReadProvision(ref, provisionsDir)
+		if err != nil {
+			return
+		}
+		_, version := ParseProvisionRef(ref)
+		for _, included := range provisionIncludes(content) {
+			walk(withVersion(included, version))
+		}
+	}
+
+	for ref := range serviceProvisionRefs(m) {
+		walk(ref)
+	}
+	return reached
+}
+
+// serviceSets reports whether a service's config, or its explicit sidecar
+// config for provisionName, sets the variable.
+func serviceSets(m *ServiceManifest, provisionName, variable string) bool {
+	if _, ok := m.Config[variable]; ok {
+		return true
+	}
+	name, _ := ParseProvisionRef(provisionName)
+	_, ok := m.Services[name][variable]
+	return ok
+}
+
+// variableDefault returns the value a variable has when no service sets it,
+// or "" when it has none.
+func variableDefault(provisionName, variable string) string {
+	switch {
+	case variable == "name":
+		return defaultServiceName
+	case variable == "sidecar":
+		return defaultSidecarType
+	case strings.HasPrefix(variable, "host."):
+		return defaultHostFact
+	}
+	name, _ := ParseProvisionRef(provisionName)
+	if v, ok := SidecarDefaults[name][variable]; ok {
+		// A default that is only a reference to the same variable, like
+		// db_password: ${db_password}, is no default at all.
+		if s := toString(v); s != "${"+variable+"}" {
+			return s
+		}
+	}
+	return ""
+}
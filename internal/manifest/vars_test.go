@@ -0,0 +1,91 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestServices(t *testing.T) []*ServiceManifest {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "services", "*.yml"))
+	require.NoError(t, err)
+
+	var services []*ServiceManifest
+	for _, f := range files {
+		m, err := LoadServiceManifest(f)
+		require.NoError(t, err)
+		services = append(services, m)
+	}
+	return services
+}
+
+func findVariable(vars []ProvisionVariable, name string) *ProvisionVariable {
+	for i := range vars {
+		if vars[i].Name == name {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+func TestProvisionVariables(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+	services := loadTestServices(t)
+
+	t.Run("direct provision", func(t *testing.T) {
+		vars, err := ProvisionVariables("container", provisionsDir, services)
+		require.NoError(t, err)
+
+		var names []string
+		for _, v := range vars {
+			names = append(names, v.Name)
+		}
+		assert.Equal(t, []string{"image", "name"}, names)
+
+		image := findVariable(vars, "image")
+		assert.True(t, image.Required())
+		assert.Equal(t, []string{"container"}, image.Provisions)
+		assert.Equal(t, []string{"dbapp", "fullapp", "myapp", "mywebapp"}, image.SetBy)
+
+		name := findVariable(vars, "name")
+		assert.False(t, name.Required())
+		assert.Equal(t, defaultServiceName, name.Default)
+	})
+
+	t.Run("includes", func(t *testing.T) {
+		vars, err := ProvisionVariables("webapp", provisionsDir, services)
+		require.NoError(t, err)
+
+		image := findVariable(vars, "image")
+		require.NotNil(t, image)
+		assert.Equal(t, []string{"container"}, image.Provisions)
+		assert.Equal(t, []string{"mywebapp"}, image.SetBy)
+
+		port := findVariable(vars, "port")
+		require.NotNil(t, port)
+		assert.Contains(t, port.Provisions, "reverse-proxy")
+	})
+
+	t.Run("sidecar defaults", func(t *testing.T) {
+		vars, err := ProvisionVariables("postgres", provisionsDir, services)
+		require.NoError(t, err)
+
+		version := findVariable(vars, "version")
+		require.NotNil(t, version)
+		assert.Equal(t, "17", version.Default)
+		assert.Equal(t, []string{"fullapp"}, version.SetBy, "set in the explicit sidecar config")
+
+		password := findVariable(vars, "db_password")
+		require.NotNil(t, password)
+		assert.True(t, password.Required(), "a self-reference is not a default")
+		assert.Equal(t, []string{"dbapp", "fullapp"}, password.SetBy)
+	})
+
+	t.Run("missing provision", func(t *testing.T) {
+		_, err := ProvisionVariables("nope", provisionsDir, services)
+		assert.ErrorContains(t, err, "provision not found: nope")
+	})
+}
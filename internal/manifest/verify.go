@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VerifyExtension is the compose service extension that carries a service's
+// smoke tests into the rendered compose file, where bosun verify and the
+// post-deploy step read them.
+const VerifyExtension = "x-bosun-verify"
+
+// SmokeTest is a post-deploy check declared by a service manifest: either an
+// HTTP request or a command run inside a container.
+type SmokeTest struct {
+	// Name identifies the test in results.
+	Name string `yaml:"name"`
+
+	// HTTP sends a request and checks the response.
+	HTTP *HTTPSmokeTest `yaml:"http,omitempty"`
+
+	// Exec runs a command in the container and checks its exit code.
+	Exec []string `yaml:"exec,omitempty"`
+	// ExitCode is the expected exit code of Exec (default 0).
+	ExitCode int `yaml:"exit_code,omitempty"`
+	// Container runs Exec in another container (default: the service's).
+	Container string `yaml:"container,omitempty"`
+
+	// Timeout bounds the test, e.g. "10s" (default 10s).
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// HTTPSmokeTest is an HTTP request with an expected response.
+type HTTPSmokeTest struct {
+	URL    string `yaml:"url"`
+	Method string `yaml:"method,omitempty"`
+	// Status is the expected status code (default 200).
+	Status int `yaml:"status,omitempty"`
+	// Body must appear in the response body when set.
+	Body string `yaml:"body,omitempty"`
+}
+
+// Validate checks that the test has a name and exactly one kind of check.
+func (t SmokeTest) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("smoke test needs a name")
+	}
+	if (t.HTTP == nil) == (len(t.Exec) == 0) {
+		return fmt.Errorf("smoke test %s: set exactly one of http or exec", t.Name)
+	}
+	if t.HTTP != nil && t.HTTP.URL == "" {
+		return fmt.Errorf("smoke test %s: http.url is required", t.Name)
+	}
+	if t.Timeout != "" {
+		if _, err := time.ParseDuration(t.Timeout); err != nil {
+			return fmt.Errorf("smoke test %s: invalid timeout %q", t.Name, t.Timeout)
+		}
+	}
+	return nil
+}
+
+// applyVerify adds the manifest's smoke tests to its compose service,
+// interpolating variables in HTTP URLs and expected bodies.
+func applyVerify(output *RenderOutput, m *ServiceManifest, variables map[string]any) error {
+	if len(m.Verify) == 0 {
+		return nil
+	}
+
+	services, _ := output.Compose["services"].(map[string]any)
+	service, _ := services[m.Name].(map[string]any)
+	if service == nil {
+		return fmt.Errorf("verify: no compose service named %s", m.Name)
+	}
+
+	tests := make([]SmokeTest, 0, len(m.Verify))
+	for _, t := range m.Verify {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+		if t.HTTP != nil {
+			http := *t.HTTP
+			var err error
+			if http.URL, err = Interpolate(http.URL, variables); err != nil {
+				return fmt.Errorf("verify %s: %w", t.Name, err)
+			}
+			if http.Body, err = Interpolate(http.Body, variables); err != nil {
+				return fmt.Errorf("verify %s: %w", t.Name, err)
+			}
+			t.HTTP = &http
+		}
+		tests = append(tests, t)
+	}
+
+	// Round-trip through YAML so the output holds plain maps like the rest
+	// of the compose content.
+	data, err := yaml.Marshal(tests)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	var section []any
+	if err := yaml.Unmarshal(data, &section); err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	service[VerifyExtension] = section
+	return nil
+}
@@ -0,0 +1,73 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmokeTest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		test    SmokeTest
+		wantErr string
+	}{
+		{"http", SmokeTest{Name: "web", HTTP: &HTTPSmokeTest{URL: "http://app:8080/"}}, ""},
+		{"exec", SmokeTest{Name: "db", Exec: []string{"pg_isready"}}, ""},
+		{"missing name", SmokeTest{Exec: []string{"true"}}, "needs a name"},
+		{"neither", SmokeTest{Name: "x"}, "exactly one"},
+		{"both", SmokeTest{Name: "x", HTTP: &HTTPSmokeTest{URL: "http://a"}, Exec: []string{"true"}}, "exactly one"},
+		{"missing url", SmokeTest{Name: "x", HTTP: &HTTPSmokeTest{}}, "http.url is required"},
+		{"bad timeout", SmokeTest{Name: "x", Exec: []string{"true"}, Timeout: "soon"}, "invalid timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.test.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderService_Verify(t *testing.T) {
+	provisionsDir := filepath.Join("testdata", "provisions")
+
+	t.Run("adds interpolated tests to the service", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "myapp"},
+			Verify: []SmokeTest{
+				{Name: "web", HTTP: &HTTPSmokeTest{URL: "http://${name}:8080/health", Body: "ok"}},
+				{Name: "cli", Exec: []string{"myapp", "check"}, ExitCode: 2},
+			},
+		}
+
+		output, err := RenderService(m, provisionsDir)
+		require.NoError(t, err)
+
+		svc := output.Compose["services"].(map[string]any)["myapp"].(map[string]any)
+		assert.Equal(t, []any{
+			map[string]any{"name": "web", "http": map[string]any{"url": "http://myapp:8080/health", "body": "ok"}},
+			map[string]any{"name": "cli", "exec": []any{"myapp", "check"}, "exit_code": 2},
+		}, svc[VerifyExtension])
+	})
+
+	t.Run("rejects invalid tests", func(t *testing.T) {
+		m := &ServiceManifest{
+			Name:       "myapp",
+			Provisions: []string{"container"},
+			Config:     map[string]any{"image": "myapp"},
+			Verify:     []SmokeTest{{Name: "web"}},
+		}
+
+		_, err := RenderService(m, provisionsDir)
+		assert.ErrorContains(t, err, "exactly one")
+	})
+}
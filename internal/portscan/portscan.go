@@ -0,0 +1,160 @@
+// Package portscan probes host TCP ports to find ones already taken by
+// processes outside Docker's view.
+package portscan
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHost is the address probed for listeners.
+	DefaultHost = "127.0.0.1"
+
+	// DefaultConcurrency bounds how many dials run at once.
+	DefaultConcurrency = 32
+
+	// DefaultTimeout is how long each dial waits before treating the port as free.
+	DefaultTimeout = 250 * time.Millisecond
+)
+
+// Scanner probes TCP ports with bounded concurrency.
+type Scanner struct {
+	host        string
+	concurrency int
+	timeout     time.Duration
+	dialer      func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+// WithHost sets the address to probe.
+func WithHost(host string) Option {
+	return func(s *Scanner) {
+		s.host = host
+	}
+}
+
+// WithConcurrency sets the maximum number of dials in flight.
+func WithConcurrency(n int) Option {
+	return func(s *Scanner) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithTimeout sets the per-port dial timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Scanner) {
+		if d > 0 {
+			s.timeout = d
+		}
+	}
+}
+
+// New creates a Scanner with the given options.
+func New(opts ...Option) *Scanner {
+	s := &Scanner{
+		host:        DefaultHost,
+		concurrency: DefaultConcurrency,
+		timeout:     DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.dialer == nil {
+		d := &net.Dialer{}
+		s.dialer = d.DialContext
+	}
+	return s
+}
+
+// InUse dials each port and returns the set that accepted a connection.
+// A refused or timed-out dial counts as free. Stops early if ctx is done.
+func (s *Scanner) InUse(ctx context.Context, ports []int) map[int]bool {
+	inUse := make(map[int]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+
+	for _, port := range ports {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return inUse
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if s.probe(ctx, port) {
+				mu.Lock()
+				inUse[port] = true
+				mu.Unlock()
+			}
+		}(port)
+	}
+
+	wg.Wait()
+	return inUse
+}
+
+// FindFree returns up to count ports in [start, end] that are neither
+// reserved nor listening on the host. Candidates are probed in batches so
+// a wide range is not scanned when the first few ports are free.
+func (s *Scanner) FindFree(ctx context.Context, start, end, count int, reserved map[int]bool) ([]int, error) {
+	if start < 1 || end > 65535 || start > end {
+		return nil, fmt.Errorf("invalid port range %d-%d", start, end)
+	}
+
+	batchSize := s.concurrency * 2
+	var free []int
+	port := start
+
+	for port <= end && len(free) < count {
+		var batch []int
+		for ; port <= end && len(batch) < batchSize; port++ {
+			if !reserved[port] {
+				batch = append(batch, port)
+			}
+		}
+
+		inUse := s.InUse(ctx, batch)
+		if err := ctx.Err(); err != nil {
+			return free, err
+		}
+
+		for _, p := range batch {
+			if !inUse[p] {
+				free = append(free, p)
+				if len(free) == count {
+					break
+				}
+			}
+		}
+	}
+
+	return free, nil
+}
+
+// probe reports whether something accepts TCP connections on port.
+func (s *Scanner) probe(ctx context.Context, port int) bool {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := s.dialer(ctx, "tcp", net.JoinHostPort(s.host, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
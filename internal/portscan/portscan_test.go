@@ -0,0 +1,105 @@
+package portscan
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listen opens a TCP listener on a random localhost port.
+func listen(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort returns a localhost port with nothing listening on it.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestScanner_InUse(t *testing.T) {
+	busy := listen(t)
+	free := closedPort(t)
+
+	inUse := New().InUse(context.Background(), []int{busy, free})
+	assert.True(t, inUse[busy])
+	assert.False(t, inUse[free])
+}
+
+func TestScanner_InUse_BoundsConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	s := New(WithConcurrency(3))
+	s.dialer = func(ctx context.Context, network, address string) (net.Conn, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil, &net.OpError{Op: "dial"}
+	}
+
+	ports := make([]int, 20)
+	for i := range ports {
+		ports[i] = 9000 + i
+	}
+	assert.Empty(t, s.InUse(context.Background(), ports))
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(3))
+}
+
+func TestScanner_InUse_Timeout(t *testing.T) {
+	s := New(WithTimeout(10 * time.Millisecond))
+	s.dialer = func(ctx context.Context, network, address string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	assert.Empty(t, s.InUse(context.Background(), []int{1, 2, 3}))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestScanner_FindFree(t *testing.T) {
+	s := New()
+	s.dialer = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address == "127.0.0.1:8081" {
+			c1, c2 := net.Pipe()
+			c2.Close()
+			return c1, nil
+		}
+		return nil, &net.OpError{Op: "dial"}
+	}
+
+	t.Run("skips reserved and listening ports", func(t *testing.T) {
+		free, err := s.FindFree(context.Background(), 8080, 8090, 3, map[int]bool{8080: true, 8082: true})
+		require.NoError(t, err)
+		assert.Equal(t, []int{8083, 8084, 8085}, free)
+	})
+
+	t.Run("returns fewer when range is exhausted", func(t *testing.T) {
+		free, err := s.FindFree(context.Background(), 8080, 8081, 5, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int{8080}, free)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := s.FindFree(context.Background(), 9000, 8000, 1, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,185 @@
+package preflight
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMountProbeTimeout is the default timeout for probing a bind-mount source path.
+// A healthy local or network path answers stat in milliseconds; anything slower is
+// treated as a hung mount.
+const DefaultMountProbeTimeout = 3 * time.Second
+
+var (
+	// ErrStaleHandle indicates a path returned a stale NFS/FUSE file handle.
+	ErrStaleHandle = errors.New("stale file handle")
+
+	// ErrProbeTimeout indicates stat did not return within the probe timeout (hung mount).
+	ErrProbeTimeout = errors.New("mount probe timed out")
+)
+
+// MountCheck represents a bind-mount source path that failed its probe.
+type MountCheck struct {
+	Path  string
+	Error error
+}
+
+// statPath is os.Stat, replaced in tests to simulate a hung mount.
+var statPath = os.Stat
+
+// probe is a stat of one path. A stat blocked on a hung mount can't be
+// cancelled, so each path has at most one probe in flight: later callers
+// wait on the same stat instead of leaking another goroutine per call.
+type probe struct {
+	done chan struct{} // Closed once the stat returns
+	err  error
+}
+
+var (
+	probesMu sync.Mutex
+	probes   = make(map[string]*probe) // Path -> probe in flight
+)
+
+// startProbe returns the probe in flight for path, starting one if there
+// is none.
+func startProbe(path string) *probe {
+	probesMu.Lock()
+	defer probesMu.Unlock()
+
+	if p, ok := probes[path]; ok {
+		return p
+	}
+	p := &probe{done: make(chan struct{})}
+	probes[path] = p
+	go func() {
+		_, p.err = statPath(path)
+		probesMu.Lock()
+		delete(probes, path)
+		probesMu.Unlock()
+		close(p.done)
+	}()
+	return p
+}
+
+// ProbePath stats a path with a timeout and reports stale or hung mounts.
+// Paths that do not exist are not considered failures: compose creates missing
+// bind sources, and a missing path is not a stale handle. While a path's stat
+// hangs, later probes of it time out on the same stat rather than starting
+// another.
+func ProbePath(path string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultMountProbeTimeout
+	}
+
+	p := startProbe(path)
+	select {
+	case <-p.done:
+		err := p.err
+		if err == nil || os.IsNotExist(err) {
+			return nil
+		}
+		// ESTALE is returned by NFS; ENOTCONN is what FUSE (mergerfs, shfs)
+		// returns once the backing process has gone away.
+		if errors.Is(err, syscall.ESTALE) || errors.Is(err, syscall.ENOTCONN) {
+			return fmt.Errorf("%s: %w", path, ErrStaleHandle)
+		}
+		return fmt.Errorf("stat %s: %w", path, err)
+	case <-time.After(timeout):
+		return fmt.Errorf("%s: %w after %s", path, ErrProbeTimeout, timeout)
+	}
+}
+
+// CheckMountPaths probes all paths concurrently and returns the ones that failed.
+// Results are sorted by path for stable output.
+func CheckMountPaths(paths []string, timeout time.Duration) []MountCheck {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed []MountCheck
+	)
+
+	for _, p := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			if err := ProbePath(path, timeout); err != nil {
+				mu.Lock()
+				failed = append(failed, MountCheck{Path: path, Error: err})
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Path < failed[j].Path })
+	return failed
+}
+
+// composeVolumes is the subset of a compose file needed to find bind mounts.
+type composeVolumes struct {
+	Services map[string]struct {
+		Volumes []any `yaml:"volumes"`
+	} `yaml:"services"`
+}
+
+// BindMountSources returns the absolute host paths bind-mounted by services in a compose file.
+// Named volumes and relative paths are skipped. The result is deduplicated and sorted.
+func BindMountSources(composeFile string) ([]string, error) {
+	data, err := os.ReadFile(composeFile)
+	if err != nil {
+		return nil, fmt.Errorf("read compose file: %w", err)
+	}
+
+	var compose composeVolumes
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("parse compose file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, svc := range compose.Services {
+		for _, entry := range svc.Volumes {
+			if source := bindSource(entry); source != "" {
+				seen[filepath.Clean(source)] = true
+			}
+		}
+	}
+
+	sources := make([]string, 0, len(seen))
+	for s := range seen {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
+
+// bindSource extracts the host path from a compose volume entry.
+// Supports short syntax ("/host:/container:ro") and long syntax (type: bind, source: /host).
+func bindSource(entry any) string {
+	switch v := entry.(type) {
+	case string:
+		source, _, found := strings.Cut(v, ":")
+		if !found || !filepath.IsAbs(source) {
+			return ""
+		}
+		return source
+	case map[string]any:
+		if t, _ := v["type"].(string); t != "bind" {
+			return ""
+		}
+		source, _ := v["source"].(string)
+		if !filepath.IsAbs(source) {
+			return ""
+		}
+		return source
+	}
+	return ""
+}
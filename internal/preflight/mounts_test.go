@@ -0,0 +1,100 @@
+package preflight
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbePath(t *testing.T) {
+	t.Run("existing path passes", func(t *testing.T) {
+		assert.NoError(t, ProbePath(t.TempDir(), 0))
+	})
+
+	t.Run("missing path is not a failure", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "does-not-exist")
+		assert.NoError(t, ProbePath(missing, 0))
+	})
+
+	t.Run("hung path keeps one stat in flight", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls atomic.Int32
+		statPath = func(path string) (os.FileInfo, error) {
+			calls.Add(1)
+			<-release
+			return nil, syscall.ESTALE
+		}
+		defer func() { statPath = os.Stat }()
+
+		path := "/mnt/hung"
+		for i := 0; i < 3; i++ {
+			assert.ErrorIs(t, ProbePath(path, 10*time.Millisecond), ErrProbeTimeout)
+		}
+		assert.Equal(t, int32(1), calls.Load(), "timed-out probes should share the hung stat")
+
+		close(release)
+		assert.ErrorIs(t, ProbePath(path, time.Second), ErrStaleHandle)
+	})
+}
+
+func TestCheckMountPaths(t *testing.T) {
+	t.Run("healthy paths return no failures", func(t *testing.T) {
+		dir := t.TempDir()
+		failed := CheckMountPaths([]string{dir, filepath.Join(dir, "missing")}, 0)
+		assert.Empty(t, failed)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		assert.Empty(t, CheckMountPaths(nil, 0))
+	})
+}
+
+func TestBindMountSources(t *testing.T) {
+	t.Run("extracts short and long syntax bind mounts", func(t *testing.T) {
+		dir := t.TempDir()
+		composeFile := filepath.Join(dir, "stack.yml")
+		content := `services:
+  app:
+    image: app:latest
+    volumes:
+      - /mnt/user/appdata/app:/config
+      - /mnt/user/media:/media:ro
+      - app-data:/data
+      - ./relative:/relative
+  other:
+    image: other:latest
+    volumes:
+      - type: bind
+        source: /mnt/user/media
+        target: /media
+      - type: volume
+        source: cache
+        target: /cache
+`
+		require.NoError(t, os.WriteFile(composeFile, []byte(content), 0644))
+
+		sources, err := BindMountSources(composeFile)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/mnt/user/appdata/app", "/mnt/user/media"}, sources)
+	})
+
+	t.Run("missing file returns error", func(t *testing.T) {
+		_, err := BindMountSources(filepath.Join(t.TempDir(), "missing.yml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("compose without volumes", func(t *testing.T) {
+		composeFile := filepath.Join(t.TempDir(), "stack.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("services:\n  app:\n    image: app\n"), 0644))
+
+		sources, err := BindMountSources(composeFile)
+		require.NoError(t, err)
+		assert.Empty(t, sources)
+	})
+}
@@ -0,0 +1,98 @@
+package reconcile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// stackHashes returns the content hash of every staged stack: its compose
+// file together with the other files in its compose directory, such as
+// shared .env files, so a change to either changes the hash.
+func (r *Reconciler) stackHashes() map[string]string {
+	hashes := make(map[string]string)
+	for _, dir := range r.stagedComposeDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		stacks := make(map[string]string)
+		shared := sha256.New()
+		for _, e := range entries { // Sorted by name
+			if !e.Type().IsRegular() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				continue
+			}
+			if stack, ok := strings.CutSuffix(e.Name(), ".yml"); ok {
+				stacks[stack] = manifest.ContentHash(data)
+				continue
+			}
+			fmt.Fprintf(shared, "%s %s\n", e.Name(), manifest.ContentHash(data))
+		}
+
+		sharedHash := hex.EncodeToString(shared.Sum(nil))
+		for stack, hash := range stacks {
+			sum := sha256.Sum256([]byte(hash + " " + sharedHash))
+			hashes[stack] = hex.EncodeToString(sum[:])
+		}
+	}
+	return hashes
+}
+
+// skipAppliedStacks narrows a full deploy (forced, a first clone, or
+// changes that can't be scoped) to the stacks whose staged content differs
+// from what their last deploy applied, per the state file. Stacks without
+// a record, and stacks that build images, are always deployed.
+func (r *Reconciler) skipAppliedStacks() {
+	if r.changes == nil || !r.changes.Full || r.config.StateDir == "" {
+		return
+	}
+	st, err := state.NewStore(r.config.StateDir).Load()
+	if err != nil || len(st.Applied) == 0 {
+		return
+	}
+
+	builds := make(map[string]bool)
+	for _, stack := range r.buildContexts() {
+		builds[stack] = true
+	}
+	for stack, hash := range r.stackHashes() {
+		if a, ok := st.Applied[stack]; ok && a.Hash == hash && !builds[stack] {
+			r.changes.Unchanged = append(r.changes.Unchanged, stack)
+		}
+	}
+	if len(r.changes.Unchanged) > 0 {
+		sort.Strings(r.changes.Unchanged)
+		ui.Info("Stacks unchanged since their last deploy, skipping: %s", strings.Join(r.changes.Unchanged, ", "))
+	}
+}
+
+// recordApplied records the content hash each stack the run deployed now
+// has, so later runs and drift checks know what was applied.
+func (r *Reconciler) recordApplied(st *state.State, at time.Time) {
+	hashes := r.stackHashes()
+	stacks := make([]string, 0, len(hashes))
+	for stack := range hashes {
+		if r.changes.HasStack(stack) {
+			stacks = append(stacks, stack)
+		}
+	}
+	slices.Sort(stacks)
+	for _, stack := range stacks {
+		st.RecordApplied(stack, hashes[stack], r.lastCommit, at)
+	}
+}
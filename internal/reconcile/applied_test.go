@@ -0,0 +1,106 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestReconciler_StackHashes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.StagingDir = t.TempDir()
+	compose := filepath.Join(cfg.StagingDir, "unraid", "compose")
+	require.NoError(t, os.MkdirAll(compose, 0755))
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(compose, name), []byte(content), 0644))
+	}
+	write("core.yml", "services: {}\n")
+	write("apps.yml", "services: {a: {}}\n")
+	r := NewReconciler(cfg)
+
+	before := r.stackHashes()
+	require.Len(t, before, 2)
+	assert.NotEqual(t, before["core"], before["apps"])
+
+	write("apps.yml", "services: {a: {image: x}}\n")
+	after := r.stackHashes()
+	assert.Equal(t, before["core"], after["core"])
+	assert.NotEqual(t, before["apps"], after["apps"])
+
+	write(".env", "TZ=UTC\n")
+	shared := r.stackHashes()
+	assert.NotEqual(t, after["core"], shared["core"], "a shared file changes every stack's hash")
+}
+
+func TestReconciler_SkipAppliedStacks(t *testing.T) {
+	setup := func(t *testing.T) *Reconciler {
+		cfg := DefaultConfig()
+		cfg.StagingDir = t.TempDir()
+		cfg.StateDir = t.TempDir()
+		compose := filepath.Join(cfg.StagingDir, "unraid", "compose")
+		require.NoError(t, os.MkdirAll(compose, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(compose, "core.yml"), []byte("services: {}\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(compose, "apps.yml"), []byte("services: {a: {}}\n"), 0644))
+		r := NewReconciler(cfg)
+		r.lastCommit = "abc1234"
+		return r
+	}
+
+	t.Run("records applied hashes on deploy", func(t *testing.T) {
+		r := setup(t)
+		r.changes = &ChangeSet{Stacks: []string{"core"}}
+		r.recordDeploy(context.Background())
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		require.Contains(t, st.Applied, "core")
+		assert.NotContains(t, st.Applied, "apps")
+		assert.Equal(t, r.stackHashes()["core"], st.Applied["core"].Hash)
+		assert.Equal(t, "abc1234", st.Applied["core"].Commit)
+	})
+
+	t.Run("forced deploy skips unchanged stacks", func(t *testing.T) {
+		r := setup(t)
+		r.changes = &ChangeSet{Stacks: []string{"core"}}
+		r.recordDeploy(context.Background())
+
+		r.changes = FullChangeSet("forced")
+		r.skipAppliedStacks()
+		assert.Equal(t, []string{"core"}, r.changes.Unchanged)
+		assert.False(t, r.changes.HasStack("core"))
+		assert.True(t, r.changes.HasStack("apps"), "stacks without a record deploy")
+	})
+
+	t.Run("changed stacks deploy", func(t *testing.T) {
+		r := setup(t)
+		require.NoError(t, state.NewStore(r.config.StateDir).Update(func(st *state.State) error {
+			st.RecordApplied("core", "stale", "0000000", time.Now())
+			return nil
+		}))
+
+		r.changes = FullChangeSet("no previous commit")
+		r.skipAppliedStacks()
+		assert.Empty(t, r.changes.Unchanged)
+	})
+
+	t.Run("scoped and unscoped deploys are left alone", func(t *testing.T) {
+		r := setup(t)
+		r.changes = &ChangeSet{Stacks: []string{"core", "apps"}}
+		r.recordDeploy(context.Background())
+
+		r.changes = &ChangeSet{Stacks: []string{"core"}}
+		r.skipAppliedStacks()
+		assert.Empty(t, r.changes.Unchanged)
+
+		r.changes = nil
+		r.skipAppliedStacks()
+		assert.Nil(t, r.changes)
+	})
+}
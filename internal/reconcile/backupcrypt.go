@@ -0,0 +1,128 @@
+package reconcile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cameronsjo/bosun/internal/seal"
+)
+
+// BackupArchive is the archive of backed-up configs in a backup directory.
+const BackupArchive = "configs.tar.gz"
+
+// EncryptedBackupArchive is BackupArchive encrypted with age, written
+// instead of it when backup encryption is on.
+const EncryptedBackupArchive = BackupArchive + ".age"
+
+// ageHeader starts every binary age file.
+var ageHeader = []byte("age-encryption.org/v1\n")
+
+// BackupArchivePath returns the archive in a backup directory, encrypted or
+// not, and whether it is encrypted. ok is false when there is neither.
+func BackupArchivePath(backupPath string) (path string, encrypted, ok bool) {
+	plain := filepath.Join(backupPath, BackupArchive)
+	if _, err := os.Stat(plain); err == nil {
+		return plain, false, true
+	}
+	sealed := filepath.Join(backupPath, EncryptedBackupArchive)
+	if _, err := os.Stat(sealed); err == nil {
+		return sealed, true, true
+	}
+	return "", false, false
+}
+
+// OpenBackupArchive opens a backup's tar.gz archive. An encrypted archive
+// is decrypted as it is read, with the age key SOPS uses (see
+// seal.LoadKey).
+func OpenBackupArchive(backupPath string) (io.ReadCloser, error) {
+	path, encrypted, ok := BackupArchivePath(backupPath)
+	if !ok {
+		return nil, fmt.Errorf("backup archive not found in %s: %w", backupPath, os.ErrNotExist)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open backup archive: %w", err)
+	}
+	if !encrypted {
+		return f, nil
+	}
+
+	key, err := seal.LoadKey()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("backup is encrypted; load age key: %w", err)
+	}
+	r, err := key.Decrypt(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("decrypt backup archive: %w", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// encryptBackup replaces the plain archive in a backup directory with one
+// encrypted to key. The plain archive is removed only once the encrypted
+// one is complete.
+func encryptBackup(backupPath string, key *seal.Key) error {
+	plainPath := filepath.Join(backupPath, BackupArchive)
+	in, err := os.Open(plainPath)
+	if err != nil {
+		return fmt.Errorf("open backup archive: %w", err)
+	}
+	defer in.Close()
+
+	tmpFile, err := os.CreateTemp(backupPath, ".configs-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // Cleanup on failure
+
+	w, err := key.Encrypt(tmpFile)
+	if err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("encrypt backup archive: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("encrypt backup archive: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(backupPath, EncryptedBackupArchive)); err != nil {
+		return fmt.Errorf("save encrypted backup archive: %w", err)
+	}
+	if err := os.Remove(plainPath); err != nil {
+		return fmt.Errorf("remove plain backup archive: %w", err)
+	}
+	return nil
+}
+
+// verifyEncryptedBackup checks that an encrypted archive is a non-empty
+// age file. Its contents can't be listed without the key.
+func verifyEncryptedBackup(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open encrypted backup archive: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, len(ageHeader))
+	if _, err := io.ReadFull(f, header); err != nil || !bytes.Equal(header, ageHeader) {
+		return fmt.Errorf("encrypted backup archive is not an age file: %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package reconcile
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/seal"
+)
+
+func TestDeployOps_Backup_Encrypted(t *testing.T) {
+	if _, err := exec.LookPath("tar"); err != nil {
+		t.Skip("tar not installed")
+	}
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	key, err := seal.ParseKey(strings.NewReader(id.String()))
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "authelia")
+	require.NoError(t, os.MkdirAll(srcDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "configuration.yml"), []byte("jwt_secret: hunter2\n"), 0600))
+
+	backupDir := filepath.Join(tmpDir, "backups")
+	deploy := NewDeployOps(false)
+	deploy.BackupKey = key
+	name, err := deploy.Backup(context.Background(), backupDir, []string{srcDir})
+	require.NoError(t, err)
+
+	backupPath := filepath.Join(backupDir, name)
+	assert.NoFileExists(t, filepath.Join(backupPath, BackupArchive))
+	path, encrypted, ok := BackupArchivePath(backupPath)
+	require.True(t, ok)
+	assert.True(t, encrypted)
+	assert.Equal(t, filepath.Join(backupPath, EncryptedBackupArchive), path)
+	assert.NoError(t, deploy.VerifyBackup(backupPath))
+
+	t.Run("opens with the age key", func(t *testing.T) {
+		t.Setenv("SOPS_AGE_KEY", id.String())
+		archive, err := OpenBackupArchive(backupPath)
+		require.NoError(t, err)
+		defer archive.Close()
+
+		gzr, err := gzip.NewReader(archive)
+		require.NoError(t, err)
+		tr := tar.NewReader(gzr)
+		var found bool
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			if strings.HasSuffix(header.Name, "configuration.yml") {
+				content, err := io.ReadAll(tr)
+				require.NoError(t, err)
+				assert.Equal(t, "jwt_secret: hunter2\n", string(content))
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("fails with another key", func(t *testing.T) {
+		other, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		t.Setenv("SOPS_AGE_KEY", other.String())
+		_, err = OpenBackupArchive(backupPath)
+		assert.ErrorContains(t, err, "decrypt backup archive")
+	})
+
+	t.Run("rejects a corrupt archive", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("not age"), 0644))
+		assert.Error(t, deploy.VerifyBackup(backupPath))
+	})
+}
+
+func TestOpenBackupArchive_Plain(t *testing.T) {
+	backupPath := t.TempDir()
+	_, err := OpenBackupArchive(backupPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(filepath.Join(backupPath, BackupArchive), []byte("plain"), 0644))
+	archive, err := OpenBackupArchive(backupPath)
+	require.NoError(t, err)
+	defer archive.Close()
+	content, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", string(content))
+}
@@ -0,0 +1,157 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+// BackupIndexFile records, next to a backup's configs.tar.gz, which appdata
+// paths the backup holds for each stack.
+const BackupIndexFile = "stacks.json"
+
+// CoreBackupStack is the index entry for CoreBackupPaths.
+const CoreBackupStack = "core"
+
+// CoreBackupPaths are the infrastructure configs, relative to the appdata
+// root, that every backup includes whether or not a manifest declares them.
+var CoreBackupPaths = []string{
+	"traefik",
+	"authelia/configuration.yml",
+	"agentgateway/config.yaml",
+	"gatus/config.yaml",
+}
+
+// BackupIndex maps stacks to the appdata paths a backup holds for them.
+type BackupIndex struct {
+	// Appdata is the appdata root the paths are relative to, as it was on
+	// the backed-up host.
+	Appdata string `json:"appdata"`
+
+	// Stacks maps a stack name to its paths, relative to Appdata.
+	Stacks map[string][]string `json:"stacks"`
+}
+
+// NewBackupIndex returns an index of CoreBackupPaths and the paths declared
+// in the rendered compose files in composeDir, under appdata.
+func NewBackupIndex(composeDir, appdata string) (*BackupIndex, error) {
+	stacks, err := LoadBackupPaths(composeDir)
+	if err != nil {
+		return nil, err
+	}
+	stacks[CoreBackupStack] = append(stacks[CoreBackupStack], CoreBackupPaths...)
+	for stack, paths := range stacks {
+		stacks[stack] = dedupe(paths)
+	}
+	return &BackupIndex{Appdata: appdata, Stacks: stacks}, nil
+}
+
+// LoadBackupPaths returns the config paths that services declare, as
+// carried in the x-bosun-backup extension, keyed by stack (the compose
+// file's base name).
+func LoadBackupPaths(composeDir string) (map[string][]string, error) {
+	files, err := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	stacks := make(map[string][]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var compose struct {
+			Services map[string]struct {
+				Backup []string `yaml:"x-bosun-backup"`
+			} `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(file), err)
+		}
+
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		for name, svc := range compose.Services {
+			for _, p := range svc.Backup {
+				if err := manifest.ValidateBackupPath(p); err != nil {
+					return nil, fmt.Errorf("%s/%s: %w", stack, name, err)
+				}
+				stacks[stack] = append(stacks[stack], path.Clean(p))
+			}
+		}
+	}
+	return stacks, nil
+}
+
+// Paths returns every path in the index, joined to the appdata root, sorted
+// and without duplicates.
+func (idx *BackupIndex) Paths() []string {
+	var all []string
+	for _, paths := range idx.Stacks {
+		for _, p := range paths {
+			all = append(all, path.Join(idx.Appdata, p))
+		}
+	}
+	return dedupe(all)
+}
+
+// StackNames returns the stacks in the index, sorted.
+func (idx *BackupIndex) StackNames() []string {
+	names := make([]string, 0, len(idx.Stacks))
+	for name := range idx.Stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ArchivePath returns where the appdata path p is stored in configs.tar.gz.
+// tar strips the leading slash of the absolute paths it is given.
+func (idx *BackupIndex) ArchivePath(p string) string {
+	return strings.TrimPrefix(path.Join(idx.Appdata, p), "/")
+}
+
+// WriteBackupIndex writes idx into the backup directory backupPath.
+func WriteBackupIndex(backupPath string, idx *BackupIndex) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(backupPath, BackupIndexFile), data, 0644)
+}
+
+// ReadBackupIndex reads the index of the backup directory backupPath.
+// Backups taken before indexes existed return an error wrapping
+// os.ErrNotExist.
+func ReadBackupIndex(backupPath string) (*BackupIndex, error) {
+	data, err := os.ReadFile(filepath.Join(backupPath, BackupIndexFile))
+	if err != nil {
+		return nil, err
+	}
+	var idx BackupIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", BackupIndexFile, err)
+	}
+	return &idx, nil
+}
+
+// dedupe returns s sorted, without duplicates.
+func dedupe(s []string) []string {
+	sorted := append([]string(nil), s...)
+	sort.Strings(sorted)
+	out := sorted[:0]
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackupIndex(t *testing.T) {
+	composeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "apps.yml"), []byte(`services:
+  myapp:
+    image: myapp
+    x-bosun-backup: [myapp/config.yaml, shared]
+  worker:
+    image: worker
+    x-bosun-backup: [shared]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "core.yml"), []byte(`services:
+  gatus:
+    image: gatus
+    x-bosun-backup: [gatus/config.yaml]
+`), 0644))
+
+	idx, err := NewBackupIndex(composeDir, "/mnt/appdata")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"myapp/config.yaml", "shared"}, idx.Stacks["apps"])
+	assert.Equal(t, []string{
+		"agentgateway/config.yaml",
+		"authelia/configuration.yml",
+		"gatus/config.yaml",
+		"traefik",
+	}, idx.Stacks["core"], "core paths join the core stack")
+	assert.Equal(t, []string{"apps", "core"}, idx.StackNames())
+	assert.Equal(t, []string{
+		"/mnt/appdata/agentgateway/config.yaml",
+		"/mnt/appdata/authelia/configuration.yml",
+		"/mnt/appdata/gatus/config.yaml",
+		"/mnt/appdata/myapp/config.yaml",
+		"/mnt/appdata/shared",
+		"/mnt/appdata/traefik",
+	}, idx.Paths())
+	assert.Equal(t, "mnt/appdata/shared", idx.ArchivePath("shared"))
+}
+
+func TestNewBackupIndex_RejectsEscapingPaths(t *testing.T) {
+	composeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(composeDir, "apps.yml"), []byte(`services:
+  myapp:
+    x-bosun-backup: [../etc]
+`), 0644))
+
+	_, err := NewBackupIndex(composeDir, "/mnt/appdata")
+	assert.ErrorContains(t, err, "apps/myapp")
+}
+
+func TestBackupIndex_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	idx := &BackupIndex{Appdata: "/mnt/appdata", Stacks: map[string][]string{"apps": {"myapp"}}}
+	require.NoError(t, WriteBackupIndex(dir, idx))
+
+	got, err := ReadBackupIndex(dir)
+	require.NoError(t, err)
+	assert.Equal(t, idx, got)
+
+	_, err = ReadBackupIndex(t.TempDir())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,292 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// ImageBuildTimeout bounds a single image build.
+const ImageBuildTimeout = 30 * time.Minute
+
+// BuildSpec is a compose service whose image is built from source.
+type BuildSpec struct {
+	Service    string
+	Image      string // Image reference the compose file runs
+	Context    string // Absolute path to the build context
+	Dockerfile string
+	Target     string
+	Args       map[string]string
+}
+
+// BuildOptions controls how images are built.
+type BuildOptions struct {
+	// BuildKit builds with docker buildx instead of the classic builder.
+	BuildKit bool
+	// CacheDir keeps a local BuildKit layer cache between builds (implies BuildKit).
+	CacheDir string
+	// Force rebuilds even when an image for the context already exists.
+	Force bool
+}
+
+// FindBuilds returns the services in composeFile that have a build section.
+// Relative contexts are resolved against contextBase, the directory the
+// compose file lives in within the repository.
+func FindBuilds(composeFile, contextBase string) ([]BuildSpec, error) {
+	data, err := os.ReadFile(composeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+			Build any    `yaml:"build"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", composeFile, err)
+	}
+
+	var specs []BuildSpec
+	for name, svc := range compose.Services {
+		if svc.Build == nil {
+			continue
+		}
+		if svc.Image == "" {
+			return nil, fmt.Errorf("service %s: a built service needs an image name to tag", name)
+		}
+
+		spec := BuildSpec{Service: name, Image: svc.Image}
+		switch b := svc.Build.(type) {
+		case string:
+			spec.Context = b
+		case map[string]any:
+			spec.Context, _ = b["context"].(string)
+			spec.Dockerfile, _ = b["dockerfile"].(string)
+			spec.Target, _ = b["target"].(string)
+			spec.Args = buildArgsMap(b["args"])
+		}
+		if spec.Context == "" {
+			spec.Context = "."
+		}
+		if !filepath.IsAbs(spec.Context) {
+			spec.Context = filepath.Join(contextBase, spec.Context)
+		}
+		specs = append(specs, spec)
+	}
+
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Service < specs[j].Service })
+	return specs, nil
+}
+
+// buildArgsMap converts compose build args (a map or a KEY=VALUE list).
+func buildArgsMap(v any) map[string]string {
+	args := make(map[string]string)
+	switch a := v.(type) {
+	case map[string]any:
+		for k, val := range a {
+			args[k] = fmt.Sprint(val)
+		}
+	case []any:
+		for _, item := range a {
+			k, val, _ := strings.Cut(fmt.Sprint(item), "=")
+			args[k] = val
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// ContextTag returns a deterministic tag for a build context: the first 12
+// characters of the git tree hash of the context directory at HEAD. It only
+// changes when a committed file in the context changes.
+func ContextTag(contextDir string) (string, error) {
+	repo, err := git.PlainOpenWithOptions(contextDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("build context %s is not in a git repository: %w", contextDir, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	root, err := filepath.EvalSymlinks(wt.Filesystem.Root())
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.EvalSymlinks(contextDir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("build context %s is outside the repository", contextDir)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("read HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+	if rel != "." {
+		if tree, err = tree.Tree(filepath.ToSlash(rel)); err != nil {
+			return "", fmt.Errorf("build context %s is not committed", rel)
+		}
+	}
+	return tree.Hash.String()[:12], nil
+}
+
+// BuildRef returns the image reference a build is tagged with: the compose
+// image name with the context tag.
+func BuildRef(spec BuildSpec) (string, error) {
+	tag, err := ContextTag(spec.Context)
+	if err != nil {
+		return "", err
+	}
+	return manifest.WithTag(spec.Image, tag)
+}
+
+// BuildImage builds spec's image on host ("" for this machine), tagged with
+// both its context tag and the compose image reference. The build is skipped
+// when an image for the same context already exists, so unchanged contexts
+// never rebuild and their containers are not recreated. Returns the context
+// tag reference and whether a build ran.
+func (d *DeployOps) BuildImage(ctx context.Context, host string, spec BuildSpec, opts BuildOptions) (string, bool, error) {
+	ref, err := BuildRef(spec)
+	if err != nil {
+		return "", false, err
+	}
+	if d.DryRun {
+		return ref, false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ImageBuildTimeout)
+	defer cancel()
+
+	if !opts.Force && runDocker(ctx, host, "image", "inspect", "--format", "{{.Id}}", ref) == nil {
+		// Point the compose reference back at this build, e.g. after a rollback.
+		return ref, false, runDocker(ctx, host, "tag", ref, spec.Image)
+	}
+
+	if err := runDocker(ctx, host, imageBuildArgs(spec, ref, opts)...); err != nil {
+		return "", false, fmt.Errorf("build %s: %w", spec.Service, err)
+	}
+	return ref, true, nil
+}
+
+// imageBuildArgs returns the docker arguments that build spec as ref.
+func imageBuildArgs(spec BuildSpec, ref string, opts BuildOptions) []string {
+	args := []string{"build"}
+	if opts.BuildKit || opts.CacheDir != "" {
+		args = []string{"buildx", "build", "--load"}
+	}
+	if opts.CacheDir != "" {
+		args = append(args,
+			"--cache-from", "type=local,src="+opts.CacheDir,
+			"--cache-to", "type=local,dest="+opts.CacheDir+",mode=max")
+	}
+	args = append(args, "-t", ref, "-t", spec.Image)
+	if spec.Dockerfile != "" {
+		args = append(args, "-f", filepath.Join(spec.Context, spec.Dockerfile))
+	}
+	if spec.Target != "" {
+		args = append(args, "--target", spec.Target)
+	}
+
+	keys := make([]string, 0, len(spec.Args))
+	for k := range spec.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", k+"="+spec.Args[k])
+	}
+	return append(args, spec.Context)
+}
+
+// runDocker runs a docker command locally, or against host's daemon over SSH.
+func runDocker(ctx context.Context, host string, args ...string) error {
+	name := args[0]
+	if host != "" {
+		if err := validateHost(host); err != nil {
+			return fmt.Errorf("invalid SSH host: %w", err)
+		}
+		args = append([]string{"-H", "ssh://" + host}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker %s: %w: %s", name, err, lastLines(output.String(), 20))
+	}
+	return nil
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// buildImages makes the images of every staged compose service with a build
+// section available on host ("" for local), before any files are synced, so
+// a failed build leaves the running deployment untouched.
+func (r *Reconciler) buildImages(ctx context.Context, host string) error {
+	stagingCompose := filepath.Join(r.config.StagingDir, "unraid", "compose")
+	repoCompose := filepath.Join(r.config.RepoDir, r.config.InfraSubDir, "unraid", "compose")
+
+	files, _ := filepath.Glob(filepath.Join(stagingCompose, "*.yml"))
+	var specs []BuildSpec
+	for _, file := range files {
+		found, err := FindBuilds(file, repoCompose)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, found...)
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+
+	ui.Info("  Building images...")
+	opts := BuildOptions{CacheDir: r.config.BuildCacheDir}
+	for _, spec := range specs {
+		ref, built, err := r.deploy.DistributeImage(ctx, host, spec, opts, r.config.ImageDistribution, r.config.Registry)
+		switch {
+		case err != nil:
+			return err
+		case built:
+			ui.Success("    Built %s as %s", spec.Service, ref)
+		default:
+			ui.Info("    %s unchanged (%s)", spec.Service, ref)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBuilds(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := filepath.Join(dir, "apps.yml")
+	require.NoError(t, os.WriteFile(composeFile, []byte(`services:
+  web:
+    image: bosun.local/web
+    build:
+      context: ../../images/web
+      dockerfile: Dockerfile.prod
+      target: runtime
+      args:
+        - VERSION=2
+  worker:
+    image: bosun.local/worker:dev
+    build: /srv/worker
+  redis:
+    image: redis:7
+`), 0644))
+
+	specs, err := FindBuilds(composeFile, "/repo/unraid/compose")
+	require.NoError(t, err)
+	assert.Equal(t, []BuildSpec{
+		{
+			Service:    "web",
+			Image:      "bosun.local/web",
+			Context:    "/repo/images/web",
+			Dockerfile: "Dockerfile.prod",
+			Target:     "runtime",
+			Args:       map[string]string{"VERSION": "2"},
+		},
+		{Service: "worker", Image: "bosun.local/worker:dev", Context: "/srv/worker"},
+	}, specs)
+
+	t.Run("requires an image", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.yml")
+		require.NoError(t, os.WriteFile(bad, []byte("services:\n  web:\n    build: .\n"), 0644))
+		_, err := FindBuilds(bad, dir)
+		assert.ErrorContains(t, err, "needs an image name")
+	})
+}
+
+func TestContextTag(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+
+	commitFiles(t, repo, repoDir, map[string]string{
+		"images/web/Dockerfile": "FROM alpine\n",
+		"unraid/compose/a.yml":  "services: {}\n",
+	})
+	contextDir := filepath.Join(repoDir, "images", "web")
+
+	first, err := ContextTag(contextDir)
+	require.NoError(t, err)
+	assert.Len(t, first, 12)
+
+	commitFiles(t, repo, repoDir, map[string]string{"unraid/compose/a.yml": "services:\n  x: {}\n"})
+	unchanged, err := ContextTag(contextDir)
+	require.NoError(t, err)
+	assert.Equal(t, first, unchanged, "changes outside the context keep the tag")
+
+	commitFiles(t, repo, repoDir, map[string]string{"images/web/Dockerfile": "FROM alpine:3.20\n"})
+	changed, err := ContextTag(contextDir)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+
+	t.Run("uncommitted context", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "images", "new"), 0755))
+		_, err := ContextTag(filepath.Join(repoDir, "images", "new"))
+		assert.ErrorContains(t, err, "not committed")
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		_, err := ContextTag(t.TempDir())
+		assert.ErrorContains(t, err, "not in a git repository")
+	})
+}
+
+func TestImageBuildArgs(t *testing.T) {
+	spec := BuildSpec{
+		Service:    "web",
+		Image:      "bosun.local/web",
+		Context:    "/repo/images/web",
+		Dockerfile: "Dockerfile.prod",
+		Args:       map[string]string{"B": "2", "A": "1"},
+	}
+	ref := "bosun.local/web:3f9c2e1a7b4d"
+
+	assert.Equal(t, []string{
+		"build", "-t", ref, "-t", "bosun.local/web",
+		"-f", "/repo/images/web/Dockerfile.prod",
+		"--build-arg", "A=1", "--build-arg", "B=2",
+		"/repo/images/web",
+	}, imageBuildArgs(spec, ref, BuildOptions{}))
+
+	assert.Equal(t, []string{
+		"buildx", "build", "--load",
+		"--cache-from", "type=local,src=/cache", "--cache-to", "type=local,dest=/cache,mode=max",
+		"-t", ref, "-t", "bosun.local/web", "/repo/images/web",
+	}, imageBuildArgs(BuildSpec{Image: "bosun.local/web", Context: "/repo/images/web"}, ref, BuildOptions{CacheDir: "/cache"}))
+}
@@ -0,0 +1,240 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// ChangeSet is what a range of commits touches, mapped to the artifacts a
+// deploy syncs: stacks (compose files) and appdata configs. A nil ChangeSet
+// deploys everything.
+type ChangeSet struct {
+	// Full means the changes can't be scoped (secrets or an unmapped deploy
+	// path changed), so everything is deployed. Reason says why.
+	Full   bool
+	Reason string
+	// Unchanged are stacks a full deploy skips because their staged content
+	// matches what their last deploy applied (see skipAppliedStacks), sorted.
+	Unchanged []string
+
+	// Stacks whose compose files (or host overrides) changed, sorted.
+	Stacks []string
+	// Configs are appdata components whose files changed, e.g. "traefik", sorted.
+	Configs []string
+}
+
+// FullChangeSet returns a ChangeSet that deploys everything.
+func FullChangeSet(reason string) *ChangeSet {
+	return &ChangeSet{Full: true, Reason: reason}
+}
+
+// MapChanges maps repo-relative changed paths to the artifacts they affect.
+// infraSubDir is the infrastructure directory within the repo and
+// secretsFiles are the repo-relative SOPS files. buildContexts maps the
+// repo-relative build context of each built service to its stack, so a
+// source change redeploys the stack that builds it.
+//
+// Under <infra>/unraid, compose/<stack>.yml[.tmpl] and its host overrides
+// map to the stack, and appdata/<component>/... maps to the component's
+// config. A changed secrets file or any other path under unraid/ can affect
+// every rendered file, so it makes the change set full. Paths outside
+// unraid/ are not deployed and are ignored.
+func MapChanges(files []string, infraSubDir string, secretsFiles []string, buildContexts map[string]string) *ChangeSet {
+	c := &ChangeSet{}
+	stacks := make(map[string]bool)
+	configs := make(map[string]bool)
+
+	infra := path.Clean(filepath.ToSlash(infraSubDir))
+	for _, file := range files {
+		file = path.Clean(filepath.ToSlash(file))
+
+		if slices.ContainsFunc(secretsFiles, func(s string) bool { return path.Clean(filepath.ToSlash(s)) == file }) {
+			return FullChangeSet(fmt.Sprintf("secrets file %s changed", file))
+		}
+
+		for dir, stack := range buildContexts {
+			dir = path.Clean(filepath.ToSlash(dir))
+			if dir == "." || file == dir || strings.HasPrefix(file, dir+"/") {
+				stacks[stack] = true
+			}
+		}
+
+		rel := file
+		if infra != "." {
+			var ok bool
+			if rel, ok = strings.CutPrefix(file, infra+"/"); !ok {
+				continue
+			}
+		}
+
+		rel, ok := strings.CutPrefix(rel, "unraid/")
+		if !ok {
+			continue
+		}
+
+		dir, name, _ := strings.Cut(rel, "/")
+		switch {
+		case dir == "compose" && !strings.Contains(name, "/"):
+			stacks[composeStackName(name)] = true
+		case dir == "appdata" && strings.Contains(name, "/"):
+			component, _, _ := strings.Cut(name, "/")
+			configs[component] = true
+		default:
+			return FullChangeSet(fmt.Sprintf("%s changed", file))
+		}
+	}
+
+	for s := range stacks {
+		c.Stacks = append(c.Stacks, s)
+	}
+	for cfg := range configs {
+		c.Configs = append(c.Configs, cfg)
+	}
+	sort.Strings(c.Stacks)
+	sort.Strings(c.Configs)
+	return c
+}
+
+// composeStackName returns the stack a compose directory file belongs to:
+// core.yml.tmpl and core.nas.yml (a host override) both belong to core.
+func composeStackName(name string) string {
+	name = strings.TrimSuffix(name, ".tmpl")
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if stack, _, ok := cutLast(name, "."); ok {
+		return stack
+	}
+	return name
+}
+
+// HasStack reports whether the stack is deployed.
+func (c *ChangeSet) HasStack(stack string) bool {
+	if c != nil && c.Full {
+		return !slices.Contains(c.Unchanged, stack)
+	}
+	return c == nil || slices.Contains(c.Stacks, stack)
+}
+
+// HasStacks reports whether any stack is deployed.
+func (c *ChangeSet) HasStacks() bool {
+	return c == nil || c.Full || len(c.Stacks) > 0
+}
+
+// HasConfig reports whether the appdata component's config is deployed.
+func (c *ChangeSet) HasConfig(component string) bool {
+	return c == nil || c.Full || slices.Contains(c.Configs, component)
+}
+
+// Empty reports whether nothing needs deploying.
+func (c *ChangeSet) Empty() bool {
+	return c != nil && !c.Full && len(c.Stacks) == 0 && len(c.Configs) == 0
+}
+
+// String describes what is deployed, e.g. "stacks apps, media; configs traefik".
+func (c *ChangeSet) String() string {
+	if c == nil || c.Full {
+		desc := "everything"
+		if c != nil && c.Reason != "" {
+			desc += " (" + c.Reason + ")"
+		}
+		if c != nil && len(c.Unchanged) > 0 {
+			desc += " except unchanged stacks " + strings.Join(c.Unchanged, ", ")
+		}
+		return desc
+	}
+	var parts []string
+	if len(c.Stacks) > 0 {
+		parts = append(parts, "stacks "+strings.Join(c.Stacks, ", "))
+	}
+	if len(c.Configs) > 0 {
+		parts = append(parts, "configs "+strings.Join(c.Configs, ", "))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// changeLister lists the repo paths changed between two commits. GitOps
+// implements it.
+type changeLister interface {
+	ChangedFiles(ctx context.Context, before, after string) ([]string, error)
+}
+
+// planChanges decides what the reconcile deploys from the commits since the
+// last successful deploy (see deployBase), or since before when none is
+// recorded. It runs after rendering, so build contexts come from the staged
+// compose files. Forced runs, first clones, and configs with Selective off
+// deploy everything, as does a commit range that can't be diffed.
+func (r *Reconciler) planChanges(ctx context.Context, before, after string) *ChangeSet {
+	if r.config.Selective && before != "" {
+		before = r.deployBase(before)
+	}
+	switch {
+	case !r.config.Selective:
+		return nil
+	case r.config.Force:
+		return FullChangeSet("forced")
+	case before == "" || before == after:
+		return FullChangeSet("no previous commit")
+	}
+
+	lister, ok := r.git.(changeLister)
+	if !ok {
+		return FullChangeSet("changed files unavailable")
+	}
+	files, err := lister.ChangedFiles(ctx, before, after)
+	if err != nil {
+		ui.Warning("Could not diff %s..%s, deploying everything: %v", before, after, err)
+		return FullChangeSet("diff failed")
+	}
+	return MapChanges(files, r.config.InfraSubDir, r.config.SecretsFiles, r.buildContexts())
+}
+
+// deployBase returns the commit the run's changes are diffed from: the
+// commit of the last successful deploy, so changes a failed run left
+// undeployed go out with the next one, or before when the state records no
+// deploy.
+func (r *Reconciler) deployBase(before string) string {
+	if r.config.StateDir == "" {
+		return before
+	}
+	st, err := state.NewStore(r.config.StateDir).Load()
+	if err != nil {
+		return before
+	}
+	if d, ok := st.LastDeploy(); ok && d.Commit != "" {
+		return d.Commit
+	}
+	return before
+}
+
+// buildContexts maps the repo-relative build context of each staged service
+// with a build section to its stack.
+func (r *Reconciler) buildContexts() map[string]string {
+	repoCompose := filepath.Join(r.config.RepoDir, r.config.InfraSubDir, "unraid", "compose")
+	files, _ := filepath.Glob(filepath.Join(r.config.StagingDir, "unraid", "compose", "*.yml"))
+
+	contexts := make(map[string]string)
+	for _, file := range files {
+		specs, err := FindBuilds(file, repoCompose)
+		if err != nil {
+			continue
+		}
+		for _, spec := range specs {
+			rel, err := filepath.Rel(r.config.RepoDir, spec.Context)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+				continue
+			}
+			contexts[rel] = strings.TrimSuffix(filepath.Base(file), ".yml")
+		}
+	}
+	return contexts
+}
@@ -0,0 +1,199 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestMapChanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []string
+		infra       string
+		wantFull    bool
+		wantStacks  []string
+		wantConfigs []string
+	}{
+		{
+			name:       "compose templates and host overrides map to their stack",
+			files:      []string{"unraid/compose/media.yml.tmpl", "unraid/compose/apps.nas.yml", "unraid/compose/media.yml"},
+			infra:      ".",
+			wantStacks: []string{"apps", "media"},
+		},
+		{
+			name:        "appdata maps to its component",
+			files:       []string{"unraid/appdata/traefik/dynamic.yml", "unraid/appdata/gatus/config.yaml.tmpl"},
+			infra:       ".",
+			wantConfigs: []string{"gatus", "traefik"},
+		},
+		{
+			name:  "paths outside unraid are ignored",
+			files: []string{"README.md", ".github/workflows/ci.yml", "manifests/services/web.yml"},
+			infra: ".",
+		},
+		{
+			name:       "nested infra directory",
+			files:      []string{"infrastructure/unraid/compose/core.yml", "unraid/compose/apps.yml", "dotfiles/zshrc"},
+			infra:      "infrastructure",
+			wantStacks: []string{"core"},
+		},
+		{
+			name:     "secrets file deploys everything",
+			files:    []string{"unraid/compose/apps.yml", "secrets.yaml"},
+			infra:    ".",
+			wantFull: true,
+		},
+		{
+			name:     "unmapped deploy path deploys everything",
+			files:    []string{"unraid/shared/partials.tmpl"},
+			infra:    ".",
+			wantFull: true,
+		},
+		{
+			name:       "build context maps to the stack that builds it",
+			files:      []string{"src/api/main.go"},
+			infra:      ".",
+			wantStacks: []string{"apps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := MapChanges(tt.files, tt.infra, []string{"secrets.yaml"}, map[string]string{"src/api": "apps"})
+			assert.Equal(t, tt.wantFull, c.Full)
+			if tt.wantFull {
+				assert.NotEmpty(t, c.Reason)
+				return
+			}
+			assert.Equal(t, tt.wantStacks, c.Stacks)
+			assert.Equal(t, tt.wantConfigs, c.Configs)
+		})
+	}
+}
+
+func TestChangeSet(t *testing.T) {
+	var all *ChangeSet
+	assert.True(t, all.HasStack("core"))
+	assert.True(t, all.HasConfig("traefik"))
+	assert.False(t, all.Empty())
+	assert.Equal(t, "everything", all.String())
+
+	c := &ChangeSet{Stacks: []string{"apps"}, Configs: []string{"traefik"}}
+	assert.True(t, c.HasStack("apps"))
+	assert.False(t, c.HasStack("core"))
+	assert.True(t, c.HasConfig("traefik"))
+	assert.False(t, c.HasConfig("gatus"))
+	assert.Equal(t, "stacks apps; configs traefik", c.String())
+
+	assert.True(t, (&ChangeSet{}).Empty())
+	assert.False(t, (&ChangeSet{}).HasStacks())
+	assert.True(t, FullChangeSet("forced").HasStack("core"))
+	assert.Equal(t, "everything (forced)", FullChangeSet("forced").String())
+
+	skip := &ChangeSet{Full: true, Reason: "forced", Unchanged: []string{"core"}}
+	assert.False(t, skip.HasStack("core"))
+	assert.True(t, skip.HasStack("apps"))
+	assert.True(t, skip.HasConfig("traefik"))
+	assert.Equal(t, "everything (forced) except unchanged stacks core", skip.String())
+}
+
+// changedFilesGit lists fixed changed files.
+type changedFilesGit struct {
+	GitOperations
+	files []string
+	err   error
+}
+
+func (g *changedFilesGit) ChangedFiles(ctx context.Context, before, after string) ([]string, error) {
+	return g.files, g.err
+}
+
+func TestReconciler_PlanChanges(t *testing.T) {
+	ctx := context.Background()
+	plan := func(cfg *Config, git GitOperations, before, after string) *ChangeSet {
+		cfg.StagingDir = t.TempDir()
+		cfg.RepoDir = t.TempDir()
+		return NewReconciler(cfg, WithGitOperations(git)).planChanges(ctx, before, after)
+	}
+	git := &changedFilesGit{files: []string{"unraid/compose/apps.yml"}}
+
+	c := plan(DefaultConfig(), git, "aaa", "bbb")
+	require.NotNil(t, c)
+	assert.Equal(t, []string{"apps"}, c.Stacks)
+
+	t.Run("selective off deploys everything", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Selective = false
+		assert.Nil(t, plan(cfg, git, "aaa", "bbb"))
+	})
+
+	t.Run("force deploys everything", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Force = true
+		assert.True(t, plan(cfg, git, "aaa", "bbb").Full)
+	})
+
+	t.Run("fresh clone deploys everything", func(t *testing.T) {
+		assert.True(t, plan(DefaultConfig(), git, "", "bbb").Full)
+	})
+
+	t.Run("diff failure deploys everything", func(t *testing.T) {
+		failing := &changedFilesGit{err: errors.New("bad object aaa")}
+		assert.True(t, plan(DefaultConfig(), failing, "aaa", "bbb").Full)
+	})
+
+	t.Run("workspace projects forward changed files", func(t *testing.T) {
+		synced := &syncedGit{GitOperations: git, changed: true, before: "aaa", after: "bbb"}
+		assert.Equal(t, []string{"apps"}, plan(DefaultConfig(), synced, "aaa", "bbb").Stacks)
+	})
+}
+
+// rangeGit lists the changed files of each commit range, keyed before..after.
+type rangeGit struct {
+	GitOperations
+	ranges map[string][]string
+}
+
+func (g *rangeGit) ChangedFiles(ctx context.Context, before, after string) ([]string, error) {
+	files, ok := g.ranges[before+".."+after]
+	if !ok {
+		return nil, fmt.Errorf("unknown range %s..%s", before, after)
+	}
+	return files, nil
+}
+
+func TestReconciler_PlanChangesAfterFailedRun(t *testing.T) {
+	// A run of aaa..bbb failed, so the last successful deploy is still aaa
+	// when ccc arrives: bbb's stack must go out with ccc's.
+	git := &rangeGit{ranges: map[string][]string{
+		"aaa..ccc": {"unraid/compose/apps.yml", "unraid/compose/media.yml"},
+		"bbb..ccc": {"unraid/compose/media.yml"},
+	}}
+	cfg := DefaultConfig()
+	cfg.StagingDir = t.TempDir()
+	cfg.RepoDir = t.TempDir()
+	cfg.StateDir = t.TempDir()
+	require.NoError(t, state.NewStore(cfg.StateDir).Update(func(st *state.State) error {
+		st.RecordDeploy(state.Deploy{At: time.Now(), Commit: "aaa", Stacks: []string{"apps", "media"}})
+		return nil
+	}))
+
+	c := NewReconciler(cfg, WithGitOperations(git)).planChanges(context.Background(), "bbb", "ccc")
+	require.NotNil(t, c)
+	assert.False(t, c.Full)
+	assert.Equal(t, []string{"apps", "media"}, c.Stacks)
+
+	t.Run("without a recorded deploy the pulled range is used", func(t *testing.T) {
+		cfg.StateDir = t.TempDir()
+		c := NewReconciler(cfg, WithGitOperations(git)).planChanges(context.Background(), "bbb", "ccc")
+		assert.Equal(t, []string{"media"}, c.Stacks)
+	})
+}
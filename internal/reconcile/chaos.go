@@ -0,0 +1,123 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chaos fault points.
+const (
+	// ChaosComposeUp fails docker compose up before it runs.
+	ChaosComposeUp = "compose-up"
+	// ChaosHealthGate fails the health gate after a successful compose up,
+	// which triggers rollback.
+	ChaosHealthGate = "health-gate"
+)
+
+// ChaosPoints lists the fault points chaos mode can inject into.
+var ChaosPoints = []string{ChaosComposeUp, ChaosHealthGate}
+
+// ErrChaos marks a failure injected by chaos mode.
+var ErrChaos = errors.New("chaos: injected failure")
+
+// Chaos deliberately fails deploy steps at configured probabilities, so
+// rollback, alerting, and failure history get exercised in staging rather
+// than only during real outages. A nil *Chaos injects nothing.
+type Chaos struct {
+	// Rates maps a fault point to its failure probability (0 to 1).
+	Rates map[string]float64
+
+	// roll returns a number in [0, 1); defaults to math/rand.
+	roll func() float64
+}
+
+// ParseChaos parses a chaos spec: a single probability for every fault point
+// ("0.2"), or a comma list of point=probability ("compose-up=0.2,health-gate=0.1").
+func ParseChaos(spec string) (*Chaos, error) {
+	c := &Chaos{Rates: make(map[string]float64)}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		point, value, hasPoint := strings.Cut(part, "=")
+		if !hasPoint {
+			point, value = "", part
+		}
+		point = strings.TrimSpace(point)
+		if hasPoint && !isChaosPoint(point) {
+			return nil, fmt.Errorf("unknown chaos point %q (use one of: %s)", point, strings.Join(ChaosPoints, ", "))
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid chaos probability %q: must be between 0 and 1", value)
+		}
+
+		if !hasPoint {
+			for _, p := range ChaosPoints {
+				c.Rates[p] = rate
+			}
+			continue
+		}
+		c.Rates[point] = rate
+	}
+
+	if len(c.Rates) == 0 {
+		return nil, fmt.Errorf("empty chaos spec")
+	}
+	return c, nil
+}
+
+func isChaosPoint(point string) bool {
+	for _, p := range ChaosPoints {
+		if p == point {
+			return true
+		}
+	}
+	return false
+}
+
+// Inject returns an error wrapping ErrChaos with the configured probability
+// for point, or nil.
+func (c *Chaos) Inject(point string) error {
+	if c == nil {
+		return nil
+	}
+	rate := c.Rates[point]
+	if rate <= 0 {
+		return nil
+	}
+
+	roll := c.roll
+	if roll == nil {
+		roll = rand.Float64
+	}
+	if roll() >= rate {
+		return nil
+	}
+	return fmt.Errorf("%w at %s", ErrChaos, point)
+}
+
+// String formats the rates as a chaos spec.
+func (c *Chaos) String() string {
+	if c == nil {
+		return ""
+	}
+	points := make([]string, 0, len(c.Rates))
+	for p := range c.Rates {
+		points = append(points, p)
+	}
+	sort.Strings(points)
+
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = p + "=" + strconv.FormatFloat(c.Rates[p], 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,93 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChaos(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]float64
+		wantErr string
+	}{
+		{name: "single rate applies to every point", spec: "0.2", want: map[string]float64{ChaosComposeUp: 0.2, ChaosHealthGate: 0.2}},
+		{name: "per point", spec: "health-gate=0.5", want: map[string]float64{ChaosHealthGate: 0.5}},
+		{name: "override after default", spec: "0.1, compose-up=1", want: map[string]float64{ChaosComposeUp: 1, ChaosHealthGate: 0.1}},
+		{name: "unknown point", spec: "network=0.5", wantErr: "unknown chaos point"},
+		{name: "out of range", spec: "1.5", wantErr: "must be between 0 and 1"},
+		{name: "not a number", spec: "compose-up=often", wantErr: "must be between 0 and 1"},
+		{name: "empty", spec: " , ", wantErr: "empty chaos spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chaos, err := ParseChaos(tt.spec)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, chaos.Rates)
+		})
+	}
+}
+
+func TestChaos_Inject(t *testing.T) {
+	chaos := &Chaos{
+		Rates: map[string]float64{ChaosComposeUp: 0.3},
+		roll:  func() float64 { return 0.25 },
+	}
+
+	err := chaos.Inject(ChaosComposeUp)
+	assert.ErrorIs(t, err, ErrChaos)
+	assert.ErrorContains(t, err, ChaosComposeUp)
+
+	assert.NoError(t, chaos.Inject(ChaosHealthGate), "points without a rate never fail")
+
+	chaos.roll = func() float64 { return 0.3 }
+	assert.NoError(t, chaos.Inject(ChaosComposeUp), "rolls at or above the rate pass")
+
+	var none *Chaos
+	assert.NoError(t, none.Inject(ChaosComposeUp))
+}
+
+func TestChaos_String(t *testing.T) {
+	chaos, err := ParseChaos("compose-up=0.25,health-gate=1")
+	require.NoError(t, err)
+	assert.Equal(t, "compose-up=0.25,health-gate=1", chaos.String())
+}
+
+func TestDeployOps_ComposeUpWithRollback_Chaos(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("injected compose up failure goes through rollback handling", func(t *testing.T) {
+		deploy := NewDeployOps(false)
+		deploy.Chaos = &Chaos{Rates: map[string]float64{ChaosComposeUp: 1}}
+
+		err := deploy.ComposeUpWithRollback(ctx, "/any/compose.yml", "")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrChaos))
+		assert.Contains(t, err.Error(), "no backup available for rollback")
+	})
+
+	t.Run("dry run never injects", func(t *testing.T) {
+		deploy := NewDeployOps(true)
+		deploy.Chaos = &Chaos{Rates: map[string]float64{ChaosComposeUp: 1, ChaosHealthGate: 1}}
+
+		assert.NoError(t, deploy.ComposeUpWithRollback(ctx, "/any/compose.yml", ""))
+	})
+}
+
+func TestNewReconciler_Chaos(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Chaos = &Chaos{Rates: map[string]float64{ChaosHealthGate: 0.5}}
+
+	r := NewReconciler(cfg, WithDeployOps(NewDeployOps(false)))
+	assert.Same(t, cfg.Chaos, r.deploy.Chaos)
+}
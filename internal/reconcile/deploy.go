@@ -3,16 +3,20 @@ package reconcile
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/cameronsjo/bosun/internal/docker"
 	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/cameronsjo/bosun/internal/seal"
 )
 
 // ErrRollbackSucceeded indicates deployment failed but rollback succeeded.
@@ -39,6 +43,23 @@ const (
 type DeployOps struct {
 	// DryRun if true, only shows what would be done without making changes.
 	DryRun bool
+	// Chaos, when set, injects failures into compose up and the health gate.
+	Chaos *Chaos
+	// DockerHost, when set, is the remote Docker daemon (ssh://user@host or
+	// tcp://host:2376) that container signals and daemon checks go through
+	// via the Docker API, instead of docker commands run over SSH.
+	DockerHost string
+	// BackupKey, when set, encrypts backup archives: Backup and
+	// BackupRemote write EncryptedBackupArchive instead of BackupArchive.
+	BackupKey *seal.Key
+	// Profiles are the compose profiles compose up enables.
+	Profiles []string
+
+	// openDocker connects to DockerHost; nil uses docker.NewRemoteClient.
+	openDocker func(host string) (remoteDocker, error)
+
+	// probe makes one readiness probe attempt; nil uses probeReadiness.
+	probe func(ctx context.Context, c ReadinessCheck) error
 }
 
 // NewDeployOps creates a new DeployOps instance.
@@ -164,9 +185,16 @@ func parseSSHError(err error, stderr, host string) error {
 	}
 }
 
-// VerifyBackup checks that a backup archive is valid and non-empty.
+// VerifyBackup checks that a backup archive is valid and non-empty. An
+// encrypted archive is checked to be an age file.
 func (d *DeployOps) VerifyBackup(backupPath string) error {
-	tarFile := filepath.Join(backupPath, "configs.tar.gz")
+	tarFile := filepath.Join(backupPath, BackupArchive)
+	if _, err := os.Stat(tarFile); os.IsNotExist(err) {
+		sealed := filepath.Join(backupPath, EncryptedBackupArchive)
+		if _, err := os.Stat(sealed); err == nil {
+			return verifyEncryptedBackup(sealed)
+		}
+	}
 
 	// Check file exists
 	info, err := os.Stat(tarFile)
@@ -202,7 +230,7 @@ func (d *DeployOps) VerifyBackup(backupPath string) error {
 
 // Backup creates a timestamped tar.gz backup of the specified paths.
 func (d *DeployOps) Backup(ctx context.Context, backupDir string, paths []string) (string, error) {
-	timestamp := time.Now().Format("20060102-150405")
+	timestamp := time.Now().UTC().Format("20060102-150405")
 	backupName := fmt.Sprintf("backup-%s", timestamp)
 	backupPath := filepath.Join(backupDir, backupName)
 
@@ -210,7 +238,7 @@ func (d *DeployOps) Backup(ctx context.Context, backupDir string, paths []string
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	tarFile := filepath.Join(backupPath, "configs.tar.gz")
+	tarFile := filepath.Join(backupPath, BackupArchive)
 
 	// Filter to only existing paths.
 	var existingPaths []string
@@ -237,6 +265,13 @@ func (d *DeployOps) Backup(ctx context.Context, backupDir string, paths []string
 		return "", fmt.Errorf("backup verification failed: %w", err)
 	}
 
+	if d.BackupKey != nil {
+		if err := encryptBackup(backupPath, d.BackupKey); err != nil {
+			os.RemoveAll(backupPath)
+			return "", fmt.Errorf("backup encryption failed: %w", err)
+		}
+	}
+
 	return backupName, nil
 }
 
@@ -247,7 +282,7 @@ func (d *DeployOps) BackupRemote(ctx context.Context, host, backupDir string, re
 		return "", fmt.Errorf("invalid SSH host: %w", err)
 	}
 
-	timestamp := time.Now().Format("20060102-150405")
+	timestamp := time.Now().UTC().Format("20060102-150405")
 	backupName := fmt.Sprintf("backup-%s", timestamp)
 	backupPath := filepath.Join(backupDir, backupName)
 
@@ -255,10 +290,14 @@ func (d *DeployOps) BackupRemote(ctx context.Context, host, backupDir string, re
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	tarFile := filepath.Join(backupPath, "configs.tar.gz")
+	tarFile := filepath.Join(backupPath, BackupArchive)
 
 	// Build remote tar command.
-	tarArgs := strings.Join(remotePaths, " ")
+	quoted := make([]string, len(remotePaths))
+	for i, p := range remotePaths {
+		quoted[i] = shellQuote(p)
+	}
+	tarArgs := strings.Join(quoted, " ")
 	sshCmd := fmt.Sprintf("tar -czf - %s 2>/dev/null", tarArgs)
 
 	outFile, err := os.Create(tarFile)
@@ -292,6 +331,13 @@ func (d *DeployOps) BackupRemote(ctx context.Context, host, backupDir string, re
 		return "", fmt.Errorf("backup verification failed: %w", err)
 	}
 
+	if d.BackupKey != nil {
+		if err := encryptBackup(backupPath, d.BackupKey); err != nil {
+			os.RemoveAll(backupPath)
+			return "", fmt.Errorf("backup encryption failed: %w", err)
+		}
+	}
+
 	return backupName, nil
 }
 
@@ -596,13 +642,17 @@ func (d *DeployOps) EnsureRemoteDir(ctx context.Context, host, dir string) error
 	})
 }
 
-// ComposeUp runs docker compose up for the specified compose file.
-// Uses ComposeUpTimeout if the parent context has no deadline.
+// ComposeUp runs docker compose up for the specified compose file, as its
+// own compose project (see ComposeProjectName) so --remove-orphans only
+// touches that stack. Uses ComposeUpTimeout if the parent context has no deadline.
 // Returns an error if compose up fails (caller should handle rollback).
 func (d *DeployOps) ComposeUp(ctx context.Context, composeFile string) error {
 	if d.DryRun {
 		return nil
 	}
+	if err := d.Chaos.Inject(ChaosComposeUp); err != nil {
+		return err
+	}
 
 	// Apply timeout if context doesn't have one
 	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
@@ -611,7 +661,17 @@ func (d *DeployOps) ComposeUp(ctx context.Context, composeFile string) error {
 		defer cancel()
 	}
 
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", composeFile, "up", "-d", "--remove-orphans", "--wait")
+	// With BOSUN_COMPOSE_NATIVE=true, compose runs in process instead.
+	if compose, err := docker.NewComposeClient(composeFile); err == nil && compose.Native() {
+		err := compose.WithProject(ComposeProjectName(composeFile)).WithProfiles(d.Profiles...).Deploy(ctx)
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("docker compose up timed out after %v", ComposeUpTimeout)
+		}
+		return err
+	}
+
+	args := append([]string{"compose", "-p", ComposeProjectName(composeFile), "-f", composeFile}, docker.ProfileArgs(d.Profiles)...)
+	cmd := exec.CommandContext(ctx, "docker", append(args, "up", "-d", "--remove-orphans", "--wait")...)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
@@ -630,9 +690,27 @@ func (d *DeployOps) ComposeUp(ctx context.Context, composeFile string) error {
 //   - nil on success
 //   - ErrRollbackSucceeded wrapped with deployment error if rollback succeeded
 //   - ErrRollbackFailed wrapped with both errors if rollback also failed
+//   - ErrDockerUnavailable if the Docker daemon went away and did not come back
+//     (no rollback is attempted)
 //   - Original error if no backup available
 func (d *DeployOps) ComposeUpWithRollback(ctx context.Context, composeFile, backupPath string) error {
 	deployErr := d.ComposeUp(ctx, composeFile)
+	// A Docker restart is not a bad config: wait it out and retry instead of
+	// rolling back a healthy state.
+	deployErr = resumeAfterDockerRestart(ctx, deployErr, "local host", pingDocker, func() error {
+		return d.ComposeUp(ctx, composeFile)
+	})
+	if errors.Is(deployErr, ErrDockerUnavailable) {
+		return deployErr
+	}
+	if deployErr == nil && !d.DryRun {
+		// compose up --wait is the health gate, and it passes only once the
+		// readiness probes do; chaos can still fail it.
+		deployErr = d.WaitForReadiness(ctx, composeFile)
+		if deployErr == nil {
+			deployErr = d.Chaos.Inject(ChaosHealthGate)
+		}
+	}
 	if deployErr == nil {
 		return nil
 	}
@@ -652,7 +730,8 @@ func (d *DeployOps) ComposeUpWithRollback(ctx context.Context, composeFile, back
 	rollbackCtx, cancel := context.WithTimeout(context.Background(), ComposeUpTimeout)
 	defer cancel()
 
-	rollbackCmd := exec.CommandContext(rollbackCtx, "docker", "compose", "-f", backupComposeFile, "up", "-d", "--remove-orphans")
+	rollbackArgs := append([]string{"compose", "-p", ComposeProjectName(composeFile), "-f", backupComposeFile}, docker.ProfileArgs(d.Profiles)...)
+	rollbackCmd := exec.CommandContext(rollbackCtx, "docker", append(rollbackArgs, "up", "-d", "--remove-orphans")...)
 	var rollbackStderr bytes.Buffer
 	rollbackCmd.Stderr = &rollbackStderr
 
@@ -665,24 +744,150 @@ func (d *DeployOps) ComposeUpWithRollback(ctx context.Context, composeFile, back
 	return fmt.Errorf("%w: %v", ErrRollbackSucceeded, deployErr)
 }
 
-// VerifyContainerHealth checks if containers from a compose file are healthy.
-func (d *DeployOps) VerifyContainerHealth(ctx context.Context, composeFile string) error {
-	if d.DryRun {
-		return nil
+// Health polling for compose services.
+const (
+	HealthWaitTimeout  = 2 * time.Minute
+	HealthPollInterval = 2 * time.Second
+)
+
+// ServiceHealth is the state of one compose service container, as reported
+// by docker compose ps.
+type ServiceHealth struct {
+	Service  string `json:"Service"`
+	Name     string `json:"Name"`
+	State    string `json:"State"`  // running, exited, restarting, created, ...
+	Health   string `json:"Health"` // healthy, unhealthy, starting, or empty without a healthcheck
+	ExitCode int    `json:"ExitCode"`
+}
+
+// Healthy reports whether the container is running and passing its
+// healthcheck (if it has one), or is a one-shot container that exited 0.
+func (s ServiceHealth) Healthy() bool {
+	switch s.State {
+	case "running":
+		return s.Health == "" || s.Health == "healthy"
+	case "exited":
+		return s.ExitCode == 0
 	}
+	return false
+}
+
+// Starting reports whether the container has not settled yet.
+func (s ServiceHealth) Starting() bool {
+	return s.State == "created" || (s.State == "running" && s.Health == "starting")
+}
 
-	// Use docker compose ps to check container status
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", composeFile, "ps", "--format", "json")
+// Status describes the container state for display, e.g. "running (healthy)"
+// or "exited (1)".
+func (s ServiceHealth) Status() string {
+	switch {
+	case s.State == "exited":
+		return fmt.Sprintf("exited (%d)", s.ExitCode)
+	case s.Health != "":
+		return fmt.Sprintf("%s (%s)", s.State, s.Health)
+	}
+	return s.State
+}
+
+// ComposeHealth returns the state of every container in a compose file's
+// project, including stopped ones.
+func (d *DeployOps) ComposeHealth(ctx context.Context, composeFile string) ([]ServiceHealth, error) {
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-p", ComposeProjectName(composeFile), "-f", composeFile, "ps", "-a", "--format", "json")
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to check container status: %w: %s", err, stderr.String())
+		return nil, fmt.Errorf("failed to check container status: %w: %s", err, stderr.String())
+	}
+	return parseComposePS(stdout.Bytes())
+}
+
+// parseComposePS parses docker compose ps --format json output, which is a
+// JSON array in compose < 2.21 and one object per line since.
+func parseComposePS(data []byte) ([]ServiceHealth, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var services []ServiceHealth
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &services); err != nil {
+			return nil, fmt.Errorf("parse compose ps output: %w", err)
+		}
+	} else {
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) == 0 {
+				continue
+			}
+			var s ServiceHealth
+			if err := json.Unmarshal(line, &s); err != nil {
+				return nil, fmt.Errorf("parse compose ps output: %w", err)
+			}
+			services = append(services, s)
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Service != services[j].Service {
+			return services[i].Service < services[j].Service
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// WaitForHealth polls a compose project until no container is still
+// starting or timeout passes, and returns the last states seen. Callers
+// decide what to do with unhealthy services.
+func (d *DeployOps) WaitForHealth(ctx context.Context, composeFile string, timeout time.Duration) ([]ServiceHealth, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		services, err := d.ComposeHealth(ctx, composeFile)
+		if err != nil {
+			return nil, err
+		}
+		settled := true
+		for _, s := range services {
+			if s.Starting() {
+				settled = false
+				break
+			}
+		}
+		if settled || time.Now().After(deadline) {
+			return services, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return services, nil
+		case <-time.After(HealthPollInterval):
+		}
 	}
+}
 
-	// For now, just verify the command succeeded
-	// A more complete implementation would parse the JSON and check health status
+// VerifyContainerHealth checks if containers from a compose file are healthy.
+// Returns an error naming the services that are not.
+func (d *DeployOps) VerifyContainerHealth(ctx context.Context, composeFile string) error {
+	if d.DryRun {
+		return nil
+	}
+
+	services, err := d.ComposeHealth(ctx, composeFile)
+	if err != nil {
+		return err
+	}
+
+	var unhealthy []string
+	for _, s := range services {
+		if !s.Healthy() {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", s.Service, s.Status()))
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("%d unhealthy service(s): %s", len(unhealthy), strings.Join(unhealthy, ", "))
+	}
 	return nil
 }
 
@@ -696,19 +901,68 @@ func (d *DeployOps) ComposeUpRemote(ctx context.Context, host, composeDir string
 	if d.DryRun {
 		return nil
 	}
+	if err := d.Chaos.Inject(ChaosComposeUp); err != nil {
+		return err
+	}
 
-	sshCmd := fmt.Sprintf("cd %s && docker compose up -d --remove-orphans", composeDir)
+	sshCmd := fmt.Sprintf("cd %s && docker compose%s up -d --remove-orphans", composeDir, d.remoteProfileArgs())
 
-	return retryWithBackoff(ctx, DefaultMaxRetries, func() error {
-		cmd := exec.CommandContext(ctx, "ssh", host, sshCmd)
-		var stderr bytes.Buffer
-		cmd.Stderr = &stderr
+	composeUp := func() error {
+		return retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+			cmd := exec.CommandContext(ctx, "ssh", host, sshCmd)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
 
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("remote docker compose up failed: %w: %s", err, stderr.String())
-		}
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("remote docker compose up failed: %w: %s", err, stderr.String())
+			}
+			return nil
+		})
+	}
+	return resumeAfterDockerRestart(ctx, composeUp(), host, d.pingDockerRemote(host), composeUp)
+}
+
+// ComposeUpRemoteStack runs docker compose up for one stack's compose file
+// on a remote host via SSH, as its own compose project (see ComposeUp).
+// Retries on transient SSH errors with exponential backoff.
+func (d *DeployOps) ComposeUpRemoteStack(ctx context.Context, host, composeFile string) error {
+	if err := validateHost(host); err != nil {
+		return fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	if d.DryRun {
 		return nil
-	})
+	}
+	if err := d.Chaos.Inject(ChaosComposeUp); err != nil {
+		return err
+	}
+
+	sshCmd := fmt.Sprintf("docker compose -p %s -f %s%s up -d --remove-orphans --wait",
+		shellQuote(ComposeProjectName(composeFile)), shellQuote(composeFile), d.remoteProfileArgs())
+
+	composeUp := func() error {
+		return retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+			cmd := exec.CommandContext(ctx, "ssh", host, sshCmd)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("remote docker compose up failed: %w: %s", err, stderr.String())
+			}
+			return nil
+		})
+	}
+	return resumeAfterDockerRestart(ctx, composeUp(), host, d.pingDockerRemote(host), composeUp)
+}
+
+// remoteProfileArgs returns the --profile flags for a compose command run
+// over SSH, quoted and with a leading space, or "" without profiles.
+func (d *DeployOps) remoteProfileArgs() string {
+	var b strings.Builder
+	for _, p := range d.Profiles {
+		b.WriteString(" --profile " + shellQuote(p))
+	}
+	return b.String()
 }
 
 // SignalContainer sends a signal to a Docker container.
@@ -734,7 +988,8 @@ func (d *DeployOps) SignalContainer(ctx context.Context, containerName, signal s
 	return nil
 }
 
-// SignalContainerRemote sends a signal to a Docker container on a remote host.
+// SignalContainerRemote sends a signal to a Docker container on a remote host,
+// through the Docker API when DockerHost is set, else docker kill over SSH.
 // Retries on transient SSH errors with exponential backoff.
 func (d *DeployOps) SignalContainerRemote(ctx context.Context, host, containerName, signal string) error {
 	if err := validateHost(host); err != nil {
@@ -751,6 +1006,10 @@ func (d *DeployOps) SignalContainerRemote(ctx context.Context, host, containerNa
 		return nil
 	}
 
+	if d.DockerHost != "" {
+		return d.signalContainerAPI(ctx, containerName, signal)
+	}
+
 	sshCmd := fmt.Sprintf("docker kill --signal=%s %s 2>/dev/null", signal, containerName)
 
 	return retryWithBackoff(ctx, DefaultMaxRetries, func() error {
@@ -764,3 +1023,123 @@ func (d *DeployOps) SignalContainerRemote(ctx context.Context, host, containerNa
 		return nil
 	})
 }
+
+// StatRemote records the attributes of files matching rules under root on a remote host.
+// Missing files are skipped.
+func (d *DeployOps) StatRemote(ctx context.Context, host, root string, rules []PermissionRule) (map[string]FileAttrs, error) {
+	if err := validateHost(host); err != nil {
+		return nil, fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	patterns := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		patterns = append(patterns, shellGlob(rule.Pattern))
+	}
+
+	// Unmatched globs stay literal and stat skips them; ignore its exit status.
+	sshCmd := fmt.Sprintf("cd %s && stat -c '%%a %%u %%g %%n' -- %s 2>/dev/null; true", shellQuote(root), strings.Join(patterns, " "))
+
+	var output []byte
+	err := retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+		cmd := exec.CommandContext(ctx, "ssh", host, sshCmd)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("remote stat failed: %w: %s", err, stderr.String())
+		}
+		output = out
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStatOutput(string(output)), nil
+}
+
+// parseStatOutput parses "stat -c '%a %u %g %n'" lines into attributes keyed by name.
+func parseStatOutput(output string) map[string]FileAttrs {
+	attrs := make(map[string]FileAttrs)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		mode, err1 := strconv.ParseUint(fields[0], 8, 32)
+		uid, err2 := strconv.Atoi(fields[1])
+		gid, err3 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		attrs[fields[3]] = FileAttrs{Mode: os.FileMode(mode), UID: uid, GID: gid}
+	}
+	return attrs
+}
+
+// RepairPermissionsRemote restores mode and ownership of files under root on a remote host.
+// Issues are updated in place; those that could not be repaired stay
+// unrepaired, and their errors are returned joined.
+func (d *DeployOps) RepairPermissionsRemote(ctx context.Context, host, root string, issues []PermissionIssue) error {
+	if err := validateHost(host); err != nil {
+		return fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	var errs []error
+	for i := range issues {
+		issue := &issues[i]
+		path := shellQuote(filepath.Join(root, issue.Path))
+
+		var cmds []string
+		if issue.ModeChanged {
+			cmds = append(cmds, fmt.Sprintf("chmod %04o -- %s", issue.Want.Mode, path))
+		}
+		if issue.OwnerChanged {
+			cmds = append(cmds, fmt.Sprintf("chown %d:%d -- %s", issue.Want.UID, issue.Want.GID, path))
+		}
+
+		cmd := exec.CommandContext(ctx, "ssh", host, strings.Join(cmds, " && "))
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Errorf("repair %s: %w: %s", issue.Path, err, strings.TrimSpace(stderr.String())))
+			continue
+		}
+		issue.Repaired = true
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyOwnershipRemote applies ownership rules to paths under root on a remote host.
+// Rules whose path does not exist are skipped. File modes are capped by
+// perms (see OwnershipRule.FileMode).
+func (d *DeployOps) ApplyOwnershipRemote(ctx context.Context, host, root string, rules []OwnershipRule, perms []PermissionRule) error {
+	if err := validateHost(host); err != nil {
+		return fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	for _, rule := range rules {
+		target := shellQuote(filepath.Join(root, rule.Path))
+		sshCmd := fmt.Sprintf("[ ! -e %s ] || { chown -R %d:%d %s", target, rule.UID, rule.GID, target)
+		if rule.Mode != 0 {
+			sshCmd += " && " + rule.chmodCommand(root, perms)
+		}
+		sshCmd += "; }"
+
+		err := retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+			cmd := exec.CommandContext(ctx, "ssh", host, sshCmd)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("remote chown failed: %w: %s", err, stderr.String())
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("apply ownership %s: %w", rule, err)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package reconcile
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -392,6 +393,66 @@ func TestDeployOps_ComposeUp(t *testing.T) {
 	})
 }
 
+func TestParseComposePS(t *testing.T) {
+	t.Run("one object per line", func(t *testing.T) {
+		out := `{"Service":"web","Name":"core-web-1","State":"running","Health":"healthy","ExitCode":0}
+{"Service":"db","Name":"core-db-1","State":"restarting","Health":"","ExitCode":1}
+`
+		services, err := parseComposePS([]byte(out))
+		require.NoError(t, err)
+		require.Len(t, services, 2)
+		assert.Equal(t, "db", services[0].Service, "sorted by service")
+		assert.Equal(t, "restarting", services[0].State)
+		assert.Equal(t, "web", services[1].Service)
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		out := `[{"Service":"web","Name":"core-web-1","State":"running","Health":"starting","ExitCode":0}]`
+		services, err := parseComposePS([]byte(out))
+		require.NoError(t, err)
+		require.Len(t, services, 1)
+		assert.Equal(t, "starting", services[0].Health)
+	})
+
+	t.Run("no containers", func(t *testing.T) {
+		services, err := parseComposePS([]byte("\n"))
+		require.NoError(t, err)
+		assert.Empty(t, services)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		_, err := parseComposePS([]byte("not json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		svc      ServiceHealth
+		healthy  bool
+		starting bool
+		status   string
+	}{
+		{"running without healthcheck", ServiceHealth{State: "running"}, true, false, "running"},
+		{"running healthy", ServiceHealth{State: "running", Health: "healthy"}, true, false, "running (healthy)"},
+		{"running unhealthy", ServiceHealth{State: "running", Health: "unhealthy"}, false, false, "running (unhealthy)"},
+		{"health starting", ServiceHealth{State: "running", Health: "starting"}, false, true, "running (starting)"},
+		{"crash looping", ServiceHealth{State: "restarting", ExitCode: 1}, false, false, "restarting"},
+		{"one-shot finished", ServiceHealth{State: "exited"}, true, false, "exited (0)"},
+		{"exited with error", ServiceHealth{State: "exited", ExitCode: 137}, false, false, "exited (137)"},
+		{"created", ServiceHealth{State: "created"}, false, true, "created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.healthy, tt.svc.Healthy())
+			assert.Equal(t, tt.starting, tt.svc.Starting())
+			assert.Equal(t, tt.status, tt.svc.Status())
+		})
+	}
+}
+
 func TestDeployOps_SignalContainer(t *testing.T) {
 	t.Run("dry run skips execution", func(t *testing.T) {
 		ctx := context.Background()
@@ -440,6 +501,14 @@ func TestDeployOps_ComposeUpRemote(t *testing.T) {
 
 		require.NoError(t, err)
 	})
+
+	t.Run("profile flags are quoted", func(t *testing.T) {
+		deploy := NewDeployOps(false)
+		assert.Empty(t, deploy.remoteProfileArgs())
+
+		deploy.Profiles = []string{"media", "dev"}
+		assert.Equal(t, " --profile 'media' --profile 'dev'", deploy.remoteProfileArgs())
+	})
 }
 
 func TestDeployOps_SignalContainerRemote(t *testing.T) {
@@ -451,6 +520,66 @@ func TestDeployOps_SignalContainerRemote(t *testing.T) {
 
 		require.NoError(t, err)
 	})
+
+	t.Run("uses the Docker API when a docker host is set", func(t *testing.T) {
+		fake := &fakeRemoteDocker{}
+		deploy := NewDeployOps(false)
+		deploy.DockerHost = "ssh://root@tower"
+		var opened string
+		deploy.openDocker = func(host string) (remoteDocker, error) {
+			opened = host
+			return fake, nil
+		}
+
+		require.NoError(t, deploy.SignalContainerRemote(context.Background(), "root@tower", "agentgateway", "SIGHUP"))
+		assert.Equal(t, "ssh://root@tower", opened)
+		assert.Equal(t, []string{"agentgateway SIGHUP"}, fake.signals)
+		assert.True(t, fake.closed)
+	})
+
+	t.Run("Docker API errors are returned", func(t *testing.T) {
+		deploy := NewDeployOps(false)
+		deploy.DockerHost = "tcp://tower:2376"
+		deploy.openDocker = func(string) (remoteDocker, error) {
+			return nil, errors.New("docker daemon not reachable")
+		}
+
+		err := deploy.SignalContainerRemote(context.Background(), "root@tower", "agentgateway", "SIGHUP")
+		assert.ErrorContains(t, err, "not reachable")
+	})
+}
+
+func TestDeployOps_PingDockerRemote(t *testing.T) {
+	fake := &fakeRemoteDocker{pingErr: errors.New("error during connect")}
+	deploy := NewDeployOps(false)
+	deploy.DockerHost = "ssh://root@tower"
+	deploy.openDocker = func(string) (remoteDocker, error) { return fake, nil }
+
+	err := deploy.pingDockerRemote("root@tower")(context.Background())
+	assert.True(t, isDockerUnavailable(err))
+	assert.True(t, fake.closed)
+
+	fake.pingErr = nil
+	assert.NoError(t, deploy.pingDockerRemote("root@tower")(context.Background()))
+}
+
+// fakeRemoteDocker records the Docker API calls of a remote deploy.
+type fakeRemoteDocker struct {
+	pingErr error
+	signals []string
+	closed  bool
+}
+
+func (f *fakeRemoteDocker) Ping(context.Context) error { return f.pingErr }
+
+func (f *fakeRemoteDocker) Signal(_ context.Context, name, signal string) error {
+	f.signals = append(f.signals, name+" "+signal)
+	return nil
+}
+
+func (f *fakeRemoteDocker) Close() error {
+	f.closed = true
+	return nil
 }
 
 func TestIsTransientSSHError(t *testing.T) {
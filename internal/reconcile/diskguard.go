@@ -0,0 +1,129 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// Default free space thresholds checked before each deploy. A filesystem
+// must have both this many bytes and this percentage free.
+const (
+	DefaultDiskMinFree        = 1 << 30 // 1 GiB
+	DefaultDiskMinFreePercent = 5.0
+)
+
+// ErrLowDisk indicates a filesystem the deploy writes to is below its free
+// space threshold.
+var ErrLowDisk = errors.New("insufficient disk space")
+
+// ParseByteSize parses a size such as "500M", "2G", "1.5GiB", or a plain
+// byte count. Units are binary (K = 1024).
+func ParseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	upper := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(s), "B"), "I")
+	multiplier := uint64(1)
+	if upper != "" {
+		if i := strings.IndexByte("KMGT", upper[len(upper)-1]); i != -1 {
+			multiplier = 1 << (10 * (i + 1))
+			upper = upper[:len(upper)-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(upper), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 500M, 2G)", s)
+	}
+	return uint64(n * float64(multiplier)), nil
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 GB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// lowDisks returns a description of each disk below the thresholds. A zero
+// threshold is not checked.
+func lowDisks(disks []hostmetrics.DiskUsage, minFree uint64, minFreePercent float64) []string {
+	var low []string
+	for _, d := range disks {
+		freePercent := 100 - d.UsedPercent()
+		if (minFree > 0 && d.Available < minFree) || (minFreePercent > 0 && freePercent < minFreePercent) {
+			low = append(low, fmt.Sprintf("%s (%s) has %s free (%.1f%%)", d.Label, d.Path, formatBytes(d.Available), freePercent))
+		}
+	}
+	return low
+}
+
+// checkDiskSpace aborts the deploy early when a filesystem it writes to is
+// low on space, rather than failing halfway through with ENOSPC and a
+// truncated backup. It checks the repository, staging, and backup
+// directories here, and the appdata and Docker data directories on the
+// deploy target. Paths that don't exist or can't be read are skipped.
+func (r *Reconciler) checkDiskSpace(ctx context.Context, secrets map[string]any) error {
+	minFree, minFreePercent := r.config.DiskMinFree, r.config.DiskMinFreePercent
+	if minFree == 0 && minFreePercent == 0 {
+		return nil
+	}
+
+	var disks []hostmetrics.DiskUsage
+	probe := func(paths ...hostmetrics.Path) {
+		for _, p := range paths {
+			if p.Path == "" {
+				continue
+			}
+			if _, err := os.Stat(p.Path); err != nil {
+				continue
+			}
+			if usage, err := hostmetrics.ProbeDisk(p); err == nil {
+				disks = append(disks, *usage)
+			}
+		}
+	}
+
+	probe(
+		hostmetrics.Path{Label: "repo", Path: r.config.RepoDir},
+		hostmetrics.Path{Label: "staging", Path: r.config.StagingDir},
+		hostmetrics.Path{Label: "backups", Path: r.config.BackupDir},
+	)
+
+	if r.isLocalMode() {
+		probe(
+			hostmetrics.Path{Label: "appdata", Path: r.config.LocalAppdataPath},
+			hostmetrics.Path{Label: "docker", Path: r.config.DockerRootDir},
+		)
+	} else if host := r.getTargetHost(secrets); host != "" {
+		if err := validateHost(host); err != nil {
+			return fmt.Errorf("invalid target host: %w", err)
+		}
+		remote, err := hostmetrics.RemoteDisks(ctx, host, []hostmetrics.Path{
+			{Label: host + " appdata", Path: r.config.RemoteAppdataPath},
+			{Label: host + " docker", Path: r.config.DockerRootDir},
+		})
+		if err != nil {
+			ui.Warning("Skipping target disk space check: %v", err)
+		}
+		disks = append(disks, remote...)
+	}
+
+	if low := lowDisks(disks, minFree, minFreePercent); len(low) > 0 {
+		return fmt.Errorf("%w: %s (need %s and %.0f%% free; see BOSUN_DISK_MIN_FREE)",
+			ErrLowDisk, strings.Join(low, "; "), formatBytes(minFree), minFreePercent)
+	}
+	return nil
+}
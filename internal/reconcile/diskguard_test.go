@@ -0,0 +1,84 @@
+package reconcile
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"1024", 1024},
+		{"0", 0},
+		{"500M", 500 << 20},
+		{"2G", 2 << 30},
+		{"2gb", 2 << 30},
+		{"1.5GiB", 3 << 29},
+		{"1T", 1 << 40},
+		{"64K", 64 << 10},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, bad := range []string{"", "G", "lots", "-1G", "1X"} {
+		_, err := ParseByteSize(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestLowDisks(t *testing.T) {
+	disks := []hostmetrics.DiskUsage{
+		{Label: "backups", Path: "/app/backups", Total: 100 << 30, Used: 10 << 30, Available: 90 << 30},
+		{Label: "appdata", Path: "/mnt/appdata", Total: 100 << 30, Used: 99 << 30, Available: 512 << 20},
+		{Label: "docker", Path: "/var/lib/docker", Total: 1000 << 30, Used: 970 << 30, Available: 30 << 30},
+	}
+
+	low := lowDisks(disks, 1<<30, 5)
+	require.Len(t, low, 2)
+	assert.Equal(t, "appdata (/mnt/appdata) has 512.0 MB free (0.5%)", low[0])
+	assert.Contains(t, low[1], "docker (/var/lib/docker) has 30.0 GB free (3.0%)")
+
+	assert.Len(t, lowDisks(disks, 1<<30, 0), 1, "percentage threshold disabled")
+	assert.Empty(t, lowDisks(disks, 0, 0), "all thresholds disabled")
+}
+
+func TestReconciler_CheckDiskSpace(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("disk usage is only read on Linux")
+	}
+
+	cfg := DefaultConfig()
+	cfg.RepoDir = t.TempDir()
+	cfg.StagingDir = t.TempDir()
+	cfg.BackupDir = t.TempDir()
+	cfg.LocalAppdataPath = t.TempDir()
+	cfg.DockerRootDir = "/nonexistent/docker"
+
+	t.Run("passes with enough space", func(t *testing.T) {
+		cfg.DiskMinFree, cfg.DiskMinFreePercent = 1, 0
+		assert.NoError(t, NewReconciler(cfg).checkDiskSpace(context.Background(), nil))
+	})
+
+	t.Run("fails below threshold", func(t *testing.T) {
+		cfg.DiskMinFree, cfg.DiskMinFreePercent = 1<<62, 0
+		err := NewReconciler(cfg).checkDiskSpace(context.Background(), nil)
+		require.ErrorIs(t, err, ErrLowDisk)
+		assert.Contains(t, err.Error(), "backups ("+cfg.BackupDir+")")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg.DiskMinFree, cfg.DiskMinFreePercent = 0, 0
+		assert.NoError(t, NewReconciler(cfg).checkDiskSpace(context.Background(), nil))
+	})
+}
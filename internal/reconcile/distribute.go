@@ -0,0 +1,149 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+// Image distribution modes: how images built from source reach a remote
+// deploy target.
+const (
+	// ImageDistributionBuild builds on the target's Docker daemon (default).
+	ImageDistributionBuild = "build"
+	// ImageDistributionRegistry builds here, pushes to a private registry,
+	// and pulls on the target.
+	ImageDistributionRegistry = "registry"
+	// ImageDistributionSSH builds here and streams the image to the target
+	// with docker save | ssh docker load.
+	ImageDistributionSSH = "ssh"
+)
+
+// ValidateImageDistribution checks that mode is a known distribution mode and
+// that registry mode has a registry.
+func ValidateImageDistribution(mode, registry string) error {
+	switch mode {
+	case "", ImageDistributionBuild, ImageDistributionSSH:
+		return nil
+	case ImageDistributionRegistry:
+		if registry == "" {
+			return fmt.Errorf("image distribution %q needs a registry (set BOSUN_REGISTRY)", mode)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid image distribution %q (expected %s, %s, or %s)",
+		mode, ImageDistributionBuild, ImageDistributionRegistry, ImageDistributionSSH)
+}
+
+// DistributeImage makes spec's image available on host. With the build mode
+// (or a local deploy) it builds in place; otherwise it builds on this machine
+// and ships the image by registry or SSH. Targets that already have the
+// image for the context are left alone. Returns the context tag reference and
+// whether anything was built or shipped.
+func (d *DeployOps) DistributeImage(ctx context.Context, host string, spec BuildSpec, opts BuildOptions, mode, registry string) (string, bool, error) {
+	if host == "" || mode == "" || mode == ImageDistributionBuild {
+		return d.BuildImage(ctx, host, spec, opts)
+	}
+
+	ref, err := BuildRef(spec)
+	if err != nil {
+		return "", false, err
+	}
+	if d.DryRun {
+		return ref, false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ImageBuildTimeout)
+	defer cancel()
+
+	if !opts.Force && runDocker(ctx, host, "image", "inspect", "--format", "{{.Id}}", ref) == nil {
+		return ref, false, runDocker(ctx, host, "tag", ref, spec.Image)
+	}
+
+	if _, _, err := d.BuildImage(ctx, "", spec, opts); err != nil {
+		return "", false, err
+	}
+
+	switch mode {
+	case ImageDistributionRegistry:
+		err = pushAndPull(ctx, host, ref, registryRef(registry, ref))
+	case ImageDistributionSSH:
+		err = streamImage(ctx, host, ref)
+	default:
+		err = ValidateImageDistribution(mode, registry)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("ship %s to %s: %w", spec.Service, host, err)
+	}
+
+	if err := runDocker(ctx, host, "tag", ref, spec.Image); err != nil {
+		return "", false, err
+	}
+	return ref, true, nil
+}
+
+// pushAndPull pushes ref to the registry as remote, pulls it on host, and
+// tags it back as ref there.
+func pushAndPull(ctx context.Context, host, ref, remote string) error {
+	if err := runDocker(ctx, "", "tag", ref, remote); err != nil {
+		return err
+	}
+	if err := runDocker(ctx, "", "push", remote); err != nil {
+		return err
+	}
+	if err := runDocker(ctx, host, "pull", remote); err != nil {
+		return err
+	}
+	return runDocker(ctx, host, "tag", remote, ref)
+}
+
+// streamImage copies ref to host with docker save | ssh host docker load.
+func streamImage(ctx context.Context, host, ref string) error {
+	if err := validateHost(host); err != nil {
+		return fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	save := exec.CommandContext(ctx, "docker", "save", ref)
+	load := exec.CommandContext(ctx, "ssh", host, "docker", "load")
+
+	pipe, err := save.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	load.Stdin = pipe
+	var saveErr, loadErr bytes.Buffer
+	save.Stderr = &saveErr
+	load.Stderr = &loadErr
+
+	if err := load.Start(); err != nil {
+		return fmt.Errorf("start docker load: %w", err)
+	}
+	if err := save.Run(); err != nil {
+		_ = load.Wait()
+		return fmt.Errorf("docker save: %w: %s", err, strings.TrimSpace(saveErr.String()))
+	}
+	if err := load.Wait(); err != nil {
+		return fmt.Errorf("docker load: %w: %s", err, strings.TrimSpace(loadErr.String()))
+	}
+	return nil
+}
+
+// registryRef rewrites ref under registry, dropping the registry of ref if it
+// has one: bosun.local/myapp:abc becomes registry:5000/myapp:abc.
+func registryRef(registry, ref string) string {
+	path := ref
+	if first, rest, ok := strings.Cut(ref, "/"); ok && isRegistryHost(first) {
+		path = rest
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + path
+}
+
+// isRegistryHost reports whether the first component of an image reference
+// names a registry rather than a Docker Hub namespace.
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost" || component == manifest.LocalImageRegistry
+}
@@ -0,0 +1,59 @@
+package reconcile
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImageDistribution(t *testing.T) {
+	assert.NoError(t, ValidateImageDistribution("", ""))
+	assert.NoError(t, ValidateImageDistribution(ImageDistributionBuild, ""))
+	assert.NoError(t, ValidateImageDistribution(ImageDistributionSSH, ""))
+	assert.NoError(t, ValidateImageDistribution(ImageDistributionRegistry, "registry.lan:5000"))
+
+	assert.ErrorContains(t, ValidateImageDistribution(ImageDistributionRegistry, ""), "needs a registry")
+	assert.ErrorContains(t, ValidateImageDistribution("rsync", ""), "invalid image distribution")
+}
+
+func TestRegistryRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"bosun.local/myapp:3f9c2e1a7b4d", "registry.lan:5000/myapp:3f9c2e1a7b4d"},
+		{"ghcr.io/me/app:abc", "registry.lan:5000/me/app:abc"},
+		{"localhost/app:abc", "registry.lan:5000/app:abc"},
+		{"me/app:abc", "registry.lan:5000/me/app:abc"},
+		{"app:abc", "registry.lan:5000/app:abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			assert.Equal(t, tt.want, registryRef("registry.lan:5000/", tt.ref))
+		})
+	}
+}
+
+func TestDeployOps_DistributeImage_DryRun(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	commitFiles(t, repo, repoDir, map[string]string{"images/web/Dockerfile": "FROM alpine\n"})
+
+	spec := BuildSpec{Service: "web", Image: "bosun.local/web", Context: filepath.Join(repoDir, "images", "web")}
+	tag, err := ContextTag(spec.Context)
+	require.NoError(t, err)
+
+	deploy := NewDeployOps(true)
+	for _, mode := range []string{ImageDistributionBuild, ImageDistributionRegistry, ImageDistributionSSH} {
+		ref, shipped, err := deploy.DistributeImage(context.Background(), "root@tower", spec, BuildOptions{}, mode, "registry.lan:5000")
+		require.NoError(t, err, mode)
+		assert.Equal(t, "bosun.local/web:"+tag, ref, mode)
+		assert.False(t, shipped, mode)
+	}
+}
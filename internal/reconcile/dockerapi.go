@@ -0,0 +1,57 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+)
+
+// remoteDocker is the part of the Docker API that remote deploys use when
+// DeployOps.DockerHost is set.
+type remoteDocker interface {
+	Ping(ctx context.Context) error
+	Signal(ctx context.Context, name, signal string) error
+	Close() error
+}
+
+// openRemoteDocker connects to the daemon at DockerHost.
+func (d *DeployOps) openRemoteDocker() (remoteDocker, error) {
+	if d.openDocker != nil {
+		return d.openDocker(d.DockerHost)
+	}
+	client, err := docker.NewRemoteClient(docker.RemoteConfig{Host: d.DockerHost})
+	if err != nil {
+		return nil, fmt.Errorf("connect to docker at %s: %w", d.DockerHost, err)
+	}
+	return client, nil
+}
+
+// signalContainerAPI sends a signal to a container through the Docker API
+// at DockerHost.
+func (d *DeployOps) signalContainerAPI(ctx context.Context, containerName, signal string) error {
+	client, err := d.openRemoteDocker()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.Signal(ctx, containerName, signal)
+}
+
+// pingDockerRemote returns a check that the Docker daemon on host answers:
+// through the Docker API at DockerHost when set, else docker info over SSH.
+func (d *DeployOps) pingDockerRemote(host string) func(context.Context) error {
+	if d.DockerHost == "" {
+		return pingDockerSSH(host)
+	}
+	return func(ctx context.Context) error {
+		client, err := d.openRemoteDocker()
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		return client.Ping(ctx)
+	}
+}
@@ -0,0 +1,118 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// Docker restart handling. Unraid updates restart the Docker daemon, which
+// can happen mid-reconcile; bosun waits this long for it to come back.
+const (
+	DockerRestartTimeout = 2 * time.Minute
+	DockerPollInterval   = 5 * time.Second
+)
+
+// ErrDockerUnavailable indicates the Docker daemon went away during a deploy
+// and did not come back in time. No rollback is attempted: the running
+// containers were not changed by a failed config, so the previous state is
+// left as Docker restores it.
+var ErrDockerUnavailable = errors.New("docker daemon unavailable")
+
+// isDockerUnavailable reports whether err came from losing the Docker daemon
+// rather than from the compose file or a container.
+func isDockerUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if client.IsErrConnectionFailed(err) {
+		return true
+	}
+	errStr := strings.ToLower(err.Error())
+	patterns := []string{
+		"cannot connect to the docker daemon",
+		"is the docker daemon running",
+		"error during connect",
+		"docker.sock: connect",
+		"docker.sock: read: connection reset",
+	}
+	for _, pattern := range patterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForDocker polls ping until it succeeds, timeout elapses, or ctx is done.
+func waitForDocker(ctx context.Context, ping func(context.Context) error, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("not back after %s: %w", timeout, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// resumeAfterDockerRestart handles a deploy step that failed because the
+// Docker daemon went away: it waits for the daemon and runs the step once
+// more. Errors from other causes are returned unchanged.
+func resumeAfterDockerRestart(ctx context.Context, err error, where string, ping func(context.Context) error, step func() error) error {
+	if !isDockerUnavailable(err) {
+		return err
+	}
+
+	ui.Warning("Docker daemon on %s went away during deploy, waiting up to %s for it to return...", where, DockerRestartTimeout)
+	if waitErr := waitForDocker(ctx, ping, DockerRestartTimeout, DockerPollInterval); waitErr != nil {
+		return fmt.Errorf("%w on %s: %v", ErrDockerUnavailable, where, waitErr)
+	}
+
+	ui.Info("Docker daemon on %s is back, resuming deploy", where)
+	if err := step(); err != nil {
+		if isDockerUnavailable(err) {
+			return fmt.Errorf("%w on %s: %v", ErrDockerUnavailable, where, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// pingDocker checks that the local Docker daemon answers.
+func pingDocker(ctx context.Context) error {
+	return runDockerPing(exec.CommandContext(ctx, "docker", "info", "--format", "{{.ServerVersion}}"))
+}
+
+// pingDockerSSH checks that the Docker daemon on host answers, running
+// docker info over SSH.
+func pingDockerSSH(host string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return runDockerPing(exec.CommandContext(ctx, "ssh", host, "docker info --format '{{.ServerVersion}}'"))
+	}
+}
+
+func runDockerPing(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker info: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
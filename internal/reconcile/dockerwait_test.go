@@ -0,0 +1,108 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDockerUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("docker compose up failed: exit status 1: Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?"), true},
+		{errors.New("error during connect: Get \"http://%2Fvar%2Frun%2Fdocker.sock/v1.45/containers/json\": EOF"), true},
+		{errors.New("dial unix /var/run/docker.sock: connect: no such file or directory"), true},
+		{errors.New("docker compose up failed: exit status 1: service \"web\" has neither an image nor a build context"), false},
+		{errors.New("container sonarr is unhealthy"), false},
+	}
+
+	for _, tt := range tests {
+		name := "nil"
+		if tt.err != nil {
+			name = tt.err.Error()
+		}
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isDockerUnavailable(tt.err))
+		})
+	}
+}
+
+func TestWaitForDocker(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("Cannot connect to the Docker daemon")
+
+	t.Run("returns once the daemon answers", func(t *testing.T) {
+		calls := 0
+		ping := func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return down
+			}
+			return nil
+		}
+
+		require.NoError(t, waitForDocker(ctx, ping, time.Second, time.Millisecond))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		ping := func(context.Context) error { return down }
+
+		err := waitForDocker(ctx, ping, 20*time.Millisecond, 5*time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not back after 20ms")
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		ping := func(context.Context) error { return down }
+
+		err := waitForDocker(cancelled, ping, time.Minute, time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestResumeAfterDockerRestart(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("docker compose up failed: Cannot connect to the Docker daemon")
+	up := func(context.Context) error { return nil }
+
+	t.Run("other errors pass through without retry", func(t *testing.T) {
+		composeErr := errors.New("docker compose up failed: invalid compose file")
+		err := resumeAfterDockerRestart(ctx, composeErr, "local host", up, func() error {
+			t.Fatal("step should not be retried")
+			return nil
+		})
+		assert.Equal(t, composeErr, err)
+	})
+
+	t.Run("retries the step once the daemon is back", func(t *testing.T) {
+		retried := false
+		err := resumeAfterDockerRestart(ctx, down, "local host", up, func() error {
+			retried = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, retried)
+	})
+
+	t.Run("daemon lost again on retry aborts", func(t *testing.T) {
+		err := resumeAfterDockerRestart(ctx, down, "tower", up, func() error { return down })
+		assert.ErrorIs(t, err, ErrDockerUnavailable)
+		assert.Contains(t, err.Error(), "on tower")
+	})
+
+	t.Run("retry failing for another reason is returned for rollback", func(t *testing.T) {
+		composeErr := errors.New("container web is unhealthy")
+		err := resumeAfterDockerRestart(ctx, down, "local host", up, func() error { return composeErr })
+		assert.Equal(t, composeErr, err)
+	})
+}
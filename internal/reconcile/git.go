@@ -5,15 +5,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	xssh "golang.org/x/crypto/ssh"
@@ -368,6 +371,162 @@ func (g *GitOps) GetCommitMessage(ctx context.Context) (string, error) {
 	return fmt.Sprintf("%s %s", shortHash, subject), nil
 }
 
+// ReadFileAtRef returns the contents of path (relative to the repo root) at the given
+// commit, tag, or branch. Clones are shallow, so if ref is not available locally the
+// full history and tags are fetched from origin before retrying.
+// Returns os.ErrNotExist (wrapped) if the file does not exist at ref.
+func (g *GitOps) ReadFileAtRef(ctx context.Context, ref, path string) ([]byte, error) {
+	commit, err := g.commitAtRef(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := commit.File(filepath.ToSlash(filepath.Clean(path)))
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("%s at %s: %w", path, ref, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, ref, err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, ref, err)
+	}
+	return []byte(contents), nil
+}
+
+// commitAtRef resolves a commit, tag, or branch, fetching history if the
+// ref is not available in a shallow clone.
+func (g *GitOps) commitAtRef(ctx context.Context, ref string) (*object.Commit, error) {
+	if err := ValidateRef(ref); err != nil {
+		return nil, fmt.Errorf("invalid ref: %w", err)
+	}
+
+	repo, err := git.PlainOpen(g.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		if fetchErr := g.fetchHistory(ctx, repo); fetchErr != nil {
+			return nil, fmt.Errorf("ref %s not found locally and fetch failed: %w", ref, fetchErr)
+		}
+		hash, err = repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+		}
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit for %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+// ChangedFiles returns the repo-relative paths that differ between two refs.
+func (g *GitOps) ChangedFiles(ctx context.Context, before, after string) ([]string, error) {
+	from, err := g.commitAtRef(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := g.commitAtRef(ctx, after)
+	if err != nil {
+		return nil, err
+	}
+
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree at %s: %w", before, err)
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree at %s: %w", after, err)
+	}
+
+	changes, err := fromTree.DiffContext(ctx, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", before, after, err)
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		name := c.To.Name
+		if name == "" {
+			name = c.From.Name
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ExportTree writes the files under subdir at ref into dest, preserving
+// their paths relative to subdir.
+func (g *GitOps) ExportTree(ctx context.Context, ref, subdir, dest string) error {
+	commit, err := g.commitAtRef(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	prefix := filepath.ToSlash(filepath.Clean(subdir))
+	if prefix == "." {
+		prefix = ""
+	} else {
+		prefix += "/"
+	}
+
+	files, err := commit.Files()
+	if err != nil {
+		return fmt.Errorf("failed to list files at %s: %w", ref, err)
+	}
+	return files.ForEach(func(f *object.File) error {
+		if !strings.HasPrefix(f.Name, prefix) {
+			return nil
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("failed to read %s at %s: %w", f.Name, ref, err)
+		}
+		out := filepath.Join(dest, filepath.FromSlash(strings.TrimPrefix(f.Name, prefix)))
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(out, []byte(contents), 0644)
+	})
+}
+
+// fetchHistory deepens a shallow clone and fetches tags so older refs can be resolved.
+func (g *GitOps) fetchHistory(ctx context.Context, repo *git.Repository) error {
+	auth, err := getSSHAuth(g.RepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to get SSH auth: %w", err)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, GitFetchTimeout)
+	defer cancel()
+
+	err = repo.FetchContext(fetchCtx, &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", g.Branch, g.Branch)),
+			"+refs/tags/*:refs/tags/*",
+		},
+		// Same as git fetch --unshallow.
+		Depth: math.MaxInt32,
+		Auth:  auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if fetchCtx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("git fetch timed out after %v", GitFetchTimeout)
+		}
+		return err
+	}
+	return nil
+}
+
 // IsRepoCheckTimeout is the timeout for checking if a directory is a git repository.
 const IsRepoCheckTimeout = 2 * time.Second
 
@@ -390,3 +390,65 @@ func TestGitOps_Pull(t *testing.T) {
 		assert.NotEqual(t, before, after)
 	})
 }
+
+func TestGitOps_ReadFileAtRef(t *testing.T) {
+	ctx := context.Background()
+	sourceDir := t.TempDir()
+
+	repo, err := git.PlainInit(sourceDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	commit := func(content, msg string) {
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "stack.yml"), []byte(content), 0644))
+		_, err := worktree.Add("stack.yml")
+		require.NoError(t, err)
+		_, err = worktree.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@test.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	commit("v1", "first")
+	head, err := repo.Head()
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", head.Hash(), nil)
+	require.NoError(t, err)
+	firstHash := head.Hash().String()
+	commit("v2", "second")
+
+	// Shallow clone only has the latest commit.
+	targetDir := filepath.Join(t.TempDir(), "target")
+	gitOps := NewGitOps(sourceDir, "master", targetDir)
+	_, _, _, err = gitOps.Sync(ctx)
+	require.NoError(t, err)
+
+	t.Run("reads file at HEAD", func(t *testing.T) {
+		content, err := gitOps.ReadFileAtRef(ctx, "HEAD", "stack.yml")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", string(content))
+	})
+
+	t.Run("fetches history for older tag", func(t *testing.T) {
+		content, err := gitOps.ReadFileAtRef(ctx, "v1.0.0", "stack.yml")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", string(content))
+	})
+
+	t.Run("reads file at commit hash", func(t *testing.T) {
+		content, err := gitOps.ReadFileAtRef(ctx, firstHash, "stack.yml")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", string(content))
+	})
+
+	t.Run("missing file returns ErrNotExist", func(t *testing.T) {
+		_, err := gitOps.ReadFileAtRef(ctx, "HEAD", "missing.yml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("rejects option injection", func(t *testing.T) {
+		_, err := gitOps.ReadFileAtRef(ctx, "--upload-pack=evil", "stack.yml")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,193 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// stampHashes gives every staged YAML file a content hash header (see
+// manifest.WithHashHeader), replacing headers that templates carried over
+// from the repo, so the deployed copies record what was synced.
+func (r *Reconciler) stampHashes() error {
+	root := filepath.Join(r.config.StagingDir, "unraid")
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !manifest.HashableFile(path) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, manifest.WithHashHeader(data), info.Mode().Perm())
+	})
+}
+
+// configUnchanged reports whether the deployed config at deployed (a file
+// or directory, on host over SSH when set) already matches the staged one:
+// every staged file's content hash equals the deployed copy's hash header,
+// and no deployed file is missing from staging. Anything it can't read
+// counts as changed, as does a forced run.
+func (r *Reconciler) configUnchanged(ctx context.Context, host, staged, deployed string) bool {
+	if r.config.Force {
+		return false
+	}
+	info, err := os.Stat(staged)
+	if err != nil {
+		return false
+	}
+
+	if !info.IsDir() {
+		want, err := os.ReadFile(staged)
+		if err != nil {
+			return false
+		}
+		got, err := r.deploy.ReadFile(ctx, host, deployed)
+		if err != nil {
+			return false
+		}
+		hash, ok := manifest.HashHeader(got)
+		return ok && hash == manifest.ContentHash(want)
+	}
+
+	want, err := stagedHashes(staged)
+	if err != nil {
+		return false
+	}
+	got, err := r.deploy.HashHeaders(ctx, host, deployed)
+	if err != nil {
+		return false
+	}
+	return maps.Equal(want, got)
+}
+
+// stagedHashes returns the content hash of every file under dir, keyed by
+// slash-separated path relative to dir.
+func stagedHashes(dir string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)] = manifest.ContentHash(data)
+		return nil
+	})
+	return hashes, err
+}
+
+// HashHeaders returns the hash header of every file under dir on host
+// ("" for local), keyed by slash-separated path relative to dir. Files
+// without a header map to "". A missing dir has no files.
+func (d *DeployOps) HashHeaders(ctx context.Context, host, dir string) (map[string]string, error) {
+	if host == "" {
+		return localHashHeaders(dir)
+	}
+	if err := validateHost(host); err != nil {
+		return nil, fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	// head -v prints "==> ./path <==" before each file's first line.
+	script := fmt.Sprintf("cd %s 2>/dev/null || exit 0; find . -type f -exec head -v -n1 -- {} +", shellQuote(dir))
+	var output []byte
+	err := retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+		cmd := exec.CommandContext(ctx, "ssh", host, script)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("remote hash read failed: %w: %s", err, stderr.String())
+		}
+		output = out
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseHeadOutput(string(output)), nil
+}
+
+// localHashHeaders reads the hash header of every file under dir.
+func localHashHeaders(dir string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)], _ = manifest.HashHeader(data)
+		return nil
+	})
+	return hashes, err
+}
+
+// parseHeadOutput parses "head -v -n1" output into hash headers keyed by
+// path, without the leading "./".
+func parseHeadOutput(output string) map[string]string {
+	hashes := make(map[string]string)
+	var current string
+	for _, line := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(line, "==> "); ok && strings.HasSuffix(name, " <==") {
+			current = strings.TrimPrefix(strings.TrimSuffix(name, " <=="), "./")
+			hashes[current] = ""
+			continue
+		}
+		if current != "" && hashes[current] == "" {
+			hashes[current], _ = manifest.HashHeader([]byte(line))
+		}
+	}
+	return hashes
+}
+
+// syncsConfig reports whether a component's config is synced: the run's
+// changes touch it and the deployed copy differs from staging. Skipping an
+// unchanged config also skips its reload.
+func (r *Reconciler) syncsConfig(ctx context.Context, host, component, staged, deployed string) bool {
+	if !r.changes.HasConfig(component) {
+		return false
+	}
+	if r.configUnchanged(ctx, host, staged, deployed) {
+		ui.Info("  %s config unchanged, skipping", component)
+		return false
+	}
+	return true
+}
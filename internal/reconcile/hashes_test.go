@@ -0,0 +1,73 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/fileutil"
+	"github.com/cameronsjo/bosun/internal/manifest"
+)
+
+func TestReconciler_ConfigUnchanged(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.StagingDir = t.TempDir()
+	r := NewReconciler(cfg)
+
+	staged := filepath.Join(cfg.StagingDir, "unraid", "appdata")
+	writeFile(t, filepath.Join(staged, "gatus", "config.yaml"), "endpoints: []\n")
+	writeFile(t, filepath.Join(staged, "traefik", "dynamic.yml"), "http: {}\n")
+	writeFile(t, filepath.Join(staged, "traefik", "middlewares.yml"), "http: {}\n")
+	writeFile(t, filepath.Join(staged, "tailscale-gateway", "serve.json"), "{}\n")
+	require.NoError(t, r.stampHashes())
+
+	data, err := os.ReadFile(filepath.Join(staged, "gatus", "config.yaml"))
+	require.NoError(t, err)
+	_, ok := manifest.HashHeader(data)
+	assert.True(t, ok, "staged YAML is stamped")
+
+	deployed := t.TempDir()
+	require.NoError(t, fileutil.CopyDir(staged, deployed))
+
+	assert.True(t, r.configUnchanged(ctx, "", filepath.Join(staged, "gatus", "config.yaml"), filepath.Join(deployed, "gatus", "config.yaml")))
+	assert.True(t, r.configUnchanged(ctx, "", filepath.Join(staged, "traefik"), filepath.Join(deployed, "traefik")))
+	assert.False(t, r.configUnchanged(ctx, "", filepath.Join(staged, "tailscale-gateway", "serve.json"), filepath.Join(deployed, "tailscale-gateway", "serve.json")), "JSON has no hash header")
+	assert.False(t, r.configUnchanged(ctx, "", filepath.Join(staged, "gatus", "config.yaml"), filepath.Join(deployed, "missing.yaml")))
+
+	t.Run("changed file", func(t *testing.T) {
+		writeFile(t, filepath.Join(staged, "gatus", "config.yaml"), "endpoints:\n  - name: web\n")
+		require.NoError(t, r.stampHashes())
+		assert.False(t, r.configUnchanged(ctx, "", filepath.Join(staged, "gatus", "config.yaml"), filepath.Join(deployed, "gatus", "config.yaml")))
+	})
+
+	t.Run("file removed from staging", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(staged, "traefik", "middlewares.yml")))
+		assert.False(t, r.configUnchanged(ctx, "", filepath.Join(staged, "traefik"), filepath.Join(deployed, "traefik")))
+	})
+
+	t.Run("force syncs everything", func(t *testing.T) {
+		cfg.Force = true
+		defer func() { cfg.Force = false }()
+		assert.False(t, r.configUnchanged(ctx, "", filepath.Join(staged, "traefik", "dynamic.yml"), filepath.Join(deployed, "traefik", "dynamic.yml")))
+	})
+}
+
+func TestParseHeadOutput(t *testing.T) {
+	output := "==> ./dynamic.yml <==\n# bosun:hash sha256:abc123\n\n==> ./conf.d/tls.yml <==\ntls: {}\n\n==> ./empty.yml <==\n"
+	assert.Equal(t, map[string]string{
+		"dynamic.yml":    "abc123",
+		"conf.d/tls.yml": "",
+		"empty.yml":      "",
+	}, parseHeadOutput(output))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
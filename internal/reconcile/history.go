@@ -0,0 +1,93 @@
+package reconcile
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// SourceManual is the trigger source of runs that don't name one, such as
+// 'bosun reconcile' from a shell.
+const SourceManual = "manual"
+
+type triggerKey struct{}
+
+// WithTrigger returns a context that records what triggered a run
+// ("webhook", "poll", ...) in the deploy history.
+func WithTrigger(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, triggerKey{}, source)
+}
+
+// triggerFrom returns the trigger source stored by WithTrigger, or
+// SourceManual.
+func triggerFrom(ctx context.Context) string {
+	if source, ok := ctx.Value(triggerKey{}).(string); ok && source != "" {
+		return source
+	}
+	return SourceManual
+}
+
+// newReconcileID returns a short random ID for a reconcile run.
+func newReconcileID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// recordDeploy appends a successful deploy to the state history and counts
+// it, by stack, in today's digest counters.
+func (r *Reconciler) recordDeploy(ctx context.Context) {
+	if r.config.StateDir == "" || r.config.DryRun {
+		return
+	}
+
+	now := time.Now().UTC()
+	stacks := r.deployedStacks()
+	err := state.NewStore(r.config.StateDir).Update(func(st *state.State) error {
+		st.RecordDeploy(state.Deploy{
+			At:     now,
+			Source: triggerFrom(ctx),
+			Commit: r.lastCommit,
+			Stacks: stacks,
+		})
+		day := st.Day(now)
+		if day.Deploys == nil {
+			day.Deploys = make(map[string]int)
+		}
+		for _, stack := range stacks {
+			day.Deploys[stack]++
+		}
+		r.recordApplied(st, now)
+		return nil
+	})
+	if err != nil {
+		ui.Warning("Failed to record deploy: %v", err)
+	}
+}
+
+// deployedStacks returns the stacks the run deployed: those its changes
+// touch, or every staged stack a full deploy didn't skip as unchanged.
+func (r *Reconciler) deployedStacks() []string {
+	if r.changes != nil && !r.changes.Full {
+		return r.changes.Stacks
+	}
+	files, _ := stackComposeFiles(filepath.Join(r.config.StagingDir, "unraid", "compose"))
+	stacks := make([]string, 0, len(files))
+	for _, f := range files {
+		if stack := strings.TrimSuffix(filepath.Base(f), ".yml"); r.changes.HasStack(stack) {
+			stacks = append(stacks, stack)
+		}
+	}
+	sort.Strings(stacks)
+	return stacks
+}
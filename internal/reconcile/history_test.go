@@ -0,0 +1,65 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestReconciler_RecordDeploy(t *testing.T) {
+	setup := func(t *testing.T) *Reconciler {
+		cfg := DefaultConfig()
+		cfg.StateDir = t.TempDir()
+		r := NewReconciler(cfg)
+		r.lastCommit = "abc1234"
+		return r
+	}
+
+	t.Run("records the trigger source", func(t *testing.T) {
+		r := setup(t)
+		r.recordDeploy(WithTrigger(context.Background(), "webhook"))
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		last, ok := st.LastDeploy()
+		require.True(t, ok)
+		assert.Equal(t, "webhook", last.Source)
+		assert.Equal(t, "abc1234", last.Commit)
+	})
+
+	t.Run("counts deployed stacks for the digest", func(t *testing.T) {
+		r := setup(t)
+		r.changes = &ChangeSet{Stacks: []string{"core", "media"}}
+		r.recordDeploy(context.Background())
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		last, _ := st.LastDeploy()
+		assert.Equal(t, []string{"core", "media"}, last.Stacks)
+		assert.Equal(t, map[string]int{"core": 1, "media": 1}, st.Day(last.At).Deploys)
+	})
+
+	t.Run("defaults to manual", func(t *testing.T) {
+		r := setup(t)
+		r.recordDeploy(context.Background())
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		last, _ := st.LastDeploy()
+		assert.Equal(t, SourceManual, last.Source)
+	})
+
+	t.Run("dry run records nothing", func(t *testing.T) {
+		r := setup(t)
+		r.config.DryRun = true
+		r.recordDeploy(context.Background())
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		assert.Empty(t, st.Deploys)
+	})
+}
@@ -12,6 +12,9 @@ type GitOperations interface {
 	// IsRepo checks if the directory is a git repository.
 	// Uses the provided context for timeout control.
 	IsRepo(ctx context.Context) bool
+
+	// ReadFileAtRef returns the contents of a repo-relative path at a commit, tag, or branch.
+	ReadFileAtRef(ctx context.Context, ref, path string) ([]byte, error)
 }
 
 // SecretsDecryptor handles SOPS decryption.
@@ -0,0 +1,223 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// InventoryHostsDir holds the per-host staging and backup directories of
+// inventory hosts, under StagingDir and BackupDir.
+const InventoryHostsDir = "hosts"
+
+// loadInventory reads the inventory from the synced repository. It returns
+// nil when the repository has none, so every stack deploys to the target.
+func (r *Reconciler) loadInventory() (*config.Inventory, error) {
+	path := filepath.Join(r.config.RepoDir, r.config.InfraSubDir, config.InventoryFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return config.LoadInventory(path)
+}
+
+// secondaryHosts returns the names of the inventory hosts bosun deploys to
+// over SSH, sorted; the primary host is deployed as usual.
+func (r *Reconciler) secondaryHosts() []string {
+	if r.inventory == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range r.inventory.HostNames() {
+		if r.inventory.Hosts[name].Address != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// hostComposeDir returns where an inventory host's stacks are staged.
+func (r *Reconciler) hostComposeDir(name string) string {
+	return filepath.Join(r.config.StagingDir, InventoryHostsDir, name, "compose")
+}
+
+// stagedComposeDirs returns every staged compose directory: the target's,
+// then each secondary host's.
+func (r *Reconciler) stagedComposeDirs() []string {
+	dirs := []string{filepath.Join(r.config.StagingDir, "unraid", "compose")}
+	for _, name := range r.secondaryHosts() {
+		dirs = append(dirs, r.hostComposeDir(name))
+	}
+	return dirs
+}
+
+// splitInventory loads the inventory and moves each stack assigned to a
+// secondary host, with its host override files, from the target's staged
+// compose files to that host's. Stacks that build images stay put: builds
+// only run on the target.
+func (r *Reconciler) splitInventory() error {
+	inv, err := r.loadInventory()
+	if err != nil {
+		return err
+	}
+	r.inventory = inv
+	if inv == nil {
+		return nil
+	}
+
+	stagingCompose := filepath.Join(r.config.StagingDir, "unraid", "compose")
+	repoCompose := filepath.Join(r.config.RepoDir, r.config.InfraSubDir, "unraid", "compose")
+	for _, name := range r.secondaryHosts() {
+		if err := os.MkdirAll(r.hostComposeDir(name), 0755); err != nil {
+			return fmt.Errorf("create staging for host %s: %w", name, err)
+		}
+	}
+
+	for stack := range inv.Stacks {
+		name, err := inv.HostFor(stack)
+		if err != nil {
+			return err
+		}
+		if inv.Hosts[name].Address == "" {
+			continue // The primary host is the target
+		}
+
+		file := filepath.Join(stagingCompose, stack+".yml")
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			ui.Warning("Inventory assigns stack %s to %s, but there is no such stack", stack, name)
+			continue
+		}
+		specs, err := FindBuilds(file, repoCompose)
+		if err != nil {
+			return err
+		}
+		if len(specs) > 0 {
+			return fmt.Errorf("stack %s builds images, which only deploys to the primary host; it can't be assigned to %s", stack, name)
+		}
+
+		overrides, _ := filepath.Glob(filepath.Join(stagingCompose, stack+".*.yml"))
+		for _, src := range append([]string{file}, overrides...) {
+			if err := os.Rename(src, filepath.Join(r.hostComposeDir(name), filepath.Base(src))); err != nil {
+				return fmt.Errorf("stage stack %s for host %s: %w", stack, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deployInventoryHosts deploys the stacks of each secondary host the run's
+// changes touch: a backup of the paths they declare, then their compose
+// files, then compose up per stack in dependency order. Hosts deploy
+// independently; one failing doesn't stop the others, and there is no
+// rollback.
+func (r *Reconciler) deployInventoryHosts(ctx context.Context) error {
+	var errs []error
+	for _, name := range r.secondaryHosts() {
+		if err := r.deployInventoryHost(ctx, name); err != nil {
+			ui.Error("Deploy to host %s failed: %v", name, err)
+			errs = append(errs, fmt.Errorf("host %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deployInventoryHost deploys one secondary host's changed stacks.
+func (r *Reconciler) deployInventoryHost(ctx context.Context, name string) error {
+	composeDir := r.hostComposeDir(name)
+	files, err := stackComposeFiles(composeDir)
+	if err != nil {
+		return err
+	}
+	var changed []string
+	for _, file := range files {
+		if stack := strings.TrimSuffix(filepath.Base(file), ".yml"); r.changes.HasStack(stack) {
+			changed = append(changed, stack)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	host := r.inventory.Hosts[name]
+	appdata := host.Appdata
+	if appdata == "" {
+		appdata = r.config.RemoteAppdataPath
+	}
+	ui.Info("Deploying %s to host %s (%s)...", strings.Join(changed, ", "), name, host.Address)
+
+	if !r.config.DryRun {
+		if err := r.backupInventoryHost(ctx, name, host.Address, appdata); err != nil {
+			ui.Warning("Backup of host %s failed: %v", name, err)
+		}
+	}
+
+	ui.Info("  Syncing compose files...")
+	_ = r.deploy.EnsureRemoteDir(ctx, host.Address, filepath.Join(appdata, "compose"))
+	if err := r.deploy.DeployRemote(ctx, composeDir, host.Address, filepath.Join(appdata, "compose")); err != nil {
+		return err
+	}
+	if r.config.DryRun {
+		return nil
+	}
+
+	deps, depsErr := stackDependencies(files)
+	if depsErr != nil {
+		ui.Warning("Could not read stack dependencies, reloading in file order: %v", depsErr)
+	}
+	files, cycle := orderStacks(files, deps)
+	if len(cycle) > 0 {
+		ui.Warning("Stacks %s depend on each other, reloading them in file order", strings.Join(cycle, ", "))
+	}
+
+	var errs []error
+	for _, file := range files {
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		if !r.changes.HasStack(stack) {
+			continue
+		}
+		ui.Info("  Reloading stack %s...", stack)
+		remoteFile := filepath.Join(appdata, "compose", filepath.Base(file))
+		if err := r.deploy.ComposeUpRemoteStack(ctx, host.Address, remoteFile); err != nil {
+			errs = append(errs, fmt.Errorf("stack %s: %w", stack, err))
+		}
+	}
+	if len(errs) == 0 {
+		ui.Success("Host %s deployed", name)
+	}
+	return errors.Join(errs...)
+}
+
+// backupInventoryHost backs up the paths a secondary host's stacks declare
+// to its own directory under BackupDir.
+func (r *Reconciler) backupInventoryHost(ctx context.Context, name, address, appdata string) error {
+	stacks, err := LoadBackupPaths(r.hostComposeDir(name))
+	if err != nil {
+		return fmt.Errorf("collect backup paths: %w", err)
+	}
+	for stack, paths := range stacks {
+		stacks[stack] = dedupe(paths)
+	}
+	index := &BackupIndex{Appdata: appdata, Stacks: stacks}
+	if len(index.Paths()) == 0 {
+		return nil
+	}
+
+	backupDir := filepath.Join(r.config.BackupDir, InventoryHostsDir, name)
+	backupName, err := r.deploy.BackupRemote(ctx, address, backupDir, index.Paths())
+	if err != nil {
+		return err
+	}
+	if err := WriteBackupIndex(filepath.Join(backupDir, backupName), index); err != nil {
+		ui.Warning("Failed to write backup index: %v", err)
+	}
+	if err := r.deploy.CleanupBackups(backupDir, r.config.BackupsToKeep); err != nil {
+		ui.Warning("Failed to cleanup old backups: %v", err)
+	}
+	ui.Success("Backup of host %s saved: %s", name, backupName)
+	return nil
+}
@@ -0,0 +1,86 @@
+package reconcile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/config"
+)
+
+const testInventory = `hosts:
+  unraid:
+    labels: [storage]
+  node2:
+    address: deploy@10.0.0.12
+    appdata: /srv/appdata
+    labels: [gpu]
+stacks:
+  media: unraid
+  ollama: gpu
+  ghost: node2
+`
+
+// newInventoryReconciler stages core, media, and ollama stacks, with an
+// override of ollama for node2, under a repo holding testInventory.
+func newInventoryReconciler(t *testing.T) (*Reconciler, string) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.RepoDir = t.TempDir()
+	cfg.StagingDir = t.TempDir()
+	writeFile(t, filepath.Join(cfg.RepoDir, config.InventoryFile), testInventory)
+
+	compose := filepath.Join(cfg.StagingDir, "unraid", "compose")
+	writeFile(t, filepath.Join(compose, "core.yml"), "services:\n  traefik:\n    image: traefik\n")
+	writeFile(t, filepath.Join(compose, "media.yml"), "services:\n  plex:\n    image: plex\n")
+	writeFile(t, filepath.Join(compose, "ollama.yml"), "services:\n  ollama:\n    image: ollama/ollama\n")
+	writeFile(t, filepath.Join(compose, "ollama.node2.yml"), "services:\n  ollama:\n    environment:\n      OLLAMA_GPU: \"1\"\n")
+	return NewReconciler(cfg), compose
+}
+
+func TestReconciler_SplitInventory(t *testing.T) {
+	r, compose := newInventoryReconciler(t)
+
+	require.NoError(t, r.splitInventory())
+	require.NotNil(t, r.inventory)
+	assert.Equal(t, []string{"node2"}, r.secondaryHosts())
+	assert.Equal(t, []string{compose, filepath.Join(r.config.StagingDir, "hosts", "node2", "compose")}, r.stagedComposeDirs())
+
+	assert.FileExists(t, filepath.Join(compose, "core.yml"))
+	assert.FileExists(t, filepath.Join(compose, "media.yml"), "stacks on the primary host stay")
+	assert.NoFileExists(t, filepath.Join(compose, "ollama.yml"))
+	assert.NoFileExists(t, filepath.Join(compose, "ollama.node2.yml"))
+
+	node2 := r.hostComposeDir("node2")
+	assert.FileExists(t, filepath.Join(node2, "ollama.yml"))
+	assert.FileExists(t, filepath.Join(node2, "ollama.node2.yml"), "overrides move with their stack")
+
+	require.NoError(t, r.applyHostOverrides())
+	assert.NoFileExists(t, filepath.Join(node2, "ollama.node2.yml"))
+	merged, err := readComposeMap(filepath.Join(node2, "ollama.yml"))
+	require.NoError(t, err)
+	ollama := merged["services"].(map[string]any)["ollama"].(map[string]any)
+	assert.Equal(t, map[string]any{"OLLAMA_GPU": "1"}, ollama["environment"])
+}
+
+func TestReconciler_SplitInventory_NoInventory(t *testing.T) {
+	r, compose := newInventoryReconciler(t)
+	r.config.RepoDir = t.TempDir()
+
+	require.NoError(t, r.splitInventory())
+	assert.Nil(t, r.inventory)
+	assert.Empty(t, r.secondaryHosts())
+	assert.Equal(t, []string{compose}, r.stagedComposeDirs())
+	assert.FileExists(t, filepath.Join(compose, "ollama.yml"))
+}
+
+func TestReconciler_SplitInventory_RejectsBuilds(t *testing.T) {
+	r, compose := newInventoryReconciler(t)
+	writeFile(t, filepath.Join(compose, "ollama.yml"), "services:\n  ollama:\n    image: ollama-custom\n    build: ./ollama\n")
+
+	err := r.splitInventory()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "builds images")
+}
@@ -0,0 +1,283 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// MaintenanceName names the Traefik router, middleware, and service that
+// serve the maintenance page.
+const MaintenanceName = "bosun-maintenance"
+
+// MaintenancePath is where the backend serves the maintenance page; every
+// request is rewritten to it.
+const MaintenancePath = "/maintenance"
+
+// DefaultMaintenanceURL is the maintenance page backend: the bosun daemon,
+// reached over the proxy network.
+const DefaultMaintenanceURL = "http://bosun:8080"
+
+// maintenancePriority puts the catch-all router above every manifest router.
+const maintenancePriority = 1000000
+
+// TraefikDynamicFile is the Traefik dynamic config, relative to the appdata root.
+const TraefikDynamicFile = "traefik/dynamic.yml"
+
+// EnableMaintenance adds a catch-all router to a Traefik dynamic config that
+// sends every request, HTTP and HTTPS, to the maintenance page at backend.
+func EnableMaintenance(dynamic map[string]any, backend string) {
+	if backend == "" {
+		backend = DefaultMaintenanceURL
+	}
+
+	router := func(tls bool) map[string]any {
+		r := map[string]any{
+			"rule":        "PathPrefix(`/`)",
+			"priority":    maintenancePriority,
+			"service":     MaintenanceName,
+			"middlewares": []any{MaintenanceName},
+		}
+		if tls {
+			r["tls"] = map[string]any{}
+		}
+		return r
+	}
+
+	http := childMap(dynamic, "http")
+	routers := childMap(http, "routers")
+	routers[MaintenanceName] = router(false)
+	routers[MaintenanceName+"-tls"] = router(true)
+	childMap(http, "middlewares")[MaintenanceName] = map[string]any{
+		"replacePath": map[string]any{"path": MaintenancePath},
+	}
+	childMap(http, "services")[MaintenanceName] = map[string]any{
+		"loadBalancer": map[string]any{
+			"servers": []any{map[string]any{"url": backend}},
+		},
+	}
+}
+
+// DisableMaintenance removes the maintenance router from a Traefik dynamic
+// config. Returns whether it was there.
+func DisableMaintenance(dynamic map[string]any) bool {
+	http, _ := dynamic["http"].(map[string]any)
+	if http == nil {
+		return false
+	}
+
+	removed := false
+	for section, names := range map[string][]string{
+		"routers":     {MaintenanceName, MaintenanceName + "-tls"},
+		"middlewares": {MaintenanceName},
+		"services":    {MaintenanceName},
+	} {
+		entries, _ := http[section].(map[string]any)
+		for _, name := range names {
+			if _, ok := entries[name]; ok {
+				delete(entries, name)
+				removed = true
+			}
+		}
+		if entries != nil && len(entries) == 0 {
+			delete(http, section)
+		}
+	}
+	if len(http) == 0 {
+		delete(dynamic, "http")
+	}
+	return removed
+}
+
+// MaintenanceEnabled reports whether a Traefik dynamic config has the
+// maintenance router.
+func MaintenanceEnabled(dynamic map[string]any) bool {
+	http, _ := dynamic["http"].(map[string]any)
+	routers, _ := http["routers"].(map[string]any)
+	_, ok := routers[MaintenanceName]
+	return ok
+}
+
+// SetMaintenanceFile turns the maintenance page on or off in the Traefik
+// dynamic config at path, creating the file if it is missing. The file is
+// left untouched when it is already in the requested state.
+func SetMaintenanceFile(path, backend string, on bool) error {
+	dynamic := make(map[string]any)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &dynamic); err != nil {
+			return fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+		}
+		if dynamic == nil {
+			dynamic = make(map[string]any)
+		}
+	case errors.Is(err, fs.ErrNotExist) && on:
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	default:
+		return err
+	}
+
+	if on {
+		EnableMaintenance(dynamic, backend)
+	} else if !DisableMaintenance(dynamic) {
+		return nil
+	}
+
+	out, err := yaml.Marshal(dynamic)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, manifest.WithHashHeader(out), 0644)
+}
+
+// ReadMaintenance reports whether the maintenance page is on in the Traefik
+// dynamic config at path, on host over SSH when set.
+func (d *DeployOps) ReadMaintenance(ctx context.Context, host, path string) (bool, error) {
+	data, err := d.ReadFile(ctx, host, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	dynamic := make(map[string]any)
+	if err := yaml.Unmarshal(data, &dynamic); err != nil {
+		return false, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return MaintenanceEnabled(dynamic), nil
+}
+
+// SetMaintenance turns the maintenance page on or off in the deployed
+// Traefik dynamic config at path, on host over SSH when set. Traefik's file
+// provider picks up the change without a restart.
+func (d *DeployOps) SetMaintenance(ctx context.Context, host, path, backend string, on bool) error {
+	if d.DryRun {
+		return nil
+	}
+	if host == "" {
+		return SetMaintenanceFile(path, backend, on)
+	}
+
+	tmp, err := os.CreateTemp("", "bosun-dynamic-*.yml")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	data, err := d.ReadFile(ctx, host, path)
+	switch {
+	case err == nil:
+		if err := os.WriteFile(tmp.Name(), data, 0644); err != nil {
+			return err
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if !on {
+			return nil
+		}
+	default:
+		return err
+	}
+
+	if err := SetMaintenanceFile(tmp.Name(), backend, on); err != nil {
+		return err
+	}
+	return d.DeployRemoteFile(ctx, tmp.Name(), host, path)
+}
+
+// ReadFile reads path, on host over SSH when set. A missing remote file
+// wraps fs.ErrNotExist.
+func (d *DeployOps) ReadFile(ctx context.Context, host, path string) ([]byte, error) {
+	if host == "" {
+		return os.ReadFile(path)
+	}
+	if err := validateHost(host); err != nil {
+		return nil, fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	var data []byte
+	err := retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+		script := fmt.Sprintf("if [ -e %[1]s ]; then cat %[1]s; else echo missing >&2; exit 3; fi", shellQuote(path))
+		cmd := exec.CommandContext(ctx, "ssh", host, script)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 3 {
+				return fmt.Errorf("%s on %s: %w", path, host, fs.ErrNotExist)
+			}
+			return fmt.Errorf("ssh cat failed: %w: %s", err, stderr.String())
+		}
+		data = stdout.Bytes()
+		return nil
+	})
+	return data, err
+}
+
+// enableMaintenance puts the maintenance page into the staged Traefik
+// config, so the Traefik sync brings it up before services are reloaded.
+func (r *Reconciler) enableMaintenance(stagingUnraid string) {
+	if !r.config.MaintenancePage || r.config.DryRun || !r.changes.HasStacks() {
+		return
+	}
+	path := filepath.Join(stagingUnraid, "appdata", TraefikDynamicFile)
+	if err := SetMaintenanceFile(path, r.config.MaintenanceURL, true); err != nil {
+		ui.Warning("Could not enable maintenance page: %v", err)
+		return
+	}
+	ui.Info("  Maintenance page enabled")
+}
+
+// disableMaintenance takes the maintenance page down once services have
+// passed the health gate. When they haven't, the page is left up so users
+// keep seeing it instead of errors.
+func (r *Reconciler) disableMaintenance(ctx context.Context, host, appdata string, healthy bool) {
+	if !r.config.MaintenancePage || r.config.DryRun || !r.changes.HasStacks() {
+		return
+	}
+	if !healthy {
+		ui.Warning("Maintenance page left up; run 'bosun maintenance off' once services are healthy")
+		return
+	}
+	if err := r.deploy.SetMaintenance(ctx, host, filepath.Join(appdata, TraefikDynamicFile), "", false); err != nil {
+		ui.Warning("Could not disable maintenance page: %v", err)
+		return
+	}
+	ui.Info("  Maintenance page disabled")
+}
+
+// syncsTraefik reports whether the deploy syncs the Traefik configs: when
+// they changed, or to bring up the maintenance page for a stack reload.
+func (r *Reconciler) syncsTraefik(ctx context.Context, host, staged, deployed string) bool {
+	if r.config.MaintenancePage && !r.config.DryRun && r.changes.HasStacks() {
+		return true
+	}
+	return r.syncsConfig(ctx, host, "traefik", staged, deployed)
+}
+
+// childMap returns m[key] as a map, creating it when missing.
+func childMap(m map[string]any, key string) map[string]any {
+	child, ok := m[key].(map[string]any)
+	if !ok {
+		child = make(map[string]any)
+		m[key] = child
+	}
+	return child
+}
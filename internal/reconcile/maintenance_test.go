@@ -0,0 +1,129 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnableDisableMaintenance(t *testing.T) {
+	dynamic := map[string]any{
+		"http": map[string]any{
+			"routers": map[string]any{
+				"wiki": map[string]any{"rule": "Host(`wiki.example.com`)", "service": "wiki"},
+			},
+		},
+	}
+
+	EnableMaintenance(dynamic, "")
+	assert.True(t, MaintenanceEnabled(dynamic))
+
+	http := dynamic["http"].(map[string]any)
+	routers := http["routers"].(map[string]any)
+	assert.Contains(t, routers, "wiki")
+	assert.Contains(t, routers, MaintenanceName+"-tls")
+	assert.Equal(t, map[string]any{}, routers[MaintenanceName+"-tls"].(map[string]any)["tls"])
+	assert.Equal(t, map[string]any{"replacePath": map[string]any{"path": MaintenancePath}},
+		http["middlewares"].(map[string]any)[MaintenanceName])
+	servers := http["services"].(map[string]any)[MaintenanceName].(map[string]any)["loadBalancer"].(map[string]any)["servers"]
+	assert.Equal(t, []any{map[string]any{"url": DefaultMaintenanceURL}}, servers)
+
+	assert.True(t, DisableMaintenance(dynamic))
+	assert.False(t, MaintenanceEnabled(dynamic))
+	assert.Equal(t, map[string]any{
+		"http": map[string]any{
+			"routers": map[string]any{
+				"wiki": map[string]any{"rule": "Host(`wiki.example.com`)", "service": "wiki"},
+			},
+		},
+	}, dynamic)
+	assert.False(t, DisableMaintenance(dynamic))
+}
+
+func TestSetMaintenanceFile(t *testing.T) {
+	t.Run("on and off", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traefik", "dynamic.yml")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		original := "http:\n  routers:\n    wiki:\n      rule: Host(`wiki.example.com`)\n      service: wiki\n"
+		require.NoError(t, os.WriteFile(path, []byte(original), 0644))
+
+		require.NoError(t, SetMaintenanceFile(path, "http://pages:80", true))
+		on, err := NewDeployOps(false).ReadMaintenance(context.Background(), "", path)
+		require.NoError(t, err)
+		assert.True(t, on)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "http://pages:80")
+
+		require.NoError(t, SetMaintenanceFile(path, "", false))
+		var dynamic, want map[string]any
+		data, err = os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, yaml.Unmarshal(data, &dynamic))
+		require.NoError(t, yaml.Unmarshal([]byte(original), &want))
+		assert.Equal(t, want, dynamic)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traefik", "dynamic.yml")
+
+		require.NoError(t, SetMaintenanceFile(path, "", false))
+		assert.NoFileExists(t, path)
+
+		require.NoError(t, SetMaintenanceFile(path, "", true))
+		on, err := NewDeployOps(false).ReadMaintenance(context.Background(), "", path)
+		require.NoError(t, err)
+		assert.True(t, on)
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dynamic.yml")
+		require.NoError(t, os.WriteFile(path, []byte("http: [unclosed"), 0644))
+		assert.Error(t, SetMaintenanceFile(path, "", true))
+	})
+}
+
+func TestReconcilerMaintenance(t *testing.T) {
+	staging := t.TempDir()
+	appdata := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.MaintenancePage = true
+	r := NewReconciler(cfg)
+
+	r.enableMaintenance(staging)
+	staged := filepath.Join(staging, "appdata", TraefikDynamicFile)
+	on, err := r.deploy.ReadMaintenance(context.Background(), "", staged)
+	require.NoError(t, err)
+	assert.True(t, on, "staged config should carry the maintenance router")
+
+	// Simulate the Traefik sync
+	deployed := filepath.Join(appdata, TraefikDynamicFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(deployed), 0755))
+	data, err := os.ReadFile(staged)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(deployed, data, 0644))
+
+	// Unhealthy services keep the page up
+	r.disableMaintenance(context.Background(), "", appdata, false)
+	on, err = r.deploy.ReadMaintenance(context.Background(), "", deployed)
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	r.disableMaintenance(context.Background(), "", appdata, true)
+	on, err = r.deploy.ReadMaintenance(context.Background(), "", deployed)
+	require.NoError(t, err)
+	assert.False(t, on)
+}
+
+func TestReconcilerMaintenance_Disabled(t *testing.T) {
+	staging := t.TempDir()
+	r := NewReconciler(DefaultConfig())
+
+	r.enableMaintenance(staging)
+	assert.NoFileExists(t, filepath.Join(staging, "appdata", TraefikDynamicFile))
+}
@@ -0,0 +1,119 @@
+package reconcile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// ApplyHostOverrides merges each <stack>.<host>.yml in composeDir over
+// <stack>.yml for the given host label, then removes every override file so
+// none is deployed as a stack of its own. A file is an override only when
+// its base stack file exists. Returns the overrides that were applied.
+//
+// Merging follows manifest.DeepMerge: maps merge recursively, environment
+// and labels merge by key, networks and depends_on are unioned, and other
+// lists (ports, volumes, devices) are replaced.
+func ApplyHostOverrides(composeDir, host string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yml")
+		stack, label, ok := cutLast(name, ".")
+		if !ok {
+			continue
+		}
+		base := filepath.Join(composeDir, stack+".yml")
+		if _, err := os.Stat(base); err != nil {
+			continue
+		}
+
+		if host != "" && strings.EqualFold(label, host) {
+			if err := mergeComposeFile(base, file); err != nil {
+				return nil, fmt.Errorf("apply override %s: %w", filepath.Base(file), err)
+			}
+			applied = append(applied, filepath.Base(file))
+		}
+		if err := os.Remove(file); err != nil {
+			return nil, fmt.Errorf("remove override %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	sort.Strings(applied)
+	return applied, nil
+}
+
+// cutLast splits s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i > 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// mergeComposeFile merges the compose file at overridePath into basePath.
+func mergeComposeFile(basePath, overridePath string) error {
+	base, err := readComposeMap(basePath)
+	if err != nil {
+		return err
+	}
+	override, err := readComposeMap(overridePath)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(manifest.DeepMerge(base, override))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(basePath, data, 0644)
+}
+
+func readComposeMap(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := make(map[string]any)
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return content, nil
+}
+
+// applyHostOverrides applies the deploy host's compose overrides in staging.
+// The host label is Config.HostLabel, or the deploy host's short hostname.
+// Each inventory host's stacks get the overrides for its inventory name.
+func (r *Reconciler) applyHostOverrides() error {
+	host := r.config.HostLabel
+	if host == "" {
+		host, _, _ = strings.Cut(r.hostname, ".")
+	}
+
+	applied, err := ApplyHostOverrides(filepath.Join(r.config.StagingDir, "unraid", "compose"), host)
+	if err != nil {
+		return err
+	}
+	for _, name := range r.secondaryHosts() {
+		hostApplied, err := ApplyHostOverrides(r.hostComposeDir(name), name)
+		if err != nil {
+			return fmt.Errorf("host %s: %w", name, err)
+		}
+		applied = append(applied, hostApplied...)
+	}
+	for _, name := range applied {
+		ui.Info("Applied host override %s", name)
+	}
+	return nil
+}
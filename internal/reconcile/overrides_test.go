@@ -0,0 +1,96 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyHostOverrides(t *testing.T) {
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		files := map[string]string{
+			"media.yml": `services:
+  plex:
+    image: plexinc/pms-docker
+    environment:
+      TZ: UTC
+    devices:
+      - /dev/dri:/dev/dri
+    networks: [proxy]
+`,
+			"media.unraid.yml": `services:
+  plex:
+    environment:
+      PLEX_CLAIM: claim-123
+    devices:
+      - /dev/dri/renderD128:/dev/dri/renderD128
+    networks: [br0]
+`,
+			"media.vps.yml": `services:
+  plex:
+    devices: []
+`,
+			"core.yml":     "services:\n  traefik:\n    image: traefik:v3\n",
+			"my.stack.yml": "services:\n  app:\n    image: app\n",
+		}
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		return dir
+	}
+
+	t.Run("merges the host's override and removes all overrides", func(t *testing.T) {
+		dir := setup(t)
+
+		applied, err := ApplyHostOverrides(dir, "Unraid")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"media.unraid.yml"}, applied)
+
+		data, err := os.ReadFile(filepath.Join(dir, "media.yml"))
+		require.NoError(t, err)
+		var compose map[string]any
+		require.NoError(t, yaml.Unmarshal(data, &compose))
+		plex := compose["services"].(map[string]any)["plex"].(map[string]any)
+		assert.Equal(t, "plexinc/pms-docker", plex["image"])
+		assert.Equal(t, map[string]any{"TZ": "UTC", "PLEX_CLAIM": "claim-123"}, plex["environment"])
+		assert.Equal(t, []any{"/dev/dri/renderD128:/dev/dri/renderD128"}, plex["devices"])
+		assert.ElementsMatch(t, []any{"proxy", "br0"}, plex["networks"])
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		assert.ElementsMatch(t, []string{"core.yml", "media.yml", "my.stack.yml"}, names)
+	})
+
+	t.Run("no host label only removes overrides", func(t *testing.T) {
+		dir := setup(t)
+		before, err := os.ReadFile(filepath.Join(dir, "media.yml"))
+		require.NoError(t, err)
+
+		applied, err := ApplyHostOverrides(dir, "")
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+
+		after, err := os.ReadFile(filepath.Join(dir, "media.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+		assert.NoFileExists(t, filepath.Join(dir, "media.vps.yml"))
+		assert.FileExists(t, filepath.Join(dir, "my.stack.yml"), "dotted stack names without a base are stacks")
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		dir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "core.unraid.yml"), []byte("services: [\n"), 0644))
+
+		_, err := ApplyHostOverrides(dir, "unraid")
+		assert.ErrorContains(t, err, "apply override core.unraid.yml")
+	})
+}
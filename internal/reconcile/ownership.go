@@ -0,0 +1,153 @@
+package reconcile
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OwnershipRule sets the owner (and optionally file mode) of a deployed path
+// after sync. Rendered files are written as root, which breaks containers
+// running as non-root users.
+type OwnershipRule struct {
+	// Path is relative to the appdata root (e.g. "traefik" or "gatus/config.yaml").
+	// Directories are applied recursively.
+	Path string
+	// UID and GID are the numeric owner to set.
+	UID int
+	GID int
+	// Mode is applied to regular files when non-zero. Directories keep their mode.
+	Mode os.FileMode
+}
+
+// String formats the rule in the same syntax ParseOwnershipRules accepts.
+func (o OwnershipRule) String() string {
+	s := fmt.Sprintf("%s=%d:%d", o.Path, o.UID, o.GID)
+	if o.Mode != 0 {
+		s += fmt.Sprintf(":%04o", o.Mode)
+	}
+	return s
+}
+
+// ParseOwnershipRules parses a comma-separated list of path=uid:gid[:mode]
+// entries, e.g. "traefik=1000:1000,gatus=568:568:0640".
+func ParseOwnershipRules(s string) ([]OwnershipRule, error) {
+	var rules []OwnershipRule
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		path, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ownership entry %q (expected path=uid:gid[:mode])", entry)
+		}
+
+		path = filepath.Clean(strings.TrimSpace(path))
+		if !filepath.IsLocal(path) || path == "." {
+			return nil, fmt.Errorf("invalid ownership path %q: must be relative to appdata", path)
+		}
+
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid ownership entry %q (expected path=uid:gid[:mode])", entry)
+		}
+
+		uid, err := strconv.Atoi(parts[0])
+		if err != nil || uid < 0 {
+			return nil, fmt.Errorf("invalid uid %q in ownership entry %q", parts[0], entry)
+		}
+		gid, err := strconv.Atoi(parts[1])
+		if err != nil || gid < 0 {
+			return nil, fmt.Errorf("invalid gid %q in ownership entry %q", parts[1], entry)
+		}
+
+		rule := OwnershipRule{Path: path, UID: uid, GID: gid}
+		if len(parts) == 3 {
+			mode, err := strconv.ParseUint(parts[2], 8, 32)
+			if err != nil || mode == 0 || mode > 0777 {
+				return nil, fmt.Errorf("invalid mode %q in ownership entry %q (expected octal, e.g. 0640)", parts[2], entry)
+			}
+			rule.Mode = os.FileMode(mode)
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// FileMode returns the mode the rule gives the file at rel, relative to the
+// appdata root: the rule's Mode, capped by the permission rule covering the
+// file, so ownership never loosens a file the permission check guards.
+func (o OwnershipRule) FileMode(rel string, perms []PermissionRule) os.FileMode {
+	if perm, ok := matchPermissionRule(perms, rel); ok {
+		return o.Mode & perm.Mode
+	}
+	return o.Mode
+}
+
+// chmodCommand returns a shell command that sets the mode of the regular
+// files under the rule's path below root, as FileMode does.
+func (o OwnershipRule) chmodCommand(root string, perms []PermissionRule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "find %s -type f \\(", shellQuote(path.Join(root, o.Path)))
+	for _, perm := range perms {
+		if mode := o.Mode & perm.Mode; mode != o.Mode {
+			fmt.Fprintf(&b, " \\( -path %s -exec chmod %04o {} + \\) -o", shellQuote(path.Join(root, perm.Pattern)), mode)
+		}
+	}
+	fmt.Fprintf(&b, " -exec chmod %04o {} + \\)", o.Mode)
+	return b.String()
+}
+
+// ApplyOwnership applies ownership rules to local paths under root.
+// Rules whose path does not exist are skipped. File modes are capped by
+// perms (see FileMode).
+func ApplyOwnership(root string, rules []OwnershipRule, perms []PermissionRule) error {
+	for _, rule := range rules {
+		target := filepath.Join(root, rule.Path)
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := os.Lchown(path, rule.UID, rule.GID); err != nil {
+				return err
+			}
+			if rule.Mode != 0 && d.Type().IsRegular() {
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				return os.Chmod(path, rule.FileMode(filepath.ToSlash(rel), perms))
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("apply ownership %s: %w", rule, err)
+		}
+	}
+	return nil
+}
+
+// expectOwnership updates a permissions snapshot so files covered by an
+// ownership rule are expected to end up with the rule's owner rather than
+// their pre-sync owner.
+func expectOwnership(attrs map[string]FileAttrs, rules []OwnershipRule) {
+	for path, a := range attrs {
+		for _, rule := range rules {
+			if path == rule.Path || strings.HasPrefix(path, rule.Path+string(filepath.Separator)) {
+				a.UID, a.GID = rule.UID, rule.GID
+				attrs[path] = a
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOwnershipRules(t *testing.T) {
+	t.Run("valid entries", func(t *testing.T) {
+		rules, err := ParseOwnershipRules(" traefik=1000:1000, gatus/config.yaml=568:568:0640 ,")
+		require.NoError(t, err)
+		assert.Equal(t, []OwnershipRule{
+			{Path: "traefik", UID: 1000, GID: 1000},
+			{Path: "gatus/config.yaml", UID: 568, GID: 568, Mode: 0640},
+		}, rules)
+		assert.Equal(t, "gatus/config.yaml=568:568:0640", rules[1].String())
+	})
+
+	t.Run("empty string", func(t *testing.T) {
+		rules, err := ParseOwnershipRules("")
+		require.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	invalid := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing equals", "traefik:1000:1000", "expected path=uid:gid"},
+		{"missing gid", "traefik=1000", "expected path=uid:gid"},
+		{"absolute path", "/etc=0:0", "must be relative"},
+		{"traversal", "../etc=0:0", "must be relative"},
+		{"appdata root", ".=0:0", "must be relative"},
+		{"bad uid", "traefik=abc:1000", "invalid uid"},
+		{"negative gid", "traefik=1000:-1", "invalid gid"},
+		{"non-octal mode", "traefik=1000:1000:0899", "invalid mode"},
+		{"mode out of range", "traefik=1000:1000:7777", "invalid mode"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseOwnershipRules(tc.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestApplyOwnership(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "gatus")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("a"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "extra.yaml"), []byte("b"), 0600))
+
+	// Chown to ourselves works without root; the mode change is what we can observe.
+	uid, gid := os.Getuid(), os.Getgid()
+	rules := []OwnershipRule{
+		{Path: "gatus", UID: uid, GID: gid, Mode: 0640},
+		{Path: "missing", UID: uid, GID: gid},
+	}
+	require.NoError(t, ApplyOwnership(root, rules, nil))
+
+	for _, f := range []string{"config.yaml", filepath.Join("nested", "extra.yaml")} {
+		info, err := os.Stat(filepath.Join(dir, f))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), f)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "nested"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm(), "directories keep their mode")
+}
+
+func TestApplyOwnershipKeepsPermissionRules(t *testing.T) {
+	root := t.TempDir()
+	acme := filepath.Join(root, "traefik", "acme.json")
+	rules := filepath.Join(root, "traefik", "rules.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(acme), 0755))
+	require.NoError(t, os.WriteFile(acme, []byte("{}"), 0600))
+	require.NoError(t, os.WriteFile(rules, []byte("a"), 0600))
+
+	before := SnapshotPermissions(root, DefaultPermissionRules)
+	uid, gid := os.Getuid(), os.Getgid()
+	require.NoError(t, ApplyOwnership(root, []OwnershipRule{{Path: "traefik", UID: uid, GID: gid, Mode: 0644}}, DefaultPermissionRules))
+
+	info, err := os.Stat(acme)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "permission rule caps the ownership mode")
+	info, err = os.Stat(rules)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	assert.Empty(t, ComparePermissions(DefaultPermissionRules, before, SnapshotPermissions(root, DefaultPermissionRules)))
+}
+
+func TestOwnershipChmodCommand(t *testing.T) {
+	rule := OwnershipRule{Path: "traefik", Mode: 0644}
+	perms := []PermissionRule{{Pattern: "traefik/acme.json", Mode: 0600}, {Pattern: "compose/*.env", Mode: 0644}}
+
+	assert.Equal(t,
+		`find '/appdata/traefik' -type f \( \( -path '/appdata/traefik/acme.json' -exec chmod 0600 {} + \) -o -exec chmod 0644 {} + \)`,
+		rule.chmodCommand("/appdata", perms))
+}
+
+func TestExpectOwnership(t *testing.T) {
+	attrs := map[string]FileAttrs{
+		"traefik/acme.json":          {Mode: 0600, UID: 0, GID: 0},
+		"traefik-old/acme.json":      {Mode: 0600, UID: 0, GID: 0},
+		"authelia/configuration.yml": {Mode: 0600, UID: 99, GID: 100},
+	}
+
+	expectOwnership(attrs, []OwnershipRule{{Path: "traefik", UID: 1000, GID: 1000}})
+
+	assert.Equal(t, 1000, attrs["traefik/acme.json"].UID)
+	assert.Equal(t, 0, attrs["traefik-old/acme.json"].UID)
+	assert.Equal(t, 99, attrs["authelia/configuration.yml"].UID)
+}
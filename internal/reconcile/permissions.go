@@ -0,0 +1,193 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PermissionRule requires files matching a glob under appdata to be no more
+// permissive than Mode.
+type PermissionRule struct {
+	// Pattern is a glob relative to the appdata root (e.g. "traefik/acme.json").
+	Pattern string
+	// Mode is the most permissive mode allowed. Any extra bit is a regression.
+	Mode os.FileMode
+}
+
+// DefaultPermissionRules covers the secrets-bearing files bosun syncs.
+// Traefik refuses to use an acme.json readable by group or others.
+var DefaultPermissionRules = []PermissionRule{
+	{Pattern: "traefik/acme.json", Mode: 0600},
+	{Pattern: "authelia/configuration.yml", Mode: 0600},
+	{Pattern: "authelia/users_database.yml", Mode: 0600},
+	{Pattern: "compose/.env", Mode: 0600},
+	{Pattern: "compose/*.env", Mode: 0600},
+}
+
+// FileAttrs holds the permission-relevant attributes of a file.
+type FileAttrs struct {
+	Mode os.FileMode
+	UID  int
+	GID  int
+}
+
+// PermissionIssue is a permission or ownership regression on a sensitive file.
+type PermissionIssue struct {
+	// Path is the file path relative to the appdata root.
+	Path string
+	// Problem describes the regression.
+	Problem string
+	// Want is the attributes the file should have after repair.
+	Want FileAttrs
+	// ModeChanged is true if the mode needs repair.
+	ModeChanged bool
+	// OwnerChanged is true if the owner needs repair.
+	OwnerChanged bool
+	// Repaired is true once the regression has been fixed.
+	Repaired bool
+}
+
+// String formats the issue for logs and alerts.
+func (i PermissionIssue) String() string {
+	if i.Repaired {
+		return fmt.Sprintf("%s: %s (repaired)", i.Path, i.Problem)
+	}
+	return fmt.Sprintf("%s: %s", i.Path, i.Problem)
+}
+
+// ComparePermissions checks current file attributes against rules and the
+// attributes recorded before the sync. Mode bits beyond a rule's Mode are a
+// regression, as is an owner that changed during the sync. Files with no
+// "before" entry (newly created) are only checked against the rules.
+func ComparePermissions(rules []PermissionRule, before, after map[string]FileAttrs) []PermissionIssue {
+	var issues []PermissionIssue
+
+	for _, path := range sortedAttrPaths(after) {
+		cur := after[path]
+		rule, ok := matchPermissionRule(rules, path)
+		if !ok {
+			continue
+		}
+
+		issue := PermissionIssue{Path: path, Want: cur}
+		var problems []string
+
+		if extra := cur.Mode.Perm() &^ rule.Mode; extra != 0 {
+			issue.ModeChanged = true
+			issue.Want.Mode = cur.Mode.Perm() & rule.Mode
+			problems = append(problems, fmt.Sprintf("mode %04o is looser than %04o", cur.Mode.Perm(), rule.Mode))
+		}
+
+		if prev, ok := before[path]; ok && prev.UID >= 0 && cur.UID >= 0 &&
+			(prev.UID != cur.UID || prev.GID != cur.GID) {
+			issue.OwnerChanged = true
+			issue.Want.UID, issue.Want.GID = prev.UID, prev.GID
+			problems = append(problems, fmt.Sprintf("owner changed from %d:%d to %d:%d", prev.UID, prev.GID, cur.UID, cur.GID))
+		}
+
+		if len(problems) == 0 {
+			continue
+		}
+		issue.Problem = strings.Join(problems, ", ")
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// SnapshotPermissions records the attributes of local files matching rules under root.
+// Missing files are skipped. UID and GID are -1 where ownership is unavailable.
+func SnapshotPermissions(root string, rules []PermissionRule) map[string]FileAttrs {
+	attrs := make(map[string]FileAttrs)
+	for _, rule := range rules {
+		matches, _ := filepath.Glob(filepath.Join(root, rule.Pattern))
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			uid, gid, ok := fileOwner(info)
+			if !ok {
+				uid, gid = -1, -1
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				continue
+			}
+			attrs[rel] = FileAttrs{Mode: info.Mode().Perm(), UID: uid, GID: gid}
+		}
+	}
+	return attrs
+}
+
+// RepairPermissions restores mode and ownership of local files under root.
+// Issues are updated in place; those that could not be repaired stay
+// unrepaired, and their errors are returned joined.
+func RepairPermissions(root string, issues []PermissionIssue) error {
+	var errs []error
+	for i := range issues {
+		issue := &issues[i]
+		path := filepath.Join(root, issue.Path)
+
+		if issue.ModeChanged {
+			if err := os.Chmod(path, issue.Want.Mode); err != nil {
+				errs = append(errs, fmt.Errorf("repair %s: %w", issue.Path, err))
+				continue
+			}
+		}
+		if issue.OwnerChanged {
+			if err := os.Lchown(path, issue.Want.UID, issue.Want.GID); err != nil {
+				errs = append(errs, fmt.Errorf("repair %s: %w", issue.Path, err))
+				continue
+			}
+		}
+		issue.Repaired = true
+	}
+	return errors.Join(errs...)
+}
+
+// shellGlob quotes a glob for a POSIX shell, leaving its * and ? wildcards
+// unquoted so the shell still expands them.
+func shellGlob(pattern string) string {
+	var b strings.Builder
+	literal := ""
+	for _, r := range pattern {
+		if r == '*' || r == '?' {
+			if literal != "" {
+				b.WriteString(shellQuote(literal))
+				literal = ""
+			}
+			b.WriteRune(r)
+			continue
+		}
+		literal += string(r)
+	}
+	if literal != "" {
+		b.WriteString(shellQuote(literal))
+	}
+	return b.String()
+}
+
+// matchPermissionRule returns the first rule whose pattern matches path.
+func matchPermissionRule(rules []PermissionRule, path string) (PermissionRule, bool) {
+	for _, rule := range rules {
+		if ok, _ := filepath.Match(rule.Pattern, path); ok {
+			return rule, true
+		}
+	}
+	return PermissionRule{}, false
+}
+
+// sortedAttrPaths returns the paths of attrs in sorted order for stable output.
+func sortedAttrPaths(attrs map[string]FileAttrs) []string {
+	paths := make([]string, 0, len(attrs))
+	for p := range attrs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
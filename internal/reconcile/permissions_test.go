@@ -0,0 +1,102 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePermissions(t *testing.T) {
+	rules := DefaultPermissionRules
+
+	t.Run("loosened mode is a regression", func(t *testing.T) {
+		after := map[string]FileAttrs{"traefik/acme.json": {Mode: 0644, UID: 0, GID: 0}}
+
+		issues := ComparePermissions(rules, nil, after)
+		require.Len(t, issues, 1)
+		assert.True(t, issues[0].ModeChanged)
+		assert.False(t, issues[0].OwnerChanged)
+		assert.Equal(t, os.FileMode(0600), issues[0].Want.Mode)
+		assert.Equal(t, "traefik/acme.json: mode 0644 is looser than 0600", issues[0].String())
+	})
+
+	t.Run("stricter mode is fine", func(t *testing.T) {
+		after := map[string]FileAttrs{"compose/app.env": {Mode: 0400}}
+		assert.Empty(t, ComparePermissions(rules, nil, after))
+	})
+
+	t.Run("owner change is a regression", func(t *testing.T) {
+		before := map[string]FileAttrs{"authelia/configuration.yml": {Mode: 0600, UID: 99, GID: 100}}
+		after := map[string]FileAttrs{"authelia/configuration.yml": {Mode: 0600, UID: 0, GID: 0}}
+
+		issues := ComparePermissions(rules, before, after)
+		require.Len(t, issues, 1)
+		assert.True(t, issues[0].OwnerChanged)
+		assert.Equal(t, 99, issues[0].Want.UID)
+		assert.Equal(t, 100, issues[0].Want.GID)
+		assert.Contains(t, issues[0].Problem, "owner changed from 99:100 to 0:0")
+	})
+
+	t.Run("unknown ownership is not compared", func(t *testing.T) {
+		before := map[string]FileAttrs{"compose/.env": {Mode: 0600, UID: -1, GID: -1}}
+		after := map[string]FileAttrs{"compose/.env": {Mode: 0600, UID: -1, GID: -1}}
+		assert.Empty(t, ComparePermissions(rules, before, after))
+	})
+
+	t.Run("files without a rule are ignored", func(t *testing.T) {
+		after := map[string]FileAttrs{"gatus/config.yaml": {Mode: 0666}}
+		assert.Empty(t, ComparePermissions(rules, nil, after))
+	})
+}
+
+func TestSnapshotAndRepairPermissions(t *testing.T) {
+	root := t.TempDir()
+	acme := filepath.Join(root, "traefik", "acme.json")
+	env := filepath.Join(root, "compose", "media.env")
+	require.NoError(t, os.MkdirAll(filepath.Dir(acme), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(env), 0755))
+	require.NoError(t, os.WriteFile(acme, []byte("{}"), 0600))
+	require.NoError(t, os.WriteFile(env, []byte("A=1"), 0600))
+
+	before := SnapshotPermissions(root, DefaultPermissionRules)
+	require.Len(t, before, 2)
+	assert.Equal(t, os.FileMode(0600), before[filepath.Join("traefik", "acme.json")].Mode)
+
+	// Simulate a sync loosening both files.
+	require.NoError(t, os.Chmod(acme, 0644))
+	require.NoError(t, os.Chmod(env, 0664))
+
+	issues := ComparePermissions(DefaultPermissionRules, before, SnapshotPermissions(root, DefaultPermissionRules))
+	require.Len(t, issues, 2)
+
+	require.NoError(t, RepairPermissions(root, issues))
+	for _, issue := range issues {
+		assert.True(t, issue.Repaired, issue.Path)
+	}
+
+	for _, path := range []string{acme, env} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), path)
+	}
+
+	assert.Empty(t, ComparePermissions(DefaultPermissionRules, before, SnapshotPermissions(root, DefaultPermissionRules)))
+}
+
+func TestShellGlob(t *testing.T) {
+	assert.Equal(t, "'compose/'*'.env'", shellGlob("compose/*.env"))
+	assert.Equal(t, "'my app/'?", shellGlob("my app/?"))
+	assert.Equal(t, `'it'\''s.json'`, shellGlob("it's.json"))
+}
+
+func TestParseStatOutput(t *testing.T) {
+	output := "600 0 0 traefik/acme.json\n644 99 100 compose/my app.env\nstat: garbage\n"
+
+	attrs := parseStatOutput(output)
+	require.Len(t, attrs, 2)
+	assert.Equal(t, FileAttrs{Mode: 0600, UID: 0, GID: 0}, attrs["traefik/acme.json"])
+	assert.Equal(t, FileAttrs{Mode: 0644, UID: 99, GID: 100}, attrs["compose/my app.env"])
+}
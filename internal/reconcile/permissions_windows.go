@@ -0,0 +1,10 @@
+//go:build windows
+
+package reconcile
+
+import "os"
+
+// fileOwner reports ownership as unavailable; Windows has no UID/GID.
+func fileOwner(_ os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
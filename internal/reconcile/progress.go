@@ -0,0 +1,36 @@
+package reconcile
+
+import "fmt"
+
+// Reconcile steps reported to progress callbacks, in the order they run.
+const (
+	StepSync    = "sync"
+	StepDecrypt = "decrypt"
+	StepRender  = "render"
+	StepLint    = "lint"
+	StepBackup  = "backup"
+	StepDeploy  = "deploy"
+	StepVerify  = "verify"
+)
+
+// Progress describes a reconcile step as it starts.
+type Progress struct {
+	Step    string
+	Message string
+}
+
+// WithProgress sets a callback invoked as each reconcile step starts, so
+// callers such as the daemon's streaming API can report progress. The
+// callback runs on the reconcile goroutine and must not block.
+func WithProgress(fn func(Progress)) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.onProgress = fn
+	}
+}
+
+// progress reports the start of a step to the progress callback, if any.
+func (r *Reconciler) progress(step, format string, args ...any) {
+	if r.onProgress != nil {
+		r.onProgress(Progress{Step: step, Message: fmt.Sprintf(format, args...)})
+	}
+}
@@ -0,0 +1,294 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// ComposeProject is a compose project known to a Docker engine, as reported
+// by docker compose ls from its container labels.
+type ComposeProject struct {
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	ConfigFiles []string `json:"config_files"`
+}
+
+// Stacks returns the base names of the project's compose files, which is
+// how bosun names the stack a rendered compose file deploys.
+func (p ComposeProject) Stacks() []string {
+	stacks := make([]string, 0, len(p.ConfigFiles))
+	for _, f := range p.ConfigFiles {
+		stacks = append(stacks, strings.TrimSuffix(filepath.Base(f), filepath.Ext(f)))
+	}
+	return stacks
+}
+
+// ComposeProjectName returns the compose project a rendered compose file is
+// deployed as: its stack name, normalized as compose normalizes project names.
+func ComposeProjectName(composeFile string) string {
+	return normalizeProjectName(strings.TrimSuffix(filepath.Base(composeFile), filepath.Ext(composeFile)))
+}
+
+// LegacyComposeProject returns the project compose derives when no -p is
+// given: the compose file's directory name. Deploys before per-stack projects
+// ran every stack under it.
+func LegacyComposeProject(composeFile string) string {
+	return normalizeProjectName(filepath.Base(filepath.Dir(composeFile)))
+}
+
+// normalizeProjectName lowercases name and drops characters compose does not
+// allow in project names.
+func normalizeProjectName(name string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' {
+			b.WriteRune(c)
+		}
+	}
+	return strings.TrimLeft(b.String(), "-_")
+}
+
+// ListComposeProjects lists every compose project, running or stopped, on
+// host's Docker engine ("" for local).
+func ListComposeProjects(ctx context.Context, host string) ([]ComposeProject, error) {
+	ctx, cancel := context.WithTimeout(ctx, SSHTimeout)
+	defer cancel()
+
+	out, err := dockerOutput(ctx, host, "compose", "ls", "--all", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseComposeLs(out)
+}
+
+// parseComposeLs parses docker compose ls --format json output.
+func parseComposeLs(data []byte) ([]ComposeProject, error) {
+	var raw []struct {
+		Name        string `json:"Name"`
+		Status      string `json:"Status"`
+		ConfigFiles string `json:"ConfigFiles"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse compose projects: %w", err)
+	}
+
+	projects := make([]ComposeProject, 0, len(raw))
+	for _, r := range raw {
+		p := ComposeProject{Name: r.Name, Status: r.Status}
+		for _, f := range strings.Split(r.ConfigFiles, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				p.ConfigFiles = append(p.ConfigFiles, f)
+			}
+		}
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+// ComposeProjectConfig returns a project's merged compose configuration,
+// with relative paths resolved but ${...} variables left as written. The
+// compose files are read where they live: on host over SSH, or locally.
+func ComposeProjectConfig(ctx context.Context, host string, p ComposeProject) (map[string]any, error) {
+	if len(p.ConfigFiles) == 0 {
+		return nil, fmt.Errorf("compose project %s has no config files", p.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SSHTimeout)
+	defer cancel()
+
+	args := []string{"compose", "-p", p.Name}
+	for _, f := range p.ConfigFiles {
+		args = append(args, "-f", f)
+	}
+	args = append(args, "config", "--no-interpolate")
+
+	var cmd *exec.Cmd
+	if host == "" {
+		cmd = exec.CommandContext(ctx, "docker", args...)
+	} else {
+		if err := validateHost(host); err != nil {
+			return nil, fmt.Errorf("invalid SSH host: %w", err)
+		}
+		quoted := make([]string, len(args))
+		for i, a := range args {
+			quoted[i] = shellQuote(a)
+		}
+		cmd = exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", host, "docker "+strings.Join(quoted, " "))
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose config: %w: %s", err, lastLines(stderr.String(), 20))
+	}
+
+	content := make(map[string]any)
+	if err := yaml.Unmarshal(out, &content); err != nil {
+		return nil, fmt.Errorf("parse compose config: %w", err)
+	}
+	return content, nil
+}
+
+// dockerOutput runs docker against host ("" for local) and returns stdout.
+func dockerOutput(ctx context.Context, host string, args ...string) ([]byte, error) {
+	name := args[0]
+	if host != "" {
+		if err := validateHost(host); err != nil {
+			return nil, fmt.Errorf("invalid SSH host: %w", err)
+		}
+		args = append([]string{"-H", "ssh://" + host}, args...)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker %s: %w: %s", name, err, lastLines(stderr.String(), 20))
+	}
+	return out, nil
+}
+
+// MigrateLegacyProject takes down composeFile's services in the shared
+// legacy project (see LegacyComposeProject) when that project still runs the
+// stack, so compose up can recreate them under the stack's own project
+// without container name conflicts.
+func (d *DeployOps) MigrateLegacyProject(ctx context.Context, composeFile string, projects []ComposeProject) (bool, error) {
+	legacy := LegacyComposeProject(composeFile)
+	stack := strings.TrimSuffix(filepath.Base(composeFile), filepath.Ext(composeFile))
+	if d.DryRun || legacy == ComposeProjectName(composeFile) {
+		return false, nil
+	}
+
+	for _, p := range projects {
+		if p.Name != legacy || !slices.Contains(p.Stacks(), stack) {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, "docker", "compose", "-p", legacy, "-f", composeFile, "down")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return false, fmt.Errorf("docker compose down (project %s): %w: %s", legacy, err, lastLines(stderr.String(), 20))
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// stackComposeFiles returns the stack compose files in composeDir, core
+// first so the stacks that others depend on (Traefik, shared networks) come
+// up before them.
+func stackComposeFiles(composeDir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		iCore := filepath.Base(files[i]) == CoreBackupStack+".yml"
+		jCore := filepath.Base(files[j]) == CoreBackupStack+".yml"
+		return iCore && !jCore
+	})
+	return files, nil
+}
+
+// reloadStacks runs compose up, with rollback, for every stack in
+// composeDir the run's changes touch, each as its own compose project so a
+// change to one stack only recreates its own containers. Stacks come up in
+// dependency order (see stackDependencies), and a stack is skipped when one
+// it depends on did not come up healthy. A failed stack does not stop the
+// others; healthy reports whether every stack reloaded ended up running
+// (deployed or rolled back).
+func (r *Reconciler) reloadStacks(ctx context.Context, composeDir string) (healthy bool, err error) {
+	files, err := stackComposeFiles(composeDir)
+	if err != nil {
+		return false, err
+	}
+
+	deps, depsErr := stackDependencies(files)
+	if depsErr != nil {
+		ui.Warning("Could not read stack dependencies, reloading in file order: %v", depsErr)
+	}
+	files, cycle := orderStacks(files, deps)
+	if len(cycle) > 0 {
+		ui.Warning("Stacks %s depend on each other, reloading them in file order", strings.Join(cycle, ", "))
+	}
+
+	projects, listErr := r.listProjects(ctx, "")
+	if listErr != nil {
+		ui.Warning("Could not list compose projects, skipping legacy project migration: %v", listErr)
+	}
+
+	var errs []error
+	unhealthy := make(map[string]bool) // Stacks that failed and are not running
+	blocked := make(map[string]bool)   // Stacks whose dependents must wait: unhealthy or skipped
+	for _, file := range files {
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		if !r.changes.HasStack(stack) {
+			continue
+		}
+		if waitingOn := blockingDependencies(deps[stack], blocked); len(waitingOn) > 0 {
+			ui.Warning("  Skipping stack %s: %s not healthy", stack, strings.Join(waitingOn, ", "))
+			errs = append(errs, fmt.Errorf("stack %s: %w (%s)", stack, ErrDependencyUnhealthy, strings.Join(waitingOn, ", ")))
+			blocked[stack] = true
+			continue
+		}
+		if migrated, err := r.deploy.MigrateLegacyProject(ctx, file, projects); err != nil {
+			ui.Warning("Could not migrate stack %s from project %s: %v", stack, LegacyComposeProject(file), err)
+		} else if migrated {
+			ui.Info("  Moved stack %s from project %s to its own project", stack, LegacyComposeProject(file))
+		}
+
+		ui.Info("  Reloading stack %s...", stack)
+		err := r.deploy.ComposeUpWithRollback(ctx, file, r.lastBackupPath)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("stack %s: %w", stack, err))
+		switch {
+		case !errors.Is(err, ErrRollbackSucceeded):
+			unhealthy[stack] = true
+		case hasDependents(deps, stack) && !r.stackHealthy(ctx, file):
+			// The rollback comes up without --wait; its dependents start
+			// only once it is healthy.
+			ui.Warning("  Stack %s is not healthy after rollback", stack)
+			unhealthy[stack] = true
+		}
+		if unhealthy[stack] {
+			blocked[stack] = true
+		}
+		// Without Docker the remaining stacks would fail the same way.
+		if errors.Is(err, ErrDockerUnavailable) {
+			break
+		}
+	}
+
+	return len(unhealthy) == 0, errors.Join(errs...)
+}
+
+// stackHealthy waits for a stack's containers to settle and reports whether
+// they are all healthy.
+func (r *Reconciler) stackHealthy(ctx context.Context, file string) bool {
+	services, err := r.deploy.WaitForHealth(ctx, file, HealthWaitTimeout)
+	if err != nil {
+		return false
+	}
+	for _, s := range services {
+		if !s.Healthy() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComposeLs(t *testing.T) {
+	data := []byte(`[
+  {"Name":"apps","Status":"running(3)","ConfigFiles":"/mnt/user/appdata/compose/apps.yml"},
+  {"Name":"immich","Status":"exited(1), running(3)","ConfigFiles":"/opt/immich/docker-compose.yml,/opt/immich/docker-compose.override.yml"}
+]`)
+
+	projects, err := parseComposeLs(data)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, ComposeProject{Name: "apps", Status: "running(3)", ConfigFiles: []string{"/mnt/user/appdata/compose/apps.yml"}}, projects[0])
+	assert.Equal(t, []string{"docker-compose", "docker-compose.override"}, projects[1].Stacks())
+
+	_, err = parseComposeLs([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestComposeProjectName(t *testing.T) {
+	assert.Equal(t, "core", ComposeProjectName("/mnt/appdata/compose/core.yml"))
+	assert.Equal(t, "my-stack", ComposeProjectName("/mnt/appdata/compose/My-Stack.yml"))
+	assert.Equal(t, "mediastack", ComposeProjectName("media.stack.yml"))
+	assert.Equal(t, "compose", LegacyComposeProject("/mnt/appdata/compose/core.yml"))
+}
+
+func TestStackComposeFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"apps.yml", "core.yml", "media.yml", "core.env"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0644))
+	}
+
+	files, err := stackComposeFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "core.yml"),
+		filepath.Join(dir, "apps.yml"),
+		filepath.Join(dir, "media.yml"),
+	}, files)
+}
+
+func TestDeployOps_MigrateLegacyProject(t *testing.T) {
+	ctx := context.Background()
+	projects := []ComposeProject{{Name: "compose", ConfigFiles: []string{"/mnt/appdata/compose/core.yml"}}}
+
+	t.Run("dry run", func(t *testing.T) {
+		migrated, err := NewDeployOps(true).MigrateLegacyProject(ctx, "/mnt/appdata/compose/core.yml", projects)
+		require.NoError(t, err)
+		assert.False(t, migrated)
+	})
+
+	t.Run("stack not in the legacy project", func(t *testing.T) {
+		migrated, err := NewDeployOps(false).MigrateLegacyProject(ctx, "/mnt/appdata/compose/media.yml", projects)
+		require.NoError(t, err)
+		assert.False(t, migrated)
+	})
+
+	t.Run("already its own project", func(t *testing.T) {
+		migrated, err := NewDeployOps(false).MigrateLegacyProject(ctx, "/srv/core/core.yml", []ComposeProject{{Name: "core", ConfigFiles: []string{"/srv/core/core.yml"}}})
+		require.NoError(t, err)
+		assert.False(t, migrated)
+	})
+}
+
+func TestReconciler_ReloadStacks(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"apps.yml", "core.yml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0644))
+	}
+
+	cfg := DefaultConfig()
+	cfg.Chaos = &Chaos{Rates: map[string]float64{ChaosComposeUp: 1}}
+	r := NewReconciler(cfg)
+	r.listProjects = func(ctx context.Context, host string) ([]ComposeProject, error) {
+		return nil, errors.New("docker unavailable")
+	}
+
+	// Every stack is attempted even though the first fails
+	healthy, err := r.reloadStacks(context.Background(), dir)
+	require.Error(t, err)
+	assert.False(t, healthy)
+	assert.True(t, errors.Is(err, ErrChaos))
+	assert.Contains(t, err.Error(), "stack core:")
+	assert.Contains(t, err.Error(), "stack apps:")
+}
+
+func TestReconciler_ReloadStacks_OnlyChanged(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"apps.yml", "core.yml", "media.yml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0644))
+	}
+
+	cfg := DefaultConfig()
+	cfg.Chaos = &Chaos{Rates: map[string]float64{ChaosComposeUp: 1}}
+	r := NewReconciler(cfg)
+	r.listProjects = func(ctx context.Context, host string) ([]ComposeProject, error) {
+		return nil, nil
+	}
+	r.changes = &ChangeSet{Stacks: []string{"apps", "media"}}
+
+	_, err := r.reloadStacks(context.Background(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stack apps:")
+	assert.Contains(t, err.Error(), "stack media:")
+	assert.NotContains(t, err.Error(), "stack core:")
+}
@@ -0,0 +1,158 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/verify"
+)
+
+// ReadinessCheck is a readiness probe bound to the compose service that
+// declared it.
+type ReadinessCheck struct {
+	Service   string
+	Container string // Container that exec probes run in
+	manifest.ReadinessProbe
+}
+
+// LoadReadiness returns the readiness probes declared in a rendered compose
+// file (see manifest.ReadinessExtension), sorted by service.
+func LoadReadiness(composeFile string) ([]ReadinessCheck, error) {
+	data, err := os.ReadFile(composeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var compose struct {
+		Services map[string]struct {
+			ContainerName string                   `yaml:"container_name"`
+			Readiness     *manifest.ReadinessProbe `yaml:"x-bosun-readiness"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", composeFile, err)
+	}
+
+	var checks []ReadinessCheck
+	for name, svc := range compose.Services {
+		if svc.Readiness == nil {
+			continue
+		}
+		if err := svc.Readiness.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c := ReadinessCheck{Service: name, Container: svc.ContainerName, ReadinessProbe: *svc.Readiness}
+		if c.Container == "" {
+			c.Container = name
+		}
+		if svc.Readiness.Container != "" {
+			c.Container = svc.Readiness.Container
+		}
+		checks = append(checks, c)
+	}
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Service < checks[j].Service })
+	return checks, nil
+}
+
+// probeReadiness makes one attempt at a readiness probe: docker exec in
+// the container, or the host command on this machine.
+func probeReadiness(ctx context.Context, c ReadinessCheck) error {
+	if len(c.Exec) > 0 {
+		code, output, err := verify.DockerExec{}.Exec(ctx, c.Container, c.Exec)
+		if err != nil {
+			return fmt.Errorf("exec in %s: %w", c.Container, err)
+		}
+		if code != 0 {
+			return probeFailure(c.Exec, code, output)
+		}
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, c.Host[0], c.Host[1:]...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return probeFailure(c.Host, exitErr.ExitCode(), out.String())
+	}
+	return err
+}
+
+// probeFailure describes a probe command that exited nonzero, with the last
+// line of its output.
+func probeFailure(command []string, code int, output string) error {
+	detail := fmt.Sprintf("%s exited %d", strings.Join(command, " "), code)
+	if output = strings.TrimSpace(output); output != "" {
+		if i := strings.LastIndex(output, "\n"); i != -1 {
+			output = output[i+1:]
+		}
+		detail += ": " + output
+	}
+	return errors.New(detail)
+}
+
+// WaitForReadiness runs the readiness probes a compose file declares,
+// retrying each until it passes or its timeout runs out. It returns an
+// error naming the services that never became ready.
+func (d *DeployOps) WaitForReadiness(ctx context.Context, composeFile string) error {
+	if d.DryRun {
+		return nil
+	}
+
+	checks, err := LoadReadiness(composeFile)
+	if err != nil {
+		return fmt.Errorf("load readiness probes: %w", err)
+	}
+
+	probe := d.probe
+	if probe == nil {
+		probe = probeReadiness
+	}
+
+	var notReady []string
+	for _, c := range checks {
+		ui.Info("  Waiting for %s to be ready...", c.Service)
+		if err := waitReady(ctx, c, probe); err != nil {
+			notReady = append(notReady, fmt.Sprintf("%s: %v", c.Service, err))
+		}
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("%d service(s) not ready: %s", len(notReady), strings.Join(notReady, "; "))
+	}
+	return nil
+}
+
+// waitReady retries a probe every interval until it passes, returning the
+// last failure once the timeout runs out.
+func waitReady(ctx context.Context, c ReadinessCheck, probe func(context.Context, ReadinessCheck) error) error {
+	timeout, interval := c.Durations()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := probe(ctx, c)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s: %w", timeout, err)
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeReadinessCompose(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "apps.yml")
+	content := `services:
+  wiki:
+    image: wiki:2
+    container_name: wiki-app
+    x-bosun-readiness:
+      exec: [wiki, migrate, --check]
+      interval: 1ms
+      timeout: 50ms
+  api:
+    image: api:1
+    x-bosun-readiness:
+      host: [curl, -fsS, http://localhost:8080/ready]
+      interval: 1ms
+      timeout: 50ms
+  redis:
+    image: redis:7
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestLoadReadiness(t *testing.T) {
+	t.Run("probes by service", func(t *testing.T) {
+		checks, err := LoadReadiness(writeReadinessCompose(t))
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+
+		assert.Equal(t, "api", checks[0].Service)
+		assert.Equal(t, "api", checks[0].Container)
+		assert.Equal(t, []string{"curl", "-fsS", "http://localhost:8080/ready"}, checks[0].Host)
+
+		assert.Equal(t, "wiki", checks[1].Service)
+		assert.Equal(t, "wiki-app", checks[1].Container)
+		assert.Equal(t, []string{"wiki", "migrate", "--check"}, checks[1].Exec)
+	})
+
+	t.Run("invalid probe", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "apps.yml")
+		require.NoError(t, os.WriteFile(file, []byte("services:\n  wiki:\n    x-bosun-readiness: {}\n"), 0644))
+
+		_, err := LoadReadiness(file)
+		assert.ErrorContains(t, err, "wiki: readiness")
+	})
+}
+
+func TestWaitForReadiness(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("waits until probes pass", func(t *testing.T) {
+		attempts := map[string]int{}
+		d := &DeployOps{probe: func(_ context.Context, c ReadinessCheck) error {
+			attempts[c.Service]++
+			if attempts[c.Service] < 3 {
+				return errors.New("migrations running")
+			}
+			return nil
+		}}
+
+		require.NoError(t, d.WaitForReadiness(ctx, writeReadinessCompose(t)))
+		assert.Equal(t, map[string]int{"api": 3, "wiki": 3}, attempts)
+	})
+
+	t.Run("names services that never become ready", func(t *testing.T) {
+		d := &DeployOps{probe: func(_ context.Context, c ReadinessCheck) error {
+			if c.Service == "wiki" {
+				return errors.New("wiki migrate --check exited 1")
+			}
+			return nil
+		}}
+
+		err := d.WaitForReadiness(ctx, writeReadinessCompose(t))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 service(s) not ready")
+		assert.Contains(t, err.Error(), "wiki: not ready after 50ms: wiki migrate --check exited 1")
+		assert.NotContains(t, err.Error(), "api")
+	})
+
+	t.Run("dry run skips probes", func(t *testing.T) {
+		d := &DeployOps{DryRun: true, probe: func(context.Context, ReadinessCheck) error {
+			t.Fatal("probe ran in dry run")
+			return nil
+		}}
+		assert.NoError(t, d.WaitForReadiness(ctx, writeReadinessCompose(t)))
+	})
+}
+
+func TestProbeReadiness_Host(t *testing.T) {
+	ctx := context.Background()
+
+	ready := ReadinessCheck{Service: "api"}
+	ready.Host = []string{"true"}
+	assert.NoError(t, probeReadiness(ctx, ready))
+
+	notReady := ReadinessCheck{Service: "api"}
+	notReady.Host = []string{"sh", "-c", "echo starting; echo still migrating; exit 3"}
+	assert.EqualError(t, probeReadiness(ctx, notReady), "sh -c echo starting; echo still migrating; exit 3 exited 3: still migrating")
+}
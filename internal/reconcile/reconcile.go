@@ -6,11 +6,38 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+	"github.com/cameronsjo/bosun/internal/lint"
+	"github.com/cameronsjo/bosun/internal/preflight"
+	"github.com/cameronsjo/bosun/internal/seal"
+	"github.com/cameronsjo/bosun/internal/state"
 	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/verify"
 )
 
+// Lint gate enforcement modes.
+const (
+	// LintModeBlock rejects the deploy when rendered compose files have lint errors.
+	LintModeBlock = "block"
+	// LintModeWarn logs lint findings but deploys anyway.
+	LintModeWarn = "warn"
+	// LintModeOff skips the lint gate.
+	LintModeOff = "off"
+)
+
+// ValidateLintMode checks that mode is a known lint gate mode.
+func ValidateLintMode(mode string) error {
+	switch mode {
+	case LintModeBlock, LintModeWarn, LintModeOff:
+		return nil
+	}
+	return fmt.Errorf("invalid lint mode %q (expected %s, %s, or %s)", mode, LintModeBlock, LintModeWarn, LintModeOff)
+}
+
 // Config holds the reconciliation configuration.
 type Config struct {
 	// RepoURL is the git repository URL.
@@ -25,9 +52,15 @@ type Config struct {
 	BackupDir string
 	// LogDir is the directory for log files.
 	LogDir string
+	// StateDir is the directory for persistent runtime state (stack pins).
+	StateDir string
 
 	// TargetHost is empty for local deployment, or "user@host" for remote.
 	TargetHost string
+	// DockerHost is the remote target's Docker daemon (ssh://user@host or
+	// tcp://host:2376, TLS from DOCKER_CERT_PATH). When set, remote container
+	// signals and daemon checks use the Docker API rather than SSH commands.
+	DockerHost string
 	// LocalAppdataPath is the path to appdata when running locally.
 	LocalAppdataPath string
 	// RemoteAppdataPath is the path to appdata on the remote host.
@@ -37,6 +70,9 @@ type Config struct {
 	DryRun bool
 	// Force if true, runs deployment even if no changes detected.
 	Force bool
+	// Selective deploys only the stacks and configs the new commits touch
+	// (see MapChanges). Off, every reconcile deploys everything.
+	Selective bool
 
 	// SecretsFiles is the list of SOPS-encrypted secret files to decrypt.
 	SecretsFiles []string
@@ -47,29 +83,123 @@ type Config struct {
 
 	// BackupsToKeep is the number of backups to retain.
 	BackupsToKeep int
+	// EncryptBackups age-encrypts backup archives with the SOPS age key
+	// (see seal.LoadKey), since backed-up configs hold secrets. Without a
+	// key, backups fail rather than fall back to plaintext.
+	EncryptBackups bool
+
+	// PermissionRules lists sensitive appdata files whose permissions are
+	// verified (and repaired) after each sync.
+	PermissionRules []PermissionRule
+
+	// Ownership sets the owner and mode of deployed paths after each sync,
+	// for containers that run as non-root users.
+	Ownership []OwnershipRule
+	// Userns is how the target's Docker daemon maps container IDs, which
+	// decides how ownership rules are applied: "none", "rootless", or
+	// "userns". Empty detects it with docker info.
+	Userns string
+	// OwnershipImage is the helper image that applies ownership rules on
+	// rootless targets (default DefaultOwnershipImage).
+	OwnershipImage string
+
+	// MaintenancePage, when set, routes all Traefik traffic to a maintenance
+	// page from the Traefik sync until services pass the health gate.
+	MaintenancePage bool
+	// MaintenanceURL is the backend serving the maintenance page (default
+	// DefaultMaintenanceURL, the bosun daemon).
+	MaintenanceURL string
+
+	// LintMode controls the lint gate between render and deploy:
+	// "block" (default), "warn", or "off".
+	LintMode string
+
+	// BuildCacheDir keeps a BuildKit layer cache for services built from
+	// source. Empty uses the classic builder without a persistent cache.
+	BuildCacheDir string
+
+	// HostLabel selects per-host compose overrides (<stack>.<label>.yml).
+	// Empty uses the deploy host's short hostname.
+	HostLabel string
+
+	// Profiles are the compose profiles enabled on deploy. Services in
+	// other profiles are not started.
+	Profiles []string
+
+	// ImageDistribution is how built images reach a remote target: "build"
+	// (default) builds there, "registry" pushes to Registry and pulls there,
+	// and "ssh" streams with docker save | docker load.
+	ImageDistribution string
+	// Registry is the private registry used by the "registry" distribution,
+	// e.g. registry.lan:5000.
+	Registry string
+
+	// StaleTempAge is how old deploy and restore temp directories must be
+	// before they are removed as crash leftovers. Zero disables cleanup.
+	StaleTempAge time.Duration
+
+	// DockerRootDir is Docker's data root on the deploy target, checked for
+	// free space before deploys.
+	DockerRootDir string
+
+	// DiskMinFree and DiskMinFreePercent are the free space every filesystem
+	// the deploy writes to must have; the deploy aborts before rendering
+	// otherwise. Zero disables a threshold.
+	DiskMinFree        uint64
+	DiskMinFreePercent float64
+
+	// Chaos, when set, randomly fails compose up and the health gate so the
+	// rollback and alerting paths get exercised. For staging only.
+	Chaos *Chaos
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		RepoBranch:        "main",
-		RepoDir:           "/app/repo",
-		StagingDir:        "/app/staging",
-		BackupDir:         "/app/backups",
-		LogDir:            "/app/logs",
-		LocalAppdataPath:  "/mnt/appdata",
-		RemoteAppdataPath: "/mnt/user/appdata",
-		InfraSubDir:       ".",
-		BackupsToKeep:     5,
+		RepoBranch:         "main",
+		RepoDir:            "/app/repo",
+		StagingDir:         "/app/staging",
+		BackupDir:          "/app/backups",
+		LogDir:             "/app/logs",
+		StateDir:           "/app/state",
+		LocalAppdataPath:   "/mnt/appdata",
+		RemoteAppdataPath:  "/mnt/user/appdata",
+		InfraSubDir:        ".",
+		Selective:          true,
+		BackupsToKeep:      5,
+		PermissionRules:    DefaultPermissionRules,
+		LintMode:           LintModeBlock,
+		StaleTempAge:       DefaultStaleTempAge,
+		DockerRootDir:      "/var/lib/docker",
+		DiskMinFree:        DefaultDiskMinFree,
+		DiskMinFreePercent: DefaultDiskMinFreePercent,
 	}
 }
 
+// ResolveStateDir returns dir, or when it is empty the state directory from
+// BOSUN_STATE_DIR or STATE_DIR (in that order), or the default. The CLI
+// commands and the daemon all resolve it here, so they read and write the
+// same state.
+func ResolveStateDir(dir string) string {
+	if dir == "" {
+		dir = os.Getenv("BOSUN_STATE_DIR")
+	}
+	if dir == "" {
+		dir = os.Getenv("STATE_DIR")
+	}
+	if dir == "" {
+		dir = DefaultConfig().StateDir
+	}
+	return dir
+}
+
 // AlertSender sends alerts for reconciliation events.
 type AlertSender interface {
 	SendDeploySuccess(ctx context.Context, commit, target string) error
 	SendDeployFailure(ctx context.Context, commit, target, reason string) error
 	SendRollbackSuccess(ctx context.Context, target, backupName string) error
 	SendRollbackFailure(ctx context.Context, target, reason string) error
+	SendPermissionRegression(ctx context.Context, target string, issues []string) error
 }
 
 // Reconciler orchestrates the GitOps reconciliation workflow.
@@ -84,6 +214,31 @@ type Reconciler struct {
 	lockFd         *os.File
 	lastBackupPath string // Path to the last backup for rollback support
 	lastCommit     string // Track commit for alerting
+	fromCommit     string // Commit the last run synced from (see CommitRange)
+	toCommit       string // Commit the last run synced to (see CommitRange)
+	hostname       string // Deploy host's hostname, from host facts
+
+	// changes is what this run deploys (see planChanges); nil deploys everything.
+	changes *ChangeSet
+	// inventory assigns stacks to hosts (see splitInventory); nil deploys
+	// every stack to the target.
+	inventory *config.Inventory
+
+	// gatherFacts reads facts about the deploy target ("" for this host).
+	gatherFacts func(ctx context.Context, target string) (*hostmetrics.Facts, error)
+	// newVerifier returns the smoke test runner for the deploy target.
+	newVerifier func(target string) *verify.Runner
+
+	// tempCleaned records when stale temp cleanup last ran, per host.
+	tempCleaned map[string]time.Time
+
+	// onProgress is called as each step starts (see WithProgress).
+	onProgress func(Progress)
+
+	// detectUserns reads the deploy target's user namespace mode.
+	detectUserns func(ctx context.Context, target string) (Userns, error)
+	// listProjects lists the compose projects on the deploy target.
+	listProjects func(ctx context.Context, host string) ([]ComposeProject, error)
 }
 
 // NewReconciler creates a new Reconciler with the given configuration.
@@ -94,11 +249,25 @@ func NewReconciler(cfg *Config, opts ...ReconcilerOption) *Reconciler {
 		sops:     NewSOPSOps(),
 		deploy:   NewDeployOps(cfg.DryRun),
 		lockFile: "/tmp/reconcile.lock",
+		gatherFacts: func(ctx context.Context, target string) (*hostmetrics.Facts, error) {
+			if target == "" {
+				return hostmetrics.LocalFacts(ctx), nil
+			}
+			return hostmetrics.RemoteFacts(ctx, target)
+		},
+		newVerifier:  verify.NewRunner,
+		detectUserns: DetectUserns,
+		listProjects: ListComposeProjects,
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
+	if cfg.Chaos != nil {
+		r.deploy.Chaos = cfg.Chaos
+	}
+	r.deploy.DockerHost = cfg.DockerHost
+	r.deploy.Profiles = cfg.Profiles
 
 	return r
 }
@@ -141,9 +310,17 @@ func WithAlerter(alerter AlertSender) ReconcilerOption {
 	}
 }
 
+// CommitRange returns the commits the last run synced from and to. Both
+// are empty when the run failed before syncing; from and to are equal when
+// there was nothing new.
+func (r *Reconciler) CommitRange() (from, to string) {
+	return r.fromCommit, r.toCommit
+}
+
 // Run executes the full reconciliation workflow.
 func (r *Reconciler) Run(ctx context.Context) error {
 	startTime := time.Now()
+	r.fromCommit, r.toCommit = "", ""
 
 	// Acquire lock to prevent concurrent runs.
 	if err := r.acquireLock(); err != nil {
@@ -151,9 +328,20 @@ func (r *Reconciler) Run(ctx context.Context) error {
 	}
 	defer r.releaseLock()
 
+	// Tag structured logs with the run, so one reconcile's lines can be
+	// picked out of the daemon's log.
+	defer ui.WithLogFields("reconcile_id", newReconcileID(), "source", triggerFrom(ctx))()
+
 	ui.Header("=== Starting reconciliation ===")
+	if r.config.Chaos != nil {
+		ui.Warning("CHAOS MODE - injecting deploy failures (%s)", r.config.Chaos)
+	}
+
+	// Remove temp directories left behind by crashed deploys and restores.
+	r.cleanStaleTemp(ctx)
 
 	// Step 1: Sync repository.
+	r.progress(StepSync, "Syncing repository")
 	changed, before, after, err := r.syncRepo(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to sync repository: %w", err)
@@ -161,6 +349,7 @@ func (r *Reconciler) Run(ctx context.Context) error {
 
 	// Track commit for alerting.
 	r.lastCommit = after
+	r.fromCommit, r.toCommit = before, after
 
 	// Skip if no changes and not forced.
 	if !changed && !r.config.Force {
@@ -175,30 +364,102 @@ func (r *Reconciler) Run(ctx context.Context) error {
 	}
 
 	// Step 2: Decrypt secrets.
+	r.progress(StepDecrypt, "Decrypting secrets")
 	secrets, err := r.decryptSecrets(ctx)
 	if err != nil {
 		r.sendFailureAlert(ctx, "failed to decrypt secrets")
 		return fmt.Errorf("failed to decrypt secrets: %w", err)
 	}
 
+	// Step 2b: Check free disk space before rendering, backing up, or pulling.
+	if err := r.checkDiskSpace(ctx, secrets); err != nil {
+		r.sendFailureAlert(ctx, err.Error())
+		return err
+	}
+
 	// Step 3: Render templates.
+	r.progress(StepRender, "Rendering templates")
 	if err := r.renderTemplates(ctx, secrets); err != nil {
 		r.sendFailureAlert(ctx, "failed to render templates")
 		return fmt.Errorf("failed to render templates: %w", err)
 	}
 
+	// Step 3b: Replace pinned stacks with their pinned versions.
+	if err := r.applyPins(ctx); err != nil {
+		r.sendFailureAlert(ctx, "failed to apply stack pins")
+		return fmt.Errorf("failed to apply stack pins: %w", err)
+	}
+
+	// Step 3c: Move stacks the inventory assigns to other hosts to their
+	// own staging, then merge per-host compose overrides over their base
+	// stacks.
+	if err := r.splitInventory(); err != nil {
+		r.sendFailureAlert(ctx, "failed to apply inventory")
+		return fmt.Errorf("failed to apply inventory: %w", err)
+	}
+	if err := r.applyHostOverrides(); err != nil {
+		r.sendFailureAlert(ctx, "failed to apply host overrides")
+		return fmt.Errorf("failed to apply host overrides: %w", err)
+	}
+
+	// Step 3e: Scope the deploy to what the new commits touch.
+	r.changes = r.planChanges(ctx, before, after)
+	r.skipAppliedStacks()
+	if r.changes.Empty() {
+		ui.Info("=== No deployable changes, skipping deployment ===")
+		if err := r.cleanupStaging(); err != nil {
+			ui.Warning("Failed to cleanup staging directory: %v", err)
+		}
+		return nil
+	}
+	if r.changes != nil && !r.changes.Full {
+		ui.Info("Deploying %s", r.changes)
+	}
+
+	// Record the run's context (with fake secrets) for 'bosun replay'.
+	r.recordFixture(ctx, before, after, secrets)
+
+	// Stamp content hashes so unchanged configs can be skipped at deploy.
+	if err := r.stampHashes(); err != nil {
+		r.sendFailureAlert(ctx, "failed to stamp content hashes")
+		return fmt.Errorf("failed to stamp content hashes: %w", err)
+	}
+
+	// Step 3d: Lint rendered compose files and Traefik config before
+	// touching the target.
+	r.progress(StepLint, "Linting rendered compose files and Traefik config")
+	if err := r.lintRendered(); err != nil {
+		r.sendFailureAlert(ctx, err.Error())
+		return fmt.Errorf("lint gate failed: %w", err)
+	}
+
 	// Step 4: Create backup (unless dry run).
 	if !r.config.DryRun {
+		r.progress(StepBackup, "Creating backup")
 		if err := r.createBackup(ctx, secrets); err != nil {
 			ui.Warning("Backup partially failed: %v", err)
 		}
 	}
 
 	// Step 5: Deploy.
+	r.progress(StepDeploy, "Deploying services")
 	if err := r.doDeploy(ctx, secrets); err != nil {
 		r.sendFailureAlert(ctx, err.Error())
 		return fmt.Errorf("deployment failed: %w", err)
 	}
+	if err := r.deployInventoryHosts(ctx); err != nil {
+		r.sendFailureAlert(ctx, err.Error())
+		return fmt.Errorf("deployment failed: %w", err)
+	}
+
+	// Step 5b: Run the smoke tests the deployed services declare.
+	r.progress(StepVerify, "Running smoke tests")
+	if err := r.verifyDeploy(ctx, secrets); err != nil {
+		r.sendFailureAlert(ctx, err.Error())
+		return err
+	}
+
+	r.recordDeploy(ctx)
 
 	// Step 6: Cleanup staging directory after successful deployment.
 	if err := r.cleanupStaging(); err != nil {
@@ -246,6 +507,33 @@ func (r *Reconciler) sendFailureAlert(ctx context.Context, reason string) {
 	}
 }
 
+// sendRollbackAlert sends a rollback notification when a service reload
+// failed and bosun rolled back to the last backup. Errors without a
+// rollback are left to the failure alert.
+func (r *Reconciler) sendRollbackAlert(ctx context.Context, reloadErr error) {
+	if r.alerter == nil {
+		return
+	}
+
+	target := r.config.TargetHost
+	if target == "" {
+		target = "local"
+	}
+
+	var err error
+	switch {
+	case errors.Is(reloadErr, ErrRollbackFailed):
+		err = r.alerter.SendRollbackFailure(ctx, target, reloadErr.Error())
+	case errors.Is(reloadErr, ErrRollbackSucceeded):
+		err = r.alerter.SendRollbackSuccess(ctx, target, filepath.Base(r.lastBackupPath))
+	default:
+		return
+	}
+	if err != nil {
+		ui.Warning("Failed to send rollback alert: %v", err)
+	}
+}
+
 // cleanupStaging removes the staging directory after successful deployment.
 func (r *Reconciler) cleanupStaging() error {
 	if r.config.DryRun {
@@ -309,8 +597,8 @@ func (r *Reconciler) renderTemplates(ctx context.Context, secrets map[string]any
 		return fmt.Errorf("failed to create staging directory: %w", err)
 	}
 
-	// Create template ops with secrets data.
-	r.template = NewTemplateOps(secrets)
+	// Create template ops with secrets data and deploy host facts.
+	r.template = NewTemplateOps(r.templateData(ctx, secrets))
 
 	infraDir := filepath.Join(r.config.RepoDir, r.config.InfraSubDir)
 	if err := r.template.RenderDirectory(ctx, infraDir, r.config.StagingDir, "unraid"); err != nil {
@@ -321,30 +609,192 @@ func (r *Reconciler) renderTemplates(ctx context.Context, secrets map[string]any
 	return nil
 }
 
-// createBackup creates a backup of current configs.
+// templateData returns the template data: the decrypted secrets plus facts
+// about the deploy host under "host" (e.g. {{ .host.ip }}), so templates
+// need not hardcode addresses. A "host" key in the secrets takes precedence.
+func (r *Reconciler) templateData(ctx context.Context, secrets map[string]any) map[string]any {
+	if _, ok := secrets["host"]; ok || r.gatherFacts == nil {
+		return secrets
+	}
+
+	target := ""
+	if !r.isLocalMode() {
+		target = r.getTargetHost(secrets)
+	}
+	facts, err := r.gatherFacts(ctx, target)
+	if err != nil {
+		ui.Warning("Host facts unavailable: %v", err)
+		return secrets
+	}
+
+	r.hostname = facts.Hostname
+
+	data := make(map[string]any, len(secrets)+1)
+	for k, v := range secrets {
+		data[k] = v
+	}
+	data["host"] = facts.Map()
+	return data
+}
+
+// applyPins overwrites the staged compose file of each pinned stack with its
+// contents at the pinned ref, so pinned stacks stay put while the branch advances.
+func (r *Reconciler) applyPins(ctx context.Context) error {
+	if r.config.StateDir == "" {
+		return nil
+	}
+
+	st, err := state.NewStore(r.config.StateDir).Load()
+	if err != nil {
+		return err
+	}
+
+	for _, stack := range st.PinnedStacks() {
+		ref := st.Pins[stack].Ref
+		ui.Info("Stack %s pinned to %s", stack, ref)
+		if err := r.applyPin(ctx, stack, ref); err != nil {
+			return fmt.Errorf("stack %s: %w", stack, err)
+		}
+	}
+	return nil
+}
+
+// applyPin renders a single pinned stack's compose file into the staging directory.
+// Both plain (<stack>.yml) and templated (<stack>.yml.tmpl) compose files are supported.
+func (r *Reconciler) applyPin(ctx context.Context, stack, ref string) error {
+	composeRel := filepath.Join(r.config.InfraSubDir, "unraid", "compose", stack+".yml")
+	outputPath := filepath.Join(r.config.StagingDir, "unraid", "compose", stack+".yml")
+
+	content, err := r.git.ReadFileAtRef(ctx, ref, composeRel)
+	if err == nil {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return fmt.Errorf("failed to create compose staging directory: %w", err)
+		}
+		return os.WriteFile(outputPath, content, 0644)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	content, err = r.git.ReadFileAtRef(ctx, ref, composeRel+".tmpl")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no compose file for stack at %s", ref)
+		}
+		return err
+	}
+
+	// Render the pinned template with the current secrets.
+	tmpFile, err := os.CreateTemp("", "bosun-pin-*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	return r.template.ExecuteTemplate(ctx, tmpFile.Name(), outputPath)
+}
+
+// lintRendered runs the lint rules (schema, values, dependencies, and port
+// conflicts) over the rendered compose files in staging, and checks the
+// staged Traefik dynamic config's references, rules, and entrypoints: the
+// pre-deploy validation gate. In block mode (the default) any error rejects the
+// deploy; in warn mode findings are only logged.
+func (r *Reconciler) lintRendered() error {
+	mode := r.config.LintMode
+	if mode == "" {
+		mode = LintModeBlock
+	}
+	if mode == LintModeOff {
+		ui.Warning("Skipping pre-deploy validation (lint mode: off)")
+		return nil
+	}
+
+	ui.Info("Linting rendered compose files and Traefik config...")
+	result := &lint.Result{}
+	var composeFiles []string
+	for _, dir := range r.stagedComposeDirs() {
+		found, err := lint.ComposeDir(dir)
+		if err != nil {
+			return err
+		}
+		result.Findings = append(result.Findings, found.Findings...)
+		files, _ := stackComposeFiles(dir)
+		composeFiles = append(composeFiles, files...)
+	}
+
+	traefikDir := filepath.Join(r.config.StagingDir, "unraid", "appdata", "traefik")
+	found, err := lint.TraefikFile(filepath.Join(traefikDir, "dynamic.yml"), lint.TraefikEntryPoints(traefikDir, composeFiles))
+	if err != nil {
+		return err
+	}
+	result.Findings = append(result.Findings, found.Findings...)
+
+	for _, f := range result.Warnings() {
+		ui.Warning("  %s", f)
+	}
+
+	lintErrors := result.Errors()
+	if len(lintErrors) == 0 {
+		ui.Success("Lint passed")
+		return nil
+	}
+
+	msgs := make([]string, 0, len(lintErrors))
+	for _, f := range lintErrors {
+		msgs = append(msgs, f.String())
+	}
+
+	if mode == LintModeWarn {
+		for _, msg := range msgs {
+			ui.Warning("  %s", msg)
+		}
+		ui.Warning("Deploying despite %d lint error(s) (lint mode: warn)", len(lintErrors))
+		return nil
+	}
+
+	for _, msg := range msgs {
+		ui.Error("  %s", msg)
+	}
+	ui.Info("Fix the rendered files, or override with 'bosun reconcile --skip-validation' (LINT_MODE=off)")
+	return fmt.Errorf("%d lint error(s): %s", len(lintErrors), strings.Join(msgs, "; "))
+}
+
+// createBackup creates a backup of current configs: the core
+// infrastructure configs plus every path the rendered services declare.
 func (r *Reconciler) createBackup(ctx context.Context, secrets map[string]any) error {
 	ui.Info("Creating backup...")
 
-	var backupName string
-	var err error
-
+	appdata := r.config.RemoteAppdataPath
 	if r.isLocalMode() {
-		paths := []string{
-			filepath.Join(r.config.LocalAppdataPath, "traefik"),
-			filepath.Join(r.config.LocalAppdataPath, "authelia", "configuration.yml"),
-			filepath.Join(r.config.LocalAppdataPath, "agentgateway", "config.yaml"),
-			filepath.Join(r.config.LocalAppdataPath, "gatus", "config.yaml"),
+		appdata = r.config.LocalAppdataPath
+	}
+	index, err := NewBackupIndex(filepath.Join(r.config.StagingDir, "unraid", "compose"), appdata)
+	if err != nil {
+		return fmt.Errorf("collect backup paths: %w", err)
+	}
+
+	if r.config.EncryptBackups && r.deploy.BackupKey == nil {
+		key, err := seal.LoadKey()
+		if err != nil {
+			return fmt.Errorf("load backup encryption key: %w", err)
 		}
-		backupName, err = r.deploy.Backup(ctx, r.config.BackupDir, paths)
+		r.deploy.BackupKey = key
+	}
+
+	var backupName string
+	if r.isLocalMode() {
+		backupName, err = r.deploy.Backup(ctx, r.config.BackupDir, index.Paths())
 	} else {
 		host := r.getTargetHost(secrets)
-		remotePaths := []string{
-			filepath.Join(r.config.RemoteAppdataPath, "traefik"),
-			filepath.Join(r.config.RemoteAppdataPath, "authelia", "configuration.yml"),
-			filepath.Join(r.config.RemoteAppdataPath, "agentgateway", "config.yaml"),
-			filepath.Join(r.config.RemoteAppdataPath, "gatus", "config.yaml"),
-		}
-		backupName, err = r.deploy.BackupRemote(ctx, host, r.config.BackupDir, remotePaths)
+		backupName, err = r.deploy.BackupRemote(ctx, host, r.config.BackupDir, index.Paths())
 	}
 
 	if err != nil {
@@ -354,6 +804,10 @@ func (r *Reconciler) createBackup(ctx context.Context, secrets map[string]any) e
 	// Store backup path for potential rollback
 	r.lastBackupPath = filepath.Join(r.config.BackupDir, backupName)
 
+	if err := WriteBackupIndex(r.lastBackupPath, index); err != nil {
+		ui.Warning("Failed to write backup index: %v", err)
+	}
+
 	// Cleanup old backups.
 	if err := r.deploy.CleanupBackups(r.config.BackupDir, r.config.BackupsToKeep); err != nil {
 		ui.Warning("Failed to cleanup old backups: %v", err)
@@ -406,58 +860,118 @@ func (r *Reconciler) deployLocal(ctx context.Context) error {
 	stagingUnraid := filepath.Join(r.config.StagingDir, "unraid")
 	appdata := r.config.LocalAppdataPath
 
-	// Sync Traefik configs.
-	ui.Info("  Syncing Traefik configs...")
-	if err := r.deploy.DeployLocal(ctx, filepath.Join(stagingUnraid, "appdata", "traefik"), filepath.Join(appdata, "traefik")); err != nil {
+	// Refuse to deploy onto stale mounts - containers would just flap.
+	if err := r.checkBindMounts(filepath.Join(stagingUnraid, "compose")); err != nil {
 		return err
 	}
 
-	// Sync agentgateway config.
-	ui.Info("  Syncing agentgateway config...")
-	if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "agentgateway", "config.yaml"), filepath.Join(appdata, "agentgateway", "config.yaml")); err != nil {
-		return err
+	// Build images from source before touching the deployed files.
+	if err := r.buildImages(ctx, ""); err != nil {
+		return fmt.Errorf("image build failed: %w", err)
+	}
+
+	// Record sensitive file permissions so sync regressions can be detected.
+	permsBefore := SnapshotPermissions(appdata, r.config.PermissionRules)
+	var userns Userns
+	if len(r.config.Ownership) > 0 {
+		userns = r.targetUserns(ctx, "")
+		expectOwnership(permsBefore, userns.ExpectedRules(r.config.Ownership))
+	}
+
+	// Sync Traefik configs, with the maintenance page up if enabled. It
+	// comes down when the deploy ends unless services failed to come back.
+	healthy := true
+	r.enableMaintenance(stagingUnraid)
+	defer func() { r.disableMaintenance(ctx, "", appdata, healthy) }()
+	if r.syncsTraefik(ctx, "", filepath.Join(stagingUnraid, "appdata", "traefik"), filepath.Join(appdata, "traefik")) {
+		ui.Info("  Syncing Traefik configs...")
+		if err := r.deploy.DeployLocal(ctx, filepath.Join(stagingUnraid, "appdata", "traefik"), filepath.Join(appdata, "traefik")); err != nil {
+			return err
+		}
+	}
+
+	// Sync agentgateway config; it is reloaded after services come up.
+	agentgatewaySynced := false
+	if r.syncsConfig(ctx, "", "agentgateway", filepath.Join(stagingUnraid, "appdata", "agentgateway", "config.yaml"), filepath.Join(appdata, "agentgateway", "config.yaml")) {
+		agentgatewaySynced = true
+		ui.Info("  Syncing agentgateway config...")
+		if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "agentgateway", "config.yaml"), filepath.Join(appdata, "agentgateway", "config.yaml")); err != nil {
+			return err
+		}
 	}
 
 	// Sync authelia config.
-	ui.Info("  Syncing authelia config...")
-	if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "authelia", "configuration.yml"), filepath.Join(appdata, "authelia", "configuration.yml")); err != nil {
-		return err
+	if r.syncsConfig(ctx, "", "authelia", filepath.Join(stagingUnraid, "appdata", "authelia", "configuration.yml"), filepath.Join(appdata, "authelia", "configuration.yml")) {
+		ui.Info("  Syncing authelia config...")
+		if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "authelia", "configuration.yml"), filepath.Join(appdata, "authelia", "configuration.yml")); err != nil {
+			return err
+		}
 	}
 
 	// Sync gatus config.
-	ui.Info("  Syncing gatus config...")
-	if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "gatus", "config.yaml"), filepath.Join(appdata, "gatus", "config.yaml")); err != nil {
-		return err
+	if r.syncsConfig(ctx, "", "gatus", filepath.Join(stagingUnraid, "appdata", "gatus", "config.yaml"), filepath.Join(appdata, "gatus", "config.yaml")) {
+		ui.Info("  Syncing gatus config...")
+		if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "gatus", "config.yaml"), filepath.Join(appdata, "gatus", "config.yaml")); err != nil {
+			return err
+		}
 	}
 
 	// Sync tailscale-gateway config.
-	ui.Info("  Syncing tailscale-gateway config...")
-	_ = os.MkdirAll(filepath.Join(appdata, "tailscale-gateway"), 0755)
-	if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "tailscale-gateway", "serve.json"), filepath.Join(appdata, "tailscale-gateway", "serve.json")); err != nil {
-		ui.Warning("tailscale-gateway sync failed: %v", err)
+	if r.changes.HasConfig("tailscale-gateway") {
+		ui.Info("  Syncing tailscale-gateway config...")
+		_ = os.MkdirAll(filepath.Join(appdata, "tailscale-gateway"), 0755)
+		if err := r.deploy.DeployLocalFile(ctx, filepath.Join(stagingUnraid, "appdata", "tailscale-gateway", "serve.json"), filepath.Join(appdata, "tailscale-gateway", "serve.json")); err != nil {
+			ui.Warning("tailscale-gateway sync failed: %v", err)
+		}
 	}
 
 	// Sync compose files.
-	ui.Info("  Syncing compose files...")
-	_ = os.MkdirAll(filepath.Join(appdata, "compose"), 0755)
-	if err := r.deploy.DeployLocal(ctx, filepath.Join(stagingUnraid, "compose"), filepath.Join(appdata, "compose")); err != nil {
-		return err
+	if r.changes.HasStacks() {
+		ui.Info("  Syncing compose files...")
+		_ = os.MkdirAll(filepath.Join(appdata, "compose"), 0755)
+		if err := r.deploy.DeployLocal(ctx, filepath.Join(stagingUnraid, "compose"), filepath.Join(appdata, "compose")); err != nil {
+			return err
+		}
 	}
 
-	// Reload services with rollback support.
+	// Apply configured ownership, then repair permission regressions,
+	// before services pick up the files.
 	if !r.config.DryRun {
+		if len(r.config.Ownership) > 0 {
+			ui.Info("  Applying file ownership...")
+			if err := r.applyOwnership(ctx, "", appdata, userns); err != nil {
+				ui.Warning("Could not apply file ownership: %v", err)
+			}
+		}
+
+		ui.Info("  Verifying file permissions...")
+		issues := ComparePermissions(r.config.PermissionRules, permsBefore, SnapshotPermissions(appdata, r.config.PermissionRules))
+		if err := RepairPermissions(appdata, issues); err != nil {
+			ui.Warning("Could not repair file permissions: %v", err)
+		}
+		r.reportPermissionIssues(ctx, issues)
+	}
+
+	// Reload each changed stack as its own compose project, with rollback support.
+	if !r.config.DryRun && r.changes.HasStacks() {
 		ui.Info("  Reloading services...")
-		composeFile := filepath.Join(appdata, "compose", "core.yml")
-		if err := r.deploy.ComposeUpWithRollback(ctx, composeFile, r.lastBackupPath); err != nil {
-			// Check if rollback succeeded or failed
-			if errors.Is(err, ErrRollbackFailed) {
+		var err error
+		// After a rollback the previous services are back up.
+		healthy, err = r.reloadStacks(ctx, filepath.Join(appdata, "compose"))
+		if err != nil {
+			r.sendRollbackAlert(ctx, err)
+			if errors.Is(err, ErrDockerUnavailable) {
+				return fmt.Errorf("deploy aborted, files synced but services not reloaded (no rollback attempted): %w", err)
+			} else if errors.Is(err, ErrRollbackFailed) {
 				return fmt.Errorf("CRITICAL: service reload and rollback both failed: %w", err)
-			} else if errors.Is(err, ErrRollbackSucceeded) {
+			} else if healthy {
 				return fmt.Errorf("service reload failed but rollback succeeded: %w", err)
 			}
 			// Other errors (no backup available, etc.)
 			return fmt.Errorf("service reload failed: %w", err)
 		}
+	}
+	if !r.config.DryRun && agentgatewaySynced {
 		if err := r.deploy.SignalContainer(ctx, "agentgateway", "SIGHUP"); err != nil {
 			ui.Warning("Could not reload agentgateway: %v", err)
 		}
@@ -467,6 +981,65 @@ func (r *Reconciler) deployLocal(ctx context.Context) error {
 	return nil
 }
 
+// checkBindMounts probes bind-mount source paths referenced by the compose files
+// in composeDir and returns an error if any are stale NFS/FUSE handles or hung.
+// Missing compose directories or paths are not treated as failures.
+func (r *Reconciler) checkBindMounts(composeDir string) error {
+	composeFiles, _ := filepath.Glob(filepath.Join(composeDir, "*.yml"))
+
+	var paths []string
+	for _, f := range composeFiles {
+		sources, err := preflight.BindMountSources(f)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, sources...)
+	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	ui.Info("  Probing %d bind mount(s)...", len(paths))
+	failed := preflight.CheckMountPaths(paths, preflight.DefaultMountProbeTimeout)
+	if len(failed) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(failed))
+	for _, f := range failed {
+		reasons = append(reasons, f.Error.Error())
+	}
+	return fmt.Errorf("pre-deploy mount check failed: %s", strings.Join(reasons, "; "))
+}
+
+// reportPermissionIssues logs permission regressions found after a sync and
+// alerts on them. Regressions never fail the deploy; repairs are best effort.
+func (r *Reconciler) reportPermissionIssues(ctx context.Context, issues []PermissionIssue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		ui.Warning("Permission regression: %s", issue)
+		lines = append(lines, issue.String())
+	}
+
+	if r.alerter == nil {
+		return
+	}
+
+	target := r.config.TargetHost
+	if target == "" {
+		target = "local"
+	}
+
+	if err := r.alerter.SendPermissionRegression(ctx, target, lines); err != nil {
+		ui.Warning("Failed to send permission alert: %v", err)
+	}
+}
+
 // deployRemote performs remote deployment via SSH.
 func (r *Reconciler) deployRemote(ctx context.Context, secrets map[string]any) error {
 	ui.Info("Using remote deployment mode (SSH)")
@@ -482,58 +1055,126 @@ func (r *Reconciler) deployRemote(ctx context.Context, secrets map[string]any) e
 	stagingUnraid := filepath.Join(r.config.StagingDir, "unraid")
 	appdata := r.config.RemoteAppdataPath
 
-	// Sync Traefik configs.
-	ui.Info("  Syncing Traefik configs...")
-	if err := r.deploy.DeployRemote(ctx, filepath.Join(stagingUnraid, "appdata", "traefik"), host, filepath.Join(appdata, "traefik")); err != nil {
-		return err
+	// Clear temp directories a crashed deploy left on the target.
+	r.cleanStaleTempRemote(ctx, host)
+
+	// Build images from source on the target before touching its files.
+	if err := r.buildImages(ctx, host); err != nil {
+		return fmt.Errorf("image build failed: %w", err)
 	}
 
-	// Sync agentgateway config.
-	ui.Info("  Syncing agentgateway config...")
-	if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "agentgateway", "config.yaml"), host, filepath.Join(appdata, "agentgateway", "config.yaml")); err != nil {
-		return err
+	// Record sensitive file permissions so sync regressions can be detected.
+	var permsBefore map[string]FileAttrs
+	var userns Userns
+	if !r.config.DryRun {
+		var err error
+		if permsBefore, err = r.deploy.StatRemote(ctx, host, appdata, r.config.PermissionRules); err != nil {
+			ui.Warning("Could not record file permissions: %v", err)
+		}
+		if len(r.config.Ownership) > 0 {
+			userns = r.targetUserns(ctx, host)
+			expectOwnership(permsBefore, userns.ExpectedRules(r.config.Ownership))
+		}
+	}
+
+	// Sync Traefik configs, with the maintenance page up if enabled. It
+	// comes down when the deploy ends unless services failed to come back.
+	healthy := true
+	r.enableMaintenance(stagingUnraid)
+	defer func() { r.disableMaintenance(ctx, host, appdata, healthy) }()
+	if r.syncsTraefik(ctx, host, filepath.Join(stagingUnraid, "appdata", "traefik"), filepath.Join(appdata, "traefik")) {
+		ui.Info("  Syncing Traefik configs...")
+		if err := r.deploy.DeployRemote(ctx, filepath.Join(stagingUnraid, "appdata", "traefik"), host, filepath.Join(appdata, "traefik")); err != nil {
+			return err
+		}
+	}
+
+	// Sync agentgateway config; it is reloaded after services come up.
+	agentgatewaySynced := false
+	if r.syncsConfig(ctx, host, "agentgateway", filepath.Join(stagingUnraid, "appdata", "agentgateway", "config.yaml"), filepath.Join(appdata, "agentgateway", "config.yaml")) {
+		agentgatewaySynced = true
+		ui.Info("  Syncing agentgateway config...")
+		if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "agentgateway", "config.yaml"), host, filepath.Join(appdata, "agentgateway", "config.yaml")); err != nil {
+			return err
+		}
 	}
 
 	// Sync authelia config.
-	ui.Info("  Syncing authelia config...")
-	if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "authelia", "configuration.yml"), host, filepath.Join(appdata, "authelia", "configuration.yml")); err != nil {
-		return err
+	if r.syncsConfig(ctx, host, "authelia", filepath.Join(stagingUnraid, "appdata", "authelia", "configuration.yml"), filepath.Join(appdata, "authelia", "configuration.yml")) {
+		ui.Info("  Syncing authelia config...")
+		if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "authelia", "configuration.yml"), host, filepath.Join(appdata, "authelia", "configuration.yml")); err != nil {
+			return err
+		}
 	}
 
 	// Sync gatus config.
-	ui.Info("  Syncing gatus config...")
-	if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "gatus", "config.yaml"), host, filepath.Join(appdata, "gatus", "config.yaml")); err != nil {
-		return err
+	if r.syncsConfig(ctx, host, "gatus", filepath.Join(stagingUnraid, "appdata", "gatus", "config.yaml"), filepath.Join(appdata, "gatus", "config.yaml")) {
+		ui.Info("  Syncing gatus config...")
+		if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "gatus", "config.yaml"), host, filepath.Join(appdata, "gatus", "config.yaml")); err != nil {
+			return err
+		}
 	}
 
 	// Sync tailscale-gateway config.
-	ui.Info("  Syncing tailscale-gateway config...")
-	_ = r.deploy.EnsureRemoteDir(ctx, host, filepath.Join(appdata, "tailscale-gateway"))
-	if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "tailscale-gateway", "serve.json"), host, filepath.Join(appdata, "tailscale-gateway", "serve.json")); err != nil {
-		ui.Warning("tailscale-gateway sync failed: %v", err)
+	if r.changes.HasConfig("tailscale-gateway") {
+		ui.Info("  Syncing tailscale-gateway config...")
+		_ = r.deploy.EnsureRemoteDir(ctx, host, filepath.Join(appdata, "tailscale-gateway"))
+		if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "appdata", "tailscale-gateway", "serve.json"), host, filepath.Join(appdata, "tailscale-gateway", "serve.json")); err != nil {
+			ui.Warning("tailscale-gateway sync failed: %v", err)
+		}
 	}
 
 	// Sync compose files.
-	ui.Info("  Syncing compose files...")
-	_ = r.deploy.EnsureRemoteDir(ctx, host, filepath.Join(appdata, "compose"))
-	if err := r.deploy.DeployRemote(ctx, filepath.Join(stagingUnraid, "compose"), host, filepath.Join(appdata, "compose")); err != nil {
-		return err
+	if r.changes.HasStacks() {
+		ui.Info("  Syncing compose files...")
+		_ = r.deploy.EnsureRemoteDir(ctx, host, filepath.Join(appdata, "compose"))
+		if err := r.deploy.DeployRemote(ctx, filepath.Join(stagingUnraid, "compose"), host, filepath.Join(appdata, "compose")); err != nil {
+			return err
+		}
 	}
 
 	// Sync to Compose Manager.
-	ui.Info("  Syncing core compose to Compose Manager...")
 	composeManagerDir := "/boot/config/plugins/compose.manager/projects/core"
-	_ = r.deploy.EnsureRemoteDir(ctx, host, composeManagerDir)
-	if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "compose", "core.yml"), host, filepath.Join(composeManagerDir, "docker-compose.yml")); err != nil {
-		ui.Warning("Compose Manager sync failed: %v", err)
+	if r.changes.HasStack("core") {
+		ui.Info("  Syncing core compose to Compose Manager...")
+		_ = r.deploy.EnsureRemoteDir(ctx, host, composeManagerDir)
+		if err := r.deploy.DeployRemoteFile(ctx, filepath.Join(stagingUnraid, "compose", "core.yml"), host, filepath.Join(composeManagerDir, "docker-compose.yml")); err != nil {
+			ui.Warning("Compose Manager sync failed: %v", err)
+		}
 	}
 
-	// Reload services.
+	// Apply configured ownership, then repair permission regressions,
+	// before services pick up the files.
 	if !r.config.DryRun {
+		if len(r.config.Ownership) > 0 {
+			ui.Info("  Applying file ownership...")
+			if err := r.applyOwnership(ctx, host, appdata, userns); err != nil {
+				ui.Warning("Could not apply file ownership: %v", err)
+			}
+		}
+
+		ui.Info("  Verifying file permissions...")
+		permsAfter, err := r.deploy.StatRemote(ctx, host, appdata, r.config.PermissionRules)
+		if err != nil {
+			ui.Warning("Could not verify file permissions: %v", err)
+		} else {
+			issues := ComparePermissions(r.config.PermissionRules, permsBefore, permsAfter)
+			if err := r.deploy.RepairPermissionsRemote(ctx, host, appdata, issues); err != nil {
+				ui.Warning("Could not repair file permissions: %v", err)
+			}
+			r.reportPermissionIssues(ctx, issues)
+		}
+	}
+
+	// Reload services.
+	if !r.config.DryRun && r.changes.HasStack("core") {
 		ui.Info("  Reloading services...")
 		if err := r.deploy.ComposeUpRemote(ctx, host, composeManagerDir); err != nil {
 			ui.Warning("Could not recreate core stack: %v", err)
+			healthy = false
 		}
+	}
+	if !r.config.DryRun && agentgatewaySynced {
 		if err := r.deploy.SignalContainerRemote(ctx, host, "agentgateway", "SIGHUP"); err != nil {
 			ui.Warning("Could not reload agentgateway: %v", err)
 		}
@@ -2,10 +2,15 @@ package reconcile
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/cameronsjo/bosun/internal/hostmetrics"
+	"github.com/cameronsjo/bosun/internal/state"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,10 +23,13 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "/app/staging", cfg.StagingDir)
 	assert.Equal(t, "/app/backups", cfg.BackupDir)
 	assert.Equal(t, "/app/logs", cfg.LogDir)
+	assert.Equal(t, "/app/state", cfg.StateDir)
 	assert.Equal(t, "/mnt/appdata", cfg.LocalAppdataPath)
 	assert.Equal(t, "/mnt/user/appdata", cfg.RemoteAppdataPath)
 	assert.Equal(t, ".", cfg.InfraSubDir)
 	assert.Equal(t, 5, cfg.BackupsToKeep)
+	assert.Equal(t, DefaultPermissionRules, cfg.PermissionRules)
+	assert.Equal(t, LintModeBlock, cfg.LintMode)
 }
 
 func TestNewReconciler(t *testing.T) {
@@ -286,3 +294,315 @@ func TestConfig_Validation(t *testing.T) {
 		assert.Equal(t, 10, cfg.BackupsToKeep)
 	})
 }
+
+func TestReconciler_CheckBindMounts(t *testing.T) {
+	t.Run("no compose directory", func(t *testing.T) {
+		r := NewReconciler(DefaultConfig())
+		assert.NoError(t, r.checkBindMounts(filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("healthy bind mounts pass", func(t *testing.T) {
+		composeDir := t.TempDir()
+		hostDir := t.TempDir()
+		content := "services:\n  app:\n    volumes:\n      - " + hostDir + ":/config\n"
+		require.NoError(t, os.WriteFile(filepath.Join(composeDir, "core.yml"), []byte(content), 0644))
+
+		r := NewReconciler(DefaultConfig())
+		assert.NoError(t, r.checkBindMounts(composeDir))
+	})
+}
+
+func TestReconciler_LintRendered(t *testing.T) {
+	newReconciler := func(t *testing.T, mode, compose string) *Reconciler {
+		t.Helper()
+		cfg := DefaultConfig()
+		cfg.StagingDir = t.TempDir()
+		cfg.LintMode = mode
+		composeDir := filepath.Join(cfg.StagingDir, "unraid", "compose")
+		require.NoError(t, os.MkdirAll(composeDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(composeDir, "core.yml"), []byte(compose), 0644))
+		return NewReconciler(cfg)
+	}
+	bad := "services:\n  app:\n    image: app\n    depends_on: [db]\n"
+	good := "services:\n  app:\n    image: app\n"
+
+	t.Run("block mode rejects errors", func(t *testing.T) {
+		err := newReconciler(t, LintModeBlock, bad).lintRendered()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "app depends on undefined service db")
+	})
+
+	t.Run("empty mode blocks", func(t *testing.T) {
+		assert.Error(t, newReconciler(t, "", bad).lintRendered())
+	})
+
+	t.Run("warn mode deploys anyway", func(t *testing.T) {
+		assert.NoError(t, newReconciler(t, LintModeWarn, bad).lintRendered())
+	})
+
+	t.Run("off mode skips lint", func(t *testing.T) {
+		assert.NoError(t, newReconciler(t, LintModeOff, "services: [unclosed").lintRendered())
+	})
+
+	t.Run("clean compose passes", func(t *testing.T) {
+		assert.NoError(t, newReconciler(t, LintModeBlock, good).lintRendered())
+	})
+
+	t.Run("bad traefik config blocks", func(t *testing.T) {
+		r := newReconciler(t, LintModeBlock, good)
+		traefikDir := filepath.Join(r.config.StagingDir, "unraid", "appdata", "traefik")
+		require.NoError(t, os.MkdirAll(traefikDir, 0755))
+		dynamic := "http:\n  routers:\n    app:\n      rule: Host(`app.example.com`)\n      service: app\n"
+		require.NoError(t, os.WriteFile(filepath.Join(traefikDir, "dynamic.yml"), []byte(dynamic), 0644))
+
+		err := r.lintRendered()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "traefik/dynamic.yml: router app references undefined service app")
+	})
+}
+
+func TestResolveStateDir(t *testing.T) {
+	t.Setenv("BOSUN_STATE_DIR", "")
+	t.Setenv("STATE_DIR", "")
+	assert.Equal(t, "/app/state", ResolveStateDir(""))
+
+	t.Setenv("STATE_DIR", "/srv/state")
+	assert.Equal(t, "/srv/state", ResolveStateDir(""))
+
+	t.Setenv("BOSUN_STATE_DIR", "/srv/bosun")
+	assert.Equal(t, "/srv/bosun", ResolveStateDir(""), "BOSUN_STATE_DIR wins")
+	assert.Equal(t, "/tmp/flag", ResolveStateDir("/tmp/flag"), "an explicit dir wins")
+}
+
+func TestValidateLintMode(t *testing.T) {
+	for _, mode := range []string{LintModeBlock, LintModeWarn, LintModeOff} {
+		assert.NoError(t, ValidateLintMode(mode))
+	}
+	assert.Error(t, ValidateLintMode("strict"))
+}
+
+// pinGitOps is a GitOperations stub that serves files for pinned refs.
+type pinGitOps struct {
+	files map[string]string // "ref:path" -> content
+}
+
+func (p *pinGitOps) Sync(context.Context) (bool, string, string, error) { return false, "", "", nil }
+func (p *pinGitOps) IsRepo(context.Context) bool                        { return true }
+func (p *pinGitOps) ReadFileAtRef(_ context.Context, ref, path string) ([]byte, error) {
+	content, ok := p.files[ref+":"+path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func TestReconciler_ApplyPins(t *testing.T) {
+	newReconciler := func(t *testing.T, git GitOperations) (*Reconciler, *Config) {
+		t.Helper()
+		cfg := &Config{
+			StagingDir:  t.TempDir(),
+			StateDir:    t.TempDir(),
+			InfraSubDir: ".",
+		}
+		r := NewReconciler(cfg, WithGitOperations(git))
+		r.template = NewTemplateOps(map[string]any{"domain": "example.com"})
+		return r, cfg
+	}
+
+	pin := func(t *testing.T, dir, stack, ref string) {
+		t.Helper()
+		store := state.NewStore(dir)
+		st, err := store.Load()
+		require.NoError(t, err)
+		st.SetPin(stack, ref, time.Now())
+		require.NoError(t, store.Save(st))
+	}
+
+	t.Run("no pins is a no-op", func(t *testing.T) {
+		r, _ := newReconciler(t, &pinGitOps{})
+		assert.NoError(t, r.applyPins(context.Background()))
+	})
+
+	t.Run("replaces staged compose with pinned version", func(t *testing.T) {
+		r, cfg := newReconciler(t, &pinGitOps{files: map[string]string{
+			"v1:unraid/compose/media.yml": "pinned",
+		}})
+		composeDir := filepath.Join(cfg.StagingDir, "unraid", "compose")
+		require.NoError(t, os.MkdirAll(composeDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(composeDir, "media.yml"), []byte("latest"), 0644))
+		pin(t, cfg.StateDir, "media", "v1")
+
+		require.NoError(t, r.applyPins(context.Background()))
+
+		content, err := os.ReadFile(filepath.Join(composeDir, "media.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, "pinned", string(content))
+	})
+
+	t.Run("renders pinned template", func(t *testing.T) {
+		r, cfg := newReconciler(t, &pinGitOps{files: map[string]string{
+			"v1:unraid/compose/media.yml.tmpl": "host: {{ .domain }}",
+		}})
+		pin(t, cfg.StateDir, "media", "v1")
+
+		require.NoError(t, r.applyPins(context.Background()))
+
+		content, err := os.ReadFile(filepath.Join(cfg.StagingDir, "unraid", "compose", "media.yml"))
+		require.NoError(t, err)
+		assert.Equal(t, "host: example.com", string(content))
+	})
+
+	t.Run("missing compose at ref fails", func(t *testing.T) {
+		r, cfg := newReconciler(t, &pinGitOps{})
+		pin(t, cfg.StateDir, "media", "v1")
+
+		err := r.applyPins(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "media")
+	})
+}
+
+func TestReconciler_TemplateData(t *testing.T) {
+	newReconciler := func(facts *hostmetrics.Facts, err error) (*Reconciler, *string) {
+		var gotTarget string
+		r := NewReconciler(&Config{LocalAppdataPath: "/nonexistent", TargetHost: "root@tower"})
+		r.gatherFacts = func(_ context.Context, target string) (*hostmetrics.Facts, error) {
+			gotTarget = target
+			return facts, err
+		}
+		return r, &gotTarget
+	}
+
+	t.Run("adds deploy host facts", func(t *testing.T) {
+		r, target := newReconciler(&hostmetrics.Facts{IP: "192.168.1.8"}, nil)
+		secrets := map[string]any{"domain": "example.com"}
+
+		data := r.templateData(context.Background(), secrets)
+		assert.Equal(t, "root@tower", *target)
+		assert.Equal(t, map[string]any{"ip": "192.168.1.8"}, data["host"])
+		assert.Equal(t, "example.com", data["domain"])
+		assert.NotContains(t, secrets, "host", "secrets are not modified")
+	})
+
+	t.Run("secrets host key wins", func(t *testing.T) {
+		r, _ := newReconciler(&hostmetrics.Facts{IP: "192.168.1.8"}, nil)
+		data := r.templateData(context.Background(), map[string]any{"host": "custom"})
+		assert.Equal(t, "custom", data["host"])
+	})
+
+	t.Run("unavailable facts are skipped", func(t *testing.T) {
+		r, _ := newReconciler(nil, assert.AnError)
+		data := r.templateData(context.Background(), map[string]any{"domain": "example.com"})
+		assert.NotContains(t, data, "host")
+	})
+}
+
+func TestReconciler_Progress(t *testing.T) {
+	cfg := &Config{StagingDir: t.TempDir(), StateDir: t.TempDir(), InfraSubDir: "."}
+
+	var steps []string
+	r := NewReconciler(cfg,
+		WithGitOperations(&pinGitOps{}),
+		WithLockFile(filepath.Join(t.TempDir(), "reconcile.lock")),
+		WithProgress(func(p Progress) { steps = append(steps, p.Step) }),
+	)
+
+	// No changes: only the sync step runs
+	require.NoError(t, r.Run(context.Background()))
+	assert.Equal(t, []string{StepSync}, steps)
+}
+
+// headGitOps syncs with no new commits at head.
+type headGitOps struct {
+	pinGitOps
+	head string
+}
+
+func (g *headGitOps) Sync(context.Context) (bool, string, string, error) {
+	return false, g.head, g.head, nil
+}
+
+func TestReconciler_CommitRange(t *testing.T) {
+	cfg := &Config{StagingDir: t.TempDir(), StateDir: t.TempDir(), InfraSubDir: "."}
+	r := NewReconciler(cfg,
+		WithGitOperations(&headGitOps{head: "abc1234"}),
+		WithLockFile(filepath.Join(t.TempDir(), "reconcile.lock")),
+	)
+
+	require.NoError(t, r.Run(context.Background()))
+	from, to := r.CommitRange()
+	assert.Equal(t, "abc1234", from)
+	assert.Equal(t, "abc1234", to)
+
+	// A run that fails before syncing has no range
+	r.lockFile = filepath.Join(t.TempDir(), "missing", "reconcile.lock")
+	require.Error(t, r.Run(context.Background()))
+	from, to = r.CommitRange()
+	assert.Empty(t, from)
+	assert.Empty(t, to)
+}
+
+// recordingAlerter records the alerts a reconciler sends.
+type recordingAlerter struct {
+	sent []string
+}
+
+func (a *recordingAlerter) SendDeploySuccess(_ context.Context, commit, target string) error {
+	a.sent = append(a.sent, "deploy-success "+target)
+	return nil
+}
+
+func (a *recordingAlerter) SendDeployFailure(_ context.Context, commit, target, reason string) error {
+	a.sent = append(a.sent, "deploy-failure "+target)
+	return nil
+}
+
+func (a *recordingAlerter) SendRollbackSuccess(_ context.Context, target, backupName string) error {
+	a.sent = append(a.sent, "rollback-success "+target+" "+backupName)
+	return nil
+}
+
+func (a *recordingAlerter) SendRollbackFailure(_ context.Context, target, reason string) error {
+	a.sent = append(a.sent, "rollback-failure "+target)
+	return nil
+}
+
+func (a *recordingAlerter) SendPermissionRegression(_ context.Context, target string, issues []string) error {
+	a.sent = append(a.sent, "permissions "+target)
+	return nil
+}
+
+func TestSendRollbackAlert(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "rollback succeeded",
+			err:  fmt.Errorf("stack core: %w", ErrRollbackSucceeded),
+			want: []string{"rollback-success local backup-20250101-000000"},
+		},
+		{
+			name: "rollback failed in one of several stacks",
+			err:  errors.Join(fmt.Errorf("stack core: %w", ErrRollbackSucceeded), fmt.Errorf("stack media: %w", ErrRollbackFailed)),
+			want: []string{"rollback-failure local"},
+		},
+		{
+			name: "no rollback attempted",
+			err:  ErrDockerUnavailable,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerter := &recordingAlerter{}
+			r := NewReconciler(DefaultConfig(), WithAlerter(alerter))
+			r.lastBackupPath = filepath.Join("/app/backups", "backup-20250101-000000")
+
+			r.sendRollbackAlert(context.Background(), tt.err)
+			assert.Equal(t, tt.want, alerter.sent)
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/lint"
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// FixtureKind identifies a recorded reconcile context.
+const FixtureKind = "ReconcileFixture"
+
+// FixtureFile is where each reconcile records its context, in StateDir.
+const FixtureFile = "last-reconcile.yml"
+
+// reloadedStack is the compose file the deploy step brings up.
+const reloadedStack = "core"
+
+// Fixture is a recorded reconcile context: the commit pair, the files that
+// changed, and the shape of the secrets with fake values. It holds enough to
+// replay the render and deploy planning without the real secrets or target.
+type Fixture struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Kind       string    `yaml:"kind"`
+	RecordedAt time.Time `yaml:"recordedAt"`
+
+	// Before and After are the commits the reconcile moved between.
+	// Before is empty for a fresh clone.
+	Before string `yaml:"before,omitempty"`
+	After  string `yaml:"after"`
+
+	// ChangedFiles lists repo paths changed between Before and After.
+	ChangedFiles []string `yaml:"changedFiles,omitempty"`
+
+	InfraDir string            `yaml:"infraDir,omitempty"`
+	Target   string            `yaml:"target,omitempty"`
+	Force    bool              `yaml:"force,omitempty"`
+	Pins     map[string]string `yaml:"pins,omitempty"`
+
+	// Secrets has the structure of the decrypted secrets with fake string values.
+	Secrets map[string]any `yaml:"secrets,omitempty"`
+	// Host holds the deploy host facts templates saw.
+	Host map[string]any `yaml:"host,omitempty"`
+}
+
+// LoadFixture reads a recorded reconcile context.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+
+	var f Fixture
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixture: %w", err)
+	}
+	if f.Kind != FixtureKind {
+		return nil, fmt.Errorf("not a reconcile fixture: kind is %q, want %q", f.Kind, FixtureKind)
+	}
+	if f.After == "" {
+		return nil, fmt.Errorf("fixture has no 'after' commit")
+	}
+	return &f, nil
+}
+
+// Save writes the fixture to path.
+func (f *Fixture) Save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("marshal fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// FakeSecrets returns a copy of secrets with every string replaced by a
+// placeholder naming its key path (e.g. "fake:network.unraid_ip"). Other
+// scalars are kept so templates that branch on them render the same way.
+func FakeSecrets(secrets map[string]any) map[string]any {
+	return fakeValue("", secrets).(map[string]any)
+}
+
+func fakeValue(path string, v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			key := k
+			if path != "" {
+				key = path + "." + k
+			}
+			out[k] = fakeValue(key, item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = fakeValue(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		return out
+	case string:
+		return "fake:" + path
+	default:
+		return val
+	}
+}
+
+// recordFixture saves this run's context to StateDir for 'bosun replay'.
+// Best-effort: failures only warn.
+func (r *Reconciler) recordFixture(ctx context.Context, before, after string, secrets map[string]any) {
+	if r.config.StateDir == "" || r.config.DryRun {
+		return
+	}
+
+	f := &Fixture{
+		APIVersion: "bosun.io/v1",
+		Kind:       FixtureKind,
+		RecordedAt: time.Now().UTC().Truncate(time.Second),
+		Before:     before,
+		After:      after,
+		InfraDir:   r.config.InfraSubDir,
+		Target:     r.config.TargetHost,
+		Force:      r.config.Force,
+		Secrets:    FakeSecrets(secrets),
+	}
+
+	if lister, ok := r.git.(changeLister); ok && before != "" && before != after {
+		if files, err := lister.ChangedFiles(ctx, before, after); err == nil {
+			f.ChangedFiles = files
+		}
+	}
+
+	if st, err := state.NewStore(r.config.StateDir).Load(); err == nil {
+		for _, stack := range st.PinnedStacks() {
+			if f.Pins == nil {
+				f.Pins = make(map[string]string)
+			}
+			f.Pins[stack] = st.Pins[stack].Ref
+		}
+	}
+
+	if r.template != nil {
+		if host, ok := r.template.Data["host"].(map[string]any); ok {
+			f.Host = host
+		}
+	}
+
+	if err := f.Save(filepath.Join(r.config.StateDir, FixtureFile)); err != nil {
+		ui.Warning("Could not record reconcile context: %v", err)
+	}
+}
+
+// Plan is what a replayed reconcile would have done.
+type Plan struct {
+	Before string
+	After  string
+
+	// Skipped is set when nothing changed and the run was not forced.
+	Skipped bool
+
+	// ChangedFiles lists repo paths changed between the commits.
+	ChangedFiles []string
+	// Files lists rendered files that would be synced to the target.
+	Files []FileChange
+	// Services lists compose services that would be created, recreated, or removed.
+	Services []ServiceChange
+	// LintErrors lists lint errors that would have blocked deployment (in block mode).
+	LintErrors []string
+	// Actions lists the reload steps the deploy would run.
+	Actions []string
+}
+
+// FileChange is a rendered file that differs between the two commits.
+type FileChange struct {
+	Path   string // Relative to the staging unraid directory
+	Action string // "add", "modify", or "remove"
+}
+
+// ServiceChange is a compose service whose definition differs.
+type ServiceChange struct {
+	Stack   string   // Compose file name without extension
+	Service string   // Service name
+	Action  string   // "create", "recreate", or "remove"
+	Fields  []string // Top-level service keys that changed (recreate only)
+}
+
+// Replay re-runs the render stage of a recorded reconcile against the repo
+// in repoDir, with the fixture's fake secrets, and compares the output for
+// both commits to produce the deploy plan. Nothing is deployed.
+func Replay(ctx context.Context, f *Fixture, repoDir string) (*Plan, error) {
+	plan := &Plan{Before: f.Before, After: f.After, ChangedFiles: f.ChangedFiles}
+	if f.Before == f.After && !f.Force {
+		plan.Skipped = true
+		return plan, nil
+	}
+
+	git := NewGitOps("", "", repoDir)
+	if len(plan.ChangedFiles) == 0 && f.Before != "" && f.Before != f.After {
+		files, err := git.ChangedFiles(ctx, f.Before, f.After)
+		if err != nil {
+			return nil, err
+		}
+		plan.ChangedFiles = files
+	}
+
+	workDir, err := os.MkdirTemp("", "bosun-replay-*")
+	if err != nil {
+		return nil, fmt.Errorf("create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	render := func(ref, name string) (string, error) {
+		staging := filepath.Join(workDir, name, "staging")
+		if ref == "" {
+			return staging, os.MkdirAll(staging, 0755)
+		}
+		return staging, renderAtRef(ctx, git, f, ref, filepath.Join(workDir, name), staging)
+	}
+
+	beforeDir, err := render(f.Before, "before")
+	if err != nil {
+		return nil, fmt.Errorf("render %s: %w", f.Before, err)
+	}
+	afterDir, err := render(f.After, "after")
+	if err != nil {
+		return nil, fmt.Errorf("render %s: %w", f.After, err)
+	}
+
+	beforeUnraid := filepath.Join(beforeDir, "unraid")
+	afterUnraid := filepath.Join(afterDir, "unraid")
+
+	if plan.Files, err = diffDirs(beforeUnraid, afterUnraid); err != nil {
+		return nil, err
+	}
+	if plan.Services, err = diffComposeServices(filepath.Join(beforeUnraid, "compose"), filepath.Join(afterUnraid, "compose")); err != nil {
+		return nil, err
+	}
+
+	if result, err := lint.ComposeDir(filepath.Join(afterUnraid, "compose")); err == nil {
+		for _, finding := range result.Errors() {
+			plan.LintErrors = append(plan.LintErrors, finding.String())
+		}
+	}
+	composeFiles, _ := stackComposeFiles(filepath.Join(afterUnraid, "compose"))
+	traefikDir := filepath.Join(afterUnraid, "appdata", "traefik")
+	if result, err := lint.TraefikFile(filepath.Join(traefikDir, "dynamic.yml"), lint.TraefikEntryPoints(traefikDir, composeFiles)); err == nil {
+		for _, finding := range result.Errors() {
+			plan.LintErrors = append(plan.LintErrors, finding.String())
+		}
+	}
+
+	plan.Actions = []string{
+		fmt.Sprintf("docker compose up -d --remove-orphans (%s.yml)", reloadedStack),
+		"SIGHUP agentgateway",
+	}
+	return plan, nil
+}
+
+// renderAtRef exports the infra tree at ref into workDir/src and renders it
+// into staging with the fixture's secrets, host facts, and pins.
+func renderAtRef(ctx context.Context, git *GitOps, f *Fixture, ref, workDir, staging string) error {
+	infraDir := f.InfraDir
+	if infraDir == "" {
+		infraDir = "."
+	}
+
+	src := filepath.Join(workDir, "src")
+	if err := git.ExportTree(ctx, ref, infraDir, src); err != nil {
+		return err
+	}
+
+	data := make(map[string]any, len(f.Secrets)+1)
+	for k, v := range f.Secrets {
+		data[k] = v
+	}
+	if _, ok := data["host"]; !ok && f.Host != nil {
+		data["host"] = f.Host
+	}
+
+	// Pins live in a scratch state store so the replayer can reuse applyPins.
+	stateDir := filepath.Join(workDir, "state")
+	if len(f.Pins) > 0 {
+		st := &state.State{}
+		for stack, pinRef := range f.Pins {
+			st.SetPin(stack, pinRef, f.RecordedAt)
+		}
+		if err := state.NewStore(stateDir).Save(st); err != nil {
+			return err
+		}
+	}
+
+	r := &Reconciler{
+		config:   &Config{RepoDir: git.Dir, StagingDir: staging, StateDir: stateDir, InfraSubDir: infraDir},
+		git:      git,
+		template: NewTemplateOps(data),
+	}
+	if err := r.template.RenderDirectory(ctx, src, staging, "unraid"); err != nil {
+		return err
+	}
+	return r.applyPins(ctx)
+}
+
+// diffDirs compares two directory trees file by file.
+func diffDirs(before, after string) ([]FileChange, error) {
+	beforeFiles, err := listFiles(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFiles, err := listFiles(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for path := range afterFiles {
+		if _, ok := beforeFiles[path]; !ok {
+			changes = append(changes, FileChange{Path: path, Action: "add"})
+			continue
+		}
+		a, _ := os.ReadFile(filepath.Join(before, path))
+		b, _ := os.ReadFile(filepath.Join(after, path))
+		if !bytes.Equal(a, b) {
+			changes = append(changes, FileChange{Path: path, Action: "modify"})
+		}
+	}
+	for path := range beforeFiles {
+		if _, ok := afterFiles[path]; !ok {
+			changes = append(changes, FileChange{Path: path, Action: "remove"})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// listFiles returns the slash-separated relative paths of files under dir.
+// A missing dir has no files.
+func listFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return files, err
+}
+
+// diffComposeServices compares the services in each compose file of two
+// rendered compose directories.
+func diffComposeServices(beforeDir, afterDir string) ([]ServiceChange, error) {
+	stacks := make(map[string]bool)
+	for _, dir := range []string{beforeDir, afterDir} {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+		for _, m := range matches {
+			stacks[strings.TrimSuffix(filepath.Base(m), ".yml")] = true
+		}
+	}
+
+	names := make([]string, 0, len(stacks))
+	for name := range stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []ServiceChange
+	for _, stack := range names {
+		before, err := composeServices(filepath.Join(beforeDir, stack+".yml"))
+		if err != nil {
+			return nil, err
+		}
+		after, err := composeServices(filepath.Join(afterDir, stack+".yml"))
+		if err != nil {
+			return nil, err
+		}
+
+		services := make([]string, 0, len(before)+len(after))
+		for name := range after {
+			services = append(services, name)
+		}
+		for name := range before {
+			if _, ok := after[name]; !ok {
+				services = append(services, name)
+			}
+		}
+		sort.Strings(services)
+
+		for _, name := range services {
+			old, hadOld := before[name]
+			cur, hasCur := after[name]
+			switch {
+			case !hadOld:
+				changes = append(changes, ServiceChange{Stack: stack, Service: name, Action: "create"})
+			case !hasCur:
+				changes = append(changes, ServiceChange{Stack: stack, Service: name, Action: "remove"})
+			default:
+				if fields := changedFields(old, cur); len(fields) > 0 {
+					changes = append(changes, ServiceChange{Stack: stack, Service: name, Action: "recreate", Fields: fields})
+				}
+			}
+		}
+	}
+	return changes, nil
+}
+
+// composeServices returns the service definitions in a compose file.
+// A missing file has no services.
+func composeServices(path string) (map[string]map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var compose struct {
+		Services map[string]map[string]any `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return compose.Services, nil
+}
+
+// changedFields returns the top-level keys whose values differ.
+func changedFields(before, after map[string]any) []string {
+	var fields []string
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,125 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitFiles writes files into the worktree and commits them, returning the hash.
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) string {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+		_, err := worktree.Add(path)
+		require.NoError(t, err)
+	}
+
+	hash, err := worktree.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+
+	before := commitFiles(t, repo, repoDir, map[string]string{
+		"unraid/compose/core.yml.tmpl": "services:\n  traefik:\n    image: traefik:v3.0\n    environment:\n      IP: {{ .network.ip }}\n  whoami:\n    image: traefik/whoami\n",
+		"unraid/appdata/gatus.yml":     "endpoints: []\n",
+	})
+	after := commitFiles(t, repo, repoDir, map[string]string{
+		"unraid/compose/core.yml.tmpl": "services:\n  traefik:\n    image: traefik:v3.1\n    environment:\n      IP: {{ .network.ip }}\n  whoami:\n    image: traefik/whoami\n  gatus:\n    image: twinproduction/gatus\n",
+	})
+
+	fixture := &Fixture{
+		Kind:    FixtureKind,
+		Before:  before,
+		After:   after,
+		Secrets: FakeSecrets(map[string]any{"network": map[string]any{"ip": "10.0.0.5"}}),
+	}
+
+	plan, err := Replay(ctx, fixture, repoDir)
+	require.NoError(t, err)
+
+	assert.False(t, plan.Skipped)
+	assert.Equal(t, []string{"unraid/compose/core.yml.tmpl"}, plan.ChangedFiles)
+	assert.Equal(t, []FileChange{{Path: "compose/core.yml", Action: "modify"}}, plan.Files)
+	assert.Equal(t, []ServiceChange{
+		{Stack: "core", Service: "gatus", Action: "create"},
+		{Stack: "core", Service: "traefik", Action: "recreate", Fields: []string{"image"}},
+	}, plan.Services)
+	assert.NotEmpty(t, plan.Actions)
+
+	t.Run("unchanged and not forced is skipped", func(t *testing.T) {
+		plan, err := Replay(ctx, &Fixture{Kind: FixtureKind, Before: after, After: after}, repoDir)
+		require.NoError(t, err)
+		assert.True(t, plan.Skipped)
+	})
+
+	t.Run("fresh clone adds everything", func(t *testing.T) {
+		plan, err := Replay(ctx, &Fixture{Kind: FixtureKind, After: after, Secrets: fixture.Secrets}, repoDir)
+		require.NoError(t, err)
+		assert.Len(t, plan.Files, 2)
+		assert.Len(t, plan.Services, 3)
+	})
+}
+
+func TestFakeSecrets(t *testing.T) {
+	secrets := map[string]any{
+		"network": map[string]any{"ip": "10.0.0.5", "port": 443},
+		"tokens":  []any{"abc", true},
+	}
+
+	assert.Equal(t, map[string]any{
+		"network": map[string]any{"ip": "fake:network.ip", "port": 443},
+		"tokens":  []any{"fake:tokens[0]", true},
+	}, FakeSecrets(secrets))
+}
+
+func TestFixture_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FixtureFile)
+	f := &Fixture{
+		Kind:       FixtureKind,
+		RecordedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Before:     "abc",
+		After:      "def",
+		Pins:       map[string]string{"core": "v1.0.0"},
+	}
+	require.NoError(t, f.Save(path))
+
+	loaded, err := LoadFixture(path)
+	require.NoError(t, err)
+	assert.Equal(t, f, loaded)
+
+	t.Run("rejects other kinds", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.yml")
+		require.NoError(t, os.WriteFile(bad, []byte("kind: Manifest\nafter: def\n"), 0644))
+		_, err := LoadFixture(bad)
+		assert.ErrorContains(t, err, "not a reconcile fixture")
+	})
+}
+
+func TestChangedFields(t *testing.T) {
+	before := map[string]any{"image": "a:1", "ports": []any{"80:80"}, "restart": "always"}
+	after := map[string]any{"image": "a:2", "ports": []any{"80:80"}, "labels": []any{"x=y"}}
+
+	assert.Equal(t, []string{"image", "labels", "restart"}, changedFields(before, after))
+	assert.Empty(t, changedFields(before, before))
+}
@@ -0,0 +1,102 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+// SourceRollback is the trigger source of deploys made by Rollback. The
+// deploy history records it with the ref, e.g. "rollback:v1.4.0".
+const SourceRollback = "rollback"
+
+// revisionGit syncs to a fixed revision instead of the tracked branch. Sync
+// checks the revision out of the tracking clone into a staging tree; reads
+// of other refs and diffs go to the tracking clone, which stays on its
+// branch.
+type revisionGit struct {
+	*GitOps
+	ref      string
+	deployed string // Commit currently deployed, "" if unknown
+	dir      string // Staging tree the revision is checked out into
+}
+
+// Sync checks out the revision and reports a change from the deployed
+// commit to it. It runs under the reconcile lock, so fetching history for
+// an old ref can't race a pull.
+func (g *revisionGit) Sync(ctx context.Context) (bool, string, string, error) {
+	if !g.IsRepo(ctx) {
+		if err := g.Clone(ctx, 1); err != nil {
+			return false, "", "", err
+		}
+	}
+
+	commit, err := g.commitAtRef(ctx, g.ref)
+	if err != nil {
+		return false, "", "", err
+	}
+	target := commit.Hash.String()
+
+	if err := os.RemoveAll(g.dir); err != nil {
+		return false, "", "", fmt.Errorf("clear rollback checkout: %w", err)
+	}
+	if err := g.ExportTree(ctx, target, ".", g.dir); err != nil {
+		return false, "", "", fmt.Errorf("check out %s: %w", g.ref, err)
+	}
+	return g.deployed != target, g.deployed, target, nil
+}
+
+// Rollback deploys the repository as of ref (a commit, tag, or branch)
+// through the full reconcile: the revision is checked out into a staging
+// tree, its secrets decrypted and templates rendered, then linted, backed
+// up, and deployed like any other commit. The deploy history records it
+// under SourceRollback, so 'bosun log' shows the rollback.
+//
+// The tracking clone stays on its branch: the next reconcile that sees a
+// new commit on the branch deploys over the rollback.
+func Rollback(ctx context.Context, cfg *Config, ref string, opts ...ReconcilerOption) error {
+	if err := ValidateRef(ref); err != nil {
+		return fmt.Errorf("invalid ref: %w", err)
+	}
+
+	workDir, err := os.MkdirTemp("", rollbackTempPattern)
+	if err != nil {
+		return fmt.Errorf("create rollback directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	tracking := NewGitOps(cfg.RepoURL, cfg.RepoBranch, cfg.RepoDir)
+	rollbackCfg := *cfg
+	rollbackCfg.RepoDir = filepath.Join(workDir, "repo")
+
+	git := &revisionGit{
+		GitOps:   tracking,
+		ref:      ref,
+		deployed: deployedCommit(ctx, cfg, tracking),
+		dir:      rollbackCfg.RepoDir,
+	}
+
+	r := NewReconciler(&rollbackCfg, append(opts, WithGitOperations(git))...)
+	return r.Run(WithTrigger(ctx, SourceRollback+":"+ref))
+}
+
+// deployedCommit returns the commit of the last recorded deploy, falling
+// back to the tracking clone's HEAD, or "" if neither is known.
+func deployedCommit(ctx context.Context, cfg *Config, tracking *GitOps) string {
+	if cfg.StateDir != "" {
+		if st, err := state.NewStore(cfg.StateDir).Load(); err == nil {
+			if d, ok := st.LastDeploy(); ok && d.Commit != "" {
+				return d.Commit
+			}
+		}
+	}
+	if tracking.IsRepo(ctx) {
+		if commit, err := tracking.GetLatestCommit(ctx); err == nil {
+			return commit
+		}
+	}
+	return ""
+}
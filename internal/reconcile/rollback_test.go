@@ -0,0 +1,111 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+)
+
+func TestRevisionGit_Sync(t *testing.T) {
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+
+	good := commitFiles(t, repo, repoDir, map[string]string{
+		"unraid/compose/core.yml": "services:\n  traefik:\n    image: traefik:v3.0\n",
+	})
+	_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(good), nil)
+	require.NoError(t, err)
+	bad := commitFiles(t, repo, repoDir, map[string]string{
+		"unraid/compose/core.yml": "services:\n  traefik:\n    image: traefik:v3.1\n",
+		"unraid/compose/new.yml":  "services: {}\n",
+	})
+
+	newGit := func(ref string) *revisionGit {
+		return &revisionGit{
+			GitOps:   NewGitOps("", "main", repoDir),
+			ref:      ref,
+			deployed: bad,
+			dir:      filepath.Join(t.TempDir(), "repo"),
+		}
+	}
+
+	t.Run("checks out the revision", func(t *testing.T) {
+		g := newGit("v1.0.0")
+		changed, before, after, err := g.Sync(ctx)
+		require.NoError(t, err)
+
+		assert.True(t, changed)
+		assert.Equal(t, bad, before)
+		assert.Equal(t, good, after)
+
+		data, err := os.ReadFile(filepath.Join(g.dir, "unraid", "compose", "core.yml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "traefik:v3.0")
+		assert.NoFileExists(t, filepath.Join(g.dir, "unraid", "compose", "new.yml"))
+	})
+
+	t.Run("leaves the tracking clone on its branch", func(t *testing.T) {
+		_, _, _, err := newGit(good).Sync(ctx)
+		require.NoError(t, err)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+		assert.Equal(t, bad, head.Hash().String())
+	})
+
+	t.Run("deployed revision is unchanged", func(t *testing.T) {
+		changed, _, _, err := newGit(bad).Sync(ctx)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, _, _, err := newGit("v9.9.9").Sync(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeployedCommit(t *testing.T) {
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	head := commitFiles(t, repo, repoDir, map[string]string{"README.md": "infra\n"})
+	tracking := NewGitOps("", "main", repoDir)
+
+	t.Run("falls back to HEAD", func(t *testing.T) {
+		cfg := &Config{StateDir: t.TempDir()}
+		assert.Equal(t, head, deployedCommit(ctx, cfg, tracking))
+	})
+
+	t.Run("prefers the last recorded deploy", func(t *testing.T) {
+		cfg := &Config{StateDir: t.TempDir()}
+		st := &state.State{}
+		st.RecordDeploy(state.Deploy{At: time.Now(), Source: "webhook", Commit: "abc1234"})
+		require.NoError(t, state.NewStore(cfg.StateDir).Save(st))
+
+		assert.Equal(t, "abc1234", deployedCommit(ctx, cfg, tracking))
+	})
+
+	t.Run("unknown without a clone", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Empty(t, deployedCommit(ctx, cfg, NewGitOps("", "main", t.TempDir())))
+	})
+}
+
+func TestRollback_InvalidRef(t *testing.T) {
+	err := Rollback(context.Background(), &Config{}, "--upload-pack=evil")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ref")
+}
@@ -0,0 +1,135 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+)
+
+// ErrNoMatchingKey is returned when none of a SOPS file's age recipients
+// match the identities it is checked against.
+var ErrNoMatchingKey = errors.New("no age recipient matches the key")
+
+// RotateSOPS re-encrypts a SOPS file for a new list of age recipients. The
+// file is decrypted with the current age key (found the same way as for
+// Decrypt), its integrity is checked, and it is encrypted again under a
+// fresh data key. The recipients replace every existing master key,
+// including any non-age ones. path selects the file format.
+func RotateSOPS(data []byte, path string, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no age recipients")
+	}
+	store := common.StoreForFormat(formats.FormatForPath(path), config.NewStoresConfig())
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotSOPSFile, path, err)
+	}
+	key, err := tree.Metadata.GetDataKey()
+	if err != nil {
+		return nil, fmt.Errorf("get data key for %s: %w", path, sanitizeDecryptError(err))
+	}
+	if err := decryptTree(&tree, key); err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+
+	masterKeys, err := sopsage.MasterKeysFromRecipients(strings.Join(recipients, ","))
+	if err != nil {
+		return nil, fmt.Errorf("parse age recipients: %w", err)
+	}
+	if err := encryptTree(&tree, masterKeys); err != nil {
+		return nil, fmt.Errorf("encrypt %s: %w", path, err)
+	}
+
+	out, err := store.EmitEncryptedFile(tree)
+	if err != nil {
+		return nil, fmt.Errorf("write %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// VerifySOPS checks that a SOPS file decrypts, with intact integrity, using
+// only the given age identities. It ignores SOPS_AGE_KEY and the other key
+// locations, so it proves the identities alone can open the file.
+func VerifySOPS(data []byte, path string, identities []age.Identity) error {
+	store := common.StoreForFormat(formats.FormatForPath(path), config.NewStoresConfig())
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrNotSOPSFile, path, err)
+	}
+
+	var key []byte
+	for _, group := range tree.Metadata.KeyGroups {
+		for _, mk := range group {
+			ageKey, ok := mk.(*sopsage.MasterKey)
+			if !ok {
+				continue
+			}
+			sopsage.ParsedIdentities(identities).ApplyToMasterKey(ageKey)
+			if k, err := ageKey.Decrypt(); err == nil {
+				key = k
+				break
+			}
+		}
+	}
+	if key == nil {
+		return fmt.Errorf("%s: %w", path, ErrNoMatchingKey)
+	}
+	if err := decryptTree(&tree, key); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// decryptTree decrypts tree in place and checks its MAC, so a tampered file
+// is never re-encrypted as if it were genuine.
+func decryptTree(tree *sops.Tree, key []byte) error {
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(key, cipher)
+	if err != nil {
+		return sanitizeDecryptError(err)
+	}
+	originalMac, err := cipher.Decrypt(
+		tree.Metadata.MessageAuthenticationCode,
+		key,
+		tree.Metadata.LastModified.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("decrypt MAC: %w", sanitizeDecryptError(err))
+	}
+	if originalMac != mac {
+		return errors.New("integrity check failed: MAC mismatch")
+	}
+	return nil
+}
+
+// encryptTree encrypts a decrypted tree for masterKeys under a new data key.
+func encryptTree(tree *sops.Tree, masterKeys []*sopsage.MasterKey) error {
+	group := make(sops.KeyGroup, len(masterKeys))
+	for i, k := range masterKeys {
+		group[i] = k
+	}
+	tree.Metadata.KeyGroups = []sops.KeyGroup{group}
+	tree.Metadata.ShamirThreshold = 0
+	tree.Metadata.DataKey = nil
+
+	dataKey, errs := tree.GenerateDataKey()
+	if len(errs) > 0 {
+		return fmt.Errorf("encrypt data key: %w", errors.Join(errs...))
+	}
+	return common.EncryptTree(common.EncryptTreeOpts{
+		Tree:    tree,
+		Cipher:  aes.NewCipher(),
+		DataKey: dataKey,
+	})
+}
@@ -0,0 +1,86 @@
+package reconcile
+
+import (
+	"testing"
+
+	"filippo.io/age"
+	"github.com/getsops/sops/v3"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/decrypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptForTest encrypts plain YAML for recipient, as 'sops --encrypt' would.
+func encryptForTest(t *testing.T, plain string, recipient *age.X25519Identity) []byte {
+	t.Helper()
+	store := common.StoreForFormat(formats.Yaml, config.NewStoresConfig())
+	branches, err := store.LoadPlainFile([]byte(plain))
+	require.NoError(t, err)
+
+	tree := sops.Tree{
+		Branches: branches,
+		Metadata: sops.Metadata{UnencryptedSuffix: "_unencrypted", Version: "3.11.0"},
+	}
+	keys, err := sopsage.MasterKeysFromRecipients(recipient.Recipient().String())
+	require.NoError(t, err)
+	require.NoError(t, encryptTree(&tree, keys))
+
+	data, err := store.EmitEncryptedFile(tree)
+	require.NoError(t, err)
+	return data
+}
+
+func TestRotateSOPS(t *testing.T) {
+	oldKey, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	newKey, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	encrypted := encryptForTest(t, "db:\n  password: hunter2\n", oldKey)
+	require.NoError(t, VerifySOPS(encrypted, "secrets.sops.yaml", []age.Identity{oldKey}))
+
+	t.Setenv("SOPS_AGE_KEY", oldKey.String())
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	rotated, err := RotateSOPS(encrypted, "secrets.sops.yaml", []string{newKey.Recipient().String()})
+	require.NoError(t, err)
+
+	t.Run("opens with the new key only", func(t *testing.T) {
+		require.NoError(t, VerifySOPS(rotated, "secrets.sops.yaml", []age.Identity{newKey}))
+
+		err := VerifySOPS(rotated, "secrets.sops.yaml", []age.Identity{oldKey})
+		assert.ErrorIs(t, err, ErrNoMatchingKey)
+	})
+
+	t.Run("keeps the secrets", func(t *testing.T) {
+		t.Setenv("SOPS_AGE_KEY", newKey.String())
+		plain, err := decrypt.Data(rotated, "yaml")
+		require.NoError(t, err)
+		assert.Contains(t, string(plain), "password: hunter2")
+	})
+
+	t.Run("lists the new recipient", func(t *testing.T) {
+		assert.Contains(t, string(rotated), newKey.Recipient().String())
+		assert.NotContains(t, string(rotated), oldKey.Recipient().String())
+	})
+
+	t.Run("fails without the current key", func(t *testing.T) {
+		t.Setenv("SOPS_AGE_KEY", newKey.String())
+		_, err := RotateSOPS(encrypted, "secrets.sops.yaml", []string{newKey.Recipient().String()})
+		assert.Error(t, err)
+	})
+
+	t.Run("needs recipients", func(t *testing.T) {
+		_, err := RotateSOPS(encrypted, "secrets.sops.yaml", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects plain files", func(t *testing.T) {
+		_, err := RotateSOPS([]byte("key: value\n"), "plain.yaml", []string{newKey.Recipient().String()})
+		assert.ErrorIs(t, err, ErrNotSOPSFile)
+	})
+}
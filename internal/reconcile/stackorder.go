@@ -0,0 +1,210 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/selector"
+)
+
+// ErrDependencyUnhealthy indicates a stack was not reloaded because a stack
+// it depends on did not come up healthy. Its previous containers are left
+// running.
+var ErrDependencyUnhealthy = errors.New("skipped, a stack it depends on is not healthy")
+
+// datastoreImages are the image names (last path element, no tag) of
+// databases and caches that apps connect to.
+var datastoreImages = map[string]bool{
+	"postgres":  true,
+	"postgis":   true,
+	"mysql":     true,
+	"mariadb":   true,
+	"redis":     true,
+	"valkey":    true,
+	"keydb":     true,
+	"mongo":     true,
+	"memcached": true,
+}
+
+// orderCompose is the part of a compose file stack ordering reads.
+type orderCompose struct {
+	Services map[string]struct {
+		Image     string `yaml:"image"`
+		DependsOn any    `yaml:"depends_on"`
+		Labels    any    `yaml:"labels"`
+	} `yaml:"services"`
+}
+
+// stackDependencies returns, for each stack compose file, the stacks that
+// must be up before it, sorted:
+//   - stacks holding a service it lists in depends_on
+//   - the stack running Traefik, if it has Traefik-routed services
+//   - data stacks (only databases and caches), unless it is one itself
+//
+// depends_on within a stack is left to compose.
+func stackDependencies(files []string) (map[string][]string, error) {
+	type stackInfo struct {
+		dependsOn []string // Services named in depends_on
+		routed    bool     // Has a Traefik-routed service
+		data      bool     // Only databases and caches
+	}
+
+	stacks := make(map[string]*stackInfo)
+	owner := make(map[string]string) // Service to the stack defining it
+	traefikStack := ""
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		var compose orderCompose
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		info := &stackInfo{data: len(compose.Services) > 0}
+		for name, svc := range compose.Services {
+			owner[name] = stack
+			image := imageName(svc.Image)
+			if name == "traefik" || image == "traefik" {
+				traefikStack = stack
+			}
+			if selector.ComposeLabels(svc.Labels)["traefik.enable"] == "true" {
+				info.routed = true
+			}
+			if !datastoreImages[image] {
+				info.data = false
+			}
+			info.dependsOn = append(info.dependsOn, dependsOnNames(svc.DependsOn)...)
+		}
+		stacks[stack] = info
+	}
+
+	deps := make(map[string][]string, len(stacks))
+	for stack, info := range stacks {
+		set := make(map[string]bool)
+		for _, svc := range info.dependsOn {
+			if dep, ok := owner[svc]; ok {
+				set[dep] = true
+			}
+		}
+		if info.routed && traefikStack != "" {
+			set[traefikStack] = true
+		}
+		if !info.data {
+			for other, otherInfo := range stacks {
+				if otherInfo.data {
+					set[other] = true
+				}
+			}
+		}
+		delete(set, stack)
+
+		list := make([]string, 0, len(set))
+		for dep := range set {
+			list = append(list, dep)
+		}
+		sort.Strings(list)
+		deps[stack] = list
+	}
+	return deps, nil
+}
+
+// orderStacks sorts stack compose files so each stack comes after the
+// stacks it depends on, keeping the order of files otherwise. Stacks on a
+// dependency cycle keep their relative order and are returned in cycle.
+func orderStacks(files []string, deps map[string][]string) (ordered, cycle []string) {
+	stackOf := func(file string) string { return strings.TrimSuffix(filepath.Base(file), ".yml") }
+	known := make(map[string]bool, len(files))
+	for _, file := range files {
+		known[stackOf(file)] = true
+	}
+
+	placed := make(map[string]bool, len(files))
+	remaining := slices.Clone(files)
+	for len(remaining) > 0 {
+		next := -1
+		for i, file := range remaining {
+			ready := true
+			for _, dep := range deps[stackOf(file)] {
+				if known[dep] && !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// Every remaining stack waits on another: a cycle.
+			next = 0
+			cycle = append(cycle, stackOf(remaining[0]))
+		}
+		placed[stackOf(remaining[next])] = true
+		ordered = append(ordered, remaining[next])
+		remaining = slices.Delete(remaining, next, next+1)
+	}
+	return ordered, cycle
+}
+
+// blockingDependencies returns the dependencies of a stack that are in
+// unhealthy.
+func blockingDependencies(deps []string, unhealthy map[string]bool) []string {
+	var blocked []string
+	for _, dep := range deps {
+		if unhealthy[dep] {
+			blocked = append(blocked, dep)
+		}
+	}
+	return blocked
+}
+
+// hasDependents reports whether any stack depends on stack.
+func hasDependents(deps map[string][]string, stack string) bool {
+	for _, list := range deps {
+		if slices.Contains(list, stack) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageName returns the last path element of an image reference, without
+// tag or digest, e.g. "postgres" for "docker.io/library/postgres:16".
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+	return strings.ToLower(image)
+}
+
+// dependsOnNames returns the service names from a depends_on list or map.
+func dependsOnNames(v any) []string {
+	var names []string
+	switch d := v.(type) {
+	case []any:
+		for _, item := range d {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case map[string]any:
+		for name := range d {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,136 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeStacks writes stack compose files to dir and returns their paths in
+// the order given.
+func writeStacks(t *testing.T, dir string, stacks [][2]string) []string {
+	t.Helper()
+	var files []string
+	for _, s := range stacks {
+		file := filepath.Join(dir, s[0]+".yml")
+		require.NoError(t, os.WriteFile(file, []byte(s[1]), 0644))
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestStackDependencies(t *testing.T) {
+	files := writeStacks(t, t.TempDir(), [][2]string{
+		{"apps", `services:
+  wiki:
+    image: ghcr.io/example/wiki:2
+    depends_on: [authelia]
+    labels:
+      traefik.enable: "true"
+  worker:
+    image: example/worker
+    depends_on:
+      wiki:
+        condition: service_healthy
+`},
+		{"core", `services:
+  proxy:
+    image: traefik:v3.0
+  authelia:
+    image: authelia/authelia
+    labels:
+      - traefik.enable=true
+`},
+		{"data", `services:
+  db:
+    image: docker.io/library/postgres:16
+  cache:
+    image: redis@sha256:abc
+`},
+		{"media", `services:
+  jellyfin:
+    image: jellyfin/jellyfin
+`},
+	})
+
+	deps, err := stackDependencies(files)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"apps":  {"core", "data"},
+		"core":  {"data"},
+		"data":  {},
+		"media": {"data"},
+	}, deps)
+}
+
+func TestStackDependencies_InvalidYAML(t *testing.T) {
+	files := writeStacks(t, t.TempDir(), [][2]string{{"apps", "services: [\n"}})
+	_, err := stackDependencies(files)
+	assert.ErrorContains(t, err, "parse")
+}
+
+func TestOrderStacks(t *testing.T) {
+	files := []string{"/c/core.yml", "/c/apps.yml", "/c/data.yml", "/c/media.yml"}
+
+	t.Run("dependencies first, file order otherwise", func(t *testing.T) {
+		ordered, cycle := orderStacks(files, map[string][]string{
+			"apps":  {"core", "data"},
+			"core":  {"data"},
+			"media": {"data"},
+		})
+		assert.Equal(t, []string{"/c/data.yml", "/c/core.yml", "/c/apps.yml", "/c/media.yml"}, ordered)
+		assert.Empty(t, cycle)
+	})
+
+	t.Run("dependencies outside the files are ignored", func(t *testing.T) {
+		ordered, _ := orderStacks([]string{"/c/apps.yml"}, map[string][]string{"apps": {"data"}})
+		assert.Equal(t, []string{"/c/apps.yml"}, ordered)
+	})
+
+	t.Run("cycles keep file order after the other stacks", func(t *testing.T) {
+		ordered, cycle := orderStacks(files, map[string][]string{
+			"core": {"apps"},
+			"apps": {"core"},
+		})
+		assert.Equal(t, []string{"/c/data.yml", "/c/media.yml", "/c/core.yml", "/c/apps.yml"}, ordered)
+		assert.Equal(t, []string{"core"}, cycle)
+	})
+}
+
+func TestImageName(t *testing.T) {
+	assert.Equal(t, "postgres", imageName("docker.io/library/postgres:16-alpine"))
+	assert.Equal(t, "redis", imageName("redis@sha256:abc"))
+	assert.Equal(t, "mariadb", imageName("lscr.io/linuxserver/MariaDB"))
+	assert.Equal(t, "registry", imageName("localhost:5000/registry"))
+}
+
+func TestReconciler_ReloadStacks_SkipsDependents(t *testing.T) {
+	dir := t.TempDir()
+	writeStacks(t, dir, [][2]string{
+		{"core", "services:\n  traefik:\n    image: traefik:v3.0\n"},
+		{"apps", "services:\n  wiki:\n    image: wiki\n    labels:\n      traefik.enable: \"true\"\n"},
+		{"tools", "services:\n  it-tools:\n    image: it-tools\n    depends_on: [wiki]\n"},
+	})
+
+	cfg := DefaultConfig()
+	cfg.Chaos = &Chaos{Rates: map[string]float64{ChaosComposeUp: 1}}
+	r := NewReconciler(cfg)
+	r.listProjects = func(ctx context.Context, host string) ([]ComposeProject, error) {
+		return nil, nil
+	}
+
+	// core fails without a backup to roll back to, so apps (routed by its
+	// Traefik) and tools (depending on apps) are never started.
+	healthy, err := r.reloadStacks(context.Background(), dir)
+	require.Error(t, err)
+	assert.False(t, healthy)
+	assert.True(t, errors.Is(err, ErrChaos))
+	assert.True(t, errors.Is(err, ErrDependencyUnhealthy))
+	assert.Contains(t, err.Error(), "stack apps: skipped, a stack it depends on is not healthy (core)")
+	assert.Contains(t, err.Error(), "stack tools: skipped, a stack it depends on is not healthy (apps)")
+}
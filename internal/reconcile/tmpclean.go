@@ -0,0 +1,197 @@
+package reconcile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// DefaultStaleTempAge is how old a deploy or restore temp directory must be
+// before it is treated as left behind by a crash. Deploys never hold one
+// longer than RemoteDeployTimeout.
+const DefaultStaleTempAge = time.Hour
+
+// Temp artifact patterns. Deploys stage directories next to their target;
+// restores stage backups, and rollbacks check out revisions, in the system
+// temp directory.
+const (
+	deployTempPattern   = ".deploy-tmp-*"
+	restoreTempPattern  = "bosun-restore-*"
+	rollbackTempPattern = "bosun-rollback-*"
+)
+
+// StaleTemp is a temp artifact removed by a cleanup.
+type StaleTemp struct {
+	Path string
+	Size uint64 // Bytes reclaimed
+}
+
+// CleanStaleTemp removes entries in dir matching pattern that were last
+// modified more than olderThan before now.
+func CleanStaleTemp(dir, pattern string, olderThan time.Duration, now time.Time) ([]StaleTemp, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []StaleTemp
+	for _, path := range matches {
+		info, err := os.Lstat(path)
+		if err != nil || now.Sub(info.ModTime()) < olderThan {
+			continue
+		}
+		size := pathSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", path, err)
+		}
+		removed = append(removed, StaleTemp{Path: path, Size: size})
+	}
+	return removed, nil
+}
+
+// pathSize returns the total size of the files under path.
+func pathSize(path string) uint64 {
+	var size uint64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size
+}
+
+// CleanStaleTempRemote removes deploy temp directories in dir on host that
+// are older than olderThan, using find over SSH.
+func CleanStaleTempRemote(ctx context.Context, host, dir string, olderThan time.Duration) ([]StaleTemp, error) {
+	if err := validateHost(host); err != nil {
+		return nil, fmt.Errorf("invalid SSH host: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SSHTimeout)
+	defer cancel()
+
+	minutes := int(olderThan.Minutes())
+	script := fmt.Sprintf(
+		"find %s -maxdepth 1 -name %s -mmin +%d 2>/dev/null | while read -r d; do echo \"$(du -sk \"$d\" | cut -f1) $d\"; rm -rf \"$d\"; done",
+		shellQuote(dir), shellQuote(deployTempPattern), minutes)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", host, script)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("clean temp directories on %s: %w: %s", host, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseRemoteCleanup(string(out)), nil
+}
+
+// parseRemoteCleanup parses the "<kilobytes> <path>" lines printed by
+// CleanStaleTempRemote.
+func parseRemoteCleanup(output string) []StaleTemp {
+	var removed []StaleTemp
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		kb, path, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok || path == "" {
+			continue
+		}
+		n, _ := strconv.ParseUint(kb, 10, 64)
+		removed = append(removed, StaleTemp{Path: path, Size: n * 1024})
+	}
+	return removed
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tempCleanDue reports whether temp cleanup for host ("" for this machine)
+// last ran at least StaleTempAge ago, and records that it runs now.
+func (r *Reconciler) tempCleanDue(host string) bool {
+	if r.config.StaleTempAge <= 0 || r.config.DryRun {
+		return false
+	}
+	if time.Since(r.tempCleaned[host]) < r.config.StaleTempAge {
+		return false
+	}
+	if r.tempCleaned == nil {
+		r.tempCleaned = make(map[string]time.Time)
+	}
+	r.tempCleaned[host] = time.Now()
+	return true
+}
+
+// cleanStaleTemp removes temp artifacts left by crashed deploys, restores,
+// and rollbacks: restore and rollback directories in the system temp
+// directory, and
+// deploy temp directories in local appdata or on the configured target.
+// Each host is cleaned at most once per StaleTempAge; cleanup never fails
+// the reconcile.
+func (r *Reconciler) cleanStaleTemp(ctx context.Context) {
+	if r.config.TargetHost != "" {
+		r.cleanStaleTempRemote(ctx, r.config.TargetHost)
+	}
+	if !r.tempCleanDue("") {
+		return
+	}
+
+	now := time.Now()
+	var removed []StaleTemp
+	clean := func(dir, pattern string) {
+		found, err := CleanStaleTemp(dir, pattern, r.config.StaleTempAge, now)
+		if err != nil {
+			ui.Warning("Stale temp cleanup: %v", err)
+		}
+		removed = append(removed, found...)
+	}
+
+	clean(os.TempDir(), restoreTempPattern)
+	clean(os.TempDir(), rollbackTempPattern)
+	if r.isLocalMode() {
+		clean(r.config.LocalAppdataPath, deployTempPattern)
+	}
+	reportStaleTemp(removed, "")
+}
+
+// cleanStaleTempRemote removes stale deploy temp directories on host.
+func (r *Reconciler) cleanStaleTempRemote(ctx context.Context, host string) {
+	if !r.tempCleanDue(host) {
+		return
+	}
+	removed, err := CleanStaleTempRemote(ctx, host, r.config.RemoteAppdataPath, r.config.StaleTempAge)
+	if err != nil {
+		ui.Warning("Stale temp cleanup: %v", err)
+	}
+	reportStaleTemp(removed, host)
+}
+
+func reportStaleTemp(removed []StaleTemp, host string) {
+	if len(removed) == 0 {
+		return
+	}
+	var total uint64
+	for _, t := range removed {
+		total += t.Size
+		ui.Info("  Removed stale %s (%s)", t.Path, formatBytes(t.Size))
+	}
+	where := ""
+	if host != "" {
+		where = " on " + host
+	}
+	ui.Info("Reclaimed %s from %d stale temp artifact(s)%s", formatBytes(total), len(removed), where)
+}
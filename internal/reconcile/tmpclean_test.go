@@ -0,0 +1,65 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanStaleTemp(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	stale := filepath.Join(dir, ".deploy-tmp-111")
+	require.NoError(t, os.MkdirAll(filepath.Join(stale, "traefik"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(stale, "traefik", "dynamic.yml"), make([]byte, 2048), 0644))
+	require.NoError(t, os.Chtimes(stale, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+
+	fresh := filepath.Join(dir, ".deploy-tmp-222")
+	require.NoError(t, os.Mkdir(fresh, 0755))
+
+	other := filepath.Join(dir, "traefik")
+	require.NoError(t, os.Mkdir(other, 0755))
+	require.NoError(t, os.Chtimes(other, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+
+	removed, err := CleanStaleTemp(dir, deployTempPattern, time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, []StaleTemp{{Path: stale, Size: 2048}}, removed)
+
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, fresh, "in-flight deploys are left alone")
+	assert.DirExists(t, other, "non-temp directories are left alone")
+}
+
+func TestParseRemoteCleanup(t *testing.T) {
+	removed := parseRemoteCleanup("12 /mnt/user/appdata/.deploy-tmp-1700000000\n\n4 /mnt/user/appdata/.deploy-tmp-1700000001\n")
+	assert.Equal(t, []StaleTemp{
+		{Path: "/mnt/user/appdata/.deploy-tmp-1700000000", Size: 12 * 1024},
+		{Path: "/mnt/user/appdata/.deploy-tmp-1700000001", Size: 4 * 1024},
+	}, removed)
+}
+
+func TestReconciler_CleanStaleTemp(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LocalAppdataPath = t.TempDir()
+	r := NewReconciler(cfg)
+
+	stale := filepath.Join(cfg.LocalAppdataPath, ".deploy-tmp-333")
+	require.NoError(t, os.Mkdir(stale, 0755))
+	old := time.Now().Add(-2 * DefaultStaleTempAge)
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	r.cleanStaleTemp(context.Background())
+	assert.NoDirExists(t, stale)
+
+	// A second run within StaleTempAge is skipped.
+	require.NoError(t, os.Mkdir(stale, 0755))
+	require.NoError(t, os.Chtimes(stale, old, old))
+	r.cleanStaleTemp(context.Background())
+	assert.DirExists(t, stale)
+}
@@ -0,0 +1,190 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/docker"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// User namespace modes: how the deploy target's Docker daemon maps container
+// user IDs to host user IDs, which decides how ownership rules are applied.
+const (
+	// UsernsNone is a rootful daemon; container IDs are host IDs.
+	UsernsNone = "none"
+	// UsernsRootless is a rootless daemon; ownership rules are applied by a
+	// helper container, since only the daemon's namespace can map the IDs.
+	UsernsRootless = docker.UsernsRootless
+	// UsernsRemap is a rootful daemon with userns-remap; rule IDs are
+	// offset into the remap range and applied directly.
+	UsernsRemap = docker.UsernsRemap
+)
+
+// DefaultOwnershipImage is the helper image that applies ownership rules on
+// rootless targets.
+const DefaultOwnershipImage = "alpine:3.21"
+
+// ValidateUsernsMode checks that mode is a known user namespace mode, or
+// empty to detect it.
+func ValidateUsernsMode(mode string) error {
+	switch mode {
+	case "", UsernsNone, UsernsRootless, UsernsRemap:
+		return nil
+	}
+	return fmt.Errorf("invalid userns mode %q (expected %s, %s, or %s)", mode, UsernsNone, UsernsRootless, UsernsRemap)
+}
+
+// Userns describes a Docker daemon's user namespace mapping.
+type Userns struct {
+	Mode string
+	// UID and GID are the host IDs container root maps to under UsernsRemap.
+	UID int
+	GID int
+}
+
+// DetectUserns asks host's Docker daemon ("" for this machine) how it maps
+// container IDs.
+func DetectUserns(ctx context.Context, host string) (Userns, error) {
+	args := []string{"info", "--format", "{{json .}}"}
+	if host != "" {
+		if err := validateHost(host); err != nil {
+			return Userns{}, fmt.Errorf("invalid SSH host: %w", err)
+		}
+		args = append([]string{"-H", "ssh://" + host}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return Userns{}, fmt.Errorf("docker info: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var info struct {
+		SecurityOptions []string
+		DockerRootDir   string
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return Userns{}, fmt.Errorf("parse docker info: %w", err)
+	}
+	return parseUserns(info.SecurityOptions, info.DockerRootDir)
+}
+
+func parseUserns(securityOptions []string, dockerRootDir string) (Userns, error) {
+	switch mode := docker.ParseUsernsMode(securityOptions); mode {
+	case UsernsRootless:
+		return Userns{Mode: mode}, nil
+	case UsernsRemap:
+		uid, gid, err := docker.ParseRemapRoot(dockerRootDir)
+		if err != nil {
+			return Userns{}, err
+		}
+		return Userns{Mode: mode, UID: uid, GID: gid}, nil
+	}
+	return Userns{Mode: UsernsNone}, nil
+}
+
+// HostRules returns rules with their container IDs translated to the host IDs
+// they map to under UsernsRemap. Other modes return rules unchanged.
+func (u Userns) HostRules(rules []OwnershipRule) []OwnershipRule {
+	if u.Mode != UsernsRemap {
+		return rules
+	}
+	mapped := make([]OwnershipRule, len(rules))
+	for i, rule := range rules {
+		rule.UID += u.UID
+		rule.GID += u.GID
+		mapped[i] = rule
+	}
+	return mapped
+}
+
+// ExpectedRules returns the rules to expect in a pre-sync permissions
+// snapshot. A rootless daemon's mapping can't be read from here, so owners
+// under its rules are left unchecked (-1) and only modes are compared.
+func (u Userns) ExpectedRules(rules []OwnershipRule) []OwnershipRule {
+	if u.Mode != UsernsRootless {
+		return u.HostRules(rules)
+	}
+	unknown := make([]OwnershipRule, len(rules))
+	for i, rule := range rules {
+		rule.UID, rule.GID = -1, -1
+		unknown[i] = rule
+	}
+	return unknown
+}
+
+// targetUserns returns the user namespace mode of host's Docker daemon: the
+// configured Userns, or the detected one. Detection failures fall back to
+// UsernsNone with a warning.
+func (r *Reconciler) targetUserns(ctx context.Context, host string) Userns {
+	switch r.config.Userns {
+	case UsernsNone, UsernsRootless:
+		return Userns{Mode: r.config.Userns}
+	}
+
+	u, err := r.detectUserns(ctx, host)
+	if err != nil {
+		ui.Warning("Could not detect Docker user namespace mode: %v", err)
+		return Userns{Mode: UsernsNone}
+	}
+	if r.config.Userns == UsernsRemap && u.Mode != UsernsRemap {
+		ui.Warning("Docker daemon is not using userns-remap; applying ownership unmapped")
+	}
+	return u
+}
+
+// applyOwnership applies the configured ownership rules under appdata on
+// host ("" for this machine), mapping container IDs for u's mode.
+func (r *Reconciler) applyOwnership(ctx context.Context, host, appdata string, u Userns) error {
+	rules := u.HostRules(r.config.Ownership)
+	switch {
+	case u.Mode == UsernsRootless:
+		image := r.config.OwnershipImage
+		if image == "" {
+			image = DefaultOwnershipImage
+		}
+		return r.deploy.ApplyOwnershipInContainer(ctx, host, appdata, rules, r.config.PermissionRules, image)
+	case host == "":
+		return ApplyOwnership(appdata, rules, r.config.PermissionRules)
+	}
+	return r.deploy.ApplyOwnershipRemote(ctx, host, appdata, rules, r.config.PermissionRules)
+}
+
+// ApplyOwnershipInContainer applies ownership rules to paths under root from
+// a helper container on host's Docker daemon ("" for this machine), so the
+// rules' IDs are mapped the same way as the service containers' IDs. Rules
+// whose path does not exist are skipped. File modes are capped by perms
+// (see OwnershipRule.FileMode).
+func (d *DeployOps) ApplyOwnershipInContainer(ctx context.Context, host, root string, rules []OwnershipRule, perms []PermissionRule, image string) error {
+	const mount = "/appdata"
+
+	var script []string
+	for _, rule := range rules {
+		target := shellQuote(path.Join(mount, rule.Path))
+		step := fmt.Sprintf("{ [ ! -e %s ] || { chown -R %d:%d %s", target, rule.UID, rule.GID, target)
+		if rule.Mode != 0 {
+			step += " && " + rule.chmodCommand(mount, perms)
+		}
+		script = append(script, step+"; }; }")
+	}
+	if len(script) == 0 {
+		return nil
+	}
+
+	return retryWithBackoff(ctx, DefaultMaxRetries, func() error {
+		err := runDocker(ctx, host, "run", "--rm", "--network", "none", "--user", "0:0",
+			"-v", root+":"+mount, "--entrypoint", "sh", image, "-c", strings.Join(script, " && "))
+		if err != nil {
+			return fmt.Errorf("apply ownership in %s: %w", image, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserns(t *testing.T) {
+	u, err := parseUserns([]string{"name=seccomp,profile=builtin", "name=rootless"}, "/home/deploy/.local/share/docker")
+	require.NoError(t, err)
+	assert.Equal(t, Userns{Mode: UsernsRootless}, u)
+
+	u, err = parseUserns([]string{"name=userns"}, "/var/lib/docker/100000.100000")
+	require.NoError(t, err)
+	assert.Equal(t, Userns{Mode: UsernsRemap, UID: 100000, GID: 100000}, u)
+
+	u, err = parseUserns(nil, "/var/lib/docker")
+	require.NoError(t, err)
+	assert.Equal(t, Userns{Mode: UsernsNone}, u)
+
+	_, err = parseUserns([]string{"name=userns"}, "/var/lib/docker")
+	assert.Error(t, err)
+}
+
+func TestUserns_Rules(t *testing.T) {
+	rules := []OwnershipRule{{Path: "traefik", UID: 1000, GID: 1000, Mode: 0640}}
+
+	none := Userns{Mode: UsernsNone}
+	assert.Equal(t, rules, none.HostRules(rules))
+	assert.Equal(t, rules, none.ExpectedRules(rules))
+
+	remap := Userns{Mode: UsernsRemap, UID: 100000, GID: 200000}
+	want := []OwnershipRule{{Path: "traefik", UID: 101000, GID: 201000, Mode: 0640}}
+	assert.Equal(t, want, remap.HostRules(rules))
+	assert.Equal(t, want, remap.ExpectedRules(rules))
+	assert.Equal(t, 1000, rules[0].UID, "rules must not be modified")
+
+	rootless := Userns{Mode: UsernsRootless}
+	assert.Equal(t, rules, rootless.HostRules(rules))
+	assert.Equal(t, []OwnershipRule{{Path: "traefik", UID: -1, GID: -1, Mode: 0640}}, rootless.ExpectedRules(rules))
+}
+
+func TestValidateUsernsMode(t *testing.T) {
+	for _, mode := range []string{"", UsernsNone, UsernsRootless, UsernsRemap} {
+		assert.NoError(t, ValidateUsernsMode(mode), mode)
+	}
+	assert.Error(t, ValidateUsernsMode("rootful"))
+}
+
+func TestReconciler_TargetUserns(t *testing.T) {
+	detected := Userns{Mode: UsernsRemap, UID: 100000, GID: 100000}
+	newReconciler := func(mode string, err error) *Reconciler {
+		cfg := DefaultConfig()
+		cfg.Userns = mode
+		r := NewReconciler(cfg)
+		r.detectUserns = func(context.Context, string) (Userns, error) { return detected, err }
+		return r
+	}
+
+	assert.Equal(t, detected, newReconciler("", nil).targetUserns(context.Background(), ""))
+	assert.Equal(t, Userns{Mode: UsernsRootless}, newReconciler(UsernsRootless, nil).targetUserns(context.Background(), ""))
+	assert.Equal(t, Userns{Mode: UsernsNone}, newReconciler(UsernsNone, nil).targetUserns(context.Background(), ""))
+	assert.Equal(t, Userns{Mode: UsernsNone}, newReconciler("", errors.New("no daemon")).targetUserns(context.Background(), ""))
+}
@@ -94,6 +94,25 @@ func validateBranch(branch string) error {
 	return nil
 }
 
+// ValidateRef validates a git commit hash, tag, or branch name used for stack pins.
+// Exported so the CLI can reject bad refs before recording a pin.
+func ValidateRef(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("ref cannot be empty")
+	}
+
+	// Reject git option injection
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref: cannot start with '-' (potential git option injection)")
+	}
+
+	if !branchPattern.MatchString(ref) {
+		return fmt.Errorf("invalid ref format: must be a commit hash, tag, or branch name")
+	}
+
+	return nil
+}
+
 // validateSignal validates a Docker signal against an allowlist.
 // Accepts: SIGHUP, SIGTERM, SIGKILL, SIGUSR1, SIGUSR2, HUP, TERM, KILL, USR1, USR2.
 func validateSignal(signal string) error {
@@ -0,0 +1,76 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/ui"
+	"github.com/cameronsjo/bosun/internal/verify"
+)
+
+// verifyDeploy runs the smoke tests declared in the deployed compose files,
+// records the run in the state history, and returns an error naming the
+// failed tests. Failures are reported, not rolled back: containers are
+// already running and healthy by the time smoke tests run.
+func (r *Reconciler) verifyDeploy(ctx context.Context, secrets map[string]any) error {
+	if r.config.DryRun {
+		return nil
+	}
+
+	checks, err := verify.LoadDir(filepath.Join(r.config.StagingDir, "unraid", "compose"), "")
+	if err != nil {
+		return fmt.Errorf("post-deploy verification failed: %w", err)
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	target := ""
+	if !r.isLocalMode() {
+		target = r.getTargetHost(secrets)
+	}
+
+	ui.Info("Running %d smoke test(s)...", len(checks))
+	results := r.newVerifier(target).Run(ctx, checks)
+
+	var failures []string
+	for _, res := range results {
+		if res.OK {
+			ui.Info("  %s/%s/%s passed", res.Stack, res.Service, res.Name)
+			continue
+		}
+		ui.Warning("  %s", res)
+		failures = append(failures, res.String())
+	}
+	r.recordVerification(len(results)-len(failures), failures)
+
+	if len(failures) > 0 {
+		return fmt.Errorf("post-deploy verification failed: %s", strings.Join(failures, "; "))
+	}
+	ui.Success("All %d smoke test(s) passed", len(results))
+	return nil
+}
+
+// recordVerification appends a verification run to the state history.
+func (r *Reconciler) recordVerification(passed int, failures []string) {
+	if r.config.StateDir == "" {
+		return
+	}
+
+	err := state.NewStore(r.config.StateDir).Update(func(st *state.State) error {
+		st.RecordVerification(state.Verification{
+			At:       time.Now().UTC(),
+			Commit:   r.lastCommit,
+			Passed:   passed,
+			Failures: failures,
+		})
+		return nil
+	})
+	if err != nil {
+		ui.Warning("Failed to record verification: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package reconcile
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/state"
+	"github.com/cameronsjo/bosun/internal/verify"
+)
+
+func TestReconciler_VerifyDeploy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	setup := func(t *testing.T, path string) *Reconciler {
+		staging := t.TempDir()
+		composeDir := filepath.Join(staging, "unraid", "compose")
+		require.NoError(t, os.MkdirAll(composeDir, 0755))
+		compose := "services:\n  app:\n    x-bosun-verify:\n      - name: web\n        http:\n          url: " + srv.URL + path + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(composeDir, "app.yml"), []byte(compose), 0644))
+
+		cfg := DefaultConfig()
+		cfg.StagingDir = staging
+		cfg.StateDir = t.TempDir()
+		cfg.LocalAppdataPath = t.TempDir()
+		r := NewReconciler(cfg)
+		r.lastCommit = "abc1234"
+		r.newVerifier = func(string) *verify.Runner { return &verify.Runner{HTTP: srv.Client()} }
+		return r
+	}
+
+	t.Run("passing tests are recorded", func(t *testing.T) {
+		r := setup(t, "/health")
+		require.NoError(t, r.verifyDeploy(context.Background(), nil))
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		require.Len(t, st.Verifications, 1)
+		assert.True(t, st.Verifications[0].OK())
+		assert.Equal(t, 1, st.Verifications[0].Passed)
+		assert.Equal(t, "abc1234", st.Verifications[0].Commit)
+	})
+
+	t.Run("failing tests return an error", func(t *testing.T) {
+		r := setup(t, "/broken")
+		err := r.verifyDeploy(context.Background(), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "post-deploy verification failed: app/app/web")
+
+		st, err := state.NewStore(r.config.StateDir).Load()
+		require.NoError(t, err)
+		require.Len(t, st.Verifications, 1)
+		assert.False(t, st.Verifications[0].OK())
+	})
+
+	t.Run("dry run skips tests", func(t *testing.T) {
+		r := setup(t, "/broken")
+		r.config.DryRun = true
+		assert.NoError(t, r.verifyDeploy(context.Background(), nil))
+	})
+}
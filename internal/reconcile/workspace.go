@@ -0,0 +1,108 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/cameronsjo/bosun/internal/ui"
+)
+
+// ProjectConfig returns a copy of cfg scoped to one workspace project: its
+// infrastructure directory is the project path, its target is the project
+// target (when set), and staging, backups, and state live in per-project
+// subdirectories so projects never share rendered output or pins.
+func ProjectConfig(cfg *Config, name string, project config.Project) *Config {
+	pcfg := *cfg
+	pcfg.InfraSubDir = filepath.Join(cfg.InfraSubDir, filepath.Clean(project.Path))
+	if project.Target != "" {
+		pcfg.TargetHost = project.Target
+	}
+	pcfg.StagingDir = projectDir(cfg.StagingDir, name)
+	pcfg.BackupDir = projectDir(cfg.BackupDir, name)
+	pcfg.StateDir = projectDir(cfg.StateDir, name)
+	return &pcfg
+}
+
+// projectDir nests a project directory under dir, leaving unset dirs unset.
+func projectDir(dir, name string) string {
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
+
+// syncedGit replays the result of a sync that already happened, so every
+// project in a workspace reconciles from the same pull.
+type syncedGit struct {
+	GitOperations
+	changed       bool
+	before, after string
+}
+
+// Sync returns the recorded sync result without touching the repository.
+func (s *syncedGit) Sync(ctx context.Context) (bool, string, string, error) {
+	return s.changed, s.before, s.after, nil
+}
+
+// ChangedFiles lists the changed paths when the underlying git operations
+// can, so each project deploys only what the pull touched.
+func (s *syncedGit) ChangedFiles(ctx context.Context, before, after string) ([]string, error) {
+	lister, ok := s.GitOperations.(changeLister)
+	if !ok {
+		return nil, errors.New("changed files unavailable")
+	}
+	return lister.ChangedFiles(ctx, before, after)
+}
+
+// RunProjects syncs the repository once and reconciles each project listed
+// in the repository's workspace file (see config.WorkspaceFile), in name
+// order. If names is non-empty, only those projects run. A failing project
+// does not stop the others; all failures are returned together.
+func RunProjects(ctx context.Context, cfg *Config, names []string, opts ...ReconcilerOption) error {
+	base := NewReconciler(cfg, opts...)
+	if err := base.acquireLock(); err != nil {
+		return fmt.Errorf("failed to acquire lock (another reconciliation may be in progress): %w", err)
+	}
+	defer base.releaseLock()
+
+	changed, before, after, err := base.syncRepo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync repository: %w", err)
+	}
+
+	ws, err := config.LoadWorkspace(filepath.Join(cfg.RepoDir, cfg.InfraSubDir, config.WorkspaceFile))
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		names = ws.ProjectNames()
+	}
+	for _, name := range names {
+		if _, ok := ws.Projects[name]; !ok {
+			return fmt.Errorf("unknown project %q (available: %s)", name, strings.Join(ws.ProjectNames(), ", "))
+		}
+	}
+
+	synced := &syncedGit{GitOperations: base.git, changed: changed, before: before, after: after}
+
+	var errs []error
+	for _, name := range names {
+		ui.Header("=== Project: %s ===", name)
+		projectOpts := append(append([]ReconcilerOption{}, opts...),
+			WithGitOperations(synced),
+			WithLockFile(base.lockFile+"."+name),
+		)
+		r := NewReconciler(ProjectConfig(cfg, name, ws.Projects[name]), projectOpts...)
+		if err := r.Run(ctx); err != nil {
+			ui.Error("Project %s failed: %v", name, err)
+			errs = append(errs, fmt.Errorf("project %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
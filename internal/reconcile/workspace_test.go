@@ -0,0 +1,73 @@
+package reconcile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cameronsjo/bosun/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectConfig(t *testing.T) {
+	base := DefaultConfig()
+	base.TargetHost = "root@default"
+
+	pcfg := ProjectConfig(base, "vps", config.Project{Path: "vps/", Target: "root@vps"})
+	assert.Equal(t, "vps", pcfg.InfraSubDir)
+	assert.Equal(t, "root@vps", pcfg.TargetHost)
+	assert.Equal(t, "/app/staging/vps", pcfg.StagingDir)
+	assert.Equal(t, "/app/backups/vps", pcfg.BackupDir)
+	assert.Equal(t, "/app/state/vps", pcfg.StateDir)
+	assert.Equal(t, base.RepoDir, pcfg.RepoDir, "projects share one clone")
+
+	pcfg = ProjectConfig(base, "homelab", config.Project{Path: "homelab"})
+	assert.Equal(t, "root@default", pcfg.TargetHost, "falls back to the base target")
+	assert.Equal(t, "/app/staging", base.StagingDir, "base config is not modified")
+}
+
+func TestRunProjects(t *testing.T) {
+	newConfig := func(t *testing.T, workspace string) *Config {
+		t.Helper()
+		cfg := &Config{
+			RepoDir:     t.TempDir(),
+			StagingDir:  t.TempDir(),
+			StateDir:    t.TempDir(),
+			InfraSubDir: ".",
+		}
+		if workspace != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(cfg.RepoDir, config.WorkspaceFile), []byte(workspace), 0644))
+		}
+		return cfg
+	}
+	lock := func(t *testing.T) ReconcilerOption {
+		return WithLockFile(filepath.Join(t.TempDir(), "reconcile.lock"))
+	}
+	workspace := "projects:\n  homelab:\n    path: homelab\n  vps:\n    path: vps\n    target: root@vps\n"
+
+	t.Run("unchanged repo skips every project", func(t *testing.T) {
+		cfg := newConfig(t, workspace)
+		assert.NoError(t, RunProjects(context.Background(), cfg, nil, WithGitOperations(&pinGitOps{}), lock(t)))
+	})
+
+	t.Run("selected project", func(t *testing.T) {
+		cfg := newConfig(t, workspace)
+		assert.NoError(t, RunProjects(context.Background(), cfg, []string{"vps"}, WithGitOperations(&pinGitOps{}), lock(t)))
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		cfg := newConfig(t, workspace)
+		err := RunProjects(context.Background(), cfg, []string{"cloud"}, WithGitOperations(&pinGitOps{}), lock(t))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "available: homelab, vps")
+	})
+
+	t.Run("missing workspace file", func(t *testing.T) {
+		cfg := newConfig(t, "")
+		err := RunProjects(context.Background(), cfg, nil, WithGitOperations(&pinGitOps{}), lock(t))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read workspace")
+	})
+}
@@ -0,0 +1,248 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Image is the image a compose service runs.
+type Image struct {
+	Stack   string // Compose file's base name
+	Service string
+	Image   string
+
+	// Running is the digest the service's running image was pulled by, or
+	// "" when it isn't running or was built locally (see SetRunning).
+	Running string
+}
+
+// Result is what the registry knows about a newer version of an image.
+type Result struct {
+	Image
+
+	// Latest is the highest tag newer than the image's, of the same shape
+	// (see NewerTag), or "".
+	Latest string
+
+	// Digest is what the image's tag, or Latest when set, points to now.
+	// It is only looked up for images pinned to a digest and for running
+	// images on a floating tag.
+	Digest string
+
+	// Skipped says why the image wasn't checked, e.g. a floating tag that
+	// isn't running.
+	Skipped string
+
+	Err error
+}
+
+// HasUpdate reports whether a newer tag or digest is available.
+func (r Result) HasUpdate() bool {
+	if r.Latest != "" {
+		return true
+	}
+	ref, err := ParseRef(r.Image.Image)
+	if err != nil || r.Digest == "" {
+		return false
+	}
+	if ref.Digest == "" {
+		return r.Running != "" && r.Digest != r.Running
+	}
+	return r.Digest != ref.Digest
+}
+
+// Redeploy reports whether the update is a new image behind the same
+// floating tag: nothing to write to the manifest, only a pull and redeploy.
+func (r Result) Redeploy() bool {
+	ref, err := ParseRef(r.Image.Image)
+	return err == nil && ref.Digest == "" && r.Latest == "" && r.HasUpdate()
+}
+
+// Tag returns the tag to bump the image to, in the form manifest.WithTag
+// takes: the newer tag, with the new digest when the image is pinned.
+func (r Result) Tag() string {
+	ref, _ := ParseRef(r.Image.Image)
+	tag := r.Latest
+	if tag == "" {
+		tag = ref.Tag
+	}
+	if ref.Digest != "" && r.Digest != "" {
+		tag += "@" + r.Digest
+	}
+	return tag
+}
+
+// Check looks up newer versions of an image: newer version tags for
+// version-tagged images, and the tag's current digest for images pinned to
+// a digest. Images on a floating tag such as latest have the tag's digest
+// compared with the one they run; they are skipped when that is unknown.
+func (c *Client) Check(ctx context.Context, img Image) Result {
+	res := Result{Image: img}
+	ref, err := ParseRef(img.Image)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	tag := ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	_, versioned := parseVersion(tag)
+	if versioned {
+		tags, err := c.Tags(ctx, ref)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		res.Latest = NewerTag(tag, tags)
+	} else if ref.Digest == "" && img.Running == "" {
+		res.Skipped = fmt.Sprintf("floating tag %q, running digest unknown", tag)
+		return res
+	}
+
+	if ref.Digest != "" || !versioned {
+		if res.Latest != "" {
+			tag = res.Latest
+		}
+		if res.Digest, err = c.Digest(ctx, ref, tag); err != nil {
+			res.Err = err
+		}
+	}
+	return res
+}
+
+// RepoDigest returns the digest in a running image's repo digests (as
+// docker image inspect lists them, e.g. nginx@sha256:...) that belongs to
+// image's repository, or "" when none does.
+func RepoDigest(image string, repoDigests []string) string {
+	ref, err := ParseRef(image)
+	if err != nil {
+		return ""
+	}
+	for _, rd := range repoDigests {
+		other, err := ParseRef(rd)
+		if err == nil && other.Digest != "" && other.Registry == ref.Registry && other.Repository == ref.Repository {
+			return other.Digest
+		}
+	}
+	return ""
+}
+
+// SetRunning sets the Running digest of each image from the repo digests
+// of the running compose services, keyed by "project/service";
+// project returns the compose project name of a stack.
+func SetRunning(images []Image, repoDigests map[string][]string, project func(stack string) string) {
+	for i, img := range images {
+		images[i].Running = RepoDigest(img.Image, repoDigests[project(img.Stack)+"/"+img.Service])
+	}
+}
+
+// version is a version tag split into its parts, e.g. v1.2.3-alpine.
+type version struct {
+	prefix string // "v" or ""
+	nums   []int
+	suffix string // e.g. "-alpine"
+}
+
+var versionPattern = regexp.MustCompile(`^(v?)(\d+(?:\.\d+)*)(.*)$`)
+
+// parseVersion parses a version tag.
+func parseVersion(tag string) (version, bool) {
+	m := versionPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return version{}, false
+	}
+	v := version{prefix: m[1], suffix: m[3]}
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return version{}, false
+		}
+		v.nums = append(v.nums, n)
+	}
+	return v, true
+}
+
+// sameShape reports whether two versions are comparable: same prefix, same
+// number of parts, and same suffix, so 1.2.3 is never "updated" to 1.3 or
+// 1.2.4-rc1, nor 16-alpine to 17.
+func (v version) sameShape(o version) bool {
+	return v.prefix == o.prefix && len(v.nums) == len(o.nums) && v.suffix == o.suffix
+}
+
+// newer reports whether v is a higher version than o.
+func (v version) newer(o version) bool {
+	for i := range v.nums {
+		if v.nums[i] != o.nums[i] {
+			return v.nums[i] > o.nums[i]
+		}
+	}
+	return false
+}
+
+// NewerTag returns the highest tag in tags that is a newer version than
+// current and of the same shape, or "" when there is none.
+func NewerTag(current string, tags []string) string {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return ""
+	}
+	best, bestTag := cur, ""
+	for _, tag := range tags {
+		v, ok := parseVersion(tag)
+		if ok && v.sameShape(cur) && v.newer(best) {
+			best, bestTag = v, tag
+		}
+	}
+	return bestTag
+}
+
+// ComposeImages returns the images the services of the compose files in
+// dir run, sorted by stack and service. Services that build their image or
+// whose image uses variables are skipped.
+func ComposeImages(dir string) ([]Image, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var images []Image
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var compose struct {
+			Services map[string]struct {
+				Image string `yaml:"image"`
+				Build any    `yaml:"build"`
+			} `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(file), err)
+		}
+		stack := strings.TrimSuffix(filepath.Base(file), ".yml")
+		for name, svc := range compose.Services {
+			if svc.Image == "" || svc.Build != nil || strings.Contains(svc.Image, "${") {
+				continue
+			}
+			images = append(images, Image{Stack: stack, Service: name, Image: svc.Image})
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Stack != images[j].Stack {
+			return images[i].Stack < images[j].Stack
+		}
+		return images[i].Service < images[j].Service
+	})
+	return images, nil
+}
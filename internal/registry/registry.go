@@ -0,0 +1,273 @@
+// Package registry queries container registries (Docker Hub, ghcr.io, and
+// other registries speaking the Docker Registry HTTP API v2) for image tags
+// and digests.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each registry request.
+const DefaultTimeout = 30 * time.Second
+
+// maxTagPages caps how many pages of a tag list are read.
+const maxTagPages = 20
+
+// manifestTypes are the manifest media types asked for when resolving a
+// digest, so multi-arch images report their index digest as docker pull does.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Ref is a parsed image reference.
+type Ref struct {
+	Registry   string // e.g. docker.io, ghcr.io, registry.lan:5000
+	Repository string // e.g. library/nginx, linuxserver/sonarr
+	Tag        string // Empty when the reference has none
+	Digest     string // sha256:..., when pinned
+}
+
+// ParseRef parses an image reference the way docker does: a first path
+// component with a dot or port, or localhost, is the registry; otherwise
+// the image is on Docker Hub, where single-name images live under library/.
+func ParseRef(image string) (Ref, error) {
+	if image == "" || strings.ContainsAny(image, " \t$") {
+		return Ref{}, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	var ref Ref
+	name := image
+	if i := strings.Index(name, "@"); i != -1 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, ref.Repository = first, rest
+	} else {
+		ref.Registry, ref.Repository = "docker.io", name
+	}
+	if ref.Registry == "docker.io" && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" {
+		return Ref{}, fmt.Errorf("invalid image reference %q", image)
+	}
+	return ref, nil
+}
+
+// apiHost returns the host serving the registry's API.
+func (r Ref) apiHost() string {
+	if r.Registry == "docker.io" {
+		return "registry-1.docker.io"
+	}
+	return r.Registry
+}
+
+// Client queries registries anonymously, fetching bearer tokens as
+// registries ask for them.
+type Client struct {
+	HTTP *http.Client
+
+	// Scheme is the URL scheme of registry requests (default https).
+	Scheme string
+
+	// GitHubAPI is the GitHub REST API release notes are read from
+	// (default DefaultGitHubAPI), and GitHubToken an optional token for it.
+	GitHubAPI   string
+	GitHubToken string
+
+	mu     sync.Mutex
+	tokens map[string]string   // Registry/repository -> bearer token
+	tags   map[string][]string // Registry/repository -> tags
+}
+
+// NewClient creates a registry client.
+func NewClient() *Client {
+	return &Client{
+		HTTP:      &http.Client{Timeout: DefaultTimeout},
+		Scheme:    "https",
+		GitHubAPI: DefaultGitHubAPI,
+		tokens:    make(map[string]string),
+		tags:      make(map[string][]string),
+	}
+}
+
+// Tags lists the repository's tags. Results are cached per repository for
+// the life of the client.
+func (c *Client) Tags(ctx context.Context, ref Ref) ([]string, error) {
+	key := ref.Registry + "/" + ref.Repository
+	c.mu.Lock()
+	cached, ok := c.tags[key]
+	c.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var tags []string
+	next := fmt.Sprintf("/v2/%s/tags/list?n=1000", ref.Repository)
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		resp, err := c.do(ctx, http.MethodGet, ref, next, "")
+		if err != nil {
+			return nil, err
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parse tags of %s: %w", key, err)
+		}
+		tags = append(tags, body.Tags...)
+		next = nextLink(resp.Header.Get("Link"))
+	}
+
+	c.mu.Lock()
+	c.tags[key] = tags
+	c.mu.Unlock()
+	return tags, nil
+}
+
+// Digest returns the content digest the registry serves for a tag.
+func (c *Client) Digest(ctx context.Context, ref Ref, tag string) (string, error) {
+	resp, err := c.do(ctx, http.MethodHead, ref, fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, tag), strings.Join(manifestTypes, ", "))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("%s/%s:%s: registry returned no digest", ref.Registry, ref.Repository, tag)
+	}
+	return digest, nil
+}
+
+// do sends a request to the registry API, authenticating when challenged.
+// Non-2xx responses are returned as errors.
+func (c *Client) do(ctx context.Context, method string, ref Ref, path, accept string) (*http.Response, error) {
+	key := ref.Registry + "/" + ref.Repository
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.Scheme+"://"+ref.apiHost()+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.mu.Lock()
+		token := c.tokens[key]
+		c.mu.Unlock()
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			token, err := c.fetchToken(ctx, challenge, ref)
+			if err != nil {
+				return nil, fmt.Errorf("authenticate to %s: %w", ref.Registry, err)
+			}
+			c.mu.Lock()
+			c.tokens[key] = token
+			c.mu.Unlock()
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s %s: registry returned %s", key, strings.ToLower(method), resp.Status)
+		}
+		return resp, nil
+	}
+}
+
+// challengeParam matches the key="value" pairs of a WWW-Authenticate header.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// fetchToken gets an anonymous pull token from the realm a Bearer
+// challenge names.
+func (c *Client) fetchToken(ctx context.Context, challenge string, ref Ref) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+	values := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(params, -1) {
+		values[m[1]] = m[2]
+	}
+	if values["realm"] == "" {
+		return "", fmt.Errorf("auth challenge has no realm")
+	}
+
+	q := url.Values{}
+	if values["service"] != "" {
+		q.Set("service", values["service"])
+	}
+	q.Set("scope", "repository:"+ref.Repository+":pull")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, values["realm"]+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("parse token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token endpoint returned no token")
+}
+
+// linkNext matches the next-page URL of a Link header.
+var linkNext = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextLink returns the path of the next page from a Link header, or "".
+func nextLink(header string) string {
+	m := linkNext.FindStringSubmatch(header)
+	if m == nil {
+		return ""
+	}
+	u, err := url.Parse(m[1])
+	if err != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
@@ -0,0 +1,325 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		image string
+		want  Ref
+	}{
+		{"nginx", Ref{Registry: "docker.io", Repository: "library/nginx"}},
+		{"nginx:1.25", Ref{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"linuxserver/sonarr:4.0.2", Ref{Registry: "docker.io", Repository: "linuxserver/sonarr", Tag: "4.0.2"}},
+		{"ghcr.io/immich-app/immich-server:v1.2.3", Ref{Registry: "ghcr.io", Repository: "immich-app/immich-server", Tag: "v1.2.3"}},
+		{"registry.lan:5000/app", Ref{Registry: "registry.lan:5000", Repository: "app"}},
+		{"localhost/app:1", Ref{Registry: "localhost", Repository: "app", Tag: "1"}},
+		{"postgres:16@sha256:abc", Ref{Registry: "docker.io", Repository: "library/postgres", Tag: "16", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRef(tt.image)
+		require.NoError(t, err, tt.image)
+		assert.Equal(t, tt.want, got, tt.image)
+	}
+
+	for _, bad := range []string{"", "${IMAGE}", "a b"} {
+		_, err := ParseRef(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNewerTag(t *testing.T) {
+	tags := []string{"latest", "1.2.3", "1.2.4", "1.2.10", "1.3", "1.2.11-rc1", "2.0.0", "v2.1.0", "16-alpine", "17-alpine", "17"}
+	tests := map[string]string{
+		"1.2.3":     "2.0.0",
+		"2.0.0":     "",
+		"v2.0.0":    "v2.1.0",
+		"1.2":       "1.3",
+		"16-alpine": "17-alpine",
+		"16":        "17",
+		"latest":    "",
+	}
+	for current, want := range tests {
+		assert.Equal(t, want, NewerTag(current, tags), current)
+	}
+	assert.Equal(t, "1.2.10", NewerTag("1.2.3", []string{"1.2.4", "1.2.10", "1.2.9"}), "numeric, not lexical, order")
+}
+
+// fakeRegistry serves tags and digests for repositories behind anonymous
+// bearer token auth, two tags per page.
+func fakeRegistry(t *testing.T, tags map[string][]string, digests map[string]string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "t-" + r.URL.Query().Get("scope")})
+			return
+		}
+		repo, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+		repo = repo + "/" + rest[:strings.Index(rest, "/")]
+		rest = rest[strings.Index(rest, "/")+1:]
+		if r.Header.Get("Authorization") != "Bearer t-repository:"+repo+":pull" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case rest == "tags/list":
+			all, ok := tags[repo]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			start := 0
+			if last := r.URL.Query().Get("last"); last != "" {
+				for i, tag := range all {
+					if tag == last {
+						start = i + 1
+					}
+				}
+			}
+			end := min(start+2, len(all))
+			if end < len(all) {
+				w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=2&last=%s>; rel="next"`, repo, all[end-1]))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": repo, "tags": all[start:end]})
+		case strings.HasPrefix(rest, "manifests/"):
+			digest, ok := digests[repo+":"+strings.TrimPrefix(rest, "manifests/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testClient returns a client speaking plain HTTP to test servers.
+func testClient() *Client {
+	c := NewClient()
+	c.Scheme = "http"
+	return c
+}
+
+func TestClient_TagsAndDigest(t *testing.T) {
+	server := fakeRegistry(t,
+		map[string][]string{"acme/app": {"1.0.0", "1.0.1", "1.1.0", "latest", "2.0.0"}},
+		map[string]string{"acme/app:1.0.0": "sha256:aaa"},
+	)
+	host := strings.TrimPrefix(server.URL, "http://")
+	ref, err := ParseRef(host + "/acme/app:1.0.0")
+	require.NoError(t, err)
+
+	c := testClient()
+	tags, err := c.Tags(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.0.1", "1.1.0", "latest", "2.0.0"}, tags, "all pages are read")
+
+	digest, err := c.Digest(context.Background(), ref, "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:aaa", digest)
+
+	_, err = c.Digest(context.Background(), ref, "9.9.9")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestClient_Check(t *testing.T) {
+	server := fakeRegistry(t,
+		map[string][]string{
+			"acme/app": {"1.0.0", "1.0.1", "latest"},
+			"acme/db":  {"16", "17"},
+		},
+		map[string]string{
+			"acme/app:latest": "sha256:new",
+			"acme/db:17":      "sha256:db17",
+		},
+	)
+	host := strings.TrimPrefix(server.URL, "http://")
+	c := testClient()
+	ctx := context.Background()
+
+	res := c.Check(ctx, Image{Image: host + "/acme/app:1.0.0"})
+	require.NoError(t, res.Err)
+	assert.True(t, res.HasUpdate())
+	assert.Equal(t, "1.0.1", res.Tag())
+
+	res = c.Check(ctx, Image{Image: host + "/acme/app:1.0.1"})
+	require.NoError(t, res.Err)
+	assert.False(t, res.HasUpdate())
+
+	res = c.Check(ctx, Image{Image: host + "/acme/app:latest"})
+	assert.False(t, res.HasUpdate())
+	assert.Contains(t, res.Skipped, "floating tag")
+
+	res = c.Check(ctx, Image{Image: host + "/acme/app:latest", Running: "sha256:old"})
+	require.NoError(t, res.Err)
+	assert.True(t, res.HasUpdate(), "floating tag moved past the running image")
+	assert.True(t, res.Redeploy())
+	assert.Equal(t, "sha256:new", res.Digest)
+
+	res = c.Check(ctx, Image{Image: host + "/acme/app:latest", Running: "sha256:new"})
+	require.NoError(t, res.Err)
+	assert.False(t, res.HasUpdate(), "running the tag's current image")
+
+	res = c.Check(ctx, Image{Image: host + "/acme/app:latest@sha256:old"})
+	require.NoError(t, res.Err)
+	assert.True(t, res.HasUpdate(), "pinned digest moved")
+	assert.False(t, res.Redeploy(), "a pinned digest is bumped in the manifest")
+	assert.Equal(t, "latest@sha256:new", res.Tag())
+
+	res = c.Check(ctx, Image{Image: host + "/acme/db:16@sha256:db16"})
+	require.NoError(t, res.Err)
+	assert.Equal(t, "17@sha256:db17", res.Tag(), "a newer tag keeps the pin")
+
+	res = c.Check(ctx, Image{Image: host + "/acme/missing:1.0.0"})
+	assert.Error(t, res.Err)
+}
+
+func TestSetRunning(t *testing.T) {
+	images := []Image{
+		{Stack: "web", Service: "app", Image: "nginx:latest"},
+		{Stack: "web", Service: "cache", Image: "ghcr.io/acme/cache:latest"},
+		{Stack: "web", Service: "stopped", Image: "redis"},
+	}
+	SetRunning(images, map[string][]string{
+		"bosun-web/app":   {"nginx@sha256:aaa"},
+		"bosun-web/cache": {"docker.io/acme/cache@sha256:other", "ghcr.io/acme/cache@sha256:bbb"},
+	}, func(stack string) string { return "bosun-" + stack })
+
+	assert.Equal(t, "sha256:aaa", images[0].Running, "docker.io/library is implied")
+	assert.Equal(t, "sha256:bbb", images[1].Running, "the digest from the image's registry")
+	assert.Empty(t, images[2].Running)
+}
+
+func TestComposeImages(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "media.yml"), []byte(`services:
+  sonarr:
+    image: lscr.io/linuxserver/sonarr:4.0.2
+  builder:
+    image: app:local
+    build: ./app
+  templated:
+    image: ${IMAGE}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "core.yml"), []byte("services:\n  traefik:\n    image: traefik:v3.0\n"), 0644))
+
+	images, err := ComposeImages(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []Image{
+		{Stack: "core", Service: "traefik", Image: "traefik:v3.0"},
+		{Stack: "media", Service: "sonarr", Image: "lscr.io/linuxserver/sonarr:4.0.2"},
+	}, images)
+}
+
+func TestClient_Source(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/acme/annotated/manifests/1.1.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"annotations": map[string]string{SourceAnnotation: "https://github.com/acme/annotated"},
+			})
+		case "/v2/acme/labelled/manifests/1.1.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{"manifests": []any{
+				map[string]any{"digest": "sha256:arm", "platform": map[string]string{"os": "linux", "architecture": "arm64"}},
+				map[string]any{"digest": "sha256:amd", "platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+			}})
+		case "/v2/acme/labelled/manifests/sha256:amd":
+			_ = json.NewEncoder(w).Encode(map[string]any{"config": map[string]string{"digest": "sha256:cfg"}})
+		case "/v2/acme/labelled/blobs/sha256:cfg":
+			_ = json.NewEncoder(w).Encode(map[string]any{"config": map[string]any{
+				"Labels": map[string]string{SourceAnnotation: "https://github.com/acme/labelled"},
+			}})
+		case "/v2/acme/bare/manifests/1.1.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{"config": map[string]string{"digest": "sha256:bare"}})
+		case "/v2/acme/bare/blobs/sha256:bare":
+			_ = json.NewEncoder(w).Encode(map[string]any{"config": map[string]any{}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "http://")
+	c := testClient()
+	ctx := context.Background()
+
+	for repo, want := range map[string]string{
+		"annotated": "https://github.com/acme/annotated",
+		"labelled":  "https://github.com/acme/labelled",
+	} {
+		ref, err := ParseRef(host + "/acme/" + repo + ":1.0.0")
+		require.NoError(t, err)
+		source, err := c.Source(ctx, ref, "1.1.0")
+		require.NoError(t, err, repo)
+		assert.Equal(t, want, source, repo)
+	}
+
+	ref, err := ParseRef(host + "/acme/bare:1.0.0")
+	require.NoError(t, err)
+	_, err = c.Source(ctx, ref, "1.1.0")
+	assert.ErrorContains(t, err, "no "+SourceAnnotation)
+}
+
+func TestGitHubRepo(t *testing.T) {
+	for _, source := range []string{"https://github.com/acme/app", "https://github.com/acme/app.git", "github.com/acme/app", "acme/app", "git@github.com:acme/app.git"} {
+		owner, repo, err := GitHubRepo(source)
+		require.NoError(t, err, source)
+		assert.Equal(t, []string{"acme", "app"}, []string{owner, repo}, source)
+	}
+	_, _, err := GitHubRepo("https://gitlab.com/acme/app")
+	assert.Error(t, err)
+}
+
+func TestClient_ReleaseNotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/app/releases/tags/v1.1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"tag_name": "v1.1.0",
+			"html_url": "https://github.com/acme/app/releases/tag/v1.1.0",
+			"body":     "## Highlights\n\n- Faster sync\n<!-- generated -->\n- Fix login loop\n",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c := testClient()
+	c.GitHubAPI = server.URL
+	c.GitHubToken = "gh-token"
+
+	release, err := c.ReleaseNotes(context.Background(), "https://github.com/acme/app", "1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, Release{
+		Name:    "v1.1.0",
+		URL:     "https://github.com/acme/app/releases/tag/v1.1.0",
+		Summary: "- Faster sync\n- Fix login loop",
+	}, release, "the v-prefixed tag is tried too")
+
+	_, err = c.ReleaseNotes(context.Background(), "acme/app", "2.0.0")
+	assert.ErrorContains(t, err, "no release for 2.0.0")
+}
+
+func TestSummarizeNotes(t *testing.T) {
+	notes := "# v2\n\n1\n2\n3\n4\n5\n6\n"
+	assert.Equal(t, "1\n2\n3\n4\n5", SummarizeNotes(notes))
+	assert.Empty(t, SummarizeNotes(""))
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// SourceAnnotation is the OCI annotation (or image label) naming the
+// repository an image is built from.
+const SourceAnnotation = "org.opencontainers.image.source"
+
+// DefaultGitHubAPI is the GitHub REST API release notes are read from.
+const DefaultGitHubAPI = "https://api.github.com"
+
+// maxSummaryLines caps the lines of release notes kept in a summary.
+const maxSummaryLines = 5
+
+// Release is a GitHub release of an image's source repository.
+type Release struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Summary string `json:"summary,omitempty"` // First lines of the release notes
+}
+
+// Source returns the source repository URL an image tag declares in its
+// org.opencontainers.image.source annotation, or in the label of the same
+// name on its image config. Multi-arch images are read through their
+// linux/amd64 manifest when the index itself has no annotation.
+func (c *Client) Source(ctx context.Context, ref Ref, tag string) (string, error) {
+	reference := tag
+	for depth := 0; depth < 2; depth++ {
+		var m struct {
+			Annotations map[string]string `json:"annotations"`
+			Manifests   []struct {
+				Digest   string `json:"digest"`
+				Platform struct {
+					OS           string `json:"os"`
+					Architecture string `json:"architecture"`
+				} `json:"platform"`
+			} `json:"manifests"`
+			Config struct {
+				Digest string `json:"digest"`
+			} `json:"config"`
+		}
+		if err := c.getJSON(ctx, ref, fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, reference), strings.Join(manifestTypes, ", "), &m); err != nil {
+			return "", err
+		}
+		if source := m.Annotations[SourceAnnotation]; source != "" {
+			return source, nil
+		}
+
+		if len(m.Manifests) > 0 {
+			reference = m.Manifests[0].Digest
+			for _, entry := range m.Manifests {
+				if entry.Platform.OS == "linux" && entry.Platform.Architecture == "amd64" {
+					reference = entry.Digest
+					break
+				}
+			}
+			continue
+		}
+		if m.Config.Digest == "" {
+			break
+		}
+
+		var config struct {
+			Config struct {
+				Labels map[string]string `json:"Labels"`
+			} `json:"config"`
+		}
+		if err := c.getJSON(ctx, ref, fmt.Sprintf("/v2/%s/blobs/%s", ref.Repository, m.Config.Digest), "", &config); err != nil {
+			return "", err
+		}
+		if source := config.Config.Labels[SourceAnnotation]; source != "" {
+			return source, nil
+		}
+		break
+	}
+	return "", fmt.Errorf("%s/%s:%s has no %s annotation", ref.Registry, ref.Repository, tag, SourceAnnotation)
+}
+
+// getJSON fetches a registry API path and decodes its JSON body into v.
+func (c *Client) getJSON(ctx context.Context, ref Ref, path, accept string, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, ref, path, accept)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// githubRepoPattern matches a GitHub repository as a URL
+// (https://github.com/owner/repo.git), host path (github.com/owner/repo),
+// or owner/repo.
+var githubRepoPattern = regexp.MustCompile(`^(?:(?:https?://|git@)?github\.com[/:])?([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+?)(?:\.git)?/?$`)
+
+// GitHubRepo returns the owner and name of a GitHub repository reference.
+func GitHubRepo(source string) (owner, repo string, err error) {
+	m := githubRepoPattern.FindStringSubmatch(strings.TrimSpace(source))
+	if m == nil {
+		return "", "", fmt.Errorf("%q is not a GitHub repository", source)
+	}
+	return m[1], m[2], nil
+}
+
+// ReleaseNotes reads the GitHub release of a source repository for an image
+// tag, trying the tag with and without a leading v since images and
+// releases often disagree. The client's GitHubToken, when set, raises the
+// API rate limit.
+func (c *Client) ReleaseNotes(ctx context.Context, source, tag string) (Release, error) {
+	owner, repo, err := GitHubRepo(source)
+	if err != nil {
+		return Release{}, err
+	}
+	api := c.GitHubAPI
+	if api == "" {
+		api = DefaultGitHubAPI
+	}
+
+	candidates := []string{tag, "v" + tag}
+	if trimmed, ok := strings.CutPrefix(tag, "v"); ok {
+		candidates = []string{tag, trimmed}
+	}
+	for _, name := range candidates {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", api, owner, repo, name), nil)
+		if err != nil {
+			return Release{}, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if c.GitHubToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.GitHubToken)
+		}
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return Release{}, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return Release{}, fmt.Errorf("%s/%s release %s: GitHub returned %s", owner, repo, name, resp.Status)
+		}
+		var body struct {
+			Name    string `json:"name"`
+			TagName string `json:"tag_name"`
+			HTMLURL string `json:"html_url"`
+			Body    string `json:"body"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return Release{}, fmt.Errorf("parse %s/%s release %s: %w", owner, repo, name, err)
+		}
+		if body.Name == "" {
+			body.Name = body.TagName
+		}
+		return Release{Name: body.Name, URL: body.HTMLURL, Summary: SummarizeNotes(body.Body)}, nil
+	}
+	return Release{}, fmt.Errorf("%s/%s has no release for %s", owner, repo, tag)
+}
+
+// SummarizeNotes returns the first lines of markdown release notes, with
+// blank lines, headings, and HTML comments dropped.
+func SummarizeNotes(notes string) string {
+	var lines []string
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--") {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == maxSummaryLines {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
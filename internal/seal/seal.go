@@ -0,0 +1,142 @@
+// Package seal encrypts individual secret values at rest with the age key
+// bosun already uses for SOPS, so local state and config files can hold
+// credentials without exposing them to anyone who copies the files. It also
+// streams whole files, such as backup archives, through the same key.
+package seal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Prefix marks a sealed value: "age:" followed by the base64 age ciphertext.
+const Prefix = "age:"
+
+// ErrNoKey is returned when no age key is available.
+var ErrNoKey = errors.New("no age key available")
+
+// Key seals values to an age X25519 identity and opens them with it.
+type Key struct {
+	identities []age.Identity
+	recipient  age.Recipient
+}
+
+// LoadKey loads the age key the same way SOPS does:
+//  1. SOPS_AGE_KEY environment variable
+//  2. SOPS_AGE_KEY_FILE environment variable
+//  3. Default location: ~/.config/sops/age/keys.txt
+//
+// Values are sealed to the first X25519 identity found. Returns ErrNoKey if
+// there is none.
+func LoadKey() (*Key, error) {
+	if key := os.Getenv("SOPS_AGE_KEY"); key != "" {
+		return ParseKey(strings.NewReader(key))
+	}
+
+	path := os.Getenv("SOPS_AGE_KEY_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, ErrNoKey
+		}
+		path = filepath.Join(home, ".config", "sops", "age", "keys.txt")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoKey
+		}
+		return nil, fmt.Errorf("open age key: %w", err)
+	}
+	defer f.Close()
+	return ParseKey(f)
+}
+
+// ParseKey parses an age key file.
+func ParseKey(r io.Reader) (*Key, error) {
+	identities, err := age.ParseIdentities(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse age key: %w", err)
+	}
+	for _, id := range identities {
+		if x, ok := id.(*age.X25519Identity); ok {
+			return &Key{identities: identities, recipient: x.Recipient()}, nil
+		}
+	}
+	return nil, ErrNoKey
+}
+
+// IsSealed reports whether s is a sealed value.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Seal encrypts s. Empty and already sealed values are returned unchanged.
+func (k *Key) Seal(s string) (string, error) {
+	if s == "" || IsSealed(s) {
+		return s, nil
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, k.recipient)
+	if err != nil {
+		return "", fmt.Errorf("seal: %w", err)
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		return "", fmt.Errorf("seal: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("seal: %w", err)
+	}
+	return Prefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Open decrypts a sealed value. Values that are not sealed are returned
+// unchanged, so plaintext written before sealing keeps working.
+func (k *Key) Open(s string) (string, error) {
+	if !IsSealed(s) {
+		return s, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, Prefix))
+	if err != nil {
+		return "", fmt.Errorf("open sealed value: %w", err)
+	}
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), k.identities...)
+	if err != nil {
+		return "", fmt.Errorf("open sealed value: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("open sealed value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Encrypt returns a writer that encrypts what is written to it into dst as
+// an age file. Close finishes the file; it does not close dst.
+func (k *Key) Encrypt(dst io.Writer) (io.WriteCloser, error) {
+	w, err := age.Encrypt(dst, k.recipient)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	return w, nil
+}
+
+// Decrypt returns a reader of the plaintext of the age file in src.
+func (k *Key) Decrypt(src io.Reader) (io.Reader, error) {
+	r, err := age.Decrypt(src, k.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return r, nil
+}
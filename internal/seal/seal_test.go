@@ -0,0 +1,107 @@
+package seal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) (*Key, string) {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	key, err := ParseKey(strings.NewReader(id.String()))
+	require.NoError(t, err)
+	return key, id.String()
+}
+
+func TestKey_SealOpen(t *testing.T) {
+	key, _ := testKey(t)
+
+	sealed, err := key.Seal("https://discord.com/api/webhooks/123/secret")
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "secret")
+
+	opened, err := key.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "https://discord.com/api/webhooks/123/secret", opened)
+
+	t.Run("sealing is idempotent", func(t *testing.T) {
+		again, err := key.Seal(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, sealed, again)
+	})
+
+	t.Run("plaintext and empty values pass through", func(t *testing.T) {
+		for _, s := range []string{"", "plain"} {
+			got, err := key.Open(s)
+			require.NoError(t, err)
+			assert.Equal(t, s, got)
+		}
+		empty, err := key.Seal("")
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("another key cannot open it", func(t *testing.T) {
+		other, _ := testKey(t)
+		_, err := other.Open(sealed)
+		assert.Error(t, err)
+	})
+}
+
+func TestKey_EncryptDecrypt(t *testing.T) {
+	key, _ := testKey(t)
+
+	var buf bytes.Buffer
+	w, err := key.Encrypt(&buf)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "session_secret: hunter2")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.NotContains(t, buf.String(), "hunter2")
+
+	r, err := key.Decrypt(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	plaintext, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "session_secret: hunter2", string(plaintext))
+
+	other, _ := testKey(t)
+	_, err = other.Decrypt(bytes.NewReader(buf.Bytes()))
+	assert.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	_, secret := testKey(t)
+
+	t.Run("from SOPS_AGE_KEY", func(t *testing.T) {
+		t.Setenv("SOPS_AGE_KEY", secret)
+		_, err := LoadKey()
+		assert.NoError(t, err)
+	})
+
+	t.Run("from SOPS_AGE_KEY_FILE", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.txt")
+		require.NoError(t, os.WriteFile(path, []byte("# created: now\n"+secret+"\n"), 0600))
+		t.Setenv("SOPS_AGE_KEY", "")
+		t.Setenv("SOPS_AGE_KEY_FILE", path)
+		_, err := LoadKey()
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Setenv("SOPS_AGE_KEY", "")
+		t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+		_, err := LoadKey()
+		assert.ErrorIs(t, err, ErrNoKey)
+	})
+}
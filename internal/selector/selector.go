@@ -0,0 +1,124 @@
+// Package selector matches containers and services by label, so operational
+// commands can act on a whole stack or label group at once.
+package selector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pseudo-labels that every selectable object carries besides its own labels.
+const (
+	// StackLabel is the bosun stack (rendered compose file) of the object.
+	StackLabel = "bosun.stack"
+	// ServiceLabel is the compose service name of the object.
+	ServiceLabel = "bosun.service"
+)
+
+// keyRegex matches label keys: alphanumerics, dots, dashes, underscores, and
+// slashes, as Docker and Kubernetes use them.
+var keyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// term is one requirement of a selector.
+type term struct {
+	key   string
+	op    string // "=", "!=", or "" (key present)
+	value string
+}
+
+// Selector is a set of label requirements that must all hold, written as
+// comma-separated key=value, key!=value, or key terms.
+type Selector struct {
+	terms []term
+}
+
+// Parse parses a selector such as "bosun.stack=media,app!=sonarr,tier".
+// An empty string selects everything.
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		t := term{key: part}
+		if k, v, ok := strings.Cut(part, "!="); ok {
+			t = term{key: k, op: "!=", value: v}
+		} else if k, v, ok := strings.Cut(part, "="); ok {
+			t = term{key: k, op: "=", value: v}
+		}
+		t.key = strings.TrimSpace(t.key)
+		t.value = strings.TrimSpace(t.value)
+		if !keyRegex.MatchString(t.key) {
+			return Selector{}, fmt.Errorf("invalid selector %q: bad label key %q", s, t.key)
+		}
+		sel.terms = append(sel.terms, t)
+	}
+	return sel, nil
+}
+
+// Stack returns a selector for one stack.
+func Stack(name string) Selector {
+	return Selector{terms: []term{{key: StackLabel, op: "=", value: name}}}
+}
+
+// And returns a selector requiring both s and other.
+func (s Selector) And(other Selector) Selector {
+	return Selector{terms: append(append([]term(nil), s.terms...), other.terms...)}
+}
+
+// Empty reports whether the selector has no terms and so matches everything.
+func (s Selector) Empty() bool {
+	return len(s.terms) == 0
+}
+
+// Matches reports whether labels satisfy every term.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, t := range s.terms {
+		v, ok := labels[t.key]
+		switch t.op {
+		case "=":
+			if !ok || v != t.value {
+				return false
+			}
+		case "!=":
+			if ok && v == t.value {
+				return false
+			}
+		default:
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the selector in its parseable form.
+func (s Selector) String() string {
+	parts := make([]string, len(s.terms))
+	for i, t := range s.terms {
+		parts[i] = t.key + t.op + t.value
+	}
+	return strings.Join(parts, ",")
+}
+
+// ComposeLabels converts compose labels, in map or "key=value" list form,
+// to a map.
+func ComposeLabels(labels any) map[string]string {
+	result := make(map[string]string)
+	switch l := labels.(type) {
+	case map[string]any:
+		for k, v := range l {
+			result[k] = fmt.Sprint(v)
+		}
+	case []any:
+		for _, entry := range l {
+			k, v, _ := strings.Cut(fmt.Sprint(entry), "=")
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	sel, err := Parse(" bosun.stack=media, app!=sonarr ,tier")
+	require.NoError(t, err)
+	assert.Equal(t, "bosun.stack=media,app!=sonarr,tier", sel.String())
+
+	empty, err := Parse("")
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+
+	for _, bad := range []string{"=media", "app name=arr", "!=x"} {
+		_, err := Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	labels := map[string]string{StackLabel: "media", "app": "arr", "tier": ""}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"bosun.stack=media", true},
+		{"bosun.stack=infra", false},
+		{"app=arr,bosun.stack=media", true},
+		{"app=arr,bosun.stack=infra", false},
+		{"app!=arr", false},
+		{"app!=other", true},
+		{"missing!=x", true},
+		{"tier", true},
+		{"missing", false},
+		{"missing=", false},
+		{"tier=", true},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.selector)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, sel.Matches(labels), tt.selector)
+	}
+}
+
+func TestStackAnd(t *testing.T) {
+	sel, err := Parse("app=arr")
+	require.NoError(t, err)
+	combined := Stack("media").And(sel)
+
+	assert.True(t, combined.Matches(map[string]string{StackLabel: "media", "app": "arr"}))
+	assert.False(t, combined.Matches(map[string]string{StackLabel: "infra", "app": "arr"}))
+	assert.Equal(t, "app=arr", sel.String(), "And must not modify its operands")
+}
+
+func TestComposeLabels(t *testing.T) {
+	assert.Equal(t,
+		map[string]string{"app": "arr", "traefik.enable": "true"},
+		ComposeLabels(map[string]any{"app": "arr", "traefik.enable": true}))
+	assert.Equal(t,
+		map[string]string{"app": "arr", "flag": ""},
+		ComposeLabels([]any{"app=arr", "flag"}))
+	assert.Empty(t, ComposeLabels(nil))
+}
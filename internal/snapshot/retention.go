@@ -0,0 +1,194 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/timezone"
+)
+
+// PinsFile is the file in the snapshots directory that records pinned
+// snapshots.
+const PinsFile = "pins.json"
+
+// Retention decides which snapshots Cleanup keeps. A snapshot is kept if
+// any rule keeps it; pinned snapshots are always kept and don't count
+// toward Keep.
+type Retention struct {
+	// Keep is how many of the newest snapshots are kept. Zero or less
+	// keeps MaxSnapshots.
+	Keep int
+	// KeepDaily keeps the newest snapshot of each of the last KeepDaily
+	// days, today included, in the display time zone (BOSUN_TIMEZONE).
+	KeepDaily int
+}
+
+// DefaultRetention keeps the newest MaxSnapshots snapshots.
+var DefaultRetention = Retention{Keep: MaxSnapshots}
+
+// PinInfo records a pinned snapshot.
+type PinInfo struct {
+	// Note says why the snapshot is pinned, e.g. "before postgres 17".
+	Note     string    `json:"note,omitempty"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// Expired returns the snapshots, newest first, that the retention policy
+// does not keep as of now. Pinned snapshots are never expired.
+func (r Retention) Expired(snapshots []SnapshotInfo, now time.Time) []SnapshotInfo {
+	keep := r.Keep
+	if keep <= 0 {
+		keep = MaxSnapshots
+	}
+
+	loc := timezone.Location()
+	y, m, d := now.In(loc).Date()
+	oldestDay := time.Date(y, m, d, 0, 0, 0, 0, loc).AddDate(0, 0, -(r.KeepDaily - 1))
+
+	var expired []SnapshotInfo
+	seenDays := make(map[string]bool)
+	kept := 0
+	for _, snap := range sortedNewestFirst(snapshots) {
+		if snap.Pinned {
+			continue
+		}
+
+		keepIt := false
+		if kept < keep {
+			keepIt = true
+			kept++
+		}
+		created := snap.Created.In(loc)
+		if day := created.Format("2006-01-02"); r.KeepDaily > 0 && !created.Before(oldestDay) && !seenDays[day] {
+			seenDays[day] = true
+			keepIt = true
+		}
+
+		if !keepIt {
+			expired = append(expired, snap)
+		}
+	}
+	return expired
+}
+
+// sortedNewestFirst returns a copy of snapshots sorted newest first.
+func sortedNewestFirst(snapshots []SnapshotInfo) []SnapshotInfo {
+	sorted := append([]SnapshotInfo(nil), snapshots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created.After(sorted[j].Created)
+	})
+	return sorted
+}
+
+// isSnapshotName reports whether name names a snapshot directory rather
+// than a path.
+func isSnapshotName(name string) bool {
+	return strings.HasPrefix(name, SnapshotPrefix) && filepath.Base(name) == name
+}
+
+// Pins returns the pinned snapshots by name. A missing pins file means
+// nothing is pinned.
+func Pins(manifestDir string) (map[string]PinInfo, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotsDir(manifestDir), PinsFile))
+	if os.IsNotExist(err) {
+		return map[string]PinInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot pins: %w", err)
+	}
+
+	pins := make(map[string]PinInfo)
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("parse snapshot pins: %w", err)
+	}
+	return pins, nil
+}
+
+// Pin pins a snapshot so retention never removes it, replacing the note of
+// an existing pin.
+func Pin(manifestDir, name, note string) error {
+	if !isSnapshotName(name) {
+		return fmt.Errorf("snapshot not found: %s", name)
+	}
+	if _, err := os.Stat(filepath.Join(snapshotsDir(manifestDir), name)); err != nil {
+		return fmt.Errorf("snapshot not found: %s", name)
+	}
+
+	pins, err := Pins(manifestDir)
+	if err != nil {
+		return err
+	}
+	pins[name] = PinInfo{Note: note, PinnedAt: time.Now().UTC()}
+	return savePins(manifestDir, pins)
+}
+
+// Unpin removes a snapshot's pin, leaving it to the retention policy. It
+// reports whether the snapshot was pinned.
+func Unpin(manifestDir, name string) (bool, error) {
+	pins, err := Pins(manifestDir)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := pins[name]; !ok {
+		return false, nil
+	}
+	delete(pins, name)
+	return true, savePins(manifestDir, pins)
+}
+
+// prunePins drops pins for snapshots that no longer exist.
+func prunePins(manifestDir string, snapshots []SnapshotInfo) error {
+	pins, err := Pins(manifestDir)
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool, len(snapshots))
+	for _, snap := range snapshots {
+		exists[snap.Name] = true
+	}
+	pruned := false
+	for name := range pins {
+		if !exists[name] {
+			delete(pins, name)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return savePins(manifestDir, pins)
+}
+
+// savePins atomically writes the pins file.
+func savePins(manifestDir string, pins map[string]PinInfo) error {
+	dir := snapshotsDir(manifestDir)
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot pins: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, ".pins-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // Cleanup on failure
+
+	if _, err := tmpFile.Write(append(data, '\n')); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("write snapshot pins: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(dir, PinsFile)); err != nil {
+		return fmt.Errorf("save snapshot pins: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeSnapshots creates snapshot directories named for the given times.
+func makeSnapshots(t *testing.T, manifestDir string, times ...time.Time) []string {
+	t.Helper()
+	var names []string
+	for _, ts := range times {
+		name := SnapshotPrefix + ts.UTC().Format(DateFormat)
+		path := filepath.Join(snapshotsDir(manifestDir), name)
+		require.NoError(t, os.MkdirAll(path, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(path, "test.yml"), []byte("test"), 0644))
+		names = append(names, name)
+	}
+	return names
+}
+
+func snapshotNames(snapshots []SnapshotInfo) []string {
+	var names []string
+	for _, snap := range snapshots {
+		names = append(names, snap.Name)
+	}
+	return names
+}
+
+func TestRetention_Expired(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	var snapshots []SnapshotInfo
+	// Three snapshots a day for five days, oldest first
+	for day := 4; day >= 0; day-- {
+		for hour := 9; hour <= 11; hour++ {
+			created := time.Date(2024, 6, 10-day, hour, 0, 0, 0, time.UTC)
+			snapshots = append(snapshots, SnapshotInfo{Name: created.Format(DateFormat), Created: created})
+		}
+	}
+
+	t.Run("keep newest", func(t *testing.T) {
+		expired := Retention{Keep: 10}.Expired(snapshots, now)
+		require.Len(t, expired, 5)
+		assert.Equal(t, "20240607-100000", expired[0].Name, "expired newest first")
+		assert.Equal(t, "20240606-090000", expired[4].Name)
+	})
+
+	t.Run("zero keep uses MaxSnapshots", func(t *testing.T) {
+		assert.Empty(t, Retention{}.Expired(snapshots, now))
+	})
+
+	t.Run("keep daily", func(t *testing.T) {
+		expired := Retention{Keep: 2, KeepDaily: 3}.Expired(snapshots, now)
+		kept := len(snapshots) - len(expired)
+		assert.Equal(t, 4, kept, "two newest plus the newest of the two days before today")
+		assert.NotContains(t, snapshotNames(expired), "20240609-110000")
+		assert.NotContains(t, snapshotNames(expired), "20240608-110000")
+		assert.Contains(t, snapshotNames(expired), "20240608-100000")
+		assert.Contains(t, snapshotNames(expired), "20240607-110000", "older than keep_daily days")
+	})
+
+	t.Run("pinned are kept and not counted", func(t *testing.T) {
+		pinned := append([]SnapshotInfo(nil), snapshots...)
+		pinned[0].Pinned = true             // oldest
+		pinned[len(pinned)-1].Pinned = true // newest
+		expired := Retention{Keep: 2}.Expired(pinned, now)
+		assert.Len(t, expired, len(pinned)-4)
+		assert.NotContains(t, snapshotNames(expired), pinned[0].Name)
+		assert.NotContains(t, snapshotNames(expired), pinned[len(pinned)-2].Name)
+	})
+}
+
+func TestPinUnpin(t *testing.T) {
+	tmpDir := t.TempDir()
+	names := makeSnapshots(t, tmpDir,
+		time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+	)
+
+	pins, err := Pins(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, pins)
+
+	require.NoError(t, Pin(tmpDir, names[0], "before postgres 17"))
+
+	pins, err = Pins(tmpDir)
+	require.NoError(t, err)
+	require.Contains(t, pins, names[0])
+	assert.Equal(t, "before postgres 17", pins[names[0]].Note)
+	assert.False(t, pins[names[0]].PinnedAt.IsZero())
+
+	snapshots, err := List(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.False(t, snapshots[0].Pinned)
+	assert.True(t, snapshots[1].Pinned)
+	assert.Equal(t, "before postgres 17", snapshots[1].PinNote)
+
+	removed, err := Unpin(tmpDir, names[0])
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = Unpin(tmpDir, names[0])
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	t.Run("unknown snapshot", func(t *testing.T) {
+		assert.ErrorContains(t, Pin(tmpDir, "snapshot-20990101-000000", ""), "snapshot not found")
+		assert.ErrorContains(t, Pin(tmpDir, "../snapshots", ""), "snapshot not found")
+		assert.ErrorContains(t, Pin(tmpDir, PinsFile, ""), "snapshot not found")
+	})
+}
+
+func TestCleanupWith_KeepsPinned(t *testing.T) {
+	tmpDir := t.TempDir()
+	var times []time.Time
+	for i := 0; i < 5; i++ {
+		times = append(times, time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC))
+	}
+	names := makeSnapshots(t, tmpDir, times...)
+	require.NoError(t, Pin(tmpDir, names[0], ""))
+
+	require.NoError(t, CleanupWith(tmpDir, Retention{Keep: 2}))
+
+	snapshots, err := List(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{names[4], names[3], names[0]}, snapshotNames(snapshots))
+}
+
+func TestCleanupWith_PrunesStalePins(t *testing.T) {
+	tmpDir := t.TempDir()
+	names := makeSnapshots(t, tmpDir, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, Pin(tmpDir, names[0], ""))
+	require.NoError(t, os.RemoveAll(filepath.Join(snapshotsDir(tmpDir), names[0])))
+
+	require.NoError(t, CleanupWith(tmpDir, DefaultRetention))
+
+	pins, err := Pins(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, pins)
+}
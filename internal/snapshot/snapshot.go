@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/cameronsjo/bosun/internal/fileutil"
-	"github.com/google/uuid"
 )
 
 const (
@@ -33,6 +32,9 @@ type SnapshotInfo struct {
 	Path      string
 	Created   time.Time
 	FileCount int
+	// Pinned snapshots are never removed by Cleanup.
+	Pinned  bool
+	PinNote string
 }
 
 // snapshotsDir returns the path to the snapshots directory.
@@ -48,7 +50,16 @@ func outputDir(manifestDir string) string {
 // Create creates a snapshot of the current output directory.
 // Returns the snapshot name, or an empty string if there was nothing to snapshot.
 func Create(manifestDir string) (string, error) {
-	outDir := outputDir(manifestDir)
+	return CreateWith(manifestDir, outputDir(manifestDir), DefaultRetention)
+}
+
+// CreateWith is Create for projects whose output directory is not
+// <manifestDir>/output, cleaning up old snapshots with the given retention
+// policy.
+func CreateWith(manifestDir, outDir string, retention Retention) (string, error) {
+	// Provisioning leaves the output directory a symlink to its current
+	// generation; snapshot what it points at.
+	outDir = fileutil.ResolveDir(outDir)
 
 	// Check if output directory exists and has content
 	if !dirHasContent(outDir) {
@@ -75,7 +86,7 @@ func Create(manifestDir string) (string, error) {
 	}
 
 	// Create snapshot name with timestamp (nanosecond precision to prevent collisions)
-	snapshotName := SnapshotPrefix + time.Now().Format(DateFormatPrecise)
+	snapshotName := SnapshotPrefix + time.Now().UTC().Format(DateFormatPrecise)
 	snapshotPath := filepath.Join(snapDir, snapshotName)
 
 	// Ensure snapshot directory exists
@@ -93,7 +104,7 @@ func Create(manifestDir string) (string, error) {
 	}
 
 	// Cleanup old snapshots
-	if err := Cleanup(manifestDir); err != nil {
+	if err := CleanupWith(manifestDir, retention); err != nil {
 		// Log but don't fail on cleanup errors
 		fmt.Fprintf(os.Stderr, "warning: failed to cleanup old snapshots: %v\n", err)
 	}
@@ -113,6 +124,11 @@ func List(manifestDir string) ([]SnapshotInfo, error) {
 		return nil, fmt.Errorf("read snapshots directory: %w", err)
 	}
 
+	pins, err := Pins(manifestDir)
+	if err != nil {
+		return nil, err
+	}
+
 	var snapshots []SnapshotInfo
 	for _, entry := range entries {
 		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), SnapshotPrefix) {
@@ -142,11 +158,14 @@ func List(manifestDir string) ([]SnapshotInfo, error) {
 			}
 		}
 
+		pin, pinned := pins[entry.Name()]
 		snapshots = append(snapshots, SnapshotInfo{
 			Name:      entry.Name(),
 			Path:      path,
 			Created:   created,
 			FileCount: fileCount,
+			Pinned:    pinned,
+			PinNote:   pin.Note,
 		})
 	}
 
@@ -159,11 +178,17 @@ func List(manifestDir string) ([]SnapshotInfo, error) {
 }
 
 // Restore restores a snapshot atomically, creating a pre-rollback backup first.
-// Uses temp directory + atomic rename pattern to prevent broken state on failure.
+// The snapshot is copied into a new output generation that replaces the
+// output directory in one rename (see fileutil.CommitGeneration).
 func Restore(manifestDir, snapshotName string) error {
+	return RestoreTo(manifestDir, outputDir(manifestDir), snapshotName)
+}
+
+// RestoreTo is Restore for projects whose output directory is not
+// <manifestDir>/output.
+func RestoreTo(manifestDir, outDir, snapshotName string) error {
 	snapDir := snapshotsDir(manifestDir)
 	snapshotPath := filepath.Join(snapDir, snapshotName)
-	outDir := outputDir(manifestDir)
 
 	// Verify snapshot exists
 	if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
@@ -181,64 +206,24 @@ func Restore(manifestDir, snapshotName string) error {
 
 	// Create pre-rollback backup if output exists
 	if dirHasContent(outDir) {
-		backupName := "pre-rollback-" + time.Now().Format(DateFormatPrecise)
+		backupName := "pre-rollback-" + time.Now().UTC().Format(DateFormatPrecise)
 		backupPath := filepath.Join(snapDir, backupName)
 
 		if err := os.MkdirAll(backupPath, 0755); err != nil {
 			return fmt.Errorf("create backup directory: %w", err)
 		}
 
-		if err := fileutil.CopyDir(outDir, backupPath); err != nil {
+		if err := fileutil.CopyDir(fileutil.ResolveDir(outDir), backupPath); err != nil {
 			os.RemoveAll(backupPath)
 			return fmt.Errorf("create pre-rollback backup: %w", err)
 		}
 	}
 
-	// Atomic restore: copy to temp directory first, then rename
-	// Use UUID to prevent race conditions with concurrent restores
-	restoreID := uuid.New().String()[:8]
-	tempDir := outDir + ".restore-temp-" + restoreID
-	oldDir := outDir + ".restore-old-" + restoreID
-
-	// Step 1: Copy snapshot to temp directory
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("create temp restore directory: %w", err)
-	}
-
-	if err := fileutil.CopyDir(snapshotPath, tempDir); err != nil {
-		os.RemoveAll(tempDir)
-		return fmt.Errorf("copy snapshot to temp: %w", err)
-	}
-
-	// Step 2: Check if output directory exists (even if empty)
-	_, statErr := os.Stat(outDir)
-	outputExists := statErr == nil
-
-	// Step 3: Atomic swap - rename current output to old (if exists)
-	if outputExists {
-		if err := os.Rename(outDir, oldDir); err != nil {
-			os.RemoveAll(tempDir)
-			return fmt.Errorf("rename current output: %w", err)
-		}
-	}
-
-	// Step 4: Rename temp to output
-	if err := os.Rename(tempDir, outDir); err != nil {
-		// Try to restore old directory on failure
-		if outputExists {
-			if recoverErr := os.Rename(oldDir, outDir); recoverErr != nil {
-				// Recovery failed - surface both errors
-				os.RemoveAll(tempDir) // Best effort cleanup
-				return fmt.Errorf("rename temp to output: %w (recovery also failed: %v)", err, recoverErr)
-			}
-		}
-		os.RemoveAll(tempDir) // Best effort cleanup
-		return fmt.Errorf("rename temp to output: %w", err)
-	}
-
-	// Step 5: Cleanup old directory
-	if outputExists {
-		os.RemoveAll(oldDir)
+	// Atomic restore: copy into a new output generation, then swap it in
+	if err := fileutil.CommitGeneration(outDir, func(genDir string) error {
+		return fileutil.CopyDir(snapshotPath, genDir)
+	}); err != nil {
+		return fmt.Errorf("restore snapshot: %w", err)
 	}
 
 	return nil
@@ -247,19 +232,23 @@ func Restore(manifestDir, snapshotName string) error {
 // Cleanup removes snapshots beyond the retention limit.
 // Continues deleting even if individual removals fail, returning a summary of all errors.
 func Cleanup(manifestDir string) error {
+	return CleanupWith(manifestDir, DefaultRetention)
+}
+
+// CleanupWith removes the snapshots that retention does not keep, along
+// with pins left behind by snapshots removed by hand.
+func CleanupWith(manifestDir string, retention Retention) error {
 	snapshots, err := List(manifestDir)
 	if err != nil {
 		return err
 	}
-
-	if len(snapshots) <= MaxSnapshots {
-		return nil
+	if err := prunePins(manifestDir, snapshots); err != nil {
+		return err
 	}
 
-	// Remove oldest snapshots (keeping MaxSnapshots)
 	// Continue on errors to clean up as many as possible
 	var errs []string
-	for _, snap := range snapshots[MaxSnapshots:] {
+	for _, snap := range retention.Expired(snapshots, time.Now()) {
 		if err := removeWithRetry(snap.Path, 3); err != nil {
 			errs = append(errs, fmt.Sprintf("%s: %v", snap.Name, err))
 		}
@@ -274,16 +263,21 @@ func Cleanup(manifestDir string) error {
 
 // GetRestoredFiles returns a list of files in the output directory.
 func GetRestoredFiles(manifestDir string) ([]string, error) {
-	outDir := outputDir(manifestDir)
+	return RestoredFilesIn(outputDir(manifestDir))
+}
+
+// RestoredFilesIn returns the YAML files in outDir, relative to its parent.
+func RestoredFilesIn(outDir string) ([]string, error) {
+	root := fileutil.ResolveDir(outDir)
 	var files []string
 
-	err := filepath.WalkDir(outDir, func(path string, d os.DirEntry, err error) error {
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if !d.IsDir() && strings.HasSuffix(d.Name(), ".yml") {
-			relPath, _ := filepath.Rel(manifestDir, path)
-			files = append(files, relPath)
+			relPath, _ := filepath.Rel(root, path)
+			files = append(files, filepath.Join(filepath.Base(outDir), relPath))
 		}
 		return nil
 	})
@@ -448,6 +448,32 @@ func TestCleanup_UnderLimit(t *testing.T) {
 	assert.Len(t, snapshots, 5)
 }
 
+func TestRestoredFilesIn_CustomOutputDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	outDir := filepath.Join(tmpDir, "deploy", "rendered")
+	require.NoError(t, os.MkdirAll(filepath.Join(outDir, "compose"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(outDir, "compose", "stack.yml"), []byte(""), 0644))
+
+	files, err := RestoredFilesIn(outDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rendered/compose/stack.yml"}, files)
+}
+
+func TestRestoreTo_CustomOutputDir(t *testing.T) {
+	manifestDir := t.TempDir()
+	snapPath := filepath.Join(snapshotsDir(manifestDir), "snapshot-20240101-120000")
+	require.NoError(t, os.MkdirAll(snapPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(snapPath, "stack.yml"), []byte("restored"), 0644))
+
+	outDir := filepath.Join(t.TempDir(), "rendered")
+	require.NoError(t, RestoreTo(manifestDir, outDir, "snapshot-20240101-120000"))
+
+	data, err := os.ReadFile(filepath.Join(outDir, "stack.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "restored", string(data))
+	assert.NoDirExists(t, filepath.Join(manifestDir, "output"))
+}
+
 func TestGetRestoredFiles_NoOutputDir(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,93 @@
+package state
+
+import "time"
+
+// MaxAudit is how many audit entries the state keeps.
+const MaxAudit = 100
+
+// Audit actions for all-stops.
+const (
+	AuditAllStop = "all-stop"
+	AuditExtend  = "extend"
+	AuditResume  = "resume"
+	AuditExpire  = "expire"
+)
+
+// AllStop is an emergency pause of all automation (polling, webhooks, and
+// scheduled tasks) until Until, when it lifts on its own.
+type AllStop struct {
+	At     time.Time `json:"at"`
+	Until  time.Time `json:"until"`
+	By     string    `json:"by"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// AuditEntry records who took an operator action, when, and why.
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"`
+	By     string    `json:"by"`
+	Reason string    `json:"reason,omitempty"`
+	Until  time.Time `json:"until,omitzero"`
+}
+
+// RecordAudit appends e to the audit log, dropping the oldest entries
+// beyond MaxAudit.
+func (st *State) RecordAudit(e AuditEntry) {
+	e.At = e.At.UTC()
+	st.Audit = append(st.Audit, e)
+	if extra := len(st.Audit) - MaxAudit; extra > 0 {
+		st.Audit = append([]AuditEntry(nil), st.Audit[extra:]...)
+	}
+}
+
+// ActiveAllStop returns the all-stop in force at now, or nil when there is
+// none or its window has passed.
+func (st *State) ActiveAllStop(now time.Time) *AllStop {
+	if st.AllStop == nil || !now.Before(st.AllStop.Until) {
+		return nil
+	}
+	return st.AllStop
+}
+
+// StartAllStop pauses automation from now for d. An all-stop already in
+// force is extended to now+d instead, keeping its start time. Returns true
+// when it extended one.
+func (st *State) StartAllStop(now time.Time, d time.Duration, by, reason string) (extended bool) {
+	until := now.Add(d).UTC()
+	action := AuditAllStop
+	if active := st.ActiveAllStop(now); active != nil {
+		extended, action = true, AuditExtend
+		active.Until, active.By = until, by
+		if reason != "" {
+			active.Reason = reason
+		}
+	} else {
+		st.AllStop = &AllStop{At: now.UTC(), Until: until, By: by, Reason: reason}
+	}
+	st.RecordAudit(AuditEntry{At: now, Action: action, By: by, Reason: reason, Until: until})
+	return extended
+}
+
+// EndAllStop lifts the all-stop in force at now. Returns false if none was.
+func (st *State) EndAllStop(now time.Time, by, reason string) bool {
+	if st.ActiveAllStop(now) == nil {
+		st.ExpireAllStop(now)
+		return false
+	}
+	st.AllStop = nil
+	st.RecordAudit(AuditEntry{At: now, Action: AuditResume, By: by, Reason: reason})
+	return true
+}
+
+// ExpireAllStop clears an all-stop whose window has passed by now,
+// auditing that automation resumed. Returns the cleared all-stop, or nil.
+func (st *State) ExpireAllStop(now time.Time) *AllStop {
+	stop := st.AllStop
+	if stop == nil || now.Before(stop.Until) {
+		return nil
+	}
+	st.AllStop = nil
+	st.RecordAudit(AuditEntry{At: stop.Until, Action: AuditExpire, By: "bosun", Reason: "window ended"})
+	return stop
+}
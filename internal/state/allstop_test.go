@@ -0,0 +1,74 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllStop_Lifecycle(t *testing.T) {
+	start := time.Date(2026, 3, 6, 14, 0, 0, 0, time.UTC)
+	st := &State{}
+
+	assert.False(t, st.StartAllStop(start, time.Hour, "ops@tower", "db corruption"))
+	stop := st.ActiveAllStop(start.Add(30 * time.Minute))
+	require.NotNil(t, stop)
+	assert.Equal(t, start.Add(time.Hour), stop.Until)
+	assert.Equal(t, "ops@tower", stop.By)
+
+	// Extending keeps the start time and the reason
+	assert.True(t, st.StartAllStop(start.Add(30*time.Minute), time.Hour, "oncall@laptop", ""))
+	assert.Equal(t, start, st.AllStop.At)
+	assert.Equal(t, start.Add(90*time.Minute), st.AllStop.Until)
+	assert.Equal(t, "db corruption", st.AllStop.Reason)
+
+	assert.Nil(t, st.ActiveAllStop(start.Add(90*time.Minute)))
+	assert.Nil(t, st.ExpireAllStop(start.Add(time.Hour)), "not yet expired")
+	expired := st.ExpireAllStop(start.Add(2 * time.Hour))
+	require.NotNil(t, expired)
+	assert.Nil(t, st.AllStop)
+
+	var actions []string
+	for _, e := range st.Audit {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []string{AuditAllStop, AuditExtend, AuditExpire}, actions)
+	assert.Equal(t, start.Add(90*time.Minute), st.Audit[2].At)
+}
+
+func TestAllStop_End(t *testing.T) {
+	now := time.Date(2026, 3, 6, 14, 0, 0, 0, time.UTC)
+	st := &State{}
+
+	assert.False(t, st.EndAllStop(now, "ops", ""))
+	st.StartAllStop(now, time.Hour, "ops", "incident")
+	assert.True(t, st.EndAllStop(now.Add(time.Minute), "ops", "fixed"))
+	assert.Nil(t, st.AllStop)
+	assert.Equal(t, AuditEntry{At: now.Add(time.Minute), Action: AuditResume, By: "ops", Reason: "fixed"}, st.Audit[1])
+}
+
+func TestRecordAudit_Caps(t *testing.T) {
+	st := &State{}
+	for i := 0; i < MaxAudit+5; i++ {
+		st.RecordAudit(AuditEntry{Action: AuditAllStop, By: fmt.Sprintf("op%d", i)})
+	}
+	require.Len(t, st.Audit, MaxAudit)
+	assert.Equal(t, "op5", st.Audit[0].By)
+}
+
+func TestAllStop_SaveRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Date(2026, 3, 6, 14, 0, 0, 0, time.UTC)
+
+	st := &State{}
+	st.StartAllStop(now, time.Hour, "ops@tower", "incident")
+	require.NoError(t, store.Save(st))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, st.AllStop, loaded.AllStop)
+	assert.Equal(t, st.Audit, loaded.Audit)
+}
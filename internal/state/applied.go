@@ -0,0 +1,52 @@
+package state
+
+import (
+	"slices"
+	"time"
+)
+
+// Applied records the last successful deploy of a stack.
+type Applied struct {
+	// Hash is the content hash of the stack's rendered compose file and
+	// the shared files deployed next to it.
+	Hash   string `json:"hash"`
+	Commit string `json:"commit,omitempty"`
+	// Since is when a deploy first applied Hash; At is the latest deploy
+	// of the stack, which may have reapplied it unchanged.
+	Since time.Time `json:"since"`
+	At    time.Time `json:"at"`
+}
+
+// RecordApplied records that a deploy at commit applied a stack with the
+// given content hash.
+func (st *State) RecordApplied(stack, hash, commit string, at time.Time) {
+	at = at.UTC()
+	if st.Applied == nil {
+		st.Applied = make(map[string]Applied)
+	}
+	a := st.Applied[stack]
+	if a.Hash != hash {
+		a.Hash, a.Since = hash, at
+	}
+	a.Commit, a.At = commit, at
+	st.Applied[stack] = a
+}
+
+// NeverDeployed reports whether the state knows a stack was never
+// deployed: stacks are tracked, but it has no applied record and no deploy
+// in the history touched it. Without tracking (before the first deploy
+// that recorded applied stacks) nothing is known, and it returns false.
+func (st *State) NeverDeployed(stack string) bool {
+	if len(st.Applied) == 0 {
+		return false
+	}
+	if _, ok := st.Applied[stack]; ok {
+		return false
+	}
+	for _, d := range st.Deploys {
+		if slices.Contains(d.Stacks, stack) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordApplied(t *testing.T) {
+	st := &State{}
+	first := time.Date(2026, 3, 6, 14, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	st.RecordApplied("media", "aaa", "c1", first)
+
+	a := st.Applied["media"]
+	assert.Equal(t, Applied{Hash: "aaa", Commit: "c1", Since: first.UTC(), At: first.UTC()}, a)
+
+	// Reapplying the same content keeps Since
+	later := first.Add(time.Hour)
+	st.RecordApplied("media", "aaa", "c2", later)
+	a = st.Applied["media"]
+	assert.Equal(t, first.UTC(), a.Since)
+	assert.Equal(t, later.UTC(), a.At)
+	assert.Equal(t, "c2", a.Commit)
+
+	// New content resets it
+	st.RecordApplied("media", "bbb", "c3", later.Add(time.Hour))
+	assert.Equal(t, later.Add(time.Hour).UTC(), st.Applied["media"].Since)
+}
+
+func TestNeverDeployed(t *testing.T) {
+	st := &State{}
+	assert.False(t, st.NeverDeployed("media"), "unknown without tracking")
+
+	st.RecordApplied("core", "aaa", "c1", time.Now())
+	st.RecordDeploy(Deploy{At: time.Now(), Stacks: []string{"apps"}})
+	assert.False(t, st.NeverDeployed("core"))
+	assert.False(t, st.NeverDeployed("apps"), "deployed before tracking started")
+	assert.True(t, st.NeverDeployed("media"))
+}
+
+func TestStore_Applied(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.Update(func(st *State) error {
+		st.RecordApplied("media", "aaa", "c1", time.Now())
+		return nil
+	}))
+
+	st, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "aaa", st.Applied["media"].Hash)
+}
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxDays is how many days of activity counters the state keeps.
+const MaxDays = 35
+
+// dayLayout is the format of DayStats.Day.
+const dayLayout = "2006-01-02"
+
+// DayStats counts one UTC day of activity for the weekly digest.
+type DayStats struct {
+	Day         string `json:"day"` // YYYY-MM-DD, UTC
+	Reconciles  int    `json:"reconciles,omitempty"`
+	Failures    int    `json:"failures,omitempty"`
+	DriftEvents int    `json:"drift_events,omitempty"`
+	// Deploys counts deploys by stack.
+	Deploys map[string]int `json:"deploys,omitempty"`
+	// Unhealthy counts containers turning unhealthy, by container.
+	Unhealthy map[string]int `json:"unhealthy,omitempty"`
+	// DiskPercent is the highest Docker root disk usage sampled that day.
+	DiskPercent float64 `json:"disk_percent,omitempty"`
+}
+
+// Day returns the counters for the UTC day of at, adding the day (and
+// dropping days beyond MaxDays) when it is new.
+func (st *State) Day(at time.Time) *DayStats {
+	day := at.UTC().Format(dayLayout)
+	for i := range st.Days {
+		if st.Days[i].Day == day {
+			return &st.Days[i]
+		}
+	}
+
+	st.Days = append(st.Days, DayStats{Day: day})
+	sort.Slice(st.Days, func(i, j int) bool { return st.Days[i].Day < st.Days[j].Day })
+	if extra := len(st.Days) - MaxDays; extra > 0 {
+		st.Days = append([]DayStats(nil), st.Days[extra:]...)
+	}
+	for i := range st.Days {
+		if st.Days[i].Day == day {
+			return &st.Days[i]
+		}
+	}
+	return &DayStats{Day: day} // Older than every kept day; not recorded
+}
+
+// Digest summarizes a period of daily activity.
+type Digest struct {
+	From, To    time.Time
+	Reconciles  int
+	Failures    int
+	DriftEvents int
+	// Deploys counts deploys by stack.
+	Deploys map[string]int
+	// Flapping are the containers that turned unhealthy most often, most
+	// first.
+	Flapping []Count
+	// DiskTrend is the highest Docker root disk usage of each sampled day,
+	// oldest first.
+	DiskTrend []float64
+}
+
+// Count is a named counter.
+type Count struct {
+	Name  string
+	Count int
+}
+
+// MaxFlapping is how many flapping containers a digest lists.
+const MaxFlapping = 5
+
+// Digest summarizes the days from now-period through now.
+func (st *State) Digest(now time.Time, period time.Duration) Digest {
+	d := Digest{From: now.Add(-period), To: now, Deploys: make(map[string]int)}
+	from := d.From.UTC().Format(dayLayout)
+	to := now.UTC().Format(dayLayout)
+
+	unhealthy := make(map[string]int)
+	for _, day := range st.Days {
+		if day.Day < from || day.Day > to {
+			continue
+		}
+		d.Reconciles += day.Reconciles
+		d.Failures += day.Failures
+		d.DriftEvents += day.DriftEvents
+		for stack, n := range day.Deploys {
+			d.Deploys[stack] += n
+		}
+		for name, n := range day.Unhealthy {
+			unhealthy[name] += n
+		}
+		if day.DiskPercent > 0 {
+			d.DiskTrend = append(d.DiskTrend, day.DiskPercent)
+		}
+	}
+
+	for name, n := range unhealthy {
+		d.Flapping = append(d.Flapping, Count{Name: name, Count: n})
+	}
+	sort.Slice(d.Flapping, func(i, j int) bool {
+		if d.Flapping[i].Count != d.Flapping[j].Count {
+			return d.Flapping[i].Count > d.Flapping[j].Count
+		}
+		return d.Flapping[i].Name < d.Flapping[j].Name
+	})
+	if len(d.Flapping) > MaxFlapping {
+		d.Flapping = d.Flapping[:MaxFlapping]
+	}
+	return d
+}
+
+// Lines formats the digest as report lines.
+func (d Digest) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("Reconciles: %d (%d failed)", d.Reconciles, d.Failures),
+		fmt.Sprintf("Drift events: %d", d.DriftEvents),
+	}
+
+	if len(d.Deploys) == 0 {
+		lines = append(lines, "Deploys: none")
+	} else {
+		stacks := make([]string, 0, len(d.Deploys))
+		for stack := range d.Deploys {
+			stacks = append(stacks, stack)
+		}
+		sort.Strings(stacks)
+		parts := make([]string, len(stacks))
+		for i, stack := range stacks {
+			parts[i] = fmt.Sprintf("%s %d", stack, d.Deploys[stack])
+		}
+		lines = append(lines, "Deploys: "+strings.Join(parts, ", "))
+	}
+
+	if len(d.Flapping) == 0 {
+		lines = append(lines, "Flapping services: none")
+	} else {
+		parts := make([]string, len(d.Flapping))
+		for i, c := range d.Flapping {
+			parts[i] = fmt.Sprintf("%s (unhealthy %dx)", c.Name, c.Count)
+		}
+		lines = append(lines, "Flapping services: "+strings.Join(parts, ", "))
+	}
+
+	if len(d.DiskTrend) > 0 {
+		first, last := d.DiskTrend[0], d.DiskTrend[len(d.DiskTrend)-1]
+		lines = append(lines, fmt.Sprintf("Docker disk: %.0f%% -> %.0f%% (%+.0f points)", first, last, last-first))
+	}
+	return lines
+}
@@ -0,0 +1,118 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_Day(t *testing.T) {
+	t.Run("returns the same day's counters", func(t *testing.T) {
+		st := &State{}
+		at := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+		st.Day(at).Reconciles++
+		st.Day(at.Add(time.Hour)).Reconciles++
+
+		require.Len(t, st.Days, 1)
+		assert.Equal(t, "2026-01-05", st.Days[0].Day)
+		assert.Equal(t, 2, st.Days[0].Reconciles)
+	})
+
+	t.Run("keeps the last MaxDays days", func(t *testing.T) {
+		st := &State{}
+		start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < MaxDays+5; i++ {
+			st.Day(start.AddDate(0, 0, i)).Reconciles = i
+		}
+
+		require.Len(t, st.Days, MaxDays)
+		assert.Equal(t, 5, st.Days[0].Reconciles)
+		assert.Equal(t, MaxDays+4, st.Days[MaxDays-1].Reconciles)
+	})
+}
+
+func TestState_Digest(t *testing.T) {
+	now := time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC)
+	st := &State{}
+	st.Day(now.AddDate(0, 0, -10)).Reconciles = 100 // Before the period
+	old := st.Day(now.AddDate(0, 0, -6))
+	old.Reconciles, old.Failures, old.DiskPercent = 10, 2, 40
+	old.Deploys = map[string]int{"media": 1}
+	old.Unhealthy = map[string]int{"sonarr": 1, "plex": 2}
+	today := st.Day(now)
+	today.Reconciles, today.DriftEvents, today.DiskPercent = 5, 1, 45.4
+	today.Deploys = map[string]int{"media": 1, "core": 2}
+	today.Unhealthy = map[string]int{"sonarr": 2}
+
+	d := st.Digest(now, 7*24*time.Hour)
+	assert.Equal(t, 15, d.Reconciles)
+	assert.Equal(t, 2, d.Failures)
+	assert.Equal(t, 1, d.DriftEvents)
+	assert.Equal(t, map[string]int{"media": 2, "core": 2}, d.Deploys)
+	assert.Equal(t, []Count{{"sonarr", 3}, {"plex", 2}}, d.Flapping)
+	assert.Equal(t, []float64{40, 45.4}, d.DiskTrend)
+
+	assert.Equal(t, []string{
+		"Reconciles: 15 (2 failed)",
+		"Drift events: 1",
+		"Deploys: core 2, media 2",
+		"Flapping services: sonarr (unhealthy 3x), plex (unhealthy 2x)",
+		"Docker disk: 40% -> 45% (+5 points)",
+	}, d.Lines())
+}
+
+func TestDigest_LinesEmpty(t *testing.T) {
+	d := (&State{}).Digest(time.Now(), 7*24*time.Hour)
+	assert.Equal(t, []string{
+		"Reconciles: 0 (0 failed)",
+		"Drift events: 0",
+		"Deploys: none",
+		"Flapping services: none",
+	}, d.Lines())
+}
+
+func TestDigest_FlappingLimit(t *testing.T) {
+	now := time.Now()
+	st := &State{}
+	day := st.Day(now)
+	day.Unhealthy = make(map[string]int)
+	for i := 0; i < MaxFlapping+2; i++ {
+		day.Unhealthy[fmt.Sprintf("svc%d", i)] = i + 1
+	}
+
+	d := st.Digest(now, 24*time.Hour)
+	require.Len(t, d.Flapping, MaxFlapping)
+	assert.Equal(t, fmt.Sprintf("svc%d", MaxFlapping+1), d.Flapping[0].Name)
+}
+
+func TestStore_Update(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.Update(func(st *State) error {
+		st.SetPin("media", "abc1234", time.Now())
+		return nil
+	}))
+	require.NoError(t, store.Update(func(st *State) error {
+		st.Day(time.Now()).DriftEvents++
+		return nil
+	}))
+
+	st, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, st.Pins, 1)
+	assert.Equal(t, 1, st.Day(time.Now()).DriftEvents)
+
+	t.Run("error skips the save", func(t *testing.T) {
+		err := store.Update(func(st *State) error {
+			st.Days = nil
+			return fmt.Errorf("boom")
+		})
+		assert.Error(t, err)
+
+		st, err := store.Load()
+		require.NoError(t, err)
+		assert.Len(t, st.Days, 1)
+	})
+}
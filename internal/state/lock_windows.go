@@ -0,0 +1,28 @@
+//go:build windows
+
+package state
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes an exclusive lock on path, waiting for other processes to
+// release it. On Windows, this uses LockFileEx.
+func lockFile(path string) (unlock func(), err error) {
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open state lock: %w", err)
+	}
+	overlapped := &windows.Overlapped{}
+	if err := windows.LockFileEx(windows.Handle(fd.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped); err != nil {
+		fd.Close()
+		return nil, fmt.Errorf("lock state: %w", err)
+	}
+	return func() {
+		_ = windows.UnlockFileEx(windows.Handle(fd.Fd()), 0, 1, 0, &windows.Overlapped{})
+		fd.Close()
+	}, nil
+}
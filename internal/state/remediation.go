@@ -0,0 +1,32 @@
+package state
+
+import "time"
+
+// MaxRemediations is how many drift remediations the state keeps.
+const MaxRemediations = 50
+
+// Remediation records the daemon redeploying a stack whose running
+// containers drifted from the deployed compose file.
+type Remediation struct {
+	At    time.Time `json:"at"`
+	Stack string    `json:"stack"`
+	// Findings are the drift that triggered it, e.g. "sonarr: not running".
+	Findings []string `json:"findings"`
+	// Error is why compose up failed; empty when it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// OK reports whether the remediation's compose up succeeded.
+func (r Remediation) OK() bool {
+	return r.Error == ""
+}
+
+// RecordRemediation appends r to the remediation log, dropping the oldest
+// entries beyond MaxRemediations.
+func (st *State) RecordRemediation(r Remediation) {
+	r.At = r.At.UTC()
+	st.Remediations = append(st.Remediations, r)
+	if extra := len(st.Remediations) - MaxRemediations; extra > 0 {
+		st.Remediations = append([]Remediation(nil), st.Remediations[extra:]...)
+	}
+}
@@ -0,0 +1,37 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRemediation(t *testing.T) {
+	st := &State{}
+	at := time.Date(2026, 3, 6, 14, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	for i := range MaxRemediations + 3 {
+		st.RecordRemediation(Remediation{At: at, Stack: fmt.Sprintf("stack%d", i), Findings: []string{"sonarr: not running"}})
+	}
+
+	require.Len(t, st.Remediations, MaxRemediations)
+	assert.Equal(t, "stack3", st.Remediations[0].Stack, "oldest entries are dropped")
+	assert.Equal(t, time.UTC, st.Remediations[0].At.Location())
+	assert.True(t, st.Remediations[0].OK())
+	assert.False(t, Remediation{Error: "compose up failed"}.OK())
+}
+
+func TestStore_Remediations(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.Update(func(st *State) error {
+		st.RecordRemediation(Remediation{At: time.Now(), Stack: "media", Findings: []string{"sonarr: config changed"}})
+		return nil
+	}))
+
+	st, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, st.Remediations, 1)
+	assert.Equal(t, []string{"sonarr: config changed"}, st.Remediations[0].Findings)
+}
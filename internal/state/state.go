@@ -0,0 +1,325 @@
+// Package state persists bosun's local runtime state between reconciliations.
+// State lives next to the reconciler (not in git) so operators can change it
+// without a commit, e.g. to pin a broken stack while everything else deploys.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cameronsjo/bosun/internal/seal"
+)
+
+// FileName is the name of the state file within the state directory.
+const FileName = "state.json"
+
+// LockFileName is the lock file Update holds within the state directory
+// while it reads, changes, and writes the state.
+const LockFileName = "state.json.lock"
+
+// SealedPlaceholder is shown in place of sealed values no available age key
+// opens (see Display).
+const SealedPlaceholder = "(sealed)"
+
+// State is the persisted runtime state.
+type State struct {
+	// Pins maps a stack name to the git ref it is pinned to.
+	Pins map[string]Pin `json:"pins,omitempty"`
+
+	// Verifications is the history of post-deploy smoke test runs, oldest
+	// first, capped at MaxVerifications.
+	Verifications []Verification `json:"verifications,omitempty"`
+
+	// Deploys is the history of successful deploys, oldest first, capped
+	// at MaxDeploys.
+	Deploys []Deploy `json:"deploys,omitempty"`
+
+	// Days are daily activity counters for the weekly digest, oldest
+	// first, capped at MaxDays.
+	Days []DayStats `json:"days,omitempty"`
+
+	// AllStop pauses all automation while in force; nil when not set.
+	AllStop *AllStop `json:"all_stop,omitempty"`
+
+	// Audit is the log of operator actions such as all-stops, oldest
+	// first, capped at MaxAudit.
+	Audit []AuditEntry `json:"audit,omitempty"`
+
+	// Remediations is the log of drifted stacks the daemon redeployed,
+	// oldest first, capped at MaxRemediations.
+	Remediations []Remediation `json:"remediations,omitempty"`
+
+	// Applied maps each stack to what the last successful deploy of it
+	// applied.
+	Applied map[string]Applied `json:"applied,omitempty"`
+}
+
+// Pin records a stack pinned to a specific git commit or tag.
+type Pin struct {
+	Ref      string    `json:"ref"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// MaxVerifications is how many verification runs the state keeps.
+const MaxVerifications = 20
+
+// Verification records one post-deploy smoke test run.
+type Verification struct {
+	At     time.Time `json:"at"`
+	Commit string    `json:"commit,omitempty"`
+	Passed int       `json:"passed"`
+	// Failures describe failed tests. They can quote URLs and command
+	// output holding credentials, so they are sealed at rest.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// OK reports whether every smoke test passed.
+func (v Verification) OK() bool {
+	return len(v.Failures) == 0
+}
+
+// MaxDeploys is how many deploys the state keeps.
+const MaxDeploys = 20
+
+// Deploy records one successful deploy.
+type Deploy struct {
+	At time.Time `json:"at"`
+	// Source is what triggered the deploy: "webhook", "poll", "manual", ...
+	Source string `json:"source,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// Stacks are the stacks the deploy touched.
+	Stacks []string `json:"stacks,omitempty"`
+}
+
+// Store reads and writes the state file in a directory.
+type Store struct {
+	dir string
+	key *seal.Key // Seals sensitive fields; nil stores them as plaintext
+}
+
+// NewStore creates a Store backed by dir. When an age key is available (see
+// seal.LoadKey), sensitive fields are sealed with it on disk.
+func NewStore(dir string) *Store {
+	key, _ := seal.LoadKey()
+	return &Store{dir: dir, key: key}
+}
+
+// sensitiveFields returns every field of st that may hold credentials.
+func sensitiveFields(st *State) []*string {
+	var fields []*string
+	for i := range st.Verifications {
+		for j := range st.Verifications[i].Failures {
+			fields = append(fields, &st.Verifications[i].Failures[j])
+		}
+	}
+	return fields
+}
+
+// Path returns the path of the state file.
+func (s *Store) Path() string {
+	return filepath.Join(s.dir, FileName)
+}
+
+// Display returns a state value for display: the value itself, or
+// SealedPlaceholder when it is still sealed because no available key opens it.
+func Display(value string) string {
+	if seal.IsSealed(value) {
+		return SealedPlaceholder
+	}
+	return value
+}
+
+// Load reads the state file. A missing file yields an empty State.
+// Sealed fields that can't be opened, with no key or a different one, keep
+// their ciphertext, which Save writes back unchanged, and a warning is
+// logged; the rest of the state loads as usual.
+func (s *Store) Load() (*State, error) {
+	data, err := os.ReadFile(s.Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse state file %s: %w", s.Path(), err)
+	}
+
+	var unopened int
+	var openErr error
+	for _, field := range sensitiveFields(&st) {
+		if !seal.IsSealed(*field) {
+			continue
+		}
+		if s.key == nil {
+			unopened++
+			continue
+		}
+		opened, err := s.key.Open(*field)
+		if err != nil {
+			unopened++
+			openErr = err
+			continue
+		}
+		*field = opened
+	}
+	if unopened > 0 {
+		reason := "no age key is available"
+		if openErr != nil {
+			reason = openErr.Error()
+		}
+		slog.Warn("Keeping sealed state fields sealed", "path", s.Path(), "fields", unopened, "reason", reason)
+	}
+	return &st, nil
+}
+
+// Save writes the state file atomically via temp file and rename.
+func (s *Store) Save(st *State) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+	if s.key != nil {
+		if data, err = s.sealed(data); err != nil {
+			return err
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(s.dir, ".state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // Cleanup on failure
+
+	if _, err := tmpFile.Write(append(data, '\n')); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.Path()); err != nil {
+		return fmt.Errorf("rename state file: %w", err)
+	}
+	return nil
+}
+
+// updateMu serializes Update calls within the process, so the daemon's
+// reconciler and health watch don't overwrite each other's changes.
+var updateMu sync.Mutex
+
+// Update loads the state, applies fn, and saves the result. Nothing is
+// saved when fn returns an error. Updates are serialized within the
+// process and, through a lock file in the state directory, with other
+// processes such as a CLI command run while the daemon is up, so none is
+// lost to a concurrent write. Every read-modify-write of the state should
+// go through Update rather than Load and Save.
+func (s *Store) Update(fn func(*State) error) error {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	unlock, err := lockFile(filepath.Join(s.dir, LockFileName))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	return s.Save(st)
+}
+
+// sealed returns the marshaled state data with its sensitive fields sealed,
+// leaving the caller's State untouched. Fields Load couldn't open are
+// already sealed, and Seal keeps them as they are.
+func (s *Store) sealed(data []byte) ([]byte, error) {
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("marshal state: %w", err)
+	}
+	for _, field := range sensitiveFields(&st) {
+		var err error
+		if *field, err = s.key.Seal(*field); err != nil {
+			return nil, fmt.Errorf("seal state: %w", err)
+		}
+	}
+	return json.MarshalIndent(&st, "", "  ")
+}
+
+// SetPin pins stack to ref, replacing any existing pin.
+func (st *State) SetPin(stack, ref string, at time.Time) {
+	if st.Pins == nil {
+		st.Pins = make(map[string]Pin)
+	}
+	st.Pins[stack] = Pin{Ref: ref, PinnedAt: at.UTC()}
+}
+
+// RemovePin removes the pin for stack. Returns false if it was not pinned.
+func (st *State) RemovePin(stack string) bool {
+	if _, ok := st.Pins[stack]; !ok {
+		return false
+	}
+	delete(st.Pins, stack)
+	return true
+}
+
+// PinnedStacks returns the names of all pinned stacks, sorted.
+func (st *State) PinnedStacks() []string {
+	stacks := make([]string, 0, len(st.Pins))
+	for name := range st.Pins {
+		stacks = append(stacks, name)
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+// RecordVerification appends v to the verification history, dropping the
+// oldest runs beyond MaxVerifications.
+func (st *State) RecordVerification(v Verification) {
+	v.At = v.At.UTC()
+	st.Verifications = append(st.Verifications, v)
+	if extra := len(st.Verifications) - MaxVerifications; extra > 0 {
+		st.Verifications = append([]Verification(nil), st.Verifications[extra:]...)
+	}
+}
+
+// RecordDeploy appends d to the deploy history, dropping the oldest deploys
+// beyond MaxDeploys.
+func (st *State) RecordDeploy(d Deploy) {
+	d.At = d.At.UTC()
+	st.Deploys = append(st.Deploys, d)
+	if extra := len(st.Deploys) - MaxDeploys; extra > 0 {
+		st.Deploys = append([]Deploy(nil), st.Deploys[extra:]...)
+	}
+}
+
+// LastDeploy returns the most recent deploy. ok is false when none is
+// recorded.
+func (st *State) LastDeploy() (d Deploy, ok bool) {
+	if len(st.Deploys) == 0 {
+		return Deploy{}, false
+	}
+	return st.Deploys[len(st.Deploys)-1], true
+}
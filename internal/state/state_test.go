@@ -0,0 +1,175 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Load(t *testing.T) {
+	t.Run("missing file returns empty state", func(t *testing.T) {
+		st, err := NewStore(t.TempDir()).Load()
+		require.NoError(t, err)
+		assert.Empty(t, st.Pins)
+	})
+
+	t.Run("invalid JSON returns error", func(t *testing.T) {
+		store := NewStore(t.TempDir())
+		require.NoError(t, os.WriteFile(store.Path(), []byte("{not json"), 0644))
+
+		_, err := store.Load()
+		assert.Error(t, err)
+	})
+}
+
+func TestStore_SaveRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir() + "/nested")
+	pinnedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	st := &State{}
+	st.SetPin("media", "v1.2.0", pinnedAt)
+	require.NoError(t, store.Save(st))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Contains(t, loaded.Pins, "media")
+	assert.Equal(t, "v1.2.0", loaded.Pins["media"].Ref)
+	assert.True(t, pinnedAt.Equal(loaded.Pins["media"].PinnedAt))
+}
+
+func TestState_Pins(t *testing.T) {
+	st := &State{}
+	st.SetPin("media", "abc1234", time.Now())
+	st.SetPin("core", "v2", time.Now())
+	st.SetPin("media", "def5678", time.Now())
+
+	assert.Equal(t, []string{"core", "media"}, st.PinnedStacks())
+	assert.Equal(t, "def5678", st.Pins["media"].Ref)
+
+	assert.True(t, st.RemovePin("media"))
+	assert.False(t, st.RemovePin("media"))
+	assert.Equal(t, []string{"core"}, st.PinnedStacks())
+}
+
+func TestState_RecordVerification(t *testing.T) {
+	st := &State{}
+	for i := 0; i < MaxVerifications+5; i++ {
+		st.RecordVerification(Verification{Passed: i})
+	}
+
+	require.Len(t, st.Verifications, MaxVerifications)
+	assert.Equal(t, 5, st.Verifications[0].Passed, "oldest runs are dropped")
+	assert.Equal(t, MaxVerifications+4, st.Verifications[MaxVerifications-1].Passed)
+
+	assert.True(t, Verification{Passed: 2}.OK())
+	assert.False(t, Verification{Failures: []string{"media/plex/web: 502"}}.OK())
+}
+
+func TestState_RecordDeploy(t *testing.T) {
+	st := &State{}
+	_, ok := st.LastDeploy()
+	assert.False(t, ok)
+
+	for i := 0; i < MaxDeploys+3; i++ {
+		st.RecordDeploy(Deploy{Commit: fmt.Sprintf("c%d", i), Source: "poll"})
+	}
+	require.Len(t, st.Deploys, MaxDeploys)
+	assert.Equal(t, "c3", st.Deploys[0].Commit, "oldest deploys are dropped")
+
+	last, ok := st.LastDeploy()
+	require.True(t, ok)
+	assert.Equal(t, fmt.Sprintf("c%d", MaxDeploys+2), last.Commit)
+}
+
+func TestStore_SealsSensitiveFields(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	t.Setenv("SOPS_AGE_KEY", id.String())
+
+	dir := t.TempDir()
+	st := &State{}
+	st.RecordVerification(Verification{Failures: []string{"apps/api/health: GET http://api/?token=s3cret returned 500"}})
+	require.NoError(t, NewStore(dir).Save(st))
+	assert.Equal(t, "apps/api/health: GET http://api/?token=s3cret returned 500", st.Verifications[0].Failures[0], "caller's state is not sealed")
+
+	data, err := os.ReadFile(NewStore(dir).Path())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+
+	loaded, err := NewStore(dir).Load()
+	require.NoError(t, err)
+	assert.Equal(t, st.Verifications, loaded.Verifications)
+
+	// Without the key, or with another one, sealed fields stay sealed
+	// through a load and save, and the original key still opens them.
+	otherKey, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	for name, setKey := range map[string]func(t *testing.T){
+		"without the key": func(t *testing.T) {
+			t.Setenv("SOPS_AGE_KEY", "")
+			t.Setenv("SOPS_AGE_KEY_FILE", dir+"/missing.txt")
+		},
+		"with another key": func(t *testing.T) {
+			t.Setenv("SOPS_AGE_KEY", otherKey.String())
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			setKey(t)
+			store := NewStore(dir)
+			loaded, err := store.Load()
+			require.NoError(t, err)
+			require.Len(t, loaded.Verifications[0].Failures, 1)
+			assert.Equal(t, SealedPlaceholder, Display(loaded.Verifications[0].Failures[0]))
+
+			loaded.RecordDeploy(Deploy{Commit: "abc"})
+			require.NoError(t, store.Save(loaded))
+		})
+	}
+
+	loaded, err = NewStore(dir).Load()
+	require.NoError(t, err)
+	assert.Equal(t, st.Verifications, loaded.Verifications, "sealed fields survive saves without the key")
+	assert.Len(t, loaded.Deploys, 2)
+}
+
+func TestStore_UpdateWaitsForLock(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	// Another process holding the lock, e.g. bosun pin while the daemon runs
+	unlock, err := lockFile(filepath.Join(dir, LockFileName))
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- store.Update(func(st *State) error {
+			st.SetPin("media", "v1", time.Now())
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Update finished while the lock was held: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, store.Save(&State{Deploys: []Deploy{{Commit: "abc"}}}))
+	unlock()
+	require.NoError(t, <-done)
+
+	st, err := store.Load()
+	require.NoError(t, err)
+	assert.Contains(t, st.Pins, "media")
+	assert.Len(t, st.Deploys, 1, "the write made while Update waited is kept")
+}
+
+func TestDisplay(t *testing.T) {
+	assert.Equal(t, "media/plex/web: 502", Display("media/plex/web: 502"))
+	assert.Equal(t, SealedPlaceholder, Display("age:YWJj"))
+}
@@ -0,0 +1,88 @@
+// Package timezone holds the timezone bosun displays timestamps and evaluates
+// schedules in. Timestamps are stored, and embedded in backup and snapshot
+// names, in UTC; they are converted to the configured zone only for display
+// and for schedules such as freeze windows.
+package timezone
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// EnvVar names the IANA timezone (e.g. "Europe/Berlin") to display and
+// schedule in. Without it, the system's local zone (TZ) is used.
+const EnvVar = "BOSUN_TIMEZONE"
+
+// Display layouts, with the zone abbreviation so times are unambiguous.
+const (
+	DisplayFormat        = "2006-01-02 15:04 MST"
+	DisplayFormatSeconds = "2006-01-02 15:04:05 MST"
+)
+
+var (
+	loadOnce sync.Once
+	location *time.Location
+)
+
+// Load returns the location for a timezone name. "local" and "" select the
+// system zone.
+func Load(name string) (*time.Location, error) {
+	switch name {
+	case "", "local", "Local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// Location returns the configured zone from BOSUN_TIMEZONE, falling back to
+// the system zone if it is unset or invalid.
+func Location() *time.Location {
+	loadOnce.Do(func() {
+		loc, err := Load(os.Getenv(EnvVar))
+		if err != nil {
+			loc = time.Local
+		}
+		location = loc
+	})
+	return location
+}
+
+// Format formats t in the configured zone.
+func Format(t time.Time, layout string) string {
+	return t.In(Location()).Format(layout)
+}
+
+// Display formats t in the configured zone to the minute, with the zone.
+func Display(t time.Time) string {
+	return Format(t, DisplayFormat)
+}
+
+// Ago describes how long before now t was, coarsely: "just now",
+// "5 minutes ago", "3 hours ago", "2 days ago".
+func Ago(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute") + " ago"
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour") + " ago"
+	default:
+		return plural(int(d.Hours()/24), "day") + " ago"
+	}
+}
+
+// plural formats n with unit, adding an "s" unless n is 1.
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,49 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	loc, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	loc, err = Load("UTC")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", loc.String())
+
+	_, err = Load("Mars/Olympus_Mons")
+	assert.ErrorContains(t, err, "invalid timezone")
+}
+
+func TestFormat(t *testing.T) {
+	berlin, err := Load("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	at := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-07-01 14:00 CEST", at.In(berlin).Format(DisplayFormat))
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{3*time.Hour + 20*time.Minute, "3 hours ago"},
+		{30 * time.Hour, "1 day ago"},
+		{9 * 24 * time.Hour, "9 days ago"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Ago(now.Add(-tt.ago), now), tt.ago.String())
+	}
+}
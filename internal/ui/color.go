@@ -3,9 +3,18 @@ package ui
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Color modes accepted by SetColorMode.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
 )
 
 var (
@@ -18,70 +27,135 @@ var (
 	Bold   = color.New(color.Bold)
 )
 
+// SetColorMode controls whether output is colored. In auto mode, color is
+// used only when stdout is a terminal, TERM is not "dumb", and NO_COLOR is
+// unset, so output captured by the daemon, CI, or systemd has no ANSI escapes.
+func SetColorMode(mode string) error {
+	switch mode {
+	case ColorAuto, "":
+		color.NoColor = !colorSupported(os.Stdout)
+	case ColorAlways:
+		color.NoColor = false
+	case ColorNever:
+		color.NoColor = true
+	default:
+		return fmt.Errorf("invalid color mode %q (use auto, always, or never)", mode)
+	}
+	return nil
+}
+
+// colorSupported reports whether colored output to f would be rendered.
+func colorSupported(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
 // Success prints a green success message with checkmark.
 func Success(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Green.Printf("✓ "+format+"\n", args...)
 }
 
 // Error prints a red error message with X.
 func Error(format string, args ...any) {
+	if logRecord(slog.LevelError, format, args...) {
+		return
+	}
 	Red.Printf("✗ "+format+"\n", args...)
 }
 
 // Warning prints a yellow warning message.
 func Warning(format string, args ...any) {
+	if logRecord(slog.LevelWarn, format, args...) {
+		return
+	}
 	Yellow.Printf("⚠ "+format+"\n", args...)
 }
 
 // Info prints a blue info message.
 func Info(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Blue.Printf(format+"\n", args...)
 }
 
 // Step prints a numbered step in cyan.
 func Step(n int, format string, args ...any) {
+	if logRecord(slog.LevelInfo, fmt.Sprintf("[%d] ", n)+format, args...) {
+		return
+	}
 	Cyan.Printf("[%d] ", n)
 	fmt.Printf(format+"\n", args...)
 }
 
 // Header prints a bold header.
 func Header(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Bold.Printf(format+"\n", args...)
 }
 
 // Nautical messages
 func Anchor(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Blue.Printf("⚓ "+format+"\n", args...)
 }
 
 func Ship(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Green.Printf("🚢 "+format+"\n", args...)
 }
 
 func Compass(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Cyan.Printf("🧭 "+format+"\n", args...)
 }
 
 func Mayday(format string, args ...any) {
+	if logRecord(slog.LevelError, format, args...) {
+		return
+	}
 	Red.Printf("🆘 "+format+"\n", args...)
 }
 
 func Snapshot(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Blue.Printf("📸 "+format+"\n", args...)
 }
 
 func Package(format string, args ...any) {
+	if logRecord(slog.LevelInfo, format, args...) {
+		return
+	}
 	Green.Printf("📦 "+format+"\n", args...)
 }
 
 // Fatal prints an error to stderr and exits.
 func Fatal(format string, args ...any) {
-	Red.Fprintf(os.Stderr, "✗ "+format+"\n", args...)
+	if !logRecord(slog.LevelError, format, args...) {
+		Red.Fprintf(os.Stderr, "✗ "+format+"\n", args...)
+	}
 	os.Exit(1)
 }
 
 // Fatalf prints a formatted error and exits.
 func Fatalf(format string, args ...any) {
-	Red.Fprintf(os.Stderr, format+"\n", args...)
+	if !logRecord(slog.LevelError, format, args...) {
+		Red.Fprintf(os.Stderr, format+"\n", args...)
+	}
 	os.Exit(1)
 }
@@ -315,3 +315,32 @@ func TestConcurrentOutput(t *testing.T) {
 		assert.Contains(t, output, "message")
 	}
 }
+
+func TestSetColorMode(t *testing.T) {
+	oldNoColor := color.NoColor
+	defer func() { color.NoColor = oldNoColor }()
+
+	assert.NoError(t, SetColorMode(ColorAlways))
+	assert.False(t, color.NoColor)
+
+	assert.NoError(t, SetColorMode(ColorNever))
+	assert.True(t, color.NoColor)
+
+	t.Run("auto honors NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		color.NoColor = false
+		assert.NoError(t, SetColorMode(ColorAuto))
+		assert.True(t, color.NoColor)
+	})
+
+	t.Run("auto disables color when not a terminal", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "out")
+		assert.NoError(t, err)
+		defer f.Close()
+		assert.False(t, colorSupported(f))
+	})
+
+	t.Run("rejects unknown modes", func(t *testing.T) {
+		assert.Error(t, SetColorMode("sometimes"))
+	})
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// Log formats accepted by SetLogFormat.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var (
+	logMu sync.Mutex
+	// jsonLog is the structured logger, nil in text mode.
+	jsonLog *slog.Logger
+	// logFields are attributes added to every structured record.
+	logFields []any
+	// textOutput and textSlog are color.Output and the default slog logger
+	// from before JSON mode, restored by text mode.
+	textOutput io.Writer
+	textSlog   *slog.Logger
+)
+
+// SetLogFormat selects how messages are written. Text is the colored
+// console output. JSON writes one slog record per line to stdout, with a
+// timestamp, level, and any fields set by WithLogFields, for log shippers
+// such as Loki or ELK. In JSON mode color is off, plain lines printed with
+// the color printers become info records, and the standard slog logger
+// writes JSON too.
+func SetLogFormat(format string) error {
+	switch format {
+	case LogFormatText, "":
+		setLogOutput(LogFormatText, os.Stdout)
+	case LogFormatJSON:
+		setLogOutput(LogFormatJSON, os.Stdout)
+	default:
+		return fmt.Errorf("invalid log format %q (use text or json)", format)
+	}
+	return nil
+}
+
+// setLogOutput switches the log format, writing structured records to w.
+func setLogOutput(format string, w io.Writer) {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	if format != LogFormatJSON {
+		if jsonLog != nil {
+			color.Output = textOutput
+			slog.SetDefault(textSlog)
+		}
+		jsonLog = nil
+		return
+	}
+	if jsonLog == nil {
+		textOutput, textSlog = color.Output, slog.Default()
+	}
+	jsonLog = slog.New(slog.NewJSONHandler(w, nil))
+	slog.SetDefault(jsonLog)
+	color.NoColor = true
+	color.Output = &lineLogger{}
+}
+
+// WithLogFields adds key-value fields, such as a reconcile ID and trigger
+// source, to every structured record until the returned function restores
+// the previous fields. It has no effect on text output.
+func WithLogFields(fields ...any) (restore func()) {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	previous := logFields
+	logFields = append(append([]any{}, previous...), fields...)
+	return func() {
+		logMu.Lock()
+		defer logMu.Unlock()
+		logFields = previous
+	}
+}
+
+// logRecord writes a structured record in JSON mode and reports whether it
+// did; in text mode the caller prints instead.
+func logRecord(level slog.Level, format string, args ...any) bool {
+	logMu.Lock()
+	logger, fields := jsonLog, logFields
+	logMu.Unlock()
+
+	if logger == nil {
+		return false
+	}
+	logger.Log(context.Background(), level, fmt.Sprintf(format, args...), fields...)
+	return true
+}
+
+// lineLogger turns plain output written through the color printers into
+// info records, one per line.
+type lineLogger struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf.Write(p)
+	for {
+		line, err := l.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line for the next write.
+			l.buf.Reset()
+			l.buf.WriteString(line)
+			break
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			logRecord(slog.LevelInfo, "%s", line)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureJSONLog runs fn in JSON mode and returns the decoded records.
+func captureJSONLog(t *testing.T, fn func()) []map[string]any {
+	t.Helper()
+	oldNoColor := color.NoColor
+
+	var buf bytes.Buffer
+	setLogOutput(LogFormatJSON, &buf)
+	fn()
+	setLogOutput(LogFormatText, nil)
+	color.NoColor = oldNoColor
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSetLogFormat(t *testing.T) {
+	assert.NoError(t, SetLogFormat(""))
+	assert.NoError(t, SetLogFormat(LogFormatText))
+	assert.ErrorContains(t, SetLogFormat("xml"), "invalid log format")
+}
+
+func TestJSONLog_Levels(t *testing.T) {
+	records := captureJSONLog(t, func() {
+		Info("Syncing %s", "repo")
+		Success("Deployed")
+		Warning("Disk at %d%%", 91)
+		Error("Compose failed")
+	})
+
+	require.Len(t, records, 4)
+	assert.Equal(t, "INFO", records[0]["level"])
+	assert.Equal(t, "Syncing repo", records[0]["msg"])
+	assert.Contains(t, records[0], "time")
+	assert.Equal(t, "INFO", records[1]["level"])
+	assert.Equal(t, "WARN", records[2]["level"])
+	assert.Equal(t, "Disk at 91%", records[2]["msg"])
+	assert.Equal(t, "ERROR", records[3]["level"])
+}
+
+func TestJSONLog_ColorPrinterLines(t *testing.T) {
+	records := captureJSONLog(t, func() {
+		Green.Printf("  * Tunnel is connected")
+		Green.Printf(" (%s)", "home.example.com")
+		Green.Println()
+		Blue.Println("      To fix this:")
+	})
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "* Tunnel is connected (home.example.com)", records[0]["msg"])
+	assert.Equal(t, "To fix this:", records[1]["msg"])
+}
+
+func TestWithLogFields(t *testing.T) {
+	records := captureJSONLog(t, func() {
+		restore := WithLogFields("reconcile_id", "a1b2c3d4", "source", "webhook")
+		Info("Starting")
+		restore()
+		Info("Idle")
+	})
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "a1b2c3d4", records[0]["reconcile_id"])
+	assert.Equal(t, "webhook", records[0]["source"])
+	assert.NotContains(t, records[1], "reconcile_id")
+}
+
+func TestTextModeIgnoresLogFields(t *testing.T) {
+	defer WithLogFields("reconcile_id", "a1b2c3d4")()
+	output := captureColorOutput(func() {
+		Info("Starting")
+	})
+	assert.Equal(t, "Starting\n", output)
+}
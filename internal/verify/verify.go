@@ -0,0 +1,290 @@
+// Package verify runs the post-deploy smoke tests that service manifests
+// declare, as carried in the x-bosun-verify extension of rendered compose files.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/selector"
+)
+
+// DefaultTimeout bounds a smoke test that does not set its own timeout.
+const DefaultTimeout = 10 * time.Second
+
+// maxBodyBytes caps how much of an HTTP response is read for body matching.
+const maxBodyBytes = 1 << 20
+
+// Check is a smoke test bound to the compose service that declared it.
+type Check struct {
+	Stack     string
+	Service   string
+	Container string // Container that exec tests run in
+	// Labels are the service's compose labels plus the bosun.stack and
+	// bosun.service pseudo-labels, for selector matching.
+	Labels map[string]string
+	manifest.SmokeTest
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Stack    string        `json:"stack"`
+	Service  string        `json:"service"`
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// String formats the result as stack/service/name: detail.
+func (r Result) String() string {
+	s := r.Stack + "/" + r.Service + "/" + r.Name
+	if r.Detail != "" {
+		s += ": " + r.Detail
+	}
+	return s
+}
+
+// Load returns the smoke tests declared in a rendered compose file. The
+// stack is the file's base name.
+func Load(composeFile string) ([]Check, error) {
+	data, err := os.ReadFile(composeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var compose struct {
+		Services map[string]struct {
+			ContainerName string               `yaml:"container_name"`
+			Labels        any                  `yaml:"labels"`
+			Verify        []manifest.SmokeTest `yaml:"x-bosun-verify"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", composeFile, err)
+	}
+
+	stack := strings.TrimSuffix(filepath.Base(composeFile), filepath.Ext(composeFile))
+	var checks []Check
+	for name, svc := range compose.Services {
+		container := svc.ContainerName
+		if container == "" {
+			container = name
+		}
+		labels := selector.ComposeLabels(svc.Labels)
+		labels[selector.StackLabel] = stack
+		labels[selector.ServiceLabel] = name
+		for _, test := range svc.Verify {
+			if err := test.Validate(); err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", stack, name, err)
+			}
+			c := Check{Stack: stack, Service: name, Container: container, Labels: labels, SmokeTest: test}
+			if test.Container != "" {
+				c.Container = test.Container
+			}
+			checks = append(checks, c)
+		}
+	}
+
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].Service < checks[j].Service })
+	return checks, nil
+}
+
+// LoadDir returns the smoke tests in every compose file in dir, or only in
+// <stack>.yml when stack is set.
+func LoadDir(dir, stack string) ([]Check, error) {
+	pattern := "*.yml"
+	if stack != "" {
+		pattern = stack + ".yml"
+	}
+	files, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	if stack != "" && len(files) == 0 {
+		return nil, fmt.Errorf("stack not found: %s", stack)
+	}
+
+	var checks []Check
+	for _, file := range files {
+		found, err := Load(file)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, found...)
+	}
+	return checks, nil
+}
+
+// Select returns the checks whose labels match sel.
+func Select(checks []Check, sel selector.Selector) []Check {
+	if sel.Empty() {
+		return checks
+	}
+	var matched []Check
+	for _, c := range checks {
+		if sel.Matches(c.Labels) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// Execer runs a command in a container and returns its exit code and output.
+type Execer interface {
+	Exec(ctx context.Context, container string, cmd []string) (int, string, error)
+}
+
+// DockerExec runs commands with docker exec, on Host's daemon over SSH when set.
+type DockerExec struct {
+	Host string
+}
+
+// Exec implements Execer.
+func (d DockerExec) Exec(ctx context.Context, container string, cmd []string) (int, string, error) {
+	var args []string
+	if d.Host != "" {
+		args = append(args, "-H", "ssh://"+d.Host)
+	}
+	args = append(append(args, "exec", container), cmd...)
+
+	c := exec.CommandContext(ctx, "docker", args...)
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, out.String(), nil
+	case errors.As(err, &exitErr) && ctx.Err() == nil:
+		return exitErr.ExitCode(), out.String(), nil
+	}
+	return -1, out.String(), err
+}
+
+// Runner runs checks.
+type Runner struct {
+	HTTP *http.Client
+	Exec Execer
+}
+
+// NewRunner returns a Runner using the default HTTP client and docker exec
+// against host ("" for this machine).
+func NewRunner(host string) *Runner {
+	return &Runner{HTTP: &http.Client{}, Exec: DockerExec{Host: host}}
+}
+
+// Run runs every check in order and returns their results.
+func (r *Runner) Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		start := time.Now()
+		err := r.run(ctx, c)
+		res := Result{Stack: c.Stack, Service: c.Service, Name: c.Name, OK: err == nil, Duration: time.Since(start)}
+		if err != nil {
+			res.Detail = err.Error()
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func (r *Runner) run(ctx context.Context, c Check) error {
+	timeout := DefaultTimeout
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err == nil {
+			timeout = d
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if c.HTTP != nil {
+		return r.runHTTP(ctx, c.HTTP)
+	}
+	return r.runExec(ctx, c)
+}
+
+func (r *Runner) runHTTP(ctx context.Context, t *manifest.HTTPSmokeTest) error {
+	method := t.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	want := t.Status
+	if want == 0 {
+		want = http.StatusOK
+	}
+	if resp.StatusCode != want {
+		return fmt.Errorf("%s %s returned %d, want %d", method, t.URL, resp.StatusCode, want)
+	}
+
+	if t.Body != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+		if !strings.Contains(string(body), t.Body) {
+			return fmt.Errorf("%s %s body does not contain %q", method, t.URL, t.Body)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) runExec(ctx context.Context, c Check) error {
+	code, output, err := r.Exec.Exec(ctx, c.Container, c.SmokeTest.Exec)
+	if err != nil {
+		return fmt.Errorf("exec in %s: %w", c.Container, err)
+	}
+	if code != c.ExitCode {
+		detail := fmt.Sprintf("%s exited %d, want %d", strings.Join(c.SmokeTest.Exec, " "), code, c.ExitCode)
+		if output = strings.TrimSpace(output); output != "" {
+			detail += ": " + lastLine(output)
+		}
+		return errors.New(detail)
+	}
+	return nil
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndex(s, "\n"); i != -1 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// Failed returns the results that did not pass.
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if !r.OK {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,173 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cameronsjo/bosun/internal/manifest"
+	"github.com/cameronsjo/bosun/internal/selector"
+)
+
+type fakeExec struct {
+	code   int
+	output string
+	err    error
+
+	container string
+	cmd       []string
+}
+
+func (f *fakeExec) Exec(_ context.Context, container string, cmd []string) (int, string, error) {
+	f.container, f.cmd = container, cmd
+	return f.code, f.output, f.err
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "media.yml"), []byte(`services:
+  plex:
+    container_name: plex-server
+    x-bosun-verify:
+      - name: web
+        http:
+          url: http://plex:32400/identity
+  sonarr:
+    image: sonarr
+    x-bosun-verify:
+      - name: api
+        exec: [curl, -f, localhost:8989/ping]
+        container: sonarr-sidecar
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "core.yml"), []byte("services:\n  traefik:\n    image: traefik\n"), 0644))
+
+	checks, err := LoadDir(dir, "")
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.Equal(t, "media", checks[0].Stack)
+	assert.Equal(t, "plex", checks[0].Service)
+	assert.Equal(t, "plex-server", checks[0].Container)
+	assert.Equal(t, "http://plex:32400/identity", checks[0].HTTP.URL)
+	assert.Equal(t, "sonarr-sidecar", checks[1].Container)
+
+	checks, err = LoadDir(dir, "core")
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+
+	_, err = LoadDir(dir, "missing")
+	assert.ErrorContains(t, err, "stack not found")
+}
+
+func TestSelect(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "media.yml"), []byte(`services:
+  sonarr:
+    labels:
+      - app=arr
+    x-bosun-verify:
+      - name: api
+        exec: [true]
+  plex:
+    labels:
+      app: plex
+    x-bosun-verify:
+      - name: web
+        exec: [true]
+`), 0644))
+
+	checks, err := LoadDir(dir, "")
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.Equal(t, map[string]string{"app": "plex", "bosun.stack": "media", "bosun.service": "plex"}, checks[0].Labels)
+
+	sel, err := selector.Parse("app=arr")
+	require.NoError(t, err)
+	matched := Select(checks, sel)
+	require.Len(t, matched, 1)
+	assert.Equal(t, "sonarr", matched[0].Service)
+
+	assert.Len(t, Select(checks, selector.Stack("media")), 2)
+	assert.Empty(t, Select(checks, selector.Stack("infra")))
+	assert.Len(t, Select(checks, selector.Selector{}), 2)
+}
+
+func TestLoad_InvalidTest(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "media.yml")
+	require.NoError(t, os.WriteFile(file, []byte("services:\n  plex:\n    x-bosun-verify:\n      - name: empty\n"), 0644))
+
+	_, err := Load(file)
+	assert.ErrorContains(t, err, "media/plex")
+}
+
+func TestRunner_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	check := func(name string, test manifest.HTTPSmokeTest) Check {
+		return Check{Stack: "app", Service: "web", SmokeTest: manifest.SmokeTest{Name: name, HTTP: &test}}
+	}
+	runner := &Runner{HTTP: srv.Client()}
+
+	results := runner.Run(context.Background(), []Check{
+		check("ok", manifest.HTTPSmokeTest{URL: srv.URL + "/health", Body: `"ok"`}),
+		check("status", manifest.HTTPSmokeTest{URL: srv.URL + "/missing"}),
+		check("expected 404", manifest.HTTPSmokeTest{URL: srv.URL + "/missing", Status: http.StatusNotFound}),
+		check("body", manifest.HTTPSmokeTest{URL: srv.URL + "/health", Body: "healthy"}),
+	})
+
+	require.Len(t, results, 4)
+	assert.True(t, results[0].OK, results[0].Detail)
+	assert.False(t, results[1].OK)
+	assert.Contains(t, results[1].Detail, "returned 404, want 200")
+	assert.True(t, results[2].OK, results[2].Detail)
+	assert.False(t, results[3].OK)
+	assert.Contains(t, results[3].Detail, `does not contain "healthy"`)
+
+	failed := Failed(results)
+	require.Len(t, failed, 2)
+	assert.Equal(t, "status", failed[0].Name)
+	assert.Equal(t, "app/web/body: "+results[3].Detail, failed[1].String())
+}
+
+func TestRunner_Exec(t *testing.T) {
+	check := Check{Stack: "db", Service: "postgres", Container: "postgres",
+		SmokeTest: manifest.SmokeTest{Name: "ready", Exec: []string{"pg_isready"}}}
+
+	t.Run("expected exit code", func(t *testing.T) {
+		exec := &fakeExec{}
+		results := (&Runner{Exec: exec}).Run(context.Background(), []Check{check})
+
+		assert.True(t, results[0].OK)
+		assert.Equal(t, "postgres", exec.container)
+		assert.Equal(t, []string{"pg_isready"}, exec.cmd)
+	})
+
+	t.Run("unexpected exit code", func(t *testing.T) {
+		exec := &fakeExec{code: 2, output: "checking\nno response\n"}
+		results := (&Runner{Exec: exec}).Run(context.Background(), []Check{check})
+
+		assert.False(t, results[0].OK)
+		assert.Equal(t, "pg_isready exited 2, want 0: no response", results[0].Detail)
+	})
+
+	t.Run("exec error", func(t *testing.T) {
+		exec := &fakeExec{err: errors.New("no such container")}
+		results := (&Runner{Exec: exec}).Run(context.Background(), []Check{check})
+
+		assert.False(t, results[0].OK)
+		assert.Contains(t, results[0].Detail, "no such container")
+	})
+}
@@ -0,0 +1,137 @@
+// Package webhook decides which git provider pushes should trigger a
+// reconcile. It is shared by the daemon's webhook endpoints and the
+// standalone bosun webhook receiver.
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Filter decides which pushes trigger a reconcile. An empty list allows
+// everything.
+type Filter struct {
+	Branches []string // Branch globs, e.g. "main" or "release/*"
+	Paths    []string // Path globs; "**" matches any number of directories
+}
+
+// Push is what a Filter sees of a provider's push payload.
+type Push struct {
+	Branch string   // Empty for tag pushes
+	Files  []string // Changed files; empty when the provider doesn't list them
+}
+
+// Commits is the part of a GitHub, GitLab, or Gitea push payload that names
+// the branch and the changed files. Embed it in a payload struct to decode
+// them alongside the provider's other fields.
+type Commits struct {
+	Ref     string `json:"ref"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// Push returns the branch and every file the push's commits touch.
+func (c Commits) Push() Push {
+	p := Push{}
+	if branch, ok := strings.CutPrefix(c.Ref, "refs/heads/"); ok {
+		p.Branch = branch
+	}
+	for _, commit := range c.Commits {
+		p.Files = append(p.Files, commit.Added...)
+		p.Files = append(p.Files, commit.Modified...)
+		p.Files = append(p.Files, commit.Removed...)
+	}
+	return p
+}
+
+// FilterFromEnv builds a filter from flags, falling back to the
+// comma-separated WEBHOOK_BRANCHES and WEBHOOK_PATHS.
+func FilterFromEnv(branches, paths []string) Filter {
+	if len(branches) == 0 {
+		branches = splitList(os.Getenv("WEBHOOK_BRANCHES"))
+	}
+	if len(paths) == 0 {
+		paths = splitList(os.Getenv("WEBHOOK_PATHS"))
+	}
+	return Filter{Branches: branches, Paths: paths}
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Allows reports whether p passes the filter, and if not, why. Pushes that
+// don't list their files (Bitbucket, or a push of existing commits) pass the
+// path filter, since what they change is unknown.
+func (f Filter) Allows(p Push) (bool, string) {
+	if len(f.Branches) > 0 {
+		if p.Branch == "" {
+			return false, "not a branch push"
+		}
+		if !matchAny(f.Branches, p.Branch) {
+			return false, fmt.Sprintf("branch %s is not in %s", p.Branch, strings.Join(f.Branches, ", "))
+		}
+	}
+	if len(f.Paths) > 0 && len(p.Files) > 0 {
+		matched := false
+		for _, file := range p.Files {
+			if matchAny(f.Paths, file) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, fmt.Sprintf("no changed file matches %s", strings.Join(f.Paths, ", "))
+		}
+	}
+	return true, ""
+}
+
+// matchAny reports whether name matches any of the globs.
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matchGlob(glob, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated name against a path.Match pattern in
+// which a "**" segment matches zero or more segments, e.g. "manifest/**".
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"manifest/**", "manifest/stacks/media.yml", true},
+		{"manifest/**", "manifest", true},
+		{"manifest/**", "docs/manifest/x.md", false},
+		{"**/*.yml", "manifest/stacks/media.yml", true},
+		{"**/*.yml", "media.yml", true},
+		{"infrastructure/*/compose.yml", "infrastructure/traefik/compose.yml", true},
+		{"infrastructure/*/compose.yml", "infrastructure/a/b/compose.yml", false},
+		{"main", "main", true},
+		{"release/*", "release/1.2", true},
+		{"release/*", "main", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_Allows(t *testing.T) {
+	filter := Filter{Branches: []string{"main"}, Paths: []string{"manifest/**", "infrastructure/**"}}
+
+	tests := []struct {
+		name string
+		push Push
+		want bool
+	}{
+		{"matching branch and path", Push{Branch: "main", Files: []string{"README.md", "manifest/stacks/media.yml"}}, true},
+		{"doc-only push", Push{Branch: "main", Files: []string{"README.md", "docs/gitops.md"}}, false},
+		{"other branch", Push{Branch: "feature", Files: []string{"manifest/stacks/media.yml"}}, false},
+		{"tag push", Push{Files: []string{"manifest/stacks/media.yml"}}, false},
+		{"files unknown", Push{Branch: "main"}, true},
+	}
+	for _, tt := range tests {
+		if got, reason := filter.Allows(tt.push); got != tt.want {
+			t.Errorf("%s: Allows() = %v (%s), want %v", tt.name, got, reason, tt.want)
+		}
+	}
+
+	if ok, _ := (Filter{}).Allows(Push{}); !ok {
+		t.Error("empty filter should allow every push")
+	}
+}
+
+func TestCommits_Push(t *testing.T) {
+	var payload struct {
+		Commits
+		Pusher struct {
+			Name string `json:"name"`
+		} `json:"pusher"`
+	}
+	body := `{"ref":"refs/heads/main","pusher":{"name":"alice"},"commits":[{"added":["a.yml"],"modified":["b.yml"]},{"removed":["c.yml"]}]}`
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+
+	p := payload.Push()
+	if p.Branch != "main" {
+		t.Errorf("Branch = %q, want main", p.Branch)
+	}
+	if strings.Join(p.Files, "|") != "a.yml|b.yml|c.yml" {
+		t.Errorf("Files = %v, want every touched file", p.Files)
+	}
+	if payload.Pusher.Name != "alice" {
+		t.Errorf("Pusher.Name = %q, want alice", payload.Pusher.Name)
+	}
+
+	tag := Commits{Ref: "refs/tags/v1.0.0"}
+	if got := tag.Push().Branch; got != "" {
+		t.Errorf("tag push Branch = %q, want empty", got)
+	}
+}
+
+func TestFilterFromEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_BRANCHES", "main, release/*")
+	t.Setenv("WEBHOOK_PATHS", "manifest/**")
+
+	f := FilterFromEnv(nil, []string{"infrastructure/**"})
+	if strings.Join(f.Branches, "|") != "main|release/*" {
+		t.Errorf("Branches = %v, want env value", f.Branches)
+	}
+	if strings.Join(f.Paths, "|") != "infrastructure/**" {
+		t.Errorf("Paths = %v, want flag value", f.Paths)
+	}
+}